@@ -18,6 +18,8 @@ type Benchmark struct {
 	DocumentURI       string              `json:"documentURI" example:"benchmarks/azure_cis_v140.md"`                                                                                                                                // Benchmark document URI
 	AutoAssign        bool                `json:"autoAssign" example:"true"`                                                                                                                                                         // Whether the benchmark is auto assigned or not
 	TracksDriftEvents bool                `json:"tracksDriftEvents" example:"true"`                                                                                                                                                  // Whether the benchmark tracks drift events or not
+	CapturesEvidence  bool                `json:"capturesEvidence" example:"false"`                                                                                                                                                  // Whether the compliance runs of the benchmark capture resource evidence
+	Source            ContentSource       `json:"source" example:"built-in"`                                                                                                                                                         // Whether the benchmark comes from the content repository or was created through the API
 	Tags              map[string][]string `json:"tags" `                                                                                                                                                                             // Benchmark tags
 	Connectors        []source.Type       `json:"connectors" example:"[azure]"`                                                                                                                                                      // Benchmark connectors
 	Children          []string            `json:"children" example:"[azure_cis_v140_1, azure_cis_v140_2]"`                                                                                                                           // Benchmark children
@@ -49,6 +51,7 @@ type BenchmarkTrendDatapoint struct {
 	ConformanceStatusSummary ConformanceStatusSummary        `json:"conformanceStatusSummary"`
 	Checks                   types.SeverityResult            `json:"checks"`
 	ControlsSeverityStatus   BenchmarkControlsSeverityStatus `json:"controlsSeverityStatus"`
+	WeightedSecurityScore    float64                         `json:"weightedSecurityScore" example:"82.5"` // Pass ratio of the findings weighted by severity and control overrides
 }
 
 type ListBenchmarksSummaryResponse struct {
@@ -110,14 +113,16 @@ type BenchmarkResourcesSeverityStatusV2 struct {
 }
 
 type ConformanceStatusSummary struct {
-	PassedCount int `json:"passed"`
-	FailedCount int `json:"failed"`
+	PassedCount   int `json:"passed"`
+	FailedCount   int `json:"failed"`
+	ExceptedCount int `json:"excepted"` // Failed findings covered by an active finding exception, not included in failed
 }
 
 type ConformanceStatusSummaryV2 struct {
-	TotalCount  int `json:"total_count"`
-	PassedCount int `json:"passed"`
-	FailedCount int `json:"failed"`
+	TotalCount    int `json:"total_count"`
+	PassedCount   int `json:"passed"`
+	FailedCount   int `json:"failed"`
+	ExceptedCount int `json:"excepted"` // Failed findings covered by an active finding exception, not included in failed
 }
 
 func (c *ConformanceStatusSummary) AddESConformanceStatusMap(summary map[types.ConformanceStatus]int) {
@@ -146,6 +151,7 @@ type BenchmarkEvaluationSummary struct {
 	ResourcesSeverityStatus  BenchmarkResourcesSeverityStatus `json:"resourcesSeverityStatus"`
 	ConnectionsStatus        BenchmarkStatusResult            `json:"connectionsStatus"`
 	CostOptimization         *float64                         `json:"costOptimization"`
+	WeightedSecurityScore    float64                          `json:"weightedSecurityScore" example:"82.5"` // Pass ratio of the findings weighted by severity and control overrides
 	EvaluatedAt              *time.Time                       `json:"evaluatedAt" example:"2020-01-01T00:00:00Z"`
 	LastJobStatus            string                           `json:"lastJobStatus" example:"success"`
 	TopConnections           []TopFieldRecord                 `json:"topConnections"`
@@ -338,6 +344,7 @@ type ComplianceSummaryOfBenchmarkResponse struct {
 	SeveritySummaryByResource  BenchmarkResourcesSeverityStatusV2 `json:"severity_summary_by_resource"`
 	SeveritySummaryByIncidents types.SeverityResultV2             `json:"severity_summary_by_incidents"`
 	CostOptimization           *float64                           `json:"cost_optimization"`
+	WeightedSecurityScore      float64                            `json:"weighted_security_score"` // Pass ratio of the findings weighted by severity and control overrides
 	FindingsSummary            ConformanceStatusSummaryV2         `json:"findings_summary"`
 	IssuesCount                int                                `json:"issues_count"`
 	TopIntegrations            []TopIntegration                   `json:"top_integrations"`
@@ -28,7 +28,8 @@ type Control struct {
 	Query              *Query                `json:"query"`
 	Severity           types.FindingSeverity `json:"severity" example:"low"`
 	ManualVerification bool                  `json:"manualVerification" example:"true"`
-	Managed            bool                  `json:"managed" example:"true"`
+	Managed            bool                  `json:"managed" example:"true"`    // Managed flag of the control in the content repository, always false for custom controls
+	Source             ContentSource         `json:"source" example:"built-in"` // Whether the control comes from the content repository or was created through the API
 	CreatedAt          time.Time             `json:"createdAt" example:"2020-01-01T00:00:00Z"`
 	UpdatedAt          time.Time             `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
 }
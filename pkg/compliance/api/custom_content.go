@@ -0,0 +1,106 @@
+package api
+
+import (
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+// ContentSource tells apart the benchmarks, controls and queries of the content repository from the ones created through the API.
+// It is the only field migrations look at to decide which rows they own, custom rows are never updated or removed by them.
+type ContentSource string
+
+const (
+	ContentSourceBuiltIn ContentSource = "built-in"
+	ContentSourceCustom  ContentSource = "custom"
+)
+
+type CustomQuery struct {
+	QueryToExecute string           `json:"queryToExecute" validate:"required" example:"select arn as resource, kaytu_account_id, case when encrypted then 'ok' else 'alarm' end as status, title as reason from aws_ebs_volume"`
+	PrimaryTable   *string          `json:"primaryTable" example:"aws_ebs_volume"`
+	ListOfTables   []string         `json:"listOfTables" example:"aws_ebs_volume"`
+	Engine         string           `json:"engine" example:"odysseus-sql"` // Defaults to odysseus-sql, the only engine custom queries support
+	Parameters     []QueryParameter `json:"parameters"`
+	Global         bool             `json:"global"`
+}
+
+type CreateCustomControlRequest struct {
+	ID          string                `json:"id" validate:"required" example:"custom_aws_ebs_encrypted"`
+	Title       string                `json:"title" validate:"required" example:"EBS volumes should be encrypted"`
+	Description string                `json:"description"`
+	Connector   []source.Type         `json:"connector" validate:"required,min=1" example:"AWS"`
+	Severity    types.FindingSeverity `json:"severity" example:"high"` // Defaults to low
+	DocumentURI string                `json:"documentURI"`
+	Tags        map[string][]string   `json:"tags"`
+	Query       *CustomQuery          `json:"query"`   // Query created along with the control, either query or queryID is required
+	QueryID     *string               `json:"queryID"` // Custom query the control runs, either query or queryID is required
+}
+
+type UpdateCustomControlRequest struct {
+	Title       *string                `json:"title"`
+	Description *string                `json:"description"`
+	Severity    *types.FindingSeverity `json:"severity"`
+	DocumentURI *string                `json:"documentURI"`
+	Enabled     *bool                  `json:"enabled"`
+	Tags        map[string][]string    `json:"tags"`    // Replaces the control tags if set
+	Query       *CustomQuery           `json:"query"`   // Replaces the control query if set
+	QueryID     *string                `json:"queryID"` // Switches the control to another custom query if set
+}
+
+type CreateCustomQueryRequest struct {
+	ID        string        `json:"id" validate:"required" example:"custom_aws_ebs_encrypted"`
+	Connector []source.Type `json:"connector" validate:"required,min=1" example:"AWS"`
+	Query     CustomQuery   `json:"query" validate:"required"`
+}
+
+type UpdateCustomQueryRequest struct {
+	Connector []source.Type `json:"connector"` // Replaces the connectors if set
+	Query     CustomQuery   `json:"query" validate:"required"`
+}
+
+type CreateCustomBenchmarkRequest struct {
+	ID                string              `json:"id" validate:"required" example:"custom_storage_baseline"`
+	Title             string              `json:"title" validate:"required" example:"Storage baseline"`
+	ReferenceCode     string              `json:"referenceCode"`
+	Description       string              `json:"description"`
+	DocumentURI       string              `json:"documentURI"`
+	Connectors        []source.Type       `json:"connectors" example:"AWS"`
+	Tags              map[string][]string `json:"tags"`
+	Children          []string            `json:"children" example:"aws_cis_v200"`             // Benchmarks nested under this benchmark, built-in or custom
+	Controls          []string            `json:"controls" example:"custom_aws_ebs_encrypted"` // Controls directly under this benchmark, built-in or custom
+	AutoAssign        bool                `json:"autoAssign"`
+	TracksDriftEvents bool                `json:"tracksDriftEvents"`
+}
+
+type UpdateCustomBenchmarkRequest struct {
+	Title         *string             `json:"title"`
+	ReferenceCode *string             `json:"referenceCode"`
+	Description   *string             `json:"description"`
+	DocumentURI   *string             `json:"documentURI"`
+	Connectors    []source.Type       `json:"connectors"` // Replaces the connectors if set
+	Tags          map[string][]string `json:"tags"`       // Replaces the tags if set
+	Children      []string            `json:"children"`   // Replaces the children if set
+	Controls      []string            `json:"controls"`   // Replaces the controls if set
+}
+
+type ValidateCustomQueryRequest struct {
+	Query CustomQuery `json:"query" validate:"required"`
+}
+
+type ValidateCustomQueryResponse struct {
+	Valid   bool     `json:"valid" example:"true"`
+	Errors  []string `json:"errors" example:"missing required column status"`
+	Columns []string `json:"columns" example:"resource"` // Columns returned by the query
+}
+
+type DryRunCustomQueryRequest struct {
+	Query        CustomQuery `json:"query" validate:"required"`
+	ConnectionID string      `json:"connectionID" validate:"required" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"` // Connection the query is executed for
+}
+
+type DryRunCustomQueryResponse struct {
+	ValidateCustomQueryResponse
+	ResultCount            int                             `json:"resultCount" example:"10"`
+	Truncated              bool                            `json:"truncated" example:"false"` // Whether the result was cut at the dry-run row limit
+	ConformanceStatusCount map[types.ConformanceStatus]int `json:"conformanceStatusCount"`
+	Rows                   [][]any                         `json:"rows"`
+}
@@ -118,6 +118,7 @@ type Query struct {
 	Engine         string           `json:"engine" example:"steampipe-v0.5"`
 	Parameters     []QueryParameter `json:"parameters"`
 	Global         bool             `json:"Global"`
+	Source         ContentSource    `json:"source" example:"built-in"`
 	CreatedAt      time.Time        `json:"createdAt" example:"2023-06-07T14:00:15.677558Z"`
 	UpdatedAt      time.Time        `json:"updatedAt" example:"2023-06-16T14:58:08.759554Z"`
 }
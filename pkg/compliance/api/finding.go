@@ -20,6 +20,7 @@ type FindingFilters struct {
 	Severity          []types.FindingSeverity `json:"severity" example:"low"`
 	ConformanceStatus []ConformanceStatus     `json:"conformanceStatus" example:"alarm"`
 	StateActive       []bool                  `json:"stateActive" example:"true"`
+	Owner             []string                `json:"owner" example:"payments"`
 	LastEvent         struct {
 		From *int64 `json:"from"`
 		To   *int64 `json:"to"`
@@ -138,6 +139,9 @@ type Finding struct {
 	ParentBenchmarkReferences []string              `json:"parentBenchmarkReferences"`
 	ParentBenchmarks          []string              `json:"parentBenchmarks"`
 	LastEvent                 time.Time             `json:"lastEvent" example:"1589395200"`
+	SLADueAt                  *time.Time            `json:"slaDueAt" example:"2020-01-01T00:00:00Z"` // When the active failing finding breaches its severity SLA, empty if it has none
+	Overdue                   bool                  `json:"overdue" example:"false"`                 // Whether the finding is past its SLA due date
+	Owner                     string                `json:"owner" example:"payments"`                // Owner group resolved from ownership rules
 
 	ResourceTypeName       string   `json:"resourceTypeName" example:"Virtual Machine"`
 	ParentBenchmarkNames   []string `json:"parentBenchmarkNames" example:"Azure CIS v1.4.0"`
@@ -172,6 +176,7 @@ func GetAPIFindingFromESFinding(finding types.Finding) Finding {
 		ParentComplianceJobID:     finding.ParentComplianceJobID,
 		ParentBenchmarks:          finding.ParentBenchmarks,
 		LastEvent:                 time.UnixMilli(finding.LastTransition),
+		Owner:                     finding.Owner,
 	}
 	if finding.ConformanceStatus.IsPassed() {
 		f.ConformanceStatus = ConformanceStatusPassed
@@ -192,10 +197,11 @@ type GetFindingsResponse struct {
 }
 
 type FindingKPIResponse struct {
-	FailedFindingsCount   int64 `json:"failedFindingsCount"`
-	FailedResourceCount   int64 `json:"failedResourceCount"`
-	FailedControlCount    int64 `json:"failedControlCount"`
-	FailedConnectionCount int64 `json:"failedConnectionCount"`
+	FailedFindingsCount   int64         `json:"failedFindingsCount"`
+	FailedResourceCount   int64         `json:"failedResourceCount"`
+	FailedControlCount    int64         `json:"failedControlCount"`
+	FailedConnectionCount int64         `json:"failedConnectionCount"`
+	SLA                   FindingSLAKPI `json:"sla"`
 }
 
 type ServiceFindingsSummary struct {
@@ -253,6 +259,8 @@ type FindingFiltersV2 struct {
 	IsActive        []bool                  `json:"is_active"`
 	ResourceType    []string                `json:"resource_type"`
 	NotResourceType []string                `json:"not_resource_type"`
+	Owner           []string                `json:"owner"`
+	Overdue         *bool                   `json:"overdue"` // Only active failing findings past their SLA if true, only the others if false
 	LastUpdated     struct {
 		From *int64 `json:"from"`
 		To   *int64 `json:"to"`
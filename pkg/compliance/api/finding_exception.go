@@ -0,0 +1,101 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type FindingException struct {
+	ID              uint       `json:"id" example:"1"`
+	BenchmarkID     *string    `json:"benchmarkID" example:"azure_cis_v140"`                                                                              // Benchmark the exception is scoped to
+	ControlID       *string    `json:"controlID" example:"azure_cis_v140_7_5"`                                                                            // Control the exception is scoped to
+	ConnectionID    *string    `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`                                                       // Connection the exception is scoped to
+	KaytuResourceID *string    `json:"kaytuResourceID" example:"/subscriptions/123/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1"` // Resource the exception is scoped to
+	TagKey          *string    `json:"tagKey" example:"team"`                                                                                             // Resource tag key the exception is scoped to
+	TagValue        *string    `json:"tagValue" example:"payments"`                                                                                       // Resource tag value the exception is scoped to, any value matches if empty
+	Justification   string     `json:"justification" example:"Legacy VM scheduled for decommission, risk accepted by security team"`                      // Why the risk is accepted
+	Approver        string     `json:"approver" example:"security-team@example.com"`                                                                      // Who approved the exception
+	CreatedBy       string     `json:"createdBy" example:"auth|123"`                                                                                      // User who created the exception
+	ExpiresAt       *time.Time `json:"expiresAt" example:"2020-01-01T00:00:00Z"`                                                                          // Exception expiry, never expires if empty
+	Active          bool       `json:"active" example:"true"`                                                                                             // Whether the exception is currently applied
+	CreatedAt       time.Time  `json:"createdAt" example:"2020-01-01T00:00:00Z"`                                                                          // Exception creation date
+	UpdatedAt       time.Time  `json:"updatedAt" example:"2020-01-01T00:00:00Z"`                                                                          // Exception last update date
+}
+
+type CreateFindingExceptionRequest struct {
+	BenchmarkID     *string    `json:"benchmarkID" example:"azure_cis_v140"`
+	ControlID       *string    `json:"controlID" example:"azure_cis_v140_7_5"`
+	ConnectionID    *string    `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	KaytuResourceID *string    `json:"kaytuResourceID" example:"/subscriptions/123/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1"`
+	TagKey          *string    `json:"tagKey" example:"team"`
+	TagValue        *string    `json:"tagValue" example:"payments"`
+	Justification   string     `json:"justification" validate:"required"`
+	Approver        string     `json:"approver" validate:"required"`
+	ExpiresAt       *time.Time `json:"expiresAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type UpdateFindingExceptionRequest struct {
+	Justification *string    `json:"justification"`
+	Approver      *string    `json:"approver"`
+	ExpiresAt     *time.Time `json:"expiresAt" example:"2020-01-01T00:00:00Z"`
+}
+
+// HasScope reports whether at least one scope field is set, an exception without scope would silence every finding
+func (r CreateFindingExceptionRequest) HasScope() bool {
+	for _, s := range []*string{r.BenchmarkID, r.ControlID, r.ConnectionID, r.KaytuResourceID, r.TagKey} {
+		if s != nil && *s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActiveAt reports whether the exception applies at the given time, expired exceptions resurface their findings
+func (e FindingException) IsActiveAt(t time.Time) bool {
+	return e.ExpiresAt == nil || e.ExpiresAt.After(t)
+}
+
+// Matches reports whether the finding falls in the exception scope, every set scope field has to match
+func (e FindingException) Matches(finding types.Finding, tags map[string]string) bool {
+	if e.BenchmarkID != nil && *e.BenchmarkID != "" {
+		matched := strings.EqualFold(*e.BenchmarkID, finding.BenchmarkID)
+		for _, parent := range finding.ParentBenchmarks {
+			if strings.EqualFold(*e.BenchmarkID, parent) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if e.ControlID != nil && *e.ControlID != "" && !strings.EqualFold(*e.ControlID, finding.ControlID) {
+		return false
+	}
+	if e.ConnectionID != nil && *e.ConnectionID != "" && !strings.EqualFold(*e.ConnectionID, finding.ConnectionID) {
+		return false
+	}
+	if e.KaytuResourceID != nil && *e.KaytuResourceID != "" &&
+		!strings.EqualFold(*e.KaytuResourceID, finding.KaytuResourceID) &&
+		!strings.EqualFold(*e.KaytuResourceID, finding.ResourceID) {
+		return false
+	}
+	if e.TagKey != nil && *e.TagKey != "" {
+		matched := false
+		for k, v := range tags {
+			if !strings.EqualFold(k, *e.TagKey) {
+				continue
+			}
+			if e.TagValue == nil || *e.TagValue == "" || strings.EqualFold(v, *e.TagValue) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
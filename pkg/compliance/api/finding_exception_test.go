@@ -0,0 +1,104 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestCreateFindingExceptionRequest_HasScope(t *testing.T) {
+	tests := []struct {
+		name string
+		req  CreateFindingExceptionRequest
+		want bool
+	}{
+		{name: "empty", req: CreateFindingExceptionRequest{}, want: false},
+		{name: "empty strings", req: CreateFindingExceptionRequest{BenchmarkID: strPtr(""), ControlID: strPtr("")}, want: false},
+		{name: "tag value only", req: CreateFindingExceptionRequest{TagValue: strPtr("payments")}, want: false},
+		{name: "benchmark", req: CreateFindingExceptionRequest{BenchmarkID: strPtr("aws_cis")}, want: true},
+		{name: "control", req: CreateFindingExceptionRequest{ControlID: strPtr("aws_cis_1")}, want: true},
+		{name: "connection", req: CreateFindingExceptionRequest{ConnectionID: strPtr("conn-1")}, want: true},
+		{name: "resource", req: CreateFindingExceptionRequest{KaytuResourceID: strPtr("res-1")}, want: true},
+		{name: "tag key", req: CreateFindingExceptionRequest{TagKey: strPtr("team")}, want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.req.HasScope())
+		})
+	}
+}
+
+func TestFindingException_IsActiveAt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	assert.True(t, FindingException{}.IsActiveAt(now), "exceptions without expiry never expire")
+	assert.True(t, FindingException{ExpiresAt: &future}.IsActiveAt(now))
+	assert.False(t, FindingException{ExpiresAt: &past}.IsActiveAt(now))
+	assert.False(t, FindingException{ExpiresAt: &now}.IsActiveAt(now), "exceptions expire at their expiry time")
+}
+
+func TestFindingException_Matches(t *testing.T) {
+	finding := types.Finding{
+		BenchmarkID:      "aws_cis_v200_1",
+		ParentBenchmarks: []string{"aws_cis_v200", "aws_cis_v200_1"},
+		ControlID:        "aws_cis_v200_1_4",
+		ConnectionID:     "conn-1",
+		KaytuResourceID:  "kaytu-res-1",
+		ResourceID:       "arn:aws:iam::123:user/admin",
+	}
+	tags := map[string]string{"Team": "Payments", "env": "prod"}
+
+	tests := []struct {
+		name      string
+		exception FindingException
+		want      bool
+	}{
+		{name: "no scope matches everything", exception: FindingException{}, want: true},
+		{name: "benchmark", exception: FindingException{BenchmarkID: strPtr("aws_cis_v200_1")}, want: true},
+		{name: "parent benchmark", exception: FindingException{BenchmarkID: strPtr("aws_cis_v200")}, want: true},
+		{name: "benchmark is case insensitive", exception: FindingException{BenchmarkID: strPtr("AWS_CIS_V200")}, want: true},
+		{name: "other benchmark", exception: FindingException{BenchmarkID: strPtr("azure_cis")}, want: false},
+		{name: "control", exception: FindingException{ControlID: strPtr("aws_cis_v200_1_4")}, want: true},
+		{name: "other control", exception: FindingException{ControlID: strPtr("aws_cis_v200_1_5")}, want: false},
+		{name: "connection", exception: FindingException{ConnectionID: strPtr("conn-1")}, want: true},
+		{name: "other connection", exception: FindingException{ConnectionID: strPtr("conn-2")}, want: false},
+		{name: "kaytu resource id", exception: FindingException{KaytuResourceID: strPtr("kaytu-res-1")}, want: true},
+		{name: "resource id", exception: FindingException{KaytuResourceID: strPtr("arn:aws:iam::123:user/admin")}, want: true},
+		{name: "other resource", exception: FindingException{KaytuResourceID: strPtr("kaytu-res-2")}, want: false},
+		{name: "tag key with any value", exception: FindingException{TagKey: strPtr("team")}, want: true},
+		{name: "tag key and value", exception: FindingException{TagKey: strPtr("team"), TagValue: strPtr("payments")}, want: true},
+		{name: "tag key with other value", exception: FindingException{TagKey: strPtr("team"), TagValue: strPtr("billing")}, want: false},
+		{name: "missing tag", exception: FindingException{TagKey: strPtr("owner")}, want: false},
+		{name: "empty scope strings are wildcards", exception: FindingException{ControlID: strPtr(""), ConnectionID: strPtr("")}, want: true},
+		{
+			name:      "every scope field has to match",
+			exception: FindingException{ControlID: strPtr("aws_cis_v200_1_4"), ConnectionID: strPtr("conn-2")},
+			want:      false,
+		},
+		{
+			name: "all scope fields match",
+			exception: FindingException{
+				BenchmarkID:     strPtr("aws_cis_v200"),
+				ControlID:       strPtr("aws_cis_v200_1_4"),
+				ConnectionID:    strPtr("conn-1"),
+				KaytuResourceID: strPtr("kaytu-res-1"),
+				TagKey:          strPtr("env"),
+				TagValue:        strPtr("prod"),
+			},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.exception.Matches(finding, tags))
+		})
+	}
+}
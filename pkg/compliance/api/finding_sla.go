@@ -0,0 +1,83 @@
+package api
+
+import (
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+// DefaultFindingSLADays are the remediation windows used for severities the workspace has not configured
+var DefaultFindingSLADays = map[types.FindingSeverity]int{
+	types.FindingSeverityCritical: 7,
+	types.FindingSeverityHigh:     30,
+	types.FindingSeverityMedium:   90,
+	types.FindingSeverityLow:      180,
+	types.FindingSeverityNone:     0,
+}
+
+// FindingSLASeverities lists the severities from critical to none, the order SLAs are reported in
+var FindingSLASeverities = []types.FindingSeverity{
+	types.FindingSeverityCritical,
+	types.FindingSeverityHigh,
+	types.FindingSeverityMedium,
+	types.FindingSeverityLow,
+	types.FindingSeverityNone,
+}
+
+type FindingSLA struct {
+	Severity types.FindingSeverity `json:"severity" validate:"required" example:"critical"`
+	Days     int                   `json:"days" validate:"min=0" example:"7"` // Days a failing finding has to be remediated in, no SLA if zero
+}
+
+type GetFindingSLAsResponse struct {
+	SLAs []FindingSLA `json:"slas"`
+}
+
+type UpdateFindingSLAsRequest struct {
+	SLAs []FindingSLA `json:"slas" validate:"required,dive"`
+}
+
+// FindingSLAPolicy maps each severity to its remediation window in days
+type FindingSLAPolicy map[types.FindingSeverity]int
+
+// DueAt returns when a finding failing since failingSince breaches its SLA, nil if the severity has no SLA
+func (p FindingSLAPolicy) DueAt(severity types.FindingSeverity, failingSince time.Time) *time.Time {
+	days := p[severity]
+	if days <= 0 {
+		return nil
+	}
+	dueAt := failingSince.AddDate(0, 0, days)
+	return &dueAt
+}
+
+// OverdueCutoff returns the failure start time before which findings of the severity are overdue at now
+func (p FindingSLAPolicy) OverdueCutoff(severity types.FindingSeverity, now time.Time) *time.Time {
+	days := p[severity]
+	if days <= 0 {
+		return nil
+	}
+	cutoff := now.AddDate(0, 0, -days)
+	return &cutoff
+}
+
+// Apply sets the SLA due date and overdue flag of active failing findings, the last transition is when they started failing
+func (p FindingSLAPolicy) Apply(finding *Finding, now time.Time) {
+	finding.SLADueAt, finding.Overdue = nil, false
+	if !finding.StateActive || finding.ConformanceStatus != ConformanceStatusFailed {
+		return
+	}
+	finding.SLADueAt = p.DueAt(finding.Severity, finding.LastEvent)
+	finding.Overdue = finding.SLADueAt != nil && finding.SLADueAt.Before(now)
+}
+
+type FindingSLASeverityKPI struct {
+	Severity             types.FindingSeverity `json:"severity" example:"critical"`
+	SLADays              int                   `json:"slaDays" example:"7"`
+	FailedFindingsCount  int64                 `json:"failedFindingsCount" example:"10"`
+	OverdueFindingsCount int64                 `json:"overdueFindingsCount" example:"3"`
+}
+
+type FindingSLAKPI struct {
+	OverdueFindingsCount int64                   `json:"overdueFindingsCount" example:"3"`
+	Severities           []FindingSLASeverityKPI `json:"severities"` // Ordered from critical to none
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestFindingSLAPolicy_DueAtAndOverdueCutoff(t *testing.T) {
+	policy := FindingSLAPolicy{
+		types.FindingSeverityCritical: 7,
+		types.FindingSeverityHigh:     30,
+		types.FindingSeverityLow:      0,
+	}
+	at := time.Date(2024, 2, 25, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		severity   types.FindingSeverity
+		wantDueAt  *time.Time
+		wantCutoff *time.Time
+	}{
+		{
+			name:       "configured severity",
+			severity:   types.FindingSeverityCritical,
+			wantDueAt:  timePtr(time.Date(2024, 3, 3, 10, 30, 0, 0, time.UTC)),
+			wantCutoff: timePtr(time.Date(2024, 2, 18, 10, 30, 0, 0, time.UTC)),
+		},
+		{
+			name:       "window across months",
+			severity:   types.FindingSeverityHigh,
+			wantDueAt:  timePtr(time.Date(2024, 3, 26, 10, 30, 0, 0, time.UTC)),
+			wantCutoff: timePtr(time.Date(2024, 1, 26, 10, 30, 0, 0, time.UTC)),
+		},
+		{name: "zero days is no sla", severity: types.FindingSeverityLow},
+		{name: "unconfigured severity", severity: types.FindingSeverityMedium},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantDueAt, policy.DueAt(tc.severity, at))
+			assert.Equal(t, tc.wantCutoff, policy.OverdueCutoff(tc.severity, at))
+		})
+	}
+}
+
+func TestFindingSLAPolicy_Apply(t *testing.T) {
+	policy := FindingSLAPolicy{types.FindingSeverityCritical: 7}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cutoff := *policy.OverdueCutoff(types.FindingSeverityCritical, now)
+
+	tests := []struct {
+		name        string
+		finding     Finding
+		wantDueAt   *time.Time
+		wantOverdue bool
+	}{
+		{
+			name:        "failing past the window",
+			finding:     Finding{StateActive: true, ConformanceStatus: ConformanceStatusFailed, Severity: types.FindingSeverityCritical, LastEvent: cutoff.Add(-time.Millisecond)},
+			wantDueAt:   timePtr(now.Add(-time.Millisecond)),
+			wantOverdue: true,
+		},
+		{
+			name:      "failing exactly at the cutoff is due now, not overdue",
+			finding:   Finding{StateActive: true, ConformanceStatus: ConformanceStatusFailed, Severity: types.FindingSeverityCritical, LastEvent: cutoff},
+			wantDueAt: timePtr(now),
+		},
+		{
+			name:      "failing within the window",
+			finding:   Finding{StateActive: true, ConformanceStatus: ConformanceStatusFailed, Severity: types.FindingSeverityCritical, LastEvent: now.AddDate(0, 0, -1)},
+			wantDueAt: timePtr(now.AddDate(0, 0, 6)),
+		},
+		{
+			name:    "unconfigured severity",
+			finding: Finding{StateActive: true, ConformanceStatus: ConformanceStatusFailed, Severity: types.FindingSeverityHigh, LastEvent: now.AddDate(-1, 0, 0)},
+		},
+		{
+			name:    "passed finding",
+			finding: Finding{StateActive: true, ConformanceStatus: ConformanceStatusPassed, Severity: types.FindingSeverityCritical, LastEvent: now.AddDate(-1, 0, 0)},
+		},
+		{
+			name:    "inactive failing finding",
+			finding: Finding{StateActive: false, ConformanceStatus: ConformanceStatusFailed, Severity: types.FindingSeverityCritical, LastEvent: now.AddDate(-1, 0, 0)},
+		},
+		{
+			name: "stale values are cleared",
+			finding: Finding{StateActive: true, ConformanceStatus: ConformanceStatusPassed, Severity: types.FindingSeverityCritical, LastEvent: now.AddDate(-1, 0, 0),
+				SLADueAt: timePtr(now.AddDate(0, -1, 0)), Overdue: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			finding := tc.finding
+			policy.Apply(&finding, now)
+			assert.Equal(t, tc.wantDueAt, finding.SLADueAt)
+			assert.Equal(t, tc.wantOverdue, finding.Overdue)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type NotificationTransition string
+
+const (
+	NotificationTransitionFailing NotificationTransition = "failing" // finding started failing or failed on first evaluation
+	NotificationTransitionPassing NotificationTransition = "passing" // failing finding passed or its resource is gone
+)
+
+type NotificationSubscription struct {
+	ID            uint                     `json:"id" example:"1"`
+	Name          string                   `json:"name" example:"security-team"`
+	URL           string                   `json:"url" example:"https://hooks.example.com/open-governance"`      // Webhook endpoint the deliveries are posted to
+	Secret        string                   `json:"secret,omitempty" example:"9b2a4f3c0e1d"`                      // HMAC secret, only returned once on creation
+	BenchmarkIDs  []string                 `json:"benchmarkIDs" example:"azure_cis_v140"`                        // Benchmarks to notify about, all if empty
+	ControlIDs    []string                 `json:"controlIDs" example:"azure_cis_v140_7_5"`                      // Controls to notify about, all if empty
+	Severities    []types.FindingSeverity  `json:"severities" example:"high"`                                    // Severities to notify about, all if empty
+	ConnectionIDs []string                 `json:"connectionIDs" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"` // Connections to notify about, all if empty
+	Transitions   []NotificationTransition `json:"transitions" example:"failing"`                                // Transition directions to notify about, all if empty
+	Enabled       bool                     `json:"enabled" example:"true"`                                       // Disabled subscriptions are skipped
+	CreatedBy     string                   `json:"createdBy" example:"auth|123"`                                 // User who created the subscription
+	CreatedAt     time.Time                `json:"createdAt" example:"2020-01-01T00:00:00Z"`                     // Subscription creation date
+	UpdatedAt     time.Time                `json:"updatedAt" example:"2020-01-01T00:00:00Z"`                     // Subscription last update date
+}
+
+type CreateNotificationSubscriptionRequest struct {
+	Name          string                   `json:"name" validate:"required"`
+	URL           string                   `json:"url" validate:"required,url"`
+	Secret        string                   `json:"secret"` // Generated if empty
+	BenchmarkIDs  []string                 `json:"benchmarkIDs"`
+	ControlIDs    []string                 `json:"controlIDs"`
+	Severities    []types.FindingSeverity  `json:"severities"`
+	ConnectionIDs []string                 `json:"connectionIDs"`
+	Transitions   []NotificationTransition `json:"transitions"`
+	Enabled       *bool                    `json:"enabled"` // Defaults to true
+}
+
+type UpdateNotificationSubscriptionRequest struct {
+	Name          *string                  `json:"name"`
+	URL           *string                  `json:"url" validate:"omitempty,url"`
+	BenchmarkIDs  []string                 `json:"benchmarkIDs"`
+	ControlIDs    []string                 `json:"controlIDs"`
+	Severities    []types.FindingSeverity  `json:"severities"`
+	ConnectionIDs []string                 `json:"connectionIDs"`
+	Transitions   []NotificationTransition `json:"transitions"`
+	Enabled       *bool                    `json:"enabled"`
+}
+
+type NotificationDelivery struct {
+	ID              uint       `json:"id" example:"1"`
+	SubscriptionID  uint       `json:"subscriptionID" example:"1"`
+	ComplianceJobID uint       `json:"complianceJobID" example:"1"` // Compliance job whose finding events were delivered, zero for test deliveries
+	EventCount      int        `json:"eventCount" example:"10"`     // Number of finding events in the payload
+	Attempts        int        `json:"attempts" example:"1"`
+	StatusCode      int        `json:"statusCode" example:"200"` // Last response status code, zero if no response was received
+	Succeeded       bool       `json:"succeeded" example:"true"`
+	Error           string     `json:"error" example:"context deadline exceeded"`
+	CreatedAt       time.Time  `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	DeliveredAt     *time.Time `json:"deliveredAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type DispatchComplianceJobNotificationsRequest struct {
+	RunnerIDs []uint `json:"runnerIDs"` // Only the finding events of these runners are delivered, all events of the job if empty
+}
+
+// NotificationPayload is the body posted to subscription webhooks.
+// The X-Signature-256 header carries "sha256=" + hex(HMAC-SHA256(secret, X-Timestamp + "." + body)).
+type NotificationPayload struct {
+	DeliveryID      uint                `json:"deliveryID" example:"1"`
+	SubscriptionID  uint                `json:"subscriptionID" example:"1"`
+	ComplianceJobID uint                `json:"complianceJobID" example:"1"`
+	SentAt          time.Time           `json:"sentAt" example:"2020-01-01T00:00:00Z"`
+	Events          []NotificationEvent `json:"events"`
+}
+
+type NotificationEvent struct {
+	Transition NotificationTransition `json:"transition" example:"failing"`
+	FindingEvent
+}
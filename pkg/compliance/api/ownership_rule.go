@@ -0,0 +1,92 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type OwnershipRule struct {
+	ID           uint      `json:"id" example:"1"`
+	Owner        string    `json:"owner" example:"payments"`                                    // Owner group assigned to matching findings
+	Priority     int       `json:"priority" example:"10"`                                       // Rules are evaluated in ascending priority, the first match wins
+	TagKey       *string   `json:"tagKey" example:"team"`                                       // Resource tag key the rule matches
+	TagValue     *string   `json:"tagValue" example:"payments"`                                 // Resource tag value the rule matches, any value matches if empty
+	ConnectionID *string   `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"` // Connection the rule matches
+	ResourceType *string   `json:"resourceType" example:"Microsoft.Compute/virtualMachines"`    // Resource type the rule matches
+	CreatedBy    string    `json:"createdBy" example:"auth|123"`                                // User who created the rule
+	CreatedAt    time.Time `json:"createdAt" example:"2020-01-01T00:00:00Z"`                    // Rule creation date
+	UpdatedAt    time.Time `json:"updatedAt" example:"2020-01-01T00:00:00Z"`                    // Rule last update date
+}
+
+type CreateOwnershipRuleRequest struct {
+	Owner        string  `json:"owner" validate:"required" example:"payments"`
+	Priority     int     `json:"priority" example:"10"`
+	TagKey       *string `json:"tagKey" example:"team"`
+	TagValue     *string `json:"tagValue" example:"payments"`
+	ConnectionID *string `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	ResourceType *string `json:"resourceType" example:"Microsoft.Compute/virtualMachines"`
+}
+
+type UpdateOwnershipRuleRequest struct {
+	Owner    *string `json:"owner" example:"payments"`
+	Priority *int    `json:"priority" example:"10"`
+}
+
+// HasScope reports whether at least one match field is set, a rule without scope would own every finding
+func (r CreateOwnershipRuleRequest) HasScope() bool {
+	for _, s := range []*string{r.TagKey, r.ConnectionID, r.ResourceType} {
+		if s != nil && *s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the finding falls in the rule scope, every set match field has to match
+func (r OwnershipRule) Matches(finding types.Finding, tags map[string]string) bool {
+	if r.ConnectionID != nil && *r.ConnectionID != "" && !strings.EqualFold(*r.ConnectionID, finding.ConnectionID) {
+		return false
+	}
+	if r.ResourceType != nil && *r.ResourceType != "" && !strings.EqualFold(*r.ResourceType, finding.ResourceType) {
+		return false
+	}
+	if r.TagKey != nil && *r.TagKey != "" {
+		matched := false
+		for k, v := range tags {
+			if !strings.EqualFold(k, *r.TagKey) {
+				continue
+			}
+			if r.TagValue == nil || *r.TagValue == "" || strings.EqualFold(v, *r.TagValue) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// SortOwnershipRules orders rules the way they are evaluated, by priority then by creation order
+func SortOwnershipRules(rules []OwnershipRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority < rules[j].Priority
+		}
+		return rules[i].ID < rules[j].ID
+	})
+}
+
+// ResolveOwner returns the owner of the first matching rule, rules must be sorted with SortOwnershipRules
+func ResolveOwner(rules []OwnershipRule, finding types.Finding, tags map[string]string) string {
+	for _, rule := range rules {
+		if rule.Matches(finding, tags) {
+			return rule.Owner
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateOwnershipRuleRequest_HasScope(t *testing.T) {
+	tests := []struct {
+		name string
+		req  CreateOwnershipRuleRequest
+		want bool
+	}{
+		{name: "empty", req: CreateOwnershipRuleRequest{Owner: "payments"}, want: false},
+		{name: "empty strings", req: CreateOwnershipRuleRequest{TagKey: strPtr(""), ResourceType: strPtr("")}, want: false},
+		{name: "tag value only", req: CreateOwnershipRuleRequest{TagValue: strPtr("payments")}, want: false},
+		{name: "tag key", req: CreateOwnershipRuleRequest{TagKey: strPtr("team")}, want: true},
+		{name: "connection", req: CreateOwnershipRuleRequest{ConnectionID: strPtr("conn-1")}, want: true},
+		{name: "resource type", req: CreateOwnershipRuleRequest{ResourceType: strPtr("AWS::EC2::Instance")}, want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.req.HasScope())
+		})
+	}
+}
+
+func TestOwnershipRule_Matches(t *testing.T) {
+	finding := types.Finding{
+		ConnectionID: "conn-1",
+		ResourceType: "AWS::EC2::Instance",
+	}
+	tags := map[string]string{"Team": "Payments", "env": "prod"}
+
+	tests := []struct {
+		name string
+		rule OwnershipRule
+		want bool
+	}{
+		{name: "no scope matches everything", rule: OwnershipRule{}, want: true},
+		{name: "connection", rule: OwnershipRule{ConnectionID: strPtr("conn-1")}, want: true},
+		{name: "other connection", rule: OwnershipRule{ConnectionID: strPtr("conn-2")}, want: false},
+		{name: "resource type", rule: OwnershipRule{ResourceType: strPtr("AWS::EC2::Instance")}, want: true},
+		{name: "resource type is case insensitive", rule: OwnershipRule{ResourceType: strPtr("aws::ec2::instance")}, want: true},
+		{name: "other resource type", rule: OwnershipRule{ResourceType: strPtr("AWS::S3::Bucket")}, want: false},
+		{name: "tag key with any value", rule: OwnershipRule{TagKey: strPtr("team")}, want: true},
+		{name: "tag key with empty value", rule: OwnershipRule{TagKey: strPtr("team"), TagValue: strPtr("")}, want: true},
+		{name: "tag key and value", rule: OwnershipRule{TagKey: strPtr("TEAM"), TagValue: strPtr("payments")}, want: true},
+		{name: "tag key with other value", rule: OwnershipRule{TagKey: strPtr("team"), TagValue: strPtr("billing")}, want: false},
+		{name: "missing tag", rule: OwnershipRule{TagKey: strPtr("owner")}, want: false},
+		{name: "tag value without key is ignored", rule: OwnershipRule{TagValue: strPtr("billing")}, want: true},
+		{
+			name: "every scope field has to match",
+			rule: OwnershipRule{ConnectionID: strPtr("conn-1"), TagKey: strPtr("env"), TagValue: strPtr("dev")},
+			want: false,
+		},
+		{
+			name: "all scope fields match",
+			rule: OwnershipRule{ConnectionID: strPtr("conn-1"), ResourceType: strPtr("AWS::EC2::Instance"), TagKey: strPtr("env"), TagValue: strPtr("prod")},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.Matches(finding, tags))
+		})
+	}
+}
+
+func TestSortOwnershipRules(t *testing.T) {
+	rules := []OwnershipRule{
+		{ID: 4, Priority: 10},
+		{ID: 3, Priority: 0},
+		{ID: 1, Priority: 10},
+		{ID: 2, Priority: -5},
+		{ID: 5, Priority: 0},
+	}
+	SortOwnershipRules(rules)
+
+	var ids []uint
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	assert.Equal(t, []uint{2, 3, 5, 1, 4}, ids)
+}
+
+func TestResolveOwner(t *testing.T) {
+	rules := []OwnershipRule{
+		{ID: 1, Owner: "catch-all", Priority: 100, ConnectionID: strPtr("conn-1")},
+		{ID: 2, Owner: "payments", Priority: 10, TagKey: strPtr("team"), TagValue: strPtr("payments")},
+		{ID: 3, Owner: "compute", Priority: 10, ResourceType: strPtr("AWS::EC2::Instance")},
+	}
+	SortOwnershipRules(rules)
+
+	tests := []struct {
+		name    string
+		finding types.Finding
+		tags    map[string]string
+		want    string
+	}{
+		{
+			name:    "first rule by creation order wins on equal priority",
+			finding: types.Finding{ConnectionID: "conn-1", ResourceType: "AWS::EC2::Instance"},
+			tags:    map[string]string{"team": "payments"},
+			want:    "payments",
+		},
+		{
+			name:    "lower priority rule is used when higher ones do not match",
+			finding: types.Finding{ConnectionID: "conn-1", ResourceType: "AWS::EC2::Instance"},
+			want:    "compute",
+		},
+		{
+			name:    "catch all rule",
+			finding: types.Finding{ConnectionID: "conn-1", ResourceType: "AWS::S3::Bucket"},
+			want:    "catch-all",
+		},
+		{
+			name:    "no matching rule",
+			finding: types.Finding{ConnectionID: "conn-2", ResourceType: "AWS::S3::Bucket"},
+			want:    "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ResolveOwner(rules, tc.finding, tc.tags))
+		})
+	}
+
+	assert.Equal(t, "", ResolveOwner(nil, types.Finding{}, nil))
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type PostureDiffChange string
+
+const (
+	PostureDiffChangeNewlyFailing PostureDiffChange = "newly_failing" // failing at target, passing or not evaluated at base
+	PostureDiffChangeNewlyPassing PostureDiffChange = "newly_passing" // failing at base, passing at target
+	PostureDiffChangeDisappeared  PostureDiffChange = "disappeared"   // evaluated at base, resource no longer returned at target
+)
+
+type PostureDiffCounts struct {
+	NewlyFailing int `json:"newlyFailing" example:"3"`
+	NewlyPassing int `json:"newlyPassing" example:"5"`
+	Disappeared  int `json:"disappeared" example:"1"`
+}
+
+func (c *PostureDiffCounts) Add(change PostureDiffChange) {
+	switch change {
+	case PostureDiffChangeNewlyFailing:
+		c.NewlyFailing++
+	case PostureDiffChangeNewlyPassing:
+		c.NewlyPassing++
+	case PostureDiffChangeDisappeared:
+		c.Disappeared++
+	}
+}
+
+type PostureDiffItem struct {
+	FindingID                 string                  `json:"findingID"`
+	ControlID                 string                  `json:"controlID" example:"azure_cis_v140_7_5"`
+	ControlTitle              string                  `json:"controlTitle"`
+	KaytuResourceID           string                  `json:"kaytuResourceID"`
+	ResourceID                string                  `json:"resourceID"`
+	ResourceName              string                  `json:"resourceName" example:"vm-1"`
+	ResourceType              string                  `json:"resourceType" example:"Microsoft.Compute/virtualMachines"`
+	ResourceTypeName          string                  `json:"resourceTypeName" example:"Virtual Machine"`
+	ResourceLocation          string                  `json:"resourceLocation" example:"eastus"`
+	PreviousConformanceStatus types.ConformanceStatus `json:"previousConformanceStatus" example:"ok"` // Empty if the pair was not evaluated at base
+	ConformanceStatus         types.ConformanceStatus `json:"conformanceStatus" example:"alarm"`
+	Reason                    string                  `json:"reason"`
+	EvaluatedAt               time.Time               `json:"evaluatedAt"` // Evaluation time of the last change in the range
+}
+
+type PostureDiffSeverity struct {
+	Severity     types.FindingSeverity `json:"severity" example:"high"`
+	Counts       PostureDiffCounts     `json:"counts"`
+	NewlyFailing []PostureDiffItem     `json:"newlyFailing"`
+	NewlyPassing []PostureDiffItem     `json:"newlyPassing"`
+	Disappeared  []PostureDiffItem     `json:"disappeared"`
+}
+
+type PostureDiffConnection struct {
+	ConnectionID           string                `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	ProviderConnectionID   string                `json:"providerConnectionID" example:"123456789012"`
+	ProviderConnectionName string                `json:"providerConnectionName" example:"production"`
+	Connector              source.Type           `json:"connector" example:"AWS"`
+	Counts                 PostureDiffCounts     `json:"counts"`
+	Severities             []PostureDiffSeverity `json:"severities"` // Ordered from critical to none
+}
+
+type GetPostureDiffResponse struct {
+	BenchmarkID           string                  `json:"benchmarkID" example:"azure_cis_v140"`
+	BaseComplianceJobID   *uint                   `json:"baseComplianceJobID,omitempty" example:"1"`
+	TargetComplianceJobID *uint                   `json:"targetComplianceJobID,omitempty" example:"2"`
+	BaseTime              *time.Time              `json:"baseTime,omitempty"`
+	TargetTime            *time.Time              `json:"targetTime,omitempty"`
+	Counts                PostureDiffCounts       `json:"counts"`
+	Connections           []PostureDiffConnection `json:"connections"`
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+// DefaultSeverityScoreWeights are the weights used for severities the workspace has not configured
+var DefaultSeverityScoreWeights = map[types.FindingSeverity]float64{
+	types.FindingSeverityCritical: 8,
+	types.FindingSeverityHigh:     4,
+	types.FindingSeverityMedium:   2,
+	types.FindingSeverityLow:      1,
+	types.FindingSeverityNone:     1,
+}
+
+type SeverityScoreWeight struct {
+	Severity types.FindingSeverity `json:"severity" validate:"required" example:"critical"`
+	Weight   float64               `json:"weight" validate:"min=0" example:"8"` // How much a finding of the severity counts towards the weighted security score
+}
+
+type ControlScoreWeight struct {
+	ControlID string  `json:"controlID" example:"azure_cis_v140_7_5"`
+	Weight    float64 `json:"weight" example:"10"` // Overrides the severity weight of the control findings
+}
+
+type GetSecurityScoreWeightsResponse struct {
+	Severities []SeverityScoreWeight `json:"severities"`
+	Controls   []ControlScoreWeight  `json:"controls"` // Control overrides of the requested benchmark
+}
+
+type UpdateSecurityScoreWeightsRequest struct {
+	Severities []SeverityScoreWeight `json:"severities" validate:"required,dive"`
+}
+
+type ChangeBenchmarkSettingsRequest struct {
+	ControlWeights map[string]*float64 `json:"controlWeights"` // Control weight overrides, a null weight removes the override
+}
+
+// SecurityScoreWeights holds the weight of each severity and the control overrides of a benchmark
+type SecurityScoreWeights struct {
+	Severities map[types.FindingSeverity]float64
+	Controls   map[string]float64
+}
+
+// Weights converts the response to the lookup used while summarizing
+func (r GetSecurityScoreWeightsResponse) Weights() SecurityScoreWeights {
+	w := SecurityScoreWeights{
+		Severities: make(map[types.FindingSeverity]float64),
+		Controls:   make(map[string]float64),
+	}
+	for severity, weight := range DefaultSeverityScoreWeights {
+		w.Severities[severity] = weight
+	}
+	for _, s := range r.Severities {
+		w.Severities[s.Severity] = s.Weight
+	}
+	for _, c := range r.Controls {
+		w.Controls[strings.ToLower(c.ControlID)] = c.Weight
+	}
+	return w
+}
+
+// Weight returns how much the finding counts towards the weighted security score, control overrides win over severities
+func (w SecurityScoreWeights) Weight(finding types.Finding) float64 {
+	if weight, ok := w.Controls[strings.ToLower(finding.ControlID)]; ok {
+		return weight
+	}
+	if weight, ok := w.Severities[finding.Severity]; ok {
+		return weight
+	}
+	return DefaultSeverityScoreWeights[finding.Severity]
+}
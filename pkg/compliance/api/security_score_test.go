@@ -0,0 +1,45 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSecurityScoreWeightsResponse_Weights(t *testing.T) {
+	w := GetSecurityScoreWeightsResponse{
+		Severities: []SeverityScoreWeight{{Severity: types.FindingSeverityCritical, Weight: 20}},
+		Controls:   []ControlScoreWeight{{ControlID: "AWS_CIS_1_4", Weight: 50}},
+	}.Weights()
+
+	assert.Equal(t, 20.0, w.Severities[types.FindingSeverityCritical])
+	assert.Equal(t, DefaultSeverityScoreWeights[types.FindingSeverityHigh], w.Severities[types.FindingSeverityHigh], "unset severities fall back to the defaults")
+	assert.Equal(t, map[string]float64{"aws_cis_1_4": 50}, w.Controls)
+	assert.Equal(t, 8.0, DefaultSeverityScoreWeights[types.FindingSeverityCritical], "the defaults are not changed")
+}
+
+func TestSecurityScoreWeights_Weight(t *testing.T) {
+	w := SecurityScoreWeights{
+		Severities: map[types.FindingSeverity]float64{types.FindingSeverityHigh: 6},
+		Controls:   map[string]float64{"aws_cis_1_4": 0, "aws_cis_1_5": 12},
+	}
+
+	tests := []struct {
+		name    string
+		finding types.Finding
+		want    float64
+	}{
+		{name: "control override wins over severity", finding: types.Finding{ControlID: "aws_cis_1_5", Severity: types.FindingSeverityHigh}, want: 12},
+		{name: "control override is case insensitive", finding: types.Finding{ControlID: "AWS_CIS_1_5", Severity: types.FindingSeverityLow}, want: 12},
+		{name: "zero control override", finding: types.Finding{ControlID: "aws_cis_1_4", Severity: types.FindingSeverityCritical}, want: 0},
+		{name: "configured severity", finding: types.Finding{ControlID: "aws_cis_2_1", Severity: types.FindingSeverityHigh}, want: 6},
+		{name: "default severity", finding: types.Finding{ControlID: "aws_cis_2_1", Severity: types.FindingSeverityCritical}, want: 8},
+		{name: "unknown severity", finding: types.Finding{ControlID: "aws_cis_2_1", Severity: "unknown"}, want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, w.Weight(tc.finding))
+		})
+	}
+}
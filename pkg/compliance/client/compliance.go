@@ -14,6 +14,7 @@ import (
 
 type ComplianceServiceClient interface {
 	ListAssignmentsByBenchmark(ctx *httpclient.Context, benchmarkID string) (*compliance.BenchmarkAssignedEntities, error)
+	ListAssignmentsByConnection(ctx *httpclient.Context, connectionID string) ([]compliance.AssignedBenchmark, error)
 	GetBenchmark(ctx *httpclient.Context, benchmarkID string) (*compliance.Benchmark, error)
 	GetBenchmarkSummary(ctx *httpclient.Context, benchmarkID string, connectionId []string, timeAt *time.Time) (*compliance.BenchmarkEvaluationSummary, error)
 	GetBenchmarkTrend(ctx *httpclient.Context, benchmarkID string, connectionId []string, startTime *time.Time, endTime *time.Time) ([]compliance.BenchmarkTrendDatapoint, error)
@@ -31,6 +32,10 @@ type ComplianceServiceClient interface {
 	GetControlDetails(ctx *httpclient.Context, controlID string) (*compliance.GetControlDetailsResponse, error)
 	PurgeSampleData(ctx *httpclient.Context) error
 	SyncQueries(ctx *httpclient.Context) error
+	ListActiveFindingExceptions(ctx *httpclient.Context) ([]compliance.FindingException, error)
+	ListOwnershipRules(ctx *httpclient.Context) ([]compliance.OwnershipRule, error)
+	GetSecurityScoreWeights(ctx *httpclient.Context, benchmarkID string) (*compliance.GetSecurityScoreWeightsResponse, error)
+	DispatchComplianceJobNotifications(ctx *httpclient.Context, complianceJobID uint, runnerIDs []uint) error
 }
 
 type complianceClient struct {
@@ -66,6 +71,19 @@ func (s *complianceClient) ListAssignmentsByBenchmark(ctx *httpclient.Context, b
 	return &response, nil
 }
 
+func (s *complianceClient) ListAssignmentsByConnection(ctx *httpclient.Context, connectionID string) ([]compliance.AssignedBenchmark, error) {
+	url := fmt.Sprintf("%s/api/v1/assignments/connection/%s", s.baseURL, connectionID)
+
+	var response []compliance.AssignedBenchmark
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodGet, url, ctx.ToHeaders(), nil, &response); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
 func (s *complianceClient) PurgeSampleData(ctx *httpclient.Context) error {
 	url := fmt.Sprintf("%s/api/v3/sample/purge", s.baseURL)
 
@@ -425,18 +443,18 @@ func (s *complianceClient) GetAccountsFindingsSummary(ctx *httpclient.Context, b
 			} else {
 				url += "&"
 			}
-			url += fmt.Sprintf("connectionId=%v", &connectionId)
+			url += fmt.Sprintf("connectionId=%s", connectionId)
 		}
 	}
 
-	if connector != nil {
+	for _, c := range connector {
 		if !firstParamAttached {
 			url += "?"
 			firstParamAttached = true
 		} else {
 			url += "&"
 		}
-		url += fmt.Sprintf("connector=%v", &connector)
+		url += fmt.Sprintf("connector=%s", c.String())
 	}
 
 	var res compliance.GetAccountsFindingsSummaryResponse
@@ -461,3 +479,59 @@ func (s *complianceClient) CreateBenchmarkAssignment(ctx *httpclient.Context, be
 	}
 	return assignments, nil
 }
+
+func (s *complianceClient) ListActiveFindingExceptions(ctx *httpclient.Context) ([]compliance.FindingException, error) {
+	url := fmt.Sprintf("%s/api/v1/finding_exceptions?activeOnly=true", s.baseURL)
+
+	var response []compliance.FindingException
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodGet, url, ctx.ToHeaders(), nil, &response); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *complianceClient) ListOwnershipRules(ctx *httpclient.Context) ([]compliance.OwnershipRule, error) {
+	url := fmt.Sprintf("%s/api/v1/ownership_rules", s.baseURL)
+
+	var response []compliance.OwnershipRule
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodGet, url, ctx.ToHeaders(), nil, &response); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *complianceClient) GetSecurityScoreWeights(ctx *httpclient.Context, benchmarkID string) (*compliance.GetSecurityScoreWeightsResponse, error) {
+	url := fmt.Sprintf("%s/api/v1/security_score/weights?benchmarkId=%s", s.baseURL, benchmarkID)
+
+	var response compliance.GetSecurityScoreWeightsResponse
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodGet, url, ctx.ToHeaders(), nil, &response); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *complianceClient) DispatchComplianceJobNotifications(ctx *httpclient.Context, complianceJobID uint, runnerIDs []uint) error {
+	url := fmt.Sprintf("%s/api/v1/notifications/compliance_jobs/%d/dispatch", s.baseURL, complianceJobID)
+
+	payload, err := json.Marshal(compliance.DispatchComplianceJobNotificationsRequest{RunnerIDs: runnerIDs})
+	if err != nil {
+		return err
+	}
+
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodPost, url, ctx.ToHeaders(), payload, nil); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return echo.NewHTTPError(statusCode, err.Error())
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package compliance
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimCustomQuery(t *testing.T) {
+	assert.Equal(t, "select 1", trimCustomQuery("  select 1;\n"))
+	assert.Equal(t, "select 1", trimCustomQuery("select 1 ; ;\t"))
+	assert.Equal(t, "", trimCustomQuery(" ;\n"))
+}
+
+func TestMissingRequiredColumns(t *testing.T) {
+	assert.Empty(t, missingRequiredColumns([]string{"kaytu_account_id", "resource", "reason", "status", "title"}))
+	assert.Equal(t, []string{"status", "kaytu_account_id"}, missingRequiredColumns([]string{"resource", "reason"}))
+	assert.Equal(t, customQueryRequiredColumns, missingRequiredColumns(nil))
+}
+
+func TestNewCustomQuery(t *testing.T) {
+	query := newCustomQuery("custom_ebs", []string{"AWS"}, api.CustomQuery{
+		QueryToExecute: "select 1",
+		ListOfTables:   []string{"aws_ebs_volume"},
+		Engine:         "",
+		Parameters:     []api.QueryParameter{{Key: "awsRegion", Required: true}},
+		Global:         true,
+	})
+
+	assert.Equal(t, "custom_ebs", query.ID)
+	assert.Equal(t, api.ContentSourceCustom, query.Source)
+	assert.Equal(t, string(api.QueryEngine_OdysseusSQL), query.Engine, "custom queries always run on the sql engine")
+	assert.Equal(t, []string{"AWS"}, []string(query.Connector))
+	assert.True(t, query.Global)
+	assert.Equal(t, []db.QueryParameter{{QueryID: "custom_ebs", Key: "awsRegion", Required: true}}, query.Parameters)
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/jackc/pgtype"
 	"github.com/kaytu-io/kaytu-util/pkg/model"
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -25,11 +27,27 @@ func (db Database) Initialize(ctx context.Context) error {
 		&Benchmark{},
 		&BenchmarkTag{},
 		&BenchmarkAssignment{},
+		&FindingException{},
+		&OwnershipRule{},
+		&FindingSLA{},
+		&SeverityScoreWeight{},
+		&BenchmarkControlWeight{},
+		&NotificationSubscription{},
+		&NotificationDelivery{},
 	)
 	if err != nil {
 		return err
 	}
 
+	// rows written before the source column existed all come from the content repository
+	for _, m := range []any{&Query{}, &Control{}, &Benchmark{}} {
+		err = db.Orm.WithContext(ctx).Model(m).Where("source IS NULL OR source = ''").
+			Update("source", api.ContentSourceBuiltIn).Error
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -890,6 +908,14 @@ func (db Database) UpdateBenchmarkTrackDriftEvents(ctx context.Context, benchmar
 	return nil
 }
 
+func (db Database) UpdateBenchmarkCapturesEvidence(ctx context.Context, benchmarkId string, capturesEvidence bool) error {
+	tx := db.Orm.WithContext(ctx).Model(&Benchmark{}).Where("id = ?", benchmarkId).Update("captures_evidence", capturesEvidence)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
 func (db Database) ListControlsUniqueConnectors(ctx context.Context) ([]string, error) {
 	var connectors []string
 
@@ -974,3 +1000,535 @@ func (db Database) GetQueryParameters(ctx context.Context) ([]string, error) {
 
 	return parameters, nil
 }
+
+// =========== FindingException ===========
+
+func (db Database) CreateFindingException(ctx context.Context, exception *FindingException) error {
+	tx := db.Orm.WithContext(ctx).Create(exception)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetFindingException(ctx context.Context, id uint) (*FindingException, error) {
+	var exception FindingException
+	tx := db.Orm.WithContext(ctx).Model(&FindingException{}).Where("id = ?", id).First(&exception)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &exception, nil
+}
+
+// ListFindingExceptions returns exceptions filtered by scope, expired ones are left out when activeOnly is set.
+// Exceptions without a value for a filtered scope apply to every value of it, so they are returned as well.
+func (db Database) ListFindingExceptions(ctx context.Context, benchmarkIDs, controlIDs, connectionIDs []string, activeOnly bool) ([]FindingException, error) {
+	var exceptions []FindingException
+	tx := db.Orm.WithContext(ctx).Model(&FindingException{})
+	if len(benchmarkIDs) > 0 {
+		tx = tx.Where("(benchmark_id IN ? OR benchmark_id IS NULL OR benchmark_id = '')", benchmarkIDs)
+	}
+	if len(controlIDs) > 0 {
+		tx = tx.Where("(control_id IN ? OR control_id IS NULL OR control_id = '')", controlIDs)
+	}
+	if len(connectionIDs) > 0 {
+		tx = tx.Where("(connection_id IN ? OR connection_id IS NULL OR connection_id = '')", connectionIDs)
+	}
+	if activeOnly {
+		tx = tx.Where("(expires_at IS NULL OR expires_at > now())")
+	}
+	tx = tx.Order("id ASC").Find(&exceptions)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return exceptions, nil
+}
+
+func (db Database) UpdateFindingException(ctx context.Context, exception *FindingException) error {
+	tx := db.Orm.WithContext(ctx).Model(&FindingException{}).Where("id = ?", exception.ID).
+		Select("justification", "approver", "expires_at", "updated_at").
+		Updates(exception)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) DeleteFindingException(ctx context.Context, id uint) error {
+	tx := db.Orm.WithContext(ctx).Where("id = ?", id).Delete(&FindingException{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// =========== OwnershipRule ===========
+
+func (db Database) CreateOwnershipRule(ctx context.Context, rule *OwnershipRule) error {
+	tx := db.Orm.WithContext(ctx).Create(rule)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetOwnershipRule(ctx context.Context, id uint) (*OwnershipRule, error) {
+	var rule OwnershipRule
+	tx := db.Orm.WithContext(ctx).Model(&OwnershipRule{}).Where("id = ?", id).First(&rule)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &rule, nil
+}
+
+// ListOwnershipRules returns rules in evaluation order, by priority then by creation order
+func (db Database) ListOwnershipRules(ctx context.Context, owners []string) ([]OwnershipRule, error) {
+	var rules []OwnershipRule
+	tx := db.Orm.WithContext(ctx).Model(&OwnershipRule{})
+	if len(owners) > 0 {
+		tx = tx.Where("owner IN ?", owners)
+	}
+	tx = tx.Order("priority ASC").Order("id ASC").Find(&rules)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return rules, nil
+}
+
+func (db Database) UpdateOwnershipRule(ctx context.Context, rule *OwnershipRule) error {
+	tx := db.Orm.WithContext(ctx).Model(&OwnershipRule{}).Where("id = ?", rule.ID).
+		Select("owner", "priority", "updated_at").
+		Updates(rule)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) DeleteOwnershipRule(ctx context.Context, id uint) error {
+	tx := db.Orm.WithContext(ctx).Where("id = ?", id).Delete(&OwnershipRule{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// =========== FindingSLA ===========
+
+func (db Database) ListFindingSLAs(ctx context.Context) ([]FindingSLA, error) {
+	var slas []FindingSLA
+	tx := db.Orm.WithContext(ctx).Model(&FindingSLA{}).Find(&slas)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return slas, nil
+}
+
+func (db Database) UpsertFindingSLAs(ctx context.Context, slas []FindingSLA) error {
+	if len(slas) == 0 {
+		return nil
+	}
+	tx := db.Orm.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "severity"}},
+		DoUpdates: clause.AssignmentColumns([]string{"days", "updated_at"}),
+	}).Create(&slas)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// =========== SecurityScoreWeight ===========
+
+func (db Database) ListSeverityScoreWeights(ctx context.Context) ([]SeverityScoreWeight, error) {
+	var weights []SeverityScoreWeight
+	tx := db.Orm.WithContext(ctx).Model(&SeverityScoreWeight{}).Find(&weights)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return weights, nil
+}
+
+func (db Database) UpsertSeverityScoreWeights(ctx context.Context, weights []SeverityScoreWeight) error {
+	if len(weights) == 0 {
+		return nil
+	}
+	tx := db.Orm.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "severity"}},
+		DoUpdates: clause.AssignmentColumns([]string{"weight", "updated_at"}),
+	}).Create(&weights)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) ListBenchmarkControlWeights(ctx context.Context, benchmarkID string) ([]BenchmarkControlWeight, error) {
+	var weights []BenchmarkControlWeight
+	tx := db.Orm.WithContext(ctx).Model(&BenchmarkControlWeight{}).Where("benchmark_id = ?", benchmarkID).
+		Order("control_id ASC").Find(&weights)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return weights, nil
+}
+
+func (db Database) UpsertBenchmarkControlWeights(ctx context.Context, weights []BenchmarkControlWeight) error {
+	if len(weights) == 0 {
+		return nil
+	}
+	tx := db.Orm.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "benchmark_id"}, {Name: "control_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"weight", "updated_at"}),
+	}).Create(&weights)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) DeleteBenchmarkControlWeights(ctx context.Context, benchmarkID string, controlIDs []string) error {
+	if len(controlIDs) == 0 {
+		return nil
+	}
+	tx := db.Orm.WithContext(ctx).Where("benchmark_id = ? AND control_id IN ?", benchmarkID, controlIDs).
+		Delete(&BenchmarkControlWeight{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// =========== Notification ===========
+
+func (db Database) CreateNotificationSubscription(ctx context.Context, subscription *NotificationSubscription) error {
+	tx := db.Orm.WithContext(ctx).Create(subscription)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetNotificationSubscription(ctx context.Context, id uint) (*NotificationSubscription, error) {
+	var subscription NotificationSubscription
+	tx := db.Orm.WithContext(ctx).Model(&NotificationSubscription{}).Where("id = ?", id).First(&subscription)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &subscription, nil
+}
+
+func (db Database) ListNotificationSubscriptions(ctx context.Context, enabledOnly bool) ([]NotificationSubscription, error) {
+	var subscriptions []NotificationSubscription
+	tx := db.Orm.WithContext(ctx).Model(&NotificationSubscription{})
+	if enabledOnly {
+		tx = tx.Where("enabled = ?", true)
+	}
+	tx = tx.Order("id ASC").Find(&subscriptions)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return subscriptions, nil
+}
+
+func (db Database) UpdateNotificationSubscription(ctx context.Context, subscription *NotificationSubscription) error {
+	tx := db.Orm.WithContext(ctx).Model(&NotificationSubscription{}).Where("id = ?", subscription.ID).
+		Select("name", "url", "benchmark_ids", "control_ids", "severities", "connection_ids", "transitions", "enabled", "updated_at").
+		Updates(subscription)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// DeleteNotificationSubscription deletes the subscription and its deliveries, gorm.ErrRecordNotFound if it does not exist
+func (db Database) DeleteNotificationSubscription(ctx context.Context, id uint) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&NotificationDelivery{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("id = ?", id).Delete(&NotificationSubscription{})
+		if res.Error != nil {
+			return res.Error
+		} else if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (db Database) CreateNotificationDelivery(ctx context.Context, delivery *NotificationDelivery) error {
+	tx := db.Orm.WithContext(ctx).Create(delivery)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) UpdateNotificationDelivery(ctx context.Context, delivery *NotificationDelivery) error {
+	tx := db.Orm.WithContext(ctx).Model(&NotificationDelivery{}).Where("id = ?", delivery.ID).
+		Select("attempts", "status_code", "succeeded", "error", "delivered_at").
+		Updates(delivery)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) ListNotificationDeliveries(ctx context.Context, subscriptionID uint, limit int) ([]NotificationDelivery, error) {
+	var deliveries []NotificationDelivery
+	tx := db.Orm.WithContext(ctx).Model(&NotificationDelivery{}).Where("subscription_id = ?", subscriptionID).
+		Order("id DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	tx = tx.Find(&deliveries)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return deliveries, nil
+}
+
+// =========== Custom content ===========
+
+// CreateCustomControl creates the control, its query is created along with it when control.Query is set
+func (db Database) CreateCustomControl(ctx context.Context, control *Control) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := control.Query
+		control.Query = nil
+		if query != nil {
+			if err := createCustomQuery(tx, query); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("Tags", "Benchmarks").Create(control).Error; err != nil {
+			return err
+		}
+		if len(control.Tags) > 0 {
+			if err := tx.Create(&control.Tags).Error; err != nil {
+				return err
+			}
+		}
+		control.Query = query
+		return nil
+	})
+}
+
+func createCustomQuery(tx *gorm.DB, query *Query) error {
+	if err := tx.Omit("Parameters", "Controls").Create(query).Error; err != nil {
+		return err
+	}
+	if len(query.Parameters) > 0 {
+		if err := tx.Create(&query.Parameters).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func updateCustomQuery(tx *gorm.DB, query *Query) error {
+	if err := tx.Where("query_id = ?", query.ID).Delete(&QueryParameter{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&Query{}).Where("id = ? AND source = ?", query.ID, api.ContentSourceCustom).
+		Select("query_to_execute", "connector", "primary_table", "list_of_tables", "engine", "global", "updated_at").
+		Updates(query).Error; err != nil {
+		return err
+	}
+	if len(query.Parameters) > 0 {
+		if err := tx.Create(&query.Parameters).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db Database) CreateCustomQuery(ctx context.Context, query *Query) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createCustomQuery(tx, query)
+	})
+}
+
+func (db Database) UpdateCustomQuery(ctx context.Context, query *Query) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateCustomQuery(tx, query)
+	})
+}
+
+// DeleteCustomQuery deletes a custom query which is not used by any control
+func (db Database) DeleteCustomQuery(ctx context.Context, queryID string) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("query_id = ?", queryID).Delete(&QueryParameter{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND source = ?", queryID, api.ContentSourceCustom).Delete(&Query{}).Error
+	})
+}
+
+func (db Database) ListControlIDsByQueryID(ctx context.Context, queryID string) ([]string, error) {
+	var controlIDs []string
+	tx := db.Orm.WithContext(ctx).Model(&Control{}).
+		Where("query_id = ?", queryID).
+		Pluck("id", &controlIDs)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return controlIDs, nil
+}
+
+// UpdateCustomControl updates the control columns and its query id, the tags and query are replaced when not nil
+func (db Database) UpdateCustomControl(ctx context.Context, control *Control, tags []ControlTag, query *Query) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Control{}).Where("id = ? AND source = ?", control.ID, api.ContentSourceCustom).
+			Select("title", "description", "severity", "document_uri", "enabled", "connector", "query_id", "updated_at").
+			Updates(control).Error; err != nil {
+			return err
+		}
+		if tags != nil {
+			if err := tx.Where("control_id = ?", control.ID).Delete(&ControlTag{}).Error; err != nil {
+				return err
+			}
+			if len(tags) > 0 {
+				if err := tx.Create(&tags).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if query != nil {
+			return updateCustomQuery(tx, query)
+		}
+		return nil
+	})
+}
+
+func (db Database) DeleteCustomControl(ctx context.Context, controlID string) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var control Control
+		if err := tx.Where("id = ? AND source = ?", controlID, api.ContentSourceCustom).First(&control).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("control_id = ?", controlID).Delete(&BenchmarkControls{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("control_id = ?", controlID).Delete(&ControlTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", controlID).Delete(&Control{}).Error; err != nil {
+			return err
+		}
+		// the query goes with the control it was created with, unless another control uses it too
+		if control.QueryID == nil || *control.QueryID != control.ID {
+			return nil
+		}
+		var users int64
+		if err := tx.Model(&Control{}).Where("query_id = ?", *control.QueryID).Count(&users).Error; err != nil {
+			return err
+		}
+		if users > 0 {
+			return nil
+		}
+		if err := tx.Where("query_id = ?", *control.QueryID).Delete(&QueryParameter{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ? AND source = ?", *control.QueryID, api.ContentSourceCustom).Delete(&Query{}).Error
+	})
+}
+
+func (db Database) CreateCustomBenchmark(ctx context.Context, benchmark *Benchmark, childIDs, controlIDs []string) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags", "Children", "Controls").Create(benchmark).Error; err != nil {
+			return err
+		}
+		if len(benchmark.Tags) > 0 {
+			if err := tx.Create(&benchmark.Tags).Error; err != nil {
+				return err
+			}
+		}
+		return replaceBenchmarkLinks(tx, benchmark.ID, childIDs, controlIDs)
+	})
+}
+
+// UpdateCustomBenchmark updates the benchmark columns, the tags, children and controls are replaced when not nil
+func (db Database) UpdateCustomBenchmark(ctx context.Context, benchmark *Benchmark, tags []BenchmarkTag, childIDs, controlIDs []string) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Benchmark{}).Where("id = ? AND source = ?", benchmark.ID, api.ContentSourceCustom).
+			Select("title", "display_code", "description", "document_uri", "connector", "updated_at").
+			Updates(benchmark).Error; err != nil {
+			return err
+		}
+		if tags != nil {
+			if err := tx.Where("benchmark_id = ?", benchmark.ID).Delete(&BenchmarkTag{}).Error; err != nil {
+				return err
+			}
+			if len(tags) > 0 {
+				if err := tx.Create(&tags).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if childIDs != nil {
+			if err := tx.Where("benchmark_id = ?", benchmark.ID).Delete(&BenchmarkChild{}).Error; err != nil {
+				return err
+			}
+		}
+		if controlIDs != nil {
+			if err := tx.Where("benchmark_id = ?", benchmark.ID).Delete(&BenchmarkControls{}).Error; err != nil {
+				return err
+			}
+		}
+		return replaceBenchmarkLinks(tx, benchmark.ID, childIDs, controlIDs)
+	})
+}
+
+func replaceBenchmarkLinks(tx *gorm.DB, benchmarkID string, childIDs, controlIDs []string) error {
+	for _, childID := range childIDs {
+		if err := tx.Create(&BenchmarkChild{BenchmarkID: benchmarkID, ChildID: childID}).Error; err != nil {
+			return err
+		}
+	}
+	for _, controlID := range controlIDs {
+		if err := tx.Create(&BenchmarkControls{BenchmarkID: benchmarkID, ControlID: controlID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db Database) UpdateBenchmarkMetadata(ctx context.Context, benchmarkID string, metadata pgtype.JSONB) error {
+	tx := db.Orm.WithContext(ctx).Model(&Benchmark{}).Where("id = ?", benchmarkID).Update("metadata", metadata)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) DeleteCustomBenchmark(ctx context.Context, benchmarkID string) error {
+	return db.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var benchmark Benchmark
+		if err := tx.Where("id = ? AND source = ?", benchmarkID, api.ContentSourceCustom).First(&benchmark).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("benchmark_id = ? OR child_id = ?", benchmarkID, benchmarkID).Delete(&BenchmarkChild{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("benchmark_id = ?", benchmarkID).Delete(&BenchmarkControls{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("benchmark_id = ?", benchmarkID).Delete(&BenchmarkTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("benchmark_id = ?", benchmarkID).Delete(&BenchmarkAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", benchmarkID).Delete(&Benchmark{}).Error
+	})
+}
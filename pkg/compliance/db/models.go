@@ -54,6 +54,8 @@ type Benchmark struct {
 	Enabled           bool
 	AutoAssign        bool
 	TracksDriftEvents bool
+	CapturesEvidence  bool
+	Source            api.ContentSource `gorm:"default:built-in"`
 	Metadata          pgtype.JSONB
 
 	Tags    []BenchmarkTag      `gorm:"foreignKey:BenchmarkID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
@@ -76,6 +78,8 @@ func (b Benchmark) ToApi() api.Benchmark {
 		DocumentURI:       b.DocumentURI,
 		AutoAssign:        b.AutoAssign,
 		TracksDriftEvents: b.TracksDriftEvents,
+		CapturesEvidence:  b.CapturesEvidence,
+		Source:            b.Source,
 		CreatedAt:         b.CreatedAt,
 		UpdatedAt:         b.UpdatedAt,
 		Tags:              b.GetTagsMap(),
@@ -153,7 +157,8 @@ type Control struct {
 	Benchmarks         []Benchmark `gorm:"many2many:benchmark_controls;"`
 	Severity           types.FindingSeverity
 	ManualVerification bool
-	Managed            bool
+	Managed            bool              // Managed flag of the content repository, built-in controls can have it false too so Source tells custom controls apart
+	Source             api.ContentSource `gorm:"default:built-in"`
 	CreatedAt          time.Time
 	UpdatedAt          time.Time
 }
@@ -173,6 +178,7 @@ func (p Control) ToApi() api.Control {
 		Severity:           p.Severity,
 		ManualVerification: p.ManualVerification,
 		Managed:            p.Managed,
+		Source:             p.Source,
 		CreatedAt:          p.CreatedAt,
 		UpdatedAt:          p.UpdatedAt,
 	}
@@ -289,6 +295,7 @@ type Query struct {
 	Controls       []Control        `gorm:"foreignKey:QueryID"`
 	Parameters     []QueryParameter `gorm:"foreignKey:QueryID"`
 	Global         bool
+	Source         api.ContentSource `gorm:"default:built-in"`
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
@@ -303,6 +310,7 @@ func (q Query) ToApi() api.Query {
 		Engine:         q.Engine,
 		Parameters:     make([]api.QueryParameter, 0, len(q.Parameters)),
 		Global:         q.Global,
+		Source:         q.Source,
 		CreatedAt:      q.CreatedAt,
 		UpdatedAt:      q.UpdatedAt,
 	}
@@ -311,3 +319,152 @@ func (q Query) ToApi() api.Query {
 	}
 	return query
 }
+
+type FindingException struct {
+	ID              uint    `gorm:"primarykey"`
+	BenchmarkID     *string `gorm:"index"`
+	ControlID       *string `gorm:"index"`
+	ConnectionID    *string `gorm:"index"`
+	KaytuResourceID *string
+	TagKey          *string
+	TagValue        *string
+	Justification   string `gorm:"not null"`
+	Approver        string `gorm:"not null"`
+	CreatedBy       string
+	ExpiresAt       *time.Time `gorm:"index"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (e FindingException) ToApi() api.FindingException {
+	fe := api.FindingException{
+		ID:              e.ID,
+		BenchmarkID:     e.BenchmarkID,
+		ControlID:       e.ControlID,
+		ConnectionID:    e.ConnectionID,
+		KaytuResourceID: e.KaytuResourceID,
+		TagKey:          e.TagKey,
+		TagValue:        e.TagValue,
+		Justification:   e.Justification,
+		Approver:        e.Approver,
+		CreatedBy:       e.CreatedBy,
+		ExpiresAt:       e.ExpiresAt,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+	}
+	fe.Active = fe.IsActiveAt(time.Now())
+	return fe
+}
+
+type OwnershipRule struct {
+	ID           uint   `gorm:"primarykey"`
+	Owner        string `gorm:"not null;index"`
+	Priority     int    `gorm:"not null;default:0"`
+	TagKey       *string
+	TagValue     *string
+	ConnectionID *string `gorm:"index"`
+	ResourceType *string
+	CreatedBy    string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (r OwnershipRule) ToApi() api.OwnershipRule {
+	return api.OwnershipRule{
+		ID:           r.ID,
+		Owner:        r.Owner,
+		Priority:     r.Priority,
+		TagKey:       r.TagKey,
+		TagValue:     r.TagValue,
+		ConnectionID: r.ConnectionID,
+		ResourceType: r.ResourceType,
+		CreatedBy:    r.CreatedBy,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}
+}
+
+type FindingSLA struct {
+	Severity  types.FindingSeverity `gorm:"primarykey"`
+	Days      int                   `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+type SeverityScoreWeight struct {
+	Severity  types.FindingSeverity `gorm:"primarykey"`
+	Weight    float64               `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+type BenchmarkControlWeight struct {
+	BenchmarkID string  `gorm:"primarykey"`
+	ControlID   string  `gorm:"primarykey"`
+	Weight      float64 `gorm:"not null"`
+	UpdatedAt   time.Time
+}
+
+type NotificationSubscription struct {
+	ID            uint           `gorm:"primarykey"`
+	Name          string         `gorm:"not null"`
+	URL           string         `gorm:"not null"`
+	Secret        string         `gorm:"not null"`
+	BenchmarkIDs  pq.StringArray `gorm:"type:text[]"`
+	ControlIDs    pq.StringArray `gorm:"type:text[]"`
+	Severities    pq.StringArray `gorm:"type:text[]"`
+	ConnectionIDs pq.StringArray `gorm:"type:text[]"`
+	Transitions   pq.StringArray `gorm:"type:text[]"`
+	Enabled       bool
+	CreatedBy     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (s NotificationSubscription) ToApi() api.NotificationSubscription {
+	sub := api.NotificationSubscription{
+		ID:            s.ID,
+		Name:          s.Name,
+		URL:           s.URL,
+		BenchmarkIDs:  s.BenchmarkIDs,
+		ControlIDs:    s.ControlIDs,
+		ConnectionIDs: s.ConnectionIDs,
+		Enabled:       s.Enabled,
+		CreatedBy:     s.CreatedBy,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+	for _, severity := range s.Severities {
+		sub.Severities = append(sub.Severities, types.ParseFindingSeverity(severity))
+	}
+	for _, transition := range s.Transitions {
+		sub.Transitions = append(sub.Transitions, api.NotificationTransition(transition))
+	}
+	return sub
+}
+
+type NotificationDelivery struct {
+	ID              uint `gorm:"primarykey"`
+	SubscriptionID  uint `gorm:"index"`
+	ComplianceJobID uint `gorm:"index"`
+	EventCount      int
+	Attempts        int
+	StatusCode      int
+	Succeeded       bool
+	Error           string
+	CreatedAt       time.Time
+	DeliveredAt     *time.Time
+}
+
+func (d NotificationDelivery) ToApi() api.NotificationDelivery {
+	return api.NotificationDelivery{
+		ID:              d.ID,
+		SubscriptionID:  d.SubscriptionID,
+		ComplianceJobID: d.ComplianceJobID,
+		EventCount:      d.EventCount,
+		Attempts:        d.Attempts,
+		StatusCode:      d.StatusCode,
+		Succeeded:       d.Succeeded,
+		Error:           d.Error,
+		CreatedAt:       d.CreatedAt,
+		DeliveredAt:     d.DeliveredAt,
+	}
+}
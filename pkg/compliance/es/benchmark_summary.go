@@ -20,6 +20,7 @@ type BenchmarkTrendDatapoint struct {
 	QueryResult    map[types.ConformanceStatus]int
 	SeverityResult map[types.FindingSeverity]int
 	Controls       map[string]types2.ControlResult
+	Weighted       types2.WeightedScore
 }
 
 func (t *BenchmarkTrendDatapoint) addResultGroupToTrendDataPoint(resultGroup types2.ResultGroup) {
@@ -29,6 +30,7 @@ func (t *BenchmarkTrendDatapoint) addResultGroupToTrendDataPoint(resultGroup typ
 	for k, v := range resultGroup.Result.SeverityResult {
 		t.SeverityResult[k] += v
 	}
+	t.Weighted.Add(resultGroup.Result.Weighted)
 	for controlId, control := range resultGroup.Controls {
 		if _, ok := t.Controls[controlId]; !ok {
 			t.Controls[controlId] = types2.ControlResult{
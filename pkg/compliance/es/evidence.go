@@ -0,0 +1,81 @@
+package es
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type ComplianceEvidenceQueryResponse struct {
+	Hits struct {
+		Total kaytu.SearchTotal `json:"total"`
+		Hits  []struct {
+			ID      string                   `json:"_id"`
+			Score   float64                  `json:"_score"`
+			Index   string                   `json:"_index"`
+			Type    string                   `json:"_type"`
+			Version int64                    `json:"_version,omitempty"`
+			Source  types.ComplianceEvidence `json:"_source"`
+			Sort    []any                    `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+	PitID string `json:"pit_id"`
+}
+
+type ComplianceEvidencePaginator struct {
+	paginator *kaytu.BaseESPaginator
+}
+
+// NewComplianceEvidencePaginator walks the evidence captured by the runners of a benchmark compliance job,
+// limited to the given connections if any
+func NewComplianceEvidencePaginator(client kaytu.Client, benchmarkID string, complianceJobID uint, connectionIDs []string) (ComplianceEvidencePaginator, error) {
+	filters := []kaytu.BoolFilter{
+		kaytu.NewTermFilter("benchmarkID", benchmarkID),
+		kaytu.NewTermFilter("parentComplianceJobID", fmt.Sprintf("%d", complianceJobID)),
+	}
+	if len(connectionIDs) > 0 {
+		filters = append(filters, kaytu.NewTermsFilter("connectionID", connectionIDs))
+	}
+	paginator, err := kaytu.NewPaginatorWithSort(client.ES(), types.ComplianceEvidenceIndex, filters, nil, []map[string]any{
+		{"evaluatedAt": "asc"},
+	})
+	if err != nil {
+		return ComplianceEvidencePaginator{}, err
+	}
+
+	return ComplianceEvidencePaginator{
+		paginator: paginator,
+	}, nil
+}
+
+func (p ComplianceEvidencePaginator) HasNext() bool {
+	return !p.paginator.Done()
+}
+
+func (p ComplianceEvidencePaginator) Close(ctx context.Context) error {
+	return p.paginator.Deallocate(ctx)
+}
+
+func (p ComplianceEvidencePaginator) NextPage(ctx context.Context) ([]types.ComplianceEvidence, error) {
+	var response ComplianceEvidenceQueryResponse
+	err := p.paginator.SearchWithLog(ctx, &response, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []types.ComplianceEvidence
+	for _, hit := range response.Hits.Hits {
+		values = append(values, hit.Source)
+	}
+
+	hits := int64(len(response.Hits.Hits))
+	if hits > 0 {
+		p.paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+	} else {
+		p.paginator.UpdateState(hits, nil, "")
+	}
+
+	return values, nil
+}
@@ -221,13 +221,7 @@ func FindingsCountByControlID(ctx context.Context, logger *zap.Logger, client ka
 	return controlIDCount, nil
 }
 
-func FindingsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client, resourceIDs []string, provider []source.Type,
-	connectionID []string, notConnectionID []string, resourceTypes []string, benchmarkID []string, controlID []string,
-	severity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time,
-	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool, conformanceStatuses []types.ConformanceStatus,
-	sorts []api.FindingsSort, pageSizeLimit int, searchAfter []any, jobIDs []string) ([]FindingsQueryHit, int64, error) {
-	idx := types.FindingsIndex
-
+func findingsRequestSort(sorts []api.FindingsSort) []map[string]any {
 	requestSort := make([]map[string]any, 0, len(sorts)+1)
 	for _, sort := range sorts {
 		switch {
@@ -315,10 +309,15 @@ func FindingsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client,
 			})
 		}
 	}
-	requestSort = append(requestSort, map[string]any{
-		"_id": "asc",
-	})
 
+	return requestSort
+}
+
+func findingsQueryFilters(resourceIDs []string, provider []source.Type,
+	connectionID []string, notConnectionID []string, resourceTypes []string, benchmarkID []string, controlID []string,
+	severity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time,
+	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool, conformanceStatuses []types.ConformanceStatus,
+	jobIDs []string, owners []string) []kaytu.BoolFilter {
 	var filters []kaytu.BoolFilter
 	if len(resourceIDs) > 0 {
 		filters = append(filters, kaytu.NewTermsFilter("resourceID", resourceIDs))
@@ -335,6 +334,9 @@ func FindingsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client,
 	if len(jobIDs) > 0 {
 		filters = append(filters, kaytu.NewTermsFilter("parentComplianceJobID", jobIDs))
 	}
+	if len(owners) > 0 {
+		filters = append(filters, kaytu.NewTermsFilter("owner", owners))
+	}
 	if len(severity) > 0 {
 		strSeverity := make([]string, 0)
 		for _, s := range severity {
@@ -396,6 +398,24 @@ func FindingsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client,
 			"", fmt.Sprintf("%d", evaluatedAtTo.UnixMilli())))
 	}
 
+	return filters
+}
+
+func FindingsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client, resourceIDs []string, provider []source.Type,
+	connectionID []string, notConnectionID []string, resourceTypes []string, benchmarkID []string, controlID []string,
+	severity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time,
+	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool, conformanceStatuses []types.ConformanceStatus,
+	sorts []api.FindingsSort, pageSizeLimit int, searchAfter []any, jobIDs []string, owners []string) ([]FindingsQueryHit, int64, error) {
+	idx := types.FindingsIndex
+
+	requestSort := findingsRequestSort(sorts)
+	requestSort = append(requestSort, map[string]any{
+		"_id": "asc",
+	})
+
+	filters := findingsQueryFilters(resourceIDs, provider, connectionID, notConnectionID, resourceTypes, benchmarkID, controlID,
+		severity, lastTransitionFrom, lastTransitionTo, evaluatedAtFrom, evaluatedAtTo, stateActive, conformanceStatuses, jobIDs, owners)
+
 	query := make(map[string]any)
 	if len(filters) > 0 {
 		query["query"] = map[string]any{
@@ -428,6 +448,19 @@ func FindingsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client,
 	return response.Hits.Hits, response.Hits.Total.Value, err
 }
 
+// NewFindingsQueryPaginator walks every finding matching the same filters as FindingsQuery, used for exports
+// where the whole result set is streamed page by page instead of loaded into memory
+func NewFindingsQueryPaginator(client kaytu.Client, resourceIDs []string, provider []source.Type,
+	connectionID []string, notConnectionID []string, resourceTypes []string, benchmarkID []string, controlID []string,
+	severity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time,
+	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool, conformanceStatuses []types.ConformanceStatus,
+	sorts []api.FindingsSort, jobIDs []string, owners []string) (FindingPaginator, error) {
+	filters := findingsQueryFilters(resourceIDs, provider, connectionID, notConnectionID, resourceTypes, benchmarkID, controlID,
+		severity, lastTransitionFrom, lastTransitionTo, evaluatedAtFrom, evaluatedAtTo, stateActive, conformanceStatuses, jobIDs, owners)
+
+	return NewFindingPaginator(client, types.FindingsIndex, filters, nil, findingsRequestSort(sorts))
+}
+
 type FindingsCountResponse struct {
 	Hits  FindingsCountHits `json:"hits"`
 	PitID string            `json:"pit_id"`
@@ -716,6 +749,117 @@ func FindingKPIQuery(ctx context.Context, logger *zap.Logger, client kaytu.Clien
 	return &resp, err
 }
 
+// FindingSLAOverdueFilter matches active failing findings that started failing before the SLA window of their severity,
+// or every other finding if overdue is false
+func FindingSLAOverdueFilter(policy api.FindingSLAPolicy, overdue bool, now time.Time) kaytu.BoolFilter {
+	if !overdue {
+		return kaytu.NewBoolMustNotFilter(FindingSLAOverdueFilter(policy, true, now))
+	}
+
+	var severityFilters []kaytu.BoolFilter
+	for _, severity := range api.FindingSLASeverities {
+		cutoff := policy.OverdueCutoff(severity, now)
+		if cutoff == nil {
+			continue
+		}
+		severityFilters = append(severityFilters, kaytu.NewBoolMustFilter(
+			kaytu.NewTermFilter("severity", string(severity)),
+			kaytu.NewRangeFilter("lastTransition", "", "", fmt.Sprintf("%d", cutoff.UnixMilli()), ""),
+		))
+	}
+	if len(severityFilters) == 0 {
+		// no severity has an SLA so nothing can be overdue, an empty terms filter matches no document
+		return kaytu.NewTermsFilter("severity", []string{})
+	}
+
+	var failedStatuses []string
+	for _, status := range types.GetFailedConformanceStatuses() {
+		failedStatuses = append(failedStatuses, string(status))
+	}
+	return kaytu.NewBoolMustFilter(
+		kaytu.NewTermFilter("stateActive", "true"),
+		kaytu.NewTermsFilter("conformanceStatus", failedStatuses),
+		kaytu.NewBoolShouldFilter(severityFilters...),
+	)
+}
+
+type FindingSLAKPIResponse struct {
+	Aggregations struct {
+		Severities struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int64  `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"severities"`
+		Overdue struct {
+			Buckets map[string]struct {
+				DocCount int64 `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"overdue"`
+	} `json:"aggregations"`
+}
+
+// FindingSLAKPIQuery counts active failing findings per severity and how many of them are past their SLA
+func FindingSLAKPIQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client, policy api.FindingSLAPolicy, now time.Time) (*FindingSLAKPIResponse, error) {
+	root := make(map[string]any)
+	root["size"] = 0
+
+	root["query"] = map[string]any{
+		"bool": map[string]any{
+			"filter": []map[string]any{
+				{"term": map[string]any{"stateActive": true}},
+				{"terms": map[string]any{"conformanceStatus": types.GetFailedConformanceStatuses()}},
+			},
+		},
+	}
+
+	aggs := map[string]any{
+		"severities": map[string]any{
+			"terms": map[string]any{
+				"field": "severity",
+				"size":  len(api.FindingSLASeverities),
+			},
+		},
+	}
+	overdueFilters := make(map[string]any)
+	for _, severity := range api.FindingSLASeverities {
+		cutoff := policy.OverdueCutoff(severity, now)
+		if cutoff == nil {
+			continue
+		}
+		overdueFilters[string(severity)] = map[string]any{
+			"bool": map[string]any{
+				"filter": []map[string]any{
+					{"term": map[string]any{"severity": severity}},
+					{"range": map[string]any{"lastTransition": map[string]any{"lt": cutoff.UnixMilli()}}},
+				},
+			},
+		}
+	}
+	if len(overdueFilters) > 0 {
+		aggs["overdue"] = map[string]any{
+			"filters": map[string]any{
+				"filters": overdueFilters,
+			},
+		}
+	}
+	root["aggs"] = aggs
+
+	queryBytes, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("FindingSLAKPIQuery", zap.String("query", string(queryBytes)))
+	var resp FindingSLAKPIResponse
+	err = client.Search(ctx, types.FindingsIndex, string(queryBytes), &resp)
+	if err != nil {
+		logger.Error("FindingSLAKPIQuery", zap.Error(err), zap.String("query", string(queryBytes)))
+		return nil, err
+	}
+	return &resp, nil
+}
+
 type FindingsTopFieldResponse struct {
 	Aggregations struct {
 		FieldFilter struct {
@@ -1346,7 +1490,8 @@ func FindingsQueryV2(ctx context.Context, logger *zap.Logger, client kaytu.Clien
 	benchmarkID []string, notBenchmarkID []string, controlID []string, notControlID []string, severity []types.FindingSeverity,
 	notSeverity []types.FindingSeverity, lastTransitionFrom *time.Time, lastTransitionTo *time.Time, notLastTransitionFrom *time.Time,
 	notLastTransitionTo *time.Time, evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time, stateActive []bool,
-	conformanceStatuses []types.ConformanceStatus, sorts []api.FindingsSortV2, pageSizeLimit int, searchAfter []any) ([]FindingsQueryHit, int64, error) {
+	conformanceStatuses []types.ConformanceStatus, owners []string, overdue *bool, slaPolicy api.FindingSLAPolicy, sorts []api.FindingsSortV2,
+	pageSizeLimit int, searchAfter []any) ([]FindingsQueryHit, int64, error) {
 	idx := types.FindingsIndex
 
 	requestSort := make([]map[string]any, 0, len(sorts)+1)
@@ -1473,6 +1618,9 @@ func FindingsQueryV2(ctx context.Context, logger *zap.Logger, client kaytu.Clien
 	if len(connectionID) > 0 {
 		filters = append(filters, kaytu.NewTermsFilter("connectionID", connectionID))
 	}
+	if len(owners) > 0 {
+		filters = append(filters, kaytu.NewTermsFilter("owner", owners))
+	}
 	if len(notConnectionID) > 0 {
 		filters = append(filters, kaytu.NewBoolMustNotFilter(kaytu.NewTermsFilter("connectionID", notConnectionID)))
 	}
@@ -1529,6 +1677,9 @@ func FindingsQueryV2(ctx context.Context, logger *zap.Logger, client kaytu.Clien
 			"", "",
 			"", fmt.Sprintf("%d", evaluatedAtTo.UnixMilli())))
 	}
+	if overdue != nil {
+		filters = append(filters, FindingSLAOverdueFilter(slaPolicy, *overdue, time.Now()))
+	}
 
 	query := make(map[string]any)
 	if len(filters) > 0 {
@@ -74,16 +74,7 @@ func FetchFindingEventsByFindingIDs(ctx context.Context, logger *zap.Logger, cli
 	return result, nil
 }
 
-func FindingEventsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client,
-	findingIDs []string, kaytuResourceIDs []string,
-	provider []source.Type, connectionID []string, notConnectionID []string,
-	resourceTypes []string,
-	benchmarkID []string, controlID []string, severity []types.FindingSeverity,
-	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time,
-	stateActive []bool, conformanceStatuses []types.ConformanceStatus,
-	sorts []api.FindingEventsSort, pageSizeLimit int, searchAfter []any) ([]FindingEventsQueryHit, int64, error) {
-	idx := types.FindingEventsIndex
-
+func findingEventsRequestSort(sorts []api.FindingEventsSort) []map[string]any {
 	requestSort := make([]map[string]any, 0, len(sorts)+1)
 	for _, sort := range sorts {
 		switch {
@@ -168,10 +159,16 @@ func FindingEventsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Cl
 			})
 		}
 	}
-	requestSort = append(requestSort, map[string]any{
-		"_id": "asc",
-	})
 
+	return requestSort
+}
+
+func findingEventsQueryFilters(findingIDs []string, kaytuResourceIDs []string,
+	provider []source.Type, connectionID []string, notConnectionID []string,
+	resourceTypes []string,
+	benchmarkID []string, controlID []string, severity []types.FindingSeverity,
+	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time,
+	stateActive []bool, conformanceStatuses []types.ConformanceStatus) []kaytu.BoolFilter {
 	var filters []kaytu.BoolFilter
 	if len(findingIDs) > 0 {
 		filters = append(filters, kaytu.NewTermsFilter("findingEsID", findingIDs))
@@ -236,6 +233,27 @@ func FindingEventsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Cl
 			"", fmt.Sprintf("%d", evaluatedAtTo.UnixMilli())))
 	}
 
+	return filters
+}
+
+func FindingEventsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Client,
+	findingIDs []string, kaytuResourceIDs []string,
+	provider []source.Type, connectionID []string, notConnectionID []string,
+	resourceTypes []string,
+	benchmarkID []string, controlID []string, severity []types.FindingSeverity,
+	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time,
+	stateActive []bool, conformanceStatuses []types.ConformanceStatus,
+	sorts []api.FindingEventsSort, pageSizeLimit int, searchAfter []any) ([]FindingEventsQueryHit, int64, error) {
+	idx := types.FindingEventsIndex
+
+	requestSort := findingEventsRequestSort(sorts)
+	requestSort = append(requestSort, map[string]any{
+		"_id": "asc",
+	})
+
+	filters := findingEventsQueryFilters(findingIDs, kaytuResourceIDs, provider, connectionID, notConnectionID, resourceTypes,
+		benchmarkID, controlID, severity, evaluatedAtFrom, evaluatedAtTo, stateActive, conformanceStatuses)
+
 	query := make(map[string]any)
 	if len(filters) > 0 {
 		query["query"] = map[string]any{
@@ -268,6 +286,68 @@ func FindingEventsQuery(ctx context.Context, logger *zap.Logger, client kaytu.Cl
 	return response.Hits.Hits, response.Hits.Total.Value, err
 }
 
+type FindingEventPaginator struct {
+	paginator *kaytu.BaseESPaginator
+}
+
+func NewFindingEventPaginator(client kaytu.Client, idx string, filters []kaytu.BoolFilter, limit *int64, sort []map[string]any) (FindingEventPaginator, error) {
+	paginator, err := kaytu.NewPaginatorWithSort(client.ES(), idx, filters, limit, sort)
+	if err != nil {
+		return FindingEventPaginator{}, err
+	}
+
+	p := FindingEventPaginator{
+		paginator: paginator,
+	}
+
+	return p, nil
+}
+
+func (p FindingEventPaginator) HasNext() bool {
+	return !p.paginator.Done()
+}
+
+func (p FindingEventPaginator) Close(ctx context.Context) error {
+	return p.paginator.Deallocate(ctx)
+}
+
+func (p FindingEventPaginator) NextPage(ctx context.Context) ([]types.FindingEvent, error) {
+	var response FindingEventsQueryResponse
+	err := p.paginator.SearchWithLog(ctx, &response, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []types.FindingEvent
+	for _, hit := range response.Hits.Hits {
+		values = append(values, hit.Source)
+	}
+
+	hits := int64(len(response.Hits.Hits))
+	if hits > 0 {
+		p.paginator.UpdateState(hits, response.Hits.Hits[hits-1].Sort, response.PitID)
+	} else {
+		p.paginator.UpdateState(hits, nil, "")
+	}
+
+	return values, nil
+}
+
+// NewFindingEventsQueryPaginator walks every finding event matching the same filters as FindingEventsQuery, used for exports
+func NewFindingEventsQueryPaginator(client kaytu.Client,
+	findingIDs []string, kaytuResourceIDs []string,
+	provider []source.Type, connectionID []string, notConnectionID []string,
+	resourceTypes []string,
+	benchmarkID []string, controlID []string, severity []types.FindingSeverity,
+	evaluatedAtFrom *time.Time, evaluatedAtTo *time.Time,
+	stateActive []bool, conformanceStatuses []types.ConformanceStatus,
+	sorts []api.FindingEventsSort) (FindingEventPaginator, error) {
+	filters := findingEventsQueryFilters(findingIDs, kaytuResourceIDs, provider, connectionID, notConnectionID, resourceTypes,
+		benchmarkID, controlID, severity, evaluatedAtFrom, evaluatedAtTo, stateActive, conformanceStatuses)
+
+	return NewFindingEventPaginator(client, types.FindingEventsIndex, filters, nil, findingEventsRequestSort(sorts))
+}
+
 type FindingEventFiltersAggregationResponse struct {
 	Aggregations struct {
 		ControlIDFilter          AggregationResult `json:"control_id_filter"`
@@ -515,3 +595,27 @@ func FindingEventsCount(ctx context.Context, client kaytu.Client, benchmarkIDs [
 
 	return response.Hits.Total.Value, err
 }
+
+// NewPostureDiffFindingEventPaginator walks the finding events of a benchmark produced after the base and up to the target,
+// the range is either compliance job ids or evaluation times, events are sorted by evaluation time
+func NewPostureDiffFindingEventPaginator(client kaytu.Client, benchmarkID string, connectionIDs []string,
+	baseJobID, targetJobID *uint, baseTime, targetTime *time.Time) (FindingEventPaginator, error) {
+	filters := []kaytu.BoolFilter{
+		kaytu.NewTermFilter("benchmarkID", benchmarkID),
+	}
+	if len(connectionIDs) > 0 {
+		filters = append(filters, kaytu.NewTermsFilter("connectionID", connectionIDs))
+	}
+	if baseJobID != nil && targetJobID != nil {
+		filters = append(filters, kaytu.NewRangeFilter("parentComplianceJobID",
+			fmt.Sprintf("%d", *baseJobID), "", "", fmt.Sprintf("%d", *targetJobID)))
+	}
+	if baseTime != nil && targetTime != nil {
+		filters = append(filters, kaytu.NewRangeFilter("evaluatedAt",
+			fmt.Sprintf("%d", baseTime.UnixMilli()), "", "", fmt.Sprintf("%d", targetTime.UnixMilli())))
+	}
+
+	return NewFindingEventPaginator(client, types.FindingEventsIndex, filters, nil, []map[string]any{
+		{"evaluatedAt": "asc"},
+	})
+}
@@ -0,0 +1,154 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// matchesFilter evaluates the term, terms, range and bool queries the finding filters are built of against a document
+func matchesFilter(t *testing.T, filter map[string]any, doc map[string]any) bool {
+	t.Helper()
+	require.Len(t, filter, 1, "filter %v", filter)
+	for kind, body := range filter {
+		clauses, _ := body.(map[string]any)
+		switch kind {
+		case "term":
+			for field, value := range clauses {
+				return fmt.Sprint(doc[field]) == fmt.Sprint(value)
+			}
+		case "terms":
+			for field, values := range clauses {
+				for _, value := range values.([]any) {
+					if fmt.Sprint(doc[field]) == fmt.Sprint(value) {
+						return true
+					}
+				}
+				return false
+			}
+		case "range":
+			for field, bounds := range clauses {
+				value := doc[field].(int64)
+				for op, bound := range bounds.(map[string]any) {
+					limit, err := strconv.ParseInt(bound.(string), 10, 64)
+					require.NoError(t, err)
+					ok := map[string]bool{"lt": value < limit, "lte": value <= limit, "gt": value > limit, "gte": value >= limit}[op]
+					if !ok {
+						return false
+					}
+				}
+				return true
+			}
+		case "bool":
+			for _, must := range asFilters(clauses["must"]) {
+				if !matchesFilter(t, must, doc) {
+					return false
+				}
+			}
+			for _, mustNot := range asFilters(clauses["must_not"]) {
+				if matchesFilter(t, mustNot, doc) {
+					return false
+				}
+			}
+			should := asFilters(clauses["should"])
+			for _, s := range should {
+				if matchesFilter(t, s, doc) {
+					return true
+				}
+			}
+			return len(should) == 0
+		}
+	}
+	t.Fatalf("unsupported filter %v", filter)
+	return false
+}
+
+func asFilters(value any) []map[string]any {
+	list, _ := value.([]any)
+	filters := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		filters = append(filters, item.(map[string]any))
+	}
+	return filters
+}
+
+func filterMap(t *testing.T, filter any) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(filter)
+	require.NoError(t, err)
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(raw, &result))
+	return result
+}
+
+func TestFindingSLAOverdueFilter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := api.FindingSLAPolicy{
+		types.FindingSeverityCritical: 7,
+		types.FindingSeverityHigh:     30,
+	}
+	criticalCutoff := policy.OverdueCutoff(types.FindingSeverityCritical, now).UnixMilli()
+	doc := func(severity types.FindingSeverity, status types.ConformanceStatus, active bool, lastTransition int64) map[string]any {
+		return map[string]any{
+			"severity":          string(severity),
+			"conformanceStatus": string(status),
+			"stateActive":       active,
+			"lastTransition":    lastTransition,
+		}
+	}
+	longAgo := now.AddDate(-1, 0, 0).UnixMilli()
+
+	docs := []struct {
+		name    string
+		doc     map[string]any
+		overdue bool
+	}{
+		{name: "critical past the window", doc: doc(types.FindingSeverityCritical, types.ConformanceStatusALARM, true, criticalCutoff-1), overdue: true},
+		{name: "critical exactly at the cutoff", doc: doc(types.FindingSeverityCritical, types.ConformanceStatusALARM, true, criticalCutoff)},
+		{name: "critical within the window", doc: doc(types.FindingSeverityCritical, types.ConformanceStatusALARM, true, criticalCutoff+1)},
+		{name: "high errored long ago", doc: doc(types.FindingSeverityHigh, types.ConformanceStatusERROR, true, longAgo), overdue: true},
+		{name: "unconfigured severity", doc: doc(types.FindingSeverityMedium, types.ConformanceStatusALARM, true, longAgo)},
+		{name: "passed", doc: doc(types.FindingSeverityCritical, types.ConformanceStatusOK, true, longAgo)},
+		{name: "inactive", doc: doc(types.FindingSeverityCritical, types.ConformanceStatusALARM, false, longAgo)},
+	}
+
+	overdueFilter := filterMap(t, FindingSLAOverdueFilter(policy, true, now))
+	notOverdueFilter := filterMap(t, FindingSLAOverdueFilter(policy, false, now))
+	for _, tc := range docs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.overdue, matchesFilter(t, overdueFilter, tc.doc), "overdue=true")
+			assert.Equal(t, !tc.overdue, matchesFilter(t, notOverdueFilter, tc.doc), "overdue=false")
+
+			// the filter agrees with the overdue flag set on the findings returned by the API
+			finding := api.Finding{
+				StateActive:       tc.doc["stateActive"].(bool),
+				ConformanceStatus: api.ConformanceStatusPassed,
+				Severity:          types.FindingSeverity(tc.doc["severity"].(string)),
+				LastEvent:         time.UnixMilli(tc.doc["lastTransition"].(int64)),
+			}
+			if !types.ConformanceStatus(tc.doc["conformanceStatus"].(string)).IsPassed() {
+				finding.ConformanceStatus = api.ConformanceStatusFailed
+			}
+			policy.Apply(&finding, now)
+			assert.Equal(t, tc.overdue, finding.Overdue)
+		})
+	}
+
+	t.Run("empty policy matches nothing", func(t *testing.T) {
+		for _, policy := range []api.FindingSLAPolicy{nil, {types.FindingSeverityCritical: 0}} {
+			overdueFilter := filterMap(t, FindingSLAOverdueFilter(policy, true, now))
+			notOverdueFilter := filterMap(t, FindingSLAOverdueFilter(policy, false, now))
+			for _, tc := range docs {
+				assert.False(t, matchesFilter(t, overdueFilter, tc.doc), tc.name)
+				assert.True(t, matchesFilter(t, notOverdueFilter, tc.doc), tc.name)
+			}
+		}
+	})
+}
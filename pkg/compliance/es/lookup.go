@@ -144,3 +144,42 @@ func FetchResourceByResourceIdAndType(ctx context.Context, client kaytu.Client,
 
 	return &response.Hits.Hits[0].Source, nil
 }
+
+// FetchResourcesByResourceIdsAndType returns the resources of the given type keyed by their id
+func FetchResourcesByResourceIdsAndType(ctx context.Context, client kaytu.Client, resourceIds []string, resourceType string) (map[string]es.Resource, error) {
+	if len(resourceIds) == 0 {
+		return nil, nil
+	}
+	request := make(map[string]any)
+	request["size"] = len(resourceIds)
+	request["query"] = map[string]any{
+		"bool": map[string]any{
+			"filter": map[string]any{
+				"terms": map[string]any{
+					"id": resourceIds,
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	index := es.ResourceTypeToESIndex(resourceType)
+
+	var response ResourceQueryResponse
+	err = client.Search(ctx, index, string(b), &response)
+	if err != nil {
+		if kaytu.IsIndexNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	resources := make(map[string]es.Resource, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		resources[hit.Source.ID] = hit.Source
+	}
+	return resources, nil
+}
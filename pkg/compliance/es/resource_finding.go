@@ -939,3 +939,31 @@ type GetPerFieldTopWithIssuesResponse struct {
 		} `json:"findings"`
 	} `json:"aggregations"`
 }
+
+// FetchResourceFindingsByKaytuResourceIDs returns the resource findings of the given resources keyed by kaytu resource id
+func FetchResourceFindingsByKaytuResourceIDs(ctx context.Context, client kaytu.Client, kaytuResourceIDs []string) (map[string][]types.ResourceFinding, error) {
+	result := make(map[string][]types.ResourceFinding)
+	for start := 0; start < len(kaytuResourceIDs); start += 1000 {
+		end := min(start+1000, len(kaytuResourceIDs))
+		paginator, err := NewResourceFindingPaginator(client, types.ResourceFindingsIndex, []kaytu.BoolFilter{
+			kaytu.NewTermsFilter("kaytuResourceID", kaytuResourceIDs[start:end]),
+		}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for paginator.HasNext() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				_ = paginator.Close(ctx)
+				return nil, err
+			}
+			for _, resourceFinding := range page {
+				result[resourceFinding.KaytuResourceID] = append(result[resourceFinding.KaytuResourceID], resourceFinding)
+			}
+		}
+		if err := paginator.Close(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
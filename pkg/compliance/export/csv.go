@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+)
+
+var (
+	findingCSVHeader = []string{
+		"id", "benchmark_id", "control_id", "control_title", "connection_id", "provider_connection_id",
+		"provider_connection_name", "connector", "kaytu_resource_id", "resource_id", "resource_name",
+		"resource_type", "resource_type_name", "resource_location", "conformance_status", "severity",
+		"state_active", "reason", "evaluated_at", "last_event", "compliance_job_id", "parent_benchmarks",
+		"sla_due_at", "overdue", "owner",
+	}
+	findingEventCSVHeader = []string{
+		"id", "finding_id", "benchmark_id", "control_id", "connection_id", "provider_connection_id",
+		"provider_connection_name", "connector", "kaytu_resource_id", "resource_id", "resource_name",
+		"resource_type", "resource_location", "previous_conformance_status", "conformance_status",
+		"previous_state_active", "state_active", "severity", "reason", "evaluated_at", "compliance_job_id",
+	}
+)
+
+type csvWriter struct {
+	w      *csv.Writer
+	header []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) writeHeader(header []string) error {
+	if c.header == nil {
+		c.header = header
+		return c.w.Write(header)
+	}
+	if len(c.header) != len(header) || c.header[0] != header[0] || c.header[1] != header[1] {
+		return errors.New("cannot mix findings and finding events in a single csv export")
+	}
+	return nil
+}
+
+func (c *csvWriter) WriteFinding(f api.Finding) error {
+	if err := c.writeHeader(findingCSVHeader); err != nil {
+		return err
+	}
+	slaDueAt := ""
+	if f.SLADueAt != nil {
+		slaDueAt = f.SLADueAt.UTC().Format(time.RFC3339)
+	}
+	return c.w.Write([]string{
+		f.ID, f.BenchmarkID, f.ControlID, f.ControlTitle, f.ConnectionID, f.ProviderConnectionID,
+		f.ProviderConnectionName, f.Connector.String(), f.KaytuResourceID, f.ResourceID, f.ResourceName,
+		f.ResourceType, f.ResourceTypeName, f.ResourceLocation, string(f.ConformanceStatus), f.Severity.String(),
+		fmt.Sprintf("%v", f.StateActive), f.Reason, time.UnixMilli(f.EvaluatedAt).UTC().Format(time.RFC3339),
+		f.LastEvent.UTC().Format(time.RFC3339), fmt.Sprintf("%d", f.ComplianceJobID), strings.Join(f.ParentBenchmarks, ";"),
+		slaDueAt, fmt.Sprintf("%v", f.Overdue), f.Owner,
+	})
+}
+
+func (c *csvWriter) WriteFindingEvent(e api.FindingEvent) error {
+	if err := c.writeHeader(findingEventCSVHeader); err != nil {
+		return err
+	}
+	return c.w.Write([]string{
+		e.ID, e.FindingID, e.BenchmarkID, e.ControlID, e.ConnectionID, e.ProviderConnectionID,
+		e.ProviderConnectionName, e.Connector.String(), e.KaytuResourceID, e.ResourceID, e.ResourceName,
+		e.ResourceType, e.ResourceLocation, string(e.PreviousConformanceStatus), string(e.ConformanceStatus),
+		fmt.Sprintf("%v", e.PreviousStateActive), fmt.Sprintf("%v", e.StateActive), e.Severity.String(), e.Reason,
+		e.EvaluatedAt.UTC().Format(time.RFC3339), fmt.Sprintf("%d", e.ComplianceJobID),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVWriter_Findings(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, nil)
+	require.NoError(t, err)
+	for _, f := range testFindings() {
+		require.NoError(t, w.WriteFinding(f))
+	}
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, findingCSVHeader, records[0])
+	for _, record := range records {
+		assert.Len(t, record, len(findingCSVHeader))
+	}
+
+	row := make(map[string]string)
+	for idx, column := range records[0] {
+		row[column] = records[1][idx]
+	}
+	assert.Equal(t, "finding-1", row["id"])
+	assert.Equal(t, "prod, main", row["provider_connection_name"])
+	assert.Equal(t, "root has \"access keys\"", row["reason"])
+	assert.Equal(t, "failed", row["conformance_status"])
+	assert.Equal(t, "critical", row["severity"])
+	assert.Equal(t, "true", row["state_active"])
+	assert.Equal(t, "2024-01-01T10:00:00Z", row["evaluated_at"])
+	assert.Equal(t, "2024-01-01T09:00:00Z", row["last_event"])
+	assert.Equal(t, "7", row["compliance_job_id"])
+	assert.Equal(t, "aws_cis_v200;aws_cis_v200_1", row["parent_benchmarks"])
+	assert.Equal(t, "2024-02-01T00:00:00Z", row["sla_due_at"])
+	assert.Equal(t, "true", row["overdue"])
+	assert.Equal(t, "security", row["owner"])
+
+	assert.Equal(t, "", records[2][len(findingCSVHeader)-3], "sla_due_at is empty without sla")
+}
+
+func TestCSVWriter_FindingEvents(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteFindingEvent(testFindingEvent()))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, findingEventCSVHeader, records[0])
+
+	row := make(map[string]string)
+	for idx, column := range records[0] {
+		row[column] = records[1][idx]
+	}
+	assert.Equal(t, "event-1", row["id"])
+	assert.Equal(t, "finding-1", row["finding_id"])
+	assert.Equal(t, "passed", row["previous_conformance_status"])
+	assert.Equal(t, "failed", row["conformance_status"])
+	assert.Equal(t, "2024-01-01T10:00:00Z", row["evaluated_at"])
+}
+
+func TestCSVWriter_RejectsMixedItems(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteFinding(testFindings()[0]))
+	assert.Error(t, w.WriteFindingEvent(testFindingEvent()))
+}
+
+func TestCSVWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+// EvidenceManifest describes the content of an evidence bundle, it is written as manifest.json
+type EvidenceManifest struct {
+	BenchmarkID     string                 `json:"benchmarkID"`
+	ComplianceJobID uint                   `json:"complianceJobID"`
+	GeneratedAt     time.Time              `json:"generatedAt"`
+	Files           []EvidenceManifestFile `json:"files"`
+}
+
+type EvidenceManifestFile struct {
+	Path              string                  `json:"path"`
+	SHA256            string                  `json:"sha256"`
+	ControlID         string                  `json:"controlID"`
+	ConnectionID      string                  `json:"connectionID"`
+	KaytuResourceID   string                  `json:"kaytuResourceID"`
+	ResourceType      string                  `json:"resourceType"`
+	ConformanceStatus types.ConformanceStatus `json:"conformanceStatus"`
+	EvaluatedAt       int64                   `json:"evaluatedAt"`
+	// ResourceSHA256 is the checksum taken by the runner when the evidence was captured
+	ResourceSHA256 string `json:"resourceSHA256"`
+}
+
+// EvidenceBundle streams a zip archive with one json file per evidence, a manifest.json and a SHA256SUMS file
+// covering every file in the archive. Close has to be called to write the manifest and terminate the archive.
+type EvidenceBundle struct {
+	zw       *zip.Writer
+	manifest EvidenceManifest
+	sums     map[string]string
+}
+
+func NewEvidenceBundle(w io.Writer, benchmarkID string, complianceJobID uint) *EvidenceBundle {
+	return &EvidenceBundle{
+		zw: zip.NewWriter(w),
+		manifest: EvidenceManifest{
+			BenchmarkID:     benchmarkID,
+			ComplianceJobID: complianceJobID,
+			GeneratedAt:     time.Now().UTC(),
+			Files:           []EvidenceManifestFile{},
+		},
+		sums: map[string]string{},
+	}
+}
+
+func (b *EvidenceBundle) Add(evidence types.ComplianceEvidence) error {
+	evidence.EsIndex = ""
+	content, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("evidence/%s/%s.json", evidence.ControlID, evidence.EsID)
+	sum, err := b.writeFile(path, content)
+	if err != nil {
+		return err
+	}
+	b.manifest.Files = append(b.manifest.Files, EvidenceManifestFile{
+		Path:              path,
+		SHA256:            sum,
+		ControlID:         evidence.ControlID,
+		ConnectionID:      evidence.ConnectionID,
+		KaytuResourceID:   evidence.KaytuResourceID,
+		ResourceType:      evidence.ResourceType,
+		ConformanceStatus: evidence.ConformanceStatus,
+		EvaluatedAt:       evidence.EvaluatedAt,
+		ResourceSHA256:    evidence.Checksum,
+	})
+	return nil
+}
+
+func (b *EvidenceBundle) Close() error {
+	manifest, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := b.writeFile("manifest.json", manifest); err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(b.sums))
+	for path := range b.sums {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var sums strings.Builder
+	for _, path := range paths {
+		// same layout as the sha256sum tool so the bundle can be checked with sha256sum -c
+		sums.WriteString(fmt.Sprintf("%s  %s\n", b.sums[path], path))
+	}
+	f, err := b.zw.Create("SHA256SUMS")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write([]byte(sums.String())); err != nil {
+		return err
+	}
+	return b.zw.Close()
+}
+
+func (b *EvidenceBundle) writeFile(path string, content []byte) (string, error) {
+	f, err := b.zw.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(content); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	b.sums[path] = hex.EncodeToString(sum[:])
+	return b.sums[path], nil
+}
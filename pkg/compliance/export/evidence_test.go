@@ -0,0 +1,103 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestEvidenceBundle(t *testing.T) {
+	evidences := []types.ComplianceEvidence{
+		{
+			EsID:              "ev-1",
+			EsIndex:           types.ComplianceEvidenceIndex,
+			ControlID:         "aws_cis_v200_1_4",
+			ConnectionID:      "conn-1",
+			KaytuResourceID:   "kaytu-res-1",
+			ResourceType:      "AWS::IAM::Account",
+			ConformanceStatus: types.ConformanceStatusALARM,
+			EvaluatedAt:       1704103200000,
+			Resource:          `{"arn":"arn:aws:iam::123456789012:root"}`,
+			Checksum:          "abc",
+		},
+		{
+			EsID:              "ev-2",
+			ControlID:         "aws_cis_v200_1_5",
+			ConnectionID:      "conn-2",
+			ConformanceStatus: types.ConformanceStatusOK,
+		},
+	}
+
+	var buf bytes.Buffer
+	bundle := NewEvidenceBundle(&buf, "aws_cis_v200", 7)
+	for _, evidence := range evidences {
+		require.NoError(t, bundle.Add(evidence))
+	}
+	require.NoError(t, bundle.Close())
+
+	files := readZip(t, buf.Bytes())
+	require.Len(t, files, 4)
+
+	var stored types.ComplianceEvidence
+	require.NoError(t, json.Unmarshal(files["evidence/aws_cis_v200_1_4/ev-1.json"], &stored))
+	assert.Empty(t, stored.EsIndex, "the index name is not part of the evidence")
+	assert.Equal(t, evidences[0].Resource, stored.Resource)
+
+	var manifest EvidenceManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.Equal(t, "aws_cis_v200", manifest.BenchmarkID)
+	assert.Equal(t, uint(7), manifest.ComplianceJobID)
+	require.Len(t, manifest.Files, 2)
+	assert.Equal(t, "evidence/aws_cis_v200_1_5/ev-2.json", manifest.Files[1].Path)
+	assert.Equal(t, "conn-1", manifest.Files[0].ConnectionID)
+	assert.Equal(t, "abc", manifest.Files[0].ResourceSHA256)
+
+	var wantSums []string
+	for _, path := range []string{"evidence/aws_cis_v200_1_4/ev-1.json", "evidence/aws_cis_v200_1_5/ev-2.json", "manifest.json"} {
+		sum := sha256.Sum256(files[path])
+		wantSums = append(wantSums, fmt.Sprintf("%s  %s", hex.EncodeToString(sum[:]), path))
+	}
+	assert.Equal(t, strings.Join(wantSums, "\n")+"\n", string(files["SHA256SUMS"]))
+	for _, file := range manifest.Files {
+		sum := sha256.Sum256(files[file.Path])
+		assert.Equal(t, hex.EncodeToString(sum[:]), file.SHA256)
+	}
+}
+
+func TestEvidenceBundle_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewEvidenceBundle(&buf, "aws_cis_v200", 7).Close())
+
+	files := readZip(t, buf.Bytes())
+	require.Len(t, files, 2)
+	var manifest EvidenceManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	assert.NotNil(t, manifest.Files)
+	assert.Empty(t, manifest.Files)
+}
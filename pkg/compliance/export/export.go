@@ -0,0 +1,81 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatSARIF Format = "sarif"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatCSV, "":
+		return FormatCSV, nil
+	case FormatJSONL, "ndjson":
+		return FormatJSONL, nil
+	case FormatSARIF:
+		return FormatSARIF, nil
+	}
+	return "", fmt.Errorf("unsupported export format %s, valid formats are csv, jsonl and sarif", s)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatSARIF:
+		return "application/sarif+json"
+	default:
+		return "text/csv"
+	}
+}
+
+func (f Format) FileExtension() string {
+	switch f {
+	case FormatJSONL:
+		return "jsonl"
+	case FormatSARIF:
+		return "sarif"
+	default:
+		return "csv"
+	}
+}
+
+// Rule is the control metadata attached to exported results, used for SARIF rules
+type Rule struct {
+	ID          string
+	Title       string
+	Description string
+	DocumentURI string
+	Severity    types.FindingSeverity
+}
+
+// Writer streams findings or finding events to the underlying writer in the chosen format.
+// A writer only accepts one kind of item, Close has to be called to terminate the document.
+type Writer interface {
+	WriteFinding(finding api.Finding) error
+	WriteFindingEvent(findingEvent api.FindingEvent) error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer, rules map[string]Rule) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatJSONL:
+		return newJSONLWriter(w), nil
+	case FormatSARIF:
+		return newSARIFWriter(w, rules), nil
+	}
+	return nil, fmt.Errorf("unsupported export format %s", format)
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFindings() []api.Finding {
+	due := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	return []api.Finding{
+		{
+			ID:                     "finding-1",
+			BenchmarkID:            "aws_cis_v200",
+			ControlID:              "aws_cis_v200_1_4",
+			ControlTitle:           "Ensure no root access keys exist",
+			ConnectionID:           "conn-1",
+			ProviderConnectionID:   "123456789012",
+			ProviderConnectionName: "prod, main",
+			Connector:              source.CloudAWS,
+			KaytuResourceID:        "kaytu-res-1",
+			ResourceID:             "arn:aws:iam::123456789012:root",
+			ResourceName:           "root",
+			ResourceType:           "AWS::IAM::Account",
+			ResourceTypeName:       "IAM Account",
+			ResourceLocation:       "global",
+			ConformanceStatus:      api.ConformanceStatusFailed,
+			Severity:               types.FindingSeverityCritical,
+			StateActive:            true,
+			Reason:                 "root has \"access keys\"",
+			EvaluatedAt:            time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).UnixMilli(),
+			LastEvent:              time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			ComplianceJobID:        7,
+			ParentBenchmarks:       []string{"aws_cis_v200", "aws_cis_v200_1"},
+			SLADueAt:               &due,
+			Overdue:                true,
+			Owner:                  "security",
+			SortKey:                []any{"sort"},
+		},
+		{
+			ID:                "finding-2",
+			BenchmarkID:       "aws_cis_v200",
+			ControlID:         "aws_cis_v200_2_1",
+			ConnectionID:      "conn-1",
+			Connector:         source.CloudAWS,
+			KaytuResourceID:   "kaytu-res-2",
+			ResourceName:      "bucket",
+			ResourceType:      "AWS::S3::Bucket",
+			ConformanceStatus: api.ConformanceStatusPassed,
+			Severity:          types.FindingSeverityLow,
+			EvaluatedAt:       time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).UnixMilli(),
+		},
+		{
+			ID:                "finding-3",
+			BenchmarkID:       "aws_cis_v200",
+			ControlID:         "aws_cis_v200_1_4",
+			ConnectionID:      "conn-2",
+			Connector:         source.CloudAWS,
+			KaytuResourceID:   "kaytu-res-3",
+			ConformanceStatus: api.ConformanceStatusFailed,
+			Severity:          types.FindingSeverityMedium,
+		},
+	}
+}
+
+func testFindingEvent() api.FindingEvent {
+	return api.FindingEvent{
+		ID:                        "event-1",
+		FindingID:                 "finding-1",
+		BenchmarkID:               "aws_cis_v200",
+		ControlID:                 "aws_cis_v200_1_4",
+		ConnectionID:              "conn-1",
+		Connector:                 source.CloudAWS,
+		KaytuResourceID:           "kaytu-res-1",
+		ResourceType:              "AWS::IAM::Account",
+		PreviousConformanceStatus: api.ConformanceStatusPassed,
+		ConformanceStatus:         api.ConformanceStatusFailed,
+		PreviousStateActive:       true,
+		StateActive:               true,
+		Severity:                  types.FindingSeverityHigh,
+		Reason:                    "root has access keys",
+		EvaluatedAt:               time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		ComplianceJobID:           7,
+		SortKey:                   []any{"sort"},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{in: "", want: FormatCSV},
+		{in: "csv", want: FormatCSV},
+		{in: "CSV", want: FormatCSV},
+		{in: "jsonl", want: FormatJSONL},
+		{in: "ndjson", want: FormatJSONL},
+		{in: "sarif", want: FormatSARIF},
+		{in: "xlsx", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			format, err := ParseFormat(tc.in)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, format)
+		})
+	}
+
+	assert.Equal(t, "text/csv", FormatCSV.ContentType())
+	assert.Equal(t, "application/x-ndjson", FormatJSONL.ContentType())
+	assert.Equal(t, "application/sarif+json", FormatSARIF.ContentType())
+	assert.Equal(t, "sarif", FormatSARIF.FileExtension())
+}
@@ -0,0 +1,30 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+)
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	return &jsonlWriter{enc: json.NewEncoder(w)}
+}
+
+func (j *jsonlWriter) WriteFinding(finding api.Finding) error {
+	finding.SortKey = nil
+	return j.enc.Encode(finding)
+}
+
+func (j *jsonlWriter) WriteFindingEvent(findingEvent api.FindingEvent) error {
+	findingEvent.SortKey = nil
+	return j.enc.Encode(findingEvent)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
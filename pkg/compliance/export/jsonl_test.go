@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLWriter_Findings(t *testing.T) {
+	findings := testFindings()
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatJSONL, &buf, nil)
+	require.NoError(t, err)
+	for _, f := range findings {
+		require.NoError(t, w.WriteFinding(f))
+	}
+	require.NoError(t, w.Close())
+
+	scanner := bufio.NewScanner(&buf)
+	var lines int
+	for scanner.Scan() {
+		var raw map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &raw), "every line is a json document")
+		assert.Nil(t, raw["sortKey"], "sort keys are not exported")
+
+		var finding api.Finding
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &finding))
+		expected := findings[lines]
+		expected.SortKey = nil
+		assert.Equal(t, expected.ID, finding.ID)
+		assert.Equal(t, expected.ConformanceStatus, finding.ConformanceStatus)
+		assert.Equal(t, expected.ParentBenchmarks, finding.ParentBenchmarks)
+		lines++
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, len(findings), lines)
+	assert.NotNil(t, findings[0].SortKey, "the caller's finding is not modified")
+}
+
+func TestJSONLWriter_FindingEvents(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatJSONL, &buf, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteFindingEvent(testFindingEvent()))
+	require.NoError(t, w.WriteFindingEvent(testFindingEvent()))
+	require.NoError(t, w.Close())
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var event api.FindingEvent
+	require.NoError(t, json.Unmarshal(lines[0], &event))
+	assert.Equal(t, "event-1", event.ID)
+	assert.Equal(t, api.ConformanceStatusPassed, event.PreviousConformanceStatus)
+	assert.Nil(t, event.SortKey)
+}
@@ -0,0 +1,253 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+const (
+	sarifVersion  = "2.1.0"
+	sarifSchema   = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolName = "open-governance"
+)
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Kind       string          `json:"kind"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	Name                 string                 `json:"name,omitempty"`
+	ShortDescription     *sarifMessage          `json:"shortDescription,omitempty"`
+	FullDescription      *sarifMessage          `json:"fullDescription,omitempty"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]any         `json:"properties,omitempty"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifWriter streams results as they come and only emits the rules table on Close,
+// so the whole export never has to be held in memory.
+type sarifWriter struct {
+	w          io.Writer
+	rules      map[string]Rule
+	ruleIndex  map[string]int
+	ruleOrder  []string
+	started    bool
+	hasResults bool
+}
+
+func newSARIFWriter(w io.Writer, rules map[string]Rule) *sarifWriter {
+	return &sarifWriter{
+		w:         w,
+		rules:     rules,
+		ruleIndex: make(map[string]int),
+	}
+}
+
+func sarifLevel(severity types.FindingSeverity) string {
+	switch severity {
+	case types.FindingSeverityCritical, types.FindingSeverityHigh:
+		return "error"
+	case types.FindingSeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifSecuritySeverity(severity types.FindingSeverity) string {
+	switch severity {
+	case types.FindingSeverityCritical:
+		return "9.5"
+	case types.FindingSeverityHigh:
+		return "8.0"
+	case types.FindingSeverityMedium:
+		return "5.5"
+	case types.FindingSeverityLow:
+		return "2.0"
+	default:
+		return "0.0"
+	}
+}
+
+func (s *sarifWriter) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	_, err := io.WriteString(s.w, `{"version":"`+sarifVersion+`","$schema":"`+sarifSchema+`","runs":[{"results":[`)
+	return err
+}
+
+func (s *sarifWriter) indexOf(controlID string) int {
+	if idx, ok := s.ruleIndex[controlID]; ok {
+		return idx
+	}
+	idx := len(s.ruleOrder)
+	s.ruleIndex[controlID] = idx
+	s.ruleOrder = append(s.ruleOrder, controlID)
+	return idx
+}
+
+func (s *sarifWriter) writeResult(controlID string, severity types.FindingSeverity, status api.ConformanceStatus,
+	reason string, kaytuResourceID, resourceName, resourceType string, properties map[string]any) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	result := sarifResult{
+		RuleID:    controlID,
+		RuleIndex: s.indexOf(controlID),
+		Kind:      "fail",
+		Level:     sarifLevel(severity),
+		Message:   sarifMessage{Text: reason},
+		Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+			Name:               resourceName,
+			FullyQualifiedName: kaytuResourceID,
+			Kind:               resourceType,
+		}}}},
+		Properties: properties,
+	}
+	if status == api.ConformanceStatusPassed {
+		result.Kind = "pass"
+		result.Level = "none"
+	}
+	if result.Message.Text == "" {
+		result.Message.Text = string(status)
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if s.hasResults {
+		if _, err := io.WriteString(s.w, ","); err != nil {
+			return err
+		}
+	}
+	s.hasResults = true
+	_, err = s.w.Write(b)
+	return err
+}
+
+func (s *sarifWriter) WriteFinding(f api.Finding) error {
+	return s.writeResult(f.ControlID, f.Severity, f.ConformanceStatus, f.Reason, f.KaytuResourceID, f.ResourceName, f.ResourceType,
+		map[string]any{
+			"findingID":              f.ID,
+			"benchmarkID":            f.BenchmarkID,
+			"connectionID":           f.ConnectionID,
+			"providerConnectionID":   f.ProviderConnectionID,
+			"providerConnectionName": f.ProviderConnectionName,
+			"connector":              f.Connector.String(),
+			"resourceID":             f.ResourceID,
+			"resourceLocation":       f.ResourceLocation,
+			"severity":               f.Severity.String(),
+			"stateActive":            f.StateActive,
+			"evaluatedAt":            f.EvaluatedAt,
+			"complianceJobID":        f.ComplianceJobID,
+		})
+}
+
+func (s *sarifWriter) WriteFindingEvent(e api.FindingEvent) error {
+	return s.writeResult(e.ControlID, e.Severity, e.ConformanceStatus, e.Reason, e.KaytuResourceID, e.ResourceName, e.ResourceType,
+		map[string]any{
+			"findingEventID":            e.ID,
+			"findingID":                 e.FindingID,
+			"benchmarkID":               e.BenchmarkID,
+			"connectionID":              e.ConnectionID,
+			"providerConnectionID":      e.ProviderConnectionID,
+			"providerConnectionName":    e.ProviderConnectionName,
+			"connector":                 e.Connector.String(),
+			"resourceID":                e.ResourceID,
+			"resourceLocation":          e.ResourceLocation,
+			"severity":                  e.Severity.String(),
+			"previousConformanceStatus": e.PreviousConformanceStatus,
+			"previousStateActive":       e.PreviousStateActive,
+			"stateActive":               e.StateActive,
+			"evaluatedAt":               e.EvaluatedAt,
+			"complianceJobID":           e.ComplianceJobID,
+		})
+}
+
+func (s *sarifWriter) Close() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	rules := make([]sarifRule, 0, len(s.ruleOrder))
+	for _, controlID := range s.ruleOrder {
+		rule := sarifRule{ID: controlID}
+		severity := types.FindingSeverityNone
+		if r, ok := s.rules[controlID]; ok {
+			rule.Name = r.Title
+			if r.Title != "" {
+				rule.ShortDescription = &sarifMessage{Text: r.Title}
+			}
+			if r.Description != "" {
+				rule.FullDescription = &sarifMessage{Text: r.Description}
+			}
+			rule.HelpURI = r.DocumentURI
+			severity = r.Severity
+		}
+		rule.DefaultConfiguration = sarifRuleConfiguration{Level: sarifLevel(severity)}
+		rule.Properties = map[string]any{
+			"security-severity": sarifSecuritySeverity(severity),
+			"tags":              []string{"security", "compliance"},
+		}
+		rules = append(rules, rule)
+	}
+
+	tool, err := json.Marshal(sarifTool{Driver: sarifDriver{
+		Name:           sarifToolName,
+		InformationURI: "https://github.com/kaytu-io/open-governance",
+		Rules:          rules,
+	}})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(s.w, `],"tool":`); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(tool); err != nil {
+		return err
+	}
+	_, err = io.WriteString(s.w, `}]}`)
+	return err
+}
@@ -0,0 +1,178 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sarifLog is the part of the SARIF 2.1.0 object model the export is expected to produce
+type sarifLog struct {
+	Version string `json:"version"`
+	Schema  string `json:"$schema"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Name  string `json:"name"`
+				Rules []struct {
+					ID                   string        `json:"id"`
+					Name                 string        `json:"name"`
+					ShortDescription     *sarifMessage `json:"shortDescription"`
+					FullDescription      *sarifMessage `json:"fullDescription"`
+					HelpURI              string        `json:"helpUri"`
+					DefaultConfiguration struct {
+						Level string `json:"level"`
+					} `json:"defaultConfiguration"`
+					Properties map[string]any `json:"properties"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			RuleIndex int    `json:"ruleIndex"`
+			Kind      string `json:"kind"`
+			Level     string `json:"level"`
+			Message   struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				LogicalLocations []struct {
+					Name               string `json:"name"`
+					FullyQualifiedName string `json:"fullyQualifiedName"`
+					Kind               string `json:"kind"`
+				} `json:"logicalLocations"`
+			} `json:"locations"`
+			Properties map[string]any `json:"properties"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+var (
+	sarifKinds  = map[string]bool{"notApplicable": true, "pass": true, "fail": true, "review": true, "open": true, "informational": true}
+	sarifLevels = map[string]bool{"none": true, "note": true, "warning": true, "error": true}
+)
+
+func TestSARIFWriter_SchemaShape(t *testing.T) {
+	rules := map[string]Rule{
+		"aws_cis_v200_1_4": {
+			ID:          "aws_cis_v200_1_4",
+			Title:       "Ensure no root access keys exist",
+			Description: "Root access keys give unrestricted access",
+			DocumentURI: "https://example.com/aws_cis_v200_1_4",
+			Severity:    types.FindingSeverityCritical,
+		},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatSARIF, &buf, rules)
+	require.NoError(t, err)
+	for _, f := range testFindings() {
+		require.NoError(t, w.WriteFinding(f))
+	}
+	require.NoError(t, w.Close())
+
+	// the streamed document has to be a single valid json object without unknown top level keys
+	var raw map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &raw))
+	assert.ElementsMatch(t, []string{"version", "$schema", "runs"}, keys(raw))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Equal(t, "https://json.schemastore.org/sarif-2.1.0.json", log.Schema)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "open-governance", run.Tool.Driver.Name)
+
+	require.Len(t, run.Tool.Driver.Rules, 2, "one rule per distinct control")
+	ruleIDs := make(map[string]bool)
+	for _, rule := range run.Tool.Driver.Rules {
+		assert.NotEmpty(t, rule.ID)
+		assert.False(t, ruleIDs[rule.ID], "rule ids are unique")
+		ruleIDs[rule.ID] = true
+		assert.True(t, sarifLevels[rule.DefaultConfiguration.Level], "invalid rule level %s", rule.DefaultConfiguration.Level)
+		assert.Contains(t, rule.Properties, "security-severity")
+	}
+	rule := run.Tool.Driver.Rules[0]
+	assert.Equal(t, "aws_cis_v200_1_4", rule.ID)
+	assert.Equal(t, "Ensure no root access keys exist", rule.Name)
+	require.NotNil(t, rule.ShortDescription)
+	require.NotNil(t, rule.FullDescription)
+	assert.Equal(t, "https://example.com/aws_cis_v200_1_4", rule.HelpURI)
+	assert.Equal(t, "error", rule.DefaultConfiguration.Level)
+	assert.Equal(t, "9.5", rule.Properties["security-severity"])
+	assert.Nil(t, run.Tool.Driver.Rules[1].ShortDescription, "rules without metadata have no description")
+	assert.Equal(t, "note", run.Tool.Driver.Rules[1].DefaultConfiguration.Level)
+
+	require.Len(t, run.Results, 3)
+	for _, result := range run.Results {
+		require.Less(t, result.RuleIndex, len(run.Tool.Driver.Rules))
+		assert.Equal(t, result.RuleID, run.Tool.Driver.Rules[result.RuleIndex].ID, "rule index points to the result rule")
+		assert.True(t, sarifKinds[result.Kind], "invalid result kind %s", result.Kind)
+		assert.True(t, sarifLevels[result.Level], "invalid result level %s", result.Level)
+		assert.NotEmpty(t, result.Message.Text, "message text is required")
+		require.Len(t, result.Locations, 1)
+		require.Len(t, result.Locations[0].LogicalLocations, 1)
+		assert.NotEmpty(t, result.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	}
+
+	failed := run.Results[0]
+	assert.Equal(t, "fail", failed.Kind)
+	assert.Equal(t, "error", failed.Level)
+	assert.Equal(t, "root has \"access keys\"", failed.Message.Text)
+	assert.Equal(t, "kaytu-res-1", failed.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, "root", failed.Locations[0].LogicalLocations[0].Name)
+	assert.Equal(t, "finding-1", failed.Properties["findingID"])
+
+	passed := run.Results[1]
+	assert.Equal(t, "pass", passed.Kind)
+	assert.Equal(t, "none", passed.Level, "passed results are not reported at a level")
+	assert.Equal(t, "passed", passed.Message.Text, "status is used when there is no reason")
+
+	assert.Equal(t, 0, run.Results[2].RuleIndex, "results of the same control share a rule")
+	assert.Equal(t, "warning", run.Results[2].Level)
+}
+
+func TestSARIFWriter_FindingEvents(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatSARIF, &buf, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteFindingEvent(testFindingEvent()))
+	require.NoError(t, w.Close())
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 1)
+	result := log.Runs[0].Results[0]
+	assert.Equal(t, "aws_cis_v200_1_4", result.RuleID)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "event-1", result.Properties["findingEventID"])
+	assert.Equal(t, "passed", result.Properties["previousConformanceStatus"])
+}
+
+func TestSARIFWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatSARIF, &buf, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Len(t, log.Runs, 1)
+	assert.Empty(t, log.Runs[0].Results)
+	assert.Empty(t, log.Runs[0].Tool.Driver.Rules)
+	assert.Contains(t, buf.String(), `"results":[]`, "results is an empty array, not null")
+	assert.Contains(t, buf.String(), `"rules":[]`)
+}
+
+func keys(m map[string]json.RawMessage) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
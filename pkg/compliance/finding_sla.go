@@ -0,0 +1,26 @@
+package compliance
+
+import (
+	"context"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"go.uber.org/zap"
+)
+
+// getFindingSLAPolicy returns the workspace SLA windows, falling back to the defaults for severities that are not configured
+func (h *HttpHandler) getFindingSLAPolicy(ctx context.Context) (api.FindingSLAPolicy, error) {
+	slas, err := h.db.ListFindingSLAs(ctx)
+	if err != nil {
+		h.logger.Error("failed to list finding slas", zap.Error(err))
+		return nil, err
+	}
+
+	policy := make(api.FindingSLAPolicy)
+	for severity, days := range api.DefaultFindingSLADays {
+		policy[severity] = days
+	}
+	for _, sla := range slas {
+		policy[sla.Severity] = sla.Days
+	}
+	return policy, nil
+}
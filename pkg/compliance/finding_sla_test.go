@@ -0,0 +1,56 @@
+package compliance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/es"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFindingSLAKPI(t *testing.T) {
+	policy := api.FindingSLAPolicy{
+		types.FindingSeverityCritical: 7,
+		types.FindingSeverityHigh:     30,
+		types.FindingSeverityLow:      180,
+	}
+	var res es.FindingSLAKPIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"aggregations": {
+		"severities": {"buckets": [
+			{"key": "low", "doc_count": 40},
+			{"key": "critical", "doc_count": 5},
+			{"key": "medium", "doc_count": 12}
+		]},
+		"overdue": {"buckets": {
+			"critical": {"doc_count": 2},
+			"high": {"doc_count": 0},
+			"low": {"doc_count": 9}
+		}}
+	}}`), &res))
+
+	kpi := newFindingSLAKPI(policy, &res)
+
+	assert.Equal(t, int64(11), kpi.OverdueFindingsCount)
+	// every severity is reported from critical to none, even without findings or an SLA
+	assert.Equal(t, []api.FindingSLASeverityKPI{
+		{Severity: types.FindingSeverityCritical, SLADays: 7, FailedFindingsCount: 5, OverdueFindingsCount: 2},
+		{Severity: types.FindingSeverityHigh, SLADays: 30},
+		{Severity: types.FindingSeverityMedium, FailedFindingsCount: 12},
+		{Severity: types.FindingSeverityLow, SLADays: 180, FailedFindingsCount: 40, OverdueFindingsCount: 9},
+		{Severity: types.FindingSeverityNone},
+	}, kpi.Severities)
+
+	t.Run("no severity has an sla", func(t *testing.T) {
+		// the overdue aggregation is left out of the query so the response has no buckets for it
+		var res es.FindingSLAKPIResponse
+		require.NoError(t, json.Unmarshal([]byte(`{"aggregations": {"severities": {"buckets": [{"key": "high", "doc_count": 3}]}}}`), &res))
+
+		kpi := newFindingSLAKPI(nil, &res)
+		assert.Zero(t, kpi.OverdueFindingsCount)
+		require.Len(t, kpi.Severities, len(api.FindingSLASeverities))
+		assert.Equal(t, api.FindingSLASeverityKPI{Severity: types.FindingSeverityHigh, FailedFindingsCount: 3}, kpi.Severities[1])
+	})
+}
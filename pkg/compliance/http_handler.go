@@ -20,6 +20,7 @@ import (
 
 	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
 	"github.com/kaytu-io/open-governance/pkg/compliance/db"
+	"github.com/kaytu-io/open-governance/pkg/compliance/notification"
 
 	"go.uber.org/zap"
 )
@@ -39,6 +40,8 @@ type HttpHandler struct {
 	metadataClient  metadataClient.MetadataServiceClient
 	openAIClient    *openai.Client
 	kubeClient      client.Client
+
+	notificationDispatcher *notification.Dispatcher
 }
 
 func NewKubeClient() (client.Client, error) {
@@ -146,6 +149,7 @@ func InitializeHttpHandler(
 	h.inventoryClient = inventoryClient.NewInventoryServiceClient(conf.Inventory.BaseURL)
 	h.metadataClient = metadataClient.NewMetadataServiceClient(conf.Metadata.BaseURL)
 	h.openAIClient = openai.NewClient(conf.OpenAI.Token)
+	h.notificationDispatcher = notification.NewDispatcher(logger, h.db, h.client)
 
 	kubeClient, err := NewKubeClient()
 	if err != nil {
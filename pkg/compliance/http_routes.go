@@ -2,7 +2,9 @@ package compliance
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,6 +20,8 @@ import (
 	"github.com/kaytu-io/open-governance/pkg/compliance/api"
 	"github.com/kaytu-io/open-governance/pkg/compliance/db"
 	"github.com/kaytu-io/open-governance/pkg/compliance/es"
+	"github.com/kaytu-io/open-governance/pkg/compliance/export"
+	"github.com/kaytu-io/open-governance/pkg/compliance/notification"
 	"github.com/kaytu-io/open-governance/pkg/compliance/runner"
 	"github.com/kaytu-io/open-governance/pkg/compliance/summarizer/types"
 	"github.com/kaytu-io/open-governance/pkg/demo"
@@ -66,6 +70,9 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	benchmarks.GET("/all", httpserver2.AuthorizeHandler(h.ListAllBenchmarks, authApi.InternalRole))
 	benchmarks.GET("/:benchmark_id", httpserver2.AuthorizeHandler(h.GetBenchmark, authApi.ViewerRole))
 	benchmarks.POST("/:benchmark_id/settings", httpserver2.AuthorizeHandler(h.ChangeBenchmarkSettings, authApi.AdminRole))
+	benchmarks.POST("/custom", httpserver2.AuthorizeHandler(h.CreateCustomBenchmark, authApi.EditorRole))
+	benchmarks.PUT("/custom/:benchmark_id", httpserver2.AuthorizeHandler(h.UpdateCustomBenchmark, authApi.EditorRole))
+	benchmarks.DELETE("/custom/:benchmark_id", httpserver2.AuthorizeHandler(h.DeleteCustomBenchmark, authApi.EditorRole))
 	benchmarks.GET("/controls/:control_id", httpserver2.AuthorizeHandler(h.GetControl, authApi.ViewerRole))
 	benchmarks.GET("/controls", httpserver2.AuthorizeHandler(h.ListControls, authApi.InternalRole))
 	benchmarks.GET("/queries", httpserver2.AuthorizeHandler(h.ListQueries, authApi.InternalRole))
@@ -73,6 +80,8 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	benchmarks.GET("/summary", httpserver2.AuthorizeHandler(h.ListBenchmarksSummary, authApi.ViewerRole))
 	benchmarks.GET("/:benchmark_id/summary", httpserver2.AuthorizeHandler(h.GetBenchmarkSummary, authApi.ViewerRole))
 	benchmarks.GET("/:benchmark_id/trend", httpserver2.AuthorizeHandler(h.GetBenchmarkTrend, authApi.ViewerRole))
+	benchmarks.GET("/:benchmark_id/diff", httpserver2.AuthorizeHandler(h.GetBenchmarkPostureDiff, authApi.ViewerRole))
+	benchmarks.GET("/:benchmark_id/evidence", httpserver2.AuthorizeHandler(h.DownloadBenchmarkEvidence, authApi.ViewerRole))
 	benchmarks.GET("/:benchmark_id/controls", httpserver2.AuthorizeHandler(h.GetBenchmarkControlsTree, authApi.ViewerRole))
 	benchmarks.GET("/:benchmark_id/controls/:controlId", httpserver2.AuthorizeHandler(h.GetBenchmarkControl, authApi.ViewerRole))
 
@@ -80,10 +89,18 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	controls.GET("/summary", httpserver2.AuthorizeHandler(h.ListControlsSummary, authApi.ViewerRole))
 	controls.GET("/:controlId/summary", httpserver2.AuthorizeHandler(h.GetControlSummary, authApi.ViewerRole))
 	controls.GET("/:controlId/trend", httpserver2.AuthorizeHandler(h.GetControlTrend, authApi.ViewerRole))
+	controls.POST("/custom", httpserver2.AuthorizeHandler(h.CreateCustomControl, authApi.EditorRole))
+	controls.POST("/custom/validate", httpserver2.AuthorizeHandler(h.ValidateCustomQuery, authApi.EditorRole))
+	controls.POST("/custom/dry-run", httpserver2.AuthorizeHandler(h.DryRunCustomQuery, authApi.EditorRole))
+	controls.PUT("/custom/:control_id", httpserver2.AuthorizeHandler(h.UpdateCustomControl, authApi.EditorRole))
+	controls.DELETE("/custom/:control_id", httpserver2.AuthorizeHandler(h.DeleteCustomControl, authApi.EditorRole))
 
 	queries := v1.Group("/queries")
 	queries.GET("/:query_id", httpserver2.AuthorizeHandler(h.GetQuery, authApi.ViewerRole))
 	queries.GET("/sync", httpserver2.AuthorizeHandler(h.SyncQueries, authApi.AdminRole))
+	queries.POST("/custom", httpserver2.AuthorizeHandler(h.CreateCustomQuery, authApi.EditorRole))
+	queries.PUT("/custom/:query_id", httpserver2.AuthorizeHandler(h.UpdateCustomQuery, authApi.EditorRole))
+	queries.DELETE("/custom/:query_id", httpserver2.AuthorizeHandler(h.DeleteCustomQuery, authApi.EditorRole))
 
 	assignments := v1.Group("/assignments")
 	assignments.GET("/benchmark/:benchmark_id", httpserver2.AuthorizeHandler(h.ListAssignmentsByBenchmark, authApi.ViewerRole))
@@ -97,6 +114,7 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 
 	findings := v1.Group("/findings")
 	findings.POST("", httpserver2.AuthorizeHandler(h.GetFindings, authApi.ViewerRole))
+	findings.POST("/export", httpserver2.AuthorizeHandler(h.ExportFindings, authApi.ViewerRole))
 	findings.POST("/resource", httpserver2.AuthorizeHandler(h.GetSingleResourceFinding, authApi.ViewerRole))
 	findings.GET("/single/:id", httpserver2.AuthorizeHandler(h.GetSingleFindingByFindingID, authApi.ViewerRole))
 	findings.GET("/events/:id", httpserver2.AuthorizeHandler(h.GetFindingEventsByFindingID, authApi.ViewerRole))
@@ -108,8 +126,41 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	findings.GET("/:benchmarkId/accounts", httpserver2.AuthorizeHandler(h.GetAccountsFindingsSummary, authApi.ViewerRole))
 	findings.GET("/:benchmarkId/services", httpserver2.AuthorizeHandler(h.GetServicesFindingsSummary, authApi.ViewerRole))
 
+	findingExceptions := v1.Group("/finding_exceptions")
+	findingExceptions.GET("", httpserver2.AuthorizeHandler(h.ListFindingExceptions, authApi.ViewerRole))
+	findingExceptions.POST("", httpserver2.AuthorizeHandler(h.CreateFindingException, authApi.EditorRole))
+	findingExceptions.GET("/:id", httpserver2.AuthorizeHandler(h.GetFindingException, authApi.ViewerRole))
+	findingExceptions.PUT("/:id", httpserver2.AuthorizeHandler(h.UpdateFindingException, authApi.EditorRole))
+	findingExceptions.DELETE("/:id", httpserver2.AuthorizeHandler(h.DeleteFindingException, authApi.EditorRole))
+
+	ownershipRules := v1.Group("/ownership_rules")
+	ownershipRules.GET("", httpserver2.AuthorizeHandler(h.ListOwnershipRules, authApi.ViewerRole))
+	ownershipRules.POST("", httpserver2.AuthorizeHandler(h.CreateOwnershipRule, authApi.EditorRole))
+	ownershipRules.GET("/:id", httpserver2.AuthorizeHandler(h.GetOwnershipRule, authApi.ViewerRole))
+	ownershipRules.PUT("/:id", httpserver2.AuthorizeHandler(h.UpdateOwnershipRule, authApi.EditorRole))
+	ownershipRules.DELETE("/:id", httpserver2.AuthorizeHandler(h.DeleteOwnershipRule, authApi.EditorRole))
+
+	findingSLA := v1.Group("/finding_sla")
+	findingSLA.GET("", httpserver2.AuthorizeHandler(h.GetFindingSLAs, authApi.ViewerRole))
+	findingSLA.PUT("", httpserver2.AuthorizeHandler(h.UpdateFindingSLAs, authApi.AdminRole))
+
+	securityScore := v1.Group("/security_score")
+	securityScore.GET("/weights", httpserver2.AuthorizeHandler(h.GetSecurityScoreWeights, authApi.ViewerRole))
+	securityScore.PUT("/weights", httpserver2.AuthorizeHandler(h.UpdateSecurityScoreWeights, authApi.AdminRole))
+
+	notifications := v1.Group("/notifications")
+	notifications.GET("/subscriptions", httpserver2.AuthorizeHandler(h.ListNotificationSubscriptions, authApi.ViewerRole))
+	notifications.POST("/subscriptions", httpserver2.AuthorizeHandler(h.CreateNotificationSubscription, authApi.AdminRole))
+	notifications.GET("/subscriptions/:id", httpserver2.AuthorizeHandler(h.GetNotificationSubscription, authApi.ViewerRole))
+	notifications.PUT("/subscriptions/:id", httpserver2.AuthorizeHandler(h.UpdateNotificationSubscription, authApi.AdminRole))
+	notifications.DELETE("/subscriptions/:id", httpserver2.AuthorizeHandler(h.DeleteNotificationSubscription, authApi.AdminRole))
+	notifications.GET("/subscriptions/:id/deliveries", httpserver2.AuthorizeHandler(h.ListNotificationDeliveries, authApi.ViewerRole))
+	notifications.POST("/subscriptions/:id/test", httpserver2.AuthorizeHandler(h.TestNotificationSubscription, authApi.AdminRole))
+	notifications.POST("/compliance_jobs/:job_id/dispatch", httpserver2.AuthorizeHandler(h.DispatchComplianceJobNotifications, authApi.InternalRole))
+
 	findingEvents := v1.Group("/finding_events")
 	findingEvents.POST("", httpserver2.AuthorizeHandler(h.GetFindingEvents, authApi.ViewerRole))
+	findingEvents.POST("/export", httpserver2.AuthorizeHandler(h.ExportFindingEvents, authApi.ViewerRole))
 	findingEvents.POST("/filters", httpserver2.AuthorizeHandler(h.GetFindingEventFilterValues, authApi.ViewerRole))
 	findingEvents.GET("/count", httpserver2.AuthorizeHandler(h.CountFindingEvents, authApi.ViewerRole))
 	findingEvents.GET("/single/:id", httpserver2.AuthorizeHandler(h.GetSingleFindingEvent, authApi.ViewerRole))
@@ -304,7 +355,7 @@ func (h *HttpHandler) GetFindings(echoCtx echo.Context) error {
 	res, totalCount, err := es.FindingsQuery(ctx, h.logger, h.client, req.Filters.ResourceID, req.Filters.Connector,
 		connectionIDs, req.Filters.NotConnectionID, req.Filters.ResourceTypeID, req.Filters.BenchmarkID,
 		req.Filters.ControlID, req.Filters.Severity, lastEventFrom, lastEventTo, evaluatedAtFrom, evaluatedAtTo,
-		req.Filters.StateActive, esConformanceStatuses, req.Sort, req.Limit, req.AfterSortKey, req.Filters.JobID)
+		req.Filters.StateActive, esConformanceStatuses, req.Sort, req.Limit, req.AfterSortKey, req.Filters.JobID, req.Filters.Owner)
 	if err != nil {
 		h.logger.Error("failed to get findings", zap.Error(err))
 		return err
@@ -344,6 +395,12 @@ func (h *HttpHandler) GetFindings(echoCtx echo.Context) error {
 		resourceTypeMetadataMap[strings.ToLower(item.ResourceType)] = &item
 	}
 
+	slaPolicy, err := h.getFindingSLAPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
 	for _, h := range res {
 		finding := api.GetAPIFindingFromESFinding(h.Source)
 
@@ -366,6 +423,7 @@ func (h *HttpHandler) GetFindings(echoCtx echo.Context) error {
 			finding.ResourceTypeName = rtMetadata.ResourceLabel
 		}
 
+		slaPolicy.Apply(&finding, now)
 		finding.SortKey = h.Sort
 
 		response.Findings = append(response.Findings, finding)
@@ -408,6 +466,242 @@ func (h *HttpHandler) GetFindings(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// ExportFindings godoc
+//
+//	@Summary		Export findings
+//	@Description	Streaming all compliance run findings with respect to filters as a file. Limit and afterSortKey are ignored.
+//	@Tags			compliance
+//	@Security		BearerToken
+//	@Accept			json
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		application/sarif+json
+//	@Param			format	query	string					false	"Export format, csv (default), jsonl or sarif"	Enums(csv, jsonl, sarif)
+//	@Param			request	body	api.GetFindingsRequest	true	"Request Body"
+//	@Success		200
+//	@Router			/compliance/api/v1/findings/export [post]
+func (h *HttpHandler) ExportFindings(echoCtx echo.Context) error {
+	var err error
+	ctx := echoCtx.Request().Context()
+
+	format, err := export.ParseFormat(echoCtx.QueryParam("format"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var req api.GetFindingsRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	req.Filters.ConnectionID, err = h.getConnectionIdFilterFromInputs(ctx, req.Filters.ConnectionID, req.Filters.ConnectionGroup)
+	if err != nil {
+		return err
+	}
+	req.Filters.ConnectionID, err = httpserver2.ResolveConnectionIDs(echoCtx, req.Filters.ConnectionID)
+	if err != nil {
+		return err
+	}
+
+	if len(req.Filters.ConformanceStatus) == 0 {
+		req.Filters.ConformanceStatus = []api.ConformanceStatus{api.ConformanceStatusFailed}
+	}
+	esConformanceStatuses := make([]kaytuTypes.ConformanceStatus, 0, len(req.Filters.ConformanceStatus))
+	for _, status := range req.Filters.ConformanceStatus {
+		esConformanceStatuses = append(esConformanceStatuses, status.GetEsConformanceStatuses()...)
+	}
+
+	if len(req.Sort) == 0 {
+		req.Sort = []api.FindingsSort{
+			{ConformanceStatus: utils.GetPointer(api.SortDirectionDescending)},
+		}
+	}
+
+	var lastEventFrom, lastEventTo, evaluatedAtFrom, evaluatedAtTo *time.Time
+	if req.Filters.LastEvent.From != nil && *req.Filters.LastEvent.From != 0 {
+		lastEventFrom = utils.GetPointer(time.Unix(*req.Filters.LastEvent.From, 0))
+	}
+	if req.Filters.LastEvent.To != nil && *req.Filters.LastEvent.To != 0 {
+		lastEventTo = utils.GetPointer(time.Unix(*req.Filters.LastEvent.To, 0))
+	}
+	if req.Filters.EvaluatedAt.From != nil && *req.Filters.EvaluatedAt.From != 0 {
+		evaluatedAtFrom = utils.GetPointer(time.Unix(*req.Filters.EvaluatedAt.From, 0))
+	}
+	if req.Filters.EvaluatedAt.To != nil && *req.Filters.EvaluatedAt.To != 0 {
+		evaluatedAtTo = utils.GetPointer(time.Unix(*req.Filters.EvaluatedAt.To, 0))
+	}
+	if req.Filters.Interval != nil {
+		evaluatedAtFrom, evaluatedAtTo, err = parseTimeInterval(*req.Filters.Interval)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	var connectionIDs []string
+	allSources, err := h.onboardClient.ListSources(httpclient.FromEchoContext(echoCtx), nil)
+	if err != nil {
+		h.logger.Error("failed to get sources", zap.Error(err))
+		return err
+	}
+	allSourcesMap := make(map[string]*onboardApi.Connection)
+	for _, src := range allSources {
+		if src.HealthState != source.HealthStatusHealthy {
+			continue
+		}
+		src := src
+		allSourcesMap[src.ID.String()] = &src
+		connectionIDs = append(connectionIDs, src.ID.String())
+	}
+	if len(req.Filters.ConnectionID) > 0 {
+		connectionIDs = req.Filters.ConnectionID
+	}
+
+	benchmarks, err := h.db.ListBenchmarksBare(ctx)
+	if err != nil {
+		h.logger.Error("failed to get benchmarks", zap.Error(err))
+		return err
+	}
+	benchmarksMap := make(map[string]*db.Benchmark)
+	for _, benchmark := range benchmarks {
+		benchmark := benchmark
+		benchmarksMap[benchmark.ID] = &benchmark
+	}
+
+	rules, resourceTypeMetadataMap, err := h.getExportMetadata(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	slaPolicy, err := h.getFindingSLAPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	paginator, err := es.NewFindingsQueryPaginator(h.client, req.Filters.ResourceID, req.Filters.Connector,
+		connectionIDs, req.Filters.NotConnectionID, req.Filters.ResourceTypeID, req.Filters.BenchmarkID,
+		req.Filters.ControlID, req.Filters.Severity, lastEventFrom, lastEventTo, evaluatedAtFrom, evaluatedAtTo,
+		req.Filters.StateActive, esConformanceStatuses, req.Sort, req.Filters.JobID, req.Filters.Owner)
+	if err != nil {
+		h.logger.Error("failed to create findings paginator", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if err := paginator.Close(ctx); err != nil {
+			h.logger.Error("failed to close findings paginator", zap.Error(err))
+		}
+	}()
+
+	writer, err := h.startExport(echoCtx, format, "findings", rules)
+	if err != nil {
+		return err
+	}
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			// headers are already sent, the only option left is to cut the stream
+			h.logger.Error("failed to get findings page", zap.Error(err))
+			return err
+		}
+
+		findings := make([]api.Finding, 0, len(page))
+		kaytuResourceIds := make([]string, 0, len(page))
+		for _, esFinding := range page {
+			finding := api.GetAPIFindingFromESFinding(esFinding)
+			for _, parentBenchmark := range esFinding.ParentBenchmarks {
+				if benchmark, ok := benchmarksMap[parentBenchmark]; ok {
+					finding.ParentBenchmarkNames = append(finding.ParentBenchmarkNames, benchmark.Title)
+				}
+			}
+			if src, ok := allSourcesMap[finding.ConnectionID]; ok {
+				finding.ProviderConnectionID = demo.EncodeResponseData(echoCtx, src.ConnectionID)
+				finding.ProviderConnectionName = demo.EncodeResponseData(echoCtx, src.ConnectionName)
+			}
+			if rule, ok := rules[finding.ControlID]; ok {
+				finding.ControlTitle = rule.Title
+			}
+			if rtMetadata, ok := resourceTypeMetadataMap[strings.ToLower(finding.ResourceType)]; ok {
+				finding.ResourceTypeName = rtMetadata.ResourceLabel
+			}
+			slaPolicy.Apply(&finding, time.Now())
+			findings = append(findings, finding)
+			kaytuResourceIds = append(kaytuResourceIds, finding.KaytuResourceID)
+		}
+
+		lookupResourcesMap, err := es.FetchLookupByResourceIDBatch(ctx, h.client, kaytuResourceIds)
+		if err != nil {
+			h.logger.Error("failed to fetch lookup resources", zap.Error(err))
+			return err
+		}
+		for _, finding := range findings {
+			for _, r := range lookupResourcesMap[finding.KaytuResourceID] {
+				if strings.ToLower(r.ResourceType) == strings.ToLower(finding.ResourceType) {
+					finding.ResourceName = r.Name
+					finding.ResourceLocation = r.Location
+					break
+				}
+			}
+			if err := writer.WriteFinding(finding); err != nil {
+				h.logger.Error("failed to write finding", zap.Error(err))
+				return err
+			}
+		}
+		echoCtx.Response().Flush()
+	}
+
+	if err := writer.Close(); err != nil {
+		h.logger.Error("failed to finish findings export", zap.Error(err))
+		return err
+	}
+	echoCtx.Response().Flush()
+	return nil
+}
+
+// getExportMetadata returns the control rules and resource type metadata used to enrich exported items
+func (h *HttpHandler) getExportMetadata(echoCtx echo.Context) (map[string]export.Rule, map[string]*inventoryApi.ResourceType, error) {
+	controls, err := h.db.ListControls(echoCtx.Request().Context(), nil, nil)
+	if err != nil {
+		h.logger.Error("failed to get controls", zap.Error(err))
+		return nil, nil, err
+	}
+	rules := make(map[string]export.Rule)
+	for _, control := range controls {
+		rules[control.ID] = export.Rule{
+			ID:          control.ID,
+			Title:       control.Title,
+			Description: control.Description,
+			DocumentURI: control.DocumentURI,
+			Severity:    control.Severity,
+		}
+	}
+
+	resourceTypeMetadata, err := h.inventoryClient.ListResourceTypesMetadata(httpclient.FromEchoContext(echoCtx),
+		nil, nil, nil, false, nil, 10000, 1)
+	if err != nil {
+		h.logger.Error("failed to get resource type metadata", zap.Error(err))
+		return nil, nil, err
+	}
+	resourceTypeMetadataMap := make(map[string]*inventoryApi.ResourceType)
+	for _, item := range resourceTypeMetadata.ResourceTypes {
+		item := item
+		resourceTypeMetadataMap[strings.ToLower(item.ResourceType)] = &item
+	}
+
+	return rules, resourceTypeMetadataMap, nil
+}
+
+// startExport writes the download headers and returns a writer streaming to the response body
+func (h *HttpHandler) startExport(echoCtx echo.Context, format export.Format, name string, rules map[string]export.Rule) (export.Writer, error) {
+	writer, err := export.NewWriter(format, echoCtx.Response(), rules)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102T150405Z"), format.FileExtension())
+	echoCtx.Response().Header().Set(echo.HeaderContentType, format.ContentType())
+	echoCtx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	echoCtx.Response().WriteHeader(http.StatusOK)
+	return writer, nil
+}
+
 // GetFindingEventsByFindingID godoc
 //
 //	@Summary		Get finding events by finding ID
@@ -548,6 +842,12 @@ func (h *HttpHandler) GetSingleResourceFinding(echoCtx echo.Context) error {
 		resourceTypeMetadataMap[strings.ToLower(item.ResourceType)] = &item
 	}
 
+	slaPolicy, err := h.getFindingSLAPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
 	findingsIDs := make([]string, 0, len(controlFindings))
 	for _, controlFinding := range controlFindings {
 		findingsIDs = append(findingsIDs, controlFinding.EsID)
@@ -575,6 +875,7 @@ func (h *HttpHandler) GetSingleResourceFinding(echoCtx echo.Context) error {
 			finding.ResourceTypeName = rtMetadata.ResourceLabel
 		}
 
+		slaPolicy.Apply(&finding, now)
 		response.ControlFindings = append(response.ControlFindings, finding)
 	}
 
@@ -619,6 +920,12 @@ func (h *HttpHandler) GetSingleFindingByFindingID(echoCtx echo.Context) error {
 
 	apiFinding := api.GetAPIFindingFromESFinding(*finding)
 
+	slaPolicy, err := h.getFindingSLAPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	slaPolicy.Apply(&apiFinding, time.Now())
+
 	connection, err := h.onboardClient.GetSource(httpclient.FromEchoContext(echoCtx), finding.ConnectionID)
 	if err != nil {
 		h.logger.Error("failed to get connection", zap.Error(err), zap.String("connection_id", finding.ConnectionID))
@@ -990,9 +1297,40 @@ func (h *HttpHandler) GetFindingKPIs(echoCtx echo.Context) error {
 		FailedControlCount:    kpiRes.Aggregations.ControlCount.Value,
 		FailedConnectionCount: kpiRes.Aggregations.ConnectionCount.Value,
 	}
+
+	slaPolicy, err := h.getFindingSLAPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	slaRes, err := es.FindingSLAKPIQuery(ctx, h.logger, h.client, slaPolicy, time.Now())
+	if err != nil {
+		h.logger.Error("failed to get finding sla kpis", zap.Error(err))
+		return err
+	}
+	response.SLA = newFindingSLAKPI(slaPolicy, slaRes)
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// newFindingSLAKPI reports the failing and overdue findings of every severity, including the ones with no findings
+func newFindingSLAKPI(policy api.FindingSLAPolicy, res *es.FindingSLAKPIResponse) api.FindingSLAKPI {
+	var kpi api.FindingSLAKPI
+	failedCounts := make(map[kaytuTypes.FindingSeverity]int64)
+	for _, bucket := range res.Aggregations.Severities.Buckets {
+		failedCounts[kaytuTypes.FindingSeverity(bucket.Key)] = bucket.DocCount
+	}
+	for _, severity := range api.FindingSLASeverities {
+		overdueCount := res.Aggregations.Overdue.Buckets[string(severity)].DocCount
+		kpi.OverdueFindingsCount += overdueCount
+		kpi.Severities = append(kpi.Severities, api.FindingSLASeverityKPI{
+			Severity:             severity,
+			SLADays:              policy[severity],
+			FailedFindingsCount:  failedCounts[severity],
+			OverdueFindingsCount: overdueCount,
+		})
+	}
+	return kpi
+}
+
 // GetTopFieldByFindingCount godoc
 //
 //	@Summary		Get top field by finding count
@@ -1001,7 +1339,7 @@ func (h *HttpHandler) GetFindingKPIs(echoCtx echo.Context) error {
 //	@Tags			compliance
 //	@Accept			json
 //	@Produce		json
-//	@Param			field				path		string							true	"Field"	Enums(resourceType,connectionID,resourceID,service,controlID)
+//	@Param			field				path		string							true	"Field"	Enums(resourceType,connectionID,resourceID,service,controlID,owner)
 //	@Param			count				path		int								true	"Count"
 //	@Param			connectionId		query		[]string						false	"Connection IDs to filter by (inclusive)"
 //	@Param			notConnectionId		query		[]string						false	"Connection IDs to filter by (exclusive)"
@@ -1386,6 +1724,28 @@ func (h *HttpHandler) GetTopFieldByFindingCount(echoCtx echo.Context) error {
 		}
 
 		response.TotalCount = topFieldTotalResponse.Aggregations.BucketCount.Value
+	case "owner":
+		totalCountMap := make(map[string]int)
+		for _, item := range topFieldTotalResponse.Aggregations.FieldFilter.Buckets {
+			if item.Key == "" {
+				continue
+			}
+			totalCountMap[item.Key] += item.DocCount
+		}
+
+		for _, item := range topFieldResponse.Aggregations.FieldFilter.Buckets {
+			// findings no ownership rule matched are left out
+			if item.Key == "" {
+				continue
+			}
+			item := item
+			response.Records = append(response.Records, api.TopFieldRecord{
+				Field:      &item.Key,
+				Count:      item.DocCount,
+				TotalCount: totalCountMap[item.Key],
+			})
+		}
+		response.TotalCount = len(totalCountMap)
 	default:
 		totalCountMap := make(map[string]int)
 		for _, item := range topFieldTotalResponse.Aggregations.FieldFilter.Buckets {
@@ -1839,44 +2199,192 @@ func (h *HttpHandler) GetFindingEvents(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
-// CountFindingEvents godoc
+// ExportFindingEvents godoc
 //
-//	@Summary		Get finding events count
-//	@Description	Retrieving all compliance run finding events count with respect to filters.
-//	@Security		BearerToken
+//	@Summary		Export finding events
+//	@Description	Streaming all compliance finding events with respect to filters as a file. Limit and afterSortKey are ignored.
 //	@Tags			compliance
+//	@Security		BearerToken
 //	@Accept			json
-//	@Produce		json
-//	@Param			conformanceStatus	query		[]api.ConformanceStatus	false	"ConformanceStatus to filter by defaults to all conformanceStatus except passed"
-//	@Param			benchmarkID			query		[]string				false	"BenchmarkID to filter by"
-//	@Param			stateActive			query		[]bool					false	"StateActive to filter by defaults to all stateActives"
-//	@Param			startTime			query		int64					false	"Start time to filter by"
-//	@Param			endTime				query		int64					false	"End time to filter by"
-//	@Success		200					{object}	api.CountFindingEventsResponse
-//	@Router			/compliance/api/v1/finding_events/count [get]
-func (h *HttpHandler) CountFindingEvents(echoCtx echo.Context) error {
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		application/sarif+json
+//	@Param			format	query	string						false	"Export format, csv (default), jsonl or sarif"	Enums(csv, jsonl, sarif)
+//	@Param			request	body	api.GetFindingEventsRequest	true	"Request Body"
+//	@Success		200
+//	@Router			/compliance/api/v1/finding_events/export [post]
+func (h *HttpHandler) ExportFindingEvents(echoCtx echo.Context) error {
+	var err error
 	ctx := echoCtx.Request().Context()
 
-	conformanceStatuses := api.ParseConformanceStatuses(httpserver2.QueryArrayParam(echoCtx, "conformanceStatus"))
-	if len(conformanceStatuses) == 0 {
-		conformanceStatuses = []api.ConformanceStatus{api.ConformanceStatusFailed}
+	format, err := export.ParseFormat(echoCtx.QueryParam("format"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	esConformanceStatuses := make([]kaytuTypes.ConformanceStatus, 0, len(conformanceStatuses))
-	for _, status := range conformanceStatuses {
-		esConformanceStatuses = append(esConformanceStatuses, status.GetEsConformanceStatuses()...)
+	var req api.GetFindingEventsRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	benchmarkIDs := httpserver2.QueryArrayParam(echoCtx, "benchmarkID")
+	req.Filters.ConnectionID, err = h.getConnectionIdFilterFromInputs(ctx, req.Filters.ConnectionID, req.Filters.ConnectionGroup)
+	if err != nil {
+		return err
+	}
+	req.Filters.ConnectionID, err = httpserver2.ResolveConnectionIDs(echoCtx, req.Filters.ConnectionID)
+	if err != nil {
+		return err
+	}
 
-	var stateActive []bool
-	stateActiveStr := httpserver2.QueryArrayParam(echoCtx, "stateActive")
-	for _, s := range stateActiveStr {
-		sa, err := strconv.ParseBool(s)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, "invalid stateActive")
-		}
-		stateActive = append(stateActive, sa)
+	if len(req.Filters.ConformanceStatus) == 0 {
+		req.Filters.ConformanceStatus = []api.ConformanceStatus{api.ConformanceStatusFailed}
+	}
+	esConformanceStatuses := make([]kaytuTypes.ConformanceStatus, 0, len(req.Filters.ConformanceStatus))
+	for _, status := range req.Filters.ConformanceStatus {
+		esConformanceStatuses = append(esConformanceStatuses, status.GetEsConformanceStatuses()...)
+	}
+
+	if len(req.Sort) == 0 {
+		req.Sort = []api.FindingEventsSort{
+			{ConformanceStatus: utils.GetPointer(api.SortDirectionDescending)},
+		}
+	}
+
+	var evaluatedAtFrom, evaluatedAtTo *time.Time
+	if req.Filters.EvaluatedAt.From != nil && *req.Filters.EvaluatedAt.From != 0 {
+		evaluatedAtFrom = utils.GetPointer(time.Unix(*req.Filters.EvaluatedAt.From, 0))
+	}
+	if req.Filters.EvaluatedAt.To != nil && *req.Filters.EvaluatedAt.To != 0 {
+		evaluatedAtTo = utils.GetPointer(time.Unix(*req.Filters.EvaluatedAt.To, 0))
+	}
+
+	allSources, err := h.onboardClient.ListSources(httpclient.FromEchoContext(echoCtx), nil)
+	if err != nil {
+		h.logger.Error("failed to get sources", zap.Error(err))
+		return err
+	}
+	allConnectionsMap := make(map[string]*onboardApi.Connection)
+	for _, src := range allSources {
+		src := src
+		allConnectionsMap[src.ID.String()] = &src
+	}
+
+	rules, resourceTypeMetadataMap, err := h.getExportMetadata(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	paginator, err := es.NewFindingEventsQueryPaginator(h.client,
+		req.Filters.FindingID, req.Filters.KaytuResourceID,
+		req.Filters.Connector, req.Filters.ConnectionID, req.Filters.NotConnectionID,
+		req.Filters.ResourceType,
+		req.Filters.BenchmarkID, req.Filters.ControlID, req.Filters.Severity,
+		evaluatedAtFrom, evaluatedAtTo,
+		req.Filters.StateActive, esConformanceStatuses, req.Sort)
+	if err != nil {
+		h.logger.Error("failed to create finding events paginator", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if err := paginator.Close(ctx); err != nil {
+			h.logger.Error("failed to close finding events paginator", zap.Error(err))
+		}
+	}()
+
+	writer, err := h.startExport(echoCtx, format, "finding-events", rules)
+	if err != nil {
+		return err
+	}
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			// headers are already sent, the only option left is to cut the stream
+			h.logger.Error("failed to get finding events page", zap.Error(err))
+			return err
+		}
+
+		findingEvents := make([]api.FindingEvent, 0, len(page))
+		kaytuResourceIds := make([]string, 0, len(page))
+		for _, esFindingEvent := range page {
+			findingEvent := api.GetAPIFindingEventFromESFindingEvent(esFindingEvent)
+			if rtMetadata, ok := resourceTypeMetadataMap[strings.ToLower(findingEvent.ResourceType)]; ok {
+				findingEvent.ResourceTypeName = rtMetadata.ResourceLabel
+			}
+			if connection, ok := allConnectionsMap[findingEvent.ConnectionID]; ok {
+				findingEvent.ProviderConnectionID = demo.EncodeResponseData(echoCtx, connection.ConnectionID)
+				findingEvent.ProviderConnectionName = demo.EncodeResponseData(echoCtx, connection.ConnectionName)
+			}
+			findingEvents = append(findingEvents, findingEvent)
+			kaytuResourceIds = append(kaytuResourceIds, findingEvent.KaytuResourceID)
+		}
+
+		lookupResourcesMap, err := es.FetchLookupByResourceIDBatch(ctx, h.client, kaytuResourceIds)
+		if err != nil {
+			h.logger.Error("failed to fetch lookup resources", zap.Error(err))
+			return err
+		}
+		for _, findingEvent := range findingEvents {
+			for _, r := range lookupResourcesMap[findingEvent.KaytuResourceID] {
+				if strings.ToLower(r.ResourceType) == strings.ToLower(findingEvent.ResourceType) {
+					findingEvent.ResourceName = r.Name
+					findingEvent.ResourceLocation = r.Location
+					break
+				}
+			}
+			if err := writer.WriteFindingEvent(findingEvent); err != nil {
+				h.logger.Error("failed to write finding event", zap.Error(err))
+				return err
+			}
+		}
+		echoCtx.Response().Flush()
+	}
+
+	if err := writer.Close(); err != nil {
+		h.logger.Error("failed to finish finding events export", zap.Error(err))
+		return err
+	}
+	echoCtx.Response().Flush()
+	return nil
+}
+
+// CountFindingEvents godoc
+//
+//	@Summary		Get finding events count
+//	@Description	Retrieving all compliance run finding events count with respect to filters.
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			conformanceStatus	query		[]api.ConformanceStatus	false	"ConformanceStatus to filter by defaults to all conformanceStatus except passed"
+//	@Param			benchmarkID			query		[]string				false	"BenchmarkID to filter by"
+//	@Param			stateActive			query		[]bool					false	"StateActive to filter by defaults to all stateActives"
+//	@Param			startTime			query		int64					false	"Start time to filter by"
+//	@Param			endTime				query		int64					false	"End time to filter by"
+//	@Success		200					{object}	api.CountFindingEventsResponse
+//	@Router			/compliance/api/v1/finding_events/count [get]
+func (h *HttpHandler) CountFindingEvents(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	conformanceStatuses := api.ParseConformanceStatuses(httpserver2.QueryArrayParam(echoCtx, "conformanceStatus"))
+	if len(conformanceStatuses) == 0 {
+		conformanceStatuses = []api.ConformanceStatus{api.ConformanceStatusFailed}
+	}
+
+	esConformanceStatuses := make([]kaytuTypes.ConformanceStatus, 0, len(conformanceStatuses))
+	for _, status := range conformanceStatuses {
+		esConformanceStatuses = append(esConformanceStatuses, status.GetEsConformanceStatuses()...)
+	}
+
+	benchmarkIDs := httpserver2.QueryArrayParam(echoCtx, "benchmarkID")
+
+	var stateActive []bool
+	stateActiveStr := httpserver2.QueryArrayParam(echoCtx, "stateActive")
+	for _, s := range stateActiveStr {
+		sa, err := strconv.ParseBool(s)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid stateActive")
+		}
+		stateActive = append(stateActive, sa)
 	}
 
 	var endTime *time.Time
@@ -1908,6 +2416,144 @@ func (h *HttpHandler) CountFindingEvents(echoCtx echo.Context) error {
 	return echoCtx.JSON(http.StatusOK, response)
 }
 
+// GetBenchmarkPostureDiff godoc
+//
+//	@Summary		Get benchmark posture diff
+//	@Description	Retrieving the resource and control pairs which started failing, started passing or disappeared between two compliance jobs or two points in time.
+//	@Description	Computed from finding events, so the benchmark has to track drift events.
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			benchmark_id	path		string		true	"Benchmark ID"
+//	@Param			baseJobId		query		int			false	"Compliance job ID to compare from"
+//	@Param			targetJobId		query		int			false	"Compliance job ID to compare to"
+//	@Param			baseTime		query		int			false	"Timestamp to compare from in epoch seconds, used when job IDs are not given"
+//	@Param			targetTime		query		int			false	"Timestamp to compare to in epoch seconds, defaults to now"
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by"
+//	@Param			connectionGroup	query		[]string	false	"Connection groups to filter by "
+//	@Success		200				{object}	api.GetPostureDiffResponse
+//	@Router			/compliance/api/v1/benchmarks/{benchmark_id}/diff [get]
+func (h *HttpHandler) GetBenchmarkPostureDiff(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	benchmarkID := echoCtx.Param("benchmark_id")
+	connectionIDs, err := h.getConnectionIdFilterFromParams(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	diffRange, err := parsePostureDiffRange(echoCtx.QueryParam("baseJobId"), echoCtx.QueryParam("targetJobId"),
+		echoCtx.QueryParam("baseTime"), echoCtx.QueryParam("targetTime"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	benchmark, err := h.db.GetBenchmarkBare(ctx, benchmarkID)
+	if err != nil {
+		h.logger.Error("failed to get benchmark", zap.Error(err), zap.String("benchmarkId", benchmarkID))
+		return err
+	}
+	if benchmark == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "benchmark not found")
+	}
+
+	response, err := h.getPostureDiff(ctx, httpclient.FromEchoContext(echoCtx), benchmarkID, connectionIDs, diffRange)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// DownloadBenchmarkEvidence godoc
+//
+//	@Summary		Download benchmark evidence
+//	@Description	Downloads the resource evidence captured during a benchmark compliance job as a zip archive.
+//	@Description	The archive holds one json file per evidence, a manifest.json and a SHA256SUMS file. The benchmark has to capture evidence.
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Produce		application/zip
+//	@Param			benchmark_id	path	string		true	"Benchmark ID"
+//	@Param			jobId			query	int			false	"Compliance job ID, defaults to the latest job of the benchmark"
+//	@Param			connectionId	query	[]string	false	"Connection IDs to filter by"
+//	@Success		200
+//	@Router			/compliance/api/v1/benchmarks/{benchmark_id}/evidence [get]
+func (h *HttpHandler) DownloadBenchmarkEvidence(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+	benchmarkID := echoCtx.Param("benchmark_id")
+
+	connectionIDs, err := httpserver2.ResolveConnectionIDs(echoCtx, httpserver2.QueryArrayParam(echoCtx, ConnectionIdParam))
+	if err != nil {
+		return err
+	}
+
+	benchmark, err := h.db.GetBenchmarkBare(ctx, benchmarkID)
+	if err != nil {
+		h.logger.Error("failed to get benchmark", zap.Error(err), zap.String("benchmarkId", benchmarkID))
+		return err
+	}
+	if benchmark == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "benchmark not found")
+	}
+
+	var jobID uint
+	if jobIDStr := echoCtx.QueryParam("jobId"); jobIDStr != "" {
+		id, err := strconv.ParseUint(jobIDStr, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid jobId")
+		}
+		jobID = uint(id)
+	} else {
+		lastJob, err := h.schedulerClient.GetLatestComplianceJobForBenchmark(httpclient.FromEchoContext(echoCtx), benchmarkID)
+		if err != nil {
+			h.logger.Error("failed to get latest compliance job for benchmark", zap.Error(err), zap.String("benchmarkID", benchmarkID))
+			return err
+		}
+		if lastJob == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "compliance job not found")
+		}
+		jobID = lastJob.ID
+	}
+
+	paginator, err := es.NewComplianceEvidencePaginator(h.client, benchmarkID, jobID, connectionIDs)
+	if err != nil {
+		h.logger.Error("failed to create evidence paginator", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if err := paginator.Close(ctx); err != nil {
+			h.logger.Error("failed to close evidence paginator", zap.Error(err))
+		}
+	}()
+
+	filename := fmt.Sprintf("evidence-%s-%d.zip", benchmarkID, jobID)
+	echoCtx.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	echoCtx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	echoCtx.Response().WriteHeader(http.StatusOK)
+
+	bundle := export.NewEvidenceBundle(echoCtx.Response(), benchmarkID, jobID)
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			// headers are already sent, the only option left is to cut the stream
+			h.logger.Error("failed to get evidence page", zap.Error(err))
+			return err
+		}
+		for _, evidence := range page {
+			if err := bundle.Add(evidence); err != nil {
+				h.logger.Error("failed to write evidence", zap.Error(err))
+				return err
+			}
+		}
+	}
+	if err := bundle.Close(); err != nil {
+		h.logger.Error("failed to close evidence bundle", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 // ChangeBenchmarkSettings godoc
 //
 //	@Summary		change benchmark settings
@@ -1916,22 +2562,81 @@ func (h *HttpHandler) CountFindingEvents(echoCtx echo.Context) error {
 //	@Tags			compliance
 //	@Accept			json
 //	@Produce		json
-//	@Param			benchmark_id		path	string	false	"BenchmarkID"
-//	@Param			tracksDriftEvents	query	bool	false	"tracksDriftEvents"
+//	@Param			benchmark_id		path	string								false	"BenchmarkID"
+//	@Param			tracksDriftEvents	query	bool								false	"tracksDriftEvents"
+//	@Param			capturesEvidence	query	bool								false	"capturesEvidence"
+//	@Param			request				body	api.ChangeBenchmarkSettingsRequest	false	"Control weight overrides of the weighted security score"
 //	@Success		200
 //	@Router			/compliance/api/v1/benchmarks/{benchmark_id}/settings [post]
 func (h *HttpHandler) ChangeBenchmarkSettings(echoCtx echo.Context) error {
 	ctx := echoCtx.Request().Context()
+	benchmarkID := echoCtx.Param("benchmark_id")
+
+	var req api.ChangeBenchmarkSettingsRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
 	tracksDriftEvents := echoCtx.QueryParam("tracksDriftEvents") == "true"
 	if len(echoCtx.QueryParam("tracksDriftEvents")) > 0 {
-		benchmarkID := echoCtx.Param("benchmark_id")
 		err := h.db.UpdateBenchmarkTrackDriftEvents(ctx, benchmarkID, tracksDriftEvents)
 		if err != nil {
 			return err
 		}
 	}
 
+	capturesEvidence := echoCtx.QueryParam("capturesEvidence") == "true"
+	if len(echoCtx.QueryParam("capturesEvidence")) > 0 {
+		err := h.db.UpdateBenchmarkCapturesEvidence(ctx, benchmarkID, capturesEvidence)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(req.ControlWeights) > 0 {
+		benchmark, err := h.db.GetBenchmarkBare(ctx, benchmarkID)
+		if err != nil {
+			h.logger.Error("failed to get benchmark", zap.Error(err))
+			return err
+		}
+		if benchmark == nil {
+			return echo.NewHTTPError(http.StatusNotFound, "benchmark not found")
+		}
+		benchmarkControls, err := h.getControlsUnderBenchmark(ctx, benchmarkID, make(map[string]BenchmarkControlsCache))
+		if err != nil {
+			h.logger.Error("failed to get benchmark controls", zap.Error(err))
+			return err
+		}
+		if invalid := controlWeightsOutsideBenchmark(req.ControlWeights, benchmarkControls); len(invalid) > 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("controls %s in controlWeights are not in the benchmark", strings.Join(invalid, ", ")))
+		}
+
+		var upserts []db.BenchmarkControlWeight
+		var deletes []string
+		for controlID, weight := range req.ControlWeights {
+			if weight == nil {
+				deletes = append(deletes, controlID)
+				continue
+			}
+			if *weight < 0 {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("negative weight for control %s", controlID))
+			}
+			upserts = append(upserts, db.BenchmarkControlWeight{
+				BenchmarkID: benchmarkID,
+				ControlID:   controlID,
+				Weight:      *weight,
+			})
+		}
+		if err := h.db.UpsertBenchmarkControlWeights(ctx, upserts); err != nil {
+			h.logger.Error("failed to update benchmark control weights", zap.Error(err))
+			return err
+		}
+		if err := h.db.DeleteBenchmarkControlWeights(ctx, benchmarkID, deletes); err != nil {
+			h.logger.Error("failed to delete benchmark control weights", zap.Error(err))
+			return err
+		}
+	}
+
 	return echoCtx.NoContent(http.StatusOK)
 }
 
@@ -2016,218 +2721,2030 @@ func (h *HttpHandler) GetFindingEventFilterValues(echoCtx echo.Context) error {
 		connectionMetadataMap[item.ID.String()] = &item
 	}
 
-	benchmarkMetadata, err := h.db.ListBenchmarksBare(ctx)
+	benchmarkMetadata, err := h.db.ListBenchmarksBare(ctx)
+	if err != nil {
+		h.logger.Error("failed to get benchmarks", zap.Error(err))
+		return err
+	}
+	benchmarkMetadataMap := make(map[string]*db.Benchmark)
+	for _, item := range benchmarkMetadata {
+		item := item
+		benchmarkMetadataMap[item.ID] = &item
+	}
+
+	controlMetadata, err := h.db.ListControlsBare(ctx)
+	if err != nil {
+		h.logger.Error("failed to get controls", zap.Error(err))
+		return err
+	}
+	controlMetadataMap := make(map[string]*db.Control)
+	for _, item := range controlMetadata {
+		item := item
+		controlMetadataMap[item.ID] = &item
+	}
+
+	possibleFilters, err := es.FindingEventsFiltersQuery(ctx, h.logger, h.client,
+		req.FindingID, req.KaytuResourceID, req.Connector, req.ConnectionID, req.NotConnectionID,
+		req.ResourceType,
+		req.BenchmarkID, req.ControlID,
+		req.Severity,
+		evaluatedAtFrom, evaluatedAtTo,
+		req.StateActive, esConformanceStatuses)
+	if err != nil {
+		h.logger.Error("failed to get possible filters", zap.Error(err))
+		return err
+	}
+	response := api.FindingFiltersWithMetadata{}
+	for _, item := range possibleFilters.Aggregations.BenchmarkIDFilter.Buckets {
+		if benchmark, ok := benchmarkMetadataMap[item.Key]; ok {
+			response.BenchmarkID = append(response.BenchmarkID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: benchmark.Title,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		} else {
+			response.BenchmarkID = append(response.BenchmarkID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: item.Key,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		}
+	}
+	for _, item := range possibleFilters.Aggregations.ControlIDFilter.Buckets {
+		if control, ok := controlMetadataMap[item.Key]; ok {
+			response.ControlID = append(response.ControlID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: control.Title,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		} else {
+			response.ControlID = append(response.ControlID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: item.Key,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		}
+	}
+	if len(possibleFilters.Aggregations.ConnectorFilter.Buckets) > 0 {
+		for _, bucket := range possibleFilters.Aggregations.ConnectorFilter.Buckets {
+			connector, _ := source.ParseType(bucket.Key)
+			response.Connector = append(response.Connector, api.FilterWithMetadata{
+				Key:         connector.String(),
+				DisplayName: connector.String(),
+				Count:       utils.GetPointer(bucket.DocCount),
+			})
+		}
+	}
+	for _, item := range possibleFilters.Aggregations.ResourceTypeFilter.Buckets {
+		if rtMetadata, ok := resourceTypeMetadataMap[strings.ToLower(item.Key)]; ok {
+			response.ResourceTypeID = append(response.ResourceTypeID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: rtMetadata.ResourceLabel,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		} else if item.Key == "" {
+			response.ResourceTypeID = append(response.ResourceTypeID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: "Unknown",
+			})
+		} else {
+			response.ResourceTypeID = append(response.ResourceTypeID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: item.Key,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		}
+	}
+
+	for _, item := range possibleFilters.Aggregations.ConnectionIDFilter.Buckets {
+		if connection, ok := connectionMetadataMap[item.Key]; ok {
+			response.ConnectionID = append(response.ConnectionID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: connection.ConnectionName,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		} else {
+			response.ConnectionID = append(response.ConnectionID, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: item.Key,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		}
+	}
+
+	for _, item := range possibleFilters.Aggregations.ResourceCollectionFilter.Buckets {
+		if resourceCollection, ok := resourceCollectionMetadataMap[item.Key]; ok {
+			response.ResourceCollection = append(response.ResourceCollection, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: resourceCollection.Name,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		} else {
+			response.ResourceCollection = append(response.ResourceCollection, api.FilterWithMetadata{
+				Key:         item.Key,
+				DisplayName: item.Key,
+				Count:       utils.GetPointer(item.DocCount),
+			})
+		}
+	}
+
+	for _, item := range possibleFilters.Aggregations.SeverityFilter.Buckets {
+		response.Severity = append(response.Severity, api.FilterWithMetadata{
+			Key:         item.Key,
+			DisplayName: item.Key,
+			Count:       utils.GetPointer(item.DocCount),
+		})
+	}
+
+	for _, item := range possibleFilters.Aggregations.StateActiveFilter.Buckets {
+		response.StateActive = append(response.StateActive, api.FilterWithMetadata{
+			Key:         item.KeyAsString,
+			DisplayName: item.KeyAsString,
+			Count:       utils.GetPointer(item.DocCount),
+		})
+	}
+
+	apiConformanceStatuses := make(map[api.ConformanceStatus]int)
+	for _, item := range possibleFilters.Aggregations.ConformanceStatusFilter.Buckets {
+		if kaytuTypes.ParseConformanceStatus(item.Key).IsPassed() {
+			apiConformanceStatuses[api.ConformanceStatusPassed] += item.DocCount
+		} else {
+			apiConformanceStatuses[api.ConformanceStatusFailed] += item.DocCount
+		}
+	}
+	for status, count := range apiConformanceStatuses {
+		count := count
+		response.ConformanceStatus = append(response.ConformanceStatus, api.FilterWithMetadata{
+			Key:         string(status),
+			DisplayName: string(status),
+			Count:       &count,
+		})
+	}
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// GetSingleFindingEvent
+//
+//	@Summary		Get single finding event
+//	@Description	Retrieving single finding event
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			findingID	path		string	true	"FindingID"
+//	@Success		200			{object}	api.FindingEvent
+//	@Router			/compliance/api/v1/finding_events/single/{id} [get]
+func (h *HttpHandler) GetSingleFindingEvent(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	findingEventID := echoCtx.Param("id")
+
+	findingEvent, err := es.FetchFindingEventByID(ctx, h.logger, h.client, findingEventID)
+	if err != nil {
+		h.logger.Error("failed to fetch findingEvent by id", zap.Error(err))
+		return err
+	}
+	if findingEvent == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "findingEvent not found")
+	}
+
+	apiFindingEvent := api.GetAPIFindingEventFromESFindingEvent(*findingEvent)
+
+	connection, err := h.onboardClient.GetSource(httpclient.FromEchoContext(echoCtx), findingEvent.ConnectionID)
+	if err != nil {
+		h.logger.Error("failed to get connection", zap.Error(err), zap.String("connection_id", findingEvent.ConnectionID))
+		return err
+	}
+	apiFindingEvent.ProviderConnectionID = connection.ConnectionID
+	apiFindingEvent.ProviderConnectionName = connection.ConnectionName
+
+	if len(findingEvent.ResourceType) > 0 {
+		resourceTypeMetadata, err := h.inventoryClient.ListResourceTypesMetadata(httpclient.FromEchoContext(echoCtx),
+			nil, nil,
+			[]string{findingEvent.ResourceType}, false, nil, 10000, 1)
+		if err != nil {
+			h.logger.Error("failed to get resource type metadata", zap.Error(err))
+			return err
+		}
+		if len(resourceTypeMetadata.ResourceTypes) > 0 {
+			apiFindingEvent.ResourceTypeName = resourceTypeMetadata.ResourceTypes[0].ResourceLabel
+		}
+	}
+
+	return echoCtx.JSON(http.StatusOK, apiFindingEvent)
+}
+
+// ListFindingExceptions godoc
+//
+//	@Summary		List finding exceptions
+//	@Description	Retrieving list of finding exceptions (accepted risks)
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			benchmarkId		query		[]string	false	"Benchmark ID"
+//	@Param			controlId		query		[]string	false	"Control ID"
+//	@Param			connectionId	query		[]string	false	"Connection ID"
+//	@Param			activeOnly		query		bool		false	"Only return exceptions that are not expired"
+//	@Success		200				{object}	[]api.FindingException
+//	@Router			/compliance/api/v1/finding_exceptions [get]
+func (h *HttpHandler) ListFindingExceptions(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	benchmarkIDs := httpserver2.QueryArrayParam(echoCtx, "benchmarkId")
+	controlIDs := httpserver2.QueryArrayParam(echoCtx, "controlId")
+	connectionIDs := httpserver2.QueryArrayParam(echoCtx, "connectionId")
+	activeOnly := echoCtx.QueryParam("activeOnly") == "true"
+
+	exceptions, err := h.db.ListFindingExceptions(ctx, benchmarkIDs, controlIDs, connectionIDs, activeOnly)
+	if err != nil {
+		h.logger.Error("failed to list finding exceptions", zap.Error(err))
+		return err
+	}
+
+	result := make([]api.FindingException, 0, len(exceptions))
+	for _, exception := range exceptions {
+		result = append(result, exception.ToApi())
+	}
+
+	return echoCtx.JSON(http.StatusOK, result)
+}
+
+// GetFindingException godoc
+//
+//	@Summary		Get finding exception
+//	@Description	Retrieving a single finding exception
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Exception ID"
+//	@Success		200	{object}	api.FindingException
+//	@Router			/compliance/api/v1/finding_exceptions/{id} [get]
+func (h *HttpHandler) GetFindingException(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid exception id")
+	}
+
+	exception, err := h.db.GetFindingException(ctx, uint(id))
+	if err != nil {
+		h.logger.Error("failed to get finding exception", zap.Error(err))
+		return err
+	}
+	if exception == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "finding exception not found")
+	}
+
+	return echoCtx.JSON(http.StatusOK, exception.ToApi())
+}
+
+// CreateFindingException godoc
+//
+//	@Summary		Create finding exception
+//	@Description	Accepting the risk of findings matching the given scope, matching findings are reported as excepted instead of failed until the exception expires
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateFindingExceptionRequest	true	"Request Body"
+//	@Success		200		{object}	api.FindingException
+//	@Router			/compliance/api/v1/finding_exceptions [post]
+func (h *HttpHandler) CreateFindingException(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.CreateFindingExceptionRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if !req.HasScope() {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one of benchmarkID, controlID, connectionID, kaytuResourceID or tagKey is required")
+	}
+	if req.TagValue != nil && *req.TagValue != "" && (req.TagKey == nil || *req.TagKey == "") {
+		return echo.NewHTTPError(http.StatusBadRequest, "tagValue requires tagKey")
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expiresAt is in the past")
+	}
+	if req.ConnectionID != nil && *req.ConnectionID != "" {
+		if err := httpserver2.CheckAccessToConnectionID(echoCtx, *req.ConnectionID); err != nil {
+			return err
+		}
+	}
+
+	exception := db.FindingException{
+		BenchmarkID:     req.BenchmarkID,
+		ControlID:       req.ControlID,
+		ConnectionID:    req.ConnectionID,
+		KaytuResourceID: req.KaytuResourceID,
+		TagKey:          req.TagKey,
+		TagValue:        req.TagValue,
+		Justification:   req.Justification,
+		Approver:        req.Approver,
+		CreatedBy:       httpserver2.GetUserID(echoCtx),
+		ExpiresAt:       req.ExpiresAt,
+	}
+	if err := h.db.CreateFindingException(ctx, &exception); err != nil {
+		h.logger.Error("failed to create finding exception", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, exception.ToApi())
+}
+
+// UpdateFindingException godoc
+//
+//	@Summary		Update finding exception
+//	@Description	Updating justification, approver or expiry of a finding exception, the scope cannot be changed
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string								true	"Exception ID"
+//	@Param			request	body		api.UpdateFindingExceptionRequest	true	"Request Body"
+//	@Success		200		{object}	api.FindingException
+//	@Router			/compliance/api/v1/finding_exceptions/{id} [put]
+func (h *HttpHandler) UpdateFindingException(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid exception id")
+	}
+
+	var req api.UpdateFindingExceptionRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	exception, err := h.db.GetFindingException(ctx, uint(id))
+	if err != nil {
+		h.logger.Error("failed to get finding exception", zap.Error(err))
+		return err
+	}
+	if exception == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "finding exception not found")
+	}
+
+	if req.Justification != nil {
+		if *req.Justification == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "justification cannot be empty")
+		}
+		exception.Justification = *req.Justification
+	}
+	if req.Approver != nil {
+		if *req.Approver == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "approver cannot be empty")
+		}
+		exception.Approver = *req.Approver
+	}
+	if req.ExpiresAt != nil {
+		exception.ExpiresAt = req.ExpiresAt
+	}
+	exception.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateFindingException(ctx, exception); err != nil {
+		h.logger.Error("failed to update finding exception", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, exception.ToApi())
+}
+
+// DeleteFindingException godoc
+//
+//	@Summary		Delete finding exception
+//	@Description	Deleting a finding exception, matching findings are reported as failed again on the next summary
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Exception ID"
+//	@Success		200
+//	@Router			/compliance/api/v1/finding_exceptions/{id} [delete]
+func (h *HttpHandler) DeleteFindingException(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid exception id")
+	}
+
+	exception, err := h.db.GetFindingException(ctx, uint(id))
+	if err != nil {
+		h.logger.Error("failed to get finding exception", zap.Error(err))
+		return err
+	}
+	if exception == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "finding exception not found")
+	}
+
+	if err := h.db.DeleteFindingException(ctx, uint(id)); err != nil {
+		h.logger.Error("failed to delete finding exception", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
+// ListOwnershipRules godoc
+//
+//	@Summary		List ownership rules
+//	@Description	Retrieving list of ownership rules in evaluation order
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			owner	query		[]string	false	"Owner"
+//	@Success		200		{object}	[]api.OwnershipRule
+//	@Router			/compliance/api/v1/ownership_rules [get]
+func (h *HttpHandler) ListOwnershipRules(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	owners := httpserver2.QueryArrayParam(echoCtx, "owner")
+
+	rules, err := h.db.ListOwnershipRules(ctx, owners)
+	if err != nil {
+		h.logger.Error("failed to list ownership rules", zap.Error(err))
+		return err
+	}
+
+	result := make([]api.OwnershipRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, rule.ToApi())
+	}
+
+	return echoCtx.JSON(http.StatusOK, result)
+}
+
+// GetOwnershipRule godoc
+//
+//	@Summary		Get ownership rule
+//	@Description	Retrieving a single ownership rule
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Rule ID"
+//	@Success		200	{object}	api.OwnershipRule
+//	@Router			/compliance/api/v1/ownership_rules/{id} [get]
+func (h *HttpHandler) GetOwnershipRule(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid rule id")
+	}
+
+	rule, err := h.db.GetOwnershipRule(ctx, uint(id))
+	if err != nil {
+		h.logger.Error("failed to get ownership rule", zap.Error(err))
+		return err
+	}
+	if rule == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "ownership rule not found")
+	}
+
+	return echoCtx.JSON(http.StatusOK, rule.ToApi())
+}
+
+// CreateOwnershipRule godoc
+//
+//	@Summary		Create ownership rule
+//	@Description	Assigning an owner to findings of resources matching the given tag, connection or resource type, owners are resolved on the next summary
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateOwnershipRuleRequest	true	"Request Body"
+//	@Success		200		{object}	api.OwnershipRule
+//	@Router			/compliance/api/v1/ownership_rules [post]
+func (h *HttpHandler) CreateOwnershipRule(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.CreateOwnershipRuleRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if !req.HasScope() {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one of tagKey, connectionID or resourceType is required")
+	}
+	if req.TagValue != nil && *req.TagValue != "" && (req.TagKey == nil || *req.TagKey == "") {
+		return echo.NewHTTPError(http.StatusBadRequest, "tagValue requires tagKey")
+	}
+	if req.ConnectionID != nil && *req.ConnectionID != "" {
+		if err := httpserver2.CheckAccessToConnectionID(echoCtx, *req.ConnectionID); err != nil {
+			return err
+		}
+	}
+
+	rule := db.OwnershipRule{
+		Owner:        req.Owner,
+		Priority:     req.Priority,
+		TagKey:       req.TagKey,
+		TagValue:     req.TagValue,
+		ConnectionID: req.ConnectionID,
+		ResourceType: req.ResourceType,
+		CreatedBy:    httpserver2.GetUserID(echoCtx),
+	}
+	if err := h.db.CreateOwnershipRule(ctx, &rule); err != nil {
+		h.logger.Error("failed to create ownership rule", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, rule.ToApi())
+}
+
+// UpdateOwnershipRule godoc
+//
+//	@Summary		Update ownership rule
+//	@Description	Updating owner or priority of an ownership rule, the match scope cannot be changed
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Rule ID"
+//	@Param			request	body		api.UpdateOwnershipRuleRequest	true	"Request Body"
+//	@Success		200		{object}	api.OwnershipRule
+//	@Router			/compliance/api/v1/ownership_rules/{id} [put]
+func (h *HttpHandler) UpdateOwnershipRule(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid rule id")
+	}
+
+	var req api.UpdateOwnershipRuleRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	rule, err := h.db.GetOwnershipRule(ctx, uint(id))
+	if err != nil {
+		h.logger.Error("failed to get ownership rule", zap.Error(err))
+		return err
+	}
+	if rule == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "ownership rule not found")
+	}
+
+	if req.Owner != nil {
+		if *req.Owner == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "owner cannot be empty")
+		}
+		rule.Owner = *req.Owner
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	rule.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateOwnershipRule(ctx, rule); err != nil {
+		h.logger.Error("failed to update ownership rule", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, rule.ToApi())
+}
+
+// DeleteOwnershipRule godoc
+//
+//	@Summary		Delete ownership rule
+//	@Description	Deleting an ownership rule, matching findings are reassigned on the next summary
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Rule ID"
+//	@Success		200
+//	@Router			/compliance/api/v1/ownership_rules/{id} [delete]
+func (h *HttpHandler) DeleteOwnershipRule(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid rule id")
+	}
+
+	rule, err := h.db.GetOwnershipRule(ctx, uint(id))
+	if err != nil {
+		h.logger.Error("failed to get ownership rule", zap.Error(err))
+		return err
+	}
+	if rule == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "ownership rule not found")
+	}
+
+	if err := h.db.DeleteOwnershipRule(ctx, rule.ID); err != nil {
+		h.logger.Error("failed to delete ownership rule", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
+// GetFindingSLAs godoc
+//
+//	@Summary		Get finding SLAs
+//	@Description	Retrieving the remediation window of each severity, failing findings past it are reported as overdue
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Produce		json
+//	@Success		200	{object}	api.GetFindingSLAsResponse
+//	@Router			/compliance/api/v1/finding_sla [get]
+func (h *HttpHandler) GetFindingSLAs(echoCtx echo.Context) error {
+	policy, err := h.getFindingSLAPolicy(echoCtx.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	response := api.GetFindingSLAsResponse{
+		SLAs: make([]api.FindingSLA, 0, len(api.FindingSLASeverities)),
+	}
+	for _, severity := range api.FindingSLASeverities {
+		response.SLAs = append(response.SLAs, api.FindingSLA{
+			Severity: severity,
+			Days:     policy[severity],
+		})
+	}
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// UpdateFindingSLAs godoc
+//
+//	@Summary		Update finding SLAs
+//	@Description	Setting the remediation window of the given severities, a zero window disables the SLA of the severity
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.UpdateFindingSLAsRequest	true	"Request Body"
+//	@Success		200		{object}	api.GetFindingSLAsResponse
+//	@Router			/compliance/api/v1/finding_sla [put]
+func (h *HttpHandler) UpdateFindingSLAs(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.UpdateFindingSLAsRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	slas := make([]db.FindingSLA, 0, len(req.SLAs))
+	for _, sla := range req.SLAs {
+		severity := kaytuTypes.ParseFindingSeverity(string(sla.Severity))
+		if severity == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid severity %s", sla.Severity))
+		}
+		slas = append(slas, db.FindingSLA{
+			Severity: severity,
+			Days:     sla.Days,
+		})
+	}
+	if err := h.db.UpsertFindingSLAs(ctx, slas); err != nil {
+		h.logger.Error("failed to update finding slas", zap.Error(err))
+		return err
+	}
+
+	return h.GetFindingSLAs(echoCtx)
+}
+
+// GetSecurityScoreWeights godoc
+//
+//	@Summary		Get security score weights
+//	@Description	Retrieving how much findings of each severity count towards the weighted security score, with the control overrides of the benchmark if given
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Produce		json
+//	@Param			benchmarkId	query		string	false	"Benchmark ID to return the control overrides of"
+//	@Success		200			{object}	api.GetSecurityScoreWeightsResponse
+//	@Router			/compliance/api/v1/security_score/weights [get]
+func (h *HttpHandler) GetSecurityScoreWeights(echoCtx echo.Context) error {
+	response, err := h.getSecurityScoreWeights(echoCtx.Request().Context(), echoCtx.QueryParam("benchmarkId"))
+	if err != nil {
+		return err
+	}
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// UpdateSecurityScoreWeights godoc
+//
+//	@Summary		Update security score weights
+//	@Description	Setting the weight of the given severities, applied to the weighted security score from the next summary on
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.UpdateSecurityScoreWeightsRequest	true	"Request Body"
+//	@Success		200		{object}	api.GetSecurityScoreWeightsResponse
+//	@Router			/compliance/api/v1/security_score/weights [put]
+func (h *HttpHandler) UpdateSecurityScoreWeights(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.UpdateSecurityScoreWeightsRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	weights := make([]db.SeverityScoreWeight, 0, len(req.Severities))
+	for _, w := range req.Severities {
+		severity := kaytuTypes.ParseFindingSeverity(string(w.Severity))
+		if severity == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid severity %s", w.Severity))
+		}
+		weights = append(weights, db.SeverityScoreWeight{
+			Severity: severity,
+			Weight:   w.Weight,
+		})
+	}
+	if err := h.db.UpsertSeverityScoreWeights(ctx, weights); err != nil {
+		h.logger.Error("failed to update severity score weights", zap.Error(err))
+		return err
+	}
+
+	response, err := h.getSecurityScoreWeights(ctx, "")
+	if err != nil {
+		return err
+	}
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// ListNotificationSubscriptions godoc
+//
+//	@Summary		List notification subscriptions
+//	@Description	Retrieving list of webhook subscriptions notified on finding state transitions
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	[]api.NotificationSubscription
+//	@Router			/compliance/api/v1/notifications/subscriptions [get]
+func (h *HttpHandler) ListNotificationSubscriptions(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	subscriptions, err := h.db.ListNotificationSubscriptions(ctx, false)
+	if err != nil {
+		h.logger.Error("failed to list notification subscriptions", zap.Error(err))
+		return err
+	}
+
+	result := make([]api.NotificationSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, subscription.ToApi())
+	}
+
+	return echoCtx.JSON(http.StatusOK, result)
+}
+
+// GetNotificationSubscription godoc
+//
+//	@Summary		Get notification subscription
+//	@Description	Retrieving a single webhook subscription
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Subscription ID"
+//	@Success		200	{object}	api.NotificationSubscription
+//	@Router			/compliance/api/v1/notifications/subscriptions/{id} [get]
+func (h *HttpHandler) GetNotificationSubscription(echoCtx echo.Context) error {
+	subscription, err := h.getNotificationSubscriptionFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, subscription.ToApi())
+}
+
+// CreateNotificationSubscription godoc
+//
+//	@Summary		Create notification subscription
+//	@Description	Subscribing a webhook to finding state transitions, deliveries are signed with the returned secret
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateNotificationSubscriptionRequest	true	"Request Body"
+//	@Success		200		{object}	api.NotificationSubscription
+//	@Router			/compliance/api/v1/notifications/subscriptions [post]
+func (h *HttpHandler) CreateNotificationSubscription(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.CreateNotificationSubscriptionRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := validateNotificationFilters(req.Severities, req.Transitions); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := notification.ValidateWebhookURL(ctx, req.URL); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			h.logger.Error("failed to generate notification secret", zap.Error(err))
+			return err
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	subscription := db.NotificationSubscription{
+		Name:          req.Name,
+		URL:           req.URL,
+		Secret:        secret,
+		BenchmarkIDs:  req.BenchmarkIDs,
+		ControlIDs:    req.ControlIDs,
+		ConnectionIDs: req.ConnectionIDs,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+		CreatedBy:     httpserver2.GetUserID(echoCtx),
+	}
+	for _, severity := range req.Severities {
+		subscription.Severities = append(subscription.Severities, string(kaytuTypes.ParseFindingSeverity(string(severity))))
+	}
+	for _, transition := range req.Transitions {
+		subscription.Transitions = append(subscription.Transitions, string(transition))
+	}
+	if err := h.db.CreateNotificationSubscription(ctx, &subscription); err != nil {
+		h.logger.Error("failed to create notification subscription", zap.Error(err))
+		return err
+	}
+
+	result := subscription.ToApi()
+	result.Secret = subscription.Secret
+	return echoCtx.JSON(http.StatusOK, result)
+}
+
+// UpdateNotificationSubscription godoc
+//
+//	@Summary		Update notification subscription
+//	@Description	Updating a webhook subscription, given filter lists replace the existing ones
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string										true	"Subscription ID"
+//	@Param			request	body		api.UpdateNotificationSubscriptionRequest	true	"Request Body"
+//	@Success		200		{object}	api.NotificationSubscription
+//	@Router			/compliance/api/v1/notifications/subscriptions/{id} [put]
+func (h *HttpHandler) UpdateNotificationSubscription(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.UpdateNotificationSubscriptionRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := validateNotificationFilters(req.Severities, req.Transitions); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	subscription, err := h.getNotificationSubscriptionFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "name cannot be empty")
+		}
+		subscription.Name = *req.Name
+	}
+	if req.URL != nil {
+		if err := notification.ValidateWebhookURL(ctx, *req.URL); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		subscription.URL = *req.URL
+	}
+	if req.BenchmarkIDs != nil {
+		subscription.BenchmarkIDs = req.BenchmarkIDs
+	}
+	if req.ControlIDs != nil {
+		subscription.ControlIDs = req.ControlIDs
+	}
+	if req.ConnectionIDs != nil {
+		subscription.ConnectionIDs = req.ConnectionIDs
+	}
+	if req.Severities != nil {
+		subscription.Severities = make([]string, 0, len(req.Severities))
+		for _, severity := range req.Severities {
+			subscription.Severities = append(subscription.Severities, string(kaytuTypes.ParseFindingSeverity(string(severity))))
+		}
+	}
+	if req.Transitions != nil {
+		subscription.Transitions = make([]string, 0, len(req.Transitions))
+		for _, transition := range req.Transitions {
+			subscription.Transitions = append(subscription.Transitions, string(transition))
+		}
+	}
+	if req.Enabled != nil {
+		subscription.Enabled = *req.Enabled
+	}
+	subscription.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateNotificationSubscription(ctx, subscription); err != nil {
+		h.logger.Error("failed to update notification subscription", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, subscription.ToApi())
+}
+
+// DeleteNotificationSubscription godoc
+//
+//	@Summary		Delete notification subscription
+//	@Description	Deleting a webhook subscription along with its delivery log
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path	string	true	"Subscription ID"
+//	@Success		200
+//	@Router			/compliance/api/v1/notifications/subscriptions/{id} [delete]
+func (h *HttpHandler) DeleteNotificationSubscription(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid subscription id")
+	}
+
+	if err := h.db.DeleteNotificationSubscription(ctx, uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "notification subscription not found")
+		}
+		h.logger.Error("failed to delete notification subscription", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
+// ListNotificationDeliveries godoc
+//
+//	@Summary		List notification deliveries
+//	@Description	Retrieving the delivery log of a webhook subscription, newest first
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string	true	"Subscription ID"
+//	@Param			limit	query		int		false	"Maximum number of deliveries to return, defaults to 100"
+//	@Success		200		{object}	[]api.NotificationDelivery
+//	@Router			/compliance/api/v1/notifications/subscriptions/{id}/deliveries [get]
+func (h *HttpHandler) ListNotificationDeliveries(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	subscription, err := h.getNotificationSubscriptionFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	limit := 100
+	if limitStr := echoCtx.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+
+	deliveries, err := h.db.ListNotificationDeliveries(ctx, subscription.ID, limit)
+	if err != nil {
+		h.logger.Error("failed to list notification deliveries", zap.Error(err))
+		return err
+	}
+
+	result := make([]api.NotificationDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, delivery.ToApi())
+	}
+
+	return echoCtx.JSON(http.StatusOK, result)
+}
+
+// TestNotificationSubscription godoc
+//
+//	@Summary		Test notification subscription
+//	@Description	Sending a sample finding event to the webhook and returning the delivery result
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Subscription ID"
+//	@Success		200	{object}	api.NotificationDelivery
+//	@Router			/compliance/api/v1/notifications/subscriptions/{id}/test [post]
+func (h *HttpHandler) TestNotificationSubscription(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	subscription, err := h.getNotificationSubscriptionFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := h.notificationDispatcher.SendTest(ctx, *subscription)
+	if err != nil {
+		h.logger.Error("failed to send test notification", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, delivery.ToApi())
+}
+
+// DispatchComplianceJobNotifications godoc
+//
+//	@Summary		Dispatch compliance job notifications
+//	@Description	Delivering finding state transitions of a finished compliance job to subscribed webhooks in the background
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Param			job_id	path	string											true	"Compliance job ID"
+//	@Param			request	body	api.DispatchComplianceJobNotificationsRequest	false	"Request Body"
+//	@Success		202
+//	@Router			/compliance/api/v1/notifications/compliance_jobs/{job_id}/dispatch [post]
+func (h *HttpHandler) DispatchComplianceJobNotifications(echoCtx echo.Context) error {
+	jobID, err := strconv.ParseUint(echoCtx.Param("job_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid compliance job id")
+	}
+	var req api.DispatchComplianceJobNotificationsRequest
+	if err := echoCtx.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	// retries with backoff can take minutes, the scheduler shouldn't wait for them
+	utils.EnsureRunGoroutine(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notification.DispatchTimeout)
+		defer cancel()
+		if err := h.notificationDispatcher.DispatchComplianceJob(ctx, uint(jobID), req.RunnerIDs); err != nil {
+			h.logger.Error("failed to dispatch compliance job notifications", zap.Uint64("job_id", jobID), zap.Error(err))
+		}
+	})
+
+	return echoCtx.NoContent(http.StatusAccepted)
+}
+
+func (h *HttpHandler) getNotificationSubscriptionFromParam(echoCtx echo.Context) (*db.NotificationSubscription, error) {
+	id, err := strconv.ParseUint(echoCtx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid subscription id")
+	}
+
+	subscription, err := h.db.GetNotificationSubscription(echoCtx.Request().Context(), uint(id))
+	if err != nil {
+		h.logger.Error("failed to get notification subscription", zap.Error(err))
+		return nil, err
+	}
+	if subscription == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "notification subscription not found")
+	}
+	return subscription, nil
+}
+
+func validateNotificationFilters(severities []kaytuTypes.FindingSeverity, transitions []api.NotificationTransition) error {
+	for _, severity := range severities {
+		if kaytuTypes.ParseFindingSeverity(string(severity)) == "" {
+			return fmt.Errorf("invalid severity %s", severity)
+		}
+	}
+	for _, transition := range transitions {
+		if transition != api.NotificationTransitionFailing && transition != api.NotificationTransitionPassing {
+			return fmt.Errorf("invalid transition %s, valid transitions are failing and passing", transition)
+		}
+	}
+	return nil
+}
+
+// CreateCustomControl godoc
+//
+//	@Summary		Create custom control
+//	@Description	Creating a control through the API with a new query or an existing custom query, custom controls survive content migrations
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateCustomControlRequest	true	"Request Body"
+//	@Success		200		{object}	api.Control
+//	@Router			/compliance/api/v1/controls/custom [post]
+func (h *HttpHandler) CreateCustomControl(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.CreateCustomControlRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	severity := kaytuTypes.FindingSeverityLow
+	if req.Severity != "" {
+		severity = kaytuTypes.ParseFindingSeverity(string(req.Severity))
+		if severity == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid severity %s", req.Severity))
+		}
+	}
+
+	if (req.Query == nil) == (req.QueryID == nil) {
+		return echo.NewHTTPError(http.StatusBadRequest, "either query or queryID is required")
+	}
+
+	existingControl, err := h.db.GetControl(ctx, req.ID)
+	if err != nil {
+		h.logger.Error("failed to get control", zap.Error(err), zap.String("controlId", req.ID))
+		return err
+	}
+	if existingControl != nil {
+		return echo.NewHTTPError(http.StatusConflict, "control with the same id already exists")
+	}
+
+	connectors := make([]string, 0, len(req.Connector))
+	for _, connector := range req.Connector {
+		connectors = append(connectors, connector.String())
+	}
+	var query *db.Query
+	queryID := req.ID
+	if req.Query != nil {
+		existingQuery, err := h.db.GetQuery(ctx, req.ID)
+		if err != nil {
+			h.logger.Error("failed to get query", zap.Error(err), zap.String("queryId", req.ID))
+			return err
+		}
+		if existingQuery != nil {
+			return echo.NewHTTPError(http.StatusConflict, "query with the same id already exists, use queryID to run it")
+		}
+		if err := h.checkCustomQuery(echoCtx, *req.Query); err != nil {
+			return err
+		}
+		q := newCustomQuery(req.ID, connectors, *req.Query)
+		query = &q
+	} else {
+		q, err := h.getReferencedCustomQuery(ctx, *req.QueryID)
+		if err != nil {
+			return err
+		}
+		queryID = q.ID
+	}
+
+	control := db.Control{
+		ID:          req.ID,
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        newCustomControlTags(req.ID, req.Tags),
+		Connector:   connectors,
+		DocumentURI: req.DocumentURI,
+		Enabled:     true,
+		QueryID:     &queryID,
+		Query:       query,
+		Severity:    severity,
+		Source:      api.ContentSourceCustom,
+	}
+	if err := h.db.CreateCustomControl(ctx, &control); err != nil {
+		h.logger.Error("failed to create custom control", zap.Error(err), zap.String("controlId", req.ID))
+		return err
+	}
+
+	created, err := h.db.GetControl(ctx, control.ID)
+	if err != nil {
+		h.logger.Error("failed to get control", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, created.ToApi())
+}
+
+// UpdateCustomControl godoc
+//
+//	@Summary		Update custom control
+//	@Description	Updating a custom control, controls coming from the content repository cannot be updated
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			control_id	path		string							true	"Control ID"
+//	@Param			request		body		api.UpdateCustomControlRequest	true	"Request Body"
+//	@Success		200			{object}	api.Control
+//	@Router			/compliance/api/v1/controls/custom/{control_id} [put]
+func (h *HttpHandler) UpdateCustomControl(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.UpdateCustomControlRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	control, err := h.getCustomControlFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+
+	if req.Title != nil {
+		control.Title = *req.Title
+	}
+	if req.Description != nil {
+		control.Description = *req.Description
+	}
+	if req.Severity != nil {
+		severity := kaytuTypes.ParseFindingSeverity(string(*req.Severity))
+		if severity == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid severity %s", *req.Severity))
+		}
+		control.Severity = severity
+	}
+	if req.DocumentURI != nil {
+		control.DocumentURI = *req.DocumentURI
+	}
+	if req.Enabled != nil {
+		control.Enabled = *req.Enabled
+	}
+	var tags []db.ControlTag
+	if req.Tags != nil {
+		tags = newCustomControlTags(control.ID, req.Tags)
+	}
+	if req.Query != nil && req.QueryID != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "only one of query and queryID can be set")
+	}
+	var query *db.Query
+	if req.Query != nil {
+		if err := h.checkCustomQuery(echoCtx, *req.Query); err != nil {
+			return err
+		}
+		queryID := control.ID
+		if control.QueryID != nil {
+			queryID = *control.QueryID
+		}
+		q := newCustomQuery(queryID, control.Connector, *req.Query)
+		query = &q
+	}
+	if req.QueryID != nil {
+		q, err := h.getReferencedCustomQuery(ctx, *req.QueryID)
+		if err != nil {
+			return err
+		}
+		control.QueryID = &q.ID
+	}
+	control.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateCustomControl(ctx, control, tags, query); err != nil {
+		h.logger.Error("failed to update custom control", zap.Error(err), zap.String("controlId", control.ID))
+		return err
+	}
+	if query != nil || req.QueryID != nil {
+		benchmarkIDs, err := h.db.GetBenchmarkIdsByControlID(ctx, control.ID)
+		if err != nil {
+			h.logger.Error("failed to get control benchmarks", zap.Error(err), zap.String("controlId", control.ID))
+			return err
+		}
+		if err := h.refreshBenchmarksMetadata(ctx, benchmarkIDs); err != nil {
+			return err
+		}
+	}
+
+	control, err = h.db.GetControl(ctx, control.ID)
+	if err != nil {
+		h.logger.Error("failed to get control", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, control.ToApi())
+}
+
+// DeleteCustomControl godoc
+//
+//	@Summary		Delete custom control
+//	@Description	Deleting a custom control, the control is removed from the benchmarks it belongs to. The query created along with the control is deleted too if no other control uses it
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Param			control_id	path	string	true	"Control ID"
+//	@Success		200
+//	@Router			/compliance/api/v1/controls/custom/{control_id} [delete]
+func (h *HttpHandler) DeleteCustomControl(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	control, err := h.getCustomControlFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+	benchmarkIDs, err := h.db.GetBenchmarkIdsByControlID(ctx, control.ID)
+	if err != nil {
+		h.logger.Error("failed to get control benchmarks", zap.Error(err), zap.String("controlId", control.ID))
+		return err
+	}
+
+	if err := h.db.DeleteCustomControl(ctx, control.ID); err != nil {
+		h.logger.Error("failed to delete custom control", zap.Error(err), zap.String("controlId", control.ID))
+		return err
+	}
+	if err := h.refreshBenchmarksMetadata(ctx, benchmarkIDs); err != nil {
+		return err
+	}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
+// ValidateCustomQuery godoc
+//
+//	@Summary		Validate custom query
+//	@Description	Checking a control query against the steampipe schema without returning any rows
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.ValidateCustomQueryRequest	true	"Request Body"
+//	@Success		200		{object}	api.ValidateCustomQueryResponse
+//	@Router			/compliance/api/v1/controls/custom/validate [post]
+func (h *HttpHandler) ValidateCustomQuery(echoCtx echo.Context) error {
+	var req api.ValidateCustomQueryRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	validation, err := h.validateCustomQuery(echoCtx, req.Query)
+	if err != nil {
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, validation)
+}
+
+// DryRunCustomQuery godoc
+//
+//	@Summary		Dry-run custom query
+//	@Description	Executing a control query for a single connection before saving it, nothing is stored and no findings are created
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.DryRunCustomQueryRequest	true	"Request Body"
+//	@Success		200		{object}	api.DryRunCustomQueryResponse
+//	@Router			/compliance/api/v1/controls/custom/dry-run [post]
+func (h *HttpHandler) DryRunCustomQuery(echoCtx echo.Context) error {
+	var req api.DryRunCustomQueryRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	connectionID, err := uuid.Parse(req.ConnectionID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid connection id")
+	}
+	if err := httpserver2.CheckAccessToConnectionID(echoCtx, connectionID.String()); err != nil {
+		return err
+	}
+
+	validation, err := h.validateCustomQuery(echoCtx, req.Query)
+	if err != nil {
+		return err
+	}
+	response := api.DryRunCustomQueryResponse{
+		ValidateCustomQueryResponse: validation,
+		ConformanceStatusCount:      make(map[kaytuTypes.ConformanceStatus]int),
+		Rows:                        [][]any{},
+	}
+	if !validation.Valid {
+		return echoCtx.JSON(http.StatusOK, response)
+	}
+
+	// connection id is a parsed uuid, safe to inline
+	query := fmt.Sprintf("SELECT * FROM (%s) AS dry_run WHERE kaytu_account_id = '%s'",
+		trimCustomQuery(req.Query.QueryToExecute), connectionID.String())
+	engine := inventoryApi.QueryEngine(inventoryApi.QueryEngine_OdysseusSQL)
+	// the query runs on behalf of the compliance service so it is not recorded in the query history of the user,
+	// access to the connection is checked above
+	clientCtx := &httpclient.Context{Ctx: echoCtx.Request().Context(), UserRole: authApi.InternalRole}
+	result, err := h.inventoryClient.RunQuery(clientCtx, inventoryApi.RunQueryRequest{
+		Page:   inventoryApi.Page{No: 1, Size: customQueryDryRunLimit + 1},
+		Query:  &query,
+		Engine: &engine,
+	})
+	if err != nil {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == http.StatusBadRequest {
+			response.Valid = false
+			response.Errors = append(response.Errors, fmt.Sprintf("%v", httpErr.Message))
+			return echoCtx.JSON(http.StatusOK, response)
+		}
+		h.logger.Error("failed to dry-run custom query", zap.Error(err))
+		return err
+	}
+
+	rows := result.Result
+	if len(rows) > customQueryDryRunLimit {
+		rows = rows[:customQueryDryRunLimit]
+		response.Truncated = true
+	}
+	statusIdx := -1
+	for idx, header := range result.Headers {
+		if header == "status" {
+			statusIdx = idx
+		}
+	}
+	for _, row := range rows {
+		if statusIdx >= 0 && statusIdx < len(row) {
+			status := kaytuTypes.ParseConformanceStatus(fmt.Sprintf("%v", row[statusIdx]))
+			response.ConformanceStatusCount[status]++
+		}
+	}
+	response.Columns = result.Headers
+	if missing := missingRequiredColumns(result.Headers); len(missing) > 0 {
+		response.Valid = false
+		for _, column := range missing {
+			response.Errors = append(response.Errors, fmt.Sprintf("missing required column %s", column))
+		}
+	}
+	response.ResultCount = len(rows)
+	response.Rows = rows
+
+	return echoCtx.JSON(http.StatusOK, response)
+}
+
+// CreateCustomBenchmark godoc
+//
+//	@Summary		Create custom benchmark
+//	@Description	Creating a benchmark grouping existing benchmarks and controls, custom benchmarks survive content migrations
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateCustomBenchmarkRequest	true	"Request Body"
+//	@Success		200		{object}	api.Benchmark
+//	@Router			/compliance/api/v1/benchmarks/custom [post]
+func (h *HttpHandler) CreateCustomBenchmark(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.CreateCustomBenchmarkRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	existing, err := h.db.GetBenchmarkBare(ctx, req.ID)
+	if err != nil {
+		h.logger.Error("failed to get benchmark", zap.Error(err), zap.String("benchmarkId", req.ID))
+		return err
+	}
+	if existing != nil {
+		return echo.NewHTTPError(http.StatusConflict, "benchmark with the same id already exists")
+	}
+	if err := h.checkCustomBenchmarkLinks(ctx, req.ID, req.Children, req.Controls); err != nil {
+		return err
+	}
+
+	connectors := make([]string, 0, len(req.Connectors))
+	for _, connector := range req.Connectors {
+		connectors = append(connectors, connector.String())
+	}
+	benchmark := db.Benchmark{
+		ID:                req.ID,
+		Title:             req.Title,
+		DisplayCode:       req.ReferenceCode,
+		Connector:         connectors,
+		Description:       req.Description,
+		DocumentURI:       req.DocumentURI,
+		Enabled:           true,
+		AutoAssign:        req.AutoAssign,
+		TracksDriftEvents: req.TracksDriftEvents,
+		Source:            api.ContentSourceCustom,
+		Metadata:          pgtype.JSONB{Status: pgtype.Null},
+		Tags:              newCustomBenchmarkTags(req.ID, req.Tags),
+	}
+	if err := h.db.CreateCustomBenchmark(ctx, &benchmark, req.Children, req.Controls); err != nil {
+		h.logger.Error("failed to create custom benchmark", zap.Error(err), zap.String("benchmarkId", req.ID))
+		return err
+	}
+	if err := h.refreshBenchmarksMetadata(ctx, []string{benchmark.ID}); err != nil {
+		return err
+	}
+
+	created, err := h.db.GetBenchmark(ctx, benchmark.ID)
+	if err != nil {
+		h.logger.Error("failed to get benchmark", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, created.ToApi())
+}
+
+// UpdateCustomBenchmark godoc
+//
+//	@Summary		Update custom benchmark
+//	@Description	Updating a custom benchmark, benchmarks coming from the content repository cannot be updated
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			benchmark_id	path		string								true	"Benchmark ID"
+//	@Param			request			body		api.UpdateCustomBenchmarkRequest	true	"Request Body"
+//	@Success		200				{object}	api.Benchmark
+//	@Router			/compliance/api/v1/benchmarks/custom/{benchmark_id} [put]
+func (h *HttpHandler) UpdateCustomBenchmark(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.UpdateCustomBenchmarkRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	benchmark, err := h.getCustomBenchmarkFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+	if err := h.checkCustomBenchmarkLinks(ctx, benchmark.ID, req.Children, req.Controls); err != nil {
+		return err
+	}
+
+	if req.Title != nil {
+		benchmark.Title = *req.Title
+	}
+	if req.ReferenceCode != nil {
+		benchmark.DisplayCode = *req.ReferenceCode
+	}
+	if req.Description != nil {
+		benchmark.Description = *req.Description
+	}
+	if req.DocumentURI != nil {
+		benchmark.DocumentURI = *req.DocumentURI
+	}
+	if req.Connectors != nil {
+		connectors := make([]string, 0, len(req.Connectors))
+		for _, connector := range req.Connectors {
+			connectors = append(connectors, connector.String())
+		}
+		benchmark.Connector = connectors
+	}
+	var tags []db.BenchmarkTag
+	if req.Tags != nil {
+		tags = newCustomBenchmarkTags(benchmark.ID, req.Tags)
+	}
+	benchmark.UpdatedAt = time.Now()
+
+	if err := h.db.UpdateCustomBenchmark(ctx, benchmark, tags, req.Children, req.Controls); err != nil {
+		h.logger.Error("failed to update custom benchmark", zap.Error(err), zap.String("benchmarkId", benchmark.ID))
+		return err
+	}
+	if req.Children != nil || req.Controls != nil {
+		if err := h.refreshBenchmarksMetadata(ctx, []string{benchmark.ID}); err != nil {
+			return err
+		}
+	}
+
+	benchmark, err = h.db.GetBenchmark(ctx, benchmark.ID)
+	if err != nil {
+		h.logger.Error("failed to get benchmark", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, benchmark.ToApi())
+}
+
+// DeleteCustomBenchmark godoc
+//
+//	@Summary		Delete custom benchmark
+//	@Description	Deleting a custom benchmark and its assignments, child benchmarks and controls are kept
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Param			benchmark_id	path	string	true	"Benchmark ID"
+//	@Success		200
+//	@Router			/compliance/api/v1/benchmarks/custom/{benchmark_id} [delete]
+func (h *HttpHandler) DeleteCustomBenchmark(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	benchmark, err := h.getCustomBenchmarkFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+	parentID, err := h.db.GetBenchmarkParent(ctx, benchmark.ID)
+	if err != nil {
+		h.logger.Error("failed to get benchmark parent", zap.Error(err), zap.String("benchmarkId", benchmark.ID))
+		return err
+	}
+
+	if err := h.db.DeleteCustomBenchmark(ctx, benchmark.ID); err != nil {
+		h.logger.Error("failed to delete custom benchmark", zap.Error(err), zap.String("benchmarkId", benchmark.ID))
+		return err
+	}
+	if parentID != "" {
+		if err := h.refreshBenchmarksMetadata(ctx, []string{parentID}); err != nil {
+			return err
+		}
+	}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
+// CreateCustomQuery godoc
+//
+//	@Summary		Create custom query
+//	@Description	Creating a query which custom controls can run, the query is validated against the steampipe schema before it is saved
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateCustomQueryRequest	true	"Request Body"
+//	@Success		200		{object}	api.Query
+//	@Router			/compliance/api/v1/queries/custom [post]
+func (h *HttpHandler) CreateCustomQuery(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.CreateCustomQueryRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	existing, err := h.db.GetQuery(ctx, req.ID)
+	if err != nil {
+		h.logger.Error("failed to get query", zap.Error(err), zap.String("queryId", req.ID))
+		return err
+	}
+	if existing != nil {
+		return echo.NewHTTPError(http.StatusConflict, "query with the same id already exists")
+	}
+	if err := h.checkCustomQuery(echoCtx, req.Query); err != nil {
+		return err
+	}
+
+	connectors := make([]string, 0, len(req.Connector))
+	for _, connector := range req.Connector {
+		connectors = append(connectors, connector.String())
+	}
+	query := newCustomQuery(req.ID, connectors, req.Query)
+	if err := h.db.CreateCustomQuery(ctx, &query); err != nil {
+		h.logger.Error("failed to create custom query", zap.Error(err), zap.String("queryId", req.ID))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, query.ToApi())
+}
+
+// UpdateCustomQuery godoc
+//
+//	@Summary		Update custom query
+//	@Description	Replacing a custom query, the controls running it pick up the new query. Queries coming from the content repository cannot be updated
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			query_id	path		string							true	"Query ID"
+//	@Param			request		body		api.UpdateCustomQueryRequest	true	"Request Body"
+//	@Success		200			{object}	api.Query
+//	@Router			/compliance/api/v1/queries/custom/{query_id} [put]
+func (h *HttpHandler) UpdateCustomQuery(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	var req api.UpdateCustomQueryRequest
+	if err := bindValidate(echoCtx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	existing, err := h.getCustomQueryFromParam(echoCtx)
+	if err != nil {
+		return err
+	}
+	if err := h.checkCustomQuery(echoCtx, req.Query); err != nil {
+		return err
+	}
+
+	connectors := []string(existing.Connector)
+	if req.Connector != nil {
+		connectors = make([]string, 0, len(req.Connector))
+		for _, connector := range req.Connector {
+			connectors = append(connectors, connector.String())
+		}
+	}
+	query := newCustomQuery(existing.ID, connectors, req.Query)
+	query.UpdatedAt = time.Now()
+	if err := h.db.UpdateCustomQuery(ctx, &query); err != nil {
+		h.logger.Error("failed to update custom query", zap.Error(err), zap.String("queryId", query.ID))
+		return err
+	}
+
+	controlIDs, err := h.db.ListControlIDsByQueryID(ctx, query.ID)
+	if err != nil {
+		h.logger.Error("failed to list query controls", zap.Error(err), zap.String("queryId", query.ID))
+		return err
+	}
+	for _, controlID := range controlIDs {
+		benchmarkIDs, err := h.db.GetBenchmarkIdsByControlID(ctx, controlID)
+		if err != nil {
+			h.logger.Error("failed to get control benchmarks", zap.Error(err), zap.String("controlId", controlID))
+			return err
+		}
+		if err := h.refreshBenchmarksMetadata(ctx, benchmarkIDs); err != nil {
+			return err
+		}
+	}
+
+	updated, err := h.db.GetQuery(ctx, query.ID)
+	if err != nil {
+		h.logger.Error("failed to get query", zap.Error(err))
+		return err
+	}
+
+	return echoCtx.JSON(http.StatusOK, updated.ToApi())
+}
+
+// DeleteCustomQuery godoc
+//
+//	@Summary		Delete custom query
+//	@Description	Deleting a custom query, queries which are still run by a control cannot be deleted
+//	@Security		BearerToken
+//	@Tags			compliance
+//	@Param			query_id	path	string	true	"Query ID"
+//	@Success		200
+//	@Router			/compliance/api/v1/queries/custom/{query_id} [delete]
+func (h *HttpHandler) DeleteCustomQuery(echoCtx echo.Context) error {
+	ctx := echoCtx.Request().Context()
+
+	query, err := h.getCustomQueryFromParam(echoCtx)
 	if err != nil {
-		h.logger.Error("failed to get benchmarks", zap.Error(err))
 		return err
 	}
-	benchmarkMetadataMap := make(map[string]*db.Benchmark)
-	for _, item := range benchmarkMetadata {
-		item := item
-		benchmarkMetadataMap[item.ID] = &item
-	}
-
-	controlMetadata, err := h.db.ListControlsBare(ctx)
+	controlIDs, err := h.db.ListControlIDsByQueryID(ctx, query.ID)
 	if err != nil {
-		h.logger.Error("failed to get controls", zap.Error(err))
+		h.logger.Error("failed to list query controls", zap.Error(err), zap.String("queryId", query.ID))
 		return err
 	}
-	controlMetadataMap := make(map[string]*db.Control)
-	for _, item := range controlMetadata {
-		item := item
-		controlMetadataMap[item.ID] = &item
+	if len(controlIDs) > 0 {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("query is used by controls %s", strings.Join(controlIDs, ", ")))
 	}
 
-	possibleFilters, err := es.FindingEventsFiltersQuery(ctx, h.logger, h.client,
-		req.FindingID, req.KaytuResourceID, req.Connector, req.ConnectionID, req.NotConnectionID,
-		req.ResourceType,
-		req.BenchmarkID, req.ControlID,
-		req.Severity,
-		evaluatedAtFrom, evaluatedAtTo,
-		req.StateActive, esConformanceStatuses)
-	if err != nil {
-		h.logger.Error("failed to get possible filters", zap.Error(err))
+	if err := h.db.DeleteCustomQuery(ctx, query.ID); err != nil {
+		h.logger.Error("failed to delete custom query", zap.Error(err), zap.String("queryId", query.ID))
 		return err
 	}
-	response := api.FindingFiltersWithMetadata{}
-	for _, item := range possibleFilters.Aggregations.BenchmarkIDFilter.Buckets {
-		if benchmark, ok := benchmarkMetadataMap[item.Key]; ok {
-			response.BenchmarkID = append(response.BenchmarkID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: benchmark.Title,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		} else {
-			response.BenchmarkID = append(response.BenchmarkID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: item.Key,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		}
+
+	return echoCtx.NoContent(http.StatusOK)
+}
+
+const customQueryDryRunLimit = 1000
+
+var customQueryRequiredColumns = []string{"resource", "status", "reason", "kaytu_account_id"}
+
+// validateCustomQuery checks the query against the steampipe schema through the inventory service without running it,
+// the query is also invalid if it misses a column the compliance runner relies on
+func (h *HttpHandler) validateCustomQuery(echoCtx echo.Context, customQuery api.CustomQuery) (api.ValidateCustomQueryResponse, error) {
+	response := api.ValidateCustomQueryResponse{Valid: true, Errors: []string{}, Columns: []string{}}
+	if customQuery.Engine != "" && customQuery.Engine != string(api.QueryEngine_OdysseusSQL) {
+		response.Valid = false
+		response.Errors = append(response.Errors, fmt.Sprintf("unsupported engine %s, custom queries only support %s", customQuery.Engine, api.QueryEngine_OdysseusSQL))
+		return response, nil
 	}
-	for _, item := range possibleFilters.Aggregations.ControlIDFilter.Buckets {
-		if control, ok := controlMetadataMap[item.Key]; ok {
-			response.ControlID = append(response.ControlID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: control.Title,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		} else {
-			response.ControlID = append(response.ControlID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: item.Key,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		}
+	queryToExecute := trimCustomQuery(customQuery.QueryToExecute)
+	if queryToExecute == "" {
+		response.Valid = false
+		response.Errors = append(response.Errors, "query is empty")
+		return response, nil
 	}
-	if len(possibleFilters.Aggregations.ConnectorFilter.Buckets) > 0 {
-		for _, bucket := range possibleFilters.Aggregations.ConnectorFilter.Buckets {
-			connector, _ := source.ParseType(bucket.Key)
-			response.Connector = append(response.Connector, api.FilterWithMetadata{
-				Key:         connector.String(),
-				DisplayName: connector.String(),
-				Count:       utils.GetPointer(bucket.DocCount),
-			})
+
+	engine := inventoryApi.QueryEngine(inventoryApi.QueryEngine_OdysseusSQL)
+	result, err := h.inventoryClient.ValidateQuery(httpclient.FromEchoContext(echoCtx), inventoryApi.ValidateQueryRequest{
+		Query:  queryToExecute,
+		Engine: &engine,
+	})
+	if err != nil {
+		h.logger.Error("failed to validate custom query", zap.Error(err))
+		return response, err
+	}
+
+	for _, issue := range result.Issues {
+		if issue.Severity == inventoryApi.QueryValidationSeverityError {
+			response.Valid = false
+			response.Errors = append(response.Errors, issue.Message)
 		}
 	}
-	for _, item := range possibleFilters.Aggregations.ResourceTypeFilter.Buckets {
-		if rtMetadata, ok := resourceTypeMetadataMap[strings.ToLower(item.Key)]; ok {
-			response.ResourceTypeID = append(response.ResourceTypeID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: rtMetadata.ResourceLabel,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		} else if item.Key == "" {
-			response.ResourceTypeID = append(response.ResourceTypeID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: "Unknown",
-			})
-		} else {
-			response.ResourceTypeID = append(response.ResourceTypeID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: item.Key,
-				Count:       utils.GetPointer(item.DocCount),
-			})
+	// the columns of queries like select * are only known once they run, the dry-run checks them
+	if len(result.Columns) > 0 {
+		response.Columns = result.Columns
+		for _, column := range missingRequiredColumns(result.Columns) {
+			response.Valid = false
+			response.Errors = append(response.Errors, fmt.Sprintf("missing required column %s", column))
 		}
 	}
+	return response, nil
+}
 
-	for _, item := range possibleFilters.Aggregations.ConnectionIDFilter.Buckets {
-		if connection, ok := connectionMetadataMap[item.Key]; ok {
-			response.ConnectionID = append(response.ConnectionID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: connection.ConnectionName,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		} else {
-			response.ConnectionID = append(response.ConnectionID, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: item.Key,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		}
+// checkCustomQuery returns a bad request error if the query is not valid
+func (h *HttpHandler) checkCustomQuery(echoCtx echo.Context, customQuery api.CustomQuery) error {
+	validation, err := h.validateCustomQuery(echoCtx, customQuery)
+	if err != nil {
+		return err
 	}
+	if !validation.Valid {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid query: %s", strings.Join(validation.Errors, ", ")))
+	}
+	return nil
+}
 
-	for _, item := range possibleFilters.Aggregations.ResourceCollectionFilter.Buckets {
-		if resourceCollection, ok := resourceCollectionMetadataMap[item.Key]; ok {
-			response.ResourceCollection = append(response.ResourceCollection, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: resourceCollection.Name,
-				Count:       utils.GetPointer(item.DocCount),
-			})
-		} else {
-			response.ResourceCollection = append(response.ResourceCollection, api.FilterWithMetadata{
-				Key:         item.Key,
-				DisplayName: item.Key,
-				Count:       utils.GetPointer(item.DocCount),
-			})
+func missingRequiredColumns(columns []string) []string {
+	var missing []string
+	for _, column := range customQueryRequiredColumns {
+		if !listContains(columns, column) {
+			missing = append(missing, column)
 		}
 	}
+	return missing
+}
 
-	for _, item := range possibleFilters.Aggregations.SeverityFilter.Buckets {
-		response.Severity = append(response.Severity, api.FilterWithMetadata{
-			Key:         item.Key,
-			DisplayName: item.Key,
-			Count:       utils.GetPointer(item.DocCount),
+func trimCustomQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), "; \n\t")
+}
+
+func newCustomQuery(id string, connectors []string, customQuery api.CustomQuery) db.Query {
+	query := db.Query{
+		ID:             id,
+		QueryToExecute: customQuery.QueryToExecute,
+		Connector:      connectors,
+		PrimaryTable:   customQuery.PrimaryTable,
+		ListOfTables:   customQuery.ListOfTables,
+		Engine:         string(api.QueryEngine_OdysseusSQL),
+		Global:         customQuery.Global,
+		Source:         api.ContentSourceCustom,
+	}
+	for _, parameter := range customQuery.Parameters {
+		query.Parameters = append(query.Parameters, db.QueryParameter{
+			QueryID:  id,
+			Key:      parameter.Key,
+			Required: parameter.Required,
 		})
 	}
+	return query
+}
 
-	for _, item := range possibleFilters.Aggregations.StateActiveFilter.Buckets {
-		response.StateActive = append(response.StateActive, api.FilterWithMetadata{
-			Key:         item.KeyAsString,
-			DisplayName: item.KeyAsString,
-			Count:       utils.GetPointer(item.DocCount),
+func newCustomControlTags(controlID string, tags map[string][]string) []db.ControlTag {
+	result := make([]db.ControlTag, 0, len(tags))
+	for key, value := range tags {
+		result = append(result, db.ControlTag{
+			Tag: model.Tag{
+				Key:   key,
+				Value: value,
+			},
+			ControlID: controlID,
 		})
 	}
+	return result
+}
 
-	apiConformanceStatuses := make(map[api.ConformanceStatus]int)
-	for _, item := range possibleFilters.Aggregations.ConformanceStatusFilter.Buckets {
-		if kaytuTypes.ParseConformanceStatus(item.Key).IsPassed() {
-			apiConformanceStatuses[api.ConformanceStatusPassed] += item.DocCount
-		} else {
-			apiConformanceStatuses[api.ConformanceStatusFailed] += item.DocCount
-		}
-	}
-	for status, count := range apiConformanceStatuses {
-		count := count
-		response.ConformanceStatus = append(response.ConformanceStatus, api.FilterWithMetadata{
-			Key:         string(status),
-			DisplayName: string(status),
-			Count:       &count,
+func newCustomBenchmarkTags(benchmarkID string, tags map[string][]string) []db.BenchmarkTag {
+	result := make([]db.BenchmarkTag, 0, len(tags))
+	for key, value := range tags {
+		result = append(result, db.BenchmarkTag{
+			Tag: model.Tag{
+				Key:   key,
+				Value: value,
+			},
+			BenchmarkID: benchmarkID,
 		})
 	}
-
-	return echoCtx.JSON(http.StatusOK, response)
+	return result
 }
 
-// GetSingleFindingEvent
-//
-//	@Summary		Get single finding event
-//	@Description	Retrieving single finding event
-//	@Security		BearerToken
-//	@Tags			compliance
-//	@Accept			json
-//	@Produce		json
-//	@Param			findingID	path		string	true	"FindingID"
-//	@Success		200			{object}	api.FindingEvent
-//	@Router			/compliance/api/v1/finding_events/single/{id} [get]
-func (h *HttpHandler) GetSingleFindingEvent(echoCtx echo.Context) error {
-	ctx := echoCtx.Request().Context()
-
-	findingEventID := echoCtx.Param("id")
+func (h *HttpHandler) getCustomControlFromParam(echoCtx echo.Context) (*db.Control, error) {
+	controlID := echoCtx.Param("control_id")
+	control, err := h.db.GetControl(echoCtx.Request().Context(), controlID)
+	if err != nil {
+		h.logger.Error("failed to get control", zap.Error(err), zap.String("controlId", controlID))
+		return nil, err
+	}
+	if control == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "control not found")
+	}
+	if control.Source != api.ContentSourceCustom {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "built-in controls cannot be modified")
+	}
+	return control, nil
+}
 
-	findingEvent, err := es.FetchFindingEventByID(ctx, h.logger, h.client, findingEventID)
+func (h *HttpHandler) getCustomBenchmarkFromParam(echoCtx echo.Context) (*db.Benchmark, error) {
+	benchmarkID := echoCtx.Param("benchmark_id")
+	benchmark, err := h.db.GetBenchmarkBare(echoCtx.Request().Context(), benchmarkID)
 	if err != nil {
-		h.logger.Error("failed to fetch findingEvent by id", zap.Error(err))
-		return err
+		h.logger.Error("failed to get benchmark", zap.Error(err), zap.String("benchmarkId", benchmarkID))
+		return nil, err
 	}
-	if findingEvent == nil {
-		return echo.NewHTTPError(http.StatusNotFound, "findingEvent not found")
+	if benchmark == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "benchmark not found")
 	}
+	if benchmark.Source != api.ContentSourceCustom {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "built-in benchmarks cannot be modified")
+	}
+	return benchmark, nil
+}
 
-	apiFindingEvent := api.GetAPIFindingEventFromESFindingEvent(*findingEvent)
+func (h *HttpHandler) getCustomQueryFromParam(echoCtx echo.Context) (*db.Query, error) {
+	queryID := echoCtx.Param("query_id")
+	query, err := h.db.GetQuery(echoCtx.Request().Context(), queryID)
+	if err != nil {
+		h.logger.Error("failed to get query", zap.Error(err), zap.String("queryId", queryID))
+		return nil, err
+	}
+	if query == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "query not found")
+	}
+	if query.Source != api.ContentSourceCustom {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "built-in queries cannot be modified")
+	}
+	return query, nil
+}
 
-	connection, err := h.onboardClient.GetSource(httpclient.FromEchoContext(echoCtx), findingEvent.ConnectionID)
+// getReferencedCustomQuery returns the custom query a control refers to, built-in queries cannot be used
+// since they are recreated by every content migration
+func (h *HttpHandler) getReferencedCustomQuery(ctx context.Context, queryID string) (*db.Query, error) {
+	query, err := h.db.GetQuery(ctx, queryID)
 	if err != nil {
-		h.logger.Error("failed to get connection", zap.Error(err), zap.String("connection_id", findingEvent.ConnectionID))
-		return err
+		h.logger.Error("failed to get query", zap.Error(err), zap.String("queryId", queryID))
+		return nil, err
 	}
-	apiFindingEvent.ProviderConnectionID = connection.ConnectionID
-	apiFindingEvent.ProviderConnectionName = connection.ConnectionName
+	if query == nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("query %s not found", queryID))
+	}
+	if query.Source != api.ContentSourceCustom {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("query %s is not a custom query", queryID))
+	}
+	return query, nil
+}
 
-	if len(findingEvent.ResourceType) > 0 {
-		resourceTypeMetadata, err := h.inventoryClient.ListResourceTypesMetadata(httpclient.FromEchoContext(echoCtx),
-			nil, nil,
-			[]string{findingEvent.ResourceType}, false, nil, 10000, 1)
+// checkCustomBenchmarkLinks makes sure the children and controls exist and no child contains the benchmark itself
+func (h *HttpHandler) checkCustomBenchmarkLinks(ctx context.Context, benchmarkID string, childIDs, controlIDs []string) error {
+	for _, childID := range childIDs {
+		if childID == benchmarkID {
+			return echo.NewHTTPError(http.StatusBadRequest, "benchmark cannot be its own child")
+		}
+		child, err := h.db.GetBenchmarkBare(ctx, childID)
 		if err != nil {
-			h.logger.Error("failed to get resource type metadata", zap.Error(err))
+			h.logger.Error("failed to get benchmark", zap.Error(err), zap.String("benchmarkId", childID))
 			return err
 		}
-		if len(resourceTypeMetadata.ResourceTypes) > 0 {
-			apiFindingEvent.ResourceTypeName = resourceTypeMetadata.ResourceTypes[0].ResourceLabel
+		if child == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("child benchmark %s not found", childID))
+		}
+		subtree, err := h.getChildBenchmarks(ctx, childID)
+		if err != nil {
+			return err
+		}
+		if listContains(subtree, benchmarkID) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("child benchmark %s already contains %s", childID, benchmarkID))
+		}
+	}
+	if len(controlIDs) > 0 {
+		controls, err := h.db.GetControls(ctx, controlIDs, nil)
+		if err != nil {
+			h.logger.Error("failed to get controls", zap.Error(err))
+			return err
+		}
+		found := make(map[string]bool)
+		for _, control := range controls {
+			found[control.ID] = true
+		}
+		for _, controlID := range controlIDs {
+			if !found[controlID] {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("control %s not found", controlID))
+			}
 		}
 	}
+	return nil
+}
 
-	return echoCtx.JSON(http.StatusOK, apiFindingEvent)
+// refreshBenchmarksMetadata recomputes the controls and tables metadata of the given benchmarks and their ancestors
+func (h *HttpHandler) refreshBenchmarksMetadata(ctx context.Context, benchmarkIDs []string) error {
+	visited := make(map[string]bool)
+	for len(benchmarkIDs) > 0 {
+		benchmarkID := benchmarkIDs[0]
+		benchmarkIDs = benchmarkIDs[1:]
+		if benchmarkID == "" || visited[benchmarkID] {
+			continue
+		}
+		visited[benchmarkID] = true
+
+		controls, err := h.getControlsUnderBenchmark(ctx, benchmarkID, make(map[string]BenchmarkControlsCache))
+		if err != nil {
+			return err
+		}
+		primaryTables, listOfTables, err := h.getTablesUnderBenchmark(ctx, benchmarkID, make(map[string]BenchmarkTablesCache))
+		if err != nil {
+			return err
+		}
+		metadataJson, err := json.Marshal(db.BenchmarkMetadata{
+			Controls:      mapToArray(controls),
+			PrimaryTables: mapToArray(primaryTables),
+			ListOfTables:  mapToArray(listOfTables),
+		})
+		if err != nil {
+			return err
+		}
+		metadata := pgtype.JSONB{}
+		if err := metadata.Set(metadataJson); err != nil {
+			return err
+		}
+		if err := h.db.UpdateBenchmarkMetadata(ctx, benchmarkID, metadata); err != nil {
+			h.logger.Error("failed to update benchmark metadata", zap.Error(err), zap.String("benchmarkId", benchmarkID))
+			return err
+		}
+
+		parentID, err := h.db.GetBenchmarkParent(ctx, benchmarkID)
+		if err != nil {
+			h.logger.Error("failed to get benchmark parent", zap.Error(err), zap.String("benchmarkId", benchmarkID))
+			return err
+		}
+		benchmarkIDs = append(benchmarkIDs, parentID)
+	}
+	return nil
 }
 
 // ListResourceFindings godoc
@@ -2612,8 +5129,11 @@ func (h *HttpHandler) ListBenchmarksSummary(echoCtx echo.Context) error {
 		sResult := kaytuTypes.SeverityResult{}
 		controlSeverityResult := api.BenchmarkControlsSeverityStatus{}
 		var costOptimization *float64
+		var weightedScore types.WeightedScore
 		addToResults := func(resultGroup types.ResultGroup) {
 			csResult.AddESConformanceStatusMap(resultGroup.Result.QueryResult)
+			csResult.ExceptedCount += resultGroup.Result.ExceptedCount()
+			weightedScore.Add(resultGroup.Result.Weighted)
 			sResult.AddResultMap(resultGroup.Result.SeverityResult)
 			costOptimization = utils.PAdd(costOptimization, resultGroup.Result.CostOptimization)
 			for controlId, controlResult := range resultGroup.Controls {
@@ -2706,6 +5226,7 @@ func (h *HttpHandler) ListBenchmarksSummary(echoCtx echo.Context) error {
 			ControlsSeverityStatus:   controlSeverityResult,
 			ResourcesSeverityStatus:  resourcesSeverityResult,
 			CostOptimization:         costOptimization,
+			WeightedSecurityScore:    weightedScore.Score(),
 			EvaluatedAt:              utils.GetPointer(time.Unix(summaryAtTime.EvaluatedAtEpoch, 0)),
 			LastJobStatus:            "",
 			TopConnections:           topConnections,
@@ -2824,8 +5345,11 @@ func (h *HttpHandler) GetBenchmarkSummary(echoCtx echo.Context) error {
 	controlSeverityResult := api.BenchmarkControlsSeverityStatus{}
 	connectionsResult := api.BenchmarkStatusResult{}
 	var costOptimization *float64
+	var weightedScore types.WeightedScore
 	addToResults := func(resultGroup types.ResultGroup) {
 		csResult.AddESConformanceStatusMap(resultGroup.Result.QueryResult)
+		csResult.ExceptedCount += resultGroup.Result.ExceptedCount()
+		weightedScore.Add(resultGroup.Result.Weighted)
 		sResult.AddResultMap(resultGroup.Result.SeverityResult)
 		costOptimization = utils.PAdd(costOptimization, resultGroup.Result.CostOptimization)
 		for controlId, controlResult := range resultGroup.Controls {
@@ -2950,6 +5474,7 @@ func (h *HttpHandler) GetBenchmarkSummary(echoCtx echo.Context) error {
 		ResourcesSeverityStatus:  resourcesSeverityResult,
 		ConnectionsStatus:        connectionsResult,
 		CostOptimization:         costOptimization,
+		WeightedSecurityScore:    weightedScore.Score(),
 		EvaluatedAt:              utils.GetPointer(time.Unix(summaryAtTime.EvaluatedAtEpoch, 0)),
 		LastJobStatus:            lastJobStatus,
 	}
@@ -3288,6 +5813,7 @@ func (h *HttpHandler) GetBenchmarkTrend(echoCtx echo.Context) error {
 		}
 		apiDataPoint.ConformanceStatusSummary.AddESConformanceStatusMap(datapoint.QueryResult)
 		apiDataPoint.Checks.AddResultMap(datapoint.SeverityResult)
+		apiDataPoint.WeightedSecurityScore = datapoint.Weighted.Score()
 		for controlId, controlResult := range datapoint.Controls {
 			control := controlsMap[strings.ToLower(controlId)]
 			apiDataPoint.ControlsSeverityStatus = addToControlSeverityResult(apiDataPoint.ControlsSeverityStatus, control, controlResult)
@@ -5817,11 +8343,18 @@ func (h *HttpHandler) GetFindingsV2(echoCtx echo.Context) error {
 	//	evaluatedAtTo = utils.GetPointer(time.Unix(*req.Filters.EvaluatedAt.To, 0))
 	//}
 
+	slaPolicy, err := h.getFindingSLAPolicy(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
 	res, totalCount, err := es.FindingsQueryV2(ctx, h.logger, h.client, req.Filters.ResourceID, req.Filters.NotResourceID, nil,
 		connectionIds, nil, req.Filters.ResourceType, req.Filters.NotResourceType, req.Filters.BenchmarkID,
 		req.Filters.NotBenchmarkID, req.Filters.ControlID, req.Filters.NotControlID,
 		req.Filters.Severity, req.Filters.NotSeverity, lastEventFrom, lastEventTo, notLastEventFrom, notLastEventTo,
-		evaluatedAtFrom, evaluatedAtTo, req.Filters.IsActive, esConformanceStatuses, req.Sort, req.Limit, req.AfterSortKey)
+		evaluatedAtFrom, evaluatedAtTo, req.Filters.IsActive, esConformanceStatuses, req.Filters.Owner, req.Filters.Overdue, slaPolicy,
+		req.Sort, req.Limit, req.AfterSortKey)
 	if err != nil {
 		h.logger.Error("failed to get findings", zap.Error(err))
 		return err
@@ -5894,6 +8427,7 @@ func (h *HttpHandler) GetFindingsV2(echoCtx echo.Context) error {
 			finding.ResourceTypeName = rtMetadata.ResourceLabel
 		}
 
+		slaPolicy.Apply(&finding, now)
 		finding.SortKey = h.Sort
 
 		response.Findings = append(response.Findings, finding)
@@ -6168,6 +8702,7 @@ func (h *HttpHandler) ComplianceSummaryOfIntegration(echoCtx echo.Context) error
 	var costOptimization *float64
 	addToResults := func(resultGroup types.ResultGroup) {
 		csResult.AddESConformanceStatusMap(resultGroup.Result.QueryResult)
+		csResult.ExceptedCount += resultGroup.Result.ExceptedCount()
 		sResult.AddResultMap(resultGroup.Result.SeverityResult)
 		costOptimization = utils.PAdd(costOptimization, resultGroup.Result.CostOptimization)
 		for controlId, controlResult := range resultGroup.Controls {
@@ -6386,8 +8921,11 @@ func (h *HttpHandler) ComplianceSummaryOfBenchmark(echoCtx echo.Context) error {
 		sResult := kaytuTypes.SeverityResultV2{}
 		controlSeverityResult := api.BenchmarkControlsSeverityStatusV2{}
 		var costOptimization *float64
+		var weightedScore types.WeightedScore
 		addToResults := func(resultGroup types.ResultGroup) {
 			csResult.AddESConformanceStatusMap(resultGroup.Result.QueryResult)
+			csResult.ExceptedCount += resultGroup.Result.ExceptedCount()
+			weightedScore.Add(resultGroup.Result.Weighted)
 			sResult.AddResultMap(resultGroup.Result.SeverityResult)
 			costOptimization = utils.PAdd(costOptimization, resultGroup.Result.CostOptimization)
 			for controlId, controlResult := range resultGroup.Controls {
@@ -6569,6 +9107,7 @@ func (h *HttpHandler) ComplianceSummaryOfBenchmark(echoCtx echo.Context) error {
 			BenchmarkTitle:             benchmark.Title,
 			Connectors:                 connectors,
 			ComplianceScore:            complianceScore,
+			WeightedSecurityScore:      weightedScore.Score(),
 			SeveritySummaryByControl:   controlSeverityResult,
 			SeveritySummaryByResource:  resourcesSeverityResult,
 			SeveritySummaryByIncidents: sResult,
@@ -6673,6 +9212,7 @@ func (h *HttpHandler) ComplianceSummaryOfJob(echoCtx echo.Context) error {
 	var costOptimization *float64
 	addToResults := func(resultGroup types.ResultGroup) {
 		csResult.AddESConformanceStatusMap(resultGroup.Result.QueryResult)
+		csResult.ExceptedCount += resultGroup.Result.ExceptedCount()
 		sResult.AddResultMap(resultGroup.Result.SeverityResult)
 		costOptimization = utils.PAdd(costOptimization, resultGroup.Result.CostOptimization)
 		for controlId, controlResult := range resultGroup.Controls {
@@ -7305,6 +9845,7 @@ func (h *HttpHandler) ListComplianceJobsHistory(ctx echo.Context) error {
 			csResult := api.ConformanceStatusSummaryV2{}
 			addToResults := func(resultGroup types.ResultGroup) {
 				csResult.AddESConformanceStatusMap(resultGroup.Result.QueryResult)
+				csResult.ExceptedCount += resultGroup.Result.ExceptedCount()
 			}
 
 			addToResults(summaryAtTime.Connections.BenchmarkResult)
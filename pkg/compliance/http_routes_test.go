@@ -2,6 +2,7 @@ package compliance
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -75,7 +76,7 @@ func (s *HttpServerSuite) SetupSuite() {
 		db: db.Database{Orm: adb},
 	}
 
-	err = s.handler.db.Initialize()
+	err = s.handler.db.Initialize(context.Background())
 	require.NoError(err, "db initialize")
 
 	logger, err := zap.NewProduction()
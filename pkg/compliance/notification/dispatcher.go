@@ -0,0 +1,217 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/db"
+	"github.com/kaytu-io/open-governance/pkg/compliance/es"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"go.uber.org/zap"
+)
+
+// maxEventsPerDelivery caps the payload size, larger batches are split in multiple deliveries
+const maxEventsPerDelivery = 100
+
+// DispatchTimeout bounds a background dispatch of a compliance job, including the retries of every delivery
+const DispatchTimeout = 30 * time.Minute
+
+type Dispatcher struct {
+	logger   *zap.Logger
+	db       db.Database
+	esClient kaytu.Client
+	sender   *WebhookSender
+}
+
+func NewDispatcher(logger *zap.Logger, db db.Database, esClient kaytu.Client) *Dispatcher {
+	return &Dispatcher{
+		logger:   logger.Named("notification"),
+		db:       db,
+		esClient: esClient,
+		sender:   NewWebhookSender(),
+	}
+}
+
+// TransitionOf returns the direction of the finding event, events that don't cross the failing/passing line are ignored
+func TransitionOf(event types.FindingEvent) (api.NotificationTransition, bool) {
+	wasFailing := event.PreviousStateActive && event.PreviousConformanceStatus != "" && !event.PreviousConformanceStatus.IsPassed()
+	isFailing := event.StateActive && !event.ConformanceStatus.IsPassed()
+	switch {
+	case !wasFailing && isFailing:
+		return api.NotificationTransitionFailing, true
+	case wasFailing && !isFailing:
+		return api.NotificationTransitionPassing, true
+	}
+	return "", false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether the subscription filters accept the event, empty filters accept everything
+func Matches(subscription db.NotificationSubscription, event types.FindingEvent, transition api.NotificationTransition) bool {
+	if len(subscription.BenchmarkIDs) > 0 && !containsFold(subscription.BenchmarkIDs, event.BenchmarkID) {
+		return false
+	}
+	if len(subscription.ControlIDs) > 0 && !containsFold(subscription.ControlIDs, event.ControlID) {
+		return false
+	}
+	if len(subscription.Severities) > 0 && !containsFold(subscription.Severities, string(event.Severity)) {
+		return false
+	}
+	if len(subscription.ConnectionIDs) > 0 && !containsFold(subscription.ConnectionIDs, event.ConnectionID) {
+		return false
+	}
+	if len(subscription.Transitions) > 0 && !containsFold(subscription.Transitions, string(transition)) {
+		return false
+	}
+	return true
+}
+
+// complianceJobEventFilters selects the finding events of the compliance job, limited to the given runners if any
+func complianceJobEventFilters(complianceJobID uint, runnerIDs []uint) []kaytu.BoolFilter {
+	filters := []kaytu.BoolFilter{
+		kaytu.NewTermFilter("parentComplianceJobID", fmt.Sprintf("%d", complianceJobID)),
+	}
+	if len(runnerIDs) > 0 {
+		ids := make([]string, 0, len(runnerIDs))
+		for _, id := range runnerIDs {
+			ids = append(ids, fmt.Sprintf("%d", id))
+		}
+		filters = append(filters, kaytu.NewTermsFilter("complianceJobID", ids))
+	}
+	return filters
+}
+
+// DispatchComplianceJob delivers the finding events produced by the compliance job to every matching subscription,
+// when runner ids are given only their events are delivered so a retried job doesn't notify the same events again
+func (d *Dispatcher) DispatchComplianceJob(ctx context.Context, complianceJobID uint, runnerIDs []uint) error {
+	subscriptions, err := d.db.ListNotificationSubscriptions(ctx, true)
+	if err != nil {
+		d.logger.Error("failed to list notification subscriptions", zap.Error(err))
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	paginator, err := es.NewFindingEventPaginator(d.esClient, types.FindingEventsIndex, complianceJobEventFilters(complianceJobID, runnerIDs), nil, []map[string]any{
+		{"evaluatedAt": "asc"},
+	})
+	if err != nil {
+		d.logger.Error("failed to create finding events paginator", zap.Error(err))
+		return err
+	}
+	defer func() {
+		if err := paginator.Close(ctx); err != nil {
+			d.logger.Error("failed to close finding events paginator", zap.Error(err))
+		}
+	}()
+
+	batches := make(map[uint][]api.NotificationEvent)
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			d.logger.Error("failed to get finding events page", zap.Error(err))
+			return err
+		}
+
+		for _, event := range page {
+			transition, ok := TransitionOf(event)
+			if !ok {
+				continue
+			}
+			for _, subscription := range subscriptions {
+				if !Matches(subscription, event, transition) {
+					continue
+				}
+				batches[subscription.ID] = append(batches[subscription.ID], api.NotificationEvent{
+					Transition:   transition,
+					FindingEvent: api.GetAPIFindingEventFromESFindingEvent(event),
+				})
+				if len(batches[subscription.ID]) >= maxEventsPerDelivery {
+					d.deliver(ctx, subscription, complianceJobID, batches[subscription.ID])
+					batches[subscription.ID] = nil
+				}
+			}
+		}
+	}
+
+	for _, subscription := range subscriptions {
+		if len(batches[subscription.ID]) > 0 {
+			d.deliver(ctx, subscription, complianceJobID, batches[subscription.ID])
+		}
+	}
+	return nil
+}
+
+// SendTest delivers a single sample event so receivers can verify their endpoint and signature check
+func (d *Dispatcher) SendTest(ctx context.Context, subscription db.NotificationSubscription) (*db.NotificationDelivery, error) {
+	event := api.NotificationEvent{
+		Transition: api.NotificationTransitionFailing,
+		FindingEvent: api.FindingEvent{
+			ID:                "test",
+			FindingID:         "test",
+			ConformanceStatus: api.ConformanceStatusFailed,
+			StateActive:       true,
+			EvaluatedAt:       time.Now(),
+			Reason:            "Test notification",
+			Severity:          types.FindingSeverityNone,
+		},
+	}
+	return d.deliver(ctx, subscription, 0, []api.NotificationEvent{event})
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, subscription db.NotificationSubscription, complianceJobID uint, events []api.NotificationEvent) (*db.NotificationDelivery, error) {
+	logger := d.logger.With(zap.Uint("subscription_id", subscription.ID), zap.Uint("compliance_job_id", complianceJobID))
+
+	delivery := db.NotificationDelivery{
+		SubscriptionID:  subscription.ID,
+		ComplianceJobID: complianceJobID,
+		EventCount:      len(events),
+	}
+	if err := d.db.CreateNotificationDelivery(ctx, &delivery); err != nil {
+		logger.Error("failed to create notification delivery", zap.Error(err))
+		return nil, err
+	}
+
+	body, err := json.Marshal(api.NotificationPayload{
+		DeliveryID:      delivery.ID,
+		SubscriptionID:  subscription.ID,
+		ComplianceJobID: complianceJobID,
+		SentAt:          time.Now(),
+		Events:          events,
+	})
+	if err != nil {
+		logger.Error("failed to marshal notification payload", zap.Error(err))
+		return nil, err
+	}
+
+	result := d.sender.Send(ctx, subscription.URL, subscription.Secret, delivery.ID, body)
+	delivery.Attempts = result.Attempts
+	delivery.StatusCode = result.StatusCode
+	delivery.Succeeded = result.Err == nil
+	if result.Err != nil {
+		delivery.Error = result.Err.Error()
+		logger.Warn("failed to deliver notification", zap.Int("attempts", result.Attempts), zap.Error(result.Err))
+	} else {
+		now := time.Now()
+		delivery.DeliveredAt = &now
+	}
+	if err := d.db.UpdateNotificationDelivery(ctx, &delivery); err != nil {
+		logger.Error("failed to update notification delivery", zap.Error(err))
+		return nil, err
+	}
+	return &delivery, nil
+}
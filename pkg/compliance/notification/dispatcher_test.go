@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/db"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  types.FindingEvent
+		want   api.NotificationTransition
+		wantOk bool
+	}{
+		{
+			name:   "new failing finding",
+			event:  types.FindingEvent{ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+			want:   api.NotificationTransitionFailing,
+			wantOk: true,
+		},
+		{
+			name: "passing finding starts failing",
+			event: types.FindingEvent{
+				PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true,
+				ConformanceStatus: types.ConformanceStatusALARM, StateActive: true,
+			},
+			want:   api.NotificationTransitionFailing,
+			wantOk: true,
+		},
+		{
+			name: "failing finding passes",
+			event: types.FindingEvent{
+				PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true,
+				ConformanceStatus: types.ConformanceStatusOK, StateActive: true,
+			},
+			want:   api.NotificationTransitionPassing,
+			wantOk: true,
+		},
+		{
+			name: "resource of failing finding is gone",
+			event: types.FindingEvent{
+				PreviousConformanceStatus: types.ConformanceStatusERROR, PreviousStateActive: true,
+				ConformanceStatus: types.ConformanceStatusALARM, StateActive: false,
+			},
+			want:   api.NotificationTransitionPassing,
+			wantOk: true,
+		},
+		{
+			name: "still failing",
+			event: types.FindingEvent{
+				PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true,
+				ConformanceStatus: types.ConformanceStatusERROR, StateActive: true,
+			},
+		},
+		{
+			name: "still passing",
+			event: types.FindingEvent{
+				PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true,
+				ConformanceStatus: types.ConformanceStatusSKIP, StateActive: true,
+			},
+		},
+		{
+			name:  "new passing finding",
+			event: types.FindingEvent{ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			transition, ok := TransitionOf(tc.event)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, transition)
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	event := types.FindingEvent{
+		BenchmarkID:  "aws_cis_v200",
+		ControlID:    "aws_cis_v200_1_4",
+		ConnectionID: "conn-1",
+		Severity:     types.FindingSeverityHigh,
+	}
+	tests := []struct {
+		name         string
+		subscription db.NotificationSubscription
+		transition   api.NotificationTransition
+		want         bool
+	}{
+		{name: "no filters", want: true},
+		{name: "benchmark", subscription: db.NotificationSubscription{BenchmarkIDs: []string{"other", "AWS_CIS_V200"}}, want: true},
+		{name: "other benchmark", subscription: db.NotificationSubscription{BenchmarkIDs: []string{"other"}}},
+		{name: "control", subscription: db.NotificationSubscription{ControlIDs: []string{"aws_cis_v200_1_4"}}, want: true},
+		{name: "other control", subscription: db.NotificationSubscription{ControlIDs: []string{"aws_cis_v200_1_5"}}},
+		{name: "severity", subscription: db.NotificationSubscription{Severities: []string{"critical", "high"}}, want: true},
+		{name: "other severity", subscription: db.NotificationSubscription{Severities: []string{"critical"}}},
+		{name: "connection", subscription: db.NotificationSubscription{ConnectionIDs: []string{"conn-1"}}, want: true},
+		{name: "other connection", subscription: db.NotificationSubscription{ConnectionIDs: []string{"conn-2"}}},
+		{name: "transition", subscription: db.NotificationSubscription{Transitions: []string{"failing"}}, want: true},
+		{name: "other transition", subscription: db.NotificationSubscription{Transitions: []string{"passing"}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Matches(tc.subscription, event, api.NotificationTransitionFailing))
+		})
+	}
+}
+
+func TestComplianceJobEventFilters(t *testing.T) {
+	assert.Equal(t, []kaytu.BoolFilter{
+		kaytu.NewTermFilter("parentComplianceJobID", "12"),
+	}, complianceJobEventFilters(12, nil))
+
+	assert.Equal(t, []kaytu.BoolFilter{
+		kaytu.NewTermFilter("parentComplianceJobID", "12"),
+		kaytu.NewTermsFilter("complianceJobID", []string{"3", "7"}),
+	}, complianceJobEventFilters(12, []uint{3, 7}))
+}
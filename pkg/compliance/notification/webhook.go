@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	SignatureHeader  = "X-Signature-256"
+	TimestampHeader  = "X-Timestamp"
+	DeliveryIDHeader = "X-Delivery-ID"
+
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 2 * time.Second
+	defaultRequestTimeout = 15 * time.Second
+	defaultDialTimeout    = 10 * time.Second
+)
+
+// ErrForbiddenWebhookAddress is returned for webhooks pointing to loopback, private, link-local or other internal addresses
+var ErrForbiddenWebhookAddress = errors.New("webhook address is not publicly routable")
+
+// carrier-grade NAT range, it is not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isForbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// ValidateWebhookURL checks the webhook is an http(s) url whose host only resolves to public addresses.
+// Addresses are checked again when connecting, since the host can resolve differently later.
+func ValidateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook url scheme must be http or https")
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("webhook url has no host")
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if isForbiddenIP(ip) {
+			return ErrForbiddenWebhookAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if isForbiddenIP(addr.IP) {
+			return ErrForbiddenWebhookAddress
+		}
+	}
+	return nil
+}
+
+// publicOnlyControl refuses connections to internal addresses, it runs after name resolution and on every redirect
+func publicOnlyControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isForbiddenIP(ip) {
+		return ErrForbiddenWebhookAddress
+	}
+	return nil
+}
+
+// Sign returns the signature receivers compare against the X-Signature-256 header
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type DeliveryResult struct {
+	Attempts   int
+	StatusCode int
+	Err        error
+}
+
+type WebhookSender struct {
+	httpClient     *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+}
+
+func NewWebhookSender() *WebhookSender {
+	dialer := &net.Dialer{
+		Timeout: defaultDialTimeout,
+		Control: publicOnlyControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would make the dialer check the proxy address instead of the webhook one
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &WebhookSender{
+		httpClient:     &http.Client{Timeout: defaultRequestTimeout, Transport: transport},
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+	}
+}
+
+// Send posts the signed body, retrying with exponential backoff on network errors, 429 and 5xx responses
+func (s *WebhookSender) Send(ctx context.Context, url, secret string, deliveryID uint, body []byte) DeliveryResult {
+	var result DeliveryResult
+	backoff := s.initialBackoff
+	for result.Attempts < s.maxAttempts {
+		if result.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return result
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		result.Attempts++
+
+		retryable := false
+		result.StatusCode, retryable, result.Err = s.post(ctx, url, secret, deliveryID, body)
+		if result.Err == nil || !retryable {
+			return result
+		}
+	}
+	return result
+}
+
+func (s *WebhookSender) post(ctx context.Context, url, secret string, deliveryID uint, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "open-governance-webhook")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(DeliveryIDHeader, strconv.FormatUint(uint64(deliveryID), 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, !errors.Is(err, ErrForbiddenWebhookAddress), err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return res.StatusCode, false, nil
+	}
+	retryable := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+	return res.StatusCode, retryable, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+}
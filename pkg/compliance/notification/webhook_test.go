@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSender returns a sender which is allowed to reach the local test server
+func newTestSender(server *httptest.Server) *WebhookSender {
+	return &WebhookSender{
+		httpClient:     server.Client(),
+		maxAttempts:    3,
+		initialBackoff: time.Millisecond,
+	}
+}
+
+func TestWebhookSender_SignedDelivery(t *testing.T) {
+	body := []byte(`{"events":[]}`)
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "42", r.Header.Get(DeliveryIDHeader))
+
+		got, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, got)
+		timestamp := r.Header.Get(TimestampHeader)
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(t, Sign("secret", timestamp, got), r.Header.Get(SignatureHeader))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	result := newTestSender(server).Send(context.Background(), server.URL, "secret", 42, body)
+	require.NoError(t, result.Err)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, http.StatusNoContent, result.StatusCode)
+	assert.Equal(t, int32(1), received.Load())
+}
+
+func TestWebhookSender_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantStatus   int
+		wantErr      bool
+	}{
+		{name: "server error then success", statuses: []int{500, 502, 200}, wantAttempts: 3, wantStatus: 200},
+		{name: "rate limited then success", statuses: []int{429, 200}, wantAttempts: 2, wantStatus: 200},
+		{name: "client error is not retried", statuses: []int{400}, wantAttempts: 1, wantStatus: 400, wantErr: true},
+		{name: "gives up after max attempts", statuses: []int{503, 503, 503, 503}, wantAttempts: 3, wantStatus: 503, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := int(calls.Add(1)) - 1
+				w.WriteHeader(tc.statuses[min(call, len(tc.statuses)-1)])
+			}))
+			defer server.Close()
+
+			result := newTestSender(server).Send(context.Background(), server.URL, "secret", 1, []byte(`{}`))
+			assert.Equal(t, tc.wantAttempts, result.Attempts)
+			assert.Equal(t, tc.wantStatus, result.StatusCode)
+			assert.Equal(t, tc.wantErr, result.Err != nil)
+			assert.Equal(t, int32(tc.wantAttempts), calls.Load())
+		})
+	}
+}
+
+func TestWebhookSender_StopsOnCanceledContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := newTestSender(server)
+	sender.initialBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := sender.Send(ctx, server.URL, "secret", 1, []byte(`{}`))
+	assert.Equal(t, 1, result.Attempts)
+	assert.ErrorIs(t, result.Err, context.DeadlineExceeded)
+}
+
+func TestWebhookSender_RefusesInternalAddresses(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	result := NewWebhookSender().Send(context.Background(), server.URL, "secret", 1, []byte(`{}`))
+	require.Error(t, result.Err)
+	assert.True(t, errors.Is(result.Err, ErrForbiddenWebhookAddress), "unexpected error %v", result.Err)
+	assert.Equal(t, 1, result.Attempts, "forbidden addresses are not retried")
+	assert.Equal(t, int32(0), calls.Load())
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		wantErr   bool
+		forbidden bool
+	}{
+		{url: "https://8.8.8.8/hook"},
+		{url: "http://1.1.1.1:8080/hook"},
+		{url: "https://[2606:4700:4700::1111]/hook"},
+		{url: "ftp://8.8.8.8/hook", wantErr: true},
+		{url: "https:///hook", wantErr: true},
+		{url: "http://127.0.0.1:8080/hook", wantErr: true, forbidden: true},
+		{url: "http://localhost/hook", wantErr: true, forbidden: true},
+		{url: "http://10.1.2.3/hook", wantErr: true, forbidden: true},
+		{url: "http://172.16.0.1/hook", wantErr: true, forbidden: true},
+		{url: "http://192.168.1.1/hook", wantErr: true, forbidden: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true, forbidden: true},
+		{url: "http://100.64.0.1/hook", wantErr: true, forbidden: true},
+		{url: "http://0.0.0.0/hook", wantErr: true, forbidden: true},
+		{url: "http://[::1]/hook", wantErr: true, forbidden: true},
+		{url: "http://[fd00::1]/hook", wantErr: true, forbidden: true},
+		{url: "http://[::ffff:127.0.0.1]/hook", wantErr: true, forbidden: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			err := ValidateWebhookURL(context.Background(), tc.url)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.forbidden, errors.Is(err, ErrForbiddenWebhookAddress), "unexpected error %v", err)
+		})
+	}
+}
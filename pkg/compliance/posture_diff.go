@@ -0,0 +1,322 @@
+package compliance
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/es"
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
+	onboardApi "github.com/kaytu-io/open-governance/pkg/onboard/api"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/kaytu-io/open-governance/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type postureDiffRange struct {
+	BaseJobID   *uint
+	TargetJobID *uint
+	BaseTime    *time.Time
+	TargetTime  *time.Time
+}
+
+// parsePostureDiffRange reads the range of a posture diff, either two compliance jobs or two unix timestamps.
+// The target time defaults to now.
+func parsePostureDiffRange(baseJobIDStr, targetJobIDStr, baseTimeStr, targetTimeStr string, now time.Time) (postureDiffRange, error) {
+	var diffRange postureDiffRange
+	if baseJobIDStr != "" || targetJobIDStr != "" {
+		if baseJobIDStr == "" || targetJobIDStr == "" {
+			return diffRange, echo.NewHTTPError(http.StatusBadRequest, "both baseJobId and targetJobId are required")
+		}
+		baseJobID, err := strconv.ParseUint(baseJobIDStr, 10, 64)
+		if err != nil {
+			return diffRange, echo.NewHTTPError(http.StatusBadRequest, "invalid baseJobId")
+		}
+		targetJobID, err := strconv.ParseUint(targetJobIDStr, 10, 64)
+		if err != nil {
+			return diffRange, echo.NewHTTPError(http.StatusBadRequest, "invalid targetJobId")
+		}
+		if baseJobID >= targetJobID {
+			return diffRange, echo.NewHTTPError(http.StatusBadRequest, "baseJobId must be older than targetJobId")
+		}
+		diffRange.BaseJobID = utils.GetPointer(uint(baseJobID))
+		diffRange.TargetJobID = utils.GetPointer(uint(targetJobID))
+		return diffRange, nil
+	}
+
+	if baseTimeStr == "" {
+		return diffRange, echo.NewHTTPError(http.StatusBadRequest, "either baseJobId and targetJobId or baseTime is required")
+	}
+	baseTimeInt, err := strconv.ParseInt(baseTimeStr, 10, 64)
+	if err != nil {
+		return diffRange, echo.NewHTTPError(http.StatusBadRequest, "invalid baseTime")
+	}
+	targetTime := now
+	if targetTimeStr != "" {
+		targetTimeInt, err := strconv.ParseInt(targetTimeStr, 10, 64)
+		if err != nil {
+			return diffRange, echo.NewHTTPError(http.StatusBadRequest, "invalid targetTime")
+		}
+		targetTime = time.Unix(targetTimeInt, 0)
+	}
+	baseTime := time.Unix(baseTimeInt, 0)
+	if !baseTime.Before(targetTime) {
+		return diffRange, echo.NewHTTPError(http.StatusBadRequest, "baseTime must be before targetTime")
+	}
+	diffRange.BaseTime = &baseTime
+	diffRange.TargetTime = &targetTime
+	return diffRange, nil
+}
+
+// postureDiffChangeOf classifies the net change of a finding, comparing the state before its first event in the range
+// with the state after its last one. Findings flapping back to their original state are not a change.
+func postureDiffChangeOf(first, last types.FindingEvent) (api.PostureDiffChange, bool) {
+	wasEvaluated := first.PreviousStateActive && first.PreviousConformanceStatus != ""
+	wasFailing := wasEvaluated && !first.PreviousConformanceStatus.IsPassed()
+
+	switch {
+	case !last.StateActive:
+		if wasEvaluated {
+			return api.PostureDiffChangeDisappeared, true
+		}
+	case !last.ConformanceStatus.IsPassed():
+		if !wasFailing {
+			return api.PostureDiffChangeNewlyFailing, true
+		}
+	default:
+		if wasFailing {
+			return api.PostureDiffChangeNewlyPassing, true
+		}
+	}
+	return "", false
+}
+
+// postureDiffFold keeps the first and last finding event of each finding, events have to be added in evaluation order
+type postureDiffFold struct {
+	firstEvents map[string]types.FindingEvent
+	lastEvents  map[string]types.FindingEvent
+}
+
+func newPostureDiffFold() *postureDiffFold {
+	return &postureDiffFold{
+		firstEvents: make(map[string]types.FindingEvent),
+		lastEvents:  make(map[string]types.FindingEvent),
+	}
+}
+
+func (f *postureDiffFold) add(event types.FindingEvent) {
+	if _, ok := f.firstEvents[event.FindingEsID]; !ok {
+		f.firstEvents[event.FindingEsID] = event
+	}
+	f.lastEvents[event.FindingEsID] = event
+}
+
+// changes returns the net change of each finding which changed in the range
+func (f *postureDiffFold) changes() map[string]api.PostureDiffChange {
+	changes := make(map[string]api.PostureDiffChange)
+	for findingID, last := range f.lastEvents {
+		if change, ok := postureDiffChangeOf(f.firstEvents[findingID], last); ok {
+			changes[findingID] = change
+		}
+	}
+	return changes
+}
+
+type postureDiffEntry struct {
+	change     api.PostureDiffChange
+	connection api.PostureDiffConnection
+	severity   types.FindingSeverity
+	item       api.PostureDiffItem
+}
+
+// groupPostureDiff groups the changed findings by connection and severity. Connections are ordered by id,
+// severities from critical to none and the items by control and resource.
+func groupPostureDiff(entries []postureDiffEntry) (api.PostureDiffCounts, []api.PostureDiffConnection) {
+	var total api.PostureDiffCounts
+	connections := make(map[string]*api.PostureDiffConnection)
+	severities := make(map[string]map[types.FindingSeverity]*api.PostureDiffSeverity)
+	for _, entry := range entries {
+		connectionID := entry.connection.ConnectionID
+		connection, ok := connections[connectionID]
+		if !ok {
+			c := entry.connection
+			connection = &c
+			connections[connectionID] = connection
+			severities[connectionID] = make(map[types.FindingSeverity]*api.PostureDiffSeverity)
+		}
+		severity, ok := severities[connectionID][entry.severity]
+		if !ok {
+			severity = &api.PostureDiffSeverity{
+				Severity:     entry.severity,
+				NewlyFailing: []api.PostureDiffItem{},
+				NewlyPassing: []api.PostureDiffItem{},
+				Disappeared:  []api.PostureDiffItem{},
+			}
+			severities[connectionID][entry.severity] = severity
+		}
+
+		switch entry.change {
+		case api.PostureDiffChangeNewlyFailing:
+			severity.NewlyFailing = append(severity.NewlyFailing, entry.item)
+		case api.PostureDiffChangeNewlyPassing:
+			severity.NewlyPassing = append(severity.NewlyPassing, entry.item)
+		case api.PostureDiffChangeDisappeared:
+			severity.Disappeared = append(severity.Disappeared, entry.item)
+		}
+		severity.Counts.Add(entry.change)
+		connection.Counts.Add(entry.change)
+		total.Add(entry.change)
+	}
+
+	result := make([]api.PostureDiffConnection, 0, len(connections))
+	for connectionID, connection := range connections {
+		for _, severity := range severities[connectionID] {
+			for _, items := range [][]api.PostureDiffItem{severity.NewlyFailing, severity.NewlyPassing, severity.Disappeared} {
+				sort.Slice(items, func(i, j int) bool {
+					if items[i].ControlID != items[j].ControlID {
+						return items[i].ControlID < items[j].ControlID
+					}
+					return items[i].KaytuResourceID < items[j].KaytuResourceID
+				})
+			}
+			connection.Severities = append(connection.Severities, *severity)
+		}
+		sort.Slice(connection.Severities, func(i, j int) bool {
+			return connection.Severities[i].Severity.Level() > connection.Severities[j].Severity.Level()
+		})
+		result = append(result, *connection)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ConnectionID < result[j].ConnectionID
+	})
+	return total, result
+}
+
+// getPostureDiff folds the finding events of the range into per finding changes and groups them by connection and severity
+func (h *HttpHandler) getPostureDiff(ctx context.Context, clientCtx *httpclient.Context, benchmarkID string, connectionIDs []string,
+	diffRange postureDiffRange) (*api.GetPostureDiffResponse, error) {
+	paginator, err := es.NewPostureDiffFindingEventPaginator(h.client, benchmarkID, connectionIDs,
+		diffRange.BaseJobID, diffRange.TargetJobID, diffRange.BaseTime, diffRange.TargetTime)
+	if err != nil {
+		h.logger.Error("failed to create finding events paginator", zap.Error(err))
+		return nil, err
+	}
+	defer func() {
+		if err := paginator.Close(ctx); err != nil {
+			h.logger.Error("failed to close finding events paginator", zap.Error(err))
+		}
+	}()
+
+	fold := newPostureDiffFold()
+	for paginator.HasNext() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			h.logger.Error("failed to get finding events page", zap.Error(err))
+			return nil, err
+		}
+		for _, event := range page {
+			fold.add(event)
+		}
+	}
+
+	changes := fold.changes()
+	var controlIDs, kaytuResourceIDs []string
+	for findingID := range changes {
+		last := fold.lastEvents[findingID]
+		controlIDs = append(controlIDs, last.ControlID)
+		kaytuResourceIDs = append(kaytuResourceIDs, last.KaytuResourceID)
+	}
+
+	controlTitles, err := h.db.GetControlsTitle(ctx, controlIDs)
+	if err != nil {
+		h.logger.Error("failed to get controls title", zap.Error(err))
+		return nil, err
+	}
+	resourceFindings, err := es.FetchResourceFindingsByKaytuResourceIDs(ctx, h.client, kaytuResourceIDs)
+	if err != nil {
+		h.logger.Error("failed to fetch resource findings", zap.Error(err))
+		return nil, err
+	}
+	allSources, err := h.onboardClient.ListSources(clientCtx, nil)
+	if err != nil {
+		h.logger.Error("failed to get sources", zap.Error(err))
+		return nil, err
+	}
+	allConnectionsMap := make(map[string]*onboardApi.Connection)
+	for _, src := range allSources {
+		src := src
+		allConnectionsMap[src.ID.String()] = &src
+	}
+	resourceTypeMetadata, err := h.inventoryClient.ListResourceTypesMetadata(clientCtx,
+		nil, nil, nil, false, nil, 10000, 1)
+	if err != nil {
+		h.logger.Error("failed to get resource type metadata", zap.Error(err))
+		return nil, err
+	}
+	resourceTypeMetadataMap := make(map[string]*inventoryApi.ResourceType)
+	for _, item := range resourceTypeMetadata.ResourceTypes {
+		item := item
+		resourceTypeMetadataMap[strings.ToLower(item.ResourceType)] = &item
+	}
+
+	entries := make([]postureDiffEntry, 0, len(changes))
+	for findingID, change := range changes {
+		first, last := fold.firstEvents[findingID], fold.lastEvents[findingID]
+		item := api.PostureDiffItem{
+			FindingID:         findingID,
+			ControlID:         last.ControlID,
+			ControlTitle:      controlTitles[last.ControlID],
+			KaytuResourceID:   last.KaytuResourceID,
+			ResourceID:        last.ResourceID,
+			ResourceType:      last.ResourceType,
+			ConformanceStatus: last.ConformanceStatus,
+			Reason:            last.Reason,
+			EvaluatedAt:       time.UnixMilli(last.EvaluatedAt),
+		}
+		if first.PreviousStateActive {
+			item.PreviousConformanceStatus = first.PreviousConformanceStatus
+		}
+		if rtMetadata, ok := resourceTypeMetadataMap[strings.ToLower(last.ResourceType)]; ok {
+			item.ResourceTypeName = rtMetadata.ResourceLabel
+		}
+		for _, resourceFinding := range resourceFindings[last.KaytuResourceID] {
+			if strings.ToLower(resourceFinding.ResourceType) == strings.ToLower(last.ResourceType) {
+				item.ResourceName = resourceFinding.ResourceName
+				item.ResourceLocation = resourceFinding.ResourceLocation
+				break
+			}
+		}
+
+		connection := api.PostureDiffConnection{
+			ConnectionID: last.ConnectionID,
+			Connector:    last.Connector,
+		}
+		if src, ok := allConnectionsMap[last.ConnectionID]; ok {
+			connection.ProviderConnectionID = src.ConnectionID
+			connection.ProviderConnectionName = src.ConnectionName
+		}
+		entries = append(entries, postureDiffEntry{
+			change:     change,
+			connection: connection,
+			severity:   last.Severity,
+			item:       item,
+		})
+	}
+
+	response := api.GetPostureDiffResponse{
+		BenchmarkID:           benchmarkID,
+		BaseComplianceJobID:   diffRange.BaseJobID,
+		TargetComplianceJobID: diffRange.TargetJobID,
+		BaseTime:              diffRange.BaseTime,
+		TargetTime:            diffRange.TargetTime,
+	}
+	response.Counts, response.Connections = groupPostureDiff(entries)
+
+	return &response, nil
+}
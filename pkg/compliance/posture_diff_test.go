@@ -0,0 +1,184 @@
+package compliance
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/kaytu-io/open-governance/pkg/utils"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostureDiffChangeOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  types.FindingEvent
+		last   types.FindingEvent
+		want   api.PostureDiffChange
+		wantOk bool
+	}{
+		{
+			name:   "new failing finding",
+			first:  types.FindingEvent{ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+			last:   types.FindingEvent{ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+			want:   api.PostureDiffChangeNewlyFailing,
+			wantOk: true,
+		},
+		{
+			name:   "passing finding starts failing",
+			first:  types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusERROR, StateActive: true},
+			last:   types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusERROR, StateActive: true},
+			want:   api.PostureDiffChangeNewlyFailing,
+			wantOk: true,
+		},
+		{
+			name:   "failing finding passes",
+			first:  types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+			last:   types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+			want:   api.PostureDiffChangeNewlyPassing,
+			wantOk: true,
+		},
+		{
+			name:   "evaluated resource disappears",
+			first:  types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusOK, StateActive: false},
+			last:   types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusOK, StateActive: false},
+			want:   api.PostureDiffChangeDisappeared,
+			wantOk: true,
+		},
+		{
+			name:  "resource appearing and disappearing in the range is not a change",
+			first: types.FindingEvent{ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+			last:  types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusALARM, StateActive: false},
+		},
+		{
+			name:  "flapping back to failing is not a change",
+			first: types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+			last:  types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+		},
+		{
+			name:  "flapping back to passing is not a change",
+			first: types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+			last:  types.FindingEvent{PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusSKIP, StateActive: true},
+		},
+		{
+			name:  "new passing finding is not a change",
+			first: types.FindingEvent{ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+			last:  types.FindingEvent{ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			change, ok := postureDiffChangeOf(tc.first, tc.last)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.want, change)
+		})
+	}
+}
+
+func TestPostureDiffFold(t *testing.T) {
+	fold := newPostureDiffFold()
+	events := []types.FindingEvent{
+		// f1 passes, then fails
+		{FindingEsID: "f1", PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+		{FindingEsID: "f2", ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+		{FindingEsID: "f1", PreviousConformanceStatus: types.ConformanceStatusOK, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusALARM, StateActive: true},
+		{FindingEsID: "f3", PreviousConformanceStatus: types.ConformanceStatusALARM, PreviousStateActive: true, ConformanceStatus: types.ConformanceStatusOK, StateActive: true},
+	}
+	for _, event := range events {
+		fold.add(event)
+	}
+
+	assert.Equal(t, map[string]api.PostureDiffChange{
+		"f2": api.PostureDiffChangeNewlyFailing,
+		"f3": api.PostureDiffChangeNewlyPassing,
+	}, fold.changes())
+	assert.Equal(t, events[0], fold.firstEvents["f1"])
+	assert.Equal(t, events[2], fold.lastEvents["f1"])
+}
+
+func TestGroupPostureDiff(t *testing.T) {
+	conn1 := api.PostureDiffConnection{ConnectionID: "conn-1", ProviderConnectionName: "production"}
+	conn2 := api.PostureDiffConnection{ConnectionID: "conn-2"}
+	entries := []postureDiffEntry{
+		{change: api.PostureDiffChangeNewlyFailing, connection: conn2, severity: types.FindingSeverityLow, item: api.PostureDiffItem{ControlID: "c1", KaytuResourceID: "r1"}},
+		{change: api.PostureDiffChangeNewlyFailing, connection: conn1, severity: types.FindingSeverityLow, item: api.PostureDiffItem{ControlID: "c2", KaytuResourceID: "r1"}},
+		{change: api.PostureDiffChangeNewlyFailing, connection: conn1, severity: types.FindingSeverityLow, item: api.PostureDiffItem{ControlID: "c1", KaytuResourceID: "r2"}},
+		{change: api.PostureDiffChangeNewlyFailing, connection: conn1, severity: types.FindingSeverityLow, item: api.PostureDiffItem{ControlID: "c1", KaytuResourceID: "r1"}},
+		{change: api.PostureDiffChangeNewlyPassing, connection: conn1, severity: types.FindingSeverityCritical, item: api.PostureDiffItem{ControlID: "c3", KaytuResourceID: "r1"}},
+		{change: api.PostureDiffChangeDisappeared, connection: conn1, severity: types.FindingSeverityHigh, item: api.PostureDiffItem{ControlID: "c4", KaytuResourceID: "r3"}},
+	}
+
+	total, connections := groupPostureDiff(entries)
+	assert.Equal(t, api.PostureDiffCounts{NewlyFailing: 4, NewlyPassing: 1, Disappeared: 1}, total)
+	require.Len(t, connections, 2)
+
+	first := connections[0]
+	assert.Equal(t, "conn-1", first.ConnectionID)
+	assert.Equal(t, "production", first.ProviderConnectionName)
+	assert.Equal(t, api.PostureDiffCounts{NewlyFailing: 3, NewlyPassing: 1, Disappeared: 1}, first.Counts)
+	require.Len(t, first.Severities, 3)
+	assert.Equal(t, types.FindingSeverityCritical, first.Severities[0].Severity)
+	assert.Equal(t, types.FindingSeverityHigh, first.Severities[1].Severity)
+	assert.Equal(t, types.FindingSeverityLow, first.Severities[2].Severity)
+
+	low := first.Severities[2]
+	assert.Equal(t, api.PostureDiffCounts{NewlyFailing: 3}, low.Counts)
+	assert.Equal(t, []api.PostureDiffItem{
+		{ControlID: "c1", KaytuResourceID: "r1"},
+		{ControlID: "c1", KaytuResourceID: "r2"},
+		{ControlID: "c2", KaytuResourceID: "r1"},
+	}, low.NewlyFailing)
+	assert.Empty(t, low.NewlyPassing)
+	assert.NotNil(t, low.NewlyPassing, "empty lists are serialized as []")
+	assert.Equal(t, []api.PostureDiffItem{{ControlID: "c3", KaytuResourceID: "r1"}}, first.Severities[0].NewlyPassing)
+	assert.Equal(t, []api.PostureDiffItem{{ControlID: "c4", KaytuResourceID: "r3"}}, first.Severities[1].Disappeared)
+
+	assert.Equal(t, "conn-2", connections[1].ConnectionID)
+	assert.Equal(t, api.PostureDiffCounts{NewlyFailing: 1}, connections[1].Counts)
+}
+
+func TestGroupPostureDiff_Empty(t *testing.T) {
+	total, connections := groupPostureDiff(nil)
+	assert.Equal(t, api.PostureDiffCounts{}, total)
+	assert.NotNil(t, connections)
+	assert.Empty(t, connections)
+}
+
+func TestParsePostureDiffRange(t *testing.T) {
+	now := time.Unix(2000, 0)
+	tests := []struct {
+		name                                 string
+		baseJobID, targetJobID, base, target string
+		want                                 postureDiffRange
+		wantErr                              bool
+	}{
+		{name: "jobs", baseJobID: "1", targetJobID: "2", want: postureDiffRange{BaseJobID: utils.GetPointer(uint(1)), TargetJobID: utils.GetPointer(uint(2))}},
+		{name: "jobs win over times", baseJobID: "1", targetJobID: "2", base: "100", want: postureDiffRange{BaseJobID: utils.GetPointer(uint(1)), TargetJobID: utils.GetPointer(uint(2))}},
+		{name: "missing target job", baseJobID: "1", wantErr: true},
+		{name: "missing base job", targetJobID: "2", wantErr: true},
+		{name: "invalid job", baseJobID: "one", targetJobID: "2", wantErr: true},
+		{name: "base job is not older", baseJobID: "2", targetJobID: "2", wantErr: true},
+		{name: "times", base: "100", target: "200", want: postureDiffRange{BaseTime: utils.GetPointer(time.Unix(100, 0)), TargetTime: utils.GetPointer(time.Unix(200, 0))}},
+		{name: "target time defaults to now", base: "100", want: postureDiffRange{BaseTime: utils.GetPointer(time.Unix(100, 0)), TargetTime: &now}},
+		{name: "nothing", wantErr: true},
+		{name: "invalid time", base: "yesterday", wantErr: true},
+		{name: "base time is not before target", base: "200", target: "100", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parsePostureDiffRange(tc.baseJobID, tc.targetJobID, tc.base, tc.target, now)
+			if tc.wantErr {
+				var httpErr *echo.HTTPError
+				require.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
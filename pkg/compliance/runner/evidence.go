@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/kaytu-io/kaytu-util/pkg/es"
+	es2 "github.com/kaytu-io/open-governance/pkg/compliance/es"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"go.uber.org/zap"
+)
+
+const evidenceFetchBatchSize = 500
+
+// captureEvidence snapshots the resources the query evaluated so the findings can be audited later
+func (w *Worker) captureEvidence(ctx context.Context, j Job, findings []types.Finding) ([]es.Doc, error) {
+	idsByType := make(map[string][]string)
+	for _, f := range findings {
+		if f.KaytuResourceID == "" || f.ResourceType == "" {
+			continue
+		}
+		idsByType[f.ResourceType] = append(idsByType[f.ResourceType], f.KaytuResourceID)
+	}
+
+	resources := make(map[string]es.Resource)
+	for resourceType, ids := range idsByType {
+		for start := 0; start < len(ids); start += evidenceFetchBatchSize {
+			end := start + evidenceFetchBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			res, err := es2.FetchResourcesByResourceIdsAndType(ctx, w.esClient, ids[start:end], resourceType)
+			if err != nil {
+				w.logger.Error("failed to fetch evidence resources", zap.Error(err), zap.String("resource_type", resourceType))
+				return nil, err
+			}
+			for id, r := range res {
+				resources[id] = r
+			}
+		}
+	}
+
+	var docs []es.Doc
+	for _, f := range findings {
+		resource, ok := resources[f.KaytuResourceID]
+		if !ok {
+			w.logger.Info("evidence resource not found", zap.String("kaytu_resource_id", f.KaytuResourceID), zap.Uint("job_id", j.ID))
+			continue
+		}
+		resource.EsID = ""
+		resource.EsIndex = ""
+		body, err := json.Marshal(resource)
+		if err != nil {
+			return nil, err
+		}
+		checksum := sha256.Sum256(body)
+
+		evidence := types.ComplianceEvidence{
+			FindingEsID:           f.EsID,
+			ParentComplianceJobID: j.ParentJobID,
+			ComplianceJobID:       j.ID,
+			BenchmarkID:           f.BenchmarkID,
+			ControlID:             f.ControlID,
+			QueryID:               j.ExecutionPlan.Query.ID,
+			ConnectionID:          f.ConnectionID,
+			Connector:             f.Connector,
+			KaytuResourceID:       f.KaytuResourceID,
+			ResourceID:            f.ResourceID,
+			ResourceType:          f.ResourceType,
+			ConformanceStatus:     f.ConformanceStatus,
+			Reason:                f.Reason,
+			EvaluatedAt:           j.CreatedAt.UnixMilli(),
+			Resource:              string(body),
+			Checksum:              hex.EncodeToString(checksum[:]),
+		}
+		keys, idx := evidence.KeysAndIndex()
+		evidence.EsID = es.HashOf(keys...)
+		evidence.EsIndex = idx
+		docs = append(docs, evidence)
+	}
+	return docs, nil
+}
@@ -26,6 +26,7 @@ import (
 type Caller struct {
 	RootBenchmark      string
 	TracksDriftEvents  bool
+	CapturesEvidence   bool
 	ParentBenchmarkIDs []string
 	ControlID          string
 	ControlSeverity    types.FindingSeverity
@@ -116,7 +117,7 @@ func (w *Worker) RunJob(ctx context.Context, j Job) (int, error) {
 				zap.Stringp("connection_id", j.ExecutionPlan.ConnectionID),
 				zap.Uint("job_id", j.ID),
 			)
-			return 0, fmt.Errorf("required query parameter not found: %s for query: %s", param.Key, j.ExecutionPlan.Query.ID)
+			return 0, fmt.Errorf("%s: %s for query: %s", missingParameterMessage, param.Key, j.ExecutionPlan.Query.ID)
 		}
 		if _, ok := queryParamMap[param.Key]; !ok && !param.Required {
 			w.logger.Info("optional query parameter not found",
@@ -301,6 +302,8 @@ func (w *Worker) RunJob(ctx context.Context, j Job) (int, error) {
 				newFinding.ComplianceJobID = j.ID
 				newFinding.ParentComplianceJobID = j.ParentJobID
 			}
+			// owner is resolved by the summarizer, keep it until the next summary
+			newFinding.Owner = f.Owner
 
 			newFindings = append(newFindings, newFinding)
 			delete(findingsMap, f.EsID)
@@ -358,6 +361,16 @@ func (w *Worker) RunJob(ctx context.Context, j Job) (int, error) {
 		f.EsIndex = idx
 		docs = append(docs, f)
 	}
+	for _, c := range j.ExecutionPlan.Callers {
+		if c.CapturesEvidence {
+			evidenceDocs, err := w.captureEvidence(ctx, j, findings)
+			if err != nil {
+				return 0, err
+			}
+			docs = append(docs, evidenceDocs...)
+			break
+		}
+	}
 	mapKey := strings.Builder{}
 	mapKey.WriteString(j.ExecutionPlan.Callers[0].RootBenchmark)
 	mapKey.WriteString("$$")
@@ -1,6 +1,9 @@
 package runner
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type ComplianceRunnerStatus string
 
@@ -14,6 +17,17 @@ const (
 	ComplianceRunnerCanceled   ComplianceRunnerStatus = "CANCELED"
 )
 
+type FailureCategory string
+
+const (
+	FailureCategoryMissingParameter FailureCategory = "MISSING_PARAMETER"
+	FailureCategorySQLError         FailureCategory = "SQL_ERROR"
+	FailureCategoryTimeout          FailureCategory = "TIMEOUT"
+	FailureCategoryOther            FailureCategory = "OTHER"
+)
+
+const missingParameterMessage = "required query parameter not found"
+
 type JobResult struct {
 	Job               Job
 	StartedAt         time.Time
@@ -21,3 +35,24 @@ type JobResult struct {
 	Error             string
 	TotalFindingCount *int
 }
+
+// CategorizeFailure tells why a runner failed from its status and failure message
+func CategorizeFailure(status ComplianceRunnerStatus, failureMessage string) FailureCategory {
+	msg := strings.ToLower(failureMessage)
+	switch {
+	case status == ComplianceRunnerTimeOut,
+		strings.Contains(msg, "timed out"),
+		strings.Contains(msg, "deadline exceeded"),
+		strings.Contains(msg, "canceling statement due to statement timeout"):
+		return FailureCategoryTimeout
+	case strings.Contains(msg, missingParameterMessage):
+		return FailureCategoryMissingParameter
+	case strings.Contains(msg, "sqlstate"),
+		strings.Contains(msg, "syntax error"),
+		strings.Contains(msg, "query template"),
+		strings.Contains(msg, "does not exist"):
+		return FailureCategorySQLError
+	default:
+		return FailureCategoryOther
+	}
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorizeFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  ComplianceRunnerStatus
+		message string
+		want    FailureCategory
+	}{
+		{name: "timed out runner", status: ComplianceRunnerTimeOut, message: "", want: FailureCategoryTimeout},
+		{name: "timed out runner with sql message", status: ComplianceRunnerTimeOut, message: "ERROR: syntax error at or near \"FROM\" (SQLSTATE 42601)", want: FailureCategoryTimeout},
+		{name: "context deadline", status: ComplianceRunnerFailed, message: "context deadline exceeded", want: FailureCategoryTimeout},
+		{name: "job timed out", status: ComplianceRunnerFailed, message: "Job timed out", want: FailureCategoryTimeout},
+		{name: "statement timeout", status: ComplianceRunnerFailed, message: "ERROR: canceling statement due to statement timeout (SQLSTATE 57014)", want: FailureCategoryTimeout},
+		{name: "missing parameter", status: ComplianceRunnerFailed, message: fmt.Sprintf("%s: awsAccessKeyMaxAge for query: aws_iam_1", missingParameterMessage), want: FailureCategoryMissingParameter},
+		{name: "missing parameter is case insensitive", status: ComplianceRunnerFailed, message: "Required Query Parameter Not Found: x", want: FailureCategoryMissingParameter},
+		{name: "sql state", status: ComplianceRunnerFailed, message: "ERROR: division by zero (SQLSTATE 22012)", want: FailureCategorySQLError},
+		{name: "syntax error", status: ComplianceRunnerFailed, message: "syntax error at end of input", want: FailureCategorySQLError},
+		{name: "missing table", status: ComplianceRunnerFailed, message: "relation \"aws_unknown\" does not exist", want: FailureCategorySQLError},
+		{name: "query template", status: ComplianceRunnerFailed, message: "failed to parse query template", want: FailureCategorySQLError},
+		{name: "other", status: ComplianceRunnerFailed, message: "connection refused", want: FailureCategoryOther},
+		{name: "no message", status: ComplianceRunnerFailed, message: "", want: FailureCategoryOther},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, CategorizeFailure(tc.status, tc.message))
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/steampipe"
 	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
 	complianceClient "github.com/kaytu-io/open-governance/pkg/compliance/client"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	inventoryClient "github.com/kaytu-io/open-governance/pkg/inventory/client"
 	metadataClient "github.com/kaytu-io/open-governance/pkg/metadata/client"
 	onboardClient "github.com/kaytu-io/open-governance/pkg/onboard/client"
@@ -43,6 +44,7 @@ type Worker struct {
 	steampipeConn    *steampipe.Database
 	esClient         kaytu.Client
 	jq               *jq.JobQueue
+	dlq              *dlq.DeadLetterQueue
 	complianceClient complianceClient.ComplianceServiceClient
 	onboardClient    onboardClient.OnboardServiceClient
 	inventoryClient  inventoryClient.InventoryServiceClient
@@ -109,12 +111,18 @@ func NewWorker(
 		return nil, err
 	}
 
+	dlq, err := dlq.New(config.NATS.URL, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	w := &Worker{
 		config:           config,
 		logger:           logger,
 		steampipeConn:    steampipeConn,
 		esClient:         esClient,
 		jq:               jq,
+		dlq:              dlq,
 		complianceClient: complianceClient.NewComplianceClient(config.Compliance.BaseURL),
 		onboardClient:    onboardClient.NewOnboardServiceClient(config.Onboard.BaseURL),
 		inventoryClient:  inventoryClient.NewInventoryServiceClient(config.Inventory.BaseURL),
@@ -172,12 +180,17 @@ func (w *Worker) Run(ctx context.Context) error {
 				}
 			}()
 
-			_, _, err := w.ProcessMessage(ctx, msg)
+			commit, _, err := w.ProcessMessage(ctx, msg)
 			if err != nil {
 				w.logger.Error("failed to process message", zap.Error(err))
 			}
 			ticker.Stop()
 
+			if !commit {
+				w.dlq.Fail(ctx, consumer, msg, err, 1)
+				return
+			}
+
 			if err := msg.Ack(); err != nil {
 				w.logger.Error("failed to send the ack message", zap.Error(err), zap.Any("msg", msg))
 			}
@@ -214,7 +227,7 @@ func (w *Worker) ProcessMessage(ctx context.Context, msg jetstream.Msg) (commit
 	var job Job
 
 	if err := json.Unmarshal(msg.Data(), &job); err != nil {
-		return true, false, err
+		return false, false, err
 	}
 
 	result := JobResult{
@@ -0,0 +1,67 @@
+package compliance
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"go.uber.org/zap"
+)
+
+// getSecurityScoreWeights returns the workspace severity weights, falling back to the defaults for severities that are not configured,
+// along with the control overrides of the benchmark if one is given
+func (h *HttpHandler) getSecurityScoreWeights(ctx context.Context, benchmarkID string) (*api.GetSecurityScoreWeightsResponse, error) {
+	weights, err := h.db.ListSeverityScoreWeights(ctx)
+	if err != nil {
+		h.logger.Error("failed to list severity score weights", zap.Error(err))
+		return nil, err
+	}
+	severityWeights := make(map[string]float64)
+	for _, w := range weights {
+		severityWeights[string(w.Severity)] = w.Weight
+	}
+
+	response := api.GetSecurityScoreWeightsResponse{
+		Severities: make([]api.SeverityScoreWeight, 0, len(api.FindingSLASeverities)),
+		Controls:   make([]api.ControlScoreWeight, 0),
+	}
+	for _, severity := range api.FindingSLASeverities {
+		weight, ok := severityWeights[string(severity)]
+		if !ok {
+			weight = api.DefaultSeverityScoreWeights[severity]
+		}
+		response.Severities = append(response.Severities, api.SeverityScoreWeight{
+			Severity: severity,
+			Weight:   weight,
+		})
+	}
+
+	if benchmarkID == "" {
+		return &response, nil
+	}
+	controlWeights, err := h.db.ListBenchmarkControlWeights(ctx, benchmarkID)
+	if err != nil {
+		h.logger.Error("failed to list benchmark control weights", zap.Error(err))
+		return nil, err
+	}
+	for _, w := range controlWeights {
+		response.Controls = append(response.Controls, api.ControlScoreWeight{
+			ControlID: w.ControlID,
+			Weight:    w.Weight,
+		})
+	}
+	return &response, nil
+}
+
+// controlWeightsOutsideBenchmark returns the sorted controls given a weight that are not part of the benchmark,
+// removing an override with a null weight is allowed for any control so stale overrides can be cleaned up
+func controlWeightsOutsideBenchmark(weights map[string]*float64, benchmarkControls map[string]bool) []string {
+	var invalid []string
+	for controlID, weight := range weights {
+		if weight != nil && !benchmarkControls[controlID] {
+			invalid = append(invalid, controlID)
+		}
+	}
+	sort.Strings(invalid)
+	return invalid
+}
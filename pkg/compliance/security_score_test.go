@@ -0,0 +1,28 @@
+package compliance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControlWeightsOutsideBenchmark(t *testing.T) {
+	weight := func(w float64) *float64 { return &w }
+	benchmarkControls := map[string]bool{"aws_cis_1_4": true, "aws_cis_1_5": true}
+
+	tests := []struct {
+		name    string
+		weights map[string]*float64
+		want    []string
+	}{
+		{name: "controls of the benchmark", weights: map[string]*float64{"aws_cis_1_4": weight(10), "aws_cis_1_5": weight(0)}, want: nil},
+		{name: "control of another benchmark", weights: map[string]*float64{"aws_cis_1_4": weight(10), "azure_cis_1": weight(3)}, want: []string{"azure_cis_1"}},
+		{name: "removing an override is always allowed", weights: map[string]*float64{"removed_control": nil}, want: nil},
+		{name: "sorted", weights: map[string]*float64{"b": weight(1), "a": weight(1)}, want: []string{"a", "b"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, controlWeightsOutsideBenchmark(tc.weights, benchmarkControls))
+		})
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
 	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
 	"strings"
+	"time"
 
 	es2 "github.com/kaytu-io/kaytu-util/pkg/es"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
 	"github.com/kaytu-io/open-governance/pkg/compliance/es"
 	types2 "github.com/kaytu-io/open-governance/pkg/compliance/summarizer/types"
 	es3 "github.com/kaytu-io/open-governance/pkg/describe/es"
@@ -23,13 +25,30 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 	w.logger.Info("Running summarizer",
 		zap.Uint("job_id", j.ID),
 		zap.String("benchmark_id", j.BenchmarkID),
+		zap.Bool("incremental", j.Incremental),
 	)
 
+	filters := []kaytu.BoolFilter{
+		kaytu.NewTermFilter("stateActive", "true"),
+	}
+	var previousSummary *types2.BenchmarkSummary
+	if j.Incremental {
+		summaries, err := es.ListBenchmarkSummariesAtTime(ctx, w.logger, w.esClient, []string{j.BenchmarkID}, nil, nil, time.Now(), true)
+		if err != nil {
+			w.logger.Error("failed to fetch previous benchmark summary", zap.Error(err))
+			return err
+		}
+		if summary, ok := summaries[j.BenchmarkID]; ok {
+			previousSummary = &summary
+			filters = append(filters, kaytu.NewTermsFilter("connectionID", j.ConnectionIDs))
+		} else {
+			w.logger.Info("no previous benchmark summary to merge into, summarizing all connections", zap.String("benchmark_id", j.BenchmarkID))
+		}
+	}
+
 	// We have to sort by kaytuResourceID to be able to optimize memory usage for resourceFinding generations
 	// this way as soon as paginator switches to next resource we can send the previous resource to the queue and free up memory
-	paginator, err := es.NewFindingPaginator(w.esClient, types.FindingsIndex, []kaytu.BoolFilter{
-		kaytu.NewTermFilter("stateActive", "true"),
-	}, nil, []map[string]any{
+	paginator, err := es.NewFindingPaginator(w.esClient, types.FindingsIndex, filters, nil, []map[string]any{
 		{"kaytuResourceID": "asc"},
 		{"resourceType": "asc"},
 	})
@@ -91,6 +110,28 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 		jd.ConnectionCache[strings.ToLower(c.ConnectionID)] = c
 	}
 
+	findingExceptions, err := w.complianceClient.ListActiveFindingExceptions(&httpclient.Context{Ctx: ctx, UserRole: api.InternalRole})
+	if err != nil {
+		w.logger.Error("failed to list finding exceptions", zap.Error(err))
+		return err
+	}
+	jd.FindingExceptions = findingExceptions
+
+	ownershipRules, err := w.complianceClient.ListOwnershipRules(&httpclient.Context{Ctx: ctx, UserRole: api.InternalRole})
+	if err != nil {
+		w.logger.Error("failed to list ownership rules", zap.Error(err))
+		return err
+	}
+	complianceApi.SortOwnershipRules(ownershipRules)
+	jd.OwnershipRules = ownershipRules
+
+	scoreWeights, err := w.complianceClient.GetSecurityScoreWeights(&httpclient.Context{Ctx: ctx, UserRole: api.InternalRole}, j.BenchmarkID)
+	if err != nil {
+		w.logger.Error("failed to get security score weights", zap.Error(err))
+		return err
+	}
+	jd.ScoreWeights = scoreWeights.Weights()
+
 	for page := 1; paginator.HasNext(); page++ {
 		w.logger.Info("Next page", zap.Int("page", page))
 		page, err := paginator.NextPage(ctx)
@@ -126,6 +167,14 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 		}
 
 		var docs []es2.Doc
+		// findings are re-ingested when their owner changed so they can be filtered by owner
+		for _, f := range jd.OwnerChangedFindings {
+			keys, idx := f.KeysAndIndex()
+			f.EsID = es2.HashOf(keys...)
+			f.EsIndex = idx
+			docs = append(docs, f)
+		}
+		jd.OwnerChangedFindings = nil
 		for resourceIdType, isReady := range jd.ResourcesFindingsIsDone {
 			if !isReady {
 				continue
@@ -157,6 +206,9 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 	)
 
 	jd.Summarize(w.logger)
+	if previousSummary != nil {
+		jd.BenchmarkSummary.Merge(*previousSummary, j.ConnectionIDs)
+	}
 
 	w.logger.Info("Summarize done", zap.Any("summary", jd))
 
@@ -179,9 +231,13 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 		return err
 	}
 
-	// Delete old resource findings
+	// Delete old resource findings, incremental jobs only see the resources of their connections
 	if len(resourceIds) > 0 {
-		err = w.deleteOldResourceFindings(ctx, j, resourceIds)
+		var connectionIDs []string
+		if previousSummary != nil {
+			connectionIDs = j.ConnectionIDs
+		}
+		err = w.deleteOldResourceFindings(ctx, j, resourceIds, connectionIDs)
 		if err != nil {
 			w.logger.Error("failed to delete old resource findings", zap.Error(err))
 			return err
@@ -196,11 +252,16 @@ func (w *Worker) RunJob(ctx context.Context, j types2.Job) error {
 	return nil
 }
 
-func (w *Worker) deleteOldResourceFindings(ctx context.Context, j types2.Job, currentResourceIds []string) error {
+// deleteOldResourceFindings deletes the resource findings of earlier jobs which were not found again,
+// only the resource findings of the given connections are deleted if any is given
+func (w *Worker) deleteOldResourceFindings(ctx context.Context, j types2.Job, currentResourceIds []string, connectionIDs []string) error {
 	// Delete old resource findings
-	filters := make([]kaytu.BoolFilter, 0, 2)
+	filters := make([]kaytu.BoolFilter, 0, 3)
 	filters = append(filters, kaytu.NewBoolMustNotFilter(kaytu.NewTermsFilter("kaytuResourceID", currentResourceIds)))
 	filters = append(filters, kaytu.NewRangeFilter("jobId", "", "", fmt.Sprintf("%d", j.ID), ""))
+	if len(connectionIDs) > 0 {
+		filters = append(filters, kaytu.NewTermsFilter("findings.connectionID", connectionIDs))
+	}
 
 	root := map[string]any{
 		"query": map[string]any{
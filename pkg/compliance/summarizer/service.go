@@ -12,7 +12,9 @@ import (
 	esSinkClient "github.com/kaytu-io/kaytu-util/pkg/es/ingest/client"
 	"github.com/kaytu-io/kaytu-util/pkg/jq"
 	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	complianceClient "github.com/kaytu-io/open-governance/pkg/compliance/client"
 	"github.com/kaytu-io/open-governance/pkg/compliance/summarizer/types"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	inventoryClient "github.com/kaytu-io/open-governance/pkg/inventory/client"
 	onboardClient "github.com/kaytu-io/open-governance/pkg/onboard/client"
 	"github.com/nats-io/nats.go/jetstream"
@@ -23,6 +25,7 @@ type Config struct {
 	ElasticSearch         config.ElasticSearch
 	NATS                  config.NATS
 	PrometheusPushAddress string
+	Compliance            config.KaytuService
 	Inventory             config.KaytuService
 	Onboard               config.KaytuService
 	EsSink                config.KaytuService
@@ -33,10 +36,12 @@ type Worker struct {
 	logger   *zap.Logger
 	esClient kaytu.Client
 	jq       *jq.JobQueue
+	dlq      *dlq.DeadLetterQueue
 
-	inventoryClient inventoryClient.InventoryServiceClient
-	onboardClient   onboardClient.OnboardServiceClient
-	esSinkClient    esSinkClient.EsSinkServiceClient
+	complianceClient complianceClient.ComplianceServiceClient
+	inventoryClient  inventoryClient.InventoryServiceClient
+	onboardClient    onboardClient.OnboardServiceClient
+	esSinkClient     esSinkClient.EsSinkServiceClient
 }
 
 var (
@@ -76,14 +81,21 @@ func NewWorker(
 		return nil, err
 	}
 
+	dlq, err := dlq.New(config.NATS.URL, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	w := &Worker{
-		config:          config,
-		logger:          logger,
-		esClient:        esClient,
-		jq:              jq,
-		inventoryClient: inventoryClient.NewInventoryServiceClient(config.Inventory.BaseURL),
-		onboardClient:   onboardClient.NewOnboardServiceClient(config.Onboard.BaseURL),
-		esSinkClient:    esSinkClient.NewEsSinkServiceClient(logger, config.EsSink.BaseURL),
+		config:           config,
+		logger:           logger,
+		esClient:         esClient,
+		jq:               jq,
+		dlq:              dlq,
+		complianceClient: complianceClient.NewComplianceClient(config.Compliance.BaseURL),
+		inventoryClient:  inventoryClient.NewInventoryServiceClient(config.Inventory.BaseURL),
+		onboardClient:    onboardClient.NewOnboardServiceClient(config.Onboard.BaseURL),
+		esSinkClient:     esSinkClient.NewEsSinkServiceClient(logger, config.EsSink.BaseURL),
 	}
 
 	return w, nil
@@ -106,10 +118,15 @@ func (w *Worker) Run(ctx context.Context) error {
 	consumeCtx, err := w.jq.Consume(ctx, service, StreamName, []string{queueTopic}, consumer, func(msg jetstream.Msg) {
 		w.logger.Info("received a new job")
 
-		if err := w.ProcessMessage(ctx, msg); err != nil {
+		commit, err := w.ProcessMessage(ctx, msg)
+		if err != nil {
 			w.logger.Error("failed to process message", zap.Error(err))
 		}
-		err := msg.Ack()
+		if !commit {
+			w.dlq.Fail(ctx, consumer, msg, err, 1)
+			return
+		}
+		err = msg.Ack()
 		if err != nil {
 			w.logger.Error("failed to ack message", zap.Error(err))
 		}
@@ -129,13 +146,14 @@ func (w *Worker) Run(ctx context.Context) error {
 	return nil
 }
 
-func (w *Worker) ProcessMessage(ctx context.Context, msg jetstream.Msg) error {
+// ProcessMessage runs the job of the message, commit is false when the message is not a valid job
+func (w *Worker) ProcessMessage(ctx context.Context, msg jetstream.Msg) (commit bool, err error) {
 	startTime := time.Now()
 
 	var job types.Job
-	err := json.Unmarshal(msg.Data(), &job)
+	err = json.Unmarshal(msg.Data(), &job)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	defer func() {
@@ -169,10 +187,10 @@ func (w *Worker) ProcessMessage(ctx context.Context, msg jetstream.Msg) error {
 	err = w.RunJob(ctx, job)
 	if err != nil {
 		w.logger.Info("failure while running job", zap.Error(err))
-		return err
+		return true, err
 	}
 
-	return nil
+	return true, nil
 }
 
 func (w *Worker) Stop() error {
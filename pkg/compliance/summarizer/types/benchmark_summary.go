@@ -13,6 +13,61 @@ type Result struct {
 	SeverityResult   map[types.FindingSeverity]int
 	SecurityScore    float64
 	CostOptimization *float64 `json:"CostOptimization,omitempty"`
+	// ExceptedResult counts failed findings covered by an active finding exception per severity,
+	// these are left out of QueryResult and SeverityResult
+	ExceptedResult map[types.FindingSeverity]int `json:"ExceptedResult,omitempty"`
+	// Weighted sums the score weight of the findings so a critical control counts more than a low one,
+	// WeightedSecurityScore is the weighted counterpart of SecurityScore
+	Weighted              WeightedScore
+	WeightedSecurityScore float64
+}
+
+// WeightedScore sums the score weight of passed and evaluated findings, sums of several results can be added up
+type WeightedScore struct {
+	Passed float64
+	Total  float64
+}
+
+func (w *WeightedScore) Add(other WeightedScore) {
+	w.Passed += other.Passed
+	w.Total += other.Total
+}
+
+// Score returns the weighted pass percentage
+func (w WeightedScore) Score() float64 {
+	if w.Total <= 0 {
+		return 0
+	}
+	return w.Passed / w.Total * 100.0
+}
+
+// add counts the finding in the result, excepted failures go to their own bucket instead of the alarms
+func (r *Result) add(finding types.Finding, excepted bool, weight float64) {
+	r.CostOptimization = utils.PAdd(r.CostOptimization, finding.CostOptimization)
+	if excepted {
+		if r.ExceptedResult == nil {
+			r.ExceptedResult = map[types.FindingSeverity]int{}
+		}
+		r.ExceptedResult[finding.Severity]++
+		return
+	}
+	if !finding.ConformanceStatus.IsPassed() {
+		r.SeverityResult[finding.Severity]++
+	}
+	r.QueryResult[finding.ConformanceStatus]++
+
+	r.Weighted.Total += weight
+	if finding.ConformanceStatus == types.ConformanceStatusOK {
+		r.Weighted.Passed += weight
+	}
+}
+
+func (r Result) ExceptedCount() int {
+	count := 0
+	for _, c := range r.ExceptedResult {
+		count += c
+	}
+	return count
 }
 
 func (r Result) IsFullyPassed() bool {
@@ -74,12 +129,8 @@ func (b BenchmarkSummary) KeysAndIndex() ([]string, string) {
 	return []string{b.BenchmarkID, fmt.Sprintf("%d", b.JobID)}, types.BenchmarkSummaryIndex
 }
 
-func (r *BenchmarkSummaryResult) addFinding(finding types.Finding) {
-	if !finding.ConformanceStatus.IsPassed() {
-		r.BenchmarkResult.Result.SeverityResult[finding.Severity]++
-	}
-	r.BenchmarkResult.Result.QueryResult[finding.ConformanceStatus]++
-	r.BenchmarkResult.Result.CostOptimization = utils.PAdd(r.BenchmarkResult.Result.CostOptimization, finding.CostOptimization)
+func (r *BenchmarkSummaryResult) addFinding(finding types.Finding, excepted bool, weight float64) {
+	r.BenchmarkResult.Result.add(finding, excepted, weight)
 
 	connection, ok := r.Connections[finding.ConnectionID]
 	if !ok {
@@ -93,11 +144,7 @@ func (r *BenchmarkSummaryResult) addFinding(finding types.Finding) {
 			Controls:      map[string]ControlResult{},
 		}
 	}
-	if !finding.ConformanceStatus.IsPassed() {
-		connection.Result.SeverityResult[finding.Severity]++
-	}
-	connection.Result.QueryResult[finding.ConformanceStatus]++
-	connection.Result.CostOptimization = utils.PAdd(connection.Result.CostOptimization, finding.CostOptimization)
+	connection.Result.add(finding, excepted, weight)
 	r.Connections[finding.ConnectionID] = connection
 
 	resourceType, ok := r.BenchmarkResult.ResourceTypes[finding.ResourceType]
@@ -108,11 +155,7 @@ func (r *BenchmarkSummaryResult) addFinding(finding types.Finding) {
 			SecurityScore:  0,
 		}
 	}
-	if !finding.ConformanceStatus.IsPassed() {
-		resourceType.SeverityResult[finding.Severity]++
-	}
-	resourceType.QueryResult[finding.ConformanceStatus]++
-	resourceType.CostOptimization = utils.PAdd(resourceType.CostOptimization, finding.CostOptimization)
+	resourceType.add(finding, excepted, weight)
 	r.BenchmarkResult.ResourceTypes[finding.ResourceType] = resourceType
 
 	connectionResourceType, ok := connection.ResourceTypes[finding.ResourceType]
@@ -123,11 +166,7 @@ func (r *BenchmarkSummaryResult) addFinding(finding types.Finding) {
 			SecurityScore:  0,
 		}
 	}
-	if !finding.ConformanceStatus.IsPassed() {
-		connectionResourceType.SeverityResult[finding.Severity]++
-	}
-	connectionResourceType.QueryResult[finding.ConformanceStatus]++
-	connectionResourceType.CostOptimization = utils.PAdd(connectionResourceType.CostOptimization, finding.CostOptimization)
+	connectionResourceType.add(finding, excepted, weight)
 	connection.ResourceTypes[finding.ResourceType] = connectionResourceType
 
 	control, ok := r.BenchmarkResult.Controls[finding.ControlID]
@@ -141,7 +180,7 @@ func (r *BenchmarkSummaryResult) addFinding(finding types.Finding) {
 		}
 	}
 
-	if !finding.ConformanceStatus.IsPassed() {
+	if !finding.ConformanceStatus.IsPassed() && !excepted {
 		control.Passed = false
 
 		control.failedResources.Insert([]byte(finding.KaytuResourceID))
@@ -162,7 +201,7 @@ func (r *BenchmarkSummaryResult) addFinding(finding types.Finding) {
 			failedConnections: hyperloglog.New16(),
 		}
 	}
-	if !finding.ConformanceStatus.IsPassed() {
+	if !finding.ConformanceStatus.IsPassed() && !excepted {
 		connectionControl.Passed = false
 		connectionControl.failedResources.Insert([]byte(finding.KaytuResourceID))
 		connectionControl.failedConnections.Insert([]byte(finding.ConnectionID))
@@ -194,6 +233,7 @@ func (r *BenchmarkSummaryResult) summarize() {
 		if total > 0 {
 			summary.SecurityScore = float64(summary.QueryResult[types.ConformanceStatusOK]) / float64(total) * 100.0
 		}
+		summary.WeightedSecurityScore = summary.Weighted.Score()
 
 		r.BenchmarkResult.ResourceTypes[resourceType] = summary
 	}
@@ -205,6 +245,7 @@ func (r *BenchmarkSummaryResult) summarize() {
 	if total > 0 {
 		r.BenchmarkResult.Result.SecurityScore = float64(r.BenchmarkResult.Result.QueryResult[types.ConformanceStatusOK]) / float64(total) * 100.0
 	}
+	r.BenchmarkResult.Result.WeightedSecurityScore = r.BenchmarkResult.Result.Weighted.Score()
 
 	for connectionID, summary := range r.Connections {
 		for controlID, controlSummary := range summary.Controls {
@@ -226,6 +267,7 @@ func (r *BenchmarkSummaryResult) summarize() {
 			if total > 0 {
 				resourceTypeSummary.SecurityScore = float64(resourceTypeSummary.QueryResult[types.ConformanceStatusOK]) / float64(total) * 100.0
 			}
+			resourceTypeSummary.WeightedSecurityScore = resourceTypeSummary.Weighted.Score()
 
 			summary.ResourceTypes[resourceType] = resourceTypeSummary
 		}
@@ -238,6 +280,7 @@ func (r *BenchmarkSummaryResult) summarize() {
 		if total > 0 {
 			summary.Result.SecurityScore = float64(summary.Result.QueryResult[types.ConformanceStatusOK]) / float64(total) * 100.0
 		}
+		summary.Result.WeightedSecurityScore = summary.Result.Weighted.Score()
 
 		r.Connections[connectionID] = summary
 	}
@@ -250,3 +293,116 @@ func (b *BenchmarkSummary) summarize() {
 		b.ResourceCollections[rcId] = rc
 	}
 }
+
+// Merge keeps the results of the connections which are not in connectionIDs from the previous summary and
+// rebuilds the benchmark results of all the connections, used by incremental jobs which only summarize connectionIDs
+func (b *BenchmarkSummary) Merge(previous BenchmarkSummary, connectionIDs []string) {
+	updated := make(map[string]bool)
+	for _, connectionID := range connectionIDs {
+		updated[connectionID] = true
+	}
+
+	b.Connections.merge(previous.Connections, updated)
+	for rcId, previousRc := range previous.ResourceCollections {
+		rc, ok := b.ResourceCollections[rcId]
+		if !ok {
+			rc = BenchmarkSummaryResult{Connections: map[string]ResultGroup{}}
+		}
+		rc.merge(previousRc, updated)
+		b.ResourceCollections[rcId] = rc
+	}
+}
+
+func (r *BenchmarkSummaryResult) merge(previous BenchmarkSummaryResult, updated map[string]bool) {
+	for connectionID, group := range previous.Connections {
+		if updated[connectionID] {
+			continue
+		}
+		r.Connections[connectionID] = group
+	}
+
+	benchmarkResult := newResultGroup()
+	for _, group := range r.Connections {
+		benchmarkResult.add(group)
+	}
+	for resourceType, result := range benchmarkResult.ResourceTypes {
+		result.score()
+		benchmarkResult.ResourceTypes[resourceType] = result
+	}
+	benchmarkResult.Result.score()
+	r.BenchmarkResult = benchmarkResult
+}
+
+func newResult() Result {
+	return Result{
+		QueryResult:    map[types.ConformanceStatus]int{},
+		SeverityResult: map[types.FindingSeverity]int{},
+		SecurityScore:  0,
+	}
+}
+
+func newResultGroup() ResultGroup {
+	return ResultGroup{
+		Result:        newResult(),
+		ResourceTypes: map[string]Result{},
+		Controls:      map[string]ControlResult{},
+	}
+}
+
+// merge adds up the counts of other, scores have to be computed again afterwards
+func (r *Result) merge(other Result) {
+	for status, count := range other.QueryResult {
+		r.QueryResult[status] += count
+	}
+	for severity, count := range other.SeverityResult {
+		r.SeverityResult[severity] += count
+	}
+	for severity, count := range other.ExceptedResult {
+		if r.ExceptedResult == nil {
+			r.ExceptedResult = map[types.FindingSeverity]int{}
+		}
+		r.ExceptedResult[severity] += count
+	}
+	r.CostOptimization = utils.PAdd(r.CostOptimization, other.CostOptimization)
+	r.Weighted.Add(other.Weighted)
+}
+
+func (r *Result) score() {
+	total := 0
+	for _, count := range r.QueryResult {
+		total += count
+	}
+	r.SecurityScore = 0
+	if total > 0 {
+		r.SecurityScore = float64(r.QueryResult[types.ConformanceStatusOK]) / float64(total) * 100.0
+	}
+	r.WeightedSecurityScore = r.Weighted.Score()
+}
+
+// add merges the result group of a connection, connections do not share resources so the control counts add up
+func (g *ResultGroup) add(other ResultGroup) {
+	g.Result.merge(other.Result)
+
+	for resourceType, result := range other.ResourceTypes {
+		current, ok := g.ResourceTypes[resourceType]
+		if !ok {
+			current = newResult()
+		}
+		current.merge(result)
+		g.ResourceTypes[resourceType] = current
+	}
+
+	for controlID, control := range other.Controls {
+		current, ok := g.Controls[controlID]
+		if !ok {
+			current = ControlResult{Passed: true}
+		}
+		current.Passed = current.Passed && control.Passed
+		current.FailedResourcesCount += control.FailedResourcesCount
+		current.TotalResourcesCount += control.TotalResourcesCount
+		current.FailedConnectionCount += control.FailedConnectionCount
+		current.TotalConnectionCount += control.TotalConnectionCount
+		current.CostOptimization = utils.PAdd(current.CostOptimization, control.CostOptimization)
+		g.Controls[controlID] = current
+	}
+}
@@ -0,0 +1,200 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResult() Result {
+	return Result{
+		QueryResult:    map[types.ConformanceStatus]int{},
+		SeverityResult: map[types.FindingSeverity]int{},
+	}
+}
+
+func TestWeightedScore_Score(t *testing.T) {
+	tests := []struct {
+		name  string
+		score WeightedScore
+		want  float64
+	}{
+		{name: "nothing evaluated", score: WeightedScore{}, want: 0},
+		{name: "nothing passed", score: WeightedScore{Passed: 0, Total: 8}, want: 0},
+		{name: "partially passed", score: WeightedScore{Passed: 3, Total: 12}, want: 25},
+		{name: "all passed", score: WeightedScore{Passed: 5, Total: 5}, want: 100},
+		{name: "zero weights only", score: WeightedScore{Passed: 0, Total: 0}, want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, tc.score.Score(), 1e-9)
+		})
+	}
+}
+
+func TestWeightedScore_Add(t *testing.T) {
+	score := WeightedScore{Passed: 1, Total: 2}
+	score.Add(WeightedScore{Passed: 3, Total: 8})
+	score.Add(WeightedScore{})
+	assert.Equal(t, WeightedScore{Passed: 4, Total: 10}, score)
+	assert.InDelta(t, 40, score.Score(), 1e-9)
+}
+
+func TestResult_Add_Weighted(t *testing.T) {
+	r := newTestResult()
+	r.add(types.Finding{ConformanceStatus: types.ConformanceStatusOK, Severity: types.FindingSeverityLow}, false, 1)
+	r.add(types.Finding{ConformanceStatus: types.ConformanceStatusALARM, Severity: types.FindingSeverityCritical}, false, 8)
+	r.add(types.Finding{ConformanceStatus: types.ConformanceStatusOK, Severity: types.FindingSeverityCritical}, false, 8)
+	// Excepted failures are neither passed nor evaluated
+	r.add(types.Finding{ConformanceStatus: types.ConformanceStatusALARM, Severity: types.FindingSeverityHigh}, true, 4)
+	// Info and skip findings count as evaluated but not as passed
+	r.add(types.Finding{ConformanceStatus: types.ConformanceStatusINFO, Severity: types.FindingSeverityNone}, false, 1)
+
+	assert.Equal(t, WeightedScore{Passed: 9, Total: 18}, r.Weighted)
+	assert.InDelta(t, 50, r.Weighted.Score(), 1e-9)
+	assert.Equal(t, 1, r.SeverityResult[types.FindingSeverityCritical])
+	assert.Equal(t, 0, r.SeverityResult[types.FindingSeverityHigh])
+	assert.Equal(t, 1, r.ExceptedCount())
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+// testResultGroup returns the result group of a connection with ok passed and alarm failed findings of a resource type and a control
+func testResultGroup(resourceType, controlID string, ok, alarm int) ResultGroup {
+	result := newTestResult()
+	result.QueryResult[types.ConformanceStatusOK] = ok
+	result.QueryResult[types.ConformanceStatusALARM] = alarm
+	result.SeverityResult[types.FindingSeverityHigh] = alarm
+	result.Weighted = WeightedScore{Passed: float64(ok), Total: float64(ok + alarm)}
+	result.CostOptimization = floatPtr(float64(alarm))
+	return ResultGroup{
+		Result:        result,
+		ResourceTypes: map[string]Result{resourceType: result},
+		Controls: map[string]ControlResult{
+			controlID: {
+				Passed:                alarm == 0,
+				FailedResourcesCount:  alarm,
+				TotalResourcesCount:   ok + alarm,
+				FailedConnectionCount: min(alarm, 1),
+				TotalConnectionCount:  1,
+			},
+		},
+	}
+}
+
+func TestResultGroup_Add(t *testing.T) {
+	g := newResultGroup()
+	g.add(testResultGroup("aws::s3::bucket", "s3_encrypted", 3, 1))
+	g.add(testResultGroup("aws::s3::bucket", "s3_encrypted", 2, 0))
+	g.add(testResultGroup("aws::ec2::instance", "ec2_imdsv2", 0, 2))
+
+	assert.Equal(t, map[types.ConformanceStatus]int{types.ConformanceStatusOK: 5, types.ConformanceStatusALARM: 3}, g.Result.QueryResult)
+	assert.Equal(t, 3, g.Result.SeverityResult[types.FindingSeverityHigh])
+	assert.Equal(t, WeightedScore{Passed: 5, Total: 8}, g.Result.Weighted)
+	assert.InDelta(t, 3, *g.Result.CostOptimization, 1e-9)
+	// scores are only computed once every group is added
+	assert.Zero(t, g.Result.SecurityScore)
+
+	assert.Equal(t, 5, g.ResourceTypes["aws::s3::bucket"].QueryResult[types.ConformanceStatusOK])
+	assert.Equal(t, 1, g.ResourceTypes["aws::s3::bucket"].QueryResult[types.ConformanceStatusALARM])
+	assert.Equal(t, 2, g.ResourceTypes["aws::ec2::instance"].QueryResult[types.ConformanceStatusALARM])
+
+	s3 := g.Controls["s3_encrypted"]
+	assert.False(t, s3.Passed)
+	assert.Equal(t, 1, s3.FailedResourcesCount)
+	assert.Equal(t, 6, s3.TotalResourcesCount)
+	assert.Equal(t, 1, s3.FailedConnectionCount)
+	assert.Equal(t, 2, s3.TotalConnectionCount)
+
+	passing := newResultGroup()
+	passing.add(testResultGroup("aws::s3::bucket", "s3_encrypted", 2, 0))
+	assert.True(t, passing.Controls["s3_encrypted"].Passed)
+}
+
+func testBenchmarkSummary(jobID uint, connections map[string]ResultGroup, resourceCollections map[string]map[string]ResultGroup) BenchmarkSummary {
+	summary := BenchmarkSummary{
+		BenchmarkID:         "aws_cis_v200",
+		JobID:               jobID,
+		Connections:         BenchmarkSummaryResult{BenchmarkResult: newResultGroup(), Connections: connections},
+		ResourceCollections: map[string]BenchmarkSummaryResult{},
+	}
+	for rcID, rcConnections := range resourceCollections {
+		summary.ResourceCollections[rcID] = BenchmarkSummaryResult{BenchmarkResult: newResultGroup(), Connections: rcConnections}
+	}
+	return summary
+}
+
+func TestBenchmarkSummary_Merge(t *testing.T) {
+	previous := testBenchmarkSummary(1, map[string]ResultGroup{
+		"conn-1": testResultGroup("aws::s3::bucket", "s3_encrypted", 1, 3),
+		"conn-2": testResultGroup("aws::s3::bucket", "s3_encrypted", 4, 0),
+		"conn-3": testResultGroup("aws::ec2::instance", "ec2_imdsv2", 0, 1),
+	}, map[string]map[string]ResultGroup{
+		"prod":    {"conn-1": testResultGroup("aws::s3::bucket", "s3_encrypted", 1, 3), "conn-2": testResultGroup("aws::s3::bucket", "s3_encrypted", 4, 0)},
+		"staging": {"conn-3": testResultGroup("aws::ec2::instance", "ec2_imdsv2", 0, 1)},
+	})
+
+	// the incremental job summarized conn-1, whose resources are all passing now, and conn-3, which has no findings anymore
+	current := testBenchmarkSummary(2, map[string]ResultGroup{
+		"conn-1": testResultGroup("aws::s3::bucket", "s3_encrypted", 4, 0),
+	}, map[string]map[string]ResultGroup{
+		"prod": {"conn-1": testResultGroup("aws::s3::bucket", "s3_encrypted", 4, 0)},
+	})
+	current.Merge(previous, []string{"conn-1", "conn-3"})
+
+	assert.Equal(t, uint(2), current.JobID)
+	assert.ElementsMatch(t, []string{"conn-1", "conn-2"}, keys(current.Connections.Connections))
+	assert.Equal(t, 4, current.Connections.Connections["conn-1"].Result.QueryResult[types.ConformanceStatusOK])
+
+	total := current.Connections.BenchmarkResult
+	assert.Equal(t, map[types.ConformanceStatus]int{types.ConformanceStatusOK: 8, types.ConformanceStatusALARM: 0}, total.Result.QueryResult)
+	assert.InDelta(t, 100, total.Result.SecurityScore, 1e-9)
+	assert.InDelta(t, 100, total.Result.WeightedSecurityScore, 1e-9)
+	assert.InDelta(t, 100, total.ResourceTypes["aws::s3::bucket"].SecurityScore, 1e-9)
+	assert.NotContains(t, total.ResourceTypes, "aws::ec2::instance")
+	assert.True(t, total.Controls["s3_encrypted"].Passed)
+	assert.Equal(t, 2, total.Controls["s3_encrypted"].TotalConnectionCount)
+	assert.NotContains(t, total.Controls, "ec2_imdsv2")
+
+	prod := current.ResourceCollections["prod"]
+	assert.ElementsMatch(t, []string{"conn-1", "conn-2"}, keys(prod.Connections))
+	assert.InDelta(t, 100, prod.BenchmarkResult.Result.SecurityScore, 1e-9)
+
+	// staging only had conn-3 which was summarized again without findings
+	staging, ok := current.ResourceCollections["staging"]
+	require.True(t, ok)
+	assert.Empty(t, staging.Connections)
+	assert.Empty(t, staging.BenchmarkResult.Result.QueryResult)
+	assert.Zero(t, staging.BenchmarkResult.Result.SecurityScore)
+}
+
+func TestBenchmarkSummary_Merge_KeepsFailures(t *testing.T) {
+	previous := testBenchmarkSummary(1, map[string]ResultGroup{
+		"conn-1": testResultGroup("aws::s3::bucket", "s3_encrypted", 2, 2),
+		"conn-2": testResultGroup("aws::s3::bucket", "s3_encrypted", 0, 4),
+	}, nil)
+	current := testBenchmarkSummary(2, map[string]ResultGroup{
+		"conn-1": testResultGroup("aws::s3::bucket", "s3_encrypted", 4, 0),
+	}, nil)
+	current.Merge(previous, []string{"conn-1"})
+
+	total := current.Connections.BenchmarkResult
+	assert.InDelta(t, 50, total.Result.SecurityScore, 1e-9)
+	assert.InDelta(t, 50, total.Result.WeightedSecurityScore, 1e-9)
+	assert.InDelta(t, 4, *total.Result.CostOptimization, 1e-9)
+	assert.False(t, total.Controls["s3_encrypted"].Passed)
+	assert.Equal(t, 4, total.Controls["s3_encrypted"].FailedResourcesCount)
+	assert.Equal(t, 1, total.Controls["s3_encrypted"].FailedConnectionCount)
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
@@ -7,4 +7,8 @@ type Job struct {
 	RetryCount  int
 	BenchmarkID string
 	CreatedAt   time.Time
+
+	// Incremental jobs only summarize the findings of ConnectionIDs and merge them into the last benchmark summary
+	Incremental   bool
+	ConnectionIDs []string
 }
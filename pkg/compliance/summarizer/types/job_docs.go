@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/kaytu-io/kaytu-util/pkg/es"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
 	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
 	onboardApi "github.com/kaytu-io/open-governance/pkg/onboard/api"
 	"github.com/kaytu-io/open-governance/pkg/types"
@@ -21,11 +22,47 @@ type JobDocs struct {
 	// caches, these are not marshalled and only used
 	ResourceCollectionCache map[string]inventoryApi.ResourceCollection `json:"-"`
 	ConnectionCache         map[string]onboardApi.Connection           `json:"-"`
+	// active finding exceptions at the time of the job, failed findings matching them are counted as excepted
+	FindingExceptions []complianceApi.FindingException `json:"-"`
+	// ownership rules in evaluation order, findings whose resolved owner changed are collected to be re-ingested
+	OwnershipRules       []complianceApi.OwnershipRule `json:"-"`
+	OwnerChangedFindings []types.Finding               `json:"-"`
+	// weights of the weighted security score
+	ScoreWeights complianceApi.SecurityScoreWeights `json:"-"`
+}
+
+func resourceTags(resource *es.LookupResource) map[string]string {
+	tags := make(map[string]string)
+	if resource != nil {
+		for _, tag := range resource.Tags {
+			tags[tag.Key] = tag.Value
+		}
+	}
+	return tags
+}
+
+func (jd *JobDocs) isExcepted(finding types.Finding, tags map[string]string) bool {
+	if finding.ConformanceStatus.IsPassed() || len(jd.FindingExceptions) == 0 {
+		return false
+	}
+
+	for _, exception := range jd.FindingExceptions {
+		if exception.Matches(finding, tags) {
+			return true
+		}
+	}
+	return false
 }
 
 func (jd *JobDocs) AddFinding(logger *zap.Logger, job Job,
 	finding types.Finding, resource *es.LookupResource,
 ) {
+	tags := resourceTags(resource)
+	if owner := complianceApi.ResolveOwner(jd.OwnershipRules, finding, tags); owner != finding.Owner {
+		finding.Owner = owner
+		jd.OwnerChangedFindings = append(jd.OwnerChangedFindings, finding)
+	}
+
 	if finding.Severity == "" {
 		finding.Severity = types.FindingSeverityNone
 	}
@@ -36,8 +73,11 @@ func (jd *JobDocs) AddFinding(logger *zap.Logger, job Job,
 		finding.ResourceType = "-"
 	}
 
+	excepted := jd.isExcepted(finding, tags)
+	weight := jd.ScoreWeights.Weight(finding)
+
 	if job.BenchmarkID == finding.BenchmarkID {
-		jd.BenchmarkSummary.Connections.addFinding(finding)
+		jd.BenchmarkSummary.Connections.addFinding(finding, excepted, weight)
 	}
 
 	if resource == nil {
@@ -86,6 +126,9 @@ func (jd *JobDocs) AddFinding(logger *zap.Logger, job Job,
 	if resourceFinding.ResourceType == "" {
 		resourceFinding.ResourceType = resource.ResourceType
 	}
+	if resourceFinding.Owner == "" {
+		resourceFinding.Owner = finding.Owner
+	}
 	resourceFinding.Findings = append(resourceFinding.Findings, finding)
 
 	for rcId, rc := range jd.ResourceCollectionCache {
@@ -188,7 +231,7 @@ func (jd *JobDocs) AddFinding(logger *zap.Logger, job Job,
 					Connections: map[string]ResultGroup{},
 				}
 			}
-			benchmarkSummaryRc.addFinding(finding)
+			benchmarkSummaryRc.addFinding(finding, excepted, weight)
 			jd.BenchmarkSummary.ResourceCollections[rcId] = benchmarkSummaryRc
 		}
 	}
@@ -0,0 +1,32 @@
+package api
+
+import "time"
+
+type DeadLetterQueue struct {
+	Stream   string     `json:"stream"`
+	Messages uint64     `json:"messages"`
+	Bytes    uint64     `json:"bytes"`
+	OldestAt *time.Time `json:"oldestAt,omitempty"`
+	NewestAt *time.Time `json:"newestAt,omitempty"`
+}
+
+type DeadLetterMessage struct {
+	Stream   string    `json:"stream"`
+	Sequence uint64    `json:"sequence"`
+	Subject  string    `json:"subject"`
+	Consumer string    `json:"consumer"`
+	Reason   string    `json:"reason"`
+	Attempts int       `json:"attempts"`
+	FailedAt time.Time `json:"failedAt"`
+	Payload  string    `json:"payload,omitempty"`
+}
+
+type ListDeadLetterMessagesResponse struct {
+	Messages     []DeadLetterMessage `json:"messages"`
+	NextSequence *uint64             `json:"nextSequence,omitempty"`
+}
+
+type ReplayDeadLetterMessagesResponse struct {
+	Replayed int `json:"replayed"`
+	Failed   int `json:"failed"`
+}
@@ -0,0 +1,47 @@
+package api
+
+import "time"
+
+type DiscoveryCoverageStatus string
+
+const (
+	DiscoveryCoverageDescribed        DiscoveryCoverageStatus = "DESCRIBED"
+	DiscoveryCoveragePermissionDenied DiscoveryCoverageStatus = "PERMISSION_DENIED"
+	DiscoveryCoverageFailed           DiscoveryCoverageStatus = "FAILED"
+	DiscoveryCoverageNeverAttempted   DiscoveryCoverageStatus = "NEVER_ATTEMPTED"
+)
+
+type DiscoveryCoverageResourceType struct {
+	ResourceType           string                  `json:"resourceType" example:"AWS::EC2::Instance"`
+	Status                 DiscoveryCoverageStatus `json:"status" enums:"DESCRIBED,PERMISSION_DENIED,FAILED,NEVER_ATTEMPTED"`
+	LastJobID              *uint                   `json:"lastJobID,omitempty" example:"1"`
+	LastJobStatus          string                  `json:"lastJobStatus,omitempty" example:"SUCCEEDED"`
+	LastDescribedAt        *time.Time              `json:"lastDescribedAt,omitempty" example:"2020-01-01T00:00:00Z"`
+	ErrorCode              string                  `json:"errorCode,omitempty" example:"AccessDeniedException"`
+	FailureMessage         string                  `json:"failureMessage,omitempty"`
+	DescribedResourceCount int64                   `json:"describedResourceCount" example:"10"`
+}
+
+type DiscoveryCoverageUnevaluableControl struct {
+	ControlID string `json:"controlID" example:"aws_cis_v150_1_4"`
+	Title     string `json:"title"`
+	// ResourceTypes are the resource types read by the control which were not described
+	ResourceTypes []string `json:"resourceTypes"`
+}
+
+type DiscoveryCoverageReport struct {
+	ConnectionID         string   `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	ProviderConnectionID string   `json:"providerConnectionID" example:"123456789012"`
+	Connector            string   `json:"connector" example:"AWS"`
+	AttachedPolicies     []string `json:"attachedPolicies,omitempty"`
+
+	TotalResourceTypes    int     `json:"totalResourceTypes" example:"300"`
+	DescribedCount        int     `json:"describedCount" example:"250"`
+	PermissionDeniedCount int     `json:"permissionDeniedCount" example:"20"`
+	FailedCount           int     `json:"failedCount" example:"10"`
+	NeverAttemptedCount   int     `json:"neverAttemptedCount" example:"20"`
+	CoveragePercentage    float64 `json:"coveragePercentage" example:"83.3"`
+
+	ResourceTypes       []DiscoveryCoverageResourceType       `json:"resourceTypes"`
+	UnevaluableControls []DiscoveryCoverageUnevaluableControl `json:"unevaluableControls"`
+}
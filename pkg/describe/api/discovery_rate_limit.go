@@ -0,0 +1,38 @@
+package api
+
+import "time"
+
+// DiscoveryBudgetExhaustedErrorCode is set on describe jobs deferred because a daily call budget is used up
+const DiscoveryBudgetExhaustedErrorCode = "DiscoveryBudgetExhausted"
+
+type DiscoveryRateLimit struct {
+	ID                uint      `json:"id" example:"1"`
+	ConnectionID      *string   `json:"connectionID,omitempty" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	ResourceType      *string   `json:"resourceType,omitempty" example:"AWS::IAM::Role"`
+	MaxConcurrentJobs int       `json:"maxConcurrentJobs,omitempty" example:"5"`  // Zero means no concurrency limit
+	DailyCallBudget   int64     `json:"dailyCallBudget,omitempty" example:"1000"` // Estimated cloud API calls allowed per UTC day, one list call per describe job plus one per described resource, zero means no budget
+	CreatedBy         string    `json:"createdBy" example:"auth|123"`
+	CreatedAt         time.Time `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	UpdatedAt         time.Time `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type CreateDiscoveryRateLimitRequest struct {
+	ConnectionID      *string `json:"connectionID"`
+	ResourceType      *string `json:"resourceType"`
+	MaxConcurrentJobs int     `json:"maxConcurrentJobs" validate:"min=0"`
+	DailyCallBudget   int64   `json:"dailyCallBudget" validate:"min=0"`
+}
+
+type UpdateDiscoveryRateLimitRequest struct {
+	MaxConcurrentJobs *int   `json:"maxConcurrentJobs" validate:"omitempty,min=0"`
+	DailyCallBudget   *int64 `json:"dailyCallBudget" validate:"omitempty,min=0"`
+}
+
+type DiscoveryRateLimitUsage struct {
+	RateLimit      DiscoveryRateLimit `json:"rateLimit"`
+	RunningJobs    int64              `json:"runningJobs" example:"3"`
+	CallsToday     int64              `json:"callsToday" example:"250"`
+	RemainingCalls *int64             `json:"remainingCalls,omitempty" example:"750"` // Empty when there is no daily budget
+	DeferredJobs   int64              `json:"deferredJobs" example:"0"`
+	ResetsAt       time.Time          `json:"resetsAt" example:"2020-01-02T00:00:00Z"`
+}
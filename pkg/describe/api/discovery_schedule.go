@@ -0,0 +1,48 @@
+package api
+
+import "time"
+
+type DiscoverySchedulePolicy struct {
+	ID                 uint      `json:"id" example:"1"`
+	Name               string    `json:"name" example:"Production security resources"`
+	ConnectionID       *string   `json:"connectionID,omitempty" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	ConnectionTagKey   *string   `json:"connectionTagKey,omitempty" example:"environment"` // Organization tag of AWS accounts or tag of Azure subscriptions
+	ConnectionTagValue *string   `json:"connectionTagValue,omitempty" example:"production"`
+	ResourceType       *string   `json:"resourceType,omitempty" example:"AWS::EC2::SecurityGroup"`
+	CronExpression     string    `json:"cronExpression,omitempty" example:"0 * * * *"` // 5 field cron expression in UTC
+	IntervalMinutes    int       `json:"intervalMinutes,omitempty" example:"60"`
+	Priority           int       `json:"priority" example:"0"` // Lower wins between policies with the same scope
+	Enabled            bool      `json:"enabled" example:"true"`
+	CreatedBy          string    `json:"createdBy" example:"auth|123"`
+	CreatedAt          time.Time `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	UpdatedAt          time.Time `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type CreateDiscoverySchedulePolicyRequest struct {
+	Name               string  `json:"name" validate:"required"`
+	ConnectionID       *string `json:"connectionID"`
+	ConnectionTagKey   *string `json:"connectionTagKey"`
+	ConnectionTagValue *string `json:"connectionTagValue"`
+	ResourceType       *string `json:"resourceType"`
+	CronExpression     string  `json:"cronExpression"`
+	IntervalMinutes    int     `json:"intervalMinutes" validate:"omitempty,min=1"`
+	Priority           int     `json:"priority"`
+	Enabled            *bool   `json:"enabled"` // Defaults to true
+}
+
+type UpdateDiscoverySchedulePolicyRequest struct {
+	Name            *string `json:"name"`
+	CronExpression  *string `json:"cronExpression"`
+	IntervalMinutes *int    `json:"intervalMinutes" validate:"omitempty,min=0"`
+	Priority        *int    `json:"priority"`
+	Enabled         *bool   `json:"enabled"`
+}
+
+type DiscoverySchedulePreview struct {
+	ConnectionID string                   `json:"connectionID,omitempty"`
+	ResourceType string                   `json:"resourceType,omitempty"`
+	Policy       *DiscoverySchedulePolicy `json:"policy,omitempty"` // Empty when the global discovery interval applies
+	Interval     string                   `json:"interval,omitempty" example:"8h0m0s"`
+	LastRunAt    *time.Time               `json:"lastRunAt,omitempty"`
+	NextRuns     []time.Time              `json:"nextRuns"`
+}
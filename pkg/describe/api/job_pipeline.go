@@ -0,0 +1,72 @@
+package api
+
+import "time"
+
+type JobPipelineNodeType string
+
+const (
+	JobPipelineNodeTypeDescribe  JobPipelineNodeType = "Describe"
+	JobPipelineNodeTypeAnalytics JobPipelineNodeType = "Analytics"
+	JobPipelineNodeTypeBenchmark JobPipelineNodeType = "Benchmark"
+	JobPipelineNodeTypeReport    JobPipelineNodeType = "Report"
+)
+
+type JobPipelineFailurePolicy string
+
+const (
+	JobPipelineFailurePolicyContinue JobPipelineFailurePolicy = "continue" // Dependent nodes run anyway
+	JobPipelineFailurePolicyAbort    JobPipelineFailurePolicy = "abort"    // Nodes not started yet are skipped and the pipeline fails
+	JobPipelineFailurePolicyRetry    JobPipelineFailurePolicy = "retry"    // The node is started again up to maxRetries times, then the pipeline aborts
+)
+
+type JobPipelineNodeRequest struct {
+	Key           string                   `json:"key" validate:"required" example:"discover"`
+	Type          JobPipelineNodeType      `json:"type" validate:"required" example:"Describe"`
+	DependsOn     []string                 `json:"dependsOn" example:"discover"`
+	FailurePolicy JobPipelineFailurePolicy `json:"failurePolicy" example:"abort"` // Defaults to abort
+	MaxRetries    int                      `json:"maxRetries" validate:"min=0" example:"2"`
+
+	ConnectionIDs []string       `json:"connectionIDs"`                              // Describe, Benchmark and Report nodes
+	ResourceTypes []string       `json:"resourceTypes" example:"AWS::EC2::Instance"` // Describe nodes
+	BenchmarkID   string         `json:"benchmarkID" example:"aws_cis_v200"`         // Benchmark and Report nodes
+	ReportFormats []ReportFormat `json:"reportFormats"`                              // Report nodes, defaults to all formats
+	TrendDays     int            `json:"trendDays"`                                  // Report nodes
+}
+
+type CreateJobPipelineRequest struct {
+	Name  string                   `json:"name" validate:"required" example:"Nightly CIS"`
+	Nodes []JobPipelineNodeRequest `json:"nodes" validate:"required,min=1"`
+}
+
+type JobPipelineNode struct {
+	ID             uint                     `json:"id" example:"1"`
+	Key            string                   `json:"key" example:"discover"`
+	Type           JobPipelineNodeType      `json:"type" example:"Describe"`
+	DependsOn      []string                 `json:"dependsOn"`
+	Dependents     []string                 `json:"dependents"`
+	FailurePolicy  JobPipelineFailurePolicy `json:"failurePolicy" example:"abort"`
+	MaxRetries     int                      `json:"maxRetries" example:"2"`
+	RetryCount     int                      `json:"retryCount" example:"0"`
+	Status         string                   `json:"status" example:"Running"`
+	JobIDs         []uint                   `json:"jobIDs"` // Describe, analytics, compliance or report run IDs depending on the type
+	FailureMessage string                   `json:"failureMessage,omitempty"`
+	StartedAt      *time.Time               `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time               `json:"finishedAt,omitempty"`
+
+	ConnectionIDs []string       `json:"connectionIDs,omitempty"`
+	ResourceTypes []string       `json:"resourceTypes,omitempty"`
+	BenchmarkID   string         `json:"benchmarkID,omitempty"`
+	ReportFormats []ReportFormat `json:"reportFormats,omitempty"`
+	TrendDays     int            `json:"trendDays,omitempty"`
+}
+
+type JobPipeline struct {
+	ID             uint              `json:"id" example:"1"`
+	Name           string            `json:"name" example:"Nightly CIS"`
+	Status         string            `json:"status" example:"RUNNING"`
+	FailureMessage string            `json:"failureMessage,omitempty"`
+	CreatedBy      string            `json:"createdBy" example:"auth|123"`
+	CreatedAt      time.Time         `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	UpdatedAt      time.Time         `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
+	Nodes          []JobPipelineNode `json:"nodes"`
+}
@@ -260,8 +260,34 @@ type GetComplianceJobStatusResponse struct {
 	IntegrationInfo IntegrationInfo `json:"integration_info"`
 	JobStatus       string          `json:"job_status"`
 	BenchmarkId     string          `json:"benchmark_id"`
+	FailureMessage  string          `json:"failure_message,omitempty"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
+
+	TotalRunners      int `json:"total_runners"`
+	SucceededRunners  int `json:"succeeded_runners"`
+	FailedRunners     int `json:"failed_runners"`
+	InProgressRunners int `json:"in_progress_runners"`
+	// PartiallySucceeded is set when some of the runners of the job succeeded and some failed
+	PartiallySucceeded bool                       `json:"partially_succeeded"`
+	FailureCategories  map[string]int             `json:"failure_categories,omitempty"`
+	FailedQueries      []ComplianceJobFailedQuery `json:"failed_queries,omitempty"`
+}
+
+type ComplianceJobFailedQuery struct {
+	RunnerID       uint     `json:"runner_id"`
+	QueryID        string   `json:"query_id"`
+	ControlIDs     []string `json:"control_ids"`
+	ConnectionID   *string  `json:"connection_id,omitempty"`
+	Status         string   `json:"status"`
+	Category       string   `json:"category" enums:"MISSING_PARAMETER,SQL_ERROR,TIMEOUT,OTHER"`
+	FailureMessage string   `json:"failure_message"`
+	RetryCount     int      `json:"retry_count"`
+}
+
+type RetryComplianceJobResponse struct {
+	JobId          uint `json:"job_id"`
+	RetriedRunners int  `json:"retried_runners"`
 }
 type GetAnalyticsJobStatusResponse struct {
 	JobId     uint      `json:"job_id"`
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"time"
+
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
+	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
+)
+
+type QueryAlertConditionType string
+
+const (
+	QueryAlertConditionRowCountAbove QueryAlertConditionType = "row_count_above" // Row count of the result is above the threshold
+	QueryAlertConditionNewRows       QueryAlertConditionType = "new_rows"        // Result has rows which were not in the previous succeeded run, not checked if the previous result was capped
+	QueryAlertConditionColumnValue   QueryAlertConditionType = "column_value"    // A row has the value in the column, only the kept rows of capped results are checked
+)
+
+type QueryAlertWebhookStatus string
+
+const (
+	QueryAlertWebhookDelivered     QueryAlertWebhookStatus = "delivered"
+	QueryAlertWebhookFailed        QueryAlertWebhookStatus = "failed"
+	QueryAlertWebhookNotConfigured QueryAlertWebhookStatus = "not_configured"
+)
+
+type QueryAlertCondition struct {
+	ID         uint                    `json:"id" example:"1"`
+	Type       QueryAlertConditionType `json:"type" enums:"row_count_above,new_rows,column_value"`
+	Threshold  int                     `json:"threshold" example:"10"` // Used by row_count_above
+	ColumnName string                  `json:"columnName" example:"status"`
+	Value      string                  `json:"value" example:"alarm"` // Used by column_value together with columnName
+}
+
+type QuerySchedule struct {
+	ID             uint                  `json:"id" example:"1"`
+	Name           string                `json:"name" example:"Public buckets every hour"`
+	SavedQueryID   string                `json:"savedQueryID" example:"b7d8e9a1-2c3d-4e5f-8a9b-0c1d2e3f4a5b"`
+	CronExpression string                `json:"cronExpression" example:"0 * * * *"`
+	HistorySize    int                   `json:"historySize" example:"20"` // Results kept for the schedule
+	KeyColumns     []string              `json:"keyColumns" example:"arn"` // Columns matching rows of consecutive runs in the changelog
+	Enabled        bool                  `json:"enabled" example:"true"`
+	Conditions     []QueryAlertCondition `json:"conditions"`
+	CreatedBy      string                `json:"createdBy" example:"auth|123"`
+	LastRunAt      *time.Time            `json:"lastRunAt" example:"2020-01-01T00:00:00Z"`
+	NextRunAt      *time.Time            `json:"nextRunAt" example:"2020-01-01T01:00:00Z"` // Empty if the schedule is disabled
+	CreatedAt      time.Time             `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	UpdatedAt      time.Time             `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type QueryAlertConditionRequest struct {
+	Type       QueryAlertConditionType `json:"type" validate:"required" enums:"row_count_above,new_rows,column_value"`
+	Threshold  int                     `json:"threshold" validate:"min=0"`
+	ColumnName string                  `json:"columnName"`
+	Value      string                  `json:"value"`
+}
+
+type CreateQueryScheduleRequest struct {
+	Name           string                       `json:"name" validate:"required"`
+	SavedQueryID   string                       `json:"savedQueryID" validate:"required"`
+	CronExpression string                       `json:"cronExpression" validate:"required"`
+	HistorySize    int                          `json:"historySize" validate:"omitempty,min=1,max=1000"` // Defaults to 20
+	KeyColumns     []string                     `json:"keyColumns"`                                      // Defaults to the whole row
+	Enabled        *bool                        `json:"enabled"`                                         // Defaults to true
+	Conditions     []QueryAlertConditionRequest `json:"conditions"`
+}
+
+// UpdateQueryScheduleRequest only changes the provided fields, given conditions replace the existing ones
+type UpdateQueryScheduleRequest struct {
+	Name           *string                      `json:"name"`
+	CronExpression *string                      `json:"cronExpression"`
+	HistorySize    *int                         `json:"historySize" validate:"omitempty,min=1,max=1000"`
+	KeyColumns     []string                     `json:"keyColumns"`
+	Enabled        *bool                        `json:"enabled"`
+	Conditions     []QueryAlertConditionRequest `json:"conditions"`
+}
+
+type QueryScheduleResult struct {
+	ID             uint                          `json:"id" example:"1"`
+	ScheduleID     uint                          `json:"scheduleID" example:"1"`
+	JobID          uint                          `json:"jobID" example:"1"`
+	Status         queryrunner.QueryRunnerStatus `json:"status" example:"SUCCEEDED"`
+	FailureMessage string                        `json:"failureMessage"`
+	ColumnNames    []string                      `json:"columnNames"`
+	Rows           [][]string                    `json:"rows"`     // Capped at 1000 rows
+	RowCount       int                           `json:"rowCount"` // Row count of the whole result
+	CreatedAt      time.Time                     `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+}
+
+// QueryScheduleChangelog is the change of a succeeded run against the previous succeeded run of its schedule
+type QueryScheduleChangelog struct {
+	ID               uint                      `json:"id" example:"1"`
+	ScheduleID       uint                      `json:"scheduleID" example:"1"`
+	ResultID         uint                      `json:"resultID" example:"2"`
+	PreviousResultID uint                      `json:"previousResultID" example:"1"`
+	AddedCount       int                       `json:"addedCount" example:"3"`
+	RemovedCount     int                       `json:"removedCount" example:"1"`
+	ChangedCount     int                       `json:"changedCount" example:"2"`
+	Partial          bool                      `json:"partial"` // One of the runs had more rows than kept, rows beyond the cap are not compared
+	Diff             inventoryApi.QueryRunDiff `json:"diff"`    // Keys and changed cells only, capped at 100 rows per list
+	CreatedAt        time.Time                 `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type QueryAlert struct {
+	ID                uint                    `json:"id" example:"1"`
+	ScheduleID        uint                    `json:"scheduleID" example:"1"`
+	ConditionID       uint                    `json:"conditionID" example:"1"`
+	ResultID          uint                    `json:"resultID" example:"1"`
+	ConditionType     QueryAlertConditionType `json:"conditionType" example:"row_count_above"`
+	Message           string                  `json:"message" example:"row count 12 is above 10"`
+	Partial           bool                    `json:"partial"` // Fired on the kept rows of a capped result, rows beyond the cap were not checked
+	WebhookStatus     QueryAlertWebhookStatus `json:"webhookStatus" example:"delivered"`
+	WebhookStatusCode int                     `json:"webhookStatusCode" example:"200"`
+	WebhookError      string                  `json:"webhookError"`
+	CreatedAt         time.Time               `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+}
+
+// QueryAlertPayload is the body posted to the outbound webhook of the workspace when an alert fires
+type QueryAlertPayload struct {
+	Type          string                  `json:"type" example:"query_alert"`
+	WorkspaceName string                  `json:"workspaceName"`
+	AlertID       uint                    `json:"alertID"`
+	ScheduleID    uint                    `json:"scheduleID"`
+	ScheduleName  string                  `json:"scheduleName"`
+	SavedQueryID  string                  `json:"savedQueryID"`
+	ConditionType QueryAlertConditionType `json:"conditionType"`
+	Message       string                  `json:"message"`
+	Partial       bool                    `json:"partial"`
+	RowCount      int                     `json:"rowCount"`
+	FiredAt       time.Time               `json:"firedAt"`
+}
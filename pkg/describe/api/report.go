@@ -0,0 +1,75 @@
+package api
+
+import "time"
+
+type ReportFormat string
+
+const (
+	ReportFormatHTML ReportFormat = "html"
+	ReportFormatPDF  ReportFormat = "pdf" // Text is limited to Latin-1 and WinAnsiEncoding, other characters are shown as ?, use html for other scripts
+)
+
+type ReportRunStatus string
+
+const (
+	ReportRunStatusInProgress ReportRunStatus = "in_progress"
+	ReportRunStatusSucceeded  ReportRunStatus = "succeeded"
+	ReportRunStatusFailed     ReportRunStatus = "failed"
+)
+
+type ReportSchedule struct {
+	ID            uint           `json:"id" example:"1"`
+	Name          string         `json:"name" example:"Monthly CIS report"`
+	BenchmarkID   string         `json:"benchmarkID" example:"aws_cis_v200"`
+	ConnectionIDs []string       `json:"connectionIDs" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"` // Connections the report is scoped to, all if empty
+	Formats       []ReportFormat `json:"formats" example:"pdf"`
+	IntervalHours int            `json:"intervalHours" example:"720"` // Hours between two generated reports
+	TrendDays     int            `json:"trendDays" example:"30"`      // Days of trend included in the report
+	Enabled       bool           `json:"enabled" example:"true"`
+	CreatedBy     string         `json:"createdBy" example:"auth|123"`
+	LastRunAt     *time.Time     `json:"lastRunAt" example:"2020-01-01T00:00:00Z"`
+	NextRunAt     *time.Time     `json:"nextRunAt" example:"2020-01-31T00:00:00Z"` // Empty if the schedule is disabled
+	CreatedAt     time.Time      `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	UpdatedAt     time.Time      `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type CreateReportScheduleRequest struct {
+	Name          string         `json:"name" validate:"required"`
+	BenchmarkID   string         `json:"benchmarkID" validate:"required"`
+	ConnectionIDs []string       `json:"connectionIDs"`
+	Formats       []ReportFormat `json:"formats"` // Defaults to html and pdf
+	IntervalHours int            `json:"intervalHours" validate:"required,min=1"`
+	TrendDays     int            `json:"trendDays" validate:"omitempty,min=1,max=365"` // Defaults to 30
+	Enabled       *bool          `json:"enabled"`                                      // Defaults to true
+}
+
+type UpdateReportScheduleRequest struct {
+	Name          *string        `json:"name"`
+	ConnectionIDs []string       `json:"connectionIDs"`
+	Formats       []ReportFormat `json:"formats"`
+	IntervalHours *int           `json:"intervalHours" validate:"omitempty,min=1"`
+	TrendDays     *int           `json:"trendDays" validate:"omitempty,min=1,max=365"`
+	Enabled       *bool          `json:"enabled"`
+}
+
+type RunReportRequest struct {
+	BenchmarkID   string         `json:"benchmarkID" validate:"required"`
+	ConnectionIDs []string       `json:"connectionIDs"`
+	Formats       []ReportFormat `json:"formats"` // Defaults to html and pdf
+	TrendDays     int            `json:"trendDays" validate:"omitempty,min=1,max=365"`
+}
+
+type ReportRun struct {
+	ID             uint            `json:"id" example:"1"`
+	ScheduleID     *uint           `json:"scheduleID" example:"1"` // Empty for reports generated on demand
+	BenchmarkID    string          `json:"benchmarkID" example:"aws_cis_v200"`
+	ConnectionIDs  []string        `json:"connectionIDs"`
+	Format         ReportFormat    `json:"format" example:"pdf"`
+	Status         ReportRunStatus `json:"status" example:"succeeded"`
+	FailureMessage string          `json:"failureMessage"`
+	FileName       string          `json:"fileName" example:"aws_cis_v200-20200101-000000.pdf"`
+	Size           int             `json:"size" example:"20480"` // Size of the generated file in bytes
+	CreatedBy      string          `json:"createdBy" example:"auth|123"`
+	CreatedAt      time.Time       `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	UpdatedAt      time.Time       `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
+}
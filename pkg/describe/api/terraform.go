@@ -0,0 +1,52 @@
+package api
+
+import "time"
+
+type TerraformBackendType string
+
+const (
+	TerraformBackendTypeS3      TerraformBackendType = "s3"
+	TerraformBackendTypeAzureRM TerraformBackendType = "azurerm"
+	TerraformBackendTypeLocal   TerraformBackendType = "local" // Config has a path relative to the configured local state directory
+)
+
+type TerraformBackend struct {
+	ID                 uint                 `json:"id" example:"1"`
+	Name               string               `json:"name" example:"network"`
+	ConnectionID       string               `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	Type               TerraformBackendType `json:"type" example:"s3"`
+	Config             map[string]any       `json:"config"` // Terraform backend configuration, credentials are masked
+	LastSyncedAt       *time.Time           `json:"lastSyncedAt,omitempty"`
+	LastSyncError      string               `json:"lastSyncError,omitempty"`
+	StateResourceCount int                  `json:"stateResourceCount" example:"120"`
+	CreatedBy          string               `json:"createdBy" example:"auth|123"`
+	CreatedAt          time.Time            `json:"createdAt" example:"2020-01-01T00:00:00Z"`
+	UpdatedAt          time.Time            `json:"updatedAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type CreateTerraformBackendRequest struct {
+	Name         string               `json:"name" validate:"required"`
+	ConnectionID string               `json:"connectionID" validate:"required"`
+	Type         TerraformBackendType `json:"type" validate:"required"`
+	Config       map[string]any       `json:"config" validate:"required"` // Credentials such as secret keys, passwords and tokens are stored encrypted by the vault
+}
+
+type TerraformResource struct {
+	ConnectionID       string    `json:"connectionID"`
+	ResourceID         string    `json:"resourceID" example:"arn:aws:s3:::my-bucket"`
+	ResourceType       string    `json:"resourceType,omitempty" example:"aws::s3::bucket"`
+	ResourceName       string    `json:"resourceName,omitempty" example:"my-bucket"`
+	BackendID          uint      `json:"backendID,omitempty" example:"1"`
+	Address            string    `json:"address,omitempty" example:"module.storage.aws_s3_bucket.this"`
+	TerraformType      string    `json:"terraformType,omitempty" example:"aws_s3_bucket"`
+	ManagedByTerraform bool      `json:"managedByTerraform"`
+	DriftStatus        string    `json:"driftStatus" example:"in_sync"`
+	SyncedAt           time.Time `json:"syncedAt"`
+}
+
+type TerraformDriftResponse struct {
+	InSyncCount     int64               `json:"inSyncCount"`
+	NotInStateCount int64               `json:"notInStateCount"`
+	NotInCloudCount int64               `json:"notInCloudCount"`
+	Resources       []TerraformResource `json:"resources"`
+}
@@ -11,6 +11,8 @@ const (
 	ServerlessProviderTypeAWSLambda      ServerlessProviderType = "aws-lambda"
 	ServerlessProviderTypeAzureFunctions ServerlessProviderType = "azure-functions"
 	ServerlessProviderTypeLocal          ServerlessProviderType = "local"
+	// ServerlessProviderTypeFile describes resources from exports on disk instead of the cloud, see FileDescriberDir
+	ServerlessProviderTypeFile ServerlessProviderType = "file"
 )
 
 func (s ServerlessProviderType) String() string {
@@ -22,6 +24,10 @@ type SchedulerConfig struct {
 	EventHubConnectionString   string `yaml:"event_hub_connection_string"`
 	ServiceBusConnectionString string `yaml:"service_bus_connection_string"`
 	ServerlessProvider         string `yaml:"serverless_provider"`
+	TerraformLocalStateDir     string `yaml:"terraform_local_state_dir"` // Enables local terraform state backends under this directory
+	IncrementalCompliance      bool   `yaml:"incremental_compliance"`    // Re-runs the controls touched by discovery changes after describe jobs
+	FileDescriberDir           string `yaml:"file_describer_dir"`        // Exports read by the file serverless provider, one directory per account or connection id
+	OutboundWebhookSecret      string `yaml:"outbound_webhook_secret"`   // Signs the alerts posted to the outbound webhook of the workspace
 	ElasticSearch              config.ElasticSearch
 	Onboard                    config.KaytuService
 	NATS                       config.NATS
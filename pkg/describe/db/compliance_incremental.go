@@ -0,0 +1,35 @@
+package db
+
+import (
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+)
+
+func (db Database) CreateComplianceIncrementalTrigger(trigger *model.ComplianceIncrementalTrigger) error {
+	tx := db.ORM.Create(trigger)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) ListComplianceIncrementalTriggers() ([]model.ComplianceIncrementalTrigger, error) {
+	var triggers []model.ComplianceIncrementalTrigger
+	tx := db.ORM.Model(&model.ComplianceIncrementalTrigger{}).Order("id ASC").Find(&triggers)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return triggers, nil
+}
+
+// DeleteComplianceIncrementalTriggers deletes the triggers of the connection up to maxID,
+// triggers created while the incremental jobs were being created are kept for the next cycle
+func (db Database) DeleteComplianceIncrementalTriggers(connectionID string, maxID uint) error {
+	tx := db.ORM.
+		Where("connection_id = ?", connectionID).
+		Where("id <= ?", maxID).
+		Delete(&model.ComplianceIncrementalTrigger{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
@@ -69,7 +69,8 @@ func (db Database) UpdateComplianceJobAreAllRunnersQueued(id uint, areAllRunners
 func (db Database) UpdateComplianceJobsTimedOut(complianceIntervalHours int64) error {
 	tx := db.ORM.
 		Model(&model.ComplianceJob{}).
-		Where(fmt.Sprintf("created_at < NOW() - INTERVAL '%d HOURS'", complianceIntervalHours)).
+		// jobs whose failed runners were retried get the same time to finish again
+		Where(fmt.Sprintf("COALESCE(retried_at, created_at) < NOW() - INTERVAL '%d HOURS'", complianceIntervalHours)).
 		Where("status IN ?", []string{string(model.ComplianceJobCreated),
 			string(model.ComplianceJobRunnersInProgress),
 			string(model.ComplianceJobSummarizerInProgress),
@@ -101,9 +102,11 @@ func (db Database) CleanupComplianceJobsOlderThan(t time.Time) error {
 	return nil
 }
 
+// GetLastComplianceJob returns the last full compliance job of the benchmark, incremental jobs do not replace scheduled runs
 func (db Database) GetLastComplianceJob(benchmarkID string) (*model.ComplianceJob, error) {
 	var job model.ComplianceJob
-	tx := db.ORM.Model(&model.ComplianceJob{}).Where("benchmark_id = ?", benchmarkID).Order("created_at DESC").First(&job)
+	tx := db.ORM.Model(&model.ComplianceJob{}).Where("benchmark_id = ?", benchmarkID).
+		Where("incremental = ?", false).Order("created_at DESC").First(&job)
 	if tx.Error != nil {
 		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -167,7 +170,7 @@ func (db Database) ListComplianceJobsWithSummaryJob(interval, triggerType, creat
 			compliance_jobs.created_by,
 			COALESCE(array_agg(COALESCE(compliance_summarizers.id::text, '')), '{}') as summarizer_jobs
 		`).
-		Joins("LEFT JOIN compliance_summarizers ON compliance_jobs.id = compliance_summarizers.parent_job_id").
+		Joins("LEFT JOIN compliance_summarizers ON compliance_jobs.id = compliance_summarizers.parent_job_id AND compliance_summarizers.deleted_at IS NULL").
 		Group("compliance_jobs.id")
 
 	// Apply filters
@@ -222,6 +225,20 @@ func (db Database) ListPendingComplianceJobsByConnectionID(connectionIds []strin
 	return job, nil
 }
 
+// ListUnfinishedComplianceJobsByConnectionID returns the compliance jobs of the connection which are not done yet
+func (db Database) ListUnfinishedComplianceJobsByConnectionID(connectionID string) ([]model.ComplianceJob, error) {
+	var jobs []model.ComplianceJob
+	tx := db.ORM.Model(&model.ComplianceJob{}).
+		Where("connection_id = ?", connectionID).
+		Where("status NOT IN ?", []model.ComplianceJobStatus{model.ComplianceJobSucceeded, model.ComplianceJobFailed,
+			model.ComplianceJobTimeOut, model.ComplianceJobCanceled}).
+		Find(&jobs)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return jobs, nil
+}
+
 func (db Database) ListComplianceJobsByBenchmarkID(benchmarkIds []string) ([]model.ComplianceJob, error) {
 	var job []model.ComplianceJob
 	tx := db.ORM.Model(&model.ComplianceJob{}).Where("benchmark_id IN ?", benchmarkIds).Find(&job)
@@ -350,7 +367,7 @@ func (db Database) ListJobsToFinish() ([]model.ComplianceJob, error) {
 	var jobs []model.ComplianceJob
 	tx := db.ORM.Raw(`
 SELECT * FROM compliance_jobs j WHERE status = 'SUMMARIZER_IN_PROGRESS' AND
-	(select count(*) from compliance_summarizers where parent_job_id = j.id AND (status = 'SUCCEEDED' OR (status = 'FAILED' and retry_count >= 3))) > 0
+	(select count(*) from compliance_summarizers where parent_job_id = j.id AND deleted_at IS NULL AND (status = 'SUCCEEDED' OR (status = 'FAILED' and retry_count >= 3))) > 0
 `).Find(&jobs)
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -175,3 +175,58 @@ func (db Database) CleanupAllComplianceRunners() error {
 	}
 	return nil
 }
+
+// RetryFailedRunnersOfJob sets the failed and timed out runners of the job to be run again and soft deletes its summarizers
+// so the job is summarized again once the runners are done, returns the number of runners to be retried
+func (db Database) RetryFailedRunnersOfJob(jobID uint) (int64, error) {
+	var count int64
+	err := db.ORM.Transaction(func(tx *gorm.DB) error {
+		retriedAt := time.Now()
+		res := tx.Model(&model.ComplianceRunner{}).
+			Where("parent_job_id = ?", jobID).
+			Where("status IN ?", []runner.ComplianceRunnerStatus{runner.ComplianceRunnerFailed, runner.ComplianceRunnerTimeOut}).
+			Updates(map[string]any{
+				"status":               runner.ComplianceRunnerCreated,
+				"retry_count":          0,
+				"failure_message":      "",
+				"nats_sequence_number": 0,
+				"retried_at":           retriedAt,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		count = res.RowsAffected
+		if count == 0 {
+			return nil
+		}
+
+		if err := tx.Where("parent_job_id = ?", jobID).Delete(&model.ComplianceSummarizer{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.ComplianceJob{}).
+			Where("id = ?", jobID).
+			Updates(map[string]any{
+				"status":          model.ComplianceJobRunnersInProgress,
+				"failure_message": "",
+				"retried_at":      retriedAt,
+			}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListRunnerIDsRetriedSince returns the ids of the runners of the job which were retried at or after the given time
+func (db Database) ListRunnerIDsRetriedSince(jobID uint, since time.Time) ([]uint, error) {
+	var ids []uint
+	tx := db.ORM.Model(&model.ComplianceRunner{}).
+		Where("parent_job_id = ?", jobID).
+		Where("retried_at >= ?", since).
+		Pluck("id", &ids)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ids, nil
+}
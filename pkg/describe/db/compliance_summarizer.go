@@ -41,7 +41,7 @@ func (db Database) FetchCreatedSummarizers(manuals bool) ([]model.ComplianceSumm
 }
 
 func (db Database) RetryFailedSummarizers() error {
-	tx := db.ORM.Exec("UPDATE compliance_summarizers SET retry_count = retry_count + 1, status = 'CREATED' WHERE status = 'FAILED' AND retry_count < 3 AND updated_at < NOW() - interval '7 minutes'")
+	tx := db.ORM.Exec("UPDATE compliance_summarizers SET retry_count = retry_count + 1, status = 'CREATED' WHERE status = 'FAILED' AND retry_count < 3 AND deleted_at IS NULL AND updated_at < NOW() - interval '7 minutes'")
 	if tx.Error != nil {
 		return tx.Error
 	}
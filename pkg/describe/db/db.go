@@ -10,8 +10,17 @@ type Database struct {
 }
 
 func (db Database) Initialize() error {
-	return db.ORM.AutoMigrate(&model.ComplianceJob{}, &model.ComplianceSummarizer{}, &model.ComplianceRunner{}, &model.CheckupJob{},
+	return db.ORM.AutoMigrate(&model.ComplianceJob{}, &model.ComplianceSummarizer{}, &model.ComplianceRunner{}, &model.ComplianceIncrementalTrigger{}, &model.CheckupJob{},
 		&model.AnalyticsJob{}, &model.DescribeConnectionJob{}, &model.IntegrationDiscovery{},
-		&model.JobSequencer{}, &model.QueryRunnerJob{},
+		&model.JobSequencer{}, &model.QueryRunnerJob{}, &model.ReportSchedule{}, &model.ReportRun{},
+		&model.DiscoverySchedulePolicy{},
+		&model.DiscoveryRateLimit{},
+		&model.JobPipeline{},
+		&model.TerraformBackend{},
+		&model.QuerySchedule{},
+		&model.QueryAlertCondition{},
+		&model.QueryScheduleResult{},
+		&model.QueryScheduleChangelog{},
+		&model.QueryAlert{},
 	)
 }
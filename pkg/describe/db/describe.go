@@ -75,33 +75,6 @@ func (db Database) CountDescribeConnectionJobsRunOverLast10Minutes(manuals bool)
 	return count, nil
 }
 
-type ResourceTypeCount struct {
-	ResourceType string
-	Count        int
-}
-
-func (db Database) CountRunningDescribeJobsPerResourceType(manuals bool) ([]ResourceTypeCount, error) {
-	var count []ResourceTypeCount
-	runningJobs := []api.DescribeResourceJobStatus{api.DescribeResourceJobQueued, api.DescribeResourceJobInProgress, api.DescribeResourceJobOldResourceDeletion}
-	query := `select resource_type, count(*) as count from describe_connection_jobs where status in ?`
-	if manuals {
-		query = query + ` AND trigger_type = ?`
-	} else {
-		query = query + ` AND trigger_type <> ?`
-	}
-	query = query + ` group by 1`
-	tx := db.ORM.Raw(query, runningJobs, enums.DescribeTriggerTypeManual)
-
-	tx = tx.Find(&count)
-	if tx.Error != nil {
-		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, tx.Error
-	}
-	return count, nil
-}
-
 func (db Database) GetLastDescribeConnectionJob(connectionID, resourceType string) (*model.DescribeConnectionJob, error) {
 	var job model.DescribeConnectionJob
 	tx := db.ORM.Preload(clause.Associations).Where("connection_id = ? AND resource_type = ?", connectionID, resourceType).Order("updated_at DESC").First(&job)
@@ -150,7 +123,10 @@ func (db Database) RetryDescribeConnectionJob(id uint) error {
 	return nil
 }
 func (db Database) QueueDescribeConnectionJob(id uint) error {
-	tx := db.ORM.Exec("update describe_connection_jobs set status = ?, queued_at = NOW(), retry_count = retry_count + 1 where id = ?", api.DescribeResourceJobQueued, id)
+	tx := db.ORM.Exec(`update describe_connection_jobs set status = ?, queued_at = NOW(), retry_count = retry_count + 1,
+	failure_message = CASE WHEN deferred_until IS NULL THEN failure_message ELSE '' END,
+	error_code = CASE WHEN deferred_until IS NULL THEN error_code ELSE '' END,
+	deferred_until = NULL where id = ?`, api.DescribeResourceJobQueued, id)
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -179,7 +155,8 @@ SELECT
 FROM
 	describe_connection_jobs dr
 WHERE
-	status = ?`
+	status = ? AND
+	(deferred_until IS NULL OR deferred_until < NOW())`
 
 	if manuals {
 		query = query + ` AND trigger_type = ?`
@@ -865,3 +842,20 @@ func (db Database) GetDiscoveryJobsByParentID(parentId uint) ([]model.DescribeCo
 
 	return jobs, nil
 }
+
+// ListLastFinishedDescribeConnectionJobs returns the last finished describe job of every resource type of the connection
+func (db Database) ListLastFinishedDescribeConnectionJobs(connectionID string) ([]model.DescribeConnectionJob, error) {
+	var jobs []model.DescribeConnectionJob
+	tx := db.ORM.Raw(`
+SELECT DISTINCT ON (resource_type) * FROM describe_connection_jobs
+WHERE connection_id = ? AND status IN ? AND deleted_at IS NULL
+ORDER BY resource_type, updated_at DESC
+`, connectionID, []api.DescribeResourceJobStatus{api.DescribeResourceJobSucceeded, api.DescribeResourceJobFailed, api.DescribeResourceJobTimeout}).Find(&jobs)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return jobs, nil
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/describe/enums"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"gorm.io/gorm"
+)
+
+type ConnectionResourceTypeCount struct {
+	ConnectionID string
+	ResourceType string
+	Count        int64
+}
+
+func (db Database) CreateDiscoveryRateLimit(limit *model.DiscoveryRateLimit) error {
+	tx := db.ORM.Create(limit)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetDiscoveryRateLimit(id uint) (*model.DiscoveryRateLimit, error) {
+	var limit model.DiscoveryRateLimit
+	tx := db.ORM.Model(&model.DiscoveryRateLimit{}).Where("id = ?", id).First(&limit)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &limit, nil
+}
+
+func (db Database) ListDiscoveryRateLimits() ([]model.DiscoveryRateLimit, error) {
+	var limits []model.DiscoveryRateLimit
+	tx := db.ORM.Model(&model.DiscoveryRateLimit{}).Order("id ASC").Find(&limits)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return limits, nil
+}
+
+func (db Database) UpdateDiscoveryRateLimit(limit *model.DiscoveryRateLimit) error {
+	tx := db.ORM.Model(&model.DiscoveryRateLimit{}).Where("id = ?", limit.ID).
+		Select("max_concurrent_jobs", "daily_call_budget", "updated_at").
+		Updates(limit)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) DeleteDiscoveryRateLimit(id uint) error {
+	tx := db.ORM.Where("id = ?", id).Delete(&model.DiscoveryRateLimit{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) countDescribeConnectionJobsPerConnectionResourceType(query string, args ...any) ([]ConnectionResourceTypeCount, error) {
+	var counts []ConnectionResourceTypeCount
+	tx := db.ORM.Model(&model.DescribeConnectionJob{}).
+		Select("connection_id, resource_type, count(*) as count").
+		Where(query, args...).
+		Group("connection_id, resource_type").
+		Find(&counts)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return counts, nil
+}
+
+// CountRunningDescribeJobsPerConnectionResourceType counts the queued and running jobs, manuals filters on the trigger type when set
+func (db Database) CountRunningDescribeJobsPerConnectionResourceType(manuals *bool) ([]ConnectionResourceTypeCount, error) {
+	runningJobs := []api.DescribeResourceJobStatus{api.DescribeResourceJobQueued, api.DescribeResourceJobInProgress, api.DescribeResourceJobOldResourceDeletion}
+	if manuals == nil {
+		return db.countDescribeConnectionJobsPerConnectionResourceType("status IN ?", runningJobs)
+	}
+	if *manuals {
+		return db.countDescribeConnectionJobsPerConnectionResourceType("status IN ? AND trigger_type = ?", runningJobs, enums.DescribeTriggerTypeManual)
+	}
+	return db.countDescribeConnectionJobsPerConnectionResourceType("status IN ? AND trigger_type <> ?", runningJobs, enums.DescribeTriggerTypeManual)
+}
+
+// CountDiscoveryCallsSince estimates the cloud API calls of the jobs sent to the describers since the given time,
+// a job makes one list call plus one call per described resource
+func (db Database) CountDiscoveryCallsSince(since time.Time) ([]ConnectionResourceTypeCount, error) {
+	var counts []ConnectionResourceTypeCount
+	tx := db.ORM.Model(&model.DescribeConnectionJob{}).
+		Select("connection_id, resource_type, sum(1 + described_resource_count) as count").
+		Where("queued_at >= ?", since).
+		Group("connection_id, resource_type").
+		Find(&counts)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return counts, nil
+}
+
+// LastDescribedResourceCounts returns the resource count of the latest succeeded job per resource type of the given connections
+func (db Database) LastDescribedResourceCounts(connectionIDs []string) ([]ConnectionResourceTypeCount, error) {
+	var counts []ConnectionResourceTypeCount
+	tx := db.ORM.Raw(`SELECT DISTINCT ON (connection_id, resource_type) connection_id, resource_type, described_resource_count as count
+FROM describe_connection_jobs
+WHERE status = ? AND connection_id IN ?
+ORDER BY connection_id, resource_type, updated_at DESC`, api.DescribeResourceJobSucceeded, connectionIDs).Find(&counts)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return counts, nil
+}
+
+func (db Database) CountDeferredDescribeJobs() ([]ConnectionResourceTypeCount, error) {
+	return db.countDescribeConnectionJobsPerConnectionResourceType("status = ? AND deferred_until > NOW()", api.DescribeResourceJobCreated)
+}
+
+// DeferDescribeConnectionJobs keeps the jobs created but skips them until the given time
+func (db Database) DeferDescribeConnectionJobs(ids []uint, until time.Time, msg, errCode string) error {
+	tx := db.ORM.Exec("UPDATE describe_connection_jobs SET deferred_until = ?, failure_message = ?, error_code = ? WHERE id IN ? AND status = ?",
+		until, msg, errCode, ids, api.DescribeResourceJobCreated)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"gorm.io/gorm"
+)
+
+func (db Database) CreateDiscoverySchedulePolicy(policy *model.DiscoverySchedulePolicy) error {
+	tx := db.ORM.Create(policy)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetDiscoverySchedulePolicy(id uint) (*model.DiscoverySchedulePolicy, error) {
+	var policy model.DiscoverySchedulePolicy
+	tx := db.ORM.Model(&model.DiscoverySchedulePolicy{}).Where("id = ?", id).First(&policy)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &policy, nil
+}
+
+func (db Database) ListDiscoverySchedulePolicies(enabledOnly bool) ([]model.DiscoverySchedulePolicy, error) {
+	var policies []model.DiscoverySchedulePolicy
+	tx := db.ORM.Model(&model.DiscoverySchedulePolicy{})
+	if enabledOnly {
+		tx = tx.Where("enabled = ?", true)
+	}
+	tx = tx.Order("priority ASC").Order("id ASC").Find(&policies)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return policies, nil
+}
+
+func (db Database) UpdateDiscoverySchedulePolicy(policy *model.DiscoverySchedulePolicy) error {
+	tx := db.ORM.Model(&model.DiscoverySchedulePolicy{}).Where("id = ?", policy.ID).
+		Select("name", "cron_expression", "interval_minutes", "priority", "enabled", "updated_at").
+		Updates(policy)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) DeleteDiscoverySchedulePolicy(id uint) error {
+	tx := db.ORM.Where("id = ?", id).Delete(&model.DiscoverySchedulePolicy{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
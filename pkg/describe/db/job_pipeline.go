@@ -0,0 +1,82 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"gorm.io/gorm"
+)
+
+// CreateJobPipeline stores the pipeline and its nodes together
+func (db Database) CreateJobPipeline(pipeline *model.JobPipeline, nodes []model.JobSequencer) error {
+	return db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(pipeline).Error; err != nil {
+			return err
+		}
+		for i := range nodes {
+			nodes[i].PipelineID = &pipeline.ID
+		}
+		if err := tx.Create(&nodes).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (db Database) GetJobPipeline(id uint) (*model.JobPipeline, error) {
+	var pipeline model.JobPipeline
+	tx := db.ORM.Model(&model.JobPipeline{}).Where("id = ?", id).First(&pipeline)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &pipeline, nil
+}
+
+func (db Database) ListJobPipelines(limit int) ([]model.JobPipeline, error) {
+	var pipelines []model.JobPipeline
+	tx := db.ORM.Model(&model.JobPipeline{}).Order("id DESC").Limit(limit).Find(&pipelines)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return pipelines, nil
+}
+
+func (db Database) ListRunningJobPipelines() ([]model.JobPipeline, error) {
+	var pipelines []model.JobPipeline
+	tx := db.ORM.Model(&model.JobPipeline{}).Where("status = ?", model.JobPipelineRunning).Order("id ASC").Find(&pipelines)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return pipelines, nil
+}
+
+func (db Database) UpdateJobPipelineStatus(id uint, status model.JobPipelineStatus, failureMessage string) error {
+	tx := db.ORM.Model(&model.JobPipeline{}).Where("id = ?", id).
+		Updates(map[string]any{"status": status, "failure_message": failureMessage})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) ListJobPipelineNodes(pipelineID uint) ([]model.JobSequencer, error) {
+	var nodes []model.JobSequencer
+	tx := db.ORM.Model(&model.JobSequencer{}).Where("pipeline_id = ?", pipelineID).Order("id ASC").Find(&nodes)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return nodes, nil
+}
+
+func (db Database) UpdateJobPipelineNode(node *model.JobSequencer) error {
+	tx := db.ORM.Model(&model.JobSequencer{}).Where("id = ?", node.ID).
+		Select("status", "next_job_ids", "retry_count", "failure_message", "started_at", "finished_at", "updated_at").
+		Updates(node)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
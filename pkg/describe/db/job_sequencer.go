@@ -23,6 +23,7 @@ func (db Database) ListWaitingJobSequencers() ([]model.JobSequencer, error) {
 	var jobs []model.JobSequencer
 	tx := db.ORM.Model(&model.JobSequencer{}).
 		Where("status = ?", model.JobSequencerWaitingForDependencies).
+		Where("pipeline_id IS NULL").
 		Find(&jobs)
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -35,6 +36,7 @@ func (db Database) ListJobSequencersOfTypeOfToday(dependencySource, nextJob mode
 	tx := db.ORM.Model(&model.JobSequencer{}).
 		Where("dependency_source", dependencySource).
 		Where("next_job", nextJob).
+		Where("pipeline_id IS NULL").
 		Where("created_at > NOW() - interval '1 day'").Order("created_at desc").Find(&jobs)
 	if tx.Error != nil {
 		return nil, tx.Error
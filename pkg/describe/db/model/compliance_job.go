@@ -42,6 +42,13 @@ type ComplianceJob struct {
 	FailureMessage      string
 	TriggerType         ComplianceTriggerType
 	CreatedBy           string
+
+	// Incremental jobs only re-run the controls querying the resource types changed by discovery
+	Incremental          bool
+	ChangedResourceTypes pq.StringArray `gorm:"type:text[]"`
+
+	// RetriedAt is when the failed runners of the job were last retried, the job times out counting from it
+	RetriedAt *time.Time
 }
 
 func (c ComplianceJob) ToApi() api.ComplianceJob {
@@ -71,6 +78,9 @@ type ComplianceRunner struct {
 
 	TriggerType        ComplianceTriggerType
 	NatsSequenceNumber uint64
+
+	// RetriedAt is when the runner was last retried as part of a retry of its job
+	RetriedAt *time.Time
 }
 
 func (cr *ComplianceRunner) GetKeyIdentifier() string {
@@ -108,6 +118,20 @@ type ComplianceSummarizer struct {
 	FailureMessage string
 
 	TriggerType ComplianceTriggerType
+
+	// Incremental summarizers only summarize the findings of ConnectionIDs and merge them into the previous benchmark summary
+	Incremental   bool
+	ConnectionIDs pq.StringArray `gorm:"type:text[]"`
+}
+
+// ComplianceIncrementalTrigger is a resource type of a connection changed by a discovery job,
+// incremental compliance jobs of the connection are created from them
+type ComplianceIncrementalTrigger struct {
+	ID            uint   `gorm:"primarykey"`
+	ConnectionID  string `gorm:"index"`
+	ResourceType  string
+	DescribeJobID uint
+	CreatedAt     time.Time
 }
 
 type ComplianceJobWithSummarizerJob struct {
@@ -57,6 +57,7 @@ type DescribeConnectionJob struct {
 	ErrorCode              string // Should be NULLSTRING
 	DescribedResourceCount int64
 	DeletingCount          int64
+	DeferredUntil          *time.Time
 
 	NatsSequenceNumber uint64
 }
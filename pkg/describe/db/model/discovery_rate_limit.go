@@ -0,0 +1,43 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"gorm.io/gorm"
+)
+
+// DiscoveryRateLimit limits the describe jobs of the connections and resource types in its scope.
+// Daily call budgets count the estimated cloud API calls of the describe jobs, one list call per job plus
+// one call per described resource, budgets reset at midnight UTC.
+type DiscoveryRateLimit struct {
+	gorm.Model
+	ConnectionID      *string `gorm:"index"`
+	ResourceType      *string
+	MaxConcurrentJobs int
+	DailyCallBudget   int64
+	CreatedBy         string
+}
+
+func (l DiscoveryRateLimit) Matches(connectionID, resourceType string) bool {
+	if l.ConnectionID != nil && *l.ConnectionID != connectionID {
+		return false
+	}
+	if l.ResourceType != nil && !strings.EqualFold(*l.ResourceType, resourceType) {
+		return false
+	}
+	return true
+}
+
+func (l DiscoveryRateLimit) ToApi() api.DiscoveryRateLimit {
+	return api.DiscoveryRateLimit{
+		ID:                l.ID,
+		ConnectionID:      l.ConnectionID,
+		ResourceType:      l.ResourceType,
+		MaxConcurrentJobs: l.MaxConcurrentJobs,
+		DailyCallBudget:   l.DailyCallBudget,
+		CreatedBy:         l.CreatedBy,
+		CreatedAt:         l.CreatedAt,
+		UpdatedAt:         l.UpdatedAt,
+	}
+}
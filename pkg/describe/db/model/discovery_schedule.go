@@ -0,0 +1,90 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// DiscoverySchedulePolicy overrides the global discovery intervals for the connections and resource types in its scope.
+// Exactly one of CronExpression and IntervalMinutes is set.
+type DiscoverySchedulePolicy struct {
+	gorm.Model
+	Name               string
+	ConnectionID       *string `gorm:"index"`
+	ConnectionTagKey   *string
+	ConnectionTagValue *string
+	ResourceType       *string
+	CronExpression     string
+	IntervalMinutes    int
+	Priority           int `gorm:"not null;default:0"`
+	Enabled            bool
+	CreatedBy          string
+}
+
+// Specificity ranks policies matching the same discovery, a connection scope beats a tag scope which beats a resource type scope
+func (p DiscoverySchedulePolicy) Specificity() int {
+	specificity := 0
+	if p.ConnectionID != nil {
+		specificity += 4
+	}
+	if p.ConnectionTagKey != nil {
+		specificity += 2
+	}
+	if p.ResourceType != nil {
+		specificity += 1
+	}
+	return specificity
+}
+
+func (p DiscoverySchedulePolicy) Matches(connectionID string, connectionTags map[string][]string, resourceType string) bool {
+	if p.ConnectionID != nil && *p.ConnectionID != connectionID {
+		return false
+	}
+	if p.ResourceType != nil && !strings.EqualFold(*p.ResourceType, resourceType) {
+		return false
+	}
+	if p.ConnectionTagKey != nil {
+		values, ok := connectionTags[*p.ConnectionTagKey]
+		if !ok {
+			return false
+		}
+		if p.ConnectionTagValue != nil && !utils.Includes(values, *p.ConnectionTagValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// NextRunAfter returns when a discovery last run at the given time is due again
+func (p DiscoverySchedulePolicy) NextRunAfter(lastRun time.Time) (time.Time, error) {
+	if p.CronExpression != "" {
+		cron, err := utils.ParseCron(p.CronExpression)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cron.Next(lastRun), nil
+	}
+	return lastRun.Add(time.Duration(p.IntervalMinutes) * time.Minute), nil
+}
+
+func (p DiscoverySchedulePolicy) ToApi() api.DiscoverySchedulePolicy {
+	return api.DiscoverySchedulePolicy{
+		ID:                 p.ID,
+		Name:               p.Name,
+		ConnectionID:       p.ConnectionID,
+		ConnectionTagKey:   p.ConnectionTagKey,
+		ConnectionTagValue: p.ConnectionTagValue,
+		ResourceType:       p.ResourceType,
+		CronExpression:     p.CronExpression,
+		IntervalMinutes:    p.IntervalMinutes,
+		Priority:           p.Priority,
+		Enabled:            p.Enabled,
+		CreatedBy:          p.CreatedBy,
+		CreatedAt:          p.CreatedAt,
+		UpdatedAt:          p.UpdatedAt,
+	}
+}
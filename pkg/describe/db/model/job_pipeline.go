@@ -0,0 +1,23 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+type JobPipelineStatus string
+
+const (
+	JobPipelineRunning              JobPipelineStatus = "RUNNING"
+	JobPipelineSucceeded            JobPipelineStatus = "SUCCEEDED"
+	JobPipelineCompletedWithFailure JobPipelineStatus = "COMPLETED_WITH_FAILURE"
+	JobPipelineFailed               JobPipelineStatus = "FAILED"
+)
+
+// JobPipeline is a DAG of jobs, its nodes are the job sequencers with the pipeline's ID
+type JobPipeline struct {
+	gorm.Model
+	Name           string
+	Status         JobPipelineStatus
+	FailureMessage string
+	CreatedBy      string
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/jackc/pgtype"
 	"github.com/lib/pq"
 	"gorm.io/gorm"
@@ -12,6 +14,8 @@ const (
 	JobSequencerWaitingForDependencies JobSequencerStatus = "WaitingForDependencies"
 	JobSequencerFinished               JobSequencerStatus = "FINISHED"
 	JobSequencerFailed                 JobSequencerStatus = "Failed"
+	JobSequencerRunning                JobSequencerStatus = "Running"
+	JobSequencerSkipped                JobSequencerStatus = "Skipped"
 )
 
 type JobSequencerJobType string
@@ -22,6 +26,15 @@ const (
 	JobSequencerJobTypeBenchmarkSummarizer JobSequencerJobType = "BenchmarkSummarizer"
 	JobSequencerJobTypeDescribe            JobSequencerJobType = "Describe"
 	JobSequencerJobTypeAnalytics           JobSequencerJobType = "Analytics"
+	JobSequencerJobTypeReport              JobSequencerJobType = "Report"
+)
+
+type JobSequencerFailurePolicy string
+
+const (
+	JobSequencerFailurePolicyContinue JobSequencerFailurePolicy = "continue"
+	JobSequencerFailurePolicyAbort    JobSequencerFailurePolicy = "abort"
+	JobSequencerFailurePolicyRetry    JobSequencerFailurePolicy = "retry"
 )
 
 type JobSequencerJobTypeBenchmarkRunnerParameters struct {
@@ -30,6 +43,15 @@ type JobSequencerJobTypeBenchmarkRunnerParameters struct {
 	ConnectionIDs []string
 }
 
+// JobSequencerNodeParameters are the parameters of a pipeline node, the ones used depend on the node job type
+type JobSequencerNodeParameters struct {
+	ConnectionIDs []string
+	ResourceTypes []string
+	BenchmarkID   string
+	ReportFormats []string
+	TrendDays     int
+}
+
 type JobSequencer struct {
 	gorm.Model
 	DependencyList    pq.Int64Array `gorm:"type:bigint[]"`
@@ -38,4 +60,15 @@ type JobSequencer struct {
 	NextJobParameters *pgtype.JSONB
 	NextJobIDs        string
 	Status            JobSequencerStatus
+
+	// Pipeline node fields, empty for the deprecated dependency sequences
+	PipelineID     *uint `gorm:"index"`
+	NodeKey        string
+	DependsOn      pq.StringArray `gorm:"type:text[]"`
+	FailurePolicy  JobSequencerFailurePolicy
+	MaxRetries     int
+	RetryCount     int
+	FailureMessage string
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
 }
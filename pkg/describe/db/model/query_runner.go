@@ -13,4 +13,5 @@ type QueryRunnerJob struct {
 	Status             queryrunner.QueryRunnerStatus
 	FailureMessage     string
 	NatsSequenceNumber uint64
+	ScheduleID         *uint `gorm:"index"` // Set for runs of a query schedule, QueryId is then a saved query
 }
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
+	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
+	"github.com/kaytu-io/open-governance/pkg/utils"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// QuerySchedule runs a saved query of the inventory service on a cron expression
+type QuerySchedule struct {
+	gorm.Model
+	Name           string
+	SavedQueryID   string `gorm:"index"`
+	CronExpression string
+	HistorySize    int
+	KeyColumns     pq.StringArray `gorm:"type:text[]"`
+	Enabled        bool
+	CreatedBy      string // Runs are made on behalf of the creator, so the saved query has to stay visible to them
+	LastRunAt      *time.Time
+	NextRunAt      *time.Time `gorm:"index"`
+
+	Conditions []QueryAlertCondition `gorm:"foreignKey:ScheduleID;constraint:OnDelete:CASCADE;"`
+}
+
+// ScheduleNext moves NextRunAt to the first time after the given one matching the cron expression
+func (s *QuerySchedule) ScheduleNext(after time.Time) error {
+	if !s.Enabled {
+		s.NextRunAt = nil
+		return nil
+	}
+	cron, err := utils.ParseCron(s.CronExpression)
+	if err != nil {
+		return err
+	}
+	next := cron.Next(after)
+	if next.IsZero() {
+		s.NextRunAt = nil
+	} else {
+		s.NextRunAt = &next
+	}
+	return nil
+}
+
+func (s QuerySchedule) ToApi() api.QuerySchedule {
+	conditions := make([]api.QueryAlertCondition, 0, len(s.Conditions))
+	for _, c := range s.Conditions {
+		conditions = append(conditions, c.ToApi())
+	}
+	return api.QuerySchedule{
+		ID:             s.ID,
+		Name:           s.Name,
+		SavedQueryID:   s.SavedQueryID,
+		CronExpression: s.CronExpression,
+		HistorySize:    s.HistorySize,
+		KeyColumns:     s.KeyColumns,
+		Enabled:        s.Enabled,
+		Conditions:     conditions,
+		CreatedBy:      s.CreatedBy,
+		LastRunAt:      s.LastRunAt,
+		NextRunAt:      s.NextRunAt,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
+
+type QueryAlertCondition struct {
+	gorm.Model
+	ScheduleID uint `gorm:"index"`
+	Type       api.QueryAlertConditionType
+	Threshold  int
+	ColumnName string
+	Value      string
+}
+
+func (c QueryAlertCondition) ToApi() api.QueryAlertCondition {
+	return api.QueryAlertCondition{
+		ID:         c.ID,
+		Type:       c.Type,
+		Threshold:  c.Threshold,
+		ColumnName: c.ColumnName,
+		Value:      c.Value,
+	}
+}
+
+// QueryScheduleResult is a run of a query schedule, only the latest HistorySize results of a schedule are kept
+type QueryScheduleResult struct {
+	gorm.Model
+	ScheduleID     uint `gorm:"index"`
+	JobID          uint `gorm:"index"`
+	Status         queryrunner.QueryRunnerStatus
+	FailureMessage string
+	ColumnNames    pq.StringArray `gorm:"type:text[]"`
+	Rows           pgtype.JSONB
+	RowCount       int
+}
+
+func (r QueryScheduleResult) GetRows() [][]string {
+	var rows [][]string
+	if r.Rows.Status == pgtype.Present {
+		_ = json.Unmarshal(r.Rows.Bytes, &rows)
+	}
+	return rows
+}
+
+func (r QueryScheduleResult) ToApi() api.QueryScheduleResult {
+	return api.QueryScheduleResult{
+		ID:             r.ID,
+		ScheduleID:     r.ScheduleID,
+		JobID:          r.JobID,
+		Status:         r.Status,
+		FailureMessage: r.FailureMessage,
+		ColumnNames:    r.ColumnNames,
+		Rows:           r.GetRows(),
+		RowCount:       r.RowCount,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+// QueryScheduleChangelog is kept for succeeded runs which differ from the previous succeeded run of the schedule
+type QueryScheduleChangelog struct {
+	gorm.Model
+	ScheduleID       uint `gorm:"index"`
+	ResultID         uint
+	PreviousResultID uint
+	AddedCount       int
+	RemovedCount     int
+	ChangedCount     int
+	Partial          bool
+	Diff             pgtype.JSONB
+}
+
+func (c QueryScheduleChangelog) ToApi() api.QueryScheduleChangelog {
+	var diff inventoryApi.QueryRunDiff
+	if c.Diff.Status == pgtype.Present {
+		_ = json.Unmarshal(c.Diff.Bytes, &diff)
+	}
+	return api.QueryScheduleChangelog{
+		ID:               c.ID,
+		ScheduleID:       c.ScheduleID,
+		ResultID:         c.ResultID,
+		PreviousResultID: c.PreviousResultID,
+		AddedCount:       c.AddedCount,
+		RemovedCount:     c.RemovedCount,
+		ChangedCount:     c.ChangedCount,
+		Partial:          c.Partial,
+		Diff:             diff,
+		CreatedAt:        c.CreatedAt,
+	}
+}
+
+type QueryAlert struct {
+	gorm.Model
+	ScheduleID        uint `gorm:"index"`
+	ConditionID       uint
+	ResultID          uint
+	ConditionType     api.QueryAlertConditionType
+	Message           string
+	Partial           bool
+	WebhookStatus     api.QueryAlertWebhookStatus
+	WebhookStatusCode int
+	WebhookError      string
+}
+
+func (a QueryAlert) ToApi() api.QueryAlert {
+	return api.QueryAlert{
+		ID:                a.ID,
+		ScheduleID:        a.ScheduleID,
+		ConditionID:       a.ConditionID,
+		ResultID:          a.ResultID,
+		ConditionType:     a.ConditionType,
+		Message:           a.Message,
+		Partial:           a.Partial,
+		WebhookStatus:     a.WebhookStatus,
+		WebhookStatusCode: a.WebhookStatusCode,
+		WebhookError:      a.WebhookError,
+		CreatedAt:         a.CreatedAt,
+	}
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+type ReportSchedule struct {
+	gorm.Model
+	Name          string
+	BenchmarkID   string
+	ConnectionIDs pq.StringArray `gorm:"type:text[]"`
+	Formats       pq.StringArray `gorm:"type:text[]"`
+	IntervalHours int
+	TrendDays     int
+	Enabled       bool
+	CreatedBy     string
+	LastRunAt     *time.Time
+}
+
+func (s ReportSchedule) NextRunAt() *time.Time {
+	if !s.Enabled {
+		return nil
+	}
+	if s.LastRunAt == nil {
+		return &s.CreatedAt
+	}
+	next := s.LastRunAt.Add(time.Duration(s.IntervalHours) * time.Hour)
+	return &next
+}
+
+func (s ReportSchedule) ToApi() api.ReportSchedule {
+	formats := make([]api.ReportFormat, 0, len(s.Formats))
+	for _, f := range s.Formats {
+		formats = append(formats, api.ReportFormat(f))
+	}
+	return api.ReportSchedule{
+		ID:            s.ID,
+		Name:          s.Name,
+		BenchmarkID:   s.BenchmarkID,
+		ConnectionIDs: s.ConnectionIDs,
+		Formats:       formats,
+		IntervalHours: s.IntervalHours,
+		TrendDays:     s.TrendDays,
+		Enabled:       s.Enabled,
+		CreatedBy:     s.CreatedBy,
+		LastRunAt:     s.LastRunAt,
+		NextRunAt:     s.NextRunAt(),
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+type ReportRun struct {
+	gorm.Model
+	ScheduleID     *uint `gorm:"index"`
+	BenchmarkID    string
+	ConnectionIDs  pq.StringArray `gorm:"type:text[]"`
+	Format         api.ReportFormat
+	Status         api.ReportRunStatus
+	FailureMessage string
+	FileName       string
+	Size           int
+	Content        []byte
+	CreatedBy      string
+}
+
+func (r ReportRun) ToApi() api.ReportRun {
+	return api.ReportRun{
+		ID:             r.ID,
+		ScheduleID:     r.ScheduleID,
+		BenchmarkID:    r.BenchmarkID,
+		ConnectionIDs:  r.ConnectionIDs,
+		Format:         r.Format,
+		Status:         r.Status,
+		FailureMessage: r.FailureMessage,
+		FileName:       r.FileName,
+		Size:           r.Size,
+		CreatedBy:      r.CreatedBy,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// TerraformBackend is where the terraform state of a connection's resources is read from
+type TerraformBackend struct {
+	gorm.Model
+	Name               string
+	ConnectionID       string `gorm:"index"`
+	Type               api.TerraformBackendType
+	Config             pgtype.JSONB   // Backend settings without the credentials
+	Secret             string         // Credentials of the config, encrypted by the vault
+	SecretKeys         pq.StringArray `gorm:"type:text[]"`
+	LastSyncedAt       *time.Time
+	LastSyncError      string
+	StateResourceCount int
+	CreatedBy          string
+}
+
+var terraformSecretConfigKeys = []string{"secret", "password", "token", "access_key", "sas"}
+
+func isTerraformSecretConfigKey(key string) bool {
+	for _, secret := range terraformSecretConfigKeys {
+		if strings.Contains(strings.ToLower(key), secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitTerraformBackendConfig separates the credentials of a backend config, to be stored encrypted, from its settings
+func SplitTerraformBackendConfig(config map[string]any) (settings map[string]any, secrets map[string]any) {
+	settings = make(map[string]any)
+	secrets = make(map[string]any)
+	for k, v := range config {
+		if isTerraformSecretConfigKey(k) {
+			secrets[k] = v
+		} else {
+			settings[k] = v
+		}
+	}
+	return settings, secrets
+}
+
+// GetConfig returns the backend settings, the credentials have to be decrypted from Secret
+func (b TerraformBackend) GetConfig() (map[string]any, error) {
+	config := make(map[string]any)
+	if len(b.Config.Bytes) == 0 {
+		return config, nil
+	}
+	err := json.Unmarshal(b.Config.Bytes, &config)
+	return config, err
+}
+
+// ToApi returns the backend with the credentials in its config masked
+func (b TerraformBackend) ToApi() api.TerraformBackend {
+	config, _ := b.GetConfig()
+	for k := range config {
+		if isTerraformSecretConfigKey(k) {
+			config[k] = "********"
+		}
+	}
+	for _, k := range b.SecretKeys {
+		config[k] = "********"
+	}
+	return api.TerraformBackend{
+		ID:                 b.ID,
+		Name:               b.Name,
+		ConnectionID:       b.ConnectionID,
+		Type:               b.Type,
+		Config:             config,
+		LastSyncedAt:       b.LastSyncedAt,
+		LastSyncError:      b.LastSyncError,
+		StateResourceCount: b.StateResourceCount,
+		CreatedBy:          b.CreatedBy,
+		CreatedAt:          b.CreatedAt,
+		UpdatedAt:          b.UpdatedAt,
+	}
+}
@@ -144,3 +144,16 @@ func (db Database) CleanupAllQueryRunnerJobs() error {
 	}
 	return nil
 }
+
+// CountUnfinishedQueryRunnerJobsOfSchedule counts the runs of the query schedule which are not done yet
+func (db Database) CountUnfinishedQueryRunnerJobsOfSchedule(scheduleID uint) (int64, error) {
+	var count int64
+	tx := db.ORM.Model(&model.QueryRunnerJob{}).
+		Where("schedule_id = ?", scheduleID).
+		Where("status IN ?", []queryrunner.QueryRunnerStatus{queryrunner.QueryRunnerCreated, queryrunner.QueryRunnerQueued, queryrunner.QueryRunnerInProgress}).
+		Count(&count)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return count, nil
+}
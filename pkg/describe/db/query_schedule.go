@@ -0,0 +1,205 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
+	"gorm.io/gorm"
+)
+
+func (db Database) CreateQuerySchedule(schedule *model.QuerySchedule) error {
+	tx := db.ORM.Create(schedule)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetQuerySchedule(id uint) (*model.QuerySchedule, error) {
+	var schedule model.QuerySchedule
+	tx := db.ORM.Model(&model.QuerySchedule{}).Preload("Conditions").Where("id = ?", id).First(&schedule)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &schedule, nil
+}
+
+func (db Database) ListQuerySchedules(savedQueryID *string) ([]model.QuerySchedule, error) {
+	var schedules []model.QuerySchedule
+	tx := db.ORM.Model(&model.QuerySchedule{}).Preload("Conditions")
+	if savedQueryID != nil {
+		tx = tx.Where("saved_query_id = ?", *savedQueryID)
+	}
+	tx = tx.Order("id ASC").Find(&schedules)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return schedules, nil
+}
+
+// ListDueQuerySchedules returns enabled schedules whose next run has come
+func (db Database) ListDueQuerySchedules(now time.Time) ([]model.QuerySchedule, error) {
+	var schedules []model.QuerySchedule
+	tx := db.ORM.Model(&model.QuerySchedule{}).
+		Where("enabled = ?", true).
+		Where("next_run_at <= ?", now).
+		Find(&schedules)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return schedules, nil
+}
+
+// UpdateQuerySchedule saves the schedule fields, the conditions are replaced by the ones of the schedule if replaceConditions is set
+func (db Database) UpdateQuerySchedule(schedule *model.QuerySchedule, replaceConditions bool) error {
+	return db.ORM.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.QuerySchedule{}).Where("id = ?", schedule.ID).
+			Select("name", "cron_expression", "history_size", "key_columns", "enabled", "next_run_at", "updated_at").
+			Updates(schedule).Error
+		if err != nil {
+			return err
+		}
+		if !replaceConditions {
+			return nil
+		}
+		if err := tx.Unscoped().Where("schedule_id = ?", schedule.ID).Delete(&model.QueryAlertCondition{}).Error; err != nil {
+			return err
+		}
+		for i := range schedule.Conditions {
+			schedule.Conditions[i].ID = 0
+			schedule.Conditions[i].ScheduleID = schedule.ID
+		}
+		if len(schedule.Conditions) == 0 {
+			return nil
+		}
+		return tx.Create(&schedule.Conditions).Error
+	})
+}
+
+func (db Database) UpdateQueryScheduleRun(id uint, lastRunAt time.Time, nextRunAt *time.Time) error {
+	tx := db.ORM.Model(&model.QuerySchedule{}).Where("id = ?", id).
+		Updates(map[string]any{"last_run_at": lastRunAt, "next_run_at": nextRunAt})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// DeleteQuerySchedule deletes the schedule with its conditions, results and changelog, fired alerts are kept
+func (db Database) DeleteQuerySchedule(id uint) error {
+	return db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("schedule_id = ?", id).Delete(&model.QueryAlertCondition{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("schedule_id = ?", id).Delete(&model.QueryScheduleResult{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("schedule_id = ?", id).Delete(&model.QueryScheduleChangelog{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.QuerySchedule{}).Error
+	})
+}
+
+// CreateQueryScheduleResult stores the result and drops the results of the schedule beyond its history size
+func (db Database) CreateQueryScheduleResult(result *model.QueryScheduleResult, historySize int) error {
+	return db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(result).Error; err != nil {
+			return err
+		}
+		keep := tx.Model(&model.QueryScheduleResult{}).Select("id").
+			Where("schedule_id = ?", result.ScheduleID).Order("id DESC").Limit(historySize)
+		return tx.Unscoped().
+			Where("schedule_id = ?", result.ScheduleID).
+			Where("id NOT IN (?)", keep).
+			Delete(&model.QueryScheduleResult{}).Error
+	})
+}
+
+// GetPreviousSucceededQueryScheduleResult returns the last succeeded result of the schedule before the given one, nil if there is none
+func (db Database) GetPreviousSucceededQueryScheduleResult(scheduleID, resultID uint) (*model.QueryScheduleResult, error) {
+	var result model.QueryScheduleResult
+	tx := db.ORM.Model(&model.QueryScheduleResult{}).
+		Where("schedule_id = ? AND id < ? AND status = ?", scheduleID, resultID, queryrunner.QueryRunnerSucceeded).
+		Order("id DESC").First(&result)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &result, nil
+}
+
+func (db Database) ListQueryScheduleResults(scheduleID uint) ([]model.QueryScheduleResult, error) {
+	var results []model.QueryScheduleResult
+	tx := db.ORM.Model(&model.QueryScheduleResult{}).Where("schedule_id = ?", scheduleID).Order("id DESC").Find(&results)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return results, nil
+}
+
+// CreateQueryScheduleChangelog stores the changelog entry and drops the oldest entries of the schedule beyond maxEntries
+func (db Database) CreateQueryScheduleChangelog(changelog *model.QueryScheduleChangelog, maxEntries int) error {
+	return db.ORM.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(changelog).Error; err != nil {
+			return err
+		}
+		keep := tx.Model(&model.QueryScheduleChangelog{}).Select("id").
+			Where("schedule_id = ?", changelog.ScheduleID).Order("id DESC").Limit(maxEntries)
+		return tx.Unscoped().
+			Where("schedule_id = ?", changelog.ScheduleID).
+			Where("id NOT IN (?)", keep).
+			Delete(&model.QueryScheduleChangelog{}).Error
+	})
+}
+
+func (db Database) ListQueryScheduleChangelog(scheduleID uint, limit int) ([]model.QueryScheduleChangelog, error) {
+	var changelog []model.QueryScheduleChangelog
+	tx := db.ORM.Model(&model.QueryScheduleChangelog{}).Where("schedule_id = ?", scheduleID).Order("id DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	tx = tx.Find(&changelog)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return changelog, nil
+}
+
+func (db Database) CreateQueryAlert(alert *model.QueryAlert) error {
+	tx := db.ORM.Create(alert)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) UpdateQueryAlertWebhook(id uint, status api.QueryAlertWebhookStatus, statusCode int, webhookError string) error {
+	tx := db.ORM.Model(&model.QueryAlert{}).Where("id = ?", id).
+		Updates(map[string]any{"webhook_status": status, "webhook_status_code": statusCode, "webhook_error": webhookError})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) ListQueryAlerts(scheduleID uint, limit int) ([]model.QueryAlert, error) {
+	var alerts []model.QueryAlert
+	tx := db.ORM.Model(&model.QueryAlert{}).Where("schedule_id = ?", scheduleID).Order("id DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	tx = tx.Find(&alerts)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return alerts, nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+func (db Database) CreateReportSchedule(schedule *model.ReportSchedule) error {
+	tx := db.ORM.Create(schedule)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetReportSchedule(id uint) (*model.ReportSchedule, error) {
+	var schedule model.ReportSchedule
+	tx := db.ORM.Model(&model.ReportSchedule{}).Where("id = ?", id).First(&schedule)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &schedule, nil
+}
+
+// ListReportSchedules returns the schedules, a non-nil connectionScope limits them to the ones covering only those connections
+func (db Database) ListReportSchedules(connectionScope []string) ([]model.ReportSchedule, error) {
+	var schedules []model.ReportSchedule
+	tx := db.ORM.Model(&model.ReportSchedule{})
+	if connectionScope != nil {
+		tx = tx.Where("cardinality(connection_ids) > 0 AND connection_ids <@ ?", pq.StringArray(connectionScope))
+	}
+	tx = tx.Order("id ASC").Find(&schedules)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return schedules, nil
+}
+
+// ListDueReportSchedules returns enabled schedules that never ran or whose interval elapsed since the last run
+func (db Database) ListDueReportSchedules(now time.Time) ([]model.ReportSchedule, error) {
+	var schedules []model.ReportSchedule
+	tx := db.ORM.Model(&model.ReportSchedule{}).
+		Where("enabled = ?", true).
+		Where("last_run_at IS NULL OR last_run_at + (interval_hours * INTERVAL '1 HOUR') <= ?", now).
+		Find(&schedules)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return schedules, nil
+}
+
+func (db Database) UpdateReportSchedule(schedule *model.ReportSchedule) error {
+	tx := db.ORM.Model(&model.ReportSchedule{}).Where("id = ?", schedule.ID).
+		Select("name", "connection_ids", "formats", "interval_hours", "trend_days", "enabled", "updated_at").
+		Updates(schedule)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) UpdateReportScheduleLastRunAt(id uint, lastRunAt time.Time) error {
+	tx := db.ORM.Model(&model.ReportSchedule{}).Where("id = ?", id).Update("last_run_at", lastRunAt)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// DeleteReportSchedule deletes the schedule, runs it generated are kept for download
+func (db Database) DeleteReportSchedule(id uint) error {
+	tx := db.ORM.Where("id = ?", id).Delete(&model.ReportSchedule{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) CreateReportRun(run *model.ReportRun) error {
+	tx := db.ORM.Create(run)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) UpdateReportRunResult(id uint, status api.ReportRunStatus, failureMessage, fileName string, content []byte) error {
+	tx := db.ORM.Model(&model.ReportRun{}).Where("id = ?", id).
+		Select("status", "failure_message", "file_name", "size", "content", "updated_at").
+		Updates(model.ReportRun{
+			Status:         status,
+			FailureMessage: failureMessage,
+			FileName:       fileName,
+			Size:           len(content),
+			Content:        content,
+		})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// GetReportRun returns the run without its content, use GetReportRunWithContent to download it
+func (db Database) GetReportRun(id uint) (*model.ReportRun, error) {
+	var run model.ReportRun
+	tx := db.ORM.Model(&model.ReportRun{}).Omit("content").Where("id = ?", id).First(&run)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &run, nil
+}
+
+func (db Database) GetReportRunWithContent(id uint) (*model.ReportRun, error) {
+	var run model.ReportRun
+	tx := db.ORM.Model(&model.ReportRun{}).Where("id = ?", id).First(&run)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &run, nil
+}
+
+// ListReportRuns returns the runs without their content, a non-nil connectionScope limits them to the ones covering only those connections
+func (db Database) ListReportRuns(scheduleID *uint, connectionScope []string, limit int) ([]model.ReportRun, error) {
+	var runs []model.ReportRun
+	tx := db.ORM.Model(&model.ReportRun{}).Omit("content")
+	if scheduleID != nil {
+		tx = tx.Where("schedule_id = ?", *scheduleID)
+	}
+	if connectionScope != nil {
+		tx = tx.Where("cardinality(connection_ids) > 0 AND connection_ids <@ ?", pq.StringArray(connectionScope))
+	}
+	tx = tx.Order("id DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	tx = tx.Find(&runs)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return runs, nil
+}
+
+func (db Database) DeleteReportRun(id uint) error {
+	tx := db.ORM.Unscoped().Where("id = ?", id).Delete(&model.ReportRun{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// UpdateTimedOutReportRuns fails runs that stayed in progress, e.g. because the scheduler restarted while generating them
+func (db Database) UpdateTimedOutReportRuns(timeout time.Duration) error {
+	tx := db.ORM.Model(&model.ReportRun{}).
+		Where("status = ?", api.ReportRunStatusInProgress).
+		Where("updated_at < ?", time.Now().Add(-timeout)).
+		Updates(model.ReportRun{Status: api.ReportRunStatusFailed, FailureMessage: "Report generation timed out"})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"gorm.io/gorm"
+)
+
+func (db Database) CreateTerraformBackend(backend *model.TerraformBackend) error {
+	tx := db.ORM.Create(backend)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (db Database) GetTerraformBackend(id uint) (*model.TerraformBackend, error) {
+	var backend model.TerraformBackend
+	tx := db.ORM.Model(&model.TerraformBackend{}).Where("id = ?", id).First(&backend)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &backend, nil
+}
+
+func (db Database) ListTerraformBackends(connectionID *string) ([]model.TerraformBackend, error) {
+	var backends []model.TerraformBackend
+	tx := db.ORM.Model(&model.TerraformBackend{})
+	if connectionID != nil {
+		tx = tx.Where("connection_id = ?", *connectionID)
+	}
+	tx = tx.Order("id ASC").Find(&backends)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return backends, nil
+}
+
+func (db Database) DeleteTerraformBackend(id uint) error {
+	tx := db.ORM.Where("id = ?", id).Delete(&model.TerraformBackend{})
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+// UpdateTerraformBackendSync records a sync attempt, syncedAt is only moved forward by successful syncs
+func (db Database) UpdateTerraformBackendSync(id uint, syncedAt *time.Time, syncError string, stateResourceCount int) error {
+	updates := map[string]any{"last_sync_error": syncError}
+	if syncedAt != nil {
+		updates["last_synced_at"] = *syncedAt
+		updates["state_resource_count"] = stateResourceCount
+	}
+	tx := db.ORM.Model(&model.TerraformBackend{}).Where("id = ?", id).Updates(updates)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package describe
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
+	"go.uber.org/zap"
+)
+
+const DeadLetterQueueMetricsInterval = time.Minute
+
+func (s *Scheduler) RunDeadLetterQueueMetrics(ctx context.Context) {
+	s.logger.Info("Updating dead-letter queue metrics on a timer")
+
+	t := ticker.NewTicker(DeadLetterQueueMetricsInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		queues, err := s.dlq.Queues(ctx)
+		if err != nil {
+			s.logger.Error("failed to list dead-letter queues", zap.Error(err))
+			continue
+		}
+		for _, queue := range queues {
+			DeadLetterQueueDepth.WithLabelValues(queue.Stream).Set(float64(queue.Messages))
+		}
+	}
+}
+
+// replayDeadLetters replays every dead letter of the stream, messages failing to be replayed are kept.
+// Sequences are collected first so messages failing again while replaying are not replayed twice.
+func (s *Scheduler) replayDeadLetters(ctx context.Context, stream string) (api.ReplayDeadLetterMessagesResponse, error) {
+	var res api.ReplayDeadLetterMessagesResponse
+	var sequences []uint64
+	var from uint64
+	for {
+		messages, next, err := s.dlq.List(ctx, stream, from, 1000)
+		if err != nil {
+			return res, err
+		}
+		for _, msg := range messages {
+			sequences = append(sequences, msg.Sequence)
+		}
+		if next == 0 {
+			break
+		}
+		from = next
+	}
+
+	for _, seq := range sequences {
+		if err := s.dlq.Replay(ctx, stream, seq); err != nil {
+			s.logger.Error("failed to replay dead letter", zap.String("stream", stream), zap.Uint64("sequence", seq), zap.Error(err))
+			res.Failed++
+			continue
+		}
+		res.Replayed++
+	}
+	return res, nil
+}
+
+func deadLetterQueueToApi(queue dlq.Queue) api.DeadLetterQueue {
+	return api.DeadLetterQueue{
+		Stream:   queue.Stream,
+		Messages: queue.Messages,
+		Bytes:    queue.Bytes,
+		OldestAt: queue.FirstAt,
+		NewestAt: queue.LastAt,
+	}
+}
+
+func deadLetterMessageToApi(msg dlq.Message, withPayload bool) api.DeadLetterMessage {
+	res := api.DeadLetterMessage{
+		Stream:   msg.Stream,
+		Sequence: msg.Sequence,
+		Subject:  msg.Subject,
+		Consumer: msg.Consumer,
+		Reason:   msg.Reason,
+		Attempts: msg.Attempts,
+		FailedAt: msg.FailedAt,
+	}
+	if withPayload {
+		res.Payload = string(msg.Data)
+	}
+	return res
+}
@@ -0,0 +1,191 @@
+package describe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	apiAuth "github.com/kaytu-io/kaytu-util/pkg/api"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/runner"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	apiOnboard "github.com/kaytu-io/open-governance/pkg/onboard/api"
+	"github.com/labstack/echo/v4"
+)
+
+// permissionDeniedErrorCodes are the error codes of describe jobs which failed because the credential lacks access
+var permissionDeniedErrorCodes = map[string]bool{
+	"AccessDenied":                    true,
+	"AccessDeniedException":           true,
+	"AuthorizationError":              true,
+	"AuthorizationFailed":             true,
+	"AuthFailure":                     true,
+	"Forbidden":                       true,
+	"InsufficientPrivilegesException": true,
+	"InvalidAuthenticationToken":      true,
+	"UnauthorizedOperation":           true,
+	"401":                             true,
+	"403":                             true,
+}
+
+func coverageStatus(job model.DescribeConnectionJob) api.DiscoveryCoverageStatus {
+	if job.Status == api.DescribeResourceJobSucceeded {
+		return api.DiscoveryCoverageDescribed
+	}
+	if permissionDeniedErrorCodes[job.ErrorCode] {
+		return api.DiscoveryCoveragePermissionDenied
+	}
+	return api.DiscoveryCoverageFailed
+}
+
+// DiscoveryCoverageReport tells which resource types of the connection are described by discovery
+// and which controls cannot be evaluated because they read resource types that are not
+func (s *Scheduler) DiscoveryCoverageReport(connectionID string) (*api.DiscoveryCoverageReport, error) {
+	clientCtx := &httpclient.Context{UserRole: apiAuth.InternalRole}
+	connection, err := s.onboardClient.GetSource(clientCtx, connectionID)
+	if err != nil {
+		// onboard answers unknown and malformed connection ids with client errors
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code >= 400 && httpErr.Code < 500 {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "connection not found")
+		}
+		return nil, err
+	}
+	if connection == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "connection not found")
+	}
+
+	discoveryResourceTypes, err := s.ListDiscoveryResourceTypes()
+	if err != nil {
+		return nil, err
+	}
+	var resourceTypes []string
+	switch connection.Connector {
+	case source.CloudAWS:
+		resourceTypes = discoveryResourceTypes.AWSResourceTypes
+	case source.CloudAzure:
+		resourceTypes = discoveryResourceTypes.AzureResourceTypes
+	default:
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported connector %s", connection.Connector))
+	}
+
+	jobs, err := s.db.ListLastFinishedDescribeConnectionJobs(connectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	controls, err := s.complianceClient.ListControl(clientCtx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDiscoveryCoverageReport(*connection, resourceTypes, jobs, controls), nil
+}
+
+// buildDiscoveryCoverageReport builds the coverage report of the connection from the last finished describe job of each resource type
+func buildDiscoveryCoverageReport(connection apiOnboard.Connection, resourceTypes []string, jobs []model.DescribeConnectionJob, controls []complianceApi.Control) *api.DiscoveryCoverageReport {
+	lastJobs := make(map[string]model.DescribeConnectionJob)
+	for _, job := range jobs {
+		lastJobs[strings.ToLower(job.ResourceType)] = job
+	}
+
+	report := api.DiscoveryCoverageReport{
+		ConnectionID:         connection.ID.String(),
+		ProviderConnectionID: connection.ConnectionID,
+		Connector:            connection.Connector.String(),
+		TotalResourceTypes:   len(resourceTypes),
+	}
+	if policies, ok := connection.Credential.Metadata["attached_policies"].([]any); ok {
+		for _, policy := range policies {
+			if p, ok := policy.(string); ok {
+				report.AttachedPolicies = append(report.AttachedPolicies, p)
+			}
+		}
+	}
+
+	described := make(map[string]bool)
+	for _, resourceType := range resourceTypes {
+		coverage := api.DiscoveryCoverageResourceType{
+			ResourceType: resourceType,
+			Status:       api.DiscoveryCoverageNeverAttempted,
+		}
+		if job, ok := lastJobs[strings.ToLower(resourceType)]; ok {
+			job := job
+			coverage.Status = coverageStatus(job)
+			coverage.LastJobID = &job.ID
+			coverage.LastJobStatus = string(job.Status)
+			coverage.LastDescribedAt = &job.UpdatedAt
+			coverage.ErrorCode = job.ErrorCode
+			coverage.FailureMessage = job.FailureMessage
+			coverage.DescribedResourceCount = job.DescribedResourceCount
+		}
+
+		switch coverage.Status {
+		case api.DiscoveryCoverageDescribed:
+			report.DescribedCount++
+			described[strings.ToLower(resourceType)] = true
+		case api.DiscoveryCoveragePermissionDenied:
+			report.PermissionDeniedCount++
+		case api.DiscoveryCoverageFailed:
+			report.FailedCount++
+		case api.DiscoveryCoverageNeverAttempted:
+			report.NeverAttemptedCount++
+		}
+		report.ResourceTypes = append(report.ResourceTypes, coverage)
+	}
+	if report.TotalResourceTypes > 0 {
+		report.CoveragePercentage = float64(report.DescribedCount) / float64(report.TotalResourceTypes) * 100.0
+	}
+
+	for _, control := range controls {
+		if control.ManualVerification || control.Query == nil {
+			continue
+		}
+		supportsConnector := false
+		for _, connector := range control.Query.Connector {
+			if connector == connection.Connector {
+				supportsConnector = true
+				break
+			}
+		}
+		if !supportsConnector {
+			continue
+		}
+
+		tables := control.Query.ListOfTables
+		if control.Query.PrimaryTable != nil {
+			tables = append([]string{*control.Query.PrimaryTable}, tables...)
+		}
+		missing := make(map[string]bool)
+		for _, table := range tables {
+			resourceType, _ := runner.GetResourceTypeFromTableName(table, control.Query.Connector)
+			if resourceType == "" || described[strings.ToLower(resourceType)] {
+				continue
+			}
+			missing[resourceType] = true
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		unevaluable := api.DiscoveryCoverageUnevaluableControl{
+			ControlID: control.ID,
+			Title:     control.Title,
+		}
+		for resourceType := range missing {
+			unevaluable.ResourceTypes = append(unevaluable.ResourceTypes, resourceType)
+		}
+		sort.Strings(unevaluable.ResourceTypes)
+		report.UnevaluableControls = append(report.UnevaluableControls, unevaluable)
+	}
+	sort.Slice(report.UnevaluableControls, func(i, j int) bool {
+		return report.UnevaluableControls[i].ControlID < report.UnevaluableControls[j].ControlID
+	})
+
+	return &report
+}
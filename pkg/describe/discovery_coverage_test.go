@@ -0,0 +1,163 @@
+package describe
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/kaytu-util/pkg/httpserver"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	apiOnboard "github.com/kaytu-io/open-governance/pkg/onboard/api"
+	onboardClient "github.com/kaytu-io/open-governance/pkg/onboard/client"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testOnboardClient answers GetSource from a fixed set of connections the way onboard does,
+// the embedded interface panics on the methods not used by the tests
+type testOnboardClient struct {
+	onboardClient.OnboardServiceClient
+	connections map[string]apiOnboard.Connection
+}
+
+func (c testOnboardClient) GetSource(_ *httpclient.Context, sourceID string) (*apiOnboard.Connection, error) {
+	connection, ok := c.connections[sourceID]
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "source not found")
+	}
+	return &connection, nil
+}
+
+func testControl(id string, connector source.Type, primaryTable string, tables ...string) complianceApi.Control {
+	return complianceApi.Control{
+		ID:    id,
+		Title: id,
+		Query: &complianceApi.Query{Connector: []source.Type{connector}, PrimaryTable: &primaryTable, ListOfTables: tables},
+	}
+}
+
+func TestCoverageStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		job  model.DescribeConnectionJob
+		want api.DiscoveryCoverageStatus
+	}{
+		{name: "succeeded", job: model.DescribeConnectionJob{Status: api.DescribeResourceJobSucceeded}, want: api.DiscoveryCoverageDescribed},
+		{name: "succeeded with a stale error code", job: model.DescribeConnectionJob{Status: api.DescribeResourceJobSucceeded, ErrorCode: "AccessDenied"}, want: api.DiscoveryCoverageDescribed},
+		{name: "aws access denied", job: model.DescribeConnectionJob{Status: api.DescribeResourceJobFailed, ErrorCode: "AccessDeniedException"}, want: api.DiscoveryCoveragePermissionDenied},
+		{name: "azure authorization failed", job: model.DescribeConnectionJob{Status: api.DescribeResourceJobFailed, ErrorCode: "AuthorizationFailed"}, want: api.DiscoveryCoveragePermissionDenied},
+		{name: "http forbidden", job: model.DescribeConnectionJob{Status: api.DescribeResourceJobFailed, ErrorCode: "403"}, want: api.DiscoveryCoveragePermissionDenied},
+		{name: "throttled", job: model.DescribeConnectionJob{Status: api.DescribeResourceJobFailed, ErrorCode: "Throttling"}, want: api.DiscoveryCoverageFailed},
+		{name: "no error code", job: model.DescribeConnectionJob{Status: api.DescribeResourceJobFailed}, want: api.DiscoveryCoverageFailed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, coverageStatus(tc.job))
+		})
+	}
+}
+
+func TestBuildDiscoveryCoverageReport(t *testing.T) {
+	connection := apiOnboard.Connection{
+		ID:           uuid.MustParse("8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"),
+		ConnectionID: "123456789012",
+		Connector:    source.CloudAWS,
+		Credential: apiOnboard.Credential{Metadata: map[string]any{
+			"attached_policies": []any{"arn:aws:iam::aws:policy/SecurityAudit", 42},
+		}},
+	}
+	describedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	jobs := []model.DescribeConnectionJob{
+		{ID: 1, ResourceType: "aws::ec2::instance", Status: api.DescribeResourceJobSucceeded, DescribedResourceCount: 12, UpdatedAt: describedAt},
+		{ID: 2, ResourceType: "AWS::S3::Bucket", Status: api.DescribeResourceJobFailed, ErrorCode: "AccessDenied", FailureMessage: "not authorized", UpdatedAt: describedAt},
+		{ID: 3, ResourceType: "AWS::IAM::Role", Status: api.DescribeResourceJobFailed, ErrorCode: "Throttling", UpdatedAt: describedAt},
+	}
+	manual := testControl("aws_manual", source.CloudAWS, "aws_s3_bucket")
+	manual.ManualVerification = true
+	controls := []complianceApi.Control{
+		testControl("aws_s3_encrypted", source.CloudAWS, "aws_s3_bucket"),
+		testControl("aws_ec2_public", source.CloudAWS, "aws_ec2_instance"),
+		testControl("aws_ec2_role", source.CloudAWS, "aws_ec2_instance", "aws_iam_role", "aws_unknown_table"),
+		testControl("azure_vm", source.CloudAzure, "azure_compute_virtual_machine"),
+		manual,
+		{ID: "aws_without_query"},
+	}
+
+	report := buildDiscoveryCoverageReport(connection, []string{"AWS::EC2::Instance", "AWS::S3::Bucket", "AWS::IAM::Role", "AWS::EC2::Volume"}, jobs, controls)
+
+	assert.Equal(t, "8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8", report.ConnectionID)
+	assert.Equal(t, "123456789012", report.ProviderConnectionID)
+	assert.Equal(t, []string{"arn:aws:iam::aws:policy/SecurityAudit"}, report.AttachedPolicies)
+	assert.Equal(t, 4, report.TotalResourceTypes)
+	assert.Equal(t, 1, report.DescribedCount)
+	assert.Equal(t, 1, report.PermissionDeniedCount)
+	assert.Equal(t, 1, report.FailedCount)
+	assert.Equal(t, 1, report.NeverAttemptedCount)
+	assert.Equal(t, 25.0, report.CoveragePercentage)
+
+	require.Len(t, report.ResourceTypes, 4)
+	ec2 := report.ResourceTypes[0]
+	assert.Equal(t, api.DiscoveryCoverageDescribed, ec2.Status)
+	require.NotNil(t, ec2.LastJobID)
+	assert.Equal(t, uint(1), *ec2.LastJobID)
+	assert.Equal(t, int64(12), ec2.DescribedResourceCount)
+	s3 := report.ResourceTypes[1]
+	assert.Equal(t, api.DiscoveryCoveragePermissionDenied, s3.Status)
+	assert.Equal(t, "AccessDenied", s3.ErrorCode)
+	assert.Equal(t, "not authorized", s3.FailureMessage)
+	assert.Equal(t, api.DiscoveryCoverageFailed, report.ResourceTypes[2].Status)
+	volume := report.ResourceTypes[3]
+	assert.Equal(t, api.DiscoveryCoverageNeverAttempted, volume.Status)
+	assert.Nil(t, volume.LastJobID)
+
+	assert.Equal(t, []api.DiscoveryCoverageUnevaluableControl{
+		{ControlID: "aws_ec2_role", Title: "aws_ec2_role", ResourceTypes: []string{"AWS::IAM::Role"}},
+		{ControlID: "aws_s3_encrypted", Title: "aws_s3_encrypted", ResourceTypes: []string{"AWS::S3::Bucket"}},
+	}, report.UnevaluableControls)
+}
+
+func TestBuildDiscoveryCoverageReport_NoResourceTypes(t *testing.T) {
+	report := buildDiscoveryCoverageReport(apiOnboard.Connection{Connector: source.CloudAzure}, nil, nil, nil)
+	assert.Zero(t, report.TotalResourceTypes)
+	assert.Zero(t, report.CoveragePercentage)
+	assert.Empty(t, report.UnevaluableControls)
+}
+
+func TestGetDiscoveryCoverage_Access(t *testing.T) {
+	h := HttpServer{Scheduler: &Scheduler{
+		logger:        zap.NewNop(),
+		onboardClient: testOnboardClient{connections: map[string]apiOnboard.Connection{}},
+	}}
+	newContext := func(connectionID, scope string) echo.Context {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if scope != "" {
+			req.Header.Set(httpserver.XKaytuUserConnectionsScope, scope)
+		}
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+		ctx.SetParamNames("connection_id")
+		ctx.SetParamValues(connectionID)
+		return ctx
+	}
+
+	t.Run("connection outside the user scope", func(t *testing.T) {
+		err := h.GetDiscoveryCoverage(newContext("conn-1", "conn-2,conn-3"))
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+	})
+
+	t.Run("unknown connection", func(t *testing.T) {
+		err := h.GetDiscoveryCoverage(newContext("conn-1", "conn-1"))
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
@@ -0,0 +1,225 @@
+package describe
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/kaytu-io/open-governance/pkg/describe/es"
+	"go.uber.org/zap"
+)
+
+const defaultResourceTypeConcurrency = 25
+
+// discoveryBudgetResetTime is when the daily call budgets reset
+func discoveryBudgetResetTime(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+func sumMatchingCounts(limit model.DiscoveryRateLimit, counts []db.ConnectionResourceTypeCount) int64 {
+	var sum int64
+	for _, c := range counts {
+		if limit.Matches(c.ConnectionID, c.ResourceType) {
+			sum += c.Count
+		}
+	}
+	return sum
+}
+
+// resourceTypeRateLimit returns the concurrency limit scoped only to the resource type, if any
+func resourceTypeRateLimit(limits []model.DiscoveryRateLimit, resourceType string) *model.DiscoveryRateLimit {
+	for _, limit := range limits {
+		if limit.ConnectionID == nil && limit.ResourceType != nil && limit.MaxConcurrentJobs > 0 && limit.Matches("", resourceType) {
+			return &limit
+		}
+	}
+	return nil
+}
+
+// resourceTypeConcurrency is the concurrency limit of a resource type across connections,
+// a rate limit scoped only to the resource type overrides the built-in limits
+func resourceTypeConcurrency(limits []model.DiscoveryRateLimit, resourceType string) int64 {
+	if limit := resourceTypeRateLimit(limits, resourceType); limit != nil {
+		return int64(limit.MaxConcurrentJobs)
+	}
+	if m, ok := es.ResourceRateLimit[resourceType]; ok {
+		return int64(m)
+	}
+	return defaultResourceTypeConcurrency
+}
+
+// estimatedDiscoveryCalls is the number of cloud API calls a job is expected to make, one list call plus
+// one call per resource found by the last succeeded job of the same connection and resource type
+func estimatedDiscoveryCalls(dc model.DescribeConnectionJob, lastResourceCounts []db.ConnectionResourceTypeCount) int64 {
+	for _, c := range lastResourceCounts {
+		if c.ConnectionID == dc.ConnectionID && c.ResourceType == dc.ResourceType {
+			return 1 + c.Count
+		}
+	}
+	return 1
+}
+
+// discoveryLimiter decides which jobs of a describe cycle are allowed to run
+type discoveryLimiter struct {
+	limits []model.DiscoveryRateLimit
+	// running counts the queued and running jobs of manual and scheduled discovery
+	running []db.ConnectionResourceTypeCount
+	// runningInQueue counts the queued and running jobs of the describer queue the cycle dispatches to
+	runningInQueue []db.ConnectionResourceTypeCount
+	// callsToday is the estimated API calls made since the budgets last reset
+	callsToday         []db.ConnectionResourceTypeCount
+	lastResourceCounts []db.ConnectionResourceTypeCount
+
+	allowed      []model.DescribeConnectionJob
+	allowedCalls map[uint]int64
+}
+
+func (l *discoveryLimiter) allowedMatching(limit model.DiscoveryRateLimit) (jobs, calls int64) {
+	for _, dc := range l.allowed {
+		if limit.Matches(dc.ConnectionID, dc.ResourceType) {
+			jobs++
+			calls += l.allowedCalls[dc.ID]
+		}
+	}
+	return jobs, calls
+}
+
+// admit returns whether dc can run now. A job is left for the next cycle when it is over a concurrency limit,
+// and the exhausted rate limit is returned when the job would take a daily call budget over its limit.
+func (l *discoveryLimiter) admit(dc model.DescribeConnectionJob) (bool, *model.DiscoveryRateLimit) {
+	rtLimit := model.DiscoveryRateLimit{ResourceType: &dc.ResourceType}
+	rtRunning := l.runningInQueue
+	if limit := resourceTypeRateLimit(l.limits, dc.ResourceType); limit != nil {
+		// configured limits apply across manual and scheduled discovery
+		rtRunning = l.running
+	}
+	rtAllowed, _ := l.allowedMatching(rtLimit)
+	if sumMatchingCounts(rtLimit, rtRunning)+rtAllowed >= resourceTypeConcurrency(l.limits, dc.ResourceType) {
+		return false, nil
+	}
+
+	calls := estimatedDiscoveryCalls(dc, l.lastResourceCounts)
+	throttled := false
+	for _, limit := range l.limits {
+		if !limit.Matches(dc.ConnectionID, dc.ResourceType) {
+			continue
+		}
+		allowedJobs, allowedCalls := l.allowedMatching(limit)
+		if limit.MaxConcurrentJobs > 0 && sumMatchingCounts(limit, l.running)+allowedJobs >= int64(limit.MaxConcurrentJobs) {
+			throttled = true
+		}
+		if limit.DailyCallBudget > 0 {
+			used := sumMatchingCounts(limit, l.callsToday) + allowedCalls
+			// a job estimated over the whole budget still runs once the day's budget is untouched
+			if used >= limit.DailyCallBudget || (used > 0 && used+calls > limit.DailyCallBudget) {
+				return false, &limit
+			}
+		}
+	}
+	if throttled {
+		return false, nil
+	}
+
+	if l.allowedCalls == nil {
+		l.allowedCalls = map[uint]int64{}
+	}
+	l.allowed = append(l.allowed, dc)
+	l.allowedCalls[dc.ID] = calls
+	return true, nil
+}
+
+// applyDiscoveryRateLimits returns the jobs allowed to run now. Jobs over a concurrency limit are left for the next cycle,
+// jobs over a daily call budget are deferred until the budget resets.
+func (s *Scheduler) applyDiscoveryRateLimits(dcs []model.DescribeConnectionJob, manuals bool) ([]model.DescribeConnectionJob, error) {
+	limits, err := s.db.ListDiscoveryRateLimits()
+	if err != nil {
+		return nil, err
+	}
+	limiter := discoveryLimiter{limits: limits}
+	limiter.running, err = s.db.CountRunningDescribeJobsPerConnectionResourceType(nil)
+	if err != nil {
+		return nil, err
+	}
+	limiter.runningInQueue, err = s.db.CountRunningDescribeJobsPerConnectionResourceType(&manuals)
+	if err != nil {
+		return nil, err
+	}
+
+	resetsAt := discoveryBudgetResetTime(time.Now())
+	for _, limit := range limits {
+		if limit.DailyCallBudget > 0 {
+			// budgets are shared between manual and scheduled discovery
+			limiter.callsToday, err = s.db.CountDiscoveryCallsSince(resetsAt.AddDate(0, 0, -1))
+			if err != nil {
+				return nil, err
+			}
+			var connectionIDs []string
+			for _, dc := range dcs {
+				if !slices.Contains(connectionIDs, dc.ConnectionID) {
+					connectionIDs = append(connectionIDs, dc.ConnectionID)
+				}
+			}
+			limiter.lastResourceCounts, err = s.db.LastDescribedResourceCounts(connectionIDs)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	deferred := map[uint][]uint{}
+	for _, dc := range dcs {
+		if _, exhausted := limiter.admit(dc); exhausted != nil {
+			deferred[exhausted.ID] = append(deferred[exhausted.ID], dc.ID)
+		}
+	}
+
+	for limitID, ids := range deferred {
+		msg := fmt.Sprintf("daily discovery call budget of rate limit %d is exhausted, deferred until %s", limitID, resetsAt.Format(time.RFC3339))
+		if err := s.db.DeferDescribeConnectionJobs(ids, resetsAt, msg, api.DiscoveryBudgetExhaustedErrorCode); err != nil {
+			return nil, err
+		}
+		DescribeResourceJobsCount.WithLabelValues("deferred", "budget_exhausted").Add(float64(len(ids)))
+		s.logger.Info("deferred describe jobs over daily call budget", zap.Uint("rate_limit_id", limitID), zap.Int("count", len(ids)))
+	}
+
+	return limiter.allowed, nil
+}
+
+// discoveryRateLimitUsage reports the usage of each rate limit over the current budget day
+func (s *Scheduler) discoveryRateLimitUsage(limits []model.DiscoveryRateLimit) ([]api.DiscoveryRateLimitUsage, error) {
+	running, err := s.db.CountRunningDescribeJobsPerConnectionResourceType(nil)
+	if err != nil {
+		return nil, err
+	}
+	resetsAt := discoveryBudgetResetTime(time.Now())
+	callsToday, err := s.db.CountDiscoveryCallsSince(resetsAt.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	deferred, err := s.db.CountDeferredDescribeJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make([]api.DiscoveryRateLimitUsage, 0, len(limits))
+	for _, limit := range limits {
+		usage := api.DiscoveryRateLimitUsage{
+			RateLimit:    limit.ToApi(),
+			RunningJobs:  sumMatchingCounts(limit, running),
+			CallsToday:   sumMatchingCounts(limit, callsToday),
+			DeferredJobs: sumMatchingCounts(limit, deferred),
+			ResetsAt:     resetsAt,
+		}
+		if limit.DailyCallBudget > 0 {
+			remaining := max(limit.DailyCallBudget-usage.CallsToday, 0)
+			usage.RemainingCalls = &remaining
+		}
+		usages = append(usages, usage)
+	}
+	return usages, nil
+}
@@ -0,0 +1,218 @@
+package describe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/db"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func testRateLimit(id uint, connectionID, resourceType *string, maxConcurrentJobs int, dailyCallBudget int64) model.DiscoveryRateLimit {
+	return model.DiscoveryRateLimit{
+		Model:             gorm.Model{ID: id},
+		ConnectionID:      connectionID,
+		ResourceType:      resourceType,
+		MaxConcurrentJobs: maxConcurrentJobs,
+		DailyCallBudget:   dailyCallBudget,
+	}
+}
+
+func testDescribeJob(id uint, connectionID, resourceType string) model.DescribeConnectionJob {
+	return model.DescribeConnectionJob{
+		ID:           id,
+		ConnectionID: connectionID,
+		ResourceType: resourceType,
+	}
+}
+
+func admittedJobIDs(limiter *discoveryLimiter, dcs []model.DescribeConnectionJob) ([]uint, map[uint][]uint) {
+	var allowed []uint
+	deferred := map[uint][]uint{}
+	for _, dc := range dcs {
+		ok, exhausted := limiter.admit(dc)
+		if ok {
+			allowed = append(allowed, dc.ID)
+		}
+		if exhausted != nil {
+			deferred[exhausted.ID] = append(deferred[exhausted.ID], dc.ID)
+		}
+	}
+	return allowed, deferred
+}
+
+func TestDiscoveryBudgetResetTime(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{name: "midday", now: time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), want: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{name: "midnight", now: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), want: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{name: "end of month", now: time.Date(2024, 2, 29, 23, 59, 0, 0, time.UTC), want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "other time zone", now: time.Date(2024, 3, 11, 2, 0, 0, 0, loc), want: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, discoveryBudgetResetTime(tc.now))
+		})
+	}
+}
+
+func TestDiscoveryRateLimit_Matches(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit model.DiscoveryRateLimit
+		want  bool
+	}{
+		{name: "no scope", limit: model.DiscoveryRateLimit{}, want: true},
+		{name: "connection", limit: model.DiscoveryRateLimit{ConnectionID: strPtr("conn-1")}, want: true},
+		{name: "other connection", limit: model.DiscoveryRateLimit{ConnectionID: strPtr("conn-2")}, want: false},
+		{name: "resource type is case insensitive", limit: model.DiscoveryRateLimit{ResourceType: strPtr("aws::iam::role")}, want: true},
+		{name: "other resource type", limit: model.DiscoveryRateLimit{ResourceType: strPtr("AWS::S3::Bucket")}, want: false},
+		{name: "connection and other resource type", limit: model.DiscoveryRateLimit{ConnectionID: strPtr("conn-1"), ResourceType: strPtr("AWS::S3::Bucket")}, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.limit.Matches("conn-1", "AWS::IAM::Role"))
+		})
+	}
+}
+
+func TestSumMatchingCounts(t *testing.T) {
+	counts := []db.ConnectionResourceTypeCount{
+		{ConnectionID: "conn-1", ResourceType: "AWS::IAM::Role", Count: 2},
+		{ConnectionID: "conn-1", ResourceType: "AWS::S3::Bucket", Count: 3},
+		{ConnectionID: "conn-2", ResourceType: "AWS::IAM::Role", Count: 5},
+	}
+	assert.Equal(t, int64(10), sumMatchingCounts(model.DiscoveryRateLimit{}, counts))
+	assert.Equal(t, int64(5), sumMatchingCounts(model.DiscoveryRateLimit{ConnectionID: strPtr("conn-1")}, counts))
+	assert.Equal(t, int64(7), sumMatchingCounts(model.DiscoveryRateLimit{ResourceType: strPtr("AWS::IAM::Role")}, counts))
+	assert.Equal(t, int64(0), sumMatchingCounts(model.DiscoveryRateLimit{ConnectionID: strPtr("conn-3")}, counts))
+}
+
+func TestResourceTypeConcurrency(t *testing.T) {
+	limits := []model.DiscoveryRateLimit{
+		testRateLimit(1, strPtr("conn-1"), strPtr("AWS::IAM::Role"), 1, 0),
+		testRateLimit(2, nil, strPtr("AWS::IAM::Role"), 0, 100),
+		testRateLimit(3, nil, strPtr("AWS::IAM::Role"), 4, 0),
+	}
+	assert.Equal(t, int64(4), resourceTypeConcurrency(limits, "AWS::IAM::Role"))
+	assert.Equal(t, int64(defaultResourceTypeConcurrency), resourceTypeConcurrency(limits, "AWS::S3::Bucket"))
+	assert.Nil(t, resourceTypeRateLimit(limits, "AWS::S3::Bucket"))
+}
+
+func TestEstimatedDiscoveryCalls(t *testing.T) {
+	last := []db.ConnectionResourceTypeCount{
+		{ConnectionID: "conn-1", ResourceType: "AWS::IAM::Role", Count: 40},
+	}
+	assert.Equal(t, int64(41), estimatedDiscoveryCalls(testDescribeJob(1, "conn-1", "AWS::IAM::Role"), last))
+	assert.Equal(t, int64(1), estimatedDiscoveryCalls(testDescribeJob(2, "conn-1", "AWS::S3::Bucket"), last))
+	assert.Equal(t, int64(1), estimatedDiscoveryCalls(testDescribeJob(3, "conn-2", "AWS::IAM::Role"), last))
+}
+
+func TestDiscoveryLimiter_ConnectionConcurrency(t *testing.T) {
+	limiter := &discoveryLimiter{
+		limits: []model.DiscoveryRateLimit{testRateLimit(1, strPtr("conn-1"), nil, 3, 0)},
+		// two jobs of the connection are running, one of them on the other describer queue
+		running: []db.ConnectionResourceTypeCount{
+			{ConnectionID: "conn-1", ResourceType: "AWS::IAM::Role", Count: 1},
+			{ConnectionID: "conn-1", ResourceType: "AWS::S3::Bucket", Count: 1},
+		},
+		runningInQueue: []db.ConnectionResourceTypeCount{
+			{ConnectionID: "conn-1", ResourceType: "AWS::IAM::Role", Count: 1},
+		},
+	}
+	allowed, deferred := admittedJobIDs(limiter, []model.DescribeConnectionJob{
+		testDescribeJob(1, "conn-1", "AWS::EC2::Instance"),
+		testDescribeJob(2, "conn-1", "AWS::EC2::Volume"),
+		testDescribeJob(3, "conn-2", "AWS::EC2::Volume"),
+	})
+	assert.Equal(t, []uint{1, 3}, allowed)
+	assert.Empty(t, deferred)
+}
+
+func TestDiscoveryLimiter_ResourceTypeConcurrency(t *testing.T) {
+	running := []db.ConnectionResourceTypeCount{
+		{ConnectionID: "conn-1", ResourceType: "AWS::IAM::Role", Count: 2},
+	}
+	dcs := []model.DescribeConnectionJob{
+		testDescribeJob(1, "conn-2", "AWS::IAM::Role"),
+		testDescribeJob(2, "conn-3", "AWS::IAM::Role"),
+	}
+
+	t.Run("configured limit counts both queues", func(t *testing.T) {
+		limiter := &discoveryLimiter{
+			limits:  []model.DiscoveryRateLimit{testRateLimit(1, nil, strPtr("AWS::IAM::Role"), 3, 0)},
+			running: running,
+		}
+		allowed, _ := admittedJobIDs(limiter, dcs)
+		assert.Equal(t, []uint{1}, allowed)
+	})
+
+	t.Run("built-in limit counts the cycle queue", func(t *testing.T) {
+		limiter := &discoveryLimiter{running: running}
+		allowed, _ := admittedJobIDs(limiter, dcs)
+		assert.Equal(t, []uint{1, 2}, allowed)
+	})
+}
+
+func TestDiscoveryLimiter_DailyCallBudget(t *testing.T) {
+	limits := []model.DiscoveryRateLimit{testRateLimit(7, strPtr("conn-1"), nil, 0, 100)}
+	lastResourceCounts := []db.ConnectionResourceTypeCount{
+		{ConnectionID: "conn-1", ResourceType: "AWS::IAM::Role", Count: 59},
+		{ConnectionID: "conn-1", ResourceType: "AWS::S3::Bucket", Count: 30},
+		{ConnectionID: "conn-1", ResourceType: "AWS::EC2::Instance", Count: 500},
+	}
+
+	t.Run("calls of allowed jobs are counted", func(t *testing.T) {
+		limiter := &discoveryLimiter{
+			limits:             limits,
+			callsToday:         []db.ConnectionResourceTypeCount{{ConnectionID: "conn-1", ResourceType: "AWS::EC2::Volume", Count: 10}},
+			lastResourceCounts: lastResourceCounts,
+		}
+		allowed, deferred := admittedJobIDs(limiter, []model.DescribeConnectionJob{
+			testDescribeJob(1, "conn-1", "AWS::IAM::Role"),
+			testDescribeJob(2, "conn-1", "AWS::S3::Bucket"),
+			testDescribeJob(3, "conn-1", "AWS::EC2::Volume"),
+			testDescribeJob(4, "conn-2", "AWS::S3::Bucket"),
+		})
+		// 10 used, the role job takes 60 and the bucket job would take 31 more
+		assert.Equal(t, []uint{1, 3, 4}, allowed)
+		assert.Equal(t, map[uint][]uint{7: {2}}, deferred)
+	})
+
+	t.Run("exhausted budget defers every job", func(t *testing.T) {
+		limiter := &discoveryLimiter{
+			limits:     limits,
+			callsToday: []db.ConnectionResourceTypeCount{{ConnectionID: "conn-1", ResourceType: "AWS::IAM::Role", Count: 100}},
+		}
+		allowed, deferred := admittedJobIDs(limiter, []model.DescribeConnectionJob{
+			testDescribeJob(1, "conn-1", "AWS::EC2::Volume"),
+		})
+		assert.Empty(t, allowed)
+		assert.Equal(t, map[uint][]uint{7: {1}}, deferred)
+	})
+
+	t.Run("job over the whole budget runs on an untouched budget", func(t *testing.T) {
+		limiter := &discoveryLimiter{
+			limits:             limits,
+			lastResourceCounts: lastResourceCounts,
+		}
+		allowed, deferred := admittedJobIDs(limiter, []model.DescribeConnectionJob{
+			testDescribeJob(1, "conn-1", "AWS::EC2::Instance"),
+			testDescribeJob(2, "conn-1", "AWS::EC2::Volume"),
+		})
+		assert.Equal(t, []uint{1}, allowed)
+		require.Len(t, deferred[7], 1)
+		assert.Equal(t, uint(2), deferred[7][0])
+	})
+}
@@ -0,0 +1,99 @@
+package describe
+
+import (
+	"time"
+
+	"github.com/kaytu-io/kaytu-aws-describer/aws"
+	"github.com/kaytu-io/kaytu-azure-describer/azure"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	apiOnboard "github.com/kaytu-io/open-governance/pkg/onboard/api"
+)
+
+// connectionTags returns the organization tags of an AWS account or the tags of an Azure subscription
+func connectionTags(connection apiOnboard.Connection) map[string][]string {
+	tags := make(map[string][]string)
+	if orgTags, ok := connection.Metadata["organization_tags"].(map[string]any); ok {
+		for k, v := range orgTags {
+			if value, ok := v.(string); ok {
+				tags[k] = append(tags[k], value)
+			}
+		}
+	}
+	if subTags, ok := connection.Metadata["subscription_tags"].(map[string]any); ok {
+		for k, v := range subTags {
+			values, ok := v.([]any)
+			if !ok {
+				continue
+			}
+			for _, value := range values {
+				if value, ok := value.(string); ok {
+					tags[k] = append(tags[k], value)
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// resolveDiscoverySchedulePolicy returns the most specific policy matching the discovery, policies are expected
+// to be sorted by priority so the first one wins between policies of the same specificity
+func resolveDiscoverySchedulePolicy(policies []model.DiscoverySchedulePolicy, connection apiOnboard.Connection, resourceType string) *model.DiscoverySchedulePolicy {
+	var tags map[string][]string
+	var resolved *model.DiscoverySchedulePolicy
+	for i, policy := range policies {
+		if policy.ConnectionTagKey != nil && tags == nil {
+			tags = connectionTags(connection)
+		}
+		if !policy.Matches(connection.ID.String(), tags, resourceType) {
+			continue
+		}
+		if resolved == nil || policy.Specificity() > resolved.Specificity() {
+			resolved = &policies[i]
+		}
+	}
+	return resolved
+}
+
+// defaultDiscoveryInterval is the global interval used for resource types without a schedule policy
+func (s *Scheduler) defaultDiscoveryInterval(connector source.Type, resourceType string) time.Duration {
+	var fastDiscovery, costDiscovery bool
+	switch connector {
+	case source.CloudAWS:
+		if rt, _ := aws.GetResourceType(resourceType); rt != nil {
+			fastDiscovery, costDiscovery = rt.FastDiscovery, rt.CostDiscovery
+		}
+	case source.CloudAzure:
+		if rt, _ := azure.GetResourceType(resourceType); rt != nil {
+			fastDiscovery, costDiscovery = rt.FastDiscovery, rt.CostDiscovery
+		}
+	}
+	if fastDiscovery {
+		return s.describeIntervalHours
+	} else if costDiscovery {
+		return s.costDiscoveryIntervalHours
+	}
+	return s.fullDiscoveryIntervalHours
+}
+
+// nextDiscoveryRuns returns the next count run times after lastRun, either from the policy or the interval
+func nextDiscoveryRuns(policy *model.DiscoverySchedulePolicy, interval time.Duration, lastRun time.Time, count int) ([]time.Time, error) {
+	runs := make([]time.Time, 0, count)
+	next := lastRun
+	for len(runs) < count {
+		if policy != nil {
+			var err error
+			next, err = policy.NextRunAfter(next)
+			if err != nil {
+				return nil, err
+			}
+			if next.IsZero() {
+				break
+			}
+		} else {
+			next = next.Add(interval)
+		}
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
@@ -0,0 +1,100 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/kaytu-io/kaytu-util/pkg/es"
+	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type ResourcesFetchResponse struct {
+	Hits struct {
+		Hits []struct {
+			ID     string      `json:"_id"`
+			Source es.Resource `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// GetResourcesByIDs returns the resources of a connection with the given ids from the resource type index
+func GetResourcesByIDs(ctx context.Context, client kaytu.Client, sourceID, resourceType string, ids []string) ([]es.Resource, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	root := map[string]any{
+		"size": len(ids),
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []map[string]any{
+					{"term": map[string]any{"source_id": sourceID}},
+					{"terms": map[string]any{"id": ids}},
+				},
+			},
+		},
+	}
+	queryBytes, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ResourcesFetchResponse
+	err = client.Search(ctx, es.ResourceTypeToESIndex(resourceType), string(queryBytes), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]es.Resource, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		resources = append(resources, hit.Source)
+	}
+	return resources, nil
+}
+
+type ResourceSnapshotsFetchResponse struct {
+	Hits struct {
+		Hits []struct {
+			ID     string                 `json:"_id"`
+			Source types.ResourceSnapshot `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// GetResourceSnapshots returns the last recorded snapshot of the given resources keyed by their kaytu resource id
+func GetResourceSnapshots(ctx context.Context, client kaytu.Client, resourceType string, ids []string) (map[string]types.ResourceSnapshot, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	root := map[string]any{
+		"size": len(ids),
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []map[string]any{
+					{"term": map[string]any{"resourceType": strings.ToLower(resourceType)}},
+					{"terms": map[string]any{"kaytuResourceID": ids}},
+				},
+			},
+		},
+	}
+	queryBytes, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ResourceSnapshotsFetchResponse
+	err = client.Search(ctx, types.ResourceSnapshotsIndex, string(queryBytes), &response)
+	if err != nil {
+		if kaytu.IsIndexNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	snapshots := make(map[string]types.ResourceSnapshot, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		snapshots[hit.Source.KaytuResourceID] = hit.Source
+	}
+	return snapshots, nil
+}
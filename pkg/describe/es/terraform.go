@@ -0,0 +1,77 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type TerraformResourcesResponse struct {
+	Hits struct {
+		Total kaytu.SearchTotal `json:"total"`
+		Hits  []struct {
+			ID     string                  `json:"_id"`
+			Source types.TerraformResource `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations struct {
+		DriftStatus struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int64  `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"drift_status"`
+	} `json:"aggregations"`
+}
+
+// ListTerraformResources returns the resources of the given sync of a connection, the drift status counts ignore the drift status filter
+func ListTerraformResources(ctx context.Context, client kaytu.Client, connectionID string, syncedAt int64, driftStatus, resourceType *string, size int) (*TerraformResourcesResponse, error) {
+	root := map[string]any{
+		"size": size,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": []map[string]any{
+					{"term": map[string]any{"connectionID": connectionID}},
+					{"term": map[string]any{"syncedAt": syncedAt}},
+				},
+			},
+		},
+		"sort": []map[string]any{
+			{"driftStatus": "asc"},
+			{"resourceID": "asc"},
+		},
+		"aggs": map[string]any{
+			"drift_status": map[string]any{
+				"terms": map[string]any{"field": "driftStatus", "size": 10},
+			},
+		},
+	}
+	var postFilters []map[string]any
+	if driftStatus != nil {
+		postFilters = append(postFilters, map[string]any{"term": map[string]any{"driftStatus": *driftStatus}})
+	}
+	if resourceType != nil {
+		postFilters = append(postFilters, map[string]any{"term": map[string]any{"resourceType": strings.ToLower(*resourceType)}})
+	}
+	if len(postFilters) > 0 {
+		root["post_filter"] = map[string]any{"bool": map[string]any{"filter": postFilters}}
+	}
+
+	queryBytes, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TerraformResourcesResponse
+	err = client.Search(ctx, types.TerraformResourcesIndex, string(queryBytes), &response)
+	if err != nil {
+		if kaytu.IsIndexNotFoundErr(err) {
+			return &response, nil
+		}
+		return nil, err
+	}
+	return &response, nil
+}
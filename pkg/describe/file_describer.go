@@ -0,0 +1,314 @@
+package describe
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	authApi "github.com/kaytu-io/kaytu-util/pkg/api"
+	es2 "github.com/kaytu-io/kaytu-util/pkg/es"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/kaytu-io/open-governance/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	fileDescriberBatchSize = 500
+	fileDescriberWorkers   = 4
+)
+
+var fileDescriberExtensions = map[string]bool{
+	".json":   true,
+	".ndjson": true,
+	".jsonl":  true,
+}
+
+// fileResource is a resource of an export, it has the shape of the resource index docs so
+// exports of the index (optionally wrapped in _source) can be loaded as they are
+type fileResource struct {
+	Source *fileResource `json:"_source,omitempty"`
+
+	ID            string            `json:"id"`
+	ARN           string            `json:"arn"`
+	Name          string            `json:"name"`
+	ResourceType  string            `json:"resource_type"`
+	ResourceGroup string            `json:"resource_group"`
+	Location      string            `json:"location"`
+	Description   any               `json:"description"`
+	Metadata      map[string]string `json:"metadata"`
+	CanonicalTags []es2.Tag         `json:"canonical_tags"`
+}
+
+// fileDescriberDirs returns the directories holding the exports of the connection,
+// they are named after the account id or the connection id
+func (s *Scheduler) fileDescriberDirs(dc model.DescribeConnectionJob) []string {
+	var dirs []string
+	for _, name := range []string{dc.AccountID, dc.ConnectionID} {
+		if name == "" {
+			continue
+		}
+		dir := filepath.Join(s.conf.FileDescriberDir, name)
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// fileDescriberFiles returns the export files to read for the resource type, files named after the
+// resource type (i.e. aws_ec2_instance.ndjson) are preferred over scanning every export of the connection
+func fileDescriberFiles(dirs []string, resourceType string) ([]string, error) {
+	var named, all []string
+	indexName := es2.ResourceTypeToESIndex(resourceType)
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(path))
+			if d.IsDir() || !fileDescriberExtensions[ext] {
+				return nil
+			}
+			if strings.ToLower(strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))) == indexName {
+				named = append(named, path)
+			}
+			all = append(all, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(named) > 0 {
+		return named, nil
+	}
+	return all, nil
+}
+
+// readFileResources calls fn for every resource of a JSON array, a single JSON object or NDJSON file
+func readFileResources(path string, fn func(fileResource) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	first, err := firstNonSpace(reader)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	decoder := json.NewDecoder(reader)
+	if first == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+	for decoder.More() {
+		var r fileResource
+		if err := decoder.Decode(&r); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if r.Source != nil {
+			r = *r.Source
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func firstNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if len(bytes.TrimSpace(b)) > 0 {
+			return b[0], nil
+		}
+		if _, err := reader.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// RunFileDescriber starts the workers running the file describe jobs, they are run off the scheduling cycle
+// since reading large exports would hold it up
+func (s *Scheduler) RunFileDescriber(ctx context.Context) {
+	for i := 0; i < fileDescriberWorkers; i++ {
+		utils.EnsureRunGoroutine(func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case dc := <-s.fileDescribeJobs:
+					if err := s.runFileDescribeJob(ctx, dc); err != nil {
+						s.logger.Error("failed to run file describe job", zap.Uint("jobID", dc.ID), zap.Error(err))
+						if err := s.db.UpdateDescribeConnectionJobStatus(dc.ID, api.DescribeResourceJobFailed, err.Error(), "", 0, 0); err != nil {
+							s.logger.Error("failed to update describe resource job status", zap.Uint("jobID", dc.ID), zap.Error(err))
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// enqueueFileDescribeJob hands the job to the file describer workers without waiting for it to run
+func (s *Scheduler) enqueueFileDescribeJob(dc model.DescribeConnectionJob) error {
+	select {
+	case s.fileDescribeJobs <- dc:
+		return nil
+	default:
+		return errors.New("file describer queue is full")
+	}
+}
+
+// runFileDescribeJob describes the resource type of the job from the exports on disk, the resources are
+// sent to es-sink like the cloud describers do and the result goes through the describe results queue
+func (s *Scheduler) runFileDescribeJob(ctx context.Context, dc model.DescribeConnectionJob) error {
+	if err := s.db.UpdateDescribeConnectionJobToInProgress(dc.ID); err != nil {
+		s.logger.Error("failed to update describe job to in progress", zap.Uint("jobID", dc.ID), zap.Error(err))
+	}
+
+	result := DescribeJobResult{
+		JobID:       dc.ID,
+		ParentJobID: 0,
+		Status:      api.DescribeResourceJobSucceeded,
+		DescribeJob: DescribeJob{
+			JobID:        dc.ID,
+			ResourceType: dc.ResourceType,
+			SourceID:     dc.ConnectionID,
+			AccountID:    dc.AccountID,
+			DescribedAt:  dc.CreatedAt.UnixMilli(),
+			SourceType:   dc.Connector,
+			TriggerType:  dc.TriggerType,
+		},
+	}
+
+	describedResourceIDs, err := s.ingestFileResources(ctx, dc)
+	if err != nil {
+		s.logger.Error("failed to describe resources from files", zap.Uint("jobID", dc.ID), zap.String("resourceType", dc.ResourceType), zap.Error(err))
+		result.Status = api.DescribeResourceJobFailed
+		result.Error = err.Error()
+	} else {
+		result.DescribedResourceIDs = describedResourceIDs
+	}
+
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if _, err := s.jq.Produce(ctx, DescribeResultsQueueName, payload, fmt.Sprintf("job-result-%d-%d", dc.ID, dc.RetryCount)); err != nil {
+		s.logger.Error("failed to publish file describe result", zap.Uint("jobID", dc.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *Scheduler) ingestFileResources(ctx context.Context, dc model.DescribeConnectionJob) ([]string, error) {
+	dirs := s.fileDescriberDirs(dc)
+	if len(dirs) == 0 {
+		// nothing exported for the connection, same as a cloud account without resources of the type
+		return nil, nil
+	}
+	files, err := fileDescriberFiles(dirs, dc.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	var describedResourceIDs []string
+	var docs []es2.Doc
+	flush := func(force bool) error {
+		if len(docs) == 0 || (!force && len(docs) < fileDescriberBatchSize) {
+			return nil
+		}
+		if _, err := s.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, docs); err != nil {
+			return err
+		}
+		docs = nil
+		return nil
+	}
+
+	for _, path := range files {
+		err := readFileResources(path, func(r fileResource) error {
+			// resources without a type can't be told apart from the ones of other resource types
+			if !strings.EqualFold(r.ResourceType, dc.ResourceType) {
+				return nil
+			}
+			id := r.ID
+			if id == "" {
+				id = r.ARN
+			}
+			if id == "" {
+				s.logger.Warn("skipping file resource without id", zap.String("file", path), zap.String("resourceType", dc.ResourceType))
+				return nil
+			}
+
+			resource := es2.Resource{
+				ID:            id,
+				ARN:           r.ARN,
+				Description:   r.Description,
+				SourceType:    dc.Connector,
+				ResourceType:  dc.ResourceType,
+				ResourceJobID: dc.ID,
+				SourceID:      dc.ConnectionID,
+				Metadata:      r.Metadata,
+				CanonicalTags: r.CanonicalTags,
+				Name:          r.Name,
+				ResourceGroup: r.ResourceGroup,
+				Location:      r.Location,
+				CreatedAt:     dc.CreatedAt.UnixMilli(),
+			}
+			keys, idx := resource.KeysAndIndex()
+			resource.EsID = es2.HashOf(keys...)
+			resource.EsIndex = idx
+
+			lookup := es2.LookupResource{
+				ResourceID:    id,
+				Name:          r.Name,
+				SourceType:    dc.Connector,
+				ResourceType:  strings.ToLower(dc.ResourceType),
+				ResourceGroup: r.ResourceGroup,
+				Location:      r.Location,
+				SourceID:      dc.ConnectionID,
+				ResourceJobID: dc.ID,
+				CreatedAt:     dc.CreatedAt.UnixMilli(),
+				Tags:          r.CanonicalTags,
+			}
+			keys, idx = lookup.KeysAndIndex()
+			lookup.EsID = es2.HashOf(keys...)
+			lookup.EsIndex = idx
+
+			docs = append(docs, resource, lookup)
+			describedResourceIDs = append(describedResourceIDs, id)
+			return flush(false)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := flush(true); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("described resources from files", zap.Uint("jobID", dc.ID), zap.String("resourceType", dc.ResourceType),
+		zap.Int("files", len(files)), zap.Int("resources", len(describedResourceIDs)))
+	return describedResourceIDs, nil
+}
@@ -0,0 +1,188 @@
+package describe
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	es2 "github.com/kaytu-io/kaytu-util/pkg/es"
+	"github.com/kaytu-io/kaytu-util/pkg/es/ingest/entity"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/describe/config"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testSinkClient struct {
+	docs []es2.Doc
+}
+
+func (c *testSinkClient) Ingest(_ *httpclient.Context, docs []es2.Doc) ([]entity.FailedDoc, error) {
+	c.docs = append(c.docs, docs...)
+	return nil, nil
+}
+
+func writeTestFile(t *testing.T, path, content string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func readTestFileResources(t *testing.T, content string) ([]fileResource, error) {
+	t.Helper()
+	path := writeTestFile(t, filepath.Join(t.TempDir(), "resources.json"), content)
+	var resources []fileResource
+	err := readFileResources(path, func(r fileResource) error {
+		resources = append(resources, r)
+		return nil
+	})
+	return resources, err
+}
+
+func TestReadFileResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantIDs []string
+	}{
+		{name: "array", content: `[{"id": "a"}, {"id": "b"}]`, wantIDs: []string{"a", "b"}},
+		{name: "array after whitespace", content: "\n\t  [{\"id\": \"a\"}]", wantIDs: []string{"a"}},
+		{name: "single object", content: `{"id": "a", "resource_type": "AWS::EC2::Instance"}`, wantIDs: []string{"a"}},
+		{name: "ndjson", content: "{\"id\": \"a\"}\n{\"id\": \"b\"}\n\n{\"id\": \"c\"}\n", wantIDs: []string{"a", "b", "c"}},
+		{name: "index export", content: `[{"_id": "x", "_source": {"id": "a", "name": "web"}}]`, wantIDs: []string{"a"}},
+		{name: "empty array", content: `[]`},
+		{name: "empty file", content: ``},
+		{name: "whitespace only", content: "  \n "},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resources, err := readTestFileResources(t, tc.content)
+			require.NoError(t, err)
+			var ids []string
+			for _, r := range resources {
+				ids = append(ids, r.ID)
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+		})
+	}
+
+	t.Run("fields", func(t *testing.T) {
+		resources, err := readTestFileResources(t, `{"_source": {"id": "i-1", "arn": "arn:aws:ec2:us-east-1:1:instance/i-1", "name": "web",
+			"resource_type": "AWS::EC2::Instance", "location": "us-east-1", "metadata": {"region": "us-east-1"},
+			"canonical_tags": [{"Key": "env", "Value": "prod"}], "description": {"InstanceId": "i-1"}}}`)
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		r := resources[0]
+		assert.Nil(t, r.Source)
+		assert.Equal(t, "arn:aws:ec2:us-east-1:1:instance/i-1", r.ARN)
+		assert.Equal(t, "AWS::EC2::Instance", r.ResourceType)
+		assert.Equal(t, map[string]string{"region": "us-east-1"}, r.Metadata)
+		assert.Equal(t, []es2.Tag{{Key: "env", Value: "prod"}}, r.CanonicalTags)
+		assert.Equal(t, map[string]any{"InstanceId": "i-1"}, r.Description)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		resources, err := readTestFileResources(t, `[{"id": "a"}, {"id": ]`)
+		assert.ErrorContains(t, err, "failed to decode")
+		assert.Len(t, resources, 1)
+	})
+
+	t.Run("callback error stops reading", func(t *testing.T) {
+		path := writeTestFile(t, filepath.Join(t.TempDir(), "resources.json"), `[{"id": "a"}, {"id": "b"}]`)
+		calls := 0
+		err := readFileResources(path, func(fileResource) error {
+			calls++
+			return assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := readFileResources(filepath.Join(t.TempDir(), "missing.json"), func(fileResource) error { return nil })
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestFileDescriberFiles(t *testing.T) {
+	root := t.TempDir()
+	account := filepath.Join(root, "123456789012")
+	connection := filepath.Join(root, "conn-1")
+	exports := []string{
+		writeTestFile(t, filepath.Join(account, "AWS_EC2_Instance.ndjson"), `{}`),
+		writeTestFile(t, filepath.Join(account, "nested", "aws_ec2_instance.json"), `{}`),
+		writeTestFile(t, filepath.Join(account, "aws_s3_bucket.JSONL"), `{}`),
+		writeTestFile(t, filepath.Join(connection, "all.json"), `{}`),
+	}
+	writeTestFile(t, filepath.Join(account, "aws_ec2_instance.csv"), `id`)
+	writeTestFile(t, filepath.Join(account, "README"), `exports`)
+
+	t.Run("files named after the resource type are preferred", func(t *testing.T) {
+		files, err := fileDescriberFiles([]string{account, connection}, "AWS::EC2::Instance")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, exports[:2], files)
+	})
+
+	t.Run("every export is read without named files", func(t *testing.T) {
+		files, err := fileDescriberFiles([]string{account, connection}, "AWS::IAM::Role")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, exports, files)
+	})
+
+	t.Run("no directories", func(t *testing.T) {
+		files, err := fileDescriberFiles(nil, "AWS::EC2::Instance")
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := fileDescriberFiles([]string{filepath.Join(root, "missing")}, "AWS::EC2::Instance")
+		assert.Error(t, err)
+	})
+}
+
+func TestIngestFileResources(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "123456789012", "export.ndjson"), `{"id": "i-1", "resource_type": "aws::ec2::instance"}
+{"arn": "arn:aws:ec2:us-east-1:1:instance/i-2", "resource_type": "AWS::EC2::Instance"}
+{"id": "untyped"}
+{"id": "bucket", "resource_type": "AWS::S3::Bucket"}
+{"name": "no id", "resource_type": "AWS::EC2::Instance"}
+`)
+	sink := &testSinkClient{}
+	s := &Scheduler{logger: zap.NewNop(), sinkClient: sink, conf: config.SchedulerConfig{FileDescriberDir: root}}
+	dc := model.DescribeConnectionJob{ID: 7, ConnectionID: "conn-1", AccountID: "123456789012", Connector: source.CloudAWS, ResourceType: "AWS::EC2::Instance"}
+
+	ids, err := s.ingestFileResources(context.Background(), dc)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-1", "arn:aws:ec2:us-east-1:1:instance/i-2"}, ids)
+	// a resource doc and a lookup doc per resource
+	require.Len(t, sink.docs, 4)
+	resource, ok := sink.docs[0].(es2.Resource)
+	require.True(t, ok)
+	assert.Equal(t, "AWS::EC2::Instance", resource.ResourceType)
+	assert.Equal(t, uint(7), resource.ResourceJobID)
+	lookup, ok := sink.docs[1].(es2.LookupResource)
+	require.True(t, ok)
+	assert.Equal(t, "aws::ec2::instance", lookup.ResourceType)
+
+	t.Run("connection without exports", func(t *testing.T) {
+		other := dc
+		other.AccountID, other.ConnectionID = "210987654321", "conn-2"
+		ids, err := s.ingestFileResources(context.Background(), other)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+}
+
+func TestEnqueueFileDescribeJob(t *testing.T) {
+	s := &Scheduler{fileDescribeJobs: make(chan model.DescribeConnectionJob, 1)}
+	require.NoError(t, s.enqueueFileDescribeJob(model.DescribeConnectionJob{ID: 1}))
+	assert.EqualError(t, s.enqueueFileDescribeJob(model.DescribeConnectionJob{ID: 2}), "file describer queue is full")
+	assert.Equal(t, uint(1), (<-s.fileDescribeJobs).ID)
+}
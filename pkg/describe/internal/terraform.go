@@ -1,17 +1,23 @@
 package internal
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/kaytu-io/terraform-package/external/addrs"
 	"github.com/kaytu-io/terraform-package/external/backend"
 	azurem "github.com/kaytu-io/terraform-package/external/backend/remote-state/azure"
 	"github.com/kaytu-io/terraform-package/external/backend/remote-state/s3"
+	"github.com/kaytu-io/terraform-package/external/configs/hcl2shim"
 	"github.com/kaytu-io/terraform-package/external/states"
 	"github.com/kaytu-io/terraform-package/external/states/statefile"
 	"github.com/kaytu-io/terraform-package/external/tfdiags"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
 type Config struct {
@@ -72,14 +78,83 @@ func GetResourceIDFromArn(arns []string) ([]string, error) {
 	return resources, nil
 }
 
-func GetRemoteState(config Config) *states.State {
+// StateResource is a managed resource instance of a state, ID is the ARN for AWS and the resource ID for Azure
+type StateResource struct {
+	Address string
+	Type    string
+	ID      string
+}
+
+// GetStateResources returns the managed resource instances of the state which have an ARN or an Azure resource ID
+func GetStateResources(state *states.State) []StateResource {
+	var resources []StateResource
+	for _, ms := range state.Modules {
+		for _, rs := range ms.Resources {
+			if rs.Addr.Resource.Mode != addrs.ManagedResourceMode {
+				continue
+			}
+			for ik, is := range rs.Instances {
+				obj := is.Current
+				if obj == nil {
+					continue
+				}
+				attributes := obj.AttrsFlat
+				if attributes == nil && obj.AttrsJSON != nil {
+					ty, err := ctyjson.ImpliedType(obj.AttrsJSON)
+					if err != nil {
+						continue
+					}
+					val, err := ctyjson.Unmarshal(obj.AttrsJSON, ty)
+					if err != nil {
+						continue
+					}
+					attributes = hcl2shim.FlatmapValueFromHCL2(val)
+				}
+
+				id := attributes["arn"]
+				if id == "" && strings.HasPrefix(strings.ToLower(attributes["id"]), "/subscriptions/") {
+					id = attributes["id"]
+				}
+				if id == "" {
+					continue
+				}
+				resources = append(resources, StateResource{
+					Address: rs.Addr.Instance(ik).String(),
+					Type:    rs.Addr.Resource.Type,
+					ID:      id,
+				})
+			}
+		}
+	}
+	return resources
+}
+
+// GetLocalState reads a state file from disk
+func GetLocalState(path string) (*states.State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file, err := statefile.Read(f)
+	if err != nil {
+		return nil, err
+	}
+	return file.State, nil
+}
+
+func GetRemoteState(config Config) (*states.State, error) {
 	c := backend.TestWrapConfig(config.Config)
 
 	var b backend.Backend
-	if config.Type == "s3" {
+	switch config.Type {
+	case "s3":
 		b = s3.New()
-	} else if config.Type == "azurem" {
+	case "azurerm", "azurem":
 		b = azurem.New()
+	default:
+		return nil, fmt.Errorf("unsupported backend type %s", config.Type)
 	}
 
 	var diags tfdiags.Diagnostics
@@ -101,7 +176,7 @@ func GetRemoteState(config Config) *states.State {
 
 	// it's valid for a Backend to have warnings (e.g. a Deprecation) as such we should only raise on errors
 	if diags.HasErrors() {
-		panic(diags.ErrWithWarnings())
+		return nil, diags.ErrWithWarnings()
 	}
 
 	obj = newObj
@@ -109,23 +184,29 @@ func GetRemoteState(config Config) *states.State {
 	confDiags := b.Configure(obj)
 	if len(confDiags) != 0 {
 		confDiags = confDiags.InConfigBody(c, "")
-		panic(confDiags.ErrWithWarnings())
+		return nil, confDiags.ErrWithWarnings()
 	}
 
 	ws, err := b.Workspaces()
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if len(ws) == 0 {
+		return nil, errors.New("backend has no workspaces")
 	}
 
 	stateMgr, err := b.StateMgr(ws[0])
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	err = stateMgr.RefreshState()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	state := stateMgr.State()
-	return state
+	if state == nil {
+		return nil, errors.New("state is empty")
+	}
+	return state, nil
 }
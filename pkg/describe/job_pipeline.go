@@ -0,0 +1,511 @@
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgtype"
+	apiAuth "github.com/kaytu-io/kaytu-util/pkg/api"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	analyticsApi "github.com/kaytu-io/open-governance/pkg/analytics/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/kaytu-io/open-governance/pkg/describe/schedulers/report"
+	"github.com/kaytu-io/open-governance/pkg/utils"
+	"go.uber.org/zap"
+)
+
+func (s *Scheduler) RunJobPipelines(ctx context.Context) {
+	s.logger.Info("Scheduling job pipelines")
+
+	t := ticker.NewTicker(JobSequencerInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		if err := s.checkJobPipelines(ctx); err != nil {
+			s.logger.Error("failed to run checkJobPipelines", zap.Error(err))
+			continue
+		}
+	}
+}
+
+// newJobPipelineNodes validates the pipeline definition and builds a job sequencer per node
+func newJobPipelineNodes(req api.CreateJobPipelineRequest) ([]model.JobSequencer, error) {
+	keys := make(map[string]bool)
+	for _, n := range req.Nodes {
+		if keys[n.Key] {
+			return nil, fmt.Errorf("duplicate node key %s", n.Key)
+		}
+		keys[n.Key] = true
+	}
+
+	nodes := make([]model.JobSequencer, 0, len(req.Nodes))
+	for _, n := range req.Nodes {
+		seen := make(map[string]bool)
+		for _, dep := range n.DependsOn {
+			if dep == n.Key {
+				return nil, fmt.Errorf("node %s depends on itself", n.Key)
+			}
+			if !keys[dep] {
+				return nil, fmt.Errorf("node %s depends on unknown node %s", n.Key, dep)
+			}
+			if seen[dep] {
+				return nil, fmt.Errorf("node %s depends on %s more than once", n.Key, dep)
+			}
+			seen[dep] = true
+		}
+
+		policy := model.JobSequencerFailurePolicy(n.FailurePolicy)
+		switch policy {
+		case "":
+			policy = model.JobSequencerFailurePolicyAbort
+		case model.JobSequencerFailurePolicyContinue, model.JobSequencerFailurePolicyAbort:
+		case model.JobSequencerFailurePolicyRetry:
+			if n.MaxRetries == 0 {
+				n.MaxRetries = 1
+			}
+		default:
+			return nil, fmt.Errorf("invalid failure policy %s of node %s", n.FailurePolicy, n.Key)
+		}
+		if policy != model.JobSequencerFailurePolicyRetry && n.MaxRetries > 0 {
+			return nil, fmt.Errorf("maxRetries of node %s requires the retry failure policy", n.Key)
+		}
+
+		parameters := model.JobSequencerNodeParameters{
+			ConnectionIDs: n.ConnectionIDs,
+			ResourceTypes: n.ResourceTypes,
+			BenchmarkID:   strings.ToLower(n.BenchmarkID),
+			TrendDays:     n.TrendDays,
+		}
+		var jobType model.JobSequencerJobType
+		switch n.Type {
+		case api.JobPipelineNodeTypeDescribe:
+			jobType = model.JobSequencerJobTypeDescribe
+			if len(n.ConnectionIDs) == 0 || len(n.ResourceTypes) == 0 {
+				return nil, fmt.Errorf("describe node %s requires connectionIDs and resourceTypes", n.Key)
+			}
+		case api.JobPipelineNodeTypeAnalytics:
+			jobType = model.JobSequencerJobTypeAnalytics
+		case api.JobPipelineNodeTypeBenchmark:
+			jobType = model.JobSequencerJobTypeBenchmark
+			if n.BenchmarkID == "" || len(n.ConnectionIDs) == 0 {
+				return nil, fmt.Errorf("benchmark node %s requires benchmarkID and connectionIDs", n.Key)
+			}
+		case api.JobPipelineNodeTypeReport:
+			jobType = model.JobSequencerJobTypeReport
+			if n.BenchmarkID == "" {
+				return nil, fmt.Errorf("report node %s requires benchmarkID", n.Key)
+			}
+			formats, err := report.NormalizeFormats(n.ReportFormats)
+			if err != nil {
+				return nil, err
+			}
+			for _, format := range formats {
+				parameters.ReportFormats = append(parameters.ReportFormats, string(format))
+			}
+		default:
+			return nil, fmt.Errorf("invalid type %s of node %s", n.Type, n.Key)
+		}
+
+		parametersJSON, err := json.Marshal(parameters)
+		if err != nil {
+			return nil, err
+		}
+		jp := pgtype.JSONB{}
+		if err := jp.Set(parametersJSON); err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, model.JobSequencer{
+			NodeKey:           n.Key,
+			DependsOn:         n.DependsOn,
+			NextJob:           jobType,
+			NextJobParameters: &jp,
+			FailurePolicy:     policy,
+			MaxRetries:        n.MaxRetries,
+			Status:            model.JobSequencerWaitingForDependencies,
+		})
+	}
+
+	// Kahn's algorithm, nodes left with dependencies are on a cycle
+	inDegree := make(map[string]int)
+	dependents := make(map[string][]string)
+	var queue []string
+	for _, n := range nodes {
+		inDegree[n.NodeKey] = len(n.DependsOn)
+		for _, dep := range n.DependsOn {
+			dependents[dep] = append(dependents[dep], n.NodeKey)
+		}
+		if len(n.DependsOn) == 0 {
+			queue = append(queue, n.NodeKey)
+		}
+	}
+	visited := 0
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, d := range dependents[key] {
+			inDegree[d]--
+			if inDegree[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+	if visited != len(nodes) {
+		return nil, errors.New("pipeline dependencies contain a cycle")
+	}
+	return nodes, nil
+}
+
+func jobSequencerJobIDs(node model.JobSequencer) []uint {
+	var ids []uint
+	for _, s := range strings.Split(node.NextJobIDs, ",") {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+func jobPipelineNodeParameters(node model.JobSequencer) (model.JobSequencerNodeParameters, error) {
+	var parameters model.JobSequencerNodeParameters
+	if node.NextJobParameters == nil {
+		return parameters, nil
+	}
+	err := json.Unmarshal(node.NextJobParameters.Bytes, &parameters)
+	return parameters, err
+}
+
+func isJobPipelineNodeDone(node model.JobSequencer) bool {
+	return node.Status == model.JobSequencerFinished || node.Status == model.JobSequencerFailed || node.Status == model.JobSequencerSkipped
+}
+
+// jobPipelineAbortedBy returns the failed node aborting the pipeline, nil if there is none
+func jobPipelineAbortedBy(nodes []model.JobSequencer) *model.JobSequencer {
+	for i, node := range nodes {
+		if node.Status == model.JobSequencerFailed && node.FailurePolicy != model.JobSequencerFailurePolicyContinue {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) checkJobPipelines(ctx context.Context) error {
+	// report runs lost to a restart would keep their nodes running forever
+	if err := s.db.UpdateTimedOutReportRuns(report.RunTimeout); err != nil {
+		return err
+	}
+
+	pipelines, err := s.db.ListRunningJobPipelines()
+	if err != nil {
+		return err
+	}
+
+	for _, pipeline := range pipelines {
+		if err := s.advanceJobPipeline(ctx, pipeline); err != nil {
+			s.logger.Error("failed to advance job pipeline", zap.Uint("pipelineID", pipeline.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// advanceJobPipeline collects the results of the running nodes, starts the nodes whose dependencies are done
+// and finishes the pipeline once every node is done
+func (s *Scheduler) advanceJobPipeline(ctx context.Context, pipeline model.JobPipeline) error {
+	nodes, err := s.db.ListJobPipelineNodes(pipeline.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range nodes {
+		if nodes[i].Status != model.JobSequencerRunning {
+			continue
+		}
+		done, failureMessage, err := s.jobPipelineNodeResult(nodes[i])
+		if err != nil {
+			return err
+		}
+		if !done {
+			continue
+		}
+		if err := s.finishJobPipelineNode(ctx, pipeline, &nodes[i], failureMessage); err != nil {
+			return err
+		}
+	}
+
+	nodesByKey := make(map[string]*model.JobSequencer)
+	for i := range nodes {
+		nodesByKey[nodes[i].NodeKey] = &nodes[i]
+	}
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Status != model.JobSequencerWaitingForDependencies {
+			continue
+		}
+		if abortedBy := jobPipelineAbortedBy(nodes); abortedBy != nil {
+			now := time.Now()
+			node.Status = model.JobSequencerSkipped
+			node.FailureMessage = fmt.Sprintf("pipeline aborted by node %s", abortedBy.NodeKey)
+			node.FinishedAt = &now
+			if err := s.db.UpdateJobPipelineNode(node); err != nil {
+				return err
+			}
+			continue
+		}
+
+		ready := true
+		for _, dep := range node.DependsOn {
+			if d, ok := nodesByKey[dep]; ok && !isJobPipelineNodeDone(*d) {
+				ready = false
+				break
+			}
+		}
+		if !ready {
+			continue
+		}
+		if err := s.runJobPipelineNode(ctx, pipeline, node); err != nil {
+			return err
+		}
+	}
+
+	failed := false
+	for _, node := range nodes {
+		if !isJobPipelineNodeDone(node) {
+			return nil
+		}
+		if node.Status == model.JobSequencerFailed {
+			failed = true
+		}
+	}
+	status, failureMessage := model.JobPipelineSucceeded, ""
+	if abortedBy := jobPipelineAbortedBy(nodes); abortedBy != nil {
+		status = model.JobPipelineFailed
+		failureMessage = fmt.Sprintf("node %s failed: %s", abortedBy.NodeKey, abortedBy.FailureMessage)
+	} else if failed {
+		status = model.JobPipelineCompletedWithFailure
+	}
+	s.logger.Info("job pipeline finished", zap.Uint("pipelineID", pipeline.ID), zap.String("status", string(status)))
+	return s.db.UpdateJobPipelineStatus(pipeline.ID, status, failureMessage)
+}
+
+// runJobPipelineNode starts the node, a node failing to start is handled like a failed node
+func (s *Scheduler) runJobPipelineNode(ctx context.Context, pipeline model.JobPipeline, node *model.JobSequencer) error {
+	jobIDs, err := s.startJobPipelineNode(ctx, pipeline, *node)
+	if err != nil {
+		s.logger.Error("failed to start job pipeline node", zap.Uint("pipelineID", pipeline.ID), zap.String("node", node.NodeKey), zap.Error(err))
+		return s.finishJobPipelineNode(ctx, pipeline, node, err.Error())
+	}
+
+	var nid []string
+	for _, id := range jobIDs {
+		nid = append(nid, fmt.Sprintf("%d", id))
+	}
+	now := time.Now()
+	node.Status = model.JobSequencerRunning
+	node.NextJobIDs = strings.Join(nid, ",")
+	node.StartedAt = &now
+	node.FinishedAt = nil
+	return s.db.UpdateJobPipelineNode(node)
+}
+
+func (s *Scheduler) finishJobPipelineNode(ctx context.Context, pipeline model.JobPipeline, node *model.JobSequencer, failureMessage string) error {
+	node.FailureMessage = failureMessage
+	if failureMessage != "" && node.FailurePolicy == model.JobSequencerFailurePolicyRetry && node.RetryCount < node.MaxRetries {
+		node.RetryCount++
+		s.logger.Info("retrying job pipeline node", zap.Uint("pipelineID", pipeline.ID), zap.String("node", node.NodeKey), zap.Int("retryCount", node.RetryCount))
+		return s.runJobPipelineNode(ctx, pipeline, node)
+	}
+
+	now := time.Now()
+	node.Status = model.JobSequencerFinished
+	if failureMessage != "" {
+		node.Status = model.JobSequencerFailed
+	}
+	node.FinishedAt = &now
+	return s.db.UpdateJobPipelineNode(node)
+}
+
+// startJobPipelineNode creates the jobs of the node and returns their IDs
+func (s *Scheduler) startJobPipelineNode(ctx context.Context, pipeline model.JobPipeline, node model.JobSequencer) ([]uint, error) {
+	parameters, err := jobPipelineNodeParameters(node)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobIDs []uint
+	switch node.NextJob {
+	case model.JobSequencerJobTypeDescribe:
+		for _, connectionID := range parameters.ConnectionIDs {
+			connection, err := s.onboardClient.GetSource(&httpclient.Context{Ctx: ctx, UserRole: apiAuth.InternalRole}, connectionID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get connection %s: %v", connectionID, err)
+			}
+			for _, resourceType := range parameters.ResourceTypes {
+				job, err := s.describe(*connection, resourceType, false, false, false, nil, pipeline.CreatedBy, nil)
+				if errors.Is(err, ErrJobInProgress) {
+					// wait for the running discovery instead of starting another one
+					job, err = s.db.GetLastDescribeConnectionJob(connectionID, resourceType)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("failed to describe %s of connection %s: %v", resourceType, connectionID, err)
+				}
+				if job != nil {
+					jobIDs = append(jobIDs, job.ID)
+				}
+			}
+		}
+	case model.JobSequencerJobTypeAnalytics:
+		jobID, err := s.scheduleAnalyticsJob(model.AnalyticsJobTypeNormal, ctx)
+		if err != nil {
+			return nil, err
+		}
+		jobIDs = append(jobIDs, jobID)
+	case model.JobSequencerJobTypeBenchmark:
+		lastJob, err := s.db.GetLastComplianceJob(parameters.BenchmarkID)
+		if err != nil {
+			return nil, err
+		}
+		for _, connectionID := range parameters.ConnectionIDs {
+			jobID, err := s.complianceScheduler.CreateComplianceReportJobs(parameters.BenchmarkID, lastJob, connectionID, true, pipeline.CreatedBy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create compliance job: %v", err)
+			}
+			jobIDs = append(jobIDs, jobID)
+		}
+	case model.JobSequencerJobTypeReport:
+		formats := make([]api.ReportFormat, 0, len(parameters.ReportFormats))
+		for _, f := range parameters.ReportFormats {
+			formats = append(formats, api.ReportFormat(f))
+		}
+		runs, err := s.reportScheduler.CreateRuns(nil, parameters.BenchmarkID, parameters.ConnectionIDs, formats, pipeline.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+		utils.EnsureRunGoroutine(func() {
+			s.reportScheduler.Generate(ctx, runs, parameters.TrendDays)
+		})
+		for _, run := range runs {
+			jobIDs = append(jobIDs, run.ID)
+		}
+	default:
+		return nil, fmt.Errorf("job type %s not supported", node.NextJob)
+	}
+	return jobIDs, nil
+}
+
+// jobPipelineNodeResult reports whether every job of the node is done and why the node failed, if it did
+func (s *Scheduler) jobPipelineNodeResult(node model.JobSequencer) (bool, string, error) {
+	var failedIDs []uint
+	for _, id := range jobSequencerJobIDs(node) {
+		// a job deleted in the meantime fails the node instead of blocking it
+		done, succeeded := true, false
+		switch node.NextJob {
+		case model.JobSequencerJobTypeDescribe:
+			job, err := s.db.GetDescribeConnectionJobByID(id)
+			if err != nil {
+				return false, "", err
+			}
+			if job != nil {
+				done = job.Status == api.DescribeResourceJobSucceeded || job.Status == api.DescribeResourceJobFailed ||
+					job.Status == api.DescribeResourceJobTimeout || job.Status == api.DescribeResourceJobCanceled
+				succeeded = job.Status == api.DescribeResourceJobSucceeded
+			}
+		case model.JobSequencerJobTypeAnalytics:
+			job, err := s.db.GetAnalyticsJobByID(id)
+			if err != nil {
+				return false, "", err
+			}
+			if job != nil {
+				done = job.Status == analyticsApi.JobCompleted || job.Status == analyticsApi.JobCompletedWithFailure || job.Status == analyticsApi.JobCanceled
+				succeeded = job.Status == analyticsApi.JobCompleted
+			}
+		case model.JobSequencerJobTypeBenchmark:
+			job, err := s.db.GetComplianceJobByID(id)
+			if err != nil {
+				return false, "", err
+			}
+			if job != nil {
+				done = job.Status == model.ComplianceJobSucceeded || job.Status == model.ComplianceJobFailed ||
+					job.Status == model.ComplianceJobTimeOut || job.Status == model.ComplianceJobCanceled
+				succeeded = job.Status == model.ComplianceJobSucceeded
+			}
+		case model.JobSequencerJobTypeReport:
+			run, err := s.db.GetReportRun(id)
+			if err != nil {
+				return false, "", err
+			}
+			if run != nil {
+				done = run.Status != api.ReportRunStatusInProgress
+				succeeded = run.Status == api.ReportRunStatusSucceeded
+			}
+		default:
+			return true, fmt.Sprintf("job type %s not supported", node.NextJob), nil
+		}
+		if !done {
+			return false, "", nil
+		}
+		if !succeeded {
+			failedIDs = append(failedIDs, id)
+		}
+	}
+	if len(failedIDs) > 0 {
+		return true, fmt.Sprintf("%s jobs %v did not succeed", node.NextJob, failedIDs), nil
+	}
+	return true, "", nil
+}
+
+func jobPipelineToApi(pipeline model.JobPipeline, nodes []model.JobSequencer) api.JobPipeline {
+	dependents := make(map[string][]string)
+	for _, node := range nodes {
+		for _, dep := range node.DependsOn {
+			dependents[dep] = append(dependents[dep], node.NodeKey)
+		}
+	}
+
+	result := api.JobPipeline{
+		ID:             pipeline.ID,
+		Name:           pipeline.Name,
+		Status:         string(pipeline.Status),
+		FailureMessage: pipeline.FailureMessage,
+		CreatedBy:      pipeline.CreatedBy,
+		CreatedAt:      pipeline.CreatedAt,
+		UpdatedAt:      pipeline.UpdatedAt,
+		Nodes:          make([]api.JobPipelineNode, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		parameters, _ := jobPipelineNodeParameters(node)
+		apiNode := api.JobPipelineNode{
+			ID:             node.ID,
+			Key:            node.NodeKey,
+			Type:           api.JobPipelineNodeType(node.NextJob),
+			DependsOn:      node.DependsOn,
+			Dependents:     dependents[node.NodeKey],
+			FailurePolicy:  api.JobPipelineFailurePolicy(node.FailurePolicy),
+			MaxRetries:     node.MaxRetries,
+			RetryCount:     node.RetryCount,
+			Status:         string(node.Status),
+			JobIDs:         jobSequencerJobIDs(node),
+			FailureMessage: node.FailureMessage,
+			StartedAt:      node.StartedAt,
+			FinishedAt:     node.FinishedAt,
+			ConnectionIDs:  parameters.ConnectionIDs,
+			ResourceTypes:  parameters.ResourceTypes,
+			BenchmarkID:    parameters.BenchmarkID,
+			TrendDays:      parameters.TrendDays,
+		}
+		for _, f := range parameters.ReportFormats {
+			apiNode.ReportFormats = append(apiNode.ReportFormats, api.ReportFormat(f))
+		}
+		result.Nodes = append(result.Nodes, apiNode)
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package describe
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func analyticsNode(key string, dependsOn ...string) api.JobPipelineNodeRequest {
+	return api.JobPipelineNodeRequest{Key: key, Type: api.JobPipelineNodeTypeAnalytics, DependsOn: dependsOn}
+}
+
+func TestNewJobPipelineNodes_Dependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   []api.JobPipelineNodeRequest
+		wantErr string
+	}{
+		{
+			name:  "single node",
+			nodes: []api.JobPipelineNodeRequest{analyticsNode("a")},
+		},
+		{
+			name:  "diamond",
+			nodes: []api.JobPipelineNodeRequest{analyticsNode("d", "b", "c"), analyticsNode("b", "a"), analyticsNode("c", "a"), analyticsNode("a")},
+		},
+		{
+			name:  "independent chains",
+			nodes: []api.JobPipelineNodeRequest{analyticsNode("a"), analyticsNode("b", "a"), analyticsNode("x"), analyticsNode("y", "x")},
+		},
+		{
+			name:    "two node cycle",
+			nodes:   []api.JobPipelineNodeRequest{analyticsNode("a", "b"), analyticsNode("b", "a")},
+			wantErr: "pipeline dependencies contain a cycle",
+		},
+		{
+			name:    "longer cycle behind a valid root",
+			nodes:   []api.JobPipelineNodeRequest{analyticsNode("root"), analyticsNode("a", "root", "c"), analyticsNode("b", "a"), analyticsNode("c", "b")},
+			wantErr: "pipeline dependencies contain a cycle",
+		},
+		{
+			name:    "cycle without roots",
+			nodes:   []api.JobPipelineNodeRequest{analyticsNode("a", "c"), analyticsNode("b", "a"), analyticsNode("c", "b")},
+			wantErr: "pipeline dependencies contain a cycle",
+		},
+		{
+			name:    "self dependency",
+			nodes:   []api.JobPipelineNodeRequest{analyticsNode("a", "a")},
+			wantErr: "node a depends on itself",
+		},
+		{
+			name:    "unknown dependency",
+			nodes:   []api.JobPipelineNodeRequest{analyticsNode("a", "b")},
+			wantErr: "node a depends on unknown node b",
+		},
+		{
+			name:    "repeated dependency",
+			nodes:   []api.JobPipelineNodeRequest{analyticsNode("a"), analyticsNode("b", "a", "a")},
+			wantErr: "node b depends on a more than once",
+		},
+		{
+			name:    "duplicate key",
+			nodes:   []api.JobPipelineNodeRequest{analyticsNode("a"), analyticsNode("a")},
+			wantErr: "duplicate node key a",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := newJobPipelineNodes(api.CreateJobPipelineRequest{Name: "test", Nodes: tc.nodes})
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				assert.Nil(t, nodes)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, nodes, len(tc.nodes))
+			for i, node := range nodes {
+				assert.Equal(t, tc.nodes[i].Key, node.NodeKey)
+				assert.Equal(t, model.JobSequencerWaitingForDependencies, node.Status)
+			}
+		})
+	}
+}
+
+func TestNewJobPipelineNodes_FailurePolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		node           api.JobPipelineNodeRequest
+		wantPolicy     model.JobSequencerFailurePolicy
+		wantMaxRetries int
+		wantErr        string
+	}{
+		{name: "defaults to abort", node: analyticsNode("a"), wantPolicy: model.JobSequencerFailurePolicyAbort},
+		{
+			name:       "continue",
+			node:       api.JobPipelineNodeRequest{Key: "a", Type: api.JobPipelineNodeTypeAnalytics, FailurePolicy: api.JobPipelineFailurePolicyContinue},
+			wantPolicy: model.JobSequencerFailurePolicyContinue,
+		},
+		{
+			name:           "retry defaults to one retry",
+			node:           api.JobPipelineNodeRequest{Key: "a", Type: api.JobPipelineNodeTypeAnalytics, FailurePolicy: api.JobPipelineFailurePolicyRetry},
+			wantPolicy:     model.JobSequencerFailurePolicyRetry,
+			wantMaxRetries: 1,
+		},
+		{
+			name:    "retries without the retry policy",
+			node:    api.JobPipelineNodeRequest{Key: "a", Type: api.JobPipelineNodeTypeAnalytics, MaxRetries: 2},
+			wantErr: "maxRetries of node a requires the retry failure policy",
+		},
+		{
+			name:    "invalid policy",
+			node:    api.JobPipelineNodeRequest{Key: "a", Type: api.JobPipelineNodeTypeAnalytics, FailurePolicy: "ignore"},
+			wantErr: "invalid failure policy ignore of node a",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := newJobPipelineNodes(api.CreateJobPipelineRequest{Nodes: []api.JobPipelineNodeRequest{tc.node}})
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, nodes, 1)
+			assert.Equal(t, tc.wantPolicy, nodes[0].FailurePolicy)
+			assert.Equal(t, tc.wantMaxRetries, nodes[0].MaxRetries)
+		})
+	}
+}
+
+func TestNewJobPipelineNodes_Parameters(t *testing.T) {
+	nodes, err := newJobPipelineNodes(api.CreateJobPipelineRequest{Nodes: []api.JobPipelineNodeRequest{
+		{Key: "discover", Type: api.JobPipelineNodeTypeDescribe, ConnectionIDs: []string{"conn-1"}, ResourceTypes: []string{"AWS::EC2::Instance"}},
+		{Key: "benchmark", Type: api.JobPipelineNodeTypeBenchmark, DependsOn: []string{"discover"}, BenchmarkID: "AWS_CIS_V200", ConnectionIDs: []string{"conn-1"}},
+		{Key: "report", Type: api.JobPipelineNodeTypeReport, DependsOn: []string{"benchmark"}, BenchmarkID: "aws_cis_v200", ReportFormats: []api.ReportFormat{api.ReportFormatPDF, api.ReportFormatPDF}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, nodes, 3)
+
+	assert.Equal(t, model.JobSequencerJobTypeDescribe, nodes[0].NextJob)
+	benchmark, err := jobPipelineNodeParameters(nodes[1])
+	require.NoError(t, err)
+	assert.Equal(t, "aws_cis_v200", benchmark.BenchmarkID)
+	report, err := jobPipelineNodeParameters(nodes[2])
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(api.ReportFormatPDF)}, report.ReportFormats)
+
+	_, err = newJobPipelineNodes(api.CreateJobPipelineRequest{Nodes: []api.JobPipelineNodeRequest{
+		{Key: "discover", Type: api.JobPipelineNodeTypeDescribe, ConnectionIDs: []string{"conn-1"}},
+	}})
+	assert.EqualError(t, err, "describe node discover requires connectionIDs and resourceTypes")
+	_, err = newJobPipelineNodes(api.CreateJobPipelineRequest{Nodes: []api.JobPipelineNodeRequest{
+		{Key: "export", Type: "Export"},
+	}})
+	assert.EqualError(t, err, "invalid type Export of node export")
+}
+
+func TestJobPipelineAbortedBy(t *testing.T) {
+	nodes := []model.JobSequencer{
+		{NodeKey: "a", Status: model.JobSequencerFinished, FailurePolicy: model.JobSequencerFailurePolicyAbort},
+		{NodeKey: "b", Status: model.JobSequencerFailed, FailurePolicy: model.JobSequencerFailurePolicyContinue},
+	}
+	assert.Nil(t, jobPipelineAbortedBy(nodes))
+
+	nodes = append(nodes, model.JobSequencer{NodeKey: "c", Status: model.JobSequencerFailed, FailurePolicy: model.JobSequencerFailurePolicyRetry})
+	abortedBy := jobPipelineAbortedBy(nodes)
+	require.NotNil(t, abortedBy)
+	assert.Equal(t, "c", abortedBy.NodeKey)
+}
@@ -85,3 +85,10 @@ var StreamFailureCount = promauto.NewCounterVec(prometheus.CounterOpts{
 	Name:      "stream_failure_total",
 	Help:      "Count of failures in streams",
 }, []string{"provider"})
+
+var DeadLetterQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "kaytu",
+	Subsystem: "scheduler",
+	Name:      "dead_letter_queue_depth",
+	Help:      "Number of messages in the dead-letter stream of each job stream",
+}, []string{"stream"})
@@ -0,0 +1,94 @@
+package describe
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/kaytu-util/pkg/httpserver"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
+	inventoryClient "github.com/kaytu-io/open-governance/pkg/inventory/client"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testInventoryClient answers the saved queries visible to the user of the request
+type testInventoryClient struct {
+	inventoryClient.InventoryServiceClient
+	visible map[string]map[string]bool
+	calls   map[string]int
+}
+
+func (c testInventoryClient) GetSavedQuery(ctx *httpclient.Context, id string) (*inventoryApi.SavedQuery, error) {
+	c.calls[id]++
+	switch id {
+	case "forbidden":
+		return nil, echo.NewHTTPError(http.StatusForbidden, "forbidden")
+	case "broken":
+		return nil, errors.New("inventory unavailable")
+	}
+	if !c.visible[id][ctx.UserID] {
+		return nil, nil
+	}
+	return &inventoryApi.SavedQuery{ID: id}, nil
+}
+
+func newQueryScheduleContext(userID string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(httpserver.XKaytuUserIDHeader, userID)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestIsQueryScheduleVisible(t *testing.T) {
+	inventory := testInventoryClient{
+		visible: map[string]map[string]bool{"shared": {"user-1": true, "user-2": true}, "private": {"user-1": true}},
+		calls:   map[string]int{},
+	}
+	h := HttpServer{Scheduler: &Scheduler{logger: zap.NewNop(), inventoryClient: inventory}}
+
+	tests := []struct {
+		name     string
+		schedule model.QuerySchedule
+		userID   string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "creator", schedule: model.QuerySchedule{SavedQueryID: "private", CreatedBy: "user-1"}, userID: "user-1", want: true},
+		{name: "creator of a query no longer visible", schedule: model.QuerySchedule{SavedQueryID: "gone", CreatedBy: "user-1"}, userID: "user-1", want: true},
+		{name: "visible saved query", schedule: model.QuerySchedule{SavedQueryID: "shared", CreatedBy: "user-1"}, userID: "user-2", want: true},
+		{name: "private saved query of another user", schedule: model.QuerySchedule{SavedQueryID: "private", CreatedBy: "user-1"}, userID: "user-2", want: false},
+		{name: "saved query denied", schedule: model.QuerySchedule{SavedQueryID: "forbidden", CreatedBy: "user-1"}, userID: "user-2", want: false},
+		{name: "inventory error", schedule: model.QuerySchedule{SavedQueryID: "broken", CreatedBy: "user-1"}, userID: "user-2", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			visible, err := h.isQueryScheduleVisible(newQueryScheduleContext(tc.userID), tc.schedule, nil)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, visible)
+		})
+	}
+
+	t.Run("saved queries are fetched once per listing", func(t *testing.T) {
+		ctx := newQueryScheduleContext("user-2")
+		visibleQueries := map[string]bool{}
+		for _, schedule := range []model.QuerySchedule{
+			{SavedQueryID: "private", CreatedBy: "user-1"},
+			{SavedQueryID: "private", CreatedBy: "user-3"},
+			{SavedQueryID: "private", CreatedBy: "user-2"},
+		} {
+			_, err := h.isQueryScheduleVisible(ctx, schedule, visibleQueries)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, map[string]bool{"private": false}, visibleQueries)
+		assert.Equal(t, 2, inventory.calls["private"])
+	})
+}
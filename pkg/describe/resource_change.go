@@ -0,0 +1,264 @@
+package describe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	authApi "github.com/kaytu-io/kaytu-util/pkg/api"
+	es2 "github.com/kaytu-io/kaytu-util/pkg/es"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/open-governance/pkg/describe/es"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"go.uber.org/zap"
+)
+
+const (
+	resourceChangeBatchSize = 500
+	// The describers send resources to es-sink which indexes them asynchronously, a job result can arrive before
+	// its resources are searchable. The result is redelivered after the delay until it was delivered this many times,
+	// staying below dlq.DefaultMaxAttempts so failures of the following steps are still retried.
+	resourceChangeMaxDeliveries = 3
+	resourceChangeRetryDelay    = 30 * time.Second
+)
+
+// recordResourceChanges compares the resources described by the job with their last snapshot and records
+// create and modify changes, the snapshots are replaced with the new descriptions. It returns the number of changes
+// and the number of described resources es-sink has not indexed for this job yet, those are not compared.
+// Recording is idempotent so the result can be processed again once the pending resources are indexed.
+func (s *Scheduler) recordResourceChanges(ctx context.Context, res DescribeJobResult) (int, int, error) {
+	resourceType := strings.ToLower(res.DescribeJob.ResourceType)
+	if isCostDiscoveryResourceType(resourceType) {
+		return 0, 0, nil
+	}
+	now := time.Now().UnixMilli()
+	changedCount, pendingCount := 0, 0
+
+	for start := 0; start < len(res.DescribedResourceIDs); start += resourceChangeBatchSize {
+		end := start + resourceChangeBatchSize
+		if end > len(res.DescribedResourceIDs) {
+			end = len(res.DescribedResourceIDs)
+		}
+		ids := res.DescribedResourceIDs[start:end]
+
+		resources, err := es.GetResourcesByIDs(ctx, s.es, res.DescribeJob.SourceID, resourceType, ids)
+		if err != nil {
+			s.logger.Error("failed to get described resources", zap.Error(err), zap.Uint("jobId", res.JobID))
+			return 0, 0, err
+		}
+		resources, pending := splitIndexedResources(res.JobID, ids, resources)
+		pendingCount += len(pending)
+		snapshots, err := es.GetResourceSnapshots(ctx, s.es, resourceType, ids)
+		if err != nil {
+			s.logger.Error("failed to get resource snapshots", zap.Error(err), zap.Uint("jobId", res.JobID))
+			return 0, 0, err
+		}
+
+		var docs []es2.Doc
+		for _, resource := range resources {
+			description, err := json.Marshal(resource.Description)
+			if err != nil {
+				return 0, 0, err
+			}
+			checksum := sha256.Sum256(description)
+			snapshot := types.ResourceSnapshot{
+				KaytuResourceID: resource.ID,
+				ResourceType:    resourceType,
+				ConnectionID:    res.DescribeJob.SourceID,
+				Connector:       res.DescribeJob.SourceType,
+				DiscoveryJobID:  res.JobID,
+				Description:     string(description),
+				Checksum:        hex.EncodeToString(checksum[:]),
+				UpdatedAt:       now,
+			}
+
+			change := types.ResourceChange{
+				KaytuResourceID: resource.ID,
+				ResourceType:    resourceType,
+				ResourceName:    resource.Name,
+				ConnectionID:    res.DescribeJob.SourceID,
+				Connector:       res.DescribeJob.SourceType,
+				DiscoveryJobID:  res.JobID,
+				ChangedAt:       now,
+			}
+			previous, ok := snapshots[resource.ID]
+			switch {
+			case ok && previous.DiscoveryJobID == res.JobID:
+				// recorded by an earlier delivery of this result
+				changedCount++
+				continue
+			case !ok:
+				change.ChangeType = types.ResourceChangeTypeCreate
+			case previous.Checksum != snapshot.Checksum:
+				change.ChangeType = types.ResourceChangeTypeModify
+				change.Diff, err = diffDescriptions(previous.Description, snapshot.Description)
+				if err != nil {
+					s.logger.Warn("failed to diff resource descriptions", zap.Error(err), zap.String("resourceId", resource.ID))
+				}
+			default:
+				continue
+			}
+
+			keys, idx := change.KeysAndIndex()
+			change.EsID = es2.HashOf(keys...)
+			change.EsIndex = idx
+			keys, idx = snapshot.KeysAndIndex()
+			snapshot.EsID = es2.HashOf(keys...)
+			snapshot.EsIndex = idx
+			docs = append(docs, change, snapshot)
+			changedCount++
+		}
+
+		if len(docs) == 0 {
+			continue
+		}
+		if _, err := s.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, docs); err != nil {
+			s.logger.Error("failed to send resource changes", zap.Error(err), zap.Uint("jobId", res.JobID))
+			return 0, 0, err
+		}
+	}
+	return changedCount, pendingCount, nil
+}
+
+// splitIndexedResources returns the resources indexed by the discovery job and the ids of the ones that are
+// missing or still hold the description of an earlier job
+func splitIndexedResources(jobID uint, ids []string, resources []es2.Resource) ([]es2.Resource, []string) {
+	indexed := make([]es2.Resource, 0, len(resources))
+	found := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		if resource.ResourceJobID != jobID {
+			continue
+		}
+		indexed = append(indexed, resource)
+		found[resource.ID] = true
+	}
+	var pending []string
+	for _, id := range ids {
+		if !found[id] {
+			pending = append(pending, id)
+		}
+	}
+	return indexed, pending
+}
+
+// deletedResourceChange is the change recorded for a resource the discovery job did not find anymore,
+// the returned resource is the snapshot to delete along with the resource
+func deletedResourceChange(res DescribeJobResult, kaytuResourceID string) (types.ResourceChange, es.DeletingResource) {
+	resourceType := strings.ToLower(res.DescribeJob.ResourceType)
+	change := types.ResourceChange{
+		KaytuResourceID: kaytuResourceID,
+		ResourceType:    resourceType,
+		ConnectionID:    res.DescribeJob.SourceID,
+		Connector:       res.DescribeJob.SourceType,
+		DiscoveryJobID:  res.JobID,
+		ChangeType:      types.ResourceChangeTypeDelete,
+		ChangedAt:       time.Now().UnixMilli(),
+	}
+	keys, idx := change.KeysAndIndex()
+	change.EsID = es2.HashOf(keys...)
+	change.EsIndex = idx
+
+	snapshot := types.ResourceSnapshot{
+		KaytuResourceID: kaytuResourceID,
+		ResourceType:    resourceType,
+	}
+	keys, idx = snapshot.KeysAndIndex()
+	return change, es.DeletingResource{
+		Key:        []byte(es2.HashOf(keys...)),
+		ResourceID: kaytuResourceID,
+		Index:      idx,
+	}
+}
+
+func isCostDiscoveryResourceType(resourceType string) bool {
+	return strings.ToLower(resourceType) == "microsoft.costmanagement/costbyresourcetype" ||
+		strings.ToLower(resourceType) == "aws::costexplorer::byservicedaily"
+}
+
+// diffDescriptions returns the changes between two json documents, paths are json pointers
+func diffDescriptions(oldDescription, newDescription string) ([]types.ResourceChangeDiff, error) {
+	var oldValue, newValue any
+	if err := json.Unmarshal([]byte(oldDescription), &oldValue); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(newDescription), &newValue); err != nil {
+		return nil, err
+	}
+	var diff []types.ResourceChangeDiff
+	diffValues("", oldValue, newValue, &diff)
+	return diff, nil
+}
+
+func diffValues(path string, oldValue, newValue any, diff *[]types.ResourceChangeDiff) {
+	switch o := oldValue.(type) {
+	case map[string]any:
+		if n, ok := newValue.(map[string]any); ok {
+			keys := make([]string, 0, len(o)+len(n))
+			for k := range o {
+				keys = append(keys, k)
+			}
+			for k := range n {
+				if _, ok := o[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				ov, oldOk := o[k]
+				nv, newOk := n[k]
+				childPath := path + "/" + escapeJSONPointer(k)
+				switch {
+				case !oldOk:
+					*diff = append(*diff, types.ResourceChangeDiff{Operation: types.ResourceChangeOperationAdd, Path: childPath, NewValue: jsonValue(nv)})
+				case !newOk:
+					*diff = append(*diff, types.ResourceChangeDiff{Operation: types.ResourceChangeOperationRemove, Path: childPath, OldValue: jsonValue(ov)})
+				default:
+					diffValues(childPath, ov, nv, diff)
+				}
+			}
+			return
+		}
+	case []any:
+		if n, ok := newValue.([]any); ok {
+			for i := 0; i < len(o) || i < len(n); i++ {
+				childPath := fmt.Sprintf("%s/%d", path, i)
+				switch {
+				case i >= len(o):
+					*diff = append(*diff, types.ResourceChangeDiff{Operation: types.ResourceChangeOperationAdd, Path: childPath, NewValue: jsonValue(n[i])})
+				case i >= len(n):
+					*diff = append(*diff, types.ResourceChangeDiff{Operation: types.ResourceChangeOperationRemove, Path: childPath, OldValue: jsonValue(o[i])})
+				default:
+					diffValues(childPath, o[i], n[i], diff)
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*diff = append(*diff, types.ResourceChangeDiff{
+			Operation: types.ResourceChangeOperationReplace,
+			Path:      path,
+			OldValue:  jsonValue(oldValue),
+			NewValue:  jsonValue(newValue),
+		})
+	}
+}
+
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func jsonValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
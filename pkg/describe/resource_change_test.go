@@ -0,0 +1,116 @@
+package describe
+
+import (
+	"testing"
+
+	es2 "github.com/kaytu-io/kaytu-util/pkg/es"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffDescriptions(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []types.ResourceChangeDiff
+	}{
+		{
+			name: "equal",
+			old:  `{"a":1,"b":{"c":[1,2]}}`,
+			new:  `{"b":{"c":[1,2]},"a":1}`,
+			want: nil,
+		},
+		{
+			name: "replaced value",
+			old:  `{"Instance":{"State":"running"}}`,
+			new:  `{"Instance":{"State":"stopped"}}`,
+			want: []types.ResourceChangeDiff{
+				{Operation: types.ResourceChangeOperationReplace, Path: "/Instance/State", OldValue: `"running"`, NewValue: `"stopped"`},
+			},
+		},
+		{
+			name: "added and removed keys in sorted order",
+			old:  `{"b":true,"c":1}`,
+			new:  `{"a":null,"c":1}`,
+			want: []types.ResourceChangeDiff{
+				{Operation: types.ResourceChangeOperationAdd, Path: "/a", NewValue: "null"},
+				{Operation: types.ResourceChangeOperationRemove, Path: "/b", OldValue: "true"},
+			},
+		},
+		{
+			name: "array elements",
+			old:  `{"Tags":["a","b"]}`,
+			new:  `{"Tags":["a","c","d"]}`,
+			want: []types.ResourceChangeDiff{
+				{Operation: types.ResourceChangeOperationReplace, Path: "/Tags/1", OldValue: `"b"`, NewValue: `"c"`},
+				{Operation: types.ResourceChangeOperationAdd, Path: "/Tags/2", NewValue: `"d"`},
+			},
+		},
+		{
+			name: "shrunk array",
+			old:  `[1,{"x":1}]`,
+			new:  `[1]`,
+			want: []types.ResourceChangeDiff{
+				{Operation: types.ResourceChangeOperationRemove, Path: "/1", OldValue: `{"x":1}`},
+			},
+		},
+		{
+			name: "type change",
+			old:  `{"Policy":{"Version":"2012"}}`,
+			new:  `{"Policy":"none"}`,
+			want: []types.ResourceChangeDiff{
+				{Operation: types.ResourceChangeOperationReplace, Path: "/Policy", OldValue: `{"Version":"2012"}`, NewValue: `"none"`},
+			},
+		},
+		{
+			name: "json pointer escaping",
+			old:  `{"a/b":{"c~d":1}}`,
+			new:  `{"a/b":{"c~d":2}}`,
+			want: []types.ResourceChangeDiff{
+				{Operation: types.ResourceChangeOperationReplace, Path: "/a~1b/c~0d", OldValue: "1", NewValue: "2"},
+			},
+		},
+		{
+			name: "root value",
+			old:  `1`,
+			new:  `2`,
+			want: []types.ResourceChangeDiff{
+				{Operation: types.ResourceChangeOperationReplace, Path: "", OldValue: "1", NewValue: "2"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			diff, err := diffDescriptions(tc.old, tc.new)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, diff)
+		})
+	}
+}
+
+func TestDiffDescriptions_InvalidJSON(t *testing.T) {
+	_, err := diffDescriptions(`{`, `{}`)
+	assert.Error(t, err)
+	_, err = diffDescriptions(`{}`, `not json`)
+	assert.Error(t, err)
+}
+
+func TestSplitIndexedResources(t *testing.T) {
+	resources := []es2.Resource{
+		{ID: "indexed", ResourceJobID: 7},
+		{ID: "stale", ResourceJobID: 6},
+		{ID: "also-indexed", ResourceJobID: 7},
+	}
+	indexed, pending := splitIndexedResources(7, []string{"indexed", "stale", "missing", "also-indexed"}, resources)
+
+	require.Len(t, indexed, 2)
+	assert.Equal(t, "indexed", indexed[0].ID)
+	assert.Equal(t, "also-indexed", indexed[1].ID)
+	assert.Equal(t, []string{"stale", "missing"}, pending)
+
+	indexed, pending = splitIndexedResources(7, nil, nil)
+	assert.Empty(t, indexed)
+	assert.Nil(t, pending)
+}
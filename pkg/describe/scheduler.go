@@ -28,6 +28,7 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
 	"github.com/kaytu-io/kaytu-util/pkg/postgres"
 	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	"github.com/kaytu-io/kaytu-util/pkg/vault"
 	"github.com/kaytu-io/kaytu-util/proto/src/golang"
 	"github.com/kaytu-io/open-governance/pkg/analytics"
 	"github.com/kaytu-io/open-governance/pkg/checkup"
@@ -41,6 +42,8 @@ import (
 	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
 	"github.com/kaytu-io/open-governance/pkg/describe/schedulers/compliance"
 	"github.com/kaytu-io/open-governance/pkg/describe/schedulers/discovery"
+	"github.com/kaytu-io/open-governance/pkg/describe/schedulers/report"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	inventoryClient "github.com/kaytu-io/open-governance/pkg/inventory/client"
 	metadataClient "github.com/kaytu-io/open-governance/pkg/metadata/client"
 	"github.com/kaytu-io/open-governance/pkg/metadata/models"
@@ -129,7 +132,9 @@ type Scheduler struct {
 	authGrpcClient   envoyAuth.AuthorizationClient
 	es               kaytu.Client
 
-	jq *jq.JobQueue
+	jq    *jq.JobQueue
+	dlq   *dlq.DeadLetterQueue
+	vault vault.VaultSourceConfig
 
 	describeJobLocalEndpoint     string
 	describeDeliverLocalEndpoint string
@@ -145,10 +150,12 @@ type Scheduler struct {
 
 	lambdaClient     *lambda.Client
 	serviceBusClient *azservicebus.Client
+	fileDescribeJobs chan model.DescribeConnectionJob
 
 	complianceScheduler  *compliance.JobScheduler
 	discoveryScheduler   *discovery.Scheduler
 	queryRunnerScheduler *queryrunnerscheduler.JobScheduler
+	reportScheduler      *report.Scheduler
 	conf                 config.SchedulerConfig
 }
 
@@ -179,6 +186,7 @@ func InitializeScheduler(
 		describeExternalEndpoint:     DescribeExternalEndpoint,
 		keyARN:                       KeyARN,
 		keyRegion:                    KeyRegion,
+		fileDescribeJobs:             make(chan model.DescribeConnectionJob, MaxQueued),
 	}
 	defer func() {
 		if err != nil && s != nil {
@@ -232,6 +240,12 @@ func InitializeScheduler(
 	}
 	s.jq = jq
 
+	s.dlq, err = dlq.New(conf.NATS.URL, s.logger)
+	if err != nil {
+		s.logger.Error("Failed to create dead-letter queue", zap.Error(err))
+		return nil, err
+	}
+
 	err = s.SetupNatsStreams(ctx)
 	if err != nil {
 		s.logger.Error("Failed to setup nats streams", zap.Error(err))
@@ -320,6 +334,19 @@ func InitializeScheduler(
 	}
 	s.authGrpcClient = envoyAuth.NewAuthorizationClient(authGRPCConn)
 
+	switch conf.Vault.Provider {
+	case vault.AwsKMS:
+		s.vault, err = vault.NewKMSVaultSourceConfig(ctx, conf.Vault.Aws, conf.Vault.KeyId)
+	case vault.AzureKeyVault:
+		s.vault, err = vault.NewAzureVaultClient(ctx, s.logger, conf.Vault.Azure, conf.Vault.KeyId)
+	case vault.HashiCorpVault:
+		s.vault, err = vault.NewHashiCorpVaultClient(ctx, s.logger, conf.Vault.HashiCorp, conf.Vault.KeyId)
+	}
+	if err != nil {
+		s.logger.Error("Failed to create vault source config", zap.Error(err))
+		return nil, err
+	}
+
 	describeServer := NewDescribeServer(s.db, s.jq, s.authGrpcClient, s.logger, conf)
 	s.grpcServer = grpc.NewServer(
 		grpc.MaxRecvMsgSize(128 * 1024 * 1024),
@@ -352,6 +379,12 @@ func InitializeScheduler(
 		s.db,
 		s.es,
 	)
+	s.reportScheduler = report.New(
+		s.logger,
+		s.db,
+		s.complianceClient,
+		s.WorkspaceName,
+	)
 	return s, nil
 }
 
@@ -483,6 +516,9 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	utils.EnsureRunGoroutine(func() {
 		s.RunDescribeResourceJobs(ctx, true)
 	})
+	if s.conf.ServerlessProvider == config.ServerlessProviderTypeFile.String() {
+		s.RunFileDescriber(ctx)
+	}
 	s.discoveryScheduler.Run(ctx)
 
 	// Inventory summarizer
@@ -496,6 +532,9 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		wg.Done()
 	})
 
+	// Compliance reports
+	s.reportScheduler.Run(ctx)
+
 	// Query Runner
 	s.queryRunnerScheduler = queryrunnerscheduler.New(
 		func(ctx context.Context) error {
@@ -505,10 +544,12 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		s.logger,
 		s.db,
 		s.jq,
+		s.dlq,
 		s.es,
 		s.inventoryClient,
 		s.complianceClient,
 		s.metadataClient,
+		s.WorkspaceName,
 	)
 	s.queryRunnerScheduler.Run(ctx)
 
@@ -523,6 +564,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 		s.onboardClient,
 		s.db,
 		s.jq,
+		s.dlq,
 		s.es,
 		s.complianceIntervalHours,
 	)
@@ -530,6 +572,15 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	utils.EnsureRunGoroutine(func() {
 		s.RunJobSequencer(ctx) // Deprecated
 	})
+	utils.EnsureRunGoroutine(func() {
+		s.RunJobPipelines(ctx)
+	})
+	utils.EnsureRunGoroutine(func() {
+		s.RunTerraformStateSync(ctx)
+	})
+	utils.EnsureRunGoroutine(func() {
+		s.RunDeadLetterQueueMetrics(ctx)
+	})
 
 	utils.EnsureRunGoroutine(func() {
 		s.RunCheckupJobScheduler(ctx)
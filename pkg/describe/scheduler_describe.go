@@ -33,7 +33,6 @@ import (
 	apiDescribe "github.com/kaytu-io/open-governance/pkg/describe/api"
 	"github.com/kaytu-io/open-governance/pkg/describe/config"
 	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
-	"github.com/kaytu-io/open-governance/pkg/describe/es"
 	apiOnboard "github.com/kaytu-io/open-governance/pkg/onboard/api"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
@@ -108,37 +107,15 @@ func (s *Scheduler) RunDescribeResourceJobCycle(ctx context.Context, manuals boo
 	}
 	s.logger.Info("got the jobs", zap.Int("length", len(dcs)), zap.Int("limit", int(s.MaxConcurrentCall)))
 
-	counts, err := s.db.CountRunningDescribeJobsPerResourceType(manuals)
-	if err != nil {
-		s.logger.Error("failed to resource type count", zap.String("spot", "CountRunningDescribeJobsPerResourceType"), zap.Error(err))
-		DescribeResourceJobsCount.WithLabelValues("failure", "resource_type_count").Inc()
-		return err
-	}
-
 	rand.Shuffle(len(dcs), func(i, j int) {
 		dcs[i], dcs[j] = dcs[j], dcs[i]
 	})
 
-	rtCount := map[string]int{}
-	for i := 0; i < len(dcs); i++ {
-		dc := dcs[i]
-		rtCount[dc.ResourceType]++
-
-		maxCount := 25
-		if m, ok := es.ResourceRateLimit[dc.ResourceType]; ok {
-			maxCount = m
-		}
-
-		currentCount := 0
-		for _, c := range counts {
-			if c.ResourceType == dc.ResourceType {
-				currentCount = c.Count
-			}
-		}
-		if rtCount[dc.ResourceType]+currentCount > maxCount {
-			dcs = append(dcs[:i], dcs[i+1:]...)
-			i--
-		}
+	dcs, err = s.applyDiscoveryRateLimits(dcs, manuals)
+	if err != nil {
+		s.logger.Error("failed to apply discovery rate limits", zap.String("spot", "applyDiscoveryRateLimits"), zap.Error(err))
+		DescribeResourceJobsCount.WithLabelValues("failure", "rate_limit").Inc()
+		return err
 	}
 
 	s.logger.Info("preparing resource jobs to run", zap.Int("length", len(dcs)))
@@ -233,6 +210,13 @@ func (s *Scheduler) scheduleDescribeJob(ctx context.Context) {
 		return
 	}
 
+	schedulePolicies, err := s.db.ListDiscoverySchedulePolicies(true)
+	if err != nil {
+		s.logger.Error("failed to get discovery schedule policies", zap.String("spot", "ListDiscoverySchedulePolicies"), zap.Error(err))
+		DescribeJobsCount.WithLabelValues("failure").Inc()
+		return
+	}
+
 	for _, connection := range connections {
 		s.logger.Info("running describe job scheduler for connection", zap.String("connection_id", connection.ID.String()))
 		var resourceTypes []string
@@ -269,7 +253,8 @@ func (s *Scheduler) scheduleDescribeJob(ctx context.Context) {
 
 			removeResourcesAzure := azureAdOnlyOnOneConnection(connections, connection, resourceType)
 			removeResourcesAWS := awsOnlyOnOneConnection(connections, connection, resourceType)
-			_, err = s.describe(connection, resourceType, true, false, removeResourcesAzure || removeResourcesAWS, nil, "system")
+			schedulePolicy := resolveDiscoverySchedulePolicy(schedulePolicies, connection, resourceType)
+			_, err = s.describe(connection, resourceType, true, false, removeResourcesAzure || removeResourcesAWS, nil, "system", schedulePolicy)
 			if err != nil {
 				s.logger.Error("failed to describe connection", zap.String("connection_id", connection.ID.String()), zap.String("resource_type", resourceType), zap.Error(err))
 			}
@@ -412,7 +397,7 @@ func (s *Scheduler) retryFailedJobs(ctx context.Context) error {
 }
 
 func (s *Scheduler) describe(connection apiOnboard.Connection, resourceType string, scheduled bool, costFullDiscovery bool,
-	removeResources bool, parentId *uint, createdBy string) (*model.DescribeConnectionJob, error) {
+	removeResources bool, parentId *uint, createdBy string, schedulePolicy *model.DiscoverySchedulePolicy) (*model.DescribeConnectionJob, error) {
 	if connection.CredentialType == apiOnboard.CredentialTypeManualAwsOrganization &&
 		strings.HasPrefix(strings.ToLower(resourceType), "aws::costexplorer") {
 		// cost on org
@@ -477,7 +462,16 @@ func (s *Scheduler) describe(connection apiOnboard.Connection, resourceType stri
 				}
 			}
 
-			if job.UpdatedAt.After(time.Now().Add(-interval)) {
+			if schedulePolicy != nil {
+				nextRun, err := schedulePolicy.NextRunAfter(job.UpdatedAt)
+				if err != nil {
+					s.logger.Error("invalid discovery schedule policy", zap.Uint("policy_id", schedulePolicy.ID), zap.Error(err))
+					return nil, err
+				}
+				if nextRun.IsZero() || nextRun.After(time.Now()) {
+					return nil, nil
+				}
+			} else if job.UpdatedAt.After(time.Now().Add(-interval)) {
 				return nil, nil
 			}
 		}
@@ -816,6 +810,11 @@ func (s *Scheduler) enqueueCloudNativeDescribeJob(ctx context.Context, dc model.
 			isFailed = true
 			return fmt.Errorf("unknown source type: %s", input.DescribeJob.SourceType.String())
 		}
+	case config.ServerlessProviderTypeFile.String():
+		if err := s.enqueueFileDescribeJob(dc); err != nil {
+			isFailed = true
+			return fmt.Errorf("failed to enqueue file describe job due to %v", err)
+		}
 	default:
 		s.logger.Error("unknown serverless provider", zap.String("provider", s.conf.ServerlessProvider))
 		isFailed = true
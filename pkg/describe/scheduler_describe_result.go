@@ -17,7 +17,9 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/source"
 	"github.com/kaytu-io/kaytu-util/pkg/ticker"
 	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
 	"github.com/kaytu-io/open-governance/pkg/describe/es"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	"github.com/nats-io/nats.go/jetstream"
 	"go.uber.org/zap"
 )
@@ -89,11 +91,8 @@ func (s *Scheduler) RunDescribeJobResultsConsumer(ctx context.Context) error {
 
 				s.logger.Error("failed to consume message from describeJobResult", zap.Error(err))
 
-				// the job cannot be parsed into json, so send ack and throw message away.
-				if err := msg.Ack(); err != nil {
-					s.logger.Error("failure while sending ack for message", zap.Error(err))
-				}
-
+				// the job cannot be parsed into json, retrying it is pointless
+				s.dlq.Fail(ctx, "describe-receiver", msg, err, 1)
 				return
 			}
 
@@ -102,6 +101,30 @@ func (s *Scheduler) RunDescribeJobResultsConsumer(ctx context.Context) error {
 				zap.String("status", string(result.Status)),
 			)
 
+			resourcesChanged := false
+			if result.Status == api.DescribeResourceJobSucceeded {
+				// change history is best effort, it must not hold back the discovery pipeline
+				changedCount, pendingCount, err := s.recordResourceChanges(ctx, result)
+				if err != nil {
+					s.logger.Error("failed to record resource changes", zap.Error(err), zap.Uint("jobId", result.JobID))
+					// without the change history any described resource may have changed
+					changedCount = len(result.DescribedResourceIDs)
+				} else if pendingCount > 0 {
+					if md, err := msg.Metadata(); err == nil && md.NumDelivered < resourceChangeMaxDeliveries {
+						s.logger.Info("waiting for es-sink to index the described resources",
+							zap.Uint("jobId", result.JobID), zap.Int("pendingCount", pendingCount))
+						if err := msg.NakWithDelay(resourceChangeRetryDelay); err != nil {
+							s.logger.Error("failed to send not-ack for message", zap.Error(err))
+						}
+						return
+					}
+					s.logger.Warn("described resources were not indexed in time, their changes are not recorded",
+						zap.Uint("jobId", result.JobID), zap.Int("pendingCount", pendingCount))
+					changedCount += pendingCount
+				}
+				resourcesChanged = changedCount > 0
+			}
+
 			var deletedCount int64
 			if s.DoDeleteOldResources && result.Status == api.DescribeResourceJobSucceeded {
 				result.Status = api.DescribeResourceJobOldResourceDeletion
@@ -110,11 +133,7 @@ func (s *Scheduler) RunDescribeJobResultsConsumer(ctx context.Context) error {
 				if err != nil {
 					ResultsProcessedCount.WithLabelValues(string(result.DescribeJob.SourceType), "failure").Inc()
 					s.logger.Error("failed to cleanupOldResources", zap.Error(err))
-
-					if err := msg.Nak(); err != nil {
-						s.logger.Error("failure while sending not-ack for message", zap.Error(err))
-					}
-
+					s.dlq.Fail(ctx, "describe-receiver", msg, err, dlq.DefaultMaxAttempts)
 					return
 				}
 
@@ -139,16 +158,23 @@ func (s *Scheduler) RunDescribeJobResultsConsumer(ctx context.Context) error {
 				ResultsProcessedCount.WithLabelValues(string(result.DescribeJob.SourceType), "failure").Inc()
 
 				s.logger.Error("failed to UpdateDescribeResourceJobStatus", zap.Error(err))
-
-				if err := msg.Nak(); err != nil {
-					s.logger.Error("failure while sending not-ack for message", zap.Error(err))
-				}
-
+				s.dlq.Fail(ctx, "describe-receiver", msg, err, dlq.DefaultMaxAttempts)
 				return
 			}
 
 			ResultsProcessedCount.WithLabelValues(string(result.DescribeJob.SourceType), "successful").Inc()
 
+			if s.conf.IncrementalCompliance && (resourcesChanged || deletedCount > 0) {
+				err := s.db.CreateComplianceIncrementalTrigger(&model.ComplianceIncrementalTrigger{
+					ConnectionID:  result.DescribeJob.SourceID,
+					ResourceType:  result.DescribeJob.ResourceType,
+					DescribeJobID: result.JobID,
+				})
+				if err != nil {
+					s.logger.Error("failed to create compliance incremental trigger", zap.Error(err), zap.Uint("jobId", result.JobID))
+				}
+			}
+
 			if err := msg.Ack(); err != nil {
 				s.logger.Error("failure while sending ack for message", zap.Error(err))
 			}
@@ -218,11 +244,7 @@ func (s *Scheduler) handleTimeoutForDiscoveryJobs() {
 func (s *Scheduler) cleanupOldResources(ctx context.Context, res DescribeJobResult) (int64, error) {
 	var searchAfter []any
 
-	isCostResourceType := false
-	if strings.ToLower(res.DescribeJob.ResourceType) == "microsoft.costmanagement/costbyresourcetype" ||
-		strings.ToLower(res.DescribeJob.ResourceType) == "aws::costexplorer::byservicedaily" {
-		isCostResourceType = true
-	}
+	isCostResourceType := isCostDiscoveryResourceType(res.DescribeJob.ResourceType)
 
 	var additionalFilters []map[string]any
 	if isCostResourceType {
@@ -265,6 +287,7 @@ func (s *Scheduler) cleanupOldResources(ctx context.Context, res DescribeJobResu
 			Connector:      res.DescribeJob.SourceType,
 			TaskType:       es.DeleteTaskTypeResource,
 		}
+		var changes []es2.Doc
 
 		for _, hit := range esResp.Hits.Hits {
 			searchAfter = hit.Sort
@@ -308,6 +331,12 @@ func (s *Scheduler) cleanupOldResources(ctx context.Context, res DescribeJobResu
 					Index:      lookUpIdx,
 				})
 
+				if !isCostResourceType {
+					change, snapshot := deletedResourceChange(res, esResourceID)
+					changes = append(changes, change)
+					task.DeletingResources = append(task.DeletingResources, snapshot)
+				}
+
 				if err != nil {
 					CleanupJobCount.WithLabelValues("failure").Inc()
 					s.logger.Error("CleanJob failed",
@@ -340,6 +369,16 @@ func (s *Scheduler) cleanupOldResources(ctx context.Context, res DescribeJobResu
 			}
 			break
 		}
+
+		if len(changes) > 0 {
+			if _, err := s.sinkClient.Ingest(&httpclient.Context{UserRole: authApi.InternalRole}, changes); err != nil {
+				s.logger.Error("failed to send resource delete changes",
+					zap.Uint("jobId", res.JobID),
+					zap.String("connection_id", res.DescribeJob.SourceID),
+					zap.String("resource_type", res.DescribeJob.ResourceType),
+					zap.Error(err))
+			}
+		}
 	}
 
 	s.logger.Info("scheduled deleting old resources",
@@ -146,6 +146,7 @@ func (s *Scheduler) runNextJob(ctx context.Context, job model.JobSequencer) erro
 					caller := runner.Caller{
 						RootBenchmark:      parameters.BenchmarkID,
 						TracksDriftEvents:  rootBenchmark.TracksDriftEvents,
+						CapturesEvidence:   rootBenchmark.CapturesEvidence,
 						ParentBenchmarkIDs: path,
 						ControlID:          control.ID,
 						ControlSeverity:    control.Severity,
@@ -7,6 +7,7 @@ import (
 
 	"github.com/kaytu-io/open-governance/pkg/compliance/runner"
 	"github.com/kaytu-io/open-governance/pkg/compliance/summarizer"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	"github.com/nats-io/nats.go/jetstream"
 	"go.uber.org/zap"
 )
@@ -15,13 +16,10 @@ const JobTimeoutCheckInterval = 5 * time.Minute
 
 func (s *JobScheduler) RunComplianceReportJobResultsConsumer(ctx context.Context) error {
 	if _, err := s.jq.Consume(ctx, "scheduler-runner-compliance", runner.StreamName, []string{runner.ResultQueueTopic}, "scheduler-runner-compliance", func(msg jetstream.Msg) {
-		if err := msg.Ack(); err != nil {
-			s.logger.Error("Failed committing message", zap.Error(err))
-		}
-
 		var result runner.JobResult
 		if err := json.Unmarshal(msg.Data(), &result); err != nil {
 			s.logger.Error("Failed to unmarshal ComplianceReportJob results", zap.Error(err))
+			s.dlq.Fail(ctx, "scheduler-runner-compliance", msg, err, 1)
 			return
 		}
 
@@ -34,8 +32,13 @@ func (s *JobScheduler) RunComplianceReportJobResultsConsumer(ctx context.Context
 			s.logger.Error("Failed to update the status of ComplianceReportJob",
 				zap.Uint("jobId", result.Job.ID),
 				zap.Error(err))
+			s.dlq.Fail(ctx, "scheduler-runner-compliance", msg, err, dlq.DefaultMaxAttempts)
 			return
 		}
+
+		if err := msg.Ack(); err != nil {
+			s.logger.Error("Failed committing message", zap.Error(err))
+		}
 	}); err != nil {
 		return err
 	}
@@ -55,10 +58,7 @@ func (s *JobScheduler) RunComplianceSummarizerResultsConsumer(ctx context.Contex
 			var result summarizer.JobResult
 			if err := json.Unmarshal(msg.Data(), &result); err != nil {
 				s.logger.Error("Failed to unmarshal ComplianceSummarizer results", zap.Error(err))
-
-				if err := msg.Ack(); err != nil {
-					s.logger.Error("Failed committing message", zap.Error(err))
-				}
+				s.dlq.Fail(ctx, "scheduler-summarizer-compliance", msg, err, 1)
 				return
 			}
 
@@ -71,11 +71,7 @@ func (s *JobScheduler) RunComplianceSummarizerResultsConsumer(ctx context.Contex
 				s.logger.Error("Failed to update the status of Summarizer",
 					zap.Uint("jobId", result.Job.ID),
 					zap.Error(err))
-
-				if err := msg.Ack(); err != nil {
-					s.logger.Error("Failed committing message", zap.Error(err))
-				}
-
+				s.dlq.Fail(ctx, "scheduler-summarizer-compliance", msg, err, dlq.DefaultMaxAttempts)
 				return
 			}
 
@@ -0,0 +1,169 @@
+package compliance
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/api"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/runner"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// IncrementalTriggerSettleTime is how long the changes of a connection must be quiet before they are evaluated,
+// so the describe jobs of a discovery run end up in the same incremental job
+const IncrementalTriggerSettleTime = 5 * time.Minute
+
+func (s *JobScheduler) RunIncrementalScheduler() {
+	s.logger.Info("Scheduling incremental compliance jobs on a timer")
+
+	t := ticker.NewTicker(JobSchedulingInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		if err := s.runIncrementalScheduler(); err != nil {
+			s.logger.Error("failed to run incremental compliance scheduler", zap.Error(err))
+			ComplianceJobsCount.WithLabelValues("failure").Inc()
+			continue
+		}
+	}
+}
+
+type connectionChanges struct {
+	resourceTypes map[string]bool
+	lastChangedAt time.Time
+	maxTriggerID  uint
+}
+
+// runIncrementalScheduler creates an incremental compliance job for every benchmark assigned to a connection
+// whose resources were changed by discovery, the assignments are listed once per evaluated connection
+func (s *JobScheduler) runIncrementalScheduler() error {
+	triggers, err := s.db.ListComplianceIncrementalTriggers()
+	if err != nil {
+		s.logger.Error("error while listing compliance incremental triggers", zap.Error(err))
+		return err
+	}
+	if len(triggers) == 0 {
+		return nil
+	}
+
+	changes := make(map[string]*connectionChanges)
+	for _, trigger := range triggers {
+		c, ok := changes[trigger.ConnectionID]
+		if !ok {
+			c = &connectionChanges{resourceTypes: make(map[string]bool)}
+			changes[trigger.ConnectionID] = c
+		}
+		c.resourceTypes[strings.ToLower(trigger.ResourceType)] = true
+		if trigger.CreatedAt.After(c.lastChangedAt) {
+			c.lastChangedAt = trigger.CreatedAt
+		}
+		if trigger.ID > c.maxTriggerID {
+			c.maxTriggerID = trigger.ID
+		}
+	}
+
+	clientCtx := &httpclient.Context{UserRole: api.InternalRole}
+	for connectionID, c := range changes {
+		if time.Since(c.lastChangedAt) < IncrementalTriggerSettleTime {
+			continue
+		}
+		// changes are kept until the running jobs of the connection are done so they are evaluated on top of them
+		unfinished, err := s.db.ListUnfinishedComplianceJobsByConnectionID(connectionID)
+		if err != nil {
+			s.logger.Error("error while listing unfinished compliance jobs", zap.Error(err))
+			return err
+		}
+		if len(unfinished) > 0 {
+			continue
+		}
+
+		resourceTypes := make([]string, 0, len(c.resourceTypes))
+		for resourceType := range c.resourceTypes {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+		sort.Strings(resourceTypes)
+		// assignments are only looked up for the connections that are evaluated in this round
+		assignments, err := s.complianceClient.ListAssignmentsByConnection(clientCtx, connectionID)
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == http.StatusNotFound {
+			// the connection is gone, its changes are dropped
+			assignments, err = nil, nil
+		}
+		if err != nil {
+			s.logger.Error("error while listing assignments", zap.String("connectionID", connectionID), zap.Error(err))
+			return err
+		}
+		for _, benchmarkID := range assignedBenchmarkIDs(assignments) {
+			job := model.ComplianceJob{
+				BenchmarkID:          benchmarkID,
+				Status:               model.ComplianceJobCreated,
+				AreAllRunnersQueued:  false,
+				ConnectionID:         connectionID,
+				TriggerType:          model.ComplianceTriggerTypeScheduled,
+				CreatedBy:            "system",
+				Incremental:          true,
+				ChangedResourceTypes: resourceTypes,
+			}
+			if err := s.db.CreateComplianceJob(nil, &job); err != nil {
+				s.logger.Error("error while creating incremental compliance job", zap.Error(err))
+				return err
+			}
+			s.logger.Info("incremental compliance job created", zap.Uint("jobID", job.ID), zap.String("benchmarkID", benchmarkID),
+				zap.String("connectionID", connectionID), zap.Strings("resourceTypes", resourceTypes))
+		}
+
+		if err := s.db.DeleteComplianceIncrementalTriggers(connectionID, c.maxTriggerID); err != nil {
+			s.logger.Error("error while deleting compliance incremental triggers", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// assignedBenchmarkIDs returns the ids of the benchmarks that are assigned to the connection
+func assignedBenchmarkIDs(assignments []complianceApi.AssignedBenchmark) []string {
+	var benchmarkIDs []string
+	for _, assignment := range assignments {
+		if assignment.Status {
+			benchmarkIDs = append(benchmarkIDs, assignment.Benchmark.ID)
+		}
+	}
+	return benchmarkIDs
+}
+
+// queriesChangedResourceType tells if the query reads any of the changed resource types,
+// queries whose tables are unknown are assumed to read them
+func queriesChangedResourceType(query *complianceApi.Query, changedResourceTypes map[string]bool) bool {
+	tables := query.ListOfTables
+	if query.PrimaryTable != nil {
+		tables = append([]string{*query.PrimaryTable}, tables...)
+	}
+	known := false
+	for _, table := range tables {
+		resourceType, _ := runner.GetResourceTypeFromTableName(table, query.Connector)
+		if resourceType == "" {
+			continue
+		}
+		known = true
+		if changedResourceTypes[strings.ToLower(resourceType)] {
+			return true
+		}
+	}
+	return !known
+}
+
+// incrementalConnectionIDs returns the connections an incremental job evaluated, nil for full jobs
+func incrementalConnectionIDs(job model.ComplianceJob) []string {
+	if !job.Incremental {
+		return nil
+	}
+	return []string{job.ConnectionID}
+}
@@ -0,0 +1,79 @@
+package compliance
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestQueriesChangedResourceType(t *testing.T) {
+	changed := map[string]bool{"aws::ec2::instance": true, "microsoft.compute/virtualmachines": true}
+
+	tests := []struct {
+		name  string
+		query complianceApi.Query
+		want  bool
+	}{
+		{
+			name:  "primary table changed",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAWS}, PrimaryTable: strPtr("aws_ec2_instance")},
+			want:  true,
+		},
+		{
+			name:  "joined table changed",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAWS}, PrimaryTable: strPtr("aws_s3_bucket"), ListOfTables: []string{"aws_iam_role", "aws_ec2_instance"}},
+			want:  true,
+		},
+		{
+			name:  "no table changed",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAWS}, PrimaryTable: strPtr("aws_s3_bucket"), ListOfTables: []string{"aws_iam_role"}},
+			want:  false,
+		},
+		{
+			name:  "unknown tables are assumed to be changed",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAWS}, ListOfTables: []string{"aws_unknown_table"}},
+			want:  true,
+		},
+		{
+			name:  "query without tables",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAWS}},
+			want:  true,
+		},
+		{
+			name:  "unknown tables are ignored next to known ones",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAWS}, ListOfTables: []string{"aws_unknown_table", "aws_s3_bucket"}},
+			want:  false,
+		},
+		{
+			name:  "azure table changed",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAzure}, PrimaryTable: strPtr("azure_compute_virtual_machine")},
+			want:  true,
+		},
+		{
+			name:  "connector is resolved from the table",
+			query: complianceApi.Query{Connector: []source.Type{source.CloudAWS, source.CloudAzure}, ListOfTables: []string{"aws_s3_bucket", "azure_compute_virtual_machine"}},
+			want:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, queriesChangedResourceType(&tc.query, changed))
+		})
+	}
+}
+
+func TestAssignedBenchmarkIDs(t *testing.T) {
+	assignments := []complianceApi.AssignedBenchmark{
+		{Benchmark: complianceApi.Benchmark{ID: "aws_cis_v200"}, Status: true},
+		{Benchmark: complianceApi.Benchmark{ID: "aws_nist"}, Status: false},
+		{Benchmark: complianceApi.Benchmark{ID: "aws_hipaa"}, Status: true},
+	}
+	assert.Equal(t, []string{"aws_cis_v200", "aws_hipaa"}, assignedBenchmarkIDs(assignments))
+	assert.Empty(t, assignedBenchmarkIDs(nil))
+}
@@ -3,6 +3,7 @@ package compliance
 import (
 	"context"
 	"github.com/kaytu-io/kaytu-util/pkg/jq"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	"time"
 
 	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
@@ -25,6 +26,7 @@ type JobScheduler struct {
 	onboardClient           onboardClient.OnboardServiceClient
 	db                      db.Database
 	jq                      *jq.JobQueue
+	dlq                     *dlq.DeadLetterQueue
 	esClient                kaytu.Client
 	complianceIntervalHours time.Duration
 }
@@ -37,6 +39,7 @@ func New(
 	onboardClient onboardClient.OnboardServiceClient,
 	db db.Database,
 	jq *jq.JobQueue,
+	dlq *dlq.DeadLetterQueue,
 	esClient kaytu.Client,
 	complianceIntervalHours time.Duration,
 ) *JobScheduler {
@@ -48,6 +51,7 @@ func New(
 		onboardClient:           onboardClient,
 		db:                      db,
 		jq:                      jq,
+		dlq:                     dlq,
 		esClient:                esClient,
 		complianceIntervalHours: complianceIntervalHours,
 	}
@@ -60,6 +64,11 @@ func (s *JobScheduler) Run(ctx context.Context) {
 	utils.EnsureRunGoroutine(func() {
 		s.RunEnqueueRunnersCycle()
 	})
+	if s.conf.IncrementalCompliance {
+		utils.EnsureRunGoroutine(func() {
+			s.RunIncrementalScheduler()
+		})
+	}
 	utils.EnsureRunGoroutine(func() {
 		s.RunPublisher(ctx, false)
 	})
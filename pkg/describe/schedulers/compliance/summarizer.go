@@ -7,6 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kaytu-io/kaytu-util/pkg/api"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	runner2 "github.com/kaytu-io/open-governance/pkg/compliance/runner"
 	"github.com/kaytu-io/open-governance/pkg/compliance/summarizer"
 	types2 "github.com/kaytu-io/open-governance/pkg/compliance/summarizer/types"
 	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
@@ -130,7 +133,7 @@ func (s *JobScheduler) runSummarizer(ctx context.Context, manuals bool) error {
 		}
 		s.logger.Info("documents are sank, creating summarizer", zap.String("benchmarkId", job.BenchmarkID), zap.Int("sankDocCount", sankDocCount), zap.Int("totalDocCount", totalDocCount))
 
-		err = s.CreateSummarizer(job.BenchmarkID, &job.ID, job.TriggerType)
+		err = s.CreateSummarizer(job.BenchmarkID, &job.ID, job.TriggerType, incrementalConnectionIDs(job))
 		if err != nil {
 			s.logger.Error("failed to create summarizer", zap.Error(err), zap.String("benchmarkId", job.BenchmarkID))
 			return err
@@ -158,6 +161,24 @@ func (s *JobScheduler) runSummarizer(ctx context.Context, manuals bool) error {
 			s.logger.Error("failed to finish compliance job", zap.Error(err), zap.String("benchmarkId", job.BenchmarkID))
 			return err
 		}
+
+		// finding events of succeeded runners are valid even if the job failed, so notify either way,
+		// a retried job only notifies the events of the retried runners as the others were notified before
+		var runnerIDs []uint
+		if job.RetriedAt != nil {
+			runnerIDs, err = s.db.ListRunnerIDsRetriedSince(job.ID, *job.RetriedAt)
+			if err != nil {
+				s.logger.Error("failed to list retried runners", zap.Error(err), zap.Uint("jobId", job.ID))
+				continue
+			}
+			if len(runnerIDs) == 0 {
+				continue
+			}
+		}
+		err = s.complianceClient.DispatchComplianceJobNotifications(&httpclient.Context{Ctx: ctx, UserRole: api.InternalRole}, job.ID, runnerIDs)
+		if err != nil {
+			s.logger.Error("failed to dispatch compliance job notifications", zap.Error(err), zap.Uint("jobId", job.ID))
+		}
 	}
 
 	err = s.db.RetryFailedSummarizers()
@@ -191,7 +212,7 @@ func (s *JobScheduler) finishComplianceJob(job model.ComplianceJob) error {
 				}
 				identify = fmt.Sprintf("controls[%s]", strings.Join(uniqIDs, ","))
 			}
-			builder.WriteString(fmt.Sprintf("%s: %s", identify, runner.FailureMessage))
+			builder.WriteString(fmt.Sprintf("%s (%s): %s", identify, runner2.CategorizeFailure(runner.Status, runner.FailureMessage), runner.FailureMessage))
 			if i != len(failedRunners)-1 {
 				builder.WriteString(", ")
 			}
@@ -222,13 +243,17 @@ func (s *JobScheduler) finishComplianceJob(job model.ComplianceJob) error {
 	return s.db.UpdateComplianceJob(job.ID, model.ComplianceJobSucceeded, "")
 }
 
-func (s *JobScheduler) CreateSummarizer(benchmarkId string, jobId *uint, triggerType model.ComplianceTriggerType) error {
+// CreateSummarizer creates a summarizer of the benchmark, when incrementalConnectionIDs is given only the findings
+// of these connections are summarized and merged into the previous summary of the benchmark
+func (s *JobScheduler) CreateSummarizer(benchmarkId string, jobId *uint, triggerType model.ComplianceTriggerType, incrementalConnectionIDs []string) error {
 	// run summarizer
 	dbModel := model.ComplianceSummarizer{
-		BenchmarkID: benchmarkId,
-		StartedAt:   time.Now(),
-		Status:      summarizer.ComplianceSummarizerCreated,
-		TriggerType: triggerType,
+		BenchmarkID:   benchmarkId,
+		StartedAt:     time.Now(),
+		Status:        summarizer.ComplianceSummarizerCreated,
+		TriggerType:   triggerType,
+		Incremental:   len(incrementalConnectionIDs) > 0,
+		ConnectionIDs: incrementalConnectionIDs,
 	}
 	if jobId != nil {
 		dbModel.ParentJobID = *jobId
@@ -245,10 +270,12 @@ func (s *JobScheduler) CreateSummarizer(benchmarkId string, jobId *uint, trigger
 
 func (s *JobScheduler) triggerSummarizer(ctx context.Context, job model.ComplianceSummarizer) error {
 	summarizerJob := types2.Job{
-		ID:          job.ID,
-		RetryCount:  job.RetryCount,
-		BenchmarkID: job.BenchmarkID,
-		CreatedAt:   job.CreatedAt,
+		ID:            job.ID,
+		RetryCount:    job.RetryCount,
+		BenchmarkID:   job.BenchmarkID,
+		CreatedAt:     job.CreatedAt,
+		Incremental:   job.Incremental,
+		ConnectionIDs: job.ConnectionIDs,
 	}
 	jobJson, err := json.Marshal(summarizerJob)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
 	"github.com/kaytu-io/kaytu-util/pkg/source"
 	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"strings"
 	"time"
 
 	"github.com/kaytu-io/open-governance/pkg/compliance/runner"
@@ -18,10 +19,12 @@ func (s *JobScheduler) buildRunners(
 	connector *source.Type,
 	resourceCollectionID *string,
 	rootBenchmarkID string,
+	capturesEvidence bool,
 	parentBenchmarkIDs []string,
 	benchmarkID string,
 	currentRunnerExistMap map[string]bool,
 	triggerType model.ComplianceTriggerType,
+	changedResourceTypes map[string]bool,
 ) ([]*model.ComplianceRunner, []*model.ComplianceRunner, error) {
 	ctx := &httpclient.Context{UserRole: api.InternalRole}
 	var runners []*model.ComplianceRunner
@@ -32,6 +35,10 @@ func (s *JobScheduler) buildRunners(
 		s.logger.Error("error while getting benchmark", zap.Error(err), zap.String("benchmarkID", benchmarkID))
 		return nil, nil, err
 	}
+	// evidence capturing is a setting of the benchmark the job runs, children inherit it
+	if benchmarkID == rootBenchmarkID {
+		capturesEvidence = benchmark.CapturesEvidence
+	}
 	if currentRunnerExistMap == nil {
 		currentRunners, err := s.db.GetRunnersByParentJobID(parentJobID)
 		if err != nil {
@@ -45,7 +52,7 @@ func (s *JobScheduler) buildRunners(
 	}
 
 	for _, child := range benchmark.Children {
-		childRunners, childGlobalRunners, err := s.buildRunners(parentJobID, connectionID, connector, resourceCollectionID, rootBenchmarkID, append(parentBenchmarkIDs, benchmarkID), child, currentRunnerExistMap, triggerType)
+		childRunners, childGlobalRunners, err := s.buildRunners(parentJobID, connectionID, connector, resourceCollectionID, rootBenchmarkID, capturesEvidence, append(parentBenchmarkIDs, benchmarkID), child, currentRunnerExistMap, triggerType, changedResourceTypes)
 		if err != nil {
 			s.logger.Error("error while building child runners", zap.Error(err))
 			return nil, nil, err
@@ -77,10 +84,15 @@ func (s *JobScheduler) buildRunners(
 				continue
 			}
 		}
+		// incremental jobs skip the controls which do not read any changed resource type
+		if changedResourceTypes != nil && !queriesChangedResourceType(control.Query, changedResourceTypes) {
+			continue
+		}
 
 		callers := runner.Caller{
 			RootBenchmark:      rootBenchmarkID,
 			TracksDriftEvents:  benchmark.TracksDriftEvents,
+			CapturesEvidence:   capturesEvidence,
 			ParentBenchmarkIDs: append(parentBenchmarkIDs, benchmarkID),
 			ControlID:          control.ID,
 			ControlSeverity:    control.Severity,
@@ -263,6 +275,14 @@ func (s *JobScheduler) enqueueRunnersCycle() error {
 			assignments.Connections = append(assignments.Connections, assignment)
 		}
 
+		var changedResourceTypes map[string]bool
+		if job.Incremental {
+			changedResourceTypes = make(map[string]bool)
+			for _, resourceType := range job.ChangedResourceTypes {
+				changedResourceTypes[strings.ToLower(resourceType)] = true
+			}
+		}
+
 		var globalRunners []*model.ComplianceRunner
 		var runners []*model.ComplianceRunner
 		for _, it := range assignments.Connections {
@@ -270,7 +290,7 @@ func (s *JobScheduler) enqueueRunnersCycle() error {
 				continue
 			}
 			connection := it
-			runners, globalRunners, err = s.buildRunners(job.ID, &connection.ConnectionID, &connection.Connector, nil, job.BenchmarkID, nil, job.BenchmarkID, nil, job.TriggerType)
+			runners, globalRunners, err = s.buildRunners(job.ID, &connection.ConnectionID, &connection.Connector, nil, job.BenchmarkID, false, nil, job.BenchmarkID, nil, job.TriggerType, changedResourceTypes)
 			if err != nil {
 				s.logger.Error("error while building runners", zap.Error(err))
 				return err
@@ -0,0 +1,267 @@
+package query_runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	authApi "github.com/kaytu-io/kaytu-util/pkg/api"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
+	metadataClient "github.com/kaytu-io/open-governance/pkg/metadata/client"
+	"github.com/kaytu-io/open-governance/pkg/metadata/models"
+	"go.uber.org/zap"
+)
+
+// processScheduledResult keeps the result of a scheduled run in the history of its schedule, records what changed
+// since the previous succeeded run and fires the matching alerts
+func (s *JobScheduler) processScheduledResult(ctx context.Context, job model.QueryRunnerJob, jobResult queryrunner.JobResult) error {
+	schedule, err := s.db.GetQuerySchedule(*job.ScheduleID)
+	if err != nil {
+		return err
+	}
+	if schedule == nil {
+		// the schedule was deleted while the query was running
+		return nil
+	}
+
+	result := model.QueryScheduleResult{
+		ScheduleID:     schedule.ID,
+		JobID:          job.ID,
+		Status:         jobResult.Status,
+		FailureMessage: jobResult.FailureMessage,
+	}
+	var rows [][]string
+	if jobResult.ScheduledResult != nil {
+		rows = jobResult.ScheduledResult.Rows
+		result.ColumnNames = jobResult.ScheduledResult.ColumnNames
+		result.RowCount = jobResult.ScheduledResult.RowCount
+	}
+	rowsJson, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	if err := result.Rows.Set(rowsJson); err != nil {
+		return err
+	}
+	if err := s.db.CreateQueryScheduleResult(&result, schedule.HistorySize); err != nil {
+		return err
+	}
+
+	if result.Status != queryrunner.QueryRunnerSucceeded {
+		return nil
+	}
+
+	previous, err := s.db.GetPreviousSucceededQueryScheduleResult(schedule.ID, result.ID)
+	if err != nil {
+		return err
+	}
+	var previousRows *conditionRows
+	if previous != nil {
+		previousRows = &conditionRows{rows: previous.GetRows(), rowCount: previous.RowCount}
+		if err := s.recordChangelog(*schedule, *previous, previousRows.rows, result, rows); err != nil {
+			// alerts of the run do not depend on the changelog
+			s.logger.Error("failed to record query changelog", zap.Uint("scheduleID", schedule.ID), zap.Uint("resultID", result.ID), zap.Error(err))
+		}
+	}
+	if len(schedule.Conditions) == 0 {
+		return nil
+	}
+
+	var alerts []model.QueryAlert
+	for _, condition := range schedule.Conditions {
+		message, fired, partial := evaluateCondition(condition, result.ColumnNames, conditionRows{rows: rows, rowCount: result.RowCount}, previousRows)
+		if !fired {
+			continue
+		}
+		alert := model.QueryAlert{
+			ScheduleID:    schedule.ID,
+			ConditionID:   condition.ID,
+			ResultID:      result.ID,
+			ConditionType: condition.Type,
+			Message:       message,
+			Partial:       partial,
+			WebhookStatus: api.QueryAlertWebhookNotConfigured,
+		}
+		if err := s.db.CreateQueryAlert(&alert); err != nil {
+			return err
+		}
+		alerts = append(alerts, alert)
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	// deliveries are retried with backoff, they should not hold the result consumer
+	go s.deliverAlerts(ctx, *schedule, result.RowCount, alerts)
+	return nil
+}
+
+// recordChangelog keeps the compact diff of the result against the previous succeeded result if any row changed
+func (s *JobScheduler) recordChangelog(schedule model.QuerySchedule, previous model.QueryScheduleResult, previousRows [][]string, result model.QueryScheduleResult, rows [][]string) error {
+	diff, err := queryrunner.DiffResults(previous.ColumnNames, previousRows, result.ColumnNames, rows, schedule.KeyColumns)
+	if err != nil {
+		return err
+	}
+	if !diff.HasChanges() {
+		return nil
+	}
+
+	diffJson, err := json.Marshal(diff.Compact(QueryChangelogRowsLimit))
+	if err != nil {
+		return err
+	}
+	changelog := model.QueryScheduleChangelog{
+		ScheduleID:       schedule.ID,
+		ResultID:         result.ID,
+		PreviousResultID: previous.ID,
+		AddedCount:       diff.AddedCount,
+		RemovedCount:     diff.RemovedCount,
+		ChangedCount:     diff.ChangedCount,
+		Partial:          previous.RowCount > len(previousRows) || result.RowCount > len(rows),
+	}
+	if err := changelog.Diff.Set(diffJson); err != nil {
+		return err
+	}
+	return s.db.CreateQueryScheduleChangelog(&changelog, MaxQueryChangelogEntries)
+}
+
+// conditionRows are the rows of a result kept by queryrunner.NewScheduledResult and the row count of the whole result
+type conditionRows struct {
+	rows     [][]string
+	rowCount int
+}
+
+// capped tells if the result had more rows than were kept
+func (r conditionRows) capped() bool {
+	return r.rowCount > len(r.rows)
+}
+
+// evaluateCondition tells if the condition fires on the result and why, previous is nil on the first run of the schedule.
+// Only the kept rows of capped results can be looked at, so conditions on rows fire as partial with the count of the kept
+// rows, and new rows are not looked for when the previous result was capped since the rows beyond its cap are not known.
+func evaluateCondition(condition model.QueryAlertCondition, columnNames []string, result conditionRows, previous *conditionRows) (message string, fired bool, partial bool) {
+	switch condition.Type {
+	case api.QueryAlertConditionRowCountAbove:
+		if result.rowCount > condition.Threshold {
+			return fmt.Sprintf("row count %d is above %d", result.rowCount, condition.Threshold), true, false
+		}
+	case api.QueryAlertConditionNewRows:
+		if previous == nil || previous.capped() {
+			// the first run has nothing to compare to
+			return "", false, false
+		}
+		seen := make(map[string]bool)
+		for _, row := range previous.rows {
+			seen[rowKey(row)] = true
+		}
+		newRows := 0
+		for _, row := range result.rows {
+			if !seen[rowKey(row)] {
+				newRows++
+			}
+		}
+		if newRows > 0 {
+			return fmt.Sprintf("%s%d new rows since the previous run", atLeast(result), newRows), true, result.capped()
+		}
+	case api.QueryAlertConditionColumnValue:
+		columnIdx := -1
+		for i, name := range columnNames {
+			if strings.EqualFold(name, condition.ColumnName) {
+				columnIdx = i
+				break
+			}
+		}
+		if columnIdx < 0 {
+			return "", false, false
+		}
+		matches := 0
+		for _, row := range result.rows {
+			if columnIdx < len(row) && row[columnIdx] == condition.Value {
+				matches++
+			}
+		}
+		if matches > 0 {
+			return fmt.Sprintf("%s%d rows have %s = %s", atLeast(result), matches, condition.ColumnName, condition.Value), true, result.capped()
+		}
+	}
+	return "", false, false
+}
+
+// atLeast prefixes the counts of capped results since the rows beyond the cap are not counted
+func atLeast(result conditionRows) string {
+	if result.capped() {
+		return "at least "
+	}
+	return ""
+}
+
+func rowKey(row []string) string {
+	return strings.Join(row, "\x1f")
+}
+
+// deliverAlerts posts the alerts to the outbound webhook of the workspace and records the delivery outcome on them
+func (s *JobScheduler) deliverAlerts(ctx context.Context, schedule model.QuerySchedule, rowCount int, alerts []model.QueryAlert) {
+	webhookURL, err := s.outboundWebhookURL(ctx)
+	if err != nil {
+		s.logger.Error("failed to get outbound webhook url", zap.Error(err))
+		for _, alert := range alerts {
+			_ = s.db.UpdateQueryAlertWebhook(alert.ID, api.QueryAlertWebhookFailed, 0, err.Error())
+		}
+		return
+	}
+	if webhookURL == "" {
+		return
+	}
+
+	for _, alert := range alerts {
+		body, err := json.Marshal(api.QueryAlertPayload{
+			Type:          "query_alert",
+			WorkspaceName: s.workspaceName,
+			AlertID:       alert.ID,
+			ScheduleID:    schedule.ID,
+			ScheduleName:  schedule.Name,
+			SavedQueryID:  schedule.SavedQueryID,
+			ConditionType: alert.ConditionType,
+			Message:       alert.Message,
+			Partial:       alert.Partial,
+			RowCount:      rowCount,
+			FiredAt:       alert.CreatedAt,
+		})
+		if err != nil {
+			s.logger.Error("failed to marshal query alert", zap.Uint("alertID", alert.ID), zap.Error(err))
+			continue
+		}
+
+		delivery := s.webhookSender.Send(ctx, webhookURL, s.conf.OutboundWebhookSecret, alert.ID, body)
+		status, errMsg := api.QueryAlertWebhookDelivered, ""
+		if delivery.Err != nil {
+			status, errMsg = api.QueryAlertWebhookFailed, delivery.Err.Error()
+			s.logger.Warn("failed to deliver query alert", zap.Uint("alertID", alert.ID), zap.Int("attempts", delivery.Attempts), zap.Error(delivery.Err))
+		}
+		if err := s.db.UpdateQueryAlertWebhook(alert.ID, status, delivery.StatusCode, errMsg); err != nil {
+			s.logger.Error("failed to update query alert delivery", zap.Uint("alertID", alert.ID), zap.Error(err))
+		}
+	}
+}
+
+// outboundWebhookURL returns the outbound webhook configured for the workspace, empty if there is none
+func (s *JobScheduler) outboundWebhookURL(ctx context.Context) (string, error) {
+	clientCtx := &httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}
+	cnf, err := s.metadataClient.GetConfigMetadata(clientCtx, models.MetadataKeyOutboundWebhookURL)
+	if err != nil {
+		if errors.Is(err, metadataClient.ErrConfigNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	webhookURL, ok := cnf.GetValue().(string)
+	if !ok {
+		return "", fmt.Errorf("outbound webhook url is not a string")
+	}
+	return strings.TrimSpace(webhookURL), nil
+}
@@ -0,0 +1,190 @@
+package query_runner
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	columnNames := []string{"id", "State"}
+	rows := [][]string{{"i-1", "running"}, {"i-2", "stopped"}, {"i-3", "running"}}
+
+	tests := []struct {
+		name        string
+		condition   model.QueryAlertCondition
+		rowCount    int
+		previous    *conditionRows
+		wantFired   bool
+		wantPartial bool
+		wantMessage string
+	}{
+		{
+			name:        "row count above threshold",
+			condition:   model.QueryAlertCondition{Type: api.QueryAlertConditionRowCountAbove, Threshold: 2},
+			rowCount:    3,
+			wantFired:   true,
+			wantMessage: "row count 3 is above 2",
+		},
+		{
+			name:      "row count at threshold",
+			condition: model.QueryAlertCondition{Type: api.QueryAlertConditionRowCountAbove, Threshold: 3},
+			rowCount:  3,
+		},
+		{
+			name:        "row count of the whole result is used",
+			condition:   model.QueryAlertCondition{Type: api.QueryAlertConditionRowCountAbove, Threshold: 100},
+			rowCount:    2500,
+			wantFired:   true,
+			wantMessage: "row count 2500 is above 100",
+		},
+		{
+			name:      "new rows on the first run",
+			condition: model.QueryAlertCondition{Type: api.QueryAlertConditionNewRows},
+			rowCount:  3,
+		},
+		{
+			name:        "new rows since the previous run",
+			condition:   model.QueryAlertCondition{Type: api.QueryAlertConditionNewRows},
+			rowCount:    3,
+			previous:    &conditionRows{rows: [][]string{{"i-1", "running"}, {"i-2", "running"}}, rowCount: 2},
+			wantFired:   true,
+			wantMessage: "2 new rows since the previous run",
+		},
+		{
+			name:      "no new rows",
+			condition: model.QueryAlertCondition{Type: api.QueryAlertConditionNewRows},
+			rowCount:  3,
+			previous:  &conditionRows{rows: [][]string{{"i-3", "running"}, {"i-2", "stopped"}, {"i-1", "running"}, {"i-4", "running"}}, rowCount: 4},
+		},
+		{
+			name:        "empty previous run",
+			condition:   model.QueryAlertCondition{Type: api.QueryAlertConditionNewRows},
+			rowCount:    3,
+			previous:    &conditionRows{rows: [][]string{}},
+			wantFired:   true,
+			wantMessage: "3 new rows since the previous run",
+		},
+		{
+			name:        "new rows of a capped result are partial",
+			condition:   model.QueryAlertCondition{Type: api.QueryAlertConditionNewRows},
+			rowCount:    2500,
+			previous:    &conditionRows{rows: [][]string{{"i-1", "running"}}, rowCount: 1},
+			wantFired:   true,
+			wantPartial: true,
+			wantMessage: "at least 2 new rows since the previous run",
+		},
+		{
+			name:      "new rows are not looked for after a capped result",
+			condition: model.QueryAlertCondition{Type: api.QueryAlertConditionNewRows},
+			rowCount:  3,
+			previous:  &conditionRows{rows: [][]string{{"i-1", "running"}}, rowCount: 2500},
+		},
+		{
+			name:        "column value with case insensitive column name",
+			condition:   model.QueryAlertCondition{Type: api.QueryAlertConditionColumnValue, ColumnName: "state", Value: "running"},
+			rowCount:    3,
+			wantFired:   true,
+			wantMessage: "2 rows have state = running",
+		},
+		{
+			name:      "column value is case sensitive",
+			condition: model.QueryAlertCondition{Type: api.QueryAlertConditionColumnValue, ColumnName: "State", Value: "Running"},
+			rowCount:  3,
+		},
+		{
+			name:        "column value on a capped result is partial",
+			condition:   model.QueryAlertCondition{Type: api.QueryAlertConditionColumnValue, ColumnName: "State", Value: "stopped"},
+			rowCount:    2500,
+			wantFired:   true,
+			wantPartial: true,
+			wantMessage: "at least 1 rows have State = stopped",
+		},
+		{
+			name:      "column value missing from the kept rows of a capped result",
+			condition: model.QueryAlertCondition{Type: api.QueryAlertConditionColumnValue, ColumnName: "State", Value: "terminated"},
+			rowCount:  2500,
+		},
+		{
+			name:      "unknown column",
+			condition: model.QueryAlertCondition{Type: api.QueryAlertConditionColumnValue, ColumnName: "region", Value: "us-east-1"},
+			rowCount:  3,
+		},
+		{
+			name:      "unknown condition type",
+			condition: model.QueryAlertCondition{Type: "row_count_below", Threshold: 10},
+			rowCount:  3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			message, fired, partial := evaluateCondition(tc.condition, columnNames, conditionRows{rows: rows, rowCount: tc.rowCount}, tc.previous)
+			assert.Equal(t, tc.wantFired, fired)
+			assert.Equal(t, tc.wantPartial, partial)
+			assert.Equal(t, tc.wantMessage, message)
+		})
+	}
+
+	t.Run("short rows are skipped", func(t *testing.T) {
+		condition := model.QueryAlertCondition{Type: api.QueryAlertConditionColumnValue, ColumnName: "State", Value: "running"}
+		message, fired, partial := evaluateCondition(condition, columnNames, conditionRows{rows: [][]string{{"i-1"}, {"i-2", "running"}}, rowCount: 2}, nil)
+		assert.True(t, fired)
+		assert.False(t, partial)
+		assert.Equal(t, "1 rows have State = running", message)
+	})
+}
+
+func TestNewAlertConditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		reqs    []api.QueryAlertConditionRequest
+		want    []model.QueryAlertCondition
+		wantErr string
+	}{
+		{
+			name: "no conditions",
+			want: []model.QueryAlertCondition{},
+		},
+		{
+			name: "every condition type",
+			reqs: []api.QueryAlertConditionRequest{
+				{Type: api.QueryAlertConditionRowCountAbove, Threshold: 10},
+				{Type: api.QueryAlertConditionNewRows},
+				{Type: api.QueryAlertConditionColumnValue, ColumnName: "state", Value: "stopped"},
+			},
+			want: []model.QueryAlertCondition{
+				{Type: api.QueryAlertConditionRowCountAbove, Threshold: 10},
+				{Type: api.QueryAlertConditionNewRows},
+				{Type: api.QueryAlertConditionColumnValue, ColumnName: "state", Value: "stopped"},
+			},
+		},
+		{
+			name:    "column value without a column",
+			reqs:    []api.QueryAlertConditionRequest{{Type: api.QueryAlertConditionColumnValue, Value: "stopped"}},
+			wantErr: "columnName is required for column_value conditions",
+		},
+		{
+			name: "invalid type after valid ones",
+			reqs: []api.QueryAlertConditionRequest{
+				{Type: api.QueryAlertConditionNewRows},
+				{Type: "row_count_below", Threshold: 10},
+			},
+			wantErr: "invalid alert condition type row_count_below",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conditions, err := NewAlertConditions(tc.reqs)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				assert.Nil(t, conditions)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, conditions)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package query_runner
 import (
 	"context"
 	"encoding/json"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
 	"github.com/nats-io/nats.go/jetstream"
 	"go.uber.org/zap"
@@ -10,13 +11,10 @@ import (
 
 func (s *JobScheduler) RunQueryRunnerReportJobResultsConsumer(ctx context.Context) error {
 	if _, err := s.jq.Consume(ctx, "scheduler-query-runner", queryrunner.StreamName, []string{queryrunner.JobResultQueueTopic}, "scheduler-query-runner", func(msg jetstream.Msg) {
-		if err := msg.Ack(); err != nil {
-			s.logger.Error("Failed committing message", zap.Error(err))
-		}
-
 		var result queryrunner.JobResult
 		if err := json.Unmarshal(msg.Data(), &result); err != nil {
 			s.logger.Error("Failed to unmarshal ComplianceReportJob results", zap.Error(err))
+			s.dlq.Fail(ctx, "scheduler-query-runner", msg, err, 1)
 			return
 		}
 
@@ -29,8 +27,27 @@ func (s *JobScheduler) RunQueryRunnerReportJobResultsConsumer(ctx context.Contex
 			s.logger.Error("Failed to update the status of QueryRunnerReportJob",
 				zap.Uint("jobId", result.ID),
 				zap.Error(err))
+			s.dlq.Fail(ctx, "scheduler-query-runner", msg, err, dlq.DefaultMaxAttempts)
 			return
 		}
+
+		if result.Status == queryrunner.QueryRunnerSucceeded || result.Status == queryrunner.QueryRunnerFailed {
+			job, err := s.db.GetQueryRunnerJob(result.ID)
+			if err != nil {
+				s.logger.Error("Failed to get QueryRunnerJob", zap.Uint("jobId", result.ID), zap.Error(err))
+			} else if job != nil && job.ScheduleID != nil {
+				if err := s.processScheduledResult(ctx, *job, result); err != nil {
+					s.logger.Error("Failed to process scheduled query result",
+						zap.Uint("jobId", result.ID),
+						zap.Uint("scheduleId", *job.ScheduleID),
+						zap.Error(err))
+				}
+			}
+		}
+
+		if err := msg.Ack(); err != nil {
+			s.logger.Error("Failed committing message", zap.Error(err))
+		}
 	}); err != nil {
 		return err
 	}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/kaytu-io/kaytu-util/pkg/api"
 	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
 	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
 	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
 	"go.uber.org/zap"
@@ -36,28 +37,18 @@ func (s *JobScheduler) runPublisher(ctx context.Context) error {
 	}
 	s.logger.Info("Fetch Created Query Runner Jobs", zap.Any("Jobs Count", len(jobs)))
 	for _, job := range jobs {
-		namedQuery, err := s.inventoryClient.GetQuery(ctx2, job.QueryId)
-		if err != nil {
-			s.logger.Error("Get Query Error", zap.Error(err))
-		}
-		controlQuery, err := s.complianceClient.GetControlDetails(ctx2, job.QueryId)
-		if err != nil {
-			s.logger.Error("Get Control Error", zap.Error(err))
-		}
 		var query string
 		var parameters []inventoryApi.QueryParameter
-		if namedQuery != nil {
-			query = namedQuery.Query.QueryToExecute
-			parameters = namedQuery.Query.Parameters
-		} else if controlQuery != nil {
-			query = controlQuery.Query.QueryToExecute
-			for _, qp := range controlQuery.Query.Parameters {
-				parameters = append(parameters, inventoryApi.QueryParameter{
-					Key:      qp.Key,
-					Required: qp.Required,
-				})
+		if job.ScheduleID != nil {
+			query, parameters, err = s.getScheduledQuery(ctx, job)
+			if err != nil {
+				_ = s.db.UpdateQueryRunnerJobStatus(job.ID, queryrunner.QueryRunnerFailed, err.Error())
+				continue
 			}
 		} else {
+			query, parameters = s.getQuery(ctx2, job)
+		}
+		if query == "" {
 			_ = s.db.UpdateQueryRunnerJobStatus(job.ID, queryrunner.QueryRunnerFailed, "query ID not found")
 			continue
 		}
@@ -88,7 +79,9 @@ func (s *JobScheduler) runPublisher(ctx context.Context) error {
 			CreatedBy:   job.CreatedBy,
 			TriggeredAt: job.CreatedAt.UnixMilli(),
 			QueryId:     job.QueryId,
+			Parameters:  parameters,
 			Query:       queryOutput.String(),
+			ScheduleID:  job.ScheduleID,
 		}
 
 		jobJson, err := json.Marshal(runnerJobMsg)
@@ -128,3 +121,45 @@ func (s *JobScheduler) runPublisher(ctx context.Context) error {
 	}
 	return nil
 }
+
+// getQuery returns the query of a named query or control run, empty if the query is not found
+func (s *JobScheduler) getQuery(ctx *httpclient.Context, job model.QueryRunnerJob) (string, []inventoryApi.QueryParameter) {
+	namedQuery, err := s.inventoryClient.GetQuery(ctx, job.QueryId)
+	if err != nil {
+		s.logger.Error("Get Query Error", zap.Error(err))
+	}
+	controlQuery, err := s.complianceClient.GetControlDetails(ctx, job.QueryId)
+	if err != nil {
+		s.logger.Error("Get Control Error", zap.Error(err))
+	}
+	var parameters []inventoryApi.QueryParameter
+	if namedQuery != nil {
+		return namedQuery.Query.QueryToExecute, namedQuery.Query.Parameters
+	} else if controlQuery != nil {
+		for _, qp := range controlQuery.Query.Parameters {
+			parameters = append(parameters, inventoryApi.QueryParameter{
+				Key:      qp.Key,
+				Required: qp.Required,
+			})
+		}
+		return controlQuery.Query.QueryToExecute, parameters
+	}
+	return "", nil
+}
+
+// getScheduledQuery returns the saved query of a scheduled run, fetched on behalf of the schedule creator
+func (s *JobScheduler) getScheduledQuery(ctx context.Context, job model.QueryRunnerJob) (string, []inventoryApi.QueryParameter, error) {
+	ctx2 := &httpclient.Context{Ctx: ctx, UserRole: api.InternalRole, UserID: job.CreatedBy}
+	savedQuery, err := s.inventoryClient.GetSavedQuery(ctx2, job.QueryId)
+	if err != nil {
+		s.logger.Error("Get Saved Query Error", zap.Uint("jobId", job.ID), zap.Error(err))
+		return "", nil, fmt.Errorf("failed to get saved query: %w", err)
+	}
+	if savedQuery == nil {
+		return "", nil, fmt.Errorf("saved query %s not found or not visible to %s", job.QueryId, job.CreatedBy)
+	}
+	if savedQuery.Engine != "" && savedQuery.Engine != inventoryApi.QueryEngine_OdysseusSQL {
+		return "", nil, fmt.Errorf("only %s saved queries can be scheduled", inventoryApi.QueryEngine_OdysseusSQL)
+	}
+	return savedQuery.QueryToExecute, savedQuery.Parameters, nil
+}
@@ -0,0 +1,105 @@
+package query_runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
+	"go.uber.org/zap"
+)
+
+const (
+	QueryScheduleInterval   = time.Minute
+	DefaultQueryHistorySize = 20
+
+	MaxQueryChangelogEntries = 500 // Changelog entries kept per schedule
+	QueryChangelogRowsLimit  = 100 // Rows kept in each list of a changelog entry
+)
+
+func (s *JobScheduler) RunQuerySchedules(ctx context.Context) {
+	s.logger.Info("Scheduling saved queries on a timer")
+
+	t := ticker.NewTicker(QueryScheduleInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		if err := s.runQuerySchedules(); err != nil {
+			s.logger.Error("failed to run query schedules", zap.Error(err))
+			continue
+		}
+	}
+}
+
+func (s *JobScheduler) runQuerySchedules() error {
+	now := time.Now()
+	schedules, err := s.db.ListDueQuerySchedules(now)
+	if err != nil {
+		s.logger.Error("failed to list due query schedules", zap.Error(err))
+		return err
+	}
+	for _, schedule := range schedules {
+		// the next run is computed before creating the job so a failing schedule does not run on every tick
+		if err := schedule.ScheduleNext(now); err != nil {
+			s.logger.Error("invalid query schedule cron expression", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+			schedule.NextRunAt = nil
+		}
+		if err := s.db.UpdateQueryScheduleRun(schedule.ID, now, schedule.NextRunAt); err != nil {
+			s.logger.Error("failed to update query schedule next run", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+			return err
+		}
+
+		unfinished, err := s.db.CountUnfinishedQueryRunnerJobsOfSchedule(schedule.ID)
+		if err != nil {
+			s.logger.Error("failed to count unfinished runs of query schedule", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+			return err
+		}
+		if unfinished > 0 {
+			s.logger.Info("skipping query schedule run, previous run is not finished", zap.Uint("scheduleID", schedule.ID))
+			continue
+		}
+
+		if _, err := s.db.CreateQueryRunnerJob(NewScheduledQueryRunnerJob(schedule)); err != nil {
+			s.logger.Error("failed to create scheduled query runner job", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// NewScheduledQueryRunnerJob returns a run of the saved query of the schedule, to be picked up by the publisher
+func NewScheduledQueryRunnerJob(schedule model.QuerySchedule) *model.QueryRunnerJob {
+	scheduleID := schedule.ID
+	return &model.QueryRunnerJob{
+		QueryId:    schedule.SavedQueryID,
+		CreatedBy:  schedule.CreatedBy,
+		Status:     queryrunner.QueryRunnerCreated,
+		ScheduleID: &scheduleID,
+	}
+}
+
+// NewAlertConditions validates the requested alert conditions of a query schedule
+func NewAlertConditions(reqs []api.QueryAlertConditionRequest) ([]model.QueryAlertCondition, error) {
+	conditions := make([]model.QueryAlertCondition, 0, len(reqs))
+	for _, req := range reqs {
+		switch req.Type {
+		case api.QueryAlertConditionRowCountAbove, api.QueryAlertConditionNewRows:
+		case api.QueryAlertConditionColumnValue:
+			if req.ColumnName == "" {
+				return nil, fmt.Errorf("columnName is required for %s conditions", req.Type)
+			}
+		default:
+			return nil, fmt.Errorf("invalid alert condition type %s", req.Type)
+		}
+		conditions = append(conditions, model.QueryAlertCondition{
+			Type:       req.Type,
+			Threshold:  req.Threshold,
+			ColumnName: req.ColumnName,
+			Value:      req.Value,
+		})
+	}
+	return conditions, nil
+}
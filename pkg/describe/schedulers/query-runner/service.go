@@ -3,12 +3,14 @@ package query_runner
 import (
 	"context"
 	"github.com/kaytu-io/kaytu-util/pkg/jq"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	metadataClient "github.com/kaytu-io/open-governance/pkg/metadata/client"
 	"time"
 
 	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
 	"github.com/kaytu-io/kaytu-util/pkg/ticker"
 	complianceClient "github.com/kaytu-io/open-governance/pkg/compliance/client"
+	"github.com/kaytu-io/open-governance/pkg/compliance/notification"
 	"github.com/kaytu-io/open-governance/pkg/describe/config"
 	"github.com/kaytu-io/open-governance/pkg/describe/db"
 	inventoryClient "github.com/kaytu-io/open-governance/pkg/inventory/client"
@@ -24,10 +26,13 @@ type JobScheduler struct {
 	logger              *zap.Logger
 	db                  db.Database
 	jq                  *jq.JobQueue
+	dlq                 *dlq.DeadLetterQueue
 	esClient            kaytu.Client
 	inventoryClient     inventoryClient.InventoryServiceClient
 	complianceClient    complianceClient.ComplianceServiceClient
 	metadataClient      metadataClient.MetadataServiceClient
+	workspaceName       string
+	webhookSender       *notification.WebhookSender
 }
 
 func New(
@@ -36,10 +41,12 @@ func New(
 	logger *zap.Logger,
 	db db.Database,
 	jq *jq.JobQueue,
+	dlq *dlq.DeadLetterQueue,
 	esClient kaytu.Client,
 	inventoryClient inventoryClient.InventoryServiceClient,
 	complianceClient complianceClient.ComplianceServiceClient,
 	metadataClient metadataClient.MetadataServiceClient,
+	workspaceName string,
 ) *JobScheduler {
 	return &JobScheduler{
 		runSetupNatsStreams: runSetupNatsStreams,
@@ -47,10 +54,13 @@ func New(
 		logger:              logger,
 		db:                  db,
 		jq:                  jq,
+		dlq:                 dlq,
 		esClient:            esClient,
 		inventoryClient:     inventoryClient,
 		complianceClient:    complianceClient,
 		metadataClient:      metadataClient,
+		workspaceName:       workspaceName,
+		webhookSender:       notification.NewWebhookSender(),
 	}
 }
 
@@ -58,6 +68,9 @@ func (s *JobScheduler) Run(ctx context.Context) {
 	utils.EnsureRunGoroutine(func() {
 		s.RunPublisher(ctx)
 	})
+	utils.EnsureRunGoroutine(func() {
+		s.RunQuerySchedules(ctx)
+	})
 	utils.EnsureRunGoroutine(func() {
 		s.logger.Fatal("ComplianceReportJobResult consumer exited", zap.Error(s.RunQueryRunnerReportJobResultsConsumer(ctx)))
 	})
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	authApi "github.com/kaytu-io/kaytu-util/pkg/api"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"go.uber.org/zap"
+)
+
+const topFailingControlsCount = 10
+
+type reportData struct {
+	WorkspaceName      string
+	GeneratedAt        time.Time
+	TrendDays          int
+	Summary            complianceApi.BenchmarkEvaluationSummary
+	TopFailingControls []complianceApi.ControlSummary
+	Connections        []complianceApi.AccountsFindingsSummary
+	Trend              []complianceApi.BenchmarkTrendDatapoint
+}
+
+func (d reportData) PassedPercentage() float64 {
+	return passedPercentage(d.Summary.ConformanceStatusSummary)
+}
+
+func passedPercentage(summary complianceApi.ConformanceStatusSummary) float64 {
+	total := summary.PassedCount + summary.FailedCount
+	if total == 0 {
+		return 0
+	}
+	return float64(summary.PassedCount) * 100 / float64(total)
+}
+
+// flattenControls returns the controls of the benchmark tree, controls shared between children are only listed once
+func flattenControls(summary complianceApi.BenchmarkControlSummary, seen map[string]bool) []complianceApi.ControlSummary {
+	var controls []complianceApi.ControlSummary
+	for _, control := range summary.Controls {
+		if seen[control.Control.ID] {
+			continue
+		}
+		seen[control.Control.ID] = true
+		controls = append(controls, control)
+	}
+	for _, child := range summary.Children {
+		controls = append(controls, flattenControls(child, seen)...)
+	}
+	return controls
+}
+
+// gatherReportData collects the report content with the internal role, connectionIDs are resolved to the connections
+// the run creator can access when the run is created so they are the only thing scoping the report
+func (s *Scheduler) gatherReportData(ctx context.Context, benchmarkID string, connectionIDs []string, trendDays int) (*reportData, error) {
+	clientCtx := &httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}
+	now := time.Now()
+
+	summary, err := s.complianceClient.GetBenchmarkSummary(clientCtx, benchmarkID, connectionIDs, nil)
+	if err != nil {
+		s.logger.Error("failed to get benchmark summary", zap.String("benchmarkID", benchmarkID), zap.Error(err))
+		return nil, err
+	}
+
+	controlSummary, err := s.complianceClient.GetBenchmarkControls(clientCtx, benchmarkID, connectionIDs, nil)
+	if err != nil {
+		s.logger.Error("failed to get benchmark controls", zap.String("benchmarkID", benchmarkID), zap.Error(err))
+		return nil, err
+	}
+	var failingControls []complianceApi.ControlSummary
+	for _, control := range flattenControls(*controlSummary, map[string]bool{}) {
+		if control.FailedResourcesCount > 0 {
+			failingControls = append(failingControls, control)
+		}
+	}
+	sort.SliceStable(failingControls, func(i, j int) bool {
+		if failingControls[i].FailedResourcesCount != failingControls[j].FailedResourcesCount {
+			return failingControls[i].FailedResourcesCount > failingControls[j].FailedResourcesCount
+		}
+		return failingControls[i].Control.Severity.Level() > failingControls[j].Control.Severity.Level()
+	})
+	if len(failingControls) > topFailingControlsCount {
+		failingControls = failingControls[:topFailingControlsCount]
+	}
+
+	accounts, err := s.complianceClient.GetAccountsFindingsSummary(clientCtx, benchmarkID, connectionIDs, nil)
+	if err != nil {
+		s.logger.Error("failed to get accounts findings summary", zap.String("benchmarkID", benchmarkID), zap.Error(err))
+		return nil, err
+	}
+	sort.SliceStable(accounts.Accounts, func(i, j int) bool {
+		return accounts.Accounts[i].SecurityScore < accounts.Accounts[j].SecurityScore
+	})
+
+	startTime := now.AddDate(0, 0, -trendDays)
+	trend, err := s.complianceClient.GetBenchmarkTrend(clientCtx, benchmarkID, connectionIDs, &startTime, &now)
+	if err != nil {
+		s.logger.Error("failed to get benchmark trend", zap.String("benchmarkID", benchmarkID), zap.Error(err))
+		return nil, err
+	}
+	sort.Slice(trend, func(i, j int) bool {
+		return trend[i].Timestamp.Before(trend[j].Timestamp)
+	})
+
+	return &reportData{
+		WorkspaceName:      s.workspaceName,
+		GeneratedAt:        now,
+		TrendDays:          trendDays,
+		Summary:            *summary,
+		TopFailingControls: failingControls,
+		Connections:        accounts.Accounts,
+		Trend:              trend,
+	}, nil
+}
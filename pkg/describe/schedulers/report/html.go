@@ -0,0 +1,100 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percentage": func(v float64) string {
+		return fmt.Sprintf("%.1f%%", v)
+	},
+	"passedPercentage": passedPercentage,
+	"formatTime": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+	"formatDate": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02")
+	},
+	"connectionFailed": func(account complianceApi.AccountsFindingsSummary) int {
+		return account.ConformanceStatusesCount.Failed + account.ConformanceStatusesCount.Error
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Summary.Title}} compliance report</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #1f2937; margin: 32px; }
+h1 { margin-bottom: 4px; }
+h2 { margin-top: 32px; border-bottom: 1px solid #e5e7eb; padding-bottom: 4px; }
+.muted { color: #6b7280; font-size: 13px; }
+.cards { display: flex; gap: 16px; margin-top: 16px; }
+.card { border: 1px solid #e5e7eb; border-radius: 6px; padding: 12px 16px; min-width: 120px; }
+.card .value { font-size: 24px; font-weight: bold; }
+table { border-collapse: collapse; width: 100%; margin-top: 8px; font-size: 13px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #e5e7eb; }
+th { background: #f9fafb; }
+.bar { background: #fee2e2; height: 10px; width: 200px; }
+.bar div { background: #16a34a; height: 10px; }
+</style>
+</head>
+<body>
+<h1>{{.Summary.Title}}</h1>
+<div class="muted">Workspace {{.WorkspaceName}} &middot; Generated at {{formatTime .GeneratedAt}}{{if .Summary.EvaluatedAt}} &middot; Last evaluated at {{formatTime .Summary.EvaluatedAt}}{{end}}</div>
+
+<h2>Summary</h2>
+<div class="cards">
+<div class="card"><div class="muted">Passed</div><div class="value">{{percentage .PassedPercentage}}</div></div>
+<div class="card"><div class="muted">Passed findings</div><div class="value">{{.Summary.ConformanceStatusSummary.PassedCount}}</div></div>
+<div class="card"><div class="muted">Failed findings</div><div class="value">{{.Summary.ConformanceStatusSummary.FailedCount}}</div></div>
+<div class="card"><div class="muted">Excepted findings</div><div class="value">{{.Summary.ConformanceStatusSummary.ExceptedCount}}</div></div>
+<div class="card"><div class="muted">Passed controls</div><div class="value">{{.Summary.ControlsSeverityStatus.Total.PassedCount}} / {{.Summary.ControlsSeverityStatus.Total.TotalCount}}</div></div>
+</div>
+<table>
+<tr><th>Severity</th><th>Failed findings</th><th>Passed controls</th></tr>
+<tr><td>Critical</td><td>{{.Summary.Checks.CriticalCount}}</td><td>{{.Summary.ControlsSeverityStatus.Critical.PassedCount}} / {{.Summary.ControlsSeverityStatus.Critical.TotalCount}}</td></tr>
+<tr><td>High</td><td>{{.Summary.Checks.HighCount}}</td><td>{{.Summary.ControlsSeverityStatus.High.PassedCount}} / {{.Summary.ControlsSeverityStatus.High.TotalCount}}</td></tr>
+<tr><td>Medium</td><td>{{.Summary.Checks.MediumCount}}</td><td>{{.Summary.ControlsSeverityStatus.Medium.PassedCount}} / {{.Summary.ControlsSeverityStatus.Medium.TotalCount}}</td></tr>
+<tr><td>Low</td><td>{{.Summary.Checks.LowCount}}</td><td>{{.Summary.ControlsSeverityStatus.Low.PassedCount}} / {{.Summary.ControlsSeverityStatus.Low.TotalCount}}</td></tr>
+<tr><td>None</td><td>{{.Summary.Checks.NoneCount}}</td><td>{{.Summary.ControlsSeverityStatus.None.PassedCount}} / {{.Summary.ControlsSeverityStatus.None.TotalCount}}</td></tr>
+</table>
+
+<h2>Top failing controls</h2>
+{{if .TopFailingControls}}
+<table>
+<tr><th>Control</th><th>Severity</th><th>Failed resources</th><th>Failed connections</th></tr>
+{{range .TopFailingControls}}<tr><td>{{.Control.Title}}<div class="muted">{{.Control.ID}}</div></td><td>{{.Control.Severity}}</td><td>{{.FailedResourcesCount}} / {{.TotalResourcesCount}}</td><td>{{.FailedConnectionCount}} / {{.TotalConnectionCount}}</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No failing controls.</p>{{end}}
+
+<h2>Connections</h2>
+{{if .Connections}}
+<table>
+<tr><th>Connection</th><th>Security score</th><th>Failed</th><th>Passed</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Last check</th></tr>
+{{range .Connections}}<tr><td>{{.AccountName}}<div class="muted">{{.AccountId}}</div></td><td>{{percentage .SecurityScore}}</td><td>{{connectionFailed .}}</td><td>{{.ConformanceStatusesCount.Passed}}</td><td>{{.SeveritiesCount.Critical}}</td><td>{{.SeveritiesCount.High}}</td><td>{{.SeveritiesCount.Medium}}</td><td>{{.SeveritiesCount.Low}}</td><td>{{formatTime .LastCheckTime}}</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No connections evaluated.</p>{{end}}
+
+<h2>Trend over the last {{.TrendDays}} days</h2>
+{{if .Trend}}
+<table>
+<tr><th>Date</th><th>Passed</th><th></th><th>Passed findings</th><th>Failed findings</th></tr>
+{{range .Trend}}{{$passed := passedPercentage .ConformanceStatusSummary}}<tr><td>{{formatDate .Timestamp}}</td><td>{{percentage $passed}}</td><td><div class="bar"><div style="width: {{printf "%.0f" $passed}}%"></div></div></td><td>{{.ConformanceStatusSummary.PassedCount}}</td><td>{{.ConformanceStatusSummary.FailedCount}}</td></tr>
+{{end}}</table>
+{{else}}<p class="muted">No evaluations in this period.</p>{{end}}
+</body>
+</html>
+`))
+
+func renderHTML(data reportData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
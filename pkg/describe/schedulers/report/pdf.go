@@ -0,0 +1,295 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	pdfPageWidth  = 595.0 // A4 in points
+	pdfPageHeight = 842.0
+	pdfMargin     = 40.0
+)
+
+type pdfColor struct {
+	R, G, B float64
+}
+
+var (
+	pdfColorText  = pdfColor{0.12, 0.16, 0.22}
+	pdfColorMuted = pdfColor{0.42, 0.45, 0.50}
+	pdfColorLine  = pdfColor{0.90, 0.91, 0.92}
+	pdfColorPass  = pdfColor{0.09, 0.64, 0.29}
+	pdfColorFail  = pdfColor{1.00, 0.89, 0.89}
+)
+
+// pdfDocument is a minimal PDF 1.4 writer for text and filled rectangles using the standard Helvetica fonts,
+// enough for the tabular compliance reports without pulling in a PDF library. Since no font is embedded the text
+// is limited to WinAnsiEncoding (Latin-1 and a few typographic characters), other characters are written as '?'
+// and the HTML report has to be used for titles and connection names in other scripts.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	y     float64 // Cursor position from the top of the current page
+}
+
+func newPDFDocument() *pdfDocument {
+	d := &pdfDocument{}
+	d.addPage()
+	return d
+}
+
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfMargin
+}
+
+func (d *pdfDocument) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensureSpace starts a new page if less than height points are left on the current one
+func (d *pdfDocument) ensureSpace(height float64) {
+	if d.y+height > pdfPageHeight-pdfMargin {
+		d.addPage()
+	}
+}
+
+// text draws s with its baseline at y points from the top of the page, truncating it to maxWidth if positive
+func (d *pdfDocument) text(x, y, size float64, bold bool, color pdfColor, s string, maxWidth float64) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	s = pdfTruncate(s, size, maxWidth)
+	fmt.Fprintf(d.current(), "BT %.2f %.2f %.2f rg /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		color.R, color.G, color.B, font, size, x, pdfPageHeight-y, pdfEscape(s))
+}
+
+// rect fills a rectangle whose top left corner is at y points from the top of the page
+func (d *pdfDocument) rect(x, y, w, h float64, color pdfColor) {
+	fmt.Fprintf(d.current(), "%.2f %.2f %.2f rg %.2f %.2f %.2f %.2f re f\n",
+		color.R, color.G, color.B, x, pdfPageHeight-y-h, w, h)
+}
+
+func (d *pdfDocument) heading(s string) {
+	d.ensureSpace(40)
+	d.y += 24
+	d.text(pdfMargin, d.y, 14, true, pdfColorText, s, 0)
+	d.y += 6
+	d.rect(pdfMargin, d.y, pdfPageWidth-2*pdfMargin, 0.8, pdfColorLine)
+	d.y += 6
+}
+
+func (d *pdfDocument) paragraph(s string, size float64, color pdfColor) {
+	d.ensureSpace(size + 6)
+	d.y += size + 4
+	d.text(pdfMargin, d.y, size, false, color, s, pdfPageWidth-2*pdfMargin)
+}
+
+// table draws rows with the given column widths, repeating the header on every page it spans
+func (d *pdfDocument) table(widths []float64, header []string, rows [][]string) {
+	const rowHeight = 16.0
+	drawRow := func(cells []string, bold bool) {
+		x := pdfMargin
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			d.text(x+2, d.y+11, 8, bold, pdfColorText, cell, widths[i]-4)
+			x += widths[i]
+		}
+		d.y += rowHeight
+		d.rect(pdfMargin, d.y-0.5, x-pdfMargin, 0.5, pdfColorLine)
+	}
+
+	d.ensureSpace(2 * rowHeight)
+	drawRow(header, true)
+	for _, row := range rows {
+		if d.y+rowHeight > pdfPageHeight-pdfMargin {
+			d.addPage()
+			drawRow(header, true)
+		}
+		drawRow(row, false)
+	}
+}
+
+// bytes serializes the document, objects 1 and 2 are the catalog and page tree and 3 and 4 the fonts
+func (d *pdfDocument) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	beginObject := func() int {
+		offsets = append(offsets, out.Len())
+		id := len(offsets)
+		fmt.Fprintf(&out, "%d 0 obj\n", id)
+		return id
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, 0, len(d.pages))
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+	beginObject()
+	out.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	beginObject()
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(d.pages))
+	beginObject()
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\nendobj\n")
+	beginObject()
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\nendobj\n")
+
+	for _, page := range d.pages {
+		pageID := beginObject()
+		fmt.Fprintf(&out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pdfPageWidth, pdfPageHeight, pageID+1)
+		beginObject()
+		fmt.Fprintf(&out, "<< /Length %d >>\nstream\n", page.Len())
+		out.Write(page.Bytes())
+		out.WriteString("endstream\nendobj\n")
+	}
+
+	xrefOffset := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+	return out.Bytes()
+}
+
+// pdfWinAnsiExtras are the characters WinAnsiEncoding has in place of the C1 controls of Latin-1
+var pdfWinAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfEscape converts s to WinAnsiEncoding and escapes PDF string delimiters, characters the encoding does not have become '?'
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if c, ok := pdfWinAnsiExtras[r]; ok {
+			fmt.Fprintf(&b, "\\%03o", c)
+			continue
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// pdfTruncate cuts s with an ellipsis to fit maxWidth, estimating Helvetica glyphs at half the font size
+func pdfTruncate(s string, size, maxWidth float64) string {
+	if maxWidth <= 0 {
+		return s
+	}
+	maxChars := int(maxWidth / (size * 0.5))
+	if utf8.RuneCountInString(s) <= maxChars {
+		return s
+	}
+	if maxChars <= 3 {
+		return string([]rune(s)[:maxChars])
+	}
+	return string([]rune(s)[:maxChars-3]) + "..."
+}
+
+func renderPDF(data reportData) []byte {
+	d := newPDFDocument()
+	contentWidth := pdfPageWidth - 2*pdfMargin
+
+	d.y += 18
+	d.text(pdfMargin, d.y, 20, true, pdfColorText, data.Summary.Title, contentWidth)
+	subtitle := fmt.Sprintf("Workspace %s - Generated at %s", data.WorkspaceName, data.GeneratedAt.UTC().Format("2006-01-02 15:04 UTC"))
+	if data.Summary.EvaluatedAt != nil {
+		subtitle += " - Last evaluated at " + data.Summary.EvaluatedAt.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	d.paragraph(subtitle, 9, pdfColorMuted)
+
+	d.heading("Summary")
+	status := data.Summary.ConformanceStatusSummary
+	controls := data.Summary.ControlsSeverityStatus
+	d.paragraph(fmt.Sprintf("Passed: %.1f%%   Passed findings: %d   Failed findings: %d   Excepted findings: %d   Passed controls: %d / %d",
+		data.PassedPercentage(), status.PassedCount, status.FailedCount, status.ExceptedCount,
+		controls.Total.PassedCount, controls.Total.TotalCount), 10, pdfColorText)
+	d.y += 8
+	checks := data.Summary.Checks
+	d.table([]float64{120, 120, 120}, []string{"Severity", "Failed findings", "Passed controls"}, [][]string{
+		{"Critical", fmt.Sprint(checks.CriticalCount), fmt.Sprintf("%d / %d", controls.Critical.PassedCount, controls.Critical.TotalCount)},
+		{"High", fmt.Sprint(checks.HighCount), fmt.Sprintf("%d / %d", controls.High.PassedCount, controls.High.TotalCount)},
+		{"Medium", fmt.Sprint(checks.MediumCount), fmt.Sprintf("%d / %d", controls.Medium.PassedCount, controls.Medium.TotalCount)},
+		{"Low", fmt.Sprint(checks.LowCount), fmt.Sprintf("%d / %d", controls.Low.PassedCount, controls.Low.TotalCount)},
+		{"None", fmt.Sprint(checks.NoneCount), fmt.Sprintf("%d / %d", controls.None.PassedCount, controls.None.TotalCount)},
+	})
+
+	d.heading("Top failing controls")
+	if len(data.TopFailingControls) == 0 {
+		d.paragraph("No failing controls.", 9, pdfColorMuted)
+	} else {
+		var rows [][]string
+		for _, control := range data.TopFailingControls {
+			rows = append(rows, []string{
+				control.Control.Title,
+				string(control.Control.Severity),
+				fmt.Sprintf("%d / %d", control.FailedResourcesCount, control.TotalResourcesCount),
+				fmt.Sprintf("%d / %d", control.FailedConnectionCount, control.TotalConnectionCount),
+			})
+		}
+		d.table([]float64{295, 60, 80, 80}, []string{"Control", "Severity", "Failed resources", "Failed connections"}, rows)
+	}
+
+	d.heading("Connections")
+	if len(data.Connections) == 0 {
+		d.paragraph("No connections evaluated.", 9, pdfColorMuted)
+	} else {
+		var rows [][]string
+		for _, account := range data.Connections {
+			rows = append(rows, []string{
+				account.AccountName,
+				account.AccountId,
+				fmt.Sprintf("%.1f%%", account.SecurityScore),
+				fmt.Sprint(account.ConformanceStatusesCount.Failed + account.ConformanceStatusesCount.Error),
+				fmt.Sprint(account.ConformanceStatusesCount.Passed),
+				fmt.Sprint(account.SeveritiesCount.Critical),
+				fmt.Sprint(account.SeveritiesCount.High),
+				fmt.Sprint(account.SeveritiesCount.Medium),
+				fmt.Sprint(account.SeveritiesCount.Low),
+			})
+		}
+		d.table([]float64{115, 115, 55, 45, 45, 35, 35, 35, 35},
+			[]string{"Connection", "ID", "Score", "Failed", "Passed", "Crit.", "High", "Med.", "Low"}, rows)
+	}
+
+	d.heading(fmt.Sprintf("Trend over the last %d days", data.TrendDays))
+	if len(data.Trend) == 0 {
+		d.paragraph("No evaluations in this period.", 9, pdfColorMuted)
+	} else {
+		const barWidth = 200.0
+		for _, point := range data.Trend {
+			d.ensureSpace(14)
+			d.y += 14
+			passed := passedPercentage(point.ConformanceStatusSummary)
+			d.text(pdfMargin, d.y, 8, false, pdfColorText, point.Timestamp.UTC().Format("2006-01-02"), 0)
+			d.rect(pdfMargin+70, d.y-8, barWidth, 8, pdfColorFail)
+			d.rect(pdfMargin+70, d.y-8, barWidth*passed/100, 8, pdfColorPass)
+			d.text(pdfMargin+80+barWidth, d.y, 8, false, pdfColorText, fmt.Sprintf("%.1f%%  (%d passed, %d failed)",
+				passed, point.ConformanceStatusSummary.PassedCount, point.ConformanceStatusSummary.FailedCount), 0)
+		}
+	}
+
+	return d.bytes()
+}
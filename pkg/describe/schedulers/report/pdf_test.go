@@ -0,0 +1,160 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	pdfStartXrefPattern = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	pdfStreamPattern    = regexp.MustCompile(`/Length (\d+) >>\nstream\n`)
+	pdfPageCountPattern = regexp.MustCompile(`/Type /Pages /Kids \[[^\]]*\] /Count (\d+)`)
+)
+
+// checkPDFStructure validates the cross-reference table and stream lengths of pdf and returns its page count
+func checkPDFStructure(t *testing.T, pdf []byte) int {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")), "missing header")
+
+	match := pdfStartXrefPattern.FindSubmatch(pdf)
+	require.NotNil(t, match, "missing startxref")
+	xrefOffset, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	require.Less(t, xrefOffset, len(pdf))
+
+	var size int
+	_, err = fmt.Sscanf(string(pdf[xrefOffset:]), "xref\n0 %d\n", &size)
+	require.NoError(t, err, "startxref does not point at the xref table")
+	assert.Contains(t, string(pdf), fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R >>", size))
+
+	entries := bytes.SplitN(pdf[xrefOffset:], []byte("\n"), size+3)[2:]
+	require.Len(t, entries, size+1)
+	assert.Equal(t, "0000000000 65535 f ", string(entries[0]))
+	for id := 1; id < size; id++ {
+		var offset int
+		_, err := fmt.Sscanf(string(entries[id]), "%010d 00000 n ", &offset)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", id))), "object %d is not at its xref offset", id)
+	}
+
+	for _, loc := range pdfStreamPattern.FindAllSubmatchIndex(pdf, -1) {
+		length, err := strconv.Atoi(string(pdf[loc[2]:loc[3]]))
+		require.NoError(t, err)
+		require.LessOrEqual(t, loc[1]+length, len(pdf))
+		assert.True(t, bytes.HasPrefix(pdf[loc[1]+length:], []byte("endstream\n")), "stream length does not match its content")
+	}
+
+	pages := pdfPageCountPattern.FindSubmatch(pdf)
+	require.NotNil(t, pages, "missing page tree")
+	count, err := strconv.Atoi(string(pages[1]))
+	require.NoError(t, err)
+	assert.Equal(t, count, bytes.Count(pdf, []byte("/Type /Page /Parent 2 0 R")))
+	// Catalog, page tree and two fonts, then a page and a content stream per page
+	assert.Equal(t, 5+2*count, size)
+	return count
+}
+
+func TestPDFDocument_Bytes(t *testing.T) {
+	d := newPDFDocument()
+	d.heading("Summary")
+	d.paragraph("Passed (100%)", 10, pdfColorText)
+
+	pdf := d.bytes()
+	assert.Equal(t, 1, checkPDFStructure(t, pdf))
+	assert.Contains(t, string(pdf), "(Summary) Tj")
+	assert.Contains(t, string(pdf), `(Passed \(100%\)) Tj`)
+}
+
+func TestPDFDocument_TablePaging(t *testing.T) {
+	var rows [][]string
+	for i := 0; i < 100; i++ {
+		rows = append(rows, []string{fmt.Sprintf("control %d", i), "high"})
+	}
+	d := newPDFDocument()
+	d.table([]float64{200, 100}, []string{"Control", "Severity"}, rows)
+
+	pdf := d.bytes()
+	pages := checkPDFStructure(t, pdf)
+	assert.Greater(t, pages, 1)
+	assert.Len(t, d.pages, pages)
+	for i, page := range d.pages {
+		assert.Contains(t, page.String(), "(Control) Tj", "header missing on page %d", i+1)
+	}
+	assert.Contains(t, string(pdf), "(control 99) Tj")
+}
+
+func TestPDFEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain text", want: "plain text"},
+		{in: "f(x)", want: `f\(x\)`},
+		{in: `C:\temp`, want: `C:\\temp`},
+		{in: "line\nbreak\ttab", want: "line break tab"},
+		{in: "café", want: `caf\351`},
+		{in: "100 €", want: `100 \200`},
+		{in: "“quoted” – dash…", want: `\223quoted\224 \226 dash\205`},
+		// characters outside WinAnsiEncoding cannot be shown with the standard fonts
+		{in: "日本", want: "??"},
+		{in: "Ελλάδα ok", want: "?????? ok"},
+		{in: "emoji 🚀", want: "emoji ?"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			assert.Equal(t, tc.want, pdfEscape(tc.in))
+		})
+	}
+}
+
+func TestPDFTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		size     float64
+		maxWidth float64
+		want     string
+	}{
+		{name: "no limit", in: "a long control title", size: 10, maxWidth: 0, want: "a long control title"},
+		{name: "fits", in: "short", size: 10, maxWidth: 25, want: "short"},
+		{name: "ellipsis", in: "a long control title", size: 10, maxWidth: 50, want: "a long ..."},
+		{name: "too narrow for ellipsis", in: "abcdef", size: 10, maxWidth: 15, want: "abc"},
+		{name: "counts runes", in: "éééééé", size: 10, maxWidth: 30, want: "éééééé"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, pdfTruncate(tc.in, tc.size, tc.maxWidth))
+		})
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := reportData{
+		WorkspaceName: "main",
+		GeneratedAt:   now,
+		TrendDays:     30,
+		Trend: []complianceApi.BenchmarkTrendDatapoint{
+			{Timestamp: now.AddDate(0, 0, -1)},
+			{Timestamp: now},
+		},
+	}
+	data.Summary.Title = "CIS (v2.0.0)"
+	data.Summary.ConformanceStatusSummary.PassedCount = 3
+	data.Summary.ConformanceStatusSummary.FailedCount = 1
+
+	pdf := renderPDF(data)
+	assert.Equal(t, 1, checkPDFStructure(t, pdf))
+	assert.Contains(t, string(pdf), `(CIS \(v2.0.0\)) Tj`)
+	assert.Contains(t, string(pdf), "(Passed: 75.0%")
+	assert.Contains(t, string(pdf), "(No failing controls.) Tj")
+	assert.Contains(t, string(pdf), "(2024-01-02) Tj")
+}
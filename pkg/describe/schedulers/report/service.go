@@ -0,0 +1,178 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	complianceClient "github.com/kaytu-io/open-governance/pkg/compliance/client"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/kaytu-io/open-governance/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	ReportSchedulingInterval = 5 * time.Minute
+	DefaultTrendDays         = 30
+	// RunTimeout bounds the generation of a report, runs left in progress longer are failed
+	RunTimeout = 30 * time.Minute
+)
+
+type Scheduler struct {
+	logger           *zap.Logger
+	db               db.Database
+	complianceClient complianceClient.ComplianceServiceClient
+	workspaceName    string
+}
+
+func New(logger *zap.Logger, db db.Database, complianceClient complianceClient.ComplianceServiceClient, workspaceName string) *Scheduler {
+	return &Scheduler{
+		logger:           logger,
+		db:               db,
+		complianceClient: complianceClient,
+		workspaceName:    workspaceName,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	utils.EnsureRunGoroutine(func() {
+		s.RunScheduledReports(ctx)
+	})
+}
+
+func (s *Scheduler) RunScheduledReports(ctx context.Context) {
+	s.logger.Info("Scheduling compliance reports on a timer")
+
+	t := ticker.NewTicker(ReportSchedulingInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		if err := s.runScheduledReports(ctx); err != nil {
+			s.logger.Error("failed to run scheduled reports", zap.Error(err))
+			continue
+		}
+	}
+}
+
+func (s *Scheduler) runScheduledReports(ctx context.Context) error {
+	if err := s.db.UpdateTimedOutReportRuns(RunTimeout); err != nil {
+		s.logger.Error("failed to update timed out report runs", zap.Error(err))
+		return err
+	}
+
+	schedules, err := s.db.ListDueReportSchedules(time.Now())
+	if err != nil {
+		s.logger.Error("failed to list due report schedules", zap.Error(err))
+		return err
+	}
+	for _, schedule := range schedules {
+		// the schedule is moved forward before generating so a failing report is retried on the next interval, not on every tick
+		if err := s.db.UpdateReportScheduleLastRunAt(schedule.ID, time.Now()); err != nil {
+			s.logger.Error("failed to update report schedule last run", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+			return err
+		}
+
+		formats := make([]api.ReportFormat, 0, len(schedule.Formats))
+		for _, f := range schedule.Formats {
+			formats = append(formats, api.ReportFormat(f))
+		}
+		scheduleID := schedule.ID
+		runs, err := s.CreateRuns(&scheduleID, schedule.BenchmarkID, schedule.ConnectionIDs, formats, schedule.CreatedBy)
+		if err != nil {
+			return err
+		}
+		s.Generate(ctx, runs, schedule.TrendDays)
+	}
+	return nil
+}
+
+// NormalizeFormats validates the requested report formats and defaults to all of them if none is given
+func NormalizeFormats(formats []api.ReportFormat) ([]api.ReportFormat, error) {
+	if len(formats) == 0 {
+		return []api.ReportFormat{api.ReportFormatHTML, api.ReportFormatPDF}, nil
+	}
+	seen := make(map[api.ReportFormat]bool)
+	var result []api.ReportFormat
+	for _, format := range formats {
+		switch format {
+		case api.ReportFormatHTML, api.ReportFormatPDF:
+		default:
+			return nil, fmt.Errorf("invalid report format %s", format)
+		}
+		if seen[format] {
+			continue
+		}
+		seen[format] = true
+		result = append(result, format)
+	}
+	return result, nil
+}
+
+// CreateRuns stores an in progress run per format, to be filled by Generate
+func (s *Scheduler) CreateRuns(scheduleID *uint, benchmarkID string, connectionIDs []string, formats []api.ReportFormat, createdBy string) ([]model.ReportRun, error) {
+	var runs []model.ReportRun
+	for _, format := range formats {
+		run := model.ReportRun{
+			ScheduleID:    scheduleID,
+			BenchmarkID:   benchmarkID,
+			ConnectionIDs: connectionIDs,
+			Format:        format,
+			Status:        api.ReportRunStatusInProgress,
+			CreatedBy:     createdBy,
+		}
+		if err := s.db.CreateReportRun(&run); err != nil {
+			s.logger.Error("failed to create report run", zap.String("benchmarkID", benchmarkID), zap.Error(err))
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Generate gathers the report data once and renders it into every run, runs are marked failed if rendering fails
+func (s *Scheduler) Generate(ctx context.Context, runs []model.ReportRun, trendDays int) {
+	if len(runs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, RunTimeout)
+	defer cancel()
+	if trendDays <= 0 {
+		trendDays = DefaultTrendDays
+	}
+
+	data, err := s.gatherReportData(ctx, runs[0].BenchmarkID, runs[0].ConnectionIDs, trendDays)
+	if err != nil {
+		for _, run := range runs {
+			s.finishRun(run, nil, err)
+		}
+		return
+	}
+
+	for _, run := range runs {
+		var content []byte
+		switch run.Format {
+		case api.ReportFormatHTML:
+			content, err = renderHTML(*data)
+		case api.ReportFormatPDF:
+			content = renderPDF(*data)
+		default:
+			err = fmt.Errorf("invalid report format %s", run.Format)
+		}
+		s.finishRun(run, content, err)
+	}
+}
+
+func (s *Scheduler) finishRun(run model.ReportRun, content []byte, runErr error) {
+	status, failureMessage, fileName := api.ReportRunStatusSucceeded, "", ""
+	if runErr != nil {
+		status, failureMessage, content = api.ReportRunStatusFailed, runErr.Error(), nil
+	} else {
+		fileName = fmt.Sprintf("%s-%s.%s", run.BenchmarkID, run.CreatedAt.UTC().Format("20060102-150405"), run.Format)
+	}
+	if err := s.db.UpdateReportRunResult(run.ID, status, failureMessage, fileName, content); err != nil {
+		s.logger.Error("failed to update report run", zap.Uint("runID", run.ID), zap.Error(err))
+	}
+}
@@ -1,6 +1,7 @@
 package describe
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/json"
 	"errors"
@@ -12,6 +13,7 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/httpserver"
 	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
 	runner2 "github.com/kaytu-io/open-governance/pkg/compliance/runner"
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
 	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
 	onboardClient "github.com/kaytu-io/open-governance/pkg/onboard/client"
 	"github.com/kaytu-io/open-governance/pkg/utils"
@@ -19,6 +21,7 @@ import (
 	"github.com/sony/sonyflake"
 	"net/http"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,7 +39,11 @@ import (
 	"github.com/kaytu-io/open-governance/pkg/describe/db"
 	model2 "github.com/kaytu-io/open-governance/pkg/describe/db/model"
 	"github.com/kaytu-io/open-governance/pkg/describe/es"
+	queryrunnerscheduler "github.com/kaytu-io/open-governance/pkg/describe/schedulers/query-runner"
+	"github.com/kaytu-io/open-governance/pkg/describe/schedulers/report"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	onboardapi "github.com/kaytu-io/open-governance/pkg/onboard/api"
+	"github.com/kaytu-io/open-governance/pkg/types"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -81,8 +88,60 @@ func (h HttpServer) Register(e *echo.Echo) {
 	v1.GET("/describe/all/jobs/state", httpserver.AuthorizeHandler(h.GetDescribeAllJobsStatus, apiAuth.InternalRole))
 
 	v1.GET("/discovery/resourcetypes/list", httpserver.AuthorizeHandler(h.GetDiscoveryResourceTypeList, apiAuth.ViewerRole))
+	v1.GET("/discovery/coverage/:connection_id", httpserver.AuthorizeHandler(h.GetDiscoveryCoverage, apiAuth.ViewerRole))
+	v1.GET("/discovery/schedules", httpserver.AuthorizeHandler(h.ListDiscoverySchedulePolicies, apiAuth.ViewerRole))
+	v1.POST("/discovery/schedules", httpserver.AuthorizeHandler(h.CreateDiscoverySchedulePolicy, apiAuth.AdminRole))
+	v1.GET("/discovery/schedules/preview", httpserver.AuthorizeHandler(h.PreviewDiscoverySchedule, apiAuth.ViewerRole))
+	v1.GET("/discovery/schedules/:policy_id", httpserver.AuthorizeHandler(h.GetDiscoverySchedulePolicy, apiAuth.ViewerRole))
+	v1.PUT("/discovery/schedules/:policy_id", httpserver.AuthorizeHandler(h.UpdateDiscoverySchedulePolicy, apiAuth.AdminRole))
+	v1.DELETE("/discovery/schedules/:policy_id", httpserver.AuthorizeHandler(h.DeleteDiscoverySchedulePolicy, apiAuth.AdminRole))
+	v1.GET("/discovery/schedules/:policy_id/preview", httpserver.AuthorizeHandler(h.PreviewDiscoverySchedulePolicy, apiAuth.ViewerRole))
+	v1.GET("/discovery/rate-limits", httpserver.AuthorizeHandler(h.ListDiscoveryRateLimits, apiAuth.ViewerRole))
+	v1.POST("/discovery/rate-limits", httpserver.AuthorizeHandler(h.CreateDiscoveryRateLimit, apiAuth.AdminRole))
+	v1.GET("/discovery/rate-limits/usage", httpserver.AuthorizeHandler(h.GetDiscoveryRateLimitsUsage, apiAuth.ViewerRole))
+	v1.GET("/discovery/rate-limits/:limit_id", httpserver.AuthorizeHandler(h.GetDiscoveryRateLimit, apiAuth.ViewerRole))
+	v1.PUT("/discovery/rate-limits/:limit_id", httpserver.AuthorizeHandler(h.UpdateDiscoveryRateLimit, apiAuth.AdminRole))
+	v1.DELETE("/discovery/rate-limits/:limit_id", httpserver.AuthorizeHandler(h.DeleteDiscoveryRateLimit, apiAuth.AdminRole))
+	v1.GET("/terraform/backends", httpserver.AuthorizeHandler(h.ListTerraformBackends, apiAuth.ViewerRole))
+	v1.POST("/terraform/backends", httpserver.AuthorizeHandler(h.CreateTerraformBackend, apiAuth.AdminRole))
+	v1.GET("/terraform/backends/:backend_id", httpserver.AuthorizeHandler(h.GetTerraformBackend, apiAuth.ViewerRole))
+	v1.DELETE("/terraform/backends/:backend_id", httpserver.AuthorizeHandler(h.DeleteTerraformBackend, apiAuth.AdminRole))
+	v1.POST("/terraform/sync/:connection_id", httpserver.AuthorizeHandler(h.SyncTerraformState, apiAuth.AdminRole))
+	v1.GET("/terraform/drift/:connection_id", httpserver.AuthorizeHandler(h.GetTerraformDrift, apiAuth.ViewerRole))
+
+	v1.GET("/dlq", httpserver.AuthorizeHandler(h.ListDeadLetterQueues, apiAuth.AdminRole))
+	v1.GET("/dlq/:stream", httpserver.AuthorizeHandler(h.ListDeadLetterMessages, apiAuth.AdminRole))
+	v1.DELETE("/dlq/:stream", httpserver.AuthorizeHandler(h.PurgeDeadLetterQueue, apiAuth.AdminRole))
+	v1.POST("/dlq/:stream/replay", httpserver.AuthorizeHandler(h.ReplayDeadLetterQueue, apiAuth.AdminRole))
+	v1.GET("/dlq/:stream/:sequence", httpserver.AuthorizeHandler(h.GetDeadLetterMessage, apiAuth.AdminRole))
+	v1.DELETE("/dlq/:stream/:sequence", httpserver.AuthorizeHandler(h.DeleteDeadLetterMessage, apiAuth.AdminRole))
+	v1.POST("/dlq/:stream/:sequence/replay", httpserver.AuthorizeHandler(h.ReplayDeadLetterMessage, apiAuth.AdminRole))
 	v1.POST("/jobs", httpserver.AuthorizeHandler(h.ListJobs, apiAuth.ViewerRole))
 	v1.GET("/jobs/bydate", httpserver.AuthorizeHandler(h.CountJobsByDate, apiAuth.InternalRole))
+	v1.POST("/jobs/pipelines", httpserver.AuthorizeHandler(h.CreateJobPipeline, apiAuth.AdminRole))
+	v1.GET("/jobs/pipelines", httpserver.AuthorizeHandler(h.ListJobPipelines, apiAuth.ViewerRole))
+	v1.GET("/jobs/pipelines/:pipeline_id", httpserver.AuthorizeHandler(h.GetJobPipeline, apiAuth.ViewerRole))
+
+	v1.GET("/reports/schedules", httpserver.AuthorizeHandler(h.ListReportSchedules, apiAuth.ViewerRole))
+	v1.POST("/reports/schedules", httpserver.AuthorizeHandler(h.CreateReportSchedule, apiAuth.EditorRole))
+	v1.GET("/reports/schedules/:schedule_id", httpserver.AuthorizeHandler(h.GetReportSchedule, apiAuth.ViewerRole))
+	v1.PUT("/reports/schedules/:schedule_id", httpserver.AuthorizeHandler(h.UpdateReportSchedule, apiAuth.EditorRole))
+	v1.DELETE("/reports/schedules/:schedule_id", httpserver.AuthorizeHandler(h.DeleteReportSchedule, apiAuth.EditorRole))
+	v1.POST("/reports/schedules/:schedule_id/run", httpserver.AuthorizeHandler(h.RunReportSchedule, apiAuth.EditorRole))
+	v1.POST("/reports/run", httpserver.AuthorizeHandler(h.RunReport, apiAuth.EditorRole))
+	v1.GET("/reports/runs", httpserver.AuthorizeHandler(h.ListReportRuns, apiAuth.ViewerRole))
+	v1.GET("/reports/runs/:run_id", httpserver.AuthorizeHandler(h.GetReportRun, apiAuth.ViewerRole))
+	v1.GET("/reports/runs/:run_id/download", httpserver.AuthorizeHandler(h.DownloadReportRun, apiAuth.ViewerRole))
+
+	v1.GET("/query/schedules", httpserver.AuthorizeHandler(h.ListQuerySchedules, apiAuth.ViewerRole))
+	v1.POST("/query/schedules", httpserver.AuthorizeHandler(h.CreateQuerySchedule, apiAuth.EditorRole))
+	v1.GET("/query/schedules/:schedule_id", httpserver.AuthorizeHandler(h.GetQuerySchedule, apiAuth.ViewerRole))
+	v1.PUT("/query/schedules/:schedule_id", httpserver.AuthorizeHandler(h.UpdateQuerySchedule, apiAuth.EditorRole))
+	v1.DELETE("/query/schedules/:schedule_id", httpserver.AuthorizeHandler(h.DeleteQuerySchedule, apiAuth.EditorRole))
+	v1.POST("/query/schedules/:schedule_id/run", httpserver.AuthorizeHandler(h.RunQuerySchedule, apiAuth.EditorRole))
+	v1.GET("/query/schedules/:schedule_id/results", httpserver.AuthorizeHandler(h.ListQueryScheduleResults, apiAuth.ViewerRole))
+	v1.GET("/query/schedules/:schedule_id/alerts", httpserver.AuthorizeHandler(h.ListQueryAlerts, apiAuth.ViewerRole))
+	v1.GET("/query/schedules/:schedule_id/changelog", httpserver.AuthorizeHandler(h.ListQueryScheduleChangelog, apiAuth.ViewerRole))
 
 	v3 := e.Group("/api/v3")
 	v3.POST("/jobs/discovery/connections/:connection_id", httpserver.AuthorizeHandler(h.GetDescribeJobsHistory, apiAuth.ViewerRole))
@@ -98,6 +157,7 @@ func (h HttpServer) Register(e *echo.Echo) {
 	v3.PUT("/query/:query_id/run", httpserver.AuthorizeHandler(h.RunQuery, apiAuth.AdminRole))
 	v3.GET("/job/discovery/:job_id", httpserver.AuthorizeHandler(h.GetDescribeJobStatus, apiAuth.ViewerRole))
 	v3.GET("/job/compliance/:job_id", httpserver.AuthorizeHandler(h.GetComplianceJobStatus, apiAuth.ViewerRole))
+	v3.POST("/job/compliance/:job_id/retry", httpserver.AuthorizeHandler(h.RetryComplianceJob, apiAuth.AdminRole))
 	v3.GET("/job/analytics/:job_id", httpserver.AuthorizeHandler(h.GetAnalyticsJobStatus, apiAuth.ViewerRole))
 	v3.GET("/job/query/:job_id", httpserver.AuthorizeHandler(h.GetAsyncQueryRunJobStatus, apiAuth.ViewerRole))
 	v3.POST("/jobs/discovery", httpserver.AuthorizeHandler(h.ListDescribeJobs, apiAuth.ViewerRole))
@@ -425,6 +485,34 @@ func (h HttpServer) GetDiscoveryResourceTypeList(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, result)
 }
 
+// GetDiscoveryCoverage godoc
+//
+//	@Summary		Get discovery coverage of a connection
+//	@Description	Lists which resource types of the connection were described, failed due to permissions or were never attempted, and the controls which cannot be evaluated because of them
+//	@Security		BearerToken
+//	@Tags			scheduler
+//	@Param			connection_id	path	string	true	"Connection ID"
+//	@Produce		json
+//	@Success		200	{object}	api.DiscoveryCoverageReport
+//	@Router			/schedule/api/v1/discovery/coverage/{connection_id} [get]
+func (h HttpServer) GetDiscoveryCoverage(ctx echo.Context) error {
+	connectionID := ctx.Param("connection_id")
+	if err := httpserver.CheckAccessToConnectionID(ctx, connectionID); err != nil {
+		return err
+	}
+
+	report, err := h.Scheduler.DiscoveryCoverageReport(connectionID)
+	if err != nil {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			return err
+		}
+		h.Scheduler.logger.Error("failed to build discovery coverage report", zap.String("connection_id", connectionID), zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, report)
+}
+
 func (h HttpServer) CountJobsByDate(ctx echo.Context) error {
 	startDate, err := strconv.ParseInt(ctx.QueryParam("startDate"), 10, 64)
 	if err != nil {
@@ -551,7 +639,7 @@ func (h HttpServer) TriggerPerConnectionDescribeJob(ctx echo.Context) error {
 			if !src.GetSupportedResourceTypeMap()[strings.ToLower(resourceType)] {
 				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid resource type for connection: %s", resourceType))
 			}
-			daj, err := h.Scheduler.describe(src, resourceType, false, costFullDiscovery, false, nil, userID)
+			daj, err := h.Scheduler.describe(src, resourceType, false, costFullDiscovery, false, nil, userID, nil)
 			if err == ErrJobInProgress {
 				return echo.NewHTTPError(http.StatusConflict, err.Error())
 			}
@@ -637,7 +725,7 @@ func (h HttpServer) TriggerDescribeJob(ctx echo.Context) error {
 			if !connection.GetSupportedResourceTypeMap()[strings.ToLower(resourceType)] {
 				continue
 			}
-			_, err = h.Scheduler.describe(connection, resourceType, false, false, false, nil, userID)
+			_, err = h.Scheduler.describe(connection, resourceType, false, false, false, nil, userID, nil)
 			if err != nil {
 				h.Scheduler.logger.Error("failed to describe connection", zap.String("connection_id", connection.ID.String()), zap.Error(err))
 			}
@@ -791,7 +879,7 @@ func (h HttpServer) TriggerConnectionsComplianceJobSummary(ctx echo.Context) err
 	}
 
 	for _, benchmark := range benchmarks {
-		err = h.Scheduler.complianceScheduler.CreateSummarizer(benchmark.ID, nil, model2.ComplianceTriggerTypeManual)
+		err = h.Scheduler.complianceScheduler.CreateSummarizer(benchmark.ID, nil, model2.ComplianceTriggerTypeManual, nil)
 		if err != nil {
 			return fmt.Errorf("error while creating compliance job summarizer: %v", err)
 		}
@@ -937,7 +1025,7 @@ func (h HttpServer) ReEvaluateComplianceJob(ctx echo.Context) error {
 
 	var dependencyIDs []int64
 	for _, describeJob := range describeJobs {
-		daj, err := h.Scheduler.describe(describeJob.Connection, describeJob.ResourceType, false, false, false, nil, userID)
+		daj, err := h.Scheduler.describe(describeJob.Connection, describeJob.ResourceType, false, false, false, nil, userID, nil)
 		if err != nil {
 			h.Scheduler.logger.Error("failed to describe connection", zap.String("connection_id", describeJob.Connection.ID.String()), zap.Error(err))
 			continue
@@ -1732,7 +1820,7 @@ func (h HttpServer) RunDiscovery(ctx echo.Context) error {
 			}
 
 			var status, failureReason string
-			job, err := h.Scheduler.describe(connection, resourceType, false, false, false, &integrationDiscovery.ID, userID)
+			job, err := h.Scheduler.describe(connection, resourceType, false, false, false, &integrationDiscovery.ID, userID, nil)
 			if err != nil {
 				if err.Error() == "job already in progress" {
 					tmpJob, err := h.Scheduler.db.GetLastDescribeConnectionJob(connection.ID.String(), resourceType)
@@ -1858,13 +1946,99 @@ func (h HttpServer) GetComplianceJobStatus(ctx echo.Context) error {
 		IntegrationInfo: connectionInfo,
 		BenchmarkId:     j.BenchmarkID,
 		JobStatus:       string(j.Status),
+		FailureMessage:  j.FailureMessage,
 		CreatedAt:       j.CreatedAt,
 		UpdatedAt:       j.UpdatedAt,
 	}
 
+	runners, err := h.DB.ListComplianceJobRunnersWithID(j.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	for _, r := range runners {
+		jobsResult.TotalRunners++
+		switch r.Status {
+		case runner2.ComplianceRunnerSucceeded:
+			jobsResult.SucceededRunners++
+		case runner2.ComplianceRunnerFailed, runner2.ComplianceRunnerTimeOut:
+			jobsResult.FailedRunners++
+
+			category := string(runner2.CategorizeFailure(r.Status, r.FailureMessage))
+			if jobsResult.FailureCategories == nil {
+				jobsResult.FailureCategories = make(map[string]int)
+			}
+			jobsResult.FailureCategories[category]++
+
+			var controlIDs []string
+			if callers, err := r.GetCallers(); err == nil {
+				for _, c := range callers {
+					controlIDs = append(controlIDs, c.ControlID)
+				}
+			}
+			jobsResult.FailedQueries = append(jobsResult.FailedQueries, api.ComplianceJobFailedQuery{
+				RunnerID:       r.ID,
+				QueryID:        r.QueryID,
+				ControlIDs:     controlIDs,
+				ConnectionID:   r.ConnectionID,
+				Status:         string(r.Status),
+				Category:       category,
+				FailureMessage: r.FailureMessage,
+				RetryCount:     r.RetryCount,
+			})
+		case runner2.ComplianceRunnerCanceled:
+		default:
+			jobsResult.InProgressRunners++
+		}
+	}
+	jobsResult.PartiallySucceeded = jobsResult.SucceededRunners > 0 && jobsResult.FailedRunners > 0
+
 	return ctx.JSON(http.StatusOK, jobsResult)
 }
 
+// RetryComplianceJob godoc
+//
+//	@Summary		Retry failed runners of compliance job
+//	@Description	Runs the failed and timed out queries of a finished compliance job again and summarizes the benchmark once they are done
+//	@Security		BearerToken
+//	@Tags			scheduler
+//	@Param			job_id	path	string	true	"Job ID"
+//	@Produce		json
+//	@Success		200	{object}	api.RetryComplianceJobResponse
+//	@Router			/schedule/api/v3/job/compliance/{job_id}/retry [post]
+func (h HttpServer) RetryComplianceJob(ctx echo.Context) error {
+	jobId, err := strconv.ParseUint(ctx.Param("job_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid job id")
+	}
+
+	j, err := h.DB.GetComplianceJobByID(uint(jobId))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if j == nil || j.ID == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "compliance job not found")
+	}
+	switch j.Status {
+	case model2.ComplianceJobSucceeded, model2.ComplianceJobFailed, model2.ComplianceJobTimeOut:
+	default:
+		return echo.NewHTTPError(http.StatusConflict, "compliance job is still running")
+	}
+
+	count, err := h.DB.RetryFailedRunnersOfJob(j.ID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to retry compliance job runners", zap.Uint("jobID", j.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if count == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "compliance job has no failed runners")
+	}
+
+	return ctx.JSON(http.StatusOK, api.RetryComplianceJobResponse{
+		JobId:          j.ID,
+		RetriedRunners: int(count),
+	})
+}
+
 // GetAnalyticsJobStatus godoc
 //
 //	@Summary	Get analytics job status by job id
@@ -3734,6 +3908,414 @@ func (h *HttpServer) RunQuery(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response)
 }
 
+func (h HttpServer) getQueryScheduleFromParam(ctx echo.Context) (*model2.QuerySchedule, error) {
+	scheduleID, err := strconv.ParseUint(ctx.Param("schedule_id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid schedule id")
+	}
+	schedule, err := h.DB.GetQuerySchedule(uint(scheduleID))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get query schedule", zap.Error(err))
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "query schedule not found")
+	}
+	return schedule, nil
+}
+
+// getVisibleQueryScheduleFromParam returns the schedule if it is visible to the user, see isQueryScheduleVisible
+func (h HttpServer) getVisibleQueryScheduleFromParam(ctx echo.Context) (*model2.QuerySchedule, error) {
+	schedule, err := h.getQueryScheduleFromParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := h.isQueryScheduleVisible(ctx, *schedule, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "query schedule not found")
+	}
+	return schedule, nil
+}
+
+// getOwnedQueryScheduleFromParam returns the schedule only if the user created it
+func (h HttpServer) getOwnedQueryScheduleFromParam(ctx echo.Context) (*model2.QuerySchedule, error) {
+	schedule, err := h.getQueryScheduleFromParam(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.CreatedBy != httpserver.GetUserID(ctx) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "query schedule not found")
+	}
+	return schedule, nil
+}
+
+// isQueryScheduleVisible tells if the user created the schedule or can see its saved query.
+// visibleQueries caches the visibility of the saved queries across schedules and can be nil
+func (h HttpServer) isQueryScheduleVisible(ctx echo.Context, schedule model2.QuerySchedule, visibleQueries map[string]bool) (bool, error) {
+	if schedule.CreatedBy == httpserver.GetUserID(ctx) {
+		return true, nil
+	}
+	if visible, ok := visibleQueries[schedule.SavedQueryID]; ok {
+		return visible, nil
+	}
+	savedQuery, err := h.Scheduler.inventoryClient.GetSavedQuery(httpclient.FromEchoContext(ctx), schedule.SavedQueryID)
+	if err != nil {
+		var httpErr *echo.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Code >= http.StatusInternalServerError {
+			h.Scheduler.logger.Error("failed to get saved query", zap.String("savedQueryID", schedule.SavedQueryID), zap.Error(err))
+			return false, err
+		}
+		savedQuery = nil
+	}
+	if visibleQueries != nil {
+		visibleQueries[schedule.SavedQueryID] = savedQuery != nil
+	}
+	return savedQuery != nil, nil
+}
+
+// queryScheduleKeyColumns drops empty and repeated key columns
+func queryScheduleKeyColumns(columns []string) []string {
+	keyColumns := make([]string, 0, len(columns))
+	seen := make(map[string]bool)
+	for _, column := range columns {
+		column = strings.TrimSpace(column)
+		if column == "" || seen[strings.ToLower(column)] {
+			continue
+		}
+		seen[strings.ToLower(column)] = true
+		keyColumns = append(keyColumns, column)
+	}
+	return keyColumns
+}
+
+// checkScheduledSavedQuery makes sure the saved query is visible to the user and can be run by the query runner
+func (h HttpServer) checkScheduledSavedQuery(ctx echo.Context, savedQueryID string) error {
+	savedQuery, err := h.Scheduler.inventoryClient.GetSavedQuery(httpclient.FromEchoContext(ctx), savedQueryID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get saved query", zap.String("savedQueryID", savedQueryID), zap.Error(err))
+		return err
+	}
+	if savedQuery == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "saved query not found")
+	}
+	if savedQuery.Engine != "" && savedQuery.Engine != inventoryApi.QueryEngine_OdysseusSQL {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("only %s saved queries can be scheduled", inventoryApi.QueryEngine_OdysseusSQL))
+	}
+	return nil
+}
+
+// ListQuerySchedules godoc
+//
+//	@Summary		List query schedules
+//	@Description	Returns the schedules running saved queries of the workspace, visible to the user
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Produce		json
+//	@Param			saved_query_id	query		string	false	"Only the schedules of this saved query"
+//	@Success		200				{object}	[]api.QuerySchedule
+//	@Router			/schedule/api/v1/query/schedules [get]
+func (h HttpServer) ListQuerySchedules(ctx echo.Context) error {
+	var savedQueryID *string
+	if id := ctx.QueryParam("saved_query_id"); id != "" {
+		savedQueryID = &id
+	}
+	schedules, err := h.DB.ListQuerySchedules(savedQueryID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list query schedules", zap.Error(err))
+		return err
+	}
+	visibleQueries := make(map[string]bool)
+	result := make([]api.QuerySchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		visible, err := h.isQueryScheduleVisible(ctx, schedule, visibleQueries)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			continue
+		}
+		result = append(result, schedule.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CreateQuerySchedule godoc
+//
+//	@Summary		Create query schedule
+//	@Description	Creates a schedule running a saved query on a cron expression, alerts are posted to the outbound webhook of the workspace when a condition fires
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateQueryScheduleRequest	true	"Query schedule"
+//	@Success		201		{object}	api.QuerySchedule
+//	@Router			/schedule/api/v1/query/schedules [post]
+func (h HttpServer) CreateQuerySchedule(ctx echo.Context) error {
+	var req api.CreateQueryScheduleRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if _, err := utils.ParseCron(req.CronExpression); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	conditions, err := queryrunnerscheduler.NewAlertConditions(req.Conditions)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.checkScheduledSavedQuery(ctx, req.SavedQueryID); err != nil {
+		return err
+	}
+
+	schedule := model2.QuerySchedule{
+		Name:           req.Name,
+		SavedQueryID:   req.SavedQueryID,
+		CronExpression: req.CronExpression,
+		HistorySize:    req.HistorySize,
+		KeyColumns:     queryScheduleKeyColumns(req.KeyColumns),
+		Enabled:        true,
+		CreatedBy:      httpserver.GetUserID(ctx),
+		Conditions:     conditions,
+	}
+	if schedule.HistorySize == 0 {
+		schedule.HistorySize = queryrunnerscheduler.DefaultQueryHistorySize
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if err := schedule.ScheduleNext(time.Now()); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.DB.CreateQuerySchedule(&schedule); err != nil {
+		h.Scheduler.logger.Error("failed to create query schedule", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, schedule.ToApi())
+}
+
+// GetQuerySchedule godoc
+//
+//	@Summary	Get query schedule
+//	@Security	BearerToken
+//	@Tags		query_schedules
+//	@Produce	json
+//	@Param		schedule_id	path		string	true	"Schedule ID"
+//	@Success	200			{object}	api.QuerySchedule
+//	@Router		/schedule/api/v1/query/schedules/{schedule_id} [get]
+func (h HttpServer) GetQuerySchedule(ctx echo.Context) error {
+	schedule, err := h.getVisibleQueryScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, schedule.ToApi())
+}
+
+// UpdateQuerySchedule godoc
+//
+//	@Summary		Update query schedule
+//	@Description	Updates the given fields of a query schedule, given conditions replace the existing ones. The saved query of a schedule can not be changed, only the creator of the schedule can update it
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Accept			json
+//	@Produce		json
+//	@Param			schedule_id	path		string							true	"Schedule ID"
+//	@Param			request		body		api.UpdateQueryScheduleRequest	true	"Query schedule fields"
+//	@Success		200			{object}	api.QuerySchedule
+//	@Router			/schedule/api/v1/query/schedules/{schedule_id} [put]
+func (h HttpServer) UpdateQuerySchedule(ctx echo.Context) error {
+	schedule, err := h.getOwnedQueryScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	var req api.UpdateQueryScheduleRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.Name != nil {
+		schedule.Name = *req.Name
+	}
+	if req.CronExpression != nil {
+		if _, err := utils.ParseCron(*req.CronExpression); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		schedule.CronExpression = *req.CronExpression
+	}
+	if req.HistorySize != nil {
+		schedule.HistorySize = *req.HistorySize
+	}
+	if req.KeyColumns != nil {
+		schedule.KeyColumns = queryScheduleKeyColumns(req.KeyColumns)
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if req.Conditions != nil {
+		schedule.Conditions, err = queryrunnerscheduler.NewAlertConditions(req.Conditions)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	if err := schedule.ScheduleNext(time.Now()); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := h.DB.UpdateQuerySchedule(schedule, req.Conditions != nil); err != nil {
+		h.Scheduler.logger.Error("failed to update query schedule", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, schedule.ToApi())
+}
+
+// DeleteQuerySchedule godoc
+//
+//	@Summary		Delete query schedule
+//	@Description	Deletes a query schedule with its result history, alerts it fired are kept. Only the creator of the schedule can delete it
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Param			schedule_id	path	string	true	"Schedule ID"
+//	@Success		200
+//	@Router			/schedule/api/v1/query/schedules/{schedule_id} [delete]
+func (h HttpServer) DeleteQuerySchedule(ctx echo.Context) error {
+	schedule, err := h.getOwnedQueryScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.DB.DeleteQuerySchedule(schedule.ID); err != nil {
+		h.Scheduler.logger.Error("failed to delete query schedule", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// RunQuerySchedule godoc
+//
+//	@Summary		Run query schedule
+//	@Description	Runs the saved query of a schedule now and evaluates its alert conditions, without moving its next run. Only the creator of the schedule can run it
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Produce		json
+//	@Param			schedule_id	path		string	true	"Schedule ID"
+//	@Success		200			{object}	api.RunQueryResponse
+//	@Router			/schedule/api/v1/query/schedules/{schedule_id}/run [post]
+func (h HttpServer) RunQuerySchedule(ctx echo.Context) error {
+	schedule, err := h.getOwnedQueryScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	job := queryrunnerscheduler.NewScheduledQueryRunnerJob(*schedule)
+	jobId, err := h.DB.CreateQueryRunnerJob(job)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to create query runner job", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create query runner job")
+	}
+	return ctx.JSON(http.StatusOK, api.RunQueryResponse{
+		ID:        jobId,
+		QueryId:   job.QueryId,
+		CreatedAt: job.CreatedAt,
+		CreatedBy: job.CreatedBy,
+		Status:    job.Status,
+	})
+}
+
+// ListQueryScheduleResults godoc
+//
+//	@Summary		List query schedule results
+//	@Description	Returns the kept result history of a query schedule, newest first
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Produce		json
+//	@Param			schedule_id	path		string	true	"Schedule ID"
+//	@Success		200			{object}	[]api.QueryScheduleResult
+//	@Router			/schedule/api/v1/query/schedules/{schedule_id}/results [get]
+func (h HttpServer) ListQueryScheduleResults(ctx echo.Context) error {
+	schedule, err := h.getVisibleQueryScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	results, err := h.DB.ListQueryScheduleResults(schedule.ID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list query schedule results", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return err
+	}
+	response := make([]api.QueryScheduleResult, 0, len(results))
+	for _, result := range results {
+		response = append(response, result.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ListQueryScheduleChangelog godoc
+//
+//	@Summary		List query schedule changelog
+//	@Description	Returns what changed between consecutive succeeded runs of a query schedule, newest first. Runs without changes have no entry
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Produce		json
+//	@Param			schedule_id	path		string	true	"Schedule ID"
+//	@Param			limit		query		int		false	"Maximum number of entries"
+//	@Success		200			{object}	[]api.QueryScheduleChangelog
+//	@Router			/schedule/api/v1/query/schedules/{schedule_id}/changelog [get]
+func (h HttpServer) ListQueryScheduleChangelog(ctx echo.Context) error {
+	schedule, err := h.getVisibleQueryScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	limit := 100
+	if limitStr := ctx.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+	changelog, err := h.DB.ListQueryScheduleChangelog(schedule.ID, limit)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list query schedule changelog", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return err
+	}
+	response := make([]api.QueryScheduleChangelog, 0, len(changelog))
+	for _, entry := range changelog {
+		response = append(response, entry.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ListQueryAlerts godoc
+//
+//	@Summary		List query alerts
+//	@Description	Returns the alerts fired by a query schedule with their webhook delivery status, newest first
+//	@Security		BearerToken
+//	@Tags			query_schedules
+//	@Produce		json
+//	@Param			schedule_id	path		string	true	"Schedule ID"
+//	@Param			limit		query		int		false	"Maximum number of alerts"
+//	@Success		200			{object}	[]api.QueryAlert
+//	@Router			/schedule/api/v1/query/schedules/{schedule_id}/alerts [get]
+func (h HttpServer) ListQueryAlerts(ctx echo.Context) error {
+	schedule, err := h.getVisibleQueryScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	limit := 100
+	if limitStr := ctx.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+	}
+	alerts, err := h.DB.ListQueryAlerts(schedule.ID, limit)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list query alerts", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return err
+	}
+	response := make([]api.QueryAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		response = append(response, alert.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
 // PurgeSampleData godoc
 //
 //	@Summary		List all workspaces with owner id
@@ -4103,3 +4685,1501 @@ func parseTimeInterval(intervalStr string) (*time.Time, *time.Time, error) {
 
 	return &startTime, &endTime, nil
 }
+
+func (h HttpServer) getReportScheduleFromParam(ctx echo.Context) (*model2.ReportSchedule, error) {
+	scheduleID, err := strconv.ParseUint(ctx.Param("schedule_id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid schedule id")
+	}
+	schedule, err := h.DB.GetReportSchedule(uint(scheduleID))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get report schedule", zap.Error(err))
+		return nil, err
+	}
+	if schedule == nil || !reportConnectionsInScope(reportConnectionScope(ctx), schedule.ConnectionIDs) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "report schedule not found")
+	}
+	return schedule, nil
+}
+
+// reportConnectionScope returns the connections the caller is limited to, nil if the caller can access all of them
+func reportConnectionScope(ctx echo.Context) []string {
+	scope, _ := httpserver.ResolveConnectionIDs(ctx, nil)
+	return scope
+}
+
+// reportConnectionsInScope tells whether a report over the given connections is within the scope, reports over
+// all connections are only within the nil scope of callers not limited to some connections
+func reportConnectionsInScope(scope []string, connectionIDs []string) bool {
+	if scope == nil {
+		return true
+	}
+	if len(connectionIDs) == 0 {
+		return false
+	}
+	for _, connectionID := range connectionIDs {
+		if !slices.Contains(scope, connectionID) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h HttpServer) getReportRunFromParam(ctx echo.Context, withContent bool) (*model2.ReportRun, error) {
+	runID, err := strconv.ParseUint(ctx.Param("run_id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid run id")
+	}
+	var run *model2.ReportRun
+	if withContent {
+		run, err = h.DB.GetReportRunWithContent(uint(runID))
+	} else {
+		run, err = h.DB.GetReportRun(uint(runID))
+	}
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get report run", zap.Error(err))
+		return nil, err
+	}
+	if run == nil || !reportConnectionsInScope(reportConnectionScope(ctx), run.ConnectionIDs) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "report run not found")
+	}
+	return run, nil
+}
+
+// checkReportBenchmark makes sure the benchmark exists before generating reports for it
+func (h HttpServer) checkReportBenchmark(ctx echo.Context, benchmarkID string) error {
+	clientCtx := &httpclient.Context{Ctx: ctx.Request().Context(), UserRole: apiAuth.InternalRole}
+	benchmark, err := h.Scheduler.complianceClient.GetBenchmark(clientCtx, benchmarkID)
+	if err != nil {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Code == http.StatusNotFound {
+			return echo.NewHTTPError(http.StatusBadRequest, "benchmark not found")
+		}
+		h.Scheduler.logger.Error("failed to get benchmark", zap.String("benchmarkID", benchmarkID), zap.Error(err))
+		return err
+	}
+	if benchmark == nil || benchmark.ID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "benchmark not found")
+	}
+	return nil
+}
+
+// ListReportSchedules godoc
+//
+//	@Summary		List compliance report schedules
+//	@Description	Returns the compliance report schedules of the workspace
+//	@Security		BearerToken
+//	@Tags			reports
+//	@Produce		json
+//	@Success		200	{object}	[]api.ReportSchedule
+//	@Router			/schedule/api/v1/reports/schedules [get]
+func (h HttpServer) ListReportSchedules(ctx echo.Context) error {
+	schedules, err := h.DB.ListReportSchedules(reportConnectionScope(ctx))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list report schedules", zap.Error(err))
+		return err
+	}
+	result := make([]api.ReportSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, schedule.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CreateReportSchedule godoc
+//
+//	@Summary		Create compliance report schedule
+//	@Description	Creates a schedule generating HTML and/or PDF reports of a benchmark every interval
+//	@Security		BearerToken
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateReportScheduleRequest	true	"Report schedule"
+//	@Success		201		{object}	api.ReportSchedule
+//	@Router			/schedule/api/v1/reports/schedules [post]
+func (h HttpServer) CreateReportSchedule(ctx echo.Context) error {
+	var req api.CreateReportScheduleRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	formats, err := report.NormalizeFormats(req.Formats)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	connectionIDs, err := httpserver.ResolveConnectionIDs(ctx, req.ConnectionIDs)
+	if err != nil {
+		return err
+	}
+	benchmarkID := strings.ToLower(req.BenchmarkID)
+	if err := h.checkReportBenchmark(ctx, benchmarkID); err != nil {
+		return err
+	}
+
+	schedule := model2.ReportSchedule{
+		Name:          req.Name,
+		BenchmarkID:   benchmarkID,
+		ConnectionIDs: connectionIDs,
+		IntervalHours: req.IntervalHours,
+		TrendDays:     req.TrendDays,
+		Enabled:       true,
+		CreatedBy:     httpserver.GetUserID(ctx),
+	}
+	for _, format := range formats {
+		schedule.Formats = append(schedule.Formats, string(format))
+	}
+	if schedule.TrendDays == 0 {
+		schedule.TrendDays = report.DefaultTrendDays
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if err := h.DB.CreateReportSchedule(&schedule); err != nil {
+		h.Scheduler.logger.Error("failed to create report schedule", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, schedule.ToApi())
+}
+
+// GetReportSchedule godoc
+//
+//	@Summary	Get compliance report schedule
+//	@Security	BearerToken
+//	@Tags		reports
+//	@Produce	json
+//	@Param		schedule_id	path		string	true	"Schedule ID"
+//	@Success	200			{object}	api.ReportSchedule
+//	@Router		/schedule/api/v1/reports/schedules/{schedule_id} [get]
+func (h HttpServer) GetReportSchedule(ctx echo.Context) error {
+	schedule, err := h.getReportScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, schedule.ToApi())
+}
+
+// UpdateReportSchedule godoc
+//
+//	@Summary		Update compliance report schedule
+//	@Description	Updates the given fields of a report schedule, the benchmark of a schedule can not be changed
+//	@Security		BearerToken
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Param			schedule_id	path		string							true	"Schedule ID"
+//	@Param			request		body		api.UpdateReportScheduleRequest	true	"Report schedule fields"
+//	@Success		200			{object}	api.ReportSchedule
+//	@Router			/schedule/api/v1/reports/schedules/{schedule_id} [put]
+func (h HttpServer) UpdateReportSchedule(ctx echo.Context) error {
+	schedule, err := h.getReportScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	var req api.UpdateReportScheduleRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.Name != nil {
+		schedule.Name = *req.Name
+	}
+	if req.ConnectionIDs != nil {
+		connectionIDs, err := httpserver.ResolveConnectionIDs(ctx, req.ConnectionIDs)
+		if err != nil {
+			return err
+		}
+		schedule.ConnectionIDs = connectionIDs
+	}
+	if req.Formats != nil {
+		formats, err := report.NormalizeFormats(req.Formats)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		schedule.Formats = nil
+		for _, format := range formats {
+			schedule.Formats = append(schedule.Formats, string(format))
+		}
+	}
+	if req.IntervalHours != nil {
+		schedule.IntervalHours = *req.IntervalHours
+	}
+	if req.TrendDays != nil {
+		schedule.TrendDays = *req.TrendDays
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if err := h.DB.UpdateReportSchedule(schedule); err != nil {
+		h.Scheduler.logger.Error("failed to update report schedule", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, schedule.ToApi())
+}
+
+// DeleteReportSchedule godoc
+//
+//	@Summary		Delete compliance report schedule
+//	@Description	Deletes a report schedule, reports it already generated stay available for download
+//	@Security		BearerToken
+//	@Tags			reports
+//	@Param			schedule_id	path	string	true	"Schedule ID"
+//	@Success		200
+//	@Router			/schedule/api/v1/reports/schedules/{schedule_id} [delete]
+func (h HttpServer) DeleteReportSchedule(ctx echo.Context) error {
+	schedule, err := h.getReportScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.DB.DeleteReportSchedule(schedule.ID); err != nil {
+		h.Scheduler.logger.Error("failed to delete report schedule", zap.Uint("scheduleID", schedule.ID), zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// RunReportSchedule godoc
+//
+//	@Summary		Run compliance report schedule
+//	@Description	Generates the reports of a schedule now, without moving its next run
+//	@Security		BearerToken
+//	@Tags			reports
+//	@Produce		json
+//	@Param			schedule_id	path		string	true	"Schedule ID"
+//	@Success		200			{object}	[]api.ReportRun
+//	@Router			/schedule/api/v1/reports/schedules/{schedule_id}/run [post]
+func (h HttpServer) RunReportSchedule(ctx echo.Context) error {
+	schedule, err := h.getReportScheduleFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	formats := make([]api.ReportFormat, 0, len(schedule.Formats))
+	for _, format := range schedule.Formats {
+		formats = append(formats, api.ReportFormat(format))
+	}
+	scheduleID := schedule.ID
+	return h.runReport(ctx, &scheduleID, schedule.BenchmarkID, schedule.ConnectionIDs, formats, schedule.TrendDays)
+}
+
+// RunReport godoc
+//
+//	@Summary		Generate compliance report
+//	@Description	Generates HTML and/or PDF reports of a benchmark on demand, poll the returned runs until they succeed to download them
+//	@Security		BearerToken
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.RunReportRequest	true	"Report"
+//	@Success		200		{object}	[]api.ReportRun
+//	@Router			/schedule/api/v1/reports/run [post]
+func (h HttpServer) RunReport(ctx echo.Context) error {
+	var req api.RunReportRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	formats, err := report.NormalizeFormats(req.Formats)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	connectionIDs, err := httpserver.ResolveConnectionIDs(ctx, req.ConnectionIDs)
+	if err != nil {
+		return err
+	}
+	benchmarkID := strings.ToLower(req.BenchmarkID)
+	if err := h.checkReportBenchmark(ctx, benchmarkID); err != nil {
+		return err
+	}
+	return h.runReport(ctx, nil, benchmarkID, connectionIDs, formats, req.TrendDays)
+}
+
+func (h HttpServer) runReport(ctx echo.Context, scheduleID *uint, benchmarkID string, connectionIDs []string, formats []api.ReportFormat, trendDays int) error {
+	runs, err := h.Scheduler.reportScheduler.CreateRuns(scheduleID, benchmarkID, connectionIDs, formats, httpserver.GetUserID(ctx))
+	if err != nil {
+		return err
+	}
+	utils.EnsureRunGoroutine(func() {
+		h.Scheduler.reportScheduler.Generate(context.Background(), runs, trendDays)
+	})
+
+	result := make([]api.ReportRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, run.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// ListReportRuns godoc
+//
+//	@Summary		List generated compliance reports
+//	@Description	Returns the generated compliance reports, latest first
+//	@Security		BearerToken
+//	@Tags			reports
+//	@Produce		json
+//	@Param			scheduleId	query		string	false	"Only reports generated by this schedule"
+//	@Param			limit		query		int		false	"Maximum number of reports, defaults to 100"
+//	@Success		200			{object}	[]api.ReportRun
+//	@Router			/schedule/api/v1/reports/runs [get]
+func (h HttpServer) ListReportRuns(ctx echo.Context) error {
+	var scheduleID *uint
+	if s := ctx.QueryParam("scheduleId"); s != "" {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid schedule id")
+		}
+		scheduleID = utils.GetPointer(uint(id))
+	}
+	limit := 100
+	if s := ctx.QueryParam("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = l
+	}
+
+	runs, err := h.DB.ListReportRuns(scheduleID, reportConnectionScope(ctx), limit)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list report runs", zap.Error(err))
+		return err
+	}
+	result := make([]api.ReportRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, run.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetReportRun godoc
+//
+//	@Summary	Get generated compliance report
+//	@Security	BearerToken
+//	@Tags		reports
+//	@Produce	json
+//	@Param		run_id	path		string	true	"Report run ID"
+//	@Success	200		{object}	api.ReportRun
+//	@Router		/schedule/api/v1/reports/runs/{run_id} [get]
+func (h HttpServer) GetReportRun(ctx echo.Context) error {
+	run, err := h.getReportRunFromParam(ctx, false)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, run.ToApi())
+}
+
+// DownloadReportRun godoc
+//
+//	@Summary	Download generated compliance report
+//	@Security	BearerToken
+//	@Tags		reports
+//	@Produce	html
+//	@Produce	application/pdf
+//	@Param		run_id	path	string	true	"Report run ID"
+//	@Success	200
+//	@Router		/schedule/api/v1/reports/runs/{run_id}/download [get]
+func (h HttpServer) DownloadReportRun(ctx echo.Context) error {
+	run, err := h.getReportRunFromParam(ctx, true)
+	if err != nil {
+		return err
+	}
+	if run.Status != api.ReportRunStatusSucceeded {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("report is %s", run.Status))
+	}
+
+	contentType := "text/html; charset=utf-8"
+	if run.Format == api.ReportFormatPDF {
+		contentType = "application/pdf"
+	}
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", run.FileName))
+	return ctx.Blob(http.StatusOK, contentType, run.Content)
+}
+
+func (h HttpServer) getDiscoverySchedulePolicyFromParam(ctx echo.Context) (*model2.DiscoverySchedulePolicy, error) {
+	policyID, err := strconv.ParseUint(ctx.Param("policy_id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid policy id")
+	}
+	policy, err := h.DB.GetDiscoverySchedulePolicy(uint(policyID))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get discovery schedule policy", zap.Error(err))
+		return nil, err
+	}
+	if policy == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "discovery schedule policy not found")
+	}
+	return policy, nil
+}
+
+// validateDiscoverySchedule makes sure exactly one of the cron expression and the interval is set
+func validateDiscoverySchedule(cronExpression string, intervalMinutes int) error {
+	if (cronExpression == "") == (intervalMinutes == 0) {
+		return echo.NewHTTPError(http.StatusBadRequest, "exactly one of cronExpression and intervalMinutes is required")
+	}
+	if cronExpression != "" {
+		if _, err := utils.ParseCron(cronExpression); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	return nil
+}
+
+func previewCount(ctx echo.Context) (int, error) {
+	countStr := ctx.QueryParam("count")
+	if countStr == "" {
+		return 5, nil
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 1 || count > 100 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "count must be between 1 and 100")
+	}
+	return count, nil
+}
+
+// ListDiscoverySchedulePolicies godoc
+//
+//	@Summary		List discovery schedule policies
+//	@Description	Returns the policies overriding the global discovery intervals
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Produce		json
+//	@Success		200	{object}	[]api.DiscoverySchedulePolicy
+//	@Router			/schedule/api/v1/discovery/schedules [get]
+func (h HttpServer) ListDiscoverySchedulePolicies(ctx echo.Context) error {
+	policies, err := h.DB.ListDiscoverySchedulePolicies(false)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list discovery schedule policies", zap.Error(err))
+		return err
+	}
+	result := make([]api.DiscoverySchedulePolicy, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, policy.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CreateDiscoverySchedulePolicy godoc
+//
+//	@Summary		Create discovery schedule policy
+//	@Description	Creates a policy running discovery on a cron expression or an interval for a connection, connection tag and/or resource type.
+//	@Description	The most specific matching policy wins: connection, then connection tag, then resource type. Priority breaks ties.
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateDiscoverySchedulePolicyRequest	true	"Discovery schedule policy"
+//	@Success		201		{object}	api.DiscoverySchedulePolicy
+//	@Router			/schedule/api/v1/discovery/schedules [post]
+func (h HttpServer) CreateDiscoverySchedulePolicy(ctx echo.Context) error {
+	var req api.CreateDiscoverySchedulePolicyRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := validateDiscoverySchedule(req.CronExpression, req.IntervalMinutes); err != nil {
+		return err
+	}
+	emptyToNil := func(s *string) *string {
+		if s == nil || strings.TrimSpace(*s) == "" {
+			return nil
+		}
+		return s
+	}
+	req.ConnectionID = emptyToNil(req.ConnectionID)
+	req.ConnectionTagKey = emptyToNil(req.ConnectionTagKey)
+	req.ConnectionTagValue = emptyToNil(req.ConnectionTagValue)
+	req.ResourceType = emptyToNil(req.ResourceType)
+	if req.ConnectionTagValue != nil && req.ConnectionTagKey == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "connectionTagValue requires connectionTagKey")
+	}
+	if req.ConnectionID == nil && req.ConnectionTagKey == nil && req.ResourceType == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one of connectionID, connectionTagKey and resourceType is required")
+	}
+
+	policy := model2.DiscoverySchedulePolicy{
+		Name:               req.Name,
+		ConnectionID:       req.ConnectionID,
+		ConnectionTagKey:   req.ConnectionTagKey,
+		ConnectionTagValue: req.ConnectionTagValue,
+		ResourceType:       req.ResourceType,
+		CronExpression:     strings.TrimSpace(req.CronExpression),
+		IntervalMinutes:    req.IntervalMinutes,
+		Priority:           req.Priority,
+		Enabled:            true,
+		CreatedBy:          httpserver.GetUserID(ctx),
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if err := h.DB.CreateDiscoverySchedulePolicy(&policy); err != nil {
+		h.Scheduler.logger.Error("failed to create discovery schedule policy", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, policy.ToApi())
+}
+
+// GetDiscoverySchedulePolicy godoc
+//
+//	@Summary	Get discovery schedule policy
+//	@Security	BearerToken
+//	@Tags		describe
+//	@Produce	json
+//	@Param		policy_id	path		string	true	"Policy ID"
+//	@Success	200			{object}	api.DiscoverySchedulePolicy
+//	@Router		/schedule/api/v1/discovery/schedules/{policy_id} [get]
+func (h HttpServer) GetDiscoverySchedulePolicy(ctx echo.Context) error {
+	policy, err := h.getDiscoverySchedulePolicyFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, policy.ToApi())
+}
+
+// UpdateDiscoverySchedulePolicy godoc
+//
+//	@Summary		Update discovery schedule policy
+//	@Description	Updates the given fields of a discovery schedule policy, the scope of a policy can not be changed
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Accept			json
+//	@Produce		json
+//	@Param			policy_id	path		string										true	"Policy ID"
+//	@Param			request		body		api.UpdateDiscoverySchedulePolicyRequest	true	"Discovery schedule policy fields"
+//	@Success		200			{object}	api.DiscoverySchedulePolicy
+//	@Router			/schedule/api/v1/discovery/schedules/{policy_id} [put]
+func (h HttpServer) UpdateDiscoverySchedulePolicy(ctx echo.Context) error {
+	policy, err := h.getDiscoverySchedulePolicyFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	var req api.UpdateDiscoverySchedulePolicyRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.Name != nil {
+		policy.Name = *req.Name
+	}
+	if req.CronExpression != nil {
+		policy.CronExpression = strings.TrimSpace(*req.CronExpression)
+	}
+	if req.IntervalMinutes != nil {
+		policy.IntervalMinutes = *req.IntervalMinutes
+	}
+	if err := validateDiscoverySchedule(policy.CronExpression, policy.IntervalMinutes); err != nil {
+		return err
+	}
+	if req.Priority != nil {
+		policy.Priority = *req.Priority
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if err := h.DB.UpdateDiscoverySchedulePolicy(policy); err != nil {
+		h.Scheduler.logger.Error("failed to update discovery schedule policy", zap.Uint("policyID", policy.ID), zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, policy.ToApi())
+}
+
+// DeleteDiscoverySchedulePolicy godoc
+//
+//	@Summary		Delete discovery schedule policy
+//	@Description	Deletes a discovery schedule policy, discoveries in its scope go back to the global intervals
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Param			policy_id	path	string	true	"Policy ID"
+//	@Success		200
+//	@Router			/schedule/api/v1/discovery/schedules/{policy_id} [delete]
+func (h HttpServer) DeleteDiscoverySchedulePolicy(ctx echo.Context) error {
+	policy, err := h.getDiscoverySchedulePolicyFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.DB.DeleteDiscoverySchedulePolicy(policy.ID); err != nil {
+		h.Scheduler.logger.Error("failed to delete discovery schedule policy", zap.Uint("policyID", policy.ID), zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// PreviewDiscoverySchedulePolicy godoc
+//
+//	@Summary		Preview discovery schedule policy
+//	@Description	Returns the next run times of a policy starting from now
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Produce		json
+//	@Param			policy_id	path		string	true	"Policy ID"
+//	@Param			count		query		int		false	"Number of run times, default 5"
+//	@Success		200			{object}	api.DiscoverySchedulePreview
+//	@Router			/schedule/api/v1/discovery/schedules/{policy_id}/preview [get]
+func (h HttpServer) PreviewDiscoverySchedulePolicy(ctx echo.Context) error {
+	policy, err := h.getDiscoverySchedulePolicyFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	count, err := previewCount(ctx)
+	if err != nil {
+		return err
+	}
+
+	nextRuns, err := nextDiscoveryRuns(policy, 0, time.Now(), count)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	apiPolicy := policy.ToApi()
+	return ctx.JSON(http.StatusOK, api.DiscoverySchedulePreview{
+		Policy:   &apiPolicy,
+		NextRuns: nextRuns,
+	})
+}
+
+// PreviewDiscoverySchedule godoc
+//
+//	@Summary		Preview discovery schedule
+//	@Description	Returns the schedule policy applying to a resource type of a connection and its next run times,
+//	@Description	starting from the last discovery job. Disabled connections and manual discovery are not taken into account.
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Produce		json
+//	@Param			connectionId	query		string	true	"Connection ID"
+//	@Param			resourceType	query		string	true	"Resource type"
+//	@Param			count			query		int		false	"Number of run times, default 5"
+//	@Success		200				{object}	api.DiscoverySchedulePreview
+//	@Router			/schedule/api/v1/discovery/schedules/preview [get]
+func (h HttpServer) PreviewDiscoverySchedule(ctx echo.Context) error {
+	connectionID := ctx.QueryParam("connectionId")
+	resourceType := ctx.QueryParam("resourceType")
+	if connectionID == "" || resourceType == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "connectionId and resourceType are required")
+	}
+	count, err := previewCount(ctx)
+	if err != nil {
+		return err
+	}
+
+	connection, err := h.Scheduler.onboardClient.GetSource(&httpclient.Context{Ctx: ctx.Request().Context(), UserRole: apiAuth.InternalRole}, connectionID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get connection", zap.String("connectionID", connectionID), zap.Error(err))
+		return err
+	}
+	if connection == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "connection not found")
+	}
+	if err := httpserver.CheckAccessToConnectionID(ctx, connection.ID.String()); err != nil {
+		return err
+	}
+
+	policies, err := h.DB.ListDiscoverySchedulePolicies(true)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list discovery schedule policies", zap.Error(err))
+		return err
+	}
+	policy := resolveDiscoverySchedulePolicy(policies, *connection, resourceType)
+
+	lastJob, err := h.DB.GetLastDescribeConnectionJob(connection.ID.String(), resourceType)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get last describe job", zap.String("connectionID", connectionID), zap.Error(err))
+		return err
+	}
+
+	response := api.DiscoverySchedulePreview{
+		ConnectionID: connection.ID.String(),
+		ResourceType: resourceType,
+	}
+	interval := h.Scheduler.defaultDiscoveryInterval(connection.Connector, resourceType)
+	if policy != nil {
+		apiPolicy := policy.ToApi()
+		response.Policy = &apiPolicy
+	} else {
+		response.Interval = interval.String()
+	}
+
+	lastRun := time.Now()
+	if lastJob != nil {
+		response.LastRunAt = &lastJob.UpdatedAt
+		lastRun = lastJob.UpdatedAt
+	}
+	response.NextRuns, err = nextDiscoveryRuns(policy, interval, lastRun, count)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// the scheduler picks up overdue discoveries on its next cycle
+	now := time.Now()
+	for i, run := range response.NextRuns {
+		if run.Before(now) {
+			response.NextRuns[i] = now
+		}
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h HttpServer) getDiscoveryRateLimitFromParam(ctx echo.Context) (*model2.DiscoveryRateLimit, error) {
+	limitID, err := strconv.ParseUint(ctx.Param("limit_id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid rate limit id")
+	}
+	limit, err := h.DB.GetDiscoveryRateLimit(uint(limitID))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get discovery rate limit", zap.Error(err))
+		return nil, err
+	}
+	if limit == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "discovery rate limit not found")
+	}
+	return limit, nil
+}
+
+// ListDiscoveryRateLimits godoc
+//
+//	@Summary		List discovery rate limits
+//	@Description	Returns the concurrency limits and daily call budgets of discovery
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Produce		json
+//	@Success		200	{object}	[]api.DiscoveryRateLimit
+//	@Router			/schedule/api/v1/discovery/rate-limits [get]
+func (h HttpServer) ListDiscoveryRateLimits(ctx echo.Context) error {
+	limits, err := h.DB.ListDiscoveryRateLimits()
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list discovery rate limits", zap.Error(err))
+		return err
+	}
+	result := make([]api.DiscoveryRateLimit, 0, len(limits))
+	for _, limit := range limits {
+		result = append(result, limit.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CreateDiscoveryRateLimit godoc
+//
+//	@Summary		Create discovery rate limit
+//	@Description	Creates a concurrency limit and/or daily call budget for a connection, a resource type or both.
+//	@Description	A limit scoped only to a resource type replaces the built-in concurrency limit of that resource type.
+//	@Description	Jobs over a budget are deferred until midnight UTC with the DiscoveryBudgetExhausted error code.
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateDiscoveryRateLimitRequest	true	"Discovery rate limit"
+//	@Success		201		{object}	api.DiscoveryRateLimit
+//	@Router			/schedule/api/v1/discovery/rate-limits [post]
+func (h HttpServer) CreateDiscoveryRateLimit(ctx echo.Context) error {
+	var req api.CreateDiscoveryRateLimitRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if req.ConnectionID != nil && strings.TrimSpace(*req.ConnectionID) == "" {
+		req.ConnectionID = nil
+	}
+	if req.ResourceType != nil && strings.TrimSpace(*req.ResourceType) == "" {
+		req.ResourceType = nil
+	}
+	if req.ConnectionID == nil && req.ResourceType == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one of connectionID and resourceType is required")
+	}
+	if req.MaxConcurrentJobs == 0 && req.DailyCallBudget == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one of maxConcurrentJobs and dailyCallBudget is required")
+	}
+
+	limit := model2.DiscoveryRateLimit{
+		ConnectionID:      req.ConnectionID,
+		ResourceType:      req.ResourceType,
+		MaxConcurrentJobs: req.MaxConcurrentJobs,
+		DailyCallBudget:   req.DailyCallBudget,
+		CreatedBy:         httpserver.GetUserID(ctx),
+	}
+	if err := h.DB.CreateDiscoveryRateLimit(&limit); err != nil {
+		h.Scheduler.logger.Error("failed to create discovery rate limit", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, limit.ToApi())
+}
+
+// GetDiscoveryRateLimit godoc
+//
+//	@Summary	Get discovery rate limit
+//	@Security	BearerToken
+//	@Tags		describe
+//	@Produce	json
+//	@Param		limit_id	path		string	true	"Rate limit ID"
+//	@Success	200			{object}	api.DiscoveryRateLimit
+//	@Router		/schedule/api/v1/discovery/rate-limits/{limit_id} [get]
+func (h HttpServer) GetDiscoveryRateLimit(ctx echo.Context) error {
+	limit, err := h.getDiscoveryRateLimitFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, limit.ToApi())
+}
+
+// UpdateDiscoveryRateLimit godoc
+//
+//	@Summary		Update discovery rate limit
+//	@Description	Updates the concurrency limit and daily call budget, the scope of a rate limit can not be changed
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit_id	path		string								true	"Rate limit ID"
+//	@Param			request		body		api.UpdateDiscoveryRateLimitRequest	true	"Discovery rate limit fields"
+//	@Success		200			{object}	api.DiscoveryRateLimit
+//	@Router			/schedule/api/v1/discovery/rate-limits/{limit_id} [put]
+func (h HttpServer) UpdateDiscoveryRateLimit(ctx echo.Context) error {
+	limit, err := h.getDiscoveryRateLimitFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	var req api.UpdateDiscoveryRateLimitRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if req.MaxConcurrentJobs != nil {
+		limit.MaxConcurrentJobs = *req.MaxConcurrentJobs
+	}
+	if req.DailyCallBudget != nil {
+		limit.DailyCallBudget = *req.DailyCallBudget
+	}
+	if limit.MaxConcurrentJobs == 0 && limit.DailyCallBudget == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "at least one of maxConcurrentJobs and dailyCallBudget is required")
+	}
+	if err := h.DB.UpdateDiscoveryRateLimit(limit); err != nil {
+		h.Scheduler.logger.Error("failed to update discovery rate limit", zap.Uint("limitID", limit.ID), zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, limit.ToApi())
+}
+
+// DeleteDiscoveryRateLimit godoc
+//
+//	@Summary		Delete discovery rate limit
+//	@Description	Deletes a discovery rate limit, jobs already deferred by it run once their deferral ends
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Param			limit_id	path	string	true	"Rate limit ID"
+//	@Success		200
+//	@Router			/schedule/api/v1/discovery/rate-limits/{limit_id} [delete]
+func (h HttpServer) DeleteDiscoveryRateLimit(ctx echo.Context) error {
+	limit, err := h.getDiscoveryRateLimitFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.DB.DeleteDiscoveryRateLimit(limit.ID); err != nil {
+		h.Scheduler.logger.Error("failed to delete discovery rate limit", zap.Uint("limitID", limit.ID), zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// GetDiscoveryRateLimitsUsage godoc
+//
+//	@Summary		Get discovery rate limits usage
+//	@Description	Returns the running jobs, calls made today, remaining budget and deferred jobs of each discovery rate limit
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Produce		json
+//	@Success		200	{object}	[]api.DiscoveryRateLimitUsage
+//	@Router			/schedule/api/v1/discovery/rate-limits/usage [get]
+func (h HttpServer) GetDiscoveryRateLimitsUsage(ctx echo.Context) error {
+	limits, err := h.DB.ListDiscoveryRateLimits()
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list discovery rate limits", zap.Error(err))
+		return err
+	}
+	usages, err := h.Scheduler.discoveryRateLimitUsage(limits)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get discovery rate limits usage", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, usages)
+}
+
+// CreateJobPipeline godoc
+//
+//	@Summary		Create job pipeline
+//	@Description	Submits a DAG of describe, analytics, benchmark and report jobs. A node starts once all the nodes it depends on are done.
+//	@Description	Failure policies: continue runs the dependent nodes anyway, abort skips the nodes not started yet, retry starts the node again up to maxRetries times then aborts.
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateJobPipelineRequest	true	"Pipeline definition"
+//	@Success		201		{object}	api.JobPipeline
+//	@Router			/schedule/api/v1/jobs/pipelines [post]
+func (h HttpServer) CreateJobPipeline(ctx echo.Context) error {
+	var req api.CreateJobPipelineRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	nodes, err := newJobPipelineNodes(req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	checked := make(map[string]bool)
+	for _, node := range req.Nodes {
+		benchmarkID := strings.ToLower(node.BenchmarkID)
+		if benchmarkID == "" || checked[benchmarkID] {
+			continue
+		}
+		if err := h.checkReportBenchmark(ctx, benchmarkID); err != nil {
+			return err
+		}
+		checked[benchmarkID] = true
+	}
+
+	userID := httpserver.GetUserID(ctx)
+	if userID == "" {
+		userID = "system"
+	}
+	pipeline := model2.JobPipeline{
+		Name:      req.Name,
+		Status:    model2.JobPipelineRunning,
+		CreatedBy: userID,
+	}
+	if err := h.DB.CreateJobPipeline(&pipeline, nodes); err != nil {
+		h.Scheduler.logger.Error("failed to create job pipeline", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, jobPipelineToApi(pipeline, nodes))
+}
+
+// ListJobPipelines godoc
+//
+//	@Summary		List job pipelines
+//	@Description	Returns the job pipelines with their nodes, latest first
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Produce		json
+//	@Param			limit	query		int	false	"Maximum number of pipelines, defaults to 20"
+//	@Success		200		{object}	[]api.JobPipeline
+//	@Router			/schedule/api/v1/jobs/pipelines [get]
+func (h HttpServer) ListJobPipelines(ctx echo.Context) error {
+	limit := 20
+	if s := ctx.QueryParam("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		limit = l
+	}
+
+	pipelines, err := h.DB.ListJobPipelines(limit)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list job pipelines", zap.Error(err))
+		return err
+	}
+	result := make([]api.JobPipeline, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		nodes, err := h.DB.ListJobPipelineNodes(pipeline.ID)
+		if err != nil {
+			h.Scheduler.logger.Error("failed to list job pipeline nodes", zap.Uint("pipelineID", pipeline.ID), zap.Error(err))
+			return err
+		}
+		result = append(result, jobPipelineToApi(pipeline, nodes))
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetJobPipeline godoc
+//
+//	@Summary		Get job pipeline
+//	@Description	Returns the whole graph of a job pipeline with the status and jobs of each node
+//	@Security		BearerToken
+//	@Tags			describe
+//	@Produce		json
+//	@Param			pipeline_id	path		string	true	"Pipeline ID"
+//	@Success		200			{object}	api.JobPipeline
+//	@Router			/schedule/api/v1/jobs/pipelines/{pipeline_id} [get]
+func (h HttpServer) GetJobPipeline(ctx echo.Context) error {
+	pipelineID, err := strconv.ParseUint(ctx.Param("pipeline_id"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid pipeline id")
+	}
+	pipeline, err := h.DB.GetJobPipeline(uint(pipelineID))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get job pipeline", zap.Error(err))
+		return err
+	}
+	if pipeline == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "job pipeline not found")
+	}
+	nodes, err := h.DB.ListJobPipelineNodes(pipeline.ID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list job pipeline nodes", zap.Uint("pipelineID", pipeline.ID), zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, jobPipelineToApi(*pipeline, nodes))
+}
+
+func (h HttpServer) getTerraformBackendFromParam(ctx echo.Context) (*model2.TerraformBackend, error) {
+	backendID, err := strconv.ParseUint(ctx.Param("backend_id"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid backend id")
+	}
+	backend, err := h.DB.GetTerraformBackend(uint(backendID))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get terraform backend", zap.Error(err))
+		return nil, err
+	}
+	if backend == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "terraform backend not found")
+	}
+	return backend, nil
+}
+
+// ListTerraformBackends godoc
+//
+//	@Summary		List terraform backends
+//	@Description	Returns the terraform state backends, credentials in their config are masked
+//	@Security		BearerToken
+//	@Tags			terraform
+//	@Produce		json
+//	@Param			connectionId	query		string	false	"Connection ID"
+//	@Success		200				{object}	[]api.TerraformBackend
+//	@Router			/schedule/api/v1/terraform/backends [get]
+func (h HttpServer) ListTerraformBackends(ctx echo.Context) error {
+	var connectionID *string
+	if c := ctx.QueryParam("connectionId"); c != "" {
+		connectionID = &c
+	}
+	backends, err := h.DB.ListTerraformBackends(connectionID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list terraform backends", zap.Error(err))
+		return err
+	}
+	result := make([]api.TerraformBackend, 0, len(backends))
+	for _, backend := range backends {
+		result = append(result, backend.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// CreateTerraformBackend godoc
+//
+//	@Summary		Create terraform backend
+//	@Description	Registers a terraform state backend of a connection, states are read every hour.
+//	@Description	The config is the terraform backend configuration, local backends take a path relative to the configured local state directory.
+//	@Security		BearerToken
+//	@Tags			terraform
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		api.CreateTerraformBackendRequest	true	"Terraform backend"
+//	@Success		201		{object}	api.TerraformBackend
+//	@Router			/schedule/api/v1/terraform/backends [post]
+func (h HttpServer) CreateTerraformBackend(ctx echo.Context) error {
+	var req api.CreateTerraformBackendRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	switch req.Type {
+	case api.TerraformBackendTypeS3, api.TerraformBackendTypeAzureRM:
+	case api.TerraformBackendTypeLocal:
+		if h.Scheduler.conf.TerraformLocalStateDir == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "local terraform backends are not enabled")
+		}
+		if path, _ := req.Config["path"].(string); path == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "local terraform backend requires a path")
+		}
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid terraform backend type")
+	}
+
+	connection, err := h.Scheduler.onboardClient.GetSource(&httpclient.Context{Ctx: ctx.Request().Context(), UserRole: apiAuth.InternalRole}, req.ConnectionID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get connection", zap.String("connectionID", req.ConnectionID), zap.Error(err))
+		return err
+	}
+	if connection == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "connection not found")
+	}
+
+	settings, secrets := model2.SplitTerraformBackendConfig(req.Config)
+	configJSON, err := json.Marshal(settings)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid config")
+	}
+	backend := model2.TerraformBackend{
+		Name:         req.Name,
+		ConnectionID: connection.ID.String(),
+		Type:         req.Type,
+		CreatedBy:    httpserver.GetUserID(ctx),
+	}
+	if err := backend.Config.Set(configJSON); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid config")
+	}
+	if len(secrets) > 0 {
+		if h.Scheduler.vault == nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "terraform backend credentials can not be stored without a vault")
+		}
+		backend.Secret, err = h.Scheduler.vault.Encrypt(ctx.Request().Context(), secrets)
+		if err != nil {
+			h.Scheduler.logger.Error("failed to encrypt terraform backend credentials", zap.Error(err))
+			return err
+		}
+		for k := range secrets {
+			backend.SecretKeys = append(backend.SecretKeys, k)
+		}
+		sort.Strings(backend.SecretKeys)
+	}
+	if err := h.DB.CreateTerraformBackend(&backend); err != nil {
+		h.Scheduler.logger.Error("failed to create terraform backend", zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, backend.ToApi())
+}
+
+// GetTerraformBackend godoc
+//
+//	@Summary	Get terraform backend
+//	@Security	BearerToken
+//	@Tags		terraform
+//	@Produce	json
+//	@Param		backend_id	path		string	true	"Backend ID"
+//	@Success	200			{object}	api.TerraformBackend
+//	@Router		/schedule/api/v1/terraform/backends/{backend_id} [get]
+func (h HttpServer) GetTerraformBackend(ctx echo.Context) error {
+	backend, err := h.getTerraformBackendFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, backend.ToApi())
+}
+
+// DeleteTerraformBackend godoc
+//
+//	@Summary		Delete terraform backend
+//	@Description	Deletes a terraform backend and syncs its connection again, so the resources of its state stop being tagged.
+//	@Description	Deleting the last backend of a connection removes the terraform tags and drift records of the connection.
+//	@Security		BearerToken
+//	@Tags			terraform
+//	@Param			backend_id	path	string	true	"Backend ID"
+//	@Success		200
+//	@Router			/schedule/api/v1/terraform/backends/{backend_id} [delete]
+func (h HttpServer) DeleteTerraformBackend(ctx echo.Context) error {
+	backend, err := h.getTerraformBackendFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.DB.DeleteTerraformBackend(backend.ID); err != nil {
+		h.Scheduler.logger.Error("failed to delete terraform backend", zap.Uint("backendID", backend.ID), zap.Error(err))
+		return err
+	}
+	remaining, err := h.DB.ListTerraformBackends(&backend.ConnectionID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list terraform backends", zap.Error(err))
+		return err
+	}
+
+	connectionID := backend.ConnectionID
+	utils.EnsureRunGoroutine(func() {
+		var err error
+		if len(remaining) == 0 {
+			err = h.Scheduler.removeConnectionTerraformState(context.Background(), connectionID)
+		} else {
+			err = h.Scheduler.syncTerraformStates(context.Background(), &connectionID)
+		}
+		if err != nil {
+			h.Scheduler.logger.Error("failed to update terraform state after deleting backend", zap.String("connectionID", connectionID), zap.Error(err))
+		}
+	})
+	return ctx.NoContent(http.StatusOK)
+}
+
+// SyncTerraformState godoc
+//
+//	@Summary		Sync terraform state
+//	@Description	Reads the terraform states of a connection now instead of waiting for the hourly sync
+//	@Security		BearerToken
+//	@Tags			terraform
+//	@Param			connection_id	path	string	true	"Connection ID"
+//	@Success		202
+//	@Router			/schedule/api/v1/terraform/sync/{connection_id} [post]
+func (h HttpServer) SyncTerraformState(ctx echo.Context) error {
+	connectionID := ctx.Param("connection_id")
+	if err := httpserver.CheckAccessToConnectionID(ctx, connectionID); err != nil {
+		return err
+	}
+	backends, err := h.DB.ListTerraformBackends(&connectionID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list terraform backends", zap.Error(err))
+		return err
+	}
+	if len(backends) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "connection has no terraform backend")
+	}
+	utils.EnsureRunGoroutine(func() {
+		if err := h.Scheduler.syncTerraformStates(context.Background(), &connectionID); err != nil {
+			h.Scheduler.logger.Error("failed to sync terraform state", zap.String("connectionID", connectionID), zap.Error(err))
+		}
+	})
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// GetTerraformDrift godoc
+//
+//	@Summary		Get terraform drift
+//	@Description	Returns the resources of a connection as of its last terraform sync: managed by terraform, found in the cloud but not in any state,
+//	@Description	or in a state but not found in the cloud anymore
+//	@Security		BearerToken
+//	@Tags			terraform
+//	@Produce		json
+//	@Param			connection_id	path		string	true	"Connection ID"
+//	@Param			driftStatus		query		string	false	"Drift status"	Enums(in_sync,not_in_state,not_in_cloud)
+//	@Param			resourceType	query		string	false	"Resource type"
+//	@Param			limit			query		int		false	"Maximum number of resources, defaults to 1000"
+//	@Success		200				{object}	api.TerraformDriftResponse
+//	@Router			/schedule/api/v1/terraform/drift/{connection_id} [get]
+func (h HttpServer) GetTerraformDrift(ctx echo.Context) error {
+	connectionID := ctx.Param("connection_id")
+	if err := httpserver.CheckAccessToConnectionID(ctx, connectionID); err != nil {
+		return err
+	}
+	var driftStatus, resourceType *string
+	if d := ctx.QueryParam("driftStatus"); d != "" {
+		switch types.TerraformDriftStatus(d) {
+		case types.TerraformDriftStatusInSync, types.TerraformDriftStatusNotInState, types.TerraformDriftStatusNotInCloud:
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid drift status")
+		}
+		driftStatus = &d
+	}
+	if r := ctx.QueryParam("resourceType"); r != "" {
+		resourceType = &r
+	}
+	limit := 1000
+	if s := ctx.QueryParam("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l <= 0 || l > 10000 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 10000")
+		}
+		limit = l
+	}
+
+	backends, err := h.DB.ListTerraformBackends(&connectionID)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list terraform backends", zap.Error(err))
+		return err
+	}
+	var syncedAt *time.Time
+	for _, backend := range backends {
+		if backend.LastSyncedAt != nil && (syncedAt == nil || backend.LastSyncedAt.After(*syncedAt)) {
+			syncedAt = backend.LastSyncedAt
+		}
+	}
+	response := api.TerraformDriftResponse{Resources: []api.TerraformResource{}}
+	if syncedAt == nil {
+		return ctx.JSON(http.StatusOK, response)
+	}
+
+	res, err := es.ListTerraformResources(ctx.Request().Context(), h.Scheduler.es, connectionID, syncedAt.UnixMilli(), driftStatus, resourceType, limit)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list terraform resources", zap.String("connectionID", connectionID), zap.Error(err))
+		return err
+	}
+	for _, bucket := range res.Aggregations.DriftStatus.Buckets {
+		switch types.TerraformDriftStatus(bucket.Key) {
+		case types.TerraformDriftStatusInSync:
+			response.InSyncCount = bucket.DocCount
+		case types.TerraformDriftStatusNotInState:
+			response.NotInStateCount = bucket.DocCount
+		case types.TerraformDriftStatusNotInCloud:
+			response.NotInCloudCount = bucket.DocCount
+		}
+	}
+	for _, hit := range res.Hits.Hits {
+		r := hit.Source
+		response.Resources = append(response.Resources, api.TerraformResource{
+			ConnectionID:       r.ConnectionID,
+			ResourceID:         r.ResourceID,
+			ResourceType:       r.ResourceType,
+			ResourceName:       r.ResourceName,
+			BackendID:          r.BackendID,
+			Address:            r.Address,
+			TerraformType:      r.TerraformType,
+			ManagedByTerraform: r.ManagedByTerraform,
+			DriftStatus:        string(r.DriftStatus),
+			SyncedAt:           time.UnixMilli(r.SyncedAt),
+		})
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h HttpServer) getDeadLetterMessageFromParam(ctx echo.Context) (*dlq.Message, error) {
+	sequence, err := strconv.ParseUint(ctx.Param("sequence"), 10, 64)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid sequence")
+	}
+	msg, err := h.Scheduler.dlq.Get(ctx.Request().Context(), ctx.Param("stream"), sequence)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to get dead letter", zap.String("stream", ctx.Param("stream")), zap.Uint64("sequence", sequence), zap.Error(err))
+		return nil, err
+	}
+	if msg == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "dead letter not found")
+	}
+	return msg, nil
+}
+
+// ListDeadLetterQueues godoc
+//
+//	@Summary		List dead-letter queues
+//	@Description	Returns the dead-letter stream of every job stream with the number of messages in it
+//	@Security		BearerToken
+//	@Tags			dlq
+//	@Produce		json
+//	@Success		200	{object}	[]api.DeadLetterQueue
+//	@Router			/schedule/api/v1/dlq [get]
+func (h HttpServer) ListDeadLetterQueues(ctx echo.Context) error {
+	queues, err := h.Scheduler.dlq.Queues(ctx.Request().Context())
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list dead-letter queues", zap.Error(err))
+		return err
+	}
+	result := make([]api.DeadLetterQueue, 0, len(queues))
+	for _, queue := range queues {
+		result = append(result, deadLetterQueueToApi(queue))
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// ListDeadLetterMessages godoc
+//
+//	@Summary		List dead letters
+//	@Description	Returns the dead letters of a job stream without their payload, oldest first
+//	@Security		BearerToken
+//	@Tags			dlq
+//	@Produce		json
+//	@Param			stream	path		string	true	"Job stream name"
+//	@Param			from	query		int		false	"Sequence to start from, as returned in nextSequence"
+//	@Param			limit	query		int		false	"Maximum number of messages, defaults to 100"
+//	@Success		200		{object}	api.ListDeadLetterMessagesResponse
+//	@Router			/schedule/api/v1/dlq/{stream} [get]
+func (h HttpServer) ListDeadLetterMessages(ctx echo.Context) error {
+	var from uint64
+	if f := ctx.QueryParam("from"); f != "" {
+		var err error
+		from, err = strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid from")
+		}
+	}
+	limit := 100
+	if l := ctx.QueryParam("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 1000")
+		}
+	}
+
+	messages, next, err := h.Scheduler.dlq.List(ctx.Request().Context(), ctx.Param("stream"), from, limit)
+	if err != nil {
+		h.Scheduler.logger.Error("failed to list dead letters", zap.String("stream", ctx.Param("stream")), zap.Error(err))
+		return err
+	}
+	result := api.ListDeadLetterMessagesResponse{Messages: make([]api.DeadLetterMessage, 0, len(messages))}
+	for _, msg := range messages {
+		result.Messages = append(result.Messages, deadLetterMessageToApi(msg, false))
+	}
+	if next != 0 {
+		result.NextSequence = &next
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetDeadLetterMessage godoc
+//
+//	@Summary		Get dead letter
+//	@Description	Returns a dead letter with its payload
+//	@Security		BearerToken
+//	@Tags			dlq
+//	@Produce		json
+//	@Param			stream		path		string	true	"Job stream name"
+//	@Param			sequence	path		int		true	"Dead letter sequence"
+//	@Success		200			{object}	api.DeadLetterMessage
+//	@Router			/schedule/api/v1/dlq/{stream}/{sequence} [get]
+func (h HttpServer) GetDeadLetterMessage(ctx echo.Context) error {
+	msg, err := h.getDeadLetterMessageFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, deadLetterMessageToApi(*msg, true))
+}
+
+// DeleteDeadLetterMessage godoc
+//
+//	@Summary	Delete dead letter
+//	@Security	BearerToken
+//	@Tags		dlq
+//	@Param		stream		path	string	true	"Job stream name"
+//	@Param		sequence	path	int		true	"Dead letter sequence"
+//	@Success	200
+//	@Router		/schedule/api/v1/dlq/{stream}/{sequence} [delete]
+func (h HttpServer) DeleteDeadLetterMessage(ctx echo.Context) error {
+	msg, err := h.getDeadLetterMessageFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.Scheduler.dlq.Delete(ctx.Request().Context(), msg.Stream, msg.Sequence); err != nil {
+		h.Scheduler.logger.Error("failed to delete dead letter", zap.String("stream", msg.Stream), zap.Uint64("sequence", msg.Sequence), zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// PurgeDeadLetterQueue godoc
+//
+//	@Summary	Purge dead-letter queue
+//	@Security	BearerToken
+//	@Tags		dlq
+//	@Param		stream	path	string	true	"Job stream name"
+//	@Success	200
+//	@Router		/schedule/api/v1/dlq/{stream} [delete]
+func (h HttpServer) PurgeDeadLetterQueue(ctx echo.Context) error {
+	stream := ctx.Param("stream")
+	if err := h.Scheduler.dlq.Purge(ctx.Request().Context(), stream); err != nil {
+		h.Scheduler.logger.Error("failed to purge dead letters", zap.String("stream", stream), zap.Error(err))
+		return err
+	}
+	DeadLetterQueueDepth.WithLabelValues(stream).Set(0)
+	return ctx.NoContent(http.StatusOK)
+}
+
+// ReplayDeadLetterMessage godoc
+//
+//	@Summary		Replay dead letter
+//	@Description	Publishes a dead letter back to the subject it failed on and removes it from the dead-letter queue
+//	@Security		BearerToken
+//	@Tags			dlq
+//	@Param			stream		path	string	true	"Job stream name"
+//	@Param			sequence	path	int		true	"Dead letter sequence"
+//	@Success		200
+//	@Router			/schedule/api/v1/dlq/{stream}/{sequence}/replay [post]
+func (h HttpServer) ReplayDeadLetterMessage(ctx echo.Context) error {
+	msg, err := h.getDeadLetterMessageFromParam(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.Scheduler.dlq.Replay(ctx.Request().Context(), msg.Stream, msg.Sequence); err != nil {
+		h.Scheduler.logger.Error("failed to replay dead letter", zap.String("stream", msg.Stream), zap.Uint64("sequence", msg.Sequence), zap.Error(err))
+		return err
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// ReplayDeadLetterQueue godoc
+//
+//	@Summary		Replay dead-letter queue
+//	@Description	Publishes every dead letter of a job stream back to the subject it failed on
+//	@Security		BearerToken
+//	@Tags			dlq
+//	@Produce		json
+//	@Param			stream	path		string	true	"Job stream name"
+//	@Success		200		{object}	api.ReplayDeadLetterMessagesResponse
+//	@Router			/schedule/api/v1/dlq/{stream}/replay [post]
+func (h HttpServer) ReplayDeadLetterQueue(ctx echo.Context) error {
+	res, err := h.Scheduler.replayDeadLetters(ctx.Request().Context(), ctx.Param("stream"))
+	if err != nil {
+		h.Scheduler.logger.Error("failed to replay dead letters", zap.String("stream", ctx.Param("stream")), zap.Error(err))
+		return err
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
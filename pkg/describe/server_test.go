@@ -0,0 +1,27 @@
+package describe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportConnectionsInScope(t *testing.T) {
+	tests := []struct {
+		name          string
+		scope         []string
+		connectionIDs []string
+		want          bool
+	}{
+		{name: "unrestricted caller, all connections", scope: nil, connectionIDs: nil, want: true},
+		{name: "unrestricted caller, some connections", scope: nil, connectionIDs: []string{"conn-1"}, want: true},
+		{name: "restricted caller, all connections", scope: []string{"conn-1"}, connectionIDs: nil, want: false},
+		{name: "restricted caller, connections in scope", scope: []string{"conn-1", "conn-2"}, connectionIDs: []string{"conn-2"}, want: true},
+		{name: "restricted caller, connection out of scope", scope: []string{"conn-1"}, connectionIDs: []string{"conn-1", "conn-3"}, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, reportConnectionsInScope(tc.scope, tc.connectionIDs))
+		})
+	}
+}
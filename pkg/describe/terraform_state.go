@@ -0,0 +1,337 @@
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	authApi "github.com/kaytu-io/kaytu-util/pkg/api"
+	es2 "github.com/kaytu-io/kaytu-util/pkg/es"
+	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
+	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/kaytu-io/open-governance/pkg/describe/es"
+	"github.com/kaytu-io/open-governance/pkg/describe/internal"
+	"github.com/kaytu-io/open-governance/pkg/types"
+	"github.com/kaytu-io/terraform-package/external/states"
+	"go.uber.org/zap"
+)
+
+const (
+	TerraformStateSyncInterval = 1 * time.Hour
+
+	terraformSyncBatchSize = 500
+
+	TerraformManagedTagKey = "managed_by_terraform"
+	TerraformAddressTagKey = "terraform_address"
+)
+
+func (s *Scheduler) RunTerraformStateSync(ctx context.Context) {
+	s.logger.Info("Syncing terraform states on a timer")
+
+	t := ticker.NewTicker(TerraformStateSyncInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		if err := s.syncTerraformStates(ctx, nil); err != nil {
+			s.logger.Error("failed to sync terraform states", zap.Error(err))
+			continue
+		}
+	}
+}
+
+// syncTerraformStates syncs the terraform states of every connection with a backend, or of the given connection only
+func (s *Scheduler) syncTerraformStates(ctx context.Context, connectionID *string) error {
+	backends, err := s.db.ListTerraformBackends(connectionID)
+	if err != nil {
+		return err
+	}
+
+	backendsByConnection := make(map[string][]model.TerraformBackend)
+	for _, backend := range backends {
+		backendsByConnection[backend.ConnectionID] = append(backendsByConnection[backend.ConnectionID], backend)
+	}
+	var errs []error
+	for connID, connBackends := range backendsByConnection {
+		if err := s.syncConnectionTerraformState(ctx, connID, connBackends); err != nil {
+			s.logger.Error("failed to sync terraform state of connection", zap.String("connection_id", connID), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scheduler) readTerraformState(ctx context.Context, backend model.TerraformBackend) (state *states.State, err error) {
+	// the terraform backends are not written with a long-running caller in mind
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to read terraform state: %v", r)
+		}
+	}()
+
+	config, err := backend.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if backend.Secret != "" {
+		if s.vault == nil {
+			return nil, errors.New("no vault is configured to decrypt the terraform backend credentials")
+		}
+		secrets, err := s.vault.Decrypt(ctx, backend.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt terraform backend credentials: %w", err)
+		}
+		for k, v := range secrets {
+			config[k] = v
+		}
+	}
+	switch backend.Type {
+	case api.TerraformBackendTypeLocal:
+		if s.conf.TerraformLocalStateDir == "" {
+			return nil, errors.New("local terraform backends are not enabled")
+		}
+		path, _ := config["path"].(string)
+		if path == "" {
+			return nil, errors.New("local terraform backend requires a path")
+		}
+		// the path can not escape the local state directory
+		return internal.GetLocalState(filepath.Join(s.conf.TerraformLocalStateDir, filepath.Clean("/"+path)))
+	case api.TerraformBackendTypeS3, api.TerraformBackendTypeAzureRM:
+		return internal.GetRemoteState(internal.Config{Type: string(backend.Type), Config: config})
+	default:
+		return nil, fmt.Errorf("unsupported terraform backend type %s", backend.Type)
+	}
+}
+
+// terraformResourceTags replaces the terraform tags of a resource and reports whether they changed
+func terraformResourceTags(tags []es2.Tag, address string) ([]es2.Tag, bool) {
+	result := make([]es2.Tag, 0, len(tags)+2)
+	var oldAddress string
+	for _, tag := range tags {
+		switch tag.Key {
+		case TerraformManagedTagKey:
+		case TerraformAddressTagKey:
+			oldAddress = tag.Value
+		default:
+			result = append(result, tag)
+		}
+	}
+	if address != "" {
+		result = append(result, es2.Tag{Key: TerraformManagedTagKey, Value: "true"}, es2.Tag{Key: TerraformAddressTagKey, Value: address})
+	}
+	return result, len(result) != len(tags) || oldAddress != address
+}
+
+// syncConnectionTerraformState tags the connection's resources found in its states and records the drift between
+// the states and the cloud. Drift is only computed when every state of the connection could be read.
+func (s *Scheduler) syncConnectionTerraformState(ctx context.Context, connectionID string, backends []model.TerraformBackend) error {
+	type stateEntry struct {
+		backendID uint
+		resource  internal.StateResource
+	}
+	inState := make(map[string]stateEntry)
+	stateResourceCounts := make(map[uint]int)
+	var readErr error
+	for _, backend := range backends {
+		state, err := s.readTerraformState(ctx, backend)
+		if err != nil {
+			s.logger.Error("failed to read terraform state", zap.Uint("backend_id", backend.ID), zap.Error(err))
+			if err := s.db.UpdateTerraformBackendSync(backend.ID, nil, err.Error(), 0); err != nil {
+				return err
+			}
+			readErr = err
+			continue
+		}
+		resources := internal.GetStateResources(state)
+		stateResourceCounts[backend.ID] = len(resources)
+		for _, r := range resources {
+			inState[strings.ToLower(r.ID)] = stateEntry{backendID: backend.ID, resource: r}
+		}
+	}
+	if readErr != nil {
+		return fmt.Errorf("failed to read terraform states: %w", readErr)
+	}
+
+	syncedAt := time.Now()
+	var docs []es2.Doc
+	flush := func(force bool) error {
+		if len(docs) == 0 || (!force && len(docs) < terraformSyncBatchSize) {
+			return nil
+		}
+		if _, err := s.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, docs); err != nil {
+			return err
+		}
+		docs = nil
+		return nil
+	}
+	addDoc := func(doc types.TerraformResource) {
+		keys, idx := doc.KeysAndIndex()
+		doc.EsID = es2.HashOf(keys...)
+		doc.EsIndex = idx
+		docs = append(docs, doc)
+	}
+
+	inCloud := make(map[string]bool)
+	var searchAfter []any
+	for {
+		res, err := es.GetResourceIDsForAccountFromES(ctx, s.es, connectionID, searchAfter, 1000)
+		if err != nil {
+			if kaytu.IsIndexNotFoundErr(err) {
+				break
+			}
+			return err
+		}
+		if len(res.Hits.Hits) == 0 {
+			break
+		}
+		for _, hit := range res.Hits.Hits {
+			searchAfter = hit.Sort
+			lookup := hit.Source
+			key := strings.ToLower(lookup.ResourceID)
+			entry, managed := inState[key]
+
+			doc := types.TerraformResource{
+				ConnectionID: connectionID,
+				ResourceID:   lookup.ResourceID,
+				ResourceType: strings.ToLower(lookup.ResourceType),
+				ResourceName: lookup.Name,
+				DriftStatus:  types.TerraformDriftStatusNotInState,
+				SyncedAt:     syncedAt.UnixMilli(),
+			}
+			if managed {
+				inCloud[key] = true
+				doc.ManagedByTerraform = true
+				doc.BackendID = entry.backendID
+				doc.Address = entry.resource.Address
+				doc.TerraformType = entry.resource.Type
+				doc.DriftStatus = types.TerraformDriftStatusInSync
+			}
+			addDoc(doc)
+
+			// the next discovery rewrites the lookup resource without these tags, they come back on the next sync
+			if tags, changed := terraformResourceTags(lookup.Tags, doc.Address); changed {
+				lookup.Tags = tags
+				keys, idx := lookup.KeysAndIndex()
+				lookup.EsID = es2.HashOf(keys...)
+				lookup.EsIndex = idx
+				docs = append(docs, lookup)
+			}
+		}
+		if err := flush(false); err != nil {
+			return err
+		}
+	}
+
+	for key, entry := range inState {
+		if inCloud[key] {
+			continue
+		}
+		addDoc(types.TerraformResource{
+			ConnectionID:       connectionID,
+			ResourceID:         entry.resource.ID,
+			BackendID:          entry.backendID,
+			Address:            entry.resource.Address,
+			TerraformType:      entry.resource.Type,
+			ManagedByTerraform: true,
+			DriftStatus:        types.TerraformDriftStatusNotInCloud,
+			SyncedAt:           syncedAt.UnixMilli(),
+		})
+		if err := flush(false); err != nil {
+			return err
+		}
+	}
+	if err := flush(true); err != nil {
+		return err
+	}
+
+	if err := s.deleteStaleTerraformResources(ctx, connectionID, syncedAt); err != nil {
+		s.logger.Error("failed to delete stale terraform resources", zap.String("connection_id", connectionID), zap.Error(err))
+	}
+
+	for _, backend := range backends {
+		if err := s.db.UpdateTerraformBackendSync(backend.ID, &syncedAt, "", stateResourceCounts[backend.ID]); err != nil {
+			return err
+		}
+	}
+	s.logger.Info("synced terraform state", zap.String("connection_id", connectionID), zap.Int("state_resources", len(inState)))
+	return nil
+}
+
+// removeConnectionTerraformState untags the connection's resources and deletes its drift records,
+// syncs skip connections without a backend so this runs when the last backend of a connection is deleted
+func (s *Scheduler) removeConnectionTerraformState(ctx context.Context, connectionID string) error {
+	var docs []es2.Doc
+	var searchAfter []any
+	for {
+		res, err := es.GetResourceIDsForAccountFromES(ctx, s.es, connectionID, searchAfter, 1000)
+		if err != nil {
+			if kaytu.IsIndexNotFoundErr(err) {
+				break
+			}
+			return err
+		}
+		if len(res.Hits.Hits) == 0 {
+			break
+		}
+		for _, hit := range res.Hits.Hits {
+			searchAfter = hit.Sort
+			lookup := hit.Source
+			if tags, changed := terraformResourceTags(lookup.Tags, ""); changed {
+				lookup.Tags = tags
+				keys, idx := lookup.KeysAndIndex()
+				lookup.EsID = es2.HashOf(keys...)
+				lookup.EsIndex = idx
+				docs = append(docs, lookup)
+			}
+		}
+		if len(docs) >= terraformSyncBatchSize {
+			if _, err := s.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, docs); err != nil {
+				return err
+			}
+			docs = nil
+		}
+	}
+	if len(docs) > 0 {
+		if _, err := s.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, docs); err != nil {
+			return err
+		}
+	}
+	return s.deleteStaleTerraformResources(ctx, connectionID, time.Now())
+}
+
+func (s *Scheduler) deleteStaleTerraformResources(ctx context.Context, connectionID string, syncedAt time.Time) error {
+	filters := []kaytu.BoolFilter{
+		kaytu.NewTermFilter("connectionID", connectionID),
+		kaytu.NewRangeFilter("syncedAt", "", "", fmt.Sprintf("%d", syncedAt.UnixMilli()), ""),
+	}
+	root := map[string]any{
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": filters,
+			},
+		},
+	}
+	rootJson, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	task := es.DeleteTask{
+		ConnectionID: connectionID,
+		ResourceType: "terraform-resource",
+		TaskType:     es.DeleteTaskTypeQuery,
+		Query:        string(rootJson),
+		QueryIndex:   types.TerraformResourcesIndex,
+	}
+	keys, idx := task.KeysAndIndex()
+	task.EsID = es2.HashOf(keys...)
+	task.EsIndex = idx
+	_, err = s.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, []es2.Doc{task})
+	return err
+}
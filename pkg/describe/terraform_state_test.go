@@ -0,0 +1,219 @@
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	es2 "github.com/kaytu-io/kaytu-util/pkg/es"
+	"github.com/kaytu-io/open-governance/pkg/describe/api"
+	"github.com/kaytu-io/open-governance/pkg/describe/config"
+	"github.com/kaytu-io/open-governance/pkg/describe/db/model"
+	"github.com/kaytu-io/open-governance/pkg/describe/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testTerraformState = `{
+  "version": 4,
+  "terraform_version": "1.5.7",
+  "serial": 3,
+  "lineage": "0f3c2e4a-8c1e-4b7a-9d2a-3f6f1b2c4d5e",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 0, "attributes": {"arn": "arn:aws:s3:::logs", "id": "logs"}}
+      ]
+    },
+    {
+      "module": "module.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "server",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": 0, "schema_version": 1, "attributes": {"arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-1", "id": "i-1"}},
+        {"index_key": 1, "schema_version": 1, "attributes": {"arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-2", "id": "i-2"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "azurerm_resource_group",
+      "name": "main",
+      "provider": "provider[\"registry.terraform.io/hashicorp/azurerm\"]",
+      "instances": [
+        {"schema_version": 0, "attributes": {"id": "/subscriptions/sub-1/resourceGroups/main", "name": "main"}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "aws_caller_identity",
+      "name": "current",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 0, "attributes": {"arn": "arn:aws:iam::123456789012:user/ci", "id": "123456789012"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "random_id",
+      "name": "suffix",
+      "provider": "provider[\"registry.terraform.io/hashicorp/random\"]",
+      "instances": [
+        {"schema_version": 0, "attributes": {"id": "a1b2", "hex": "a1b2"}}
+      ]
+    }
+  ]
+}`
+
+type testVault struct {
+	secrets map[string]any
+}
+
+func (v testVault) Encrypt(_ context.Context, data map[string]any) (string, error) {
+	b, err := json.Marshal(data)
+	return string(b), err
+}
+
+func (v testVault) Decrypt(_ context.Context, cypherText string) (map[string]any, error) {
+	if cypherText != "sealed" {
+		return nil, errors.New("invalid cypher text")
+	}
+	return v.secrets, nil
+}
+
+func newLocalTerraformBackend(t *testing.T, config map[string]any) model.TerraformBackend {
+	t.Helper()
+	b, err := json.Marshal(config)
+	require.NoError(t, err)
+	backend := model.TerraformBackend{Type: api.TerraformBackendTypeLocal}
+	require.NoError(t, backend.Config.Set(b))
+	return backend
+}
+
+func TestReadTerraformState_Local(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "prod"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prod", "terraform.tfstate"), []byte(testTerraformState), 0o644))
+	s := &Scheduler{logger: zap.NewNop(), conf: config.SchedulerConfig{TerraformLocalStateDir: dir}}
+
+	state, err := s.readTerraformState(context.Background(), newLocalTerraformBackend(t, map[string]any{"path": "prod/terraform.tfstate"}))
+	require.NoError(t, err)
+
+	resources := internal.GetStateResources(state)
+	assert.ElementsMatch(t, []internal.StateResource{
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", ID: "arn:aws:s3:::logs"},
+		{Address: "module.web.aws_instance.server[0]", Type: "aws_instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-1"},
+		{Address: "module.web.aws_instance.server[1]", Type: "aws_instance", ID: "arn:aws:ec2:us-east-1:123456789012:instance/i-2"},
+		{Address: "azurerm_resource_group.main", Type: "azurerm_resource_group", ID: "/subscriptions/sub-1/resourceGroups/main"},
+	}, resources)
+
+	t.Run("path can not escape the state directory", func(t *testing.T) {
+		outside := filepath.Join(t.TempDir(), "terraform.tfstate")
+		require.NoError(t, os.WriteFile(outside, []byte(testTerraformState), 0o644))
+		rel, err := filepath.Rel(dir, outside)
+		require.NoError(t, err)
+
+		_, err = s.readTerraformState(context.Background(), newLocalTerraformBackend(t, map[string]any{"path": rel}))
+		assert.Error(t, err)
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := s.readTerraformState(context.Background(), newLocalTerraformBackend(t, map[string]any{}))
+		assert.EqualError(t, err, "local terraform backend requires a path")
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.tfstate"), []byte(`{"version": 4, "resources": [`), 0o644))
+		_, err := s.readTerraformState(context.Background(), newLocalTerraformBackend(t, map[string]any{"path": "broken.tfstate"}))
+		assert.Error(t, err)
+	})
+
+	t.Run("local backends disabled", func(t *testing.T) {
+		disabled := &Scheduler{logger: zap.NewNop()}
+		_, err := disabled.readTerraformState(context.Background(), newLocalTerraformBackend(t, map[string]any{"path": "prod/terraform.tfstate"}))
+		assert.EqualError(t, err, "local terraform backends are not enabled")
+	})
+}
+
+func TestReadTerraformState_Secret(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte(testTerraformState), 0o644))
+
+	backend := newLocalTerraformBackend(t, map[string]any{})
+	backend.Secret = "sealed"
+
+	// the decrypted credentials are merged into the backend config
+	s := &Scheduler{
+		logger: zap.NewNop(),
+		conf:   config.SchedulerConfig{TerraformLocalStateDir: dir},
+		vault:  testVault{secrets: map[string]any{"path": "terraform.tfstate"}},
+	}
+	_, err := s.readTerraformState(context.Background(), backend)
+	assert.NoError(t, err)
+
+	backend.Secret = "tampered"
+	_, err = s.readTerraformState(context.Background(), backend)
+	assert.ErrorContains(t, err, "failed to decrypt terraform backend credentials")
+
+	s.vault = nil
+	_, err = s.readTerraformState(context.Background(), backend)
+	assert.ErrorContains(t, err, "no vault is configured")
+}
+
+func TestSplitTerraformBackendConfig(t *testing.T) {
+	settings, secrets := model.SplitTerraformBackendConfig(map[string]any{
+		"bucket":       "states",
+		"region":       "us-east-1",
+		"access_key":   "AKIA",
+		"secret_key":   "s3cr3t",
+		"Token":        "t",
+		"sas_token":    "sas",
+		"storage_name": "account",
+	})
+	assert.Equal(t, map[string]any{"bucket": "states", "region": "us-east-1", "storage_name": "account"}, settings)
+	assert.Equal(t, map[string]any{"access_key": "AKIA", "secret_key": "s3cr3t", "Token": "t", "sas_token": "sas"}, secrets)
+}
+
+func TestTerraformBackend_ToApi(t *testing.T) {
+	backend := newLocalTerraformBackend(t, map[string]any{"bucket": "states", "password": "legacy"})
+	backend.Secret = "sealed"
+	backend.SecretKeys = []string{"secret_key"}
+
+	assert.Equal(t, map[string]any{"bucket": "states", "password": "********", "secret_key": "********"}, backend.ToApi().Config)
+}
+
+func TestTerraformResourceTags(t *testing.T) {
+	tags := []es2.Tag{{Key: "env", Value: "prod"}}
+
+	tagged, changed := terraformResourceTags(tags, "aws_s3_bucket.logs")
+	assert.True(t, changed)
+	assert.Equal(t, []es2.Tag{
+		{Key: "env", Value: "prod"},
+		{Key: TerraformManagedTagKey, Value: "true"},
+		{Key: TerraformAddressTagKey, Value: "aws_s3_bucket.logs"},
+	}, tagged)
+
+	_, changed = terraformResourceTags(tagged, "aws_s3_bucket.logs")
+	assert.False(t, changed)
+
+	moved, changed := terraformResourceTags(tagged, "module.logs.aws_s3_bucket.this")
+	assert.True(t, changed)
+	assert.Contains(t, moved, es2.Tag{Key: TerraformAddressTagKey, Value: "module.logs.aws_s3_bucket.this"})
+
+	untagged, changed := terraformResourceTags(tagged, "")
+	assert.True(t, changed)
+	assert.Equal(t, tags, untagged)
+
+	_, changed = terraformResourceTags(tags, "")
+	assert.False(t, changed)
+}
@@ -0,0 +1,377 @@
+/*
+dlq keeps the messages that consumers of the job streams failed to process, so they can
+be inspected and replayed instead of being retried forever or dropped.
+Every job stream has its own dead-letter stream named after it.
+*/
+package dlq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderSubject  = "Kaytu-Dlq-Subject"
+	HeaderConsumer = "Kaytu-Dlq-Consumer"
+	HeaderReason   = "Kaytu-Dlq-Reason"
+	HeaderAttempts = "Kaytu-Dlq-Attempts"
+	HeaderFailedAt = "Kaytu-Dlq-Failed-At"
+	HeaderMsgID    = "Kaytu-Dlq-Msg-Id"
+
+	StreamSuffix  = "-dlq"
+	SubjectPrefix = "dlq."
+
+	// DefaultMaxAttempts is the number of deliveries after which a failing message is dead-lettered
+	DefaultMaxAttempts = 5
+)
+
+type Message struct {
+	Stream   string
+	Sequence uint64
+	Subject  string
+	Consumer string
+	Reason   string
+	Attempts int
+	FailedAt time.Time
+	Data     []byte
+}
+
+type Queue struct {
+	Stream   string
+	Messages uint64
+	Bytes    uint64
+	FirstAt  *time.Time
+	LastAt   *time.Time
+}
+
+type DeadLetterQueue struct {
+	url    string
+	logger *zap.Logger
+
+	// the connection is opened on first use, most processes never dead-letter a message
+	// and the job queue does not expose its own connection to share
+	mu sync.Mutex
+	js jetstream.JetStream
+
+	streams sync.Map
+}
+
+func New(url string, logger *zap.Logger) (*DeadLetterQueue, error) {
+	return &DeadLetterQueue{
+		url:    url,
+		logger: logger.Named("dlq"),
+	}, nil
+}
+
+func (q *DeadLetterQueue) jetStream() (jetstream.JetStream, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.js != nil {
+		return q.js, nil
+	}
+
+	conn, err := nats.Connect(q.url, nats.Name("dlq"))
+	if err != nil {
+		return nil, err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	q.js = js
+	return js, nil
+}
+
+func StreamName(stream string) string {
+	return stream + StreamSuffix
+}
+
+func subject(stream string) string {
+	return SubjectPrefix + stream
+}
+
+func (q *DeadLetterQueue) setup(ctx context.Context, js jetstream.JetStream, stream string) error {
+	if _, ok := q.streams.Load(stream); ok {
+		return nil
+	}
+
+	// poison messages must survive restarts, unlike the job streams which are kept in memory
+	_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        StreamName(stream),
+		Description: fmt.Sprintf("dead letters of %s", stream),
+		Subjects:    []string{subject(stream)},
+		Retention:   jetstream.LimitsPolicy,
+		MaxMsgs:     100000,
+		MaxAge:      14 * 24 * time.Hour,
+		Discard:     jetstream.DiscardOld,
+		Replicas:    1,
+		Storage:     jetstream.FileStorage,
+	})
+	if err != nil {
+		return err
+	}
+	q.streams.Store(stream, struct{}{})
+	return nil
+}
+
+// Send stores a copy of the message in the dead-letter stream of the stream it was consumed from.
+func (q *DeadLetterQueue) Send(ctx context.Context, consumer string, msg jetstream.Msg, reason error) error {
+	md, err := msg.Metadata()
+	if err != nil {
+		return err
+	}
+	js, err := q.jetStream()
+	if err != nil {
+		return err
+	}
+	if err := q.setup(ctx, js, md.Stream); err != nil {
+		return err
+	}
+
+	dead := nats.NewMsg(subject(md.Stream))
+	dead.Data = msg.Data()
+	for key, values := range msg.Headers() {
+		if key == jetstream.MsgIDHeader {
+			continue
+		}
+		for _, value := range values {
+			dead.Header.Add(key, value)
+		}
+	}
+	dead.Header.Set(HeaderMsgID, msg.Headers().Get(jetstream.MsgIDHeader))
+	dead.Header.Set(HeaderSubject, msg.Subject())
+	dead.Header.Set(HeaderConsumer, consumer)
+	if reason != nil {
+		dead.Header.Set(HeaderReason, reason.Error())
+	}
+	dead.Header.Set(HeaderAttempts, strconv.FormatUint(md.NumDelivered, 10))
+	dead.Header.Set(HeaderFailedAt, time.Now().UTC().Format(time.RFC3339))
+
+	_, err = js.PublishMsg(ctx, dead)
+	return err
+}
+
+// Fail is called by consumers when a message could not be processed. The message is redelivered
+// until it has been delivered maxAttempts times, then it is moved to the dead-letter stream.
+func (q *DeadLetterQueue) Fail(ctx context.Context, consumer string, msg jetstream.Msg, reason error, maxAttempts int) {
+	md, err := msg.Metadata()
+	if err == nil && md.NumDelivered < uint64(maxAttempts) {
+		if err := msg.Nak(); err != nil {
+			q.logger.Error("failed to send not-ack for message", zap.Error(err))
+		}
+		return
+	}
+
+	if err := q.Send(ctx, consumer, msg, reason); err != nil {
+		q.logger.Error("failed to dead-letter message", zap.String("consumer", consumer), zap.Error(err))
+		if err := msg.Nak(); err != nil {
+			q.logger.Error("failed to send not-ack for message", zap.Error(err))
+		}
+		return
+	}
+	q.logger.Warn("message dead-lettered", zap.String("consumer", consumer), zap.String("subject", msg.Subject()), zap.NamedError("reason", reason))
+
+	if err := msg.Term(); err != nil {
+		q.logger.Error("failed to terminate message", zap.Error(err))
+	}
+}
+
+// Queues returns the dead-letter streams and their depth.
+func (q *DeadLetterQueue) Queues(ctx context.Context) ([]Queue, error) {
+	js, err := q.jetStream()
+	if err != nil {
+		return nil, err
+	}
+	var queues []Queue
+	lister := js.ListStreams(ctx, jetstream.WithStreamListSubject(SubjectPrefix+">"))
+	for info := range lister.Info() {
+		if !strings.HasSuffix(info.Config.Name, StreamSuffix) {
+			continue
+		}
+		queue := Queue{
+			Stream:   strings.TrimSuffix(info.Config.Name, StreamSuffix),
+			Messages: info.State.Msgs,
+			Bytes:    info.State.Bytes,
+		}
+		if info.State.Msgs > 0 {
+			firstAt, lastAt := info.State.FirstTime, info.State.LastTime
+			queue.FirstAt, queue.LastAt = &firstAt, &lastAt
+		}
+		queues = append(queues, queue)
+	}
+	if err := lister.Err(); err != nil {
+		return nil, err
+	}
+	return queues, nil
+}
+
+// List returns up to limit dead letters of the stream starting from the given sequence,
+// and the sequence to continue from, which is zero when there are no more messages.
+func (q *DeadLetterQueue) List(ctx context.Context, stream string, fromSeq uint64, limit int) ([]Message, uint64, error) {
+	js, err := q.jetStream()
+	if err != nil {
+		return nil, 0, err
+	}
+	s, err := js.Stream(ctx, StreamName(stream))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrStreamNotFound) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	info, err := s.Info(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.State.Msgs == 0 {
+		return nil, 0, nil
+	}
+
+	var messages []Message
+	seq := max(fromSeq, info.State.FirstSeq)
+	for ; seq <= info.State.LastSeq && len(messages) < limit; seq++ {
+		raw, err := s.GetMsg(ctx, seq)
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgNotFound) {
+				continue
+			}
+			return nil, 0, err
+		}
+		messages = append(messages, toMessage(stream, raw))
+	}
+	if seq > info.State.LastSeq {
+		seq = 0
+	}
+	return messages, seq, nil
+}
+
+// Get returns a dead letter of the stream, or nil if it does not exist.
+func (q *DeadLetterQueue) Get(ctx context.Context, stream string, seq uint64) (*Message, error) {
+	js, err := q.jetStream()
+	if err != nil {
+		return nil, err
+	}
+	s, err := js.Stream(ctx, StreamName(stream))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrStreamNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	raw, err := s.GetMsg(ctx, seq)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	msg := toMessage(stream, raw)
+	return &msg, nil
+}
+
+// Delete removes a dead letter of the stream.
+func (q *DeadLetterQueue) Delete(ctx context.Context, stream string, seq uint64) error {
+	js, err := q.jetStream()
+	if err != nil {
+		return err
+	}
+	s, err := js.Stream(ctx, StreamName(stream))
+	if err != nil {
+		return err
+	}
+	return s.DeleteMsg(ctx, seq)
+}
+
+// Purge removes all the dead letters of the stream.
+func (q *DeadLetterQueue) Purge(ctx context.Context, stream string) error {
+	js, err := q.jetStream()
+	if err != nil {
+		return err
+	}
+	s, err := js.Stream(ctx, StreamName(stream))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrStreamNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.Purge(ctx)
+}
+
+// Replay publishes a dead letter back to the subject it was consumed from and removes it from
+// the dead-letter stream. The dead letter is removed first so a replay is never published twice,
+// and stored again if publishing fails.
+func (q *DeadLetterQueue) Replay(ctx context.Context, stream string, seq uint64) error {
+	js, err := q.jetStream()
+	if err != nil {
+		return err
+	}
+	s, err := js.Stream(ctx, StreamName(stream))
+	if err != nil {
+		return err
+	}
+	raw, err := s.GetMsg(ctx, seq)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(raw.Header.Get(HeaderSubject))
+	msg.Data = raw.Data
+	for key, values := range raw.Header {
+		if strings.HasPrefix(key, "Kaytu-Dlq-") {
+			continue
+		}
+		for _, value := range values {
+			msg.Header.Add(key, value)
+		}
+	}
+	// the original message id would be dropped by the deduplication window of the stream
+	if id := raw.Header.Get(HeaderMsgID); id != "" {
+		msg.Header.Set(jetstream.MsgIDHeader, fmt.Sprintf("%s-replay-%d", id, seq))
+	}
+
+	if err := s.DeleteMsg(ctx, seq); err != nil {
+		return err
+	}
+	if _, err := js.PublishMsg(ctx, msg); err != nil {
+		dead := nats.NewMsg(subject(stream))
+		dead.Data = raw.Data
+		for key, values := range raw.Header {
+			for _, value := range values {
+				dead.Header.Add(key, value)
+			}
+		}
+		if _, restoreErr := js.PublishMsg(ctx, dead); restoreErr != nil {
+			q.logger.Error("failed to restore dead letter after a failed replay", zap.String("stream", stream), zap.Uint64("sequence", seq), zap.Error(restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func toMessage(stream string, raw *jetstream.RawStreamMsg) Message {
+	msg := Message{
+		Stream:   stream,
+		Sequence: raw.Sequence,
+		Subject:  raw.Header.Get(HeaderSubject),
+		Consumer: raw.Header.Get(HeaderConsumer),
+		Reason:   raw.Header.Get(HeaderReason),
+		FailedAt: raw.Time,
+		Data:     raw.Data,
+	}
+	msg.Attempts, _ = strconv.Atoi(raw.Header.Get(HeaderAttempts))
+	if failedAt, err := time.Parse(time.RFC3339, raw.Header.Get(HeaderFailedAt)); err == nil {
+		msg.FailedAt = failedAt
+	}
+	return msg
+}
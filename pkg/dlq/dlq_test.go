@@ -0,0 +1,297 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeStream keeps the messages of a stream in memory, the embedded interface panics on the methods not used by the queue
+type fakeStream struct {
+	jetstream.Stream
+	msgs      map[uint64]*jetstream.RawStreamMsg
+	lastSeq   uint64
+	deleteErr error
+}
+
+func (s *fakeStream) add(msg *nats.Msg) uint64 {
+	s.lastSeq++
+	s.msgs[s.lastSeq] = &jetstream.RawStreamMsg{
+		Subject:  msg.Subject,
+		Sequence: s.lastSeq,
+		Header:   msg.Header,
+		Data:     msg.Data,
+		Time:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	return s.lastSeq
+}
+
+func (s *fakeStream) Info(context.Context, ...jetstream.StreamInfoOpt) (*jetstream.StreamInfo, error) {
+	info := &jetstream.StreamInfo{}
+	info.State.Msgs = uint64(len(s.msgs))
+	info.State.LastSeq = s.lastSeq
+	for seq := uint64(1); seq <= s.lastSeq; seq++ {
+		if _, ok := s.msgs[seq]; ok {
+			info.State.FirstSeq = seq
+			break
+		}
+	}
+	return info, nil
+}
+
+func (s *fakeStream) GetMsg(_ context.Context, seq uint64, _ ...jetstream.GetMsgOpt) (*jetstream.RawStreamMsg, error) {
+	msg, ok := s.msgs[seq]
+	if !ok {
+		return nil, jetstream.ErrMsgNotFound
+	}
+	return msg, nil
+}
+
+func (s *fakeStream) DeleteMsg(_ context.Context, seq uint64) error {
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	if _, ok := s.msgs[seq]; !ok {
+		return jetstream.ErrMsgNotFound
+	}
+	delete(s.msgs, seq)
+	return nil
+}
+
+type fakeJetStream struct {
+	jetstream.JetStream
+	streams    map[string]*fakeStream
+	subjects   map[string]string
+	published  []*nats.Msg
+	publishErr func(msg *nats.Msg) error
+}
+
+func newFakeJetStream() *fakeJetStream {
+	return &fakeJetStream{streams: map[string]*fakeStream{}, subjects: map[string]string{}}
+}
+
+func (js *fakeJetStream) addStream(name string, subjects ...string) *fakeStream {
+	s := &fakeStream{msgs: map[uint64]*jetstream.RawStreamMsg{}}
+	js.streams[name] = s
+	for _, subj := range subjects {
+		js.subjects[subj] = name
+	}
+	return s
+}
+
+func (js *fakeJetStream) CreateOrUpdateStream(_ context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	if s, ok := js.streams[cfg.Name]; ok {
+		return s, nil
+	}
+	return js.addStream(cfg.Name, cfg.Subjects...), nil
+}
+
+func (js *fakeJetStream) Stream(_ context.Context, name string) (jetstream.Stream, error) {
+	s, ok := js.streams[name]
+	if !ok {
+		return nil, jetstream.ErrStreamNotFound
+	}
+	return s, nil
+}
+
+func (js *fakeJetStream) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if js.publishErr != nil {
+		if err := js.publishErr(msg); err != nil {
+			return nil, err
+		}
+	}
+	name, ok := js.subjects[msg.Subject]
+	if !ok {
+		return nil, jetstream.ErrNoStreamResponse
+	}
+	js.published = append(js.published, msg)
+	seq := js.streams[name].add(msg)
+	return &jetstream.PubAck{Stream: name, Sequence: seq}, nil
+}
+
+type fakeMsg struct {
+	jetstream.Msg
+	subject      string
+	data         []byte
+	headers      nats.Header
+	numDelivered uint64
+	naks, terms  int
+}
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{Stream: "describe", NumDelivered: m.numDelivered}, nil
+}
+func (m *fakeMsg) Data() []byte         { return m.data }
+func (m *fakeMsg) Headers() nats.Header { return m.headers }
+func (m *fakeMsg) Subject() string      { return m.subject }
+func (m *fakeMsg) Nak() error           { m.naks++; return nil }
+func (m *fakeMsg) Term() error          { m.terms++; return nil }
+
+func newTestQueue(js jetstream.JetStream) *DeadLetterQueue {
+	return &DeadLetterQueue{js: js, logger: zap.NewNop()}
+}
+
+func newFakeMsg(numDelivered uint64) *fakeMsg {
+	headers := nats.Header{}
+	headers.Set(jetstream.MsgIDHeader, "job-1")
+	headers.Set("Trace", "abc")
+	return &fakeMsg{subject: "describe.jobs", data: []byte(`{"id":1}`), headers: headers, numDelivered: numDelivered}
+}
+
+func TestFail(t *testing.T) {
+	js := newFakeJetStream()
+	q := newTestQueue(js)
+
+	t.Run("redelivered before the last attempt", func(t *testing.T) {
+		msg := newFakeMsg(DefaultMaxAttempts - 1)
+		q.Fail(context.Background(), "scheduler", msg, errors.New("boom"), DefaultMaxAttempts)
+		assert.Equal(t, 1, msg.naks)
+		assert.Equal(t, 0, msg.terms)
+		assert.Empty(t, js.published)
+	})
+
+	t.Run("dead-lettered on the last attempt", func(t *testing.T) {
+		msg := newFakeMsg(DefaultMaxAttempts)
+		q.Fail(context.Background(), "scheduler", msg, errors.New("boom"), DefaultMaxAttempts)
+		assert.Equal(t, 0, msg.naks)
+		assert.Equal(t, 1, msg.terms)
+
+		require.Len(t, js.published, 1)
+		dead := js.published[0]
+		assert.Equal(t, "dlq.describe", dead.Subject)
+		assert.Equal(t, []byte(`{"id":1}`), dead.Data)
+		assert.Empty(t, dead.Header.Get(jetstream.MsgIDHeader))
+		assert.Equal(t, "job-1", dead.Header.Get(HeaderMsgID))
+		assert.Equal(t, "abc", dead.Header.Get("Trace"))
+
+		messages, next, err := q.List(context.Background(), "describe", 0, 10)
+		require.NoError(t, err)
+		assert.Zero(t, next)
+		require.Len(t, messages, 1)
+		assert.Equal(t, "describe.jobs", messages[0].Subject)
+		assert.Equal(t, "scheduler", messages[0].Consumer)
+		assert.Equal(t, "boom", messages[0].Reason)
+		assert.Equal(t, DefaultMaxAttempts, messages[0].Attempts)
+	})
+
+	t.Run("redelivered when dead-lettering fails", func(t *testing.T) {
+		js.publishErr = func(*nats.Msg) error { return errors.New("unavailable") }
+		defer func() { js.publishErr = nil }()
+
+		msg := newFakeMsg(DefaultMaxAttempts + 1)
+		q.Fail(context.Background(), "scheduler", msg, errors.New("boom"), DefaultMaxAttempts)
+		assert.Equal(t, 1, msg.naks)
+		assert.Equal(t, 0, msg.terms)
+	})
+}
+
+func TestList_Paging(t *testing.T) {
+	js := newFakeJetStream()
+	s := js.addStream(StreamName("describe"), subject("describe"))
+	for i := 0; i < 7; i++ {
+		msg := nats.NewMsg(subject("describe"))
+		msg.Header.Set(HeaderSubject, "describe.jobs")
+		msg.Data = []byte{byte('a' + i)}
+		s.add(msg)
+	}
+	// gaps left by deleted dead letters are skipped
+	delete(s.msgs, 1)
+	delete(s.msgs, 4)
+	q := newTestQueue(js)
+
+	var pages [][]uint64
+	var from uint64
+	for {
+		messages, next, err := q.List(context.Background(), "describe", from, 2)
+		require.NoError(t, err)
+		var page []uint64
+		for _, m := range messages {
+			page = append(page, m.Sequence)
+		}
+		pages = append(pages, page)
+		if next == 0 {
+			break
+		}
+		from = next
+	}
+	assert.Equal(t, [][]uint64{{2, 3}, {5, 6}, {7}}, pages)
+
+	messages, next, err := q.List(context.Background(), "describe", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, messages, 5)
+	assert.Zero(t, next)
+
+	messages, next, err = q.List(context.Background(), "query-runner", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+	assert.Zero(t, next)
+}
+
+func TestReplay(t *testing.T) {
+	newQueue := func() (*fakeJetStream, *fakeStream, uint64) {
+		js := newFakeJetStream()
+		js.addStream("describe", "describe.jobs")
+		s := js.addStream(StreamName("describe"), subject("describe"))
+		dead := nats.NewMsg(subject("describe"))
+		dead.Header.Set(HeaderSubject, "describe.jobs")
+		dead.Header.Set(HeaderMsgID, "job-1")
+		dead.Header.Set(HeaderReason, "boom")
+		dead.Header.Set("Trace", "abc")
+		dead.Data = []byte(`{"id":1}`)
+		return js, s, s.add(dead)
+	}
+
+	t.Run("publishes and removes the dead letter", func(t *testing.T) {
+		js, s, seq := newQueue()
+		require.NoError(t, newTestQueue(js).Replay(context.Background(), "describe", seq))
+
+		assert.Empty(t, s.msgs)
+		require.Len(t, js.published, 1)
+		replayed := js.published[0]
+		assert.Equal(t, "describe.jobs", replayed.Subject)
+		assert.Equal(t, []byte(`{"id":1}`), replayed.Data)
+		assert.Equal(t, "job-1-replay-1", replayed.Header.Get(jetstream.MsgIDHeader))
+		assert.Equal(t, "abc", replayed.Header.Get("Trace"))
+		for key := range replayed.Header {
+			assert.False(t, strings.HasPrefix(key, "Kaytu-Dlq-"), key)
+		}
+	})
+
+	t.Run("nothing is published when the dead letter can not be removed", func(t *testing.T) {
+		js, s, seq := newQueue()
+		s.deleteErr = errors.New("unavailable")
+		assert.Error(t, newTestQueue(js).Replay(context.Background(), "describe", seq))
+		assert.Empty(t, js.published)
+		assert.Len(t, s.msgs, 1)
+	})
+
+	t.Run("dead letter is stored again when publishing fails", func(t *testing.T) {
+		js, s, seq := newQueue()
+		js.publishErr = func(msg *nats.Msg) error {
+			if msg.Subject == "describe.jobs" {
+				return errors.New("unavailable")
+			}
+			return nil
+		}
+		assert.Error(t, newTestQueue(js).Replay(context.Background(), "describe", seq))
+
+		require.Len(t, s.msgs, 1)
+		restored := s.msgs[s.lastSeq]
+		assert.Equal(t, []byte(`{"id":1}`), restored.Data)
+		assert.Equal(t, "describe.jobs", restored.Header.Get(HeaderSubject))
+		assert.Equal(t, "boom", restored.Header.Get(HeaderReason))
+	})
+
+	t.Run("unknown dead letter", func(t *testing.T) {
+		js, _, _ := newQueue()
+		assert.ErrorIs(t, newTestQueue(js).Replay(context.Background(), "describe", 42), jetstream.ErrMsgNotFound)
+	})
+}
@@ -39,7 +39,7 @@ type NamedQueryTagsResult struct {
 
 type RunQueryByIDRequest struct {
 	Page        Page                 `json:"page" validate:"required"`
-	Type        string               `json:"type"`
+	Type        string               `json:"type"` // named_query, control or saved_query
 	ID          string               `json:"id"`
 	Sorts       []NamedQuerySortItem `json:"sorts"`
 	QueryParams map[string]string    `json:"query_params"`
@@ -0,0 +1,68 @@
+package api
+
+type DiffQueryRunsRequest struct {
+	BaseRunID   string   `json:"baseRunID" validate:"required"`
+	TargetRunID string   `json:"targetRunID" validate:"required"`
+	KeyColumns  []string `json:"keyColumns"` // Columns identifying a row across runs, the whole row is used if empty
+}
+
+type QueryRunDiffRow struct {
+	Key []string `json:"key"`           // Values of the key columns
+	Row []string `json:"row,omitempty"` // Empty in compact diffs
+}
+
+type QueryRunChangedCell struct {
+	Column string `json:"column"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type QueryRunChangedRow struct {
+	Key    []string              `json:"key"`              // Values of the key columns
+	Before []string              `json:"before,omitempty"` // Empty in compact diffs
+	After  []string              `json:"after,omitempty"`  // Empty in compact diffs
+	Cells  []QueryRunChangedCell `json:"cells"`            // Only the cells which are changed
+}
+
+type QueryRunDiff struct {
+	BaseRunID         string               `json:"baseRunID,omitempty"`
+	TargetRunID       string               `json:"targetRunID,omitempty"`
+	QueryID           string               `json:"queryID,omitempty"`
+	KeyColumns        []string             `json:"keyColumns"`
+	BaseColumnNames   []string             `json:"baseColumnNames"`
+	TargetColumnNames []string             `json:"targetColumnNames"`
+	AddedCount        int                  `json:"addedCount"`
+	RemovedCount      int                  `json:"removedCount"`
+	ChangedCount      int                  `json:"changedCount"`
+	UnchangedCount    int                  `json:"unchangedCount"`
+	Added             []QueryRunDiffRow    `json:"added"`     // Rows of the target run which are not in the base run, in target order
+	Removed           []QueryRunDiffRow    `json:"removed"`   // Rows of the base run which are not in the target run, in base order
+	Changed           []QueryRunChangedRow `json:"changed"`   // Rows with the same key and different values, in target order
+	Truncated         bool                 `json:"truncated"` // True if the row lists were cut, counts are always complete
+}
+
+// HasChanges tells if any row was added, removed or changed
+func (d QueryRunDiff) HasChanges() bool {
+	return d.AddedCount > 0 || d.RemovedCount > 0 || d.ChangedCount > 0
+}
+
+// Compact drops the full rows and keeps at most limit items in each row list, keys and changed cells are kept
+func (d QueryRunDiff) Compact(limit int) QueryRunDiff {
+	compact := d
+	compact.Added = make([]QueryRunDiffRow, 0, min(limit, len(d.Added)))
+	for _, row := range d.Added[:min(limit, len(d.Added))] {
+		compact.Added = append(compact.Added, QueryRunDiffRow{Key: row.Key})
+	}
+	compact.Removed = make([]QueryRunDiffRow, 0, min(limit, len(d.Removed)))
+	for _, row := range d.Removed[:min(limit, len(d.Removed))] {
+		compact.Removed = append(compact.Removed, QueryRunDiffRow{Key: row.Key})
+	}
+	compact.Changed = make([]QueryRunChangedRow, 0, min(limit, len(d.Changed)))
+	for _, row := range d.Changed[:min(limit, len(d.Changed))] {
+		compact.Changed = append(compact.Changed, QueryRunChangedRow{Key: row.Key, Cells: row.Cells})
+	}
+	if len(d.Added) > limit || len(d.Removed) > limit || len(d.Changed) > limit {
+		compact.Truncated = true
+	}
+	return compact
+}
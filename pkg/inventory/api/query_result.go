@@ -0,0 +1,35 @@
+package api
+
+import "time"
+
+type QueryResultFormat string
+
+const (
+	QueryResultFormatCSV     QueryResultFormat = "csv"
+	QueryResultFormatJSONL   QueryResultFormat = "jsonl"
+	QueryResultFormatParquet QueryResultFormat = "parquet"
+)
+
+func (f QueryResultFormat) IsValid() bool {
+	switch f {
+	case QueryResultFormatCSV, QueryResultFormatJSONL, QueryResultFormatParquet:
+		return true
+	}
+	return false
+}
+
+type CreateQueryResultRequest struct {
+	Query    string               `json:"query" validate:"required"`
+	Sorts    []NamedQuerySortItem `json:"sorts"`
+	PageSize int                  `json:"page_size"` // Number of rows in the first page, defaults to 100
+}
+
+type QueryResultPage struct {
+	RunID      string    `json:"run_id"`
+	Headers    []string  `json:"headers"`
+	TotalRows  int       `json:"total_rows"`
+	Truncated  bool      `json:"truncated"`             // True if the result exceeded the cached rows limit or the result quota of the user
+	Result     [][]any   `json:"result"`                // Rows of this page. in order to access a specific cell please use Result[Row][Column]
+	NextCursor string    `json:"next_cursor,omitempty"` // Cursor of the next page, empty on the last page
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,44 @@
+package api
+
+import "github.com/kaytu-io/kaytu-util/pkg/source"
+
+type QueryValidationSeverity string
+
+const (
+	QueryValidationSeverityError   QueryValidationSeverity = "error"
+	QueryValidationSeverityWarning QueryValidationSeverity = "warning"
+)
+
+type QueryValidationCode string
+
+const (
+	QueryValidationCodeInvalidTemplate      QueryValidationCode = "invalid_template"
+	QueryValidationCodeUnboundParameter     QueryValidationCode = "unbound_parameter"
+	QueryValidationCodeSyntaxError          QueryValidationCode = "syntax_error"
+	QueryValidationCodeUnsupportedStatement QueryValidationCode = "unsupported_statement"
+	QueryValidationCodeMultipleStatements   QueryValidationCode = "multiple_statements"
+	QueryValidationCodeUnknownTable         QueryValidationCode = "unknown_table"
+	QueryValidationCodeUnknownColumn        QueryValidationCode = "unknown_column"
+)
+
+type ValidateQueryRequest struct {
+	Query       string            `json:"query" validate:"required"`
+	Engine      *QueryEngine      `json:"engine"`
+	QueryParams map[string]string `json:"query_params"` // Parameters given at run time, in addition to the workspace query parameters
+}
+
+type QueryValidationIssue struct {
+	Severity QueryValidationSeverity `json:"severity" enums:"error,warning"`
+	Code     QueryValidationCode     `json:"code" enums:"invalid_template,unbound_parameter,syntax_error,unsupported_statement,multiple_statements,unknown_table,unknown_column"`
+	Message  string                  `json:"message"`
+	Position *int                    `json:"position,omitempty"` // 1-based character position in the query after the parameters are filled in
+}
+
+type ValidateQueryResponse struct {
+	Valid      bool                   `json:"valid"` // False if there is any issue with error severity
+	Issues     []QueryValidationIssue `json:"issues"`
+	Parameters []string               `json:"parameters"` // Template parameters used by the query
+	Tables     []string               `json:"tables"`     // Tables and query views read by the query
+	Connectors []source.Type          `json:"connectors"` // Connectors of the plugin tables read by the query
+	Columns    []string               `json:"columns"`    // Columns returned by the query, empty if they are not known before running it, e.g. for select *
+}
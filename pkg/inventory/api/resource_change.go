@@ -0,0 +1,35 @@
+package api
+
+import (
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+)
+
+type ResourceChangeDiff struct {
+	Operation string `json:"operation" example:"replace"` // add, remove or replace
+	Path      string `json:"path" example:"/Instance/InstanceType"`
+	OldValue  string `json:"oldValue,omitempty" example:"\"t3.micro\""` // Previous value as json
+	NewValue  string `json:"newValue,omitempty" example:"\"t3.large\""` // New value as json
+}
+
+type ResourceChange struct {
+	KaytuResourceID string               `json:"kaytuResourceID"`
+	ResourceType    string               `json:"resourceType" example:"aws::ec2::instance"`
+	ResourceName    string               `json:"resourceName"`
+	ConnectionID    string               `json:"connectionID"`
+	Connector       source.Type          `json:"connector" example:"AWS"`
+	DiscoveryJobID  uint                 `json:"discoveryJobID"`
+	ChangeType      string               `json:"changeType" example:"modify"` // create, modify or delete
+	Diff            []ResourceChangeDiff `json:"diff,omitempty"`
+	ChangedAt       time.Time            `json:"changedAt" example:"2020-01-01T00:00:00Z"`
+}
+
+type GetResourceHistoryResponse struct {
+	Changes []ResourceChange `json:"changes"`
+}
+
+type ListResourceChangesResponse struct {
+	TotalCount int64            `json:"totalCount"`
+	Changes    []ResourceChange `json:"changes"`
+}
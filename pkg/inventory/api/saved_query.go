@@ -0,0 +1,88 @@
+package api
+
+import (
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+)
+
+type SavedQueryVisibility string
+
+const (
+	SavedQueryVisibilityPrivate   SavedQueryVisibility = "private"
+	SavedQueryVisibilityTeam      SavedQueryVisibility = "team"
+	SavedQueryVisibilityWorkspace SavedQueryVisibility = "workspace"
+)
+
+func (v SavedQueryVisibility) IsValid() bool {
+	switch v {
+	case SavedQueryVisibilityPrivate, SavedQueryVisibilityTeam, SavedQueryVisibilityWorkspace:
+		return true
+	}
+	return false
+}
+
+type SavedQuery struct {
+	ID             string               `json:"id" example:"b7d8e9a1-2c3d-4e5f-8a9b-0c1d2e3f4a5b"`
+	Title          string               `json:"title" example:"Public buckets"`
+	Description    string               `json:"description"`
+	OwnerID        string               `json:"owner_id"`
+	Visibility     SavedQueryVisibility `json:"visibility" enums:"private,team,workspace"`
+	SharedWith     []string             `json:"shared_with"` // Users which can see the query when visibility is team
+	Connectors     []source.Type        `json:"connectors"`
+	QueryToExecute string               `json:"query_to_execute"`
+	Engine         string               `json:"engine" example:"odysseus-sql"`
+	Parameters     []QueryParameter     `json:"parameters"`
+	Tags           map[string][]string  `json:"tags"`
+	Version        int                  `json:"version" example:"1"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+type CreateSavedQueryRequest struct {
+	Title          string               `json:"title" validate:"required"`
+	Description    string               `json:"description"`
+	Visibility     SavedQueryVisibility `json:"visibility" enums:"private,team,workspace"` // Defaults to private
+	SharedWith     []string             `json:"shared_with"`
+	Connectors     []string             `json:"connectors"`
+	QueryToExecute string               `json:"query_to_execute" validate:"required"`
+	Engine         string               `json:"engine" example:"odysseus-sql"`
+	Parameters     []QueryParameter     `json:"parameters"`
+	Tags           map[string][]string  `json:"tags"`
+}
+
+// UpdateSavedQueryRequest only changes the provided fields
+type UpdateSavedQueryRequest struct {
+	Title          *string               `json:"title"`
+	Description    *string               `json:"description"`
+	Visibility     *SavedQueryVisibility `json:"visibility" enums:"private,team,workspace"`
+	SharedWith     []string              `json:"shared_with"`
+	Connectors     []string              `json:"connectors"`
+	QueryToExecute *string               `json:"query_to_execute"`
+	Engine         *string               `json:"engine"`
+	Parameters     []QueryParameter      `json:"parameters"`
+	Tags           map[string][]string   `json:"tags"`
+}
+
+type ListSavedQueriesResponse struct {
+	Items      []SavedQuery `json:"items"`
+	TotalCount int          `json:"total_count"`
+}
+
+type SavedQueryVersion struct {
+	Version        int              `json:"version" example:"2"`
+	Title          string           `json:"title"`
+	Description    string           `json:"description"`
+	QueryToExecute string           `json:"query_to_execute"`
+	Engine         string           `json:"engine"`
+	Parameters     []QueryParameter `json:"parameters"`
+	CreatedBy      string           `json:"created_by"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+type SavedQueryVersionDiff struct {
+	FromVersion   int      `json:"from_version" example:"1"`
+	ToVersion     int      `json:"to_version" example:"2"`
+	ChangedFields []string `json:"changed_fields" example:"query_to_execute"`
+	QueryDiff     string   `json:"query_diff"` // Unified diff of the query text
+}
@@ -17,7 +17,9 @@ import (
 
 type InventoryServiceClient interface {
 	RunQuery(ctx *httpclient.Context, req api.RunQueryRequest) (*api.RunQueryResponse, error)
+	ValidateQuery(ctx *httpclient.Context, req api.ValidateQueryRequest) (*api.ValidateQueryResponse, error)
 	GetQuery(ctx *httpclient.Context, id string) (*api.NamedQueryItemV2, error)
+	GetSavedQuery(ctx *httpclient.Context, id string) (*api.SavedQuery, error)
 	CountResources(ctx *httpclient.Context) (int64, error)
 	ListConnectionsData(ctx *httpclient.Context, connectionIds []string, resourceCollections []string, startTime, endTime *time.Time, metricIDs []string, needCost, needResourceCount bool) (map[string]api.ConnectionData, error)
 	ListResourceTypesMetadata(ctx *httpclient.Context, connectors []source.Type, services []string, resourceTypes []string, summarized bool, tags map[string]string, pageSize, pageNumber int) (*api.ListResourceTypeMetadataResponse, error)
@@ -58,6 +60,24 @@ func (s *inventoryClient) RunQuery(ctx *httpclient.Context, req api.RunQueryRequ
 	return &resp, nil
 }
 
+func (s *inventoryClient) ValidateQuery(ctx *httpclient.Context, req api.ValidateQueryRequest) (*api.ValidateQueryResponse, error) {
+	url := fmt.Sprintf("%s/api/v3/query/validate", s.baseURL)
+
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp api.ValidateQueryResponse
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodPost, url, ctx.ToHeaders(), reqBytes, &resp); err != nil {
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
 func (s *inventoryClient) CountResources(ctx *httpclient.Context) (int64, error) {
 	url := fmt.Sprintf("%s/api/v2/resources/count", s.baseURL)
 
@@ -83,6 +103,23 @@ func (s *inventoryClient) GetQuery(ctx *httpclient.Context, id string) (*api.Nam
 	return &namedQuery, nil
 }
 
+// GetSavedQuery returns the saved query if it is visible to the user of the context, nil if not found
+func (s *inventoryClient) GetSavedQuery(ctx *httpclient.Context, id string) (*api.SavedQuery, error) {
+	url := fmt.Sprintf("%s/api/v3/saved-queries/%s", s.baseURL, id)
+
+	var savedQuery api.SavedQuery
+	if statusCode, err := httpclient.DoRequest(ctx.Ctx, http.MethodGet, url, ctx.ToHeaders(), nil, &savedQuery); err != nil {
+		if statusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		if 400 <= statusCode && statusCode < 500 {
+			return nil, echo.NewHTTPError(statusCode, err.Error())
+		}
+		return nil, err
+	}
+	return &savedQuery, nil
+}
+
 func (s *inventoryClient) ListAnalyticsMetrics(ctx *httpclient.Context, metricType *analyticsDB.MetricType) ([]api.AnalyticsMetric, error) {
 	url := fmt.Sprintf("%s/api/v2/analytics/metrics/list", s.baseURL)
 
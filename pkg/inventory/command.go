@@ -31,6 +31,9 @@ var (
 	ComplianceBaseUrl = os.Getenv("COMPLIANCE_BASE_URL")
 	MetadataBaseUrl   = os.Getenv("METADATA_BASE_URL")
 
+	// QueryResultCacheDir has to be shared by every replica, cached results are read by any of them
+	QueryResultCacheDir = os.Getenv("QUERY_RESULT_CACHE_DIR")
+
 	HttpAddress = os.Getenv("HTTP_ADDRESS")
 )
 
@@ -52,10 +55,12 @@ func start(ctx context.Context, cnf config3.InventoryConfig) error {
 	}
 
 	handler, err := InitializeHttpHandler(
+		ctx,
 		cnf.ElasticSearch,
 		PostgreSQLHost, PostgreSQLPort, PostgreSQLDb, PostgreSQLUser, PostgreSQLPassword, PostgreSQLSSLMode,
 		SteampipeHost, SteampipePort, SteampipeDb, SteampipeUser, SteampipePassword,
 		SchedulerBaseUrl, OnboardBaseUrl, ComplianceBaseUrl, MetadataBaseUrl,
+		QueryResultCacheDir,
 		logger,
 	)
 	if err != nil {
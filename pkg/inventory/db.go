@@ -37,11 +37,23 @@ func (db Database) Initialize() error {
 		&ResourceCollection{},
 		&ResourceCollectionTag{},
 		&ResourceTypeV2{},
+		&SavedQuery{},
+		&SavedQueryTag{},
+		&SavedQueryParameter{},
+		&SavedQueryVersion{},
+		&QueryResult{},
 	)
 	if err != nil {
 		return err
 	}
 
+	// the former history was shared by every user so its rows can't be attributed to one, it's dropped instead of migrated
+	if db.orm.Migrator().HasTable(legacyNamedQueryHistoryTable) {
+		if err := db.orm.Migrator().DropTable(legacyNamedQueryHistoryTable); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -292,9 +304,9 @@ GROUP BY key;
 	return results, nil
 }
 
-func (db Database) GetQueryHistory() ([]NamedQueryHistory, error) {
+func (db Database) GetQueryHistory(userID string) ([]NamedQueryHistory, error) {
 	var history []NamedQueryHistory
-	tx := db.orm.Order("executed_at desc").Limit(3).Find(&history)
+	tx := db.orm.Where("user_id = ?", userID).Order("executed_at desc").Limit(3).Find(&history)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -302,35 +314,36 @@ func (db Database) GetQueryHistory() ([]NamedQueryHistory, error) {
 	return history, nil
 }
 
-func (db Database) UpdateQueryHistory(query string) error {
+func (db Database) UpdateQueryHistory(userID, query string) error {
 	history := NamedQueryHistory{
+		UserID:     userID,
 		Query:      query,
 		ExecutedAt: time.Now(),
 	}
 	// Upsert query history
 	err := db.orm.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "query"}},
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "query"}},
 		DoUpdates: clause.AssignmentColumns([]string{"executed_at"}),
 	}).Create(&history).Error
 	if err != nil {
 		return err
 	}
 
-	// Only keep latest 100 queries in history
+	// Only keep latest 100 queries in history of the user
 	const keepNumber = 100
 	var count int64
-	err = db.orm.Model(&NamedQueryHistory{}).Count(&count).Error
+	err = db.orm.Model(&NamedQueryHistory{}).Where("user_id = ?", userID).Count(&count).Error
 	if err != nil {
 		return err
 	}
 	if count > keepNumber {
 		var oldest NamedQueryHistory
-		err = db.orm.Model(&NamedQueryHistory{}).Order("executed_at desc").Offset(keepNumber - 1).Limit(1).Find(&oldest).Error
+		err = db.orm.Model(&NamedQueryHistory{}).Where("user_id = ?", userID).Order("executed_at desc").Offset(keepNumber - 1).Limit(1).Find(&oldest).Error
 		if err != nil {
 			return err
 		}
 
-		err = db.orm.Model(&NamedQueryHistory{}).Where("executed_at < ?", oldest.ExecutedAt).Delete(&NamedQueryHistory{}).Error
+		err = db.orm.Model(&NamedQueryHistory{}).Where("user_id = ? AND executed_at < ?", userID, oldest.ExecutedAt).Delete(&NamedQueryHistory{}).Error
 		if err != nil {
 			return err
 		}
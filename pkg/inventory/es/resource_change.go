@@ -0,0 +1,100 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/open-governance/pkg/types"
+)
+
+type ResourceChangesResponse struct {
+	Hits struct {
+		Total kaytu.SearchTotal `json:"total"`
+		Hits  []struct {
+			ID     string               `json:"_id"`
+			Source types.ResourceChange `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// GetResourceChanges returns the change history of a resource, newest first
+func GetResourceChanges(ctx context.Context, client kaytu.Client, kaytuResourceID string, resourceType string, size int) ([]types.ResourceChange, error) {
+	filters := []map[string]any{
+		{"term": map[string]any{"kaytuResourceID": kaytuResourceID}},
+	}
+	if resourceType != "" {
+		filters = append(filters, map[string]any{"term": map[string]any{"resourceType": strings.ToLower(resourceType)}})
+	}
+
+	response, err := searchResourceChanges(ctx, client, filters, size)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]types.ResourceChange, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		changes = append(changes, hit.Source)
+	}
+	return changes, nil
+}
+
+// ListResourceChanges returns the changes recorded since the given time, newest first, along with their total count
+func ListResourceChanges(ctx context.Context, client kaytu.Client, since time.Time, connectionIDs, resourceTypes, changeTypes []string, size int) ([]types.ResourceChange, int64, error) {
+	filters := []map[string]any{
+		{"range": map[string]any{"changedAt": map[string]any{"gte": since.UnixMilli()}}},
+	}
+	if len(connectionIDs) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"connectionID": connectionIDs}})
+	}
+	if len(resourceTypes) > 0 {
+		lowered := make([]string, 0, len(resourceTypes))
+		for _, resourceType := range resourceTypes {
+			lowered = append(lowered, strings.ToLower(resourceType))
+		}
+		filters = append(filters, map[string]any{"terms": map[string]any{"resourceType": lowered}})
+	}
+	if len(changeTypes) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"changeType": changeTypes}})
+	}
+
+	response, err := searchResourceChanges(ctx, client, filters, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	changes := make([]types.ResourceChange, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		changes = append(changes, hit.Source)
+	}
+	return changes, response.Hits.Total.Value, nil
+}
+
+func searchResourceChanges(ctx context.Context, client kaytu.Client, filters []map[string]any, size int) (*ResourceChangesResponse, error) {
+	root := map[string]any{
+		"size": size,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"filter": filters,
+			},
+		},
+		"sort": []map[string]any{
+			{"changedAt": "desc"},
+			{"_id": "desc"},
+		},
+	}
+	queryBytes, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ResourceChangesResponse
+	err = client.SearchWithTrackTotalHits(ctx, types.ResourceChangesIndex, string(queryBytes), nil, &response, true)
+	if err != nil {
+		if kaytu.IsIndexNotFoundErr(err) {
+			return &response, nil
+		}
+		return nil, err
+	}
+	return &response, nil
+}
@@ -1,6 +1,7 @@
 package inventory
 
 import (
+	"context"
 	"fmt"
 	metadataClient "github.com/kaytu-io/open-governance/pkg/metadata/client"
 
@@ -14,7 +15,9 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/steampipe"
 	complianceClient "github.com/kaytu-io/open-governance/pkg/compliance/client"
 	describeClient "github.com/kaytu-io/open-governance/pkg/describe/client"
+	queryvalidator "github.com/kaytu-io/open-governance/pkg/inventory/query-validator"
 	onboardClient "github.com/kaytu-io/open-governance/pkg/onboard/client"
+	"github.com/kaytu-io/open-governance/pkg/utils"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"go.uber.org/zap"
 )
@@ -29,6 +32,8 @@ type HttpHandler struct {
 	onboardClient    onboardClient.OnboardServiceClient
 	complianceClient complianceClient.ComplianceServiceClient
 	metadataClient   metadataClient.MetadataServiceClient
+	queryResults     *queryResultCache
+	querySchema      *queryvalidator.Schema
 
 	logger *zap.Logger
 
@@ -36,10 +41,12 @@ type HttpHandler struct {
 }
 
 func InitializeHttpHandler(
+	ctx context.Context,
 	esConf config.ElasticSearch,
 	postgresHost string, postgresPort string, postgresDb string, postgresUsername string, postgresPassword string, postgresSSLMode string,
 	steampipeHost string, steampipePort string, steampipeDb string, steampipeUsername string, steampipePassword string,
 	schedulerBaseUrl string, onboardBaseUrl string, complianceBaseUrl string, metadataBaseUrl string,
+	queryResultCacheDir string,
 	logger *zap.Logger,
 ) (h *HttpHandler, err error) {
 	h = &HttpHandler{}
@@ -109,9 +116,16 @@ func InitializeHttpHandler(
 
 	h.logger = logger
 
+	h.queryResults, err = newQueryResultCache(queryResultCacheDir, h.db, logger)
+	if err != nil {
+		return nil, err
+	}
+	utils.EnsureRunGoroutine(h.queryResults.RunEviction)
+
 	h.awsPlg = awsSteampipe.Plugin()
 	h.azurePlg = azureSteampipe.Plugin()
 	h.azureADPlg = azureSteampipe.ADPlugin()
+	h.querySchema = queryvalidator.NewPluginSchema(ctx)
 
 	return h, nil
 }
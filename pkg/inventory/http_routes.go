@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/kaytu-io/kaytu-util/pkg/api"
 	"github.com/kaytu-io/kaytu-util/pkg/httpclient"
 	"github.com/kaytu-io/kaytu-util/pkg/httpserver"
 	queryrunner "github.com/kaytu-io/open-governance/pkg/inventory/query-runner"
+	queryvalidator "github.com/kaytu-io/open-governance/pkg/inventory/query-validator"
 	"github.com/kaytu-io/open-governance/pkg/inventory/rego_runner"
 	onboardApi "github.com/kaytu-io/open-governance/pkg/onboard/api"
 	"github.com/kaytu-io/open-governance/pkg/types"
@@ -37,6 +39,7 @@ import (
 	"github.com/kaytu-io/open-governance/pkg/utils"
 	"github.com/labstack/echo/v4"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/pmezard/go-difflib/difflib"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -46,9 +49,11 @@ import (
 )
 
 const (
-	EsFetchPageSize = 10000
-	MaxConns        = 100
-	KafkaPageSize   = 5000
+	EsFetchPageSize            = 10000
+	MaxConns                   = 100
+	KafkaPageSize              = 5000
+	DefaultQueryResultPageSize = 100
+	MaxQueryResultPageSize     = 10000
 )
 
 const (
@@ -73,6 +78,8 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 
 	resourcesV2 := v2.Group("/resources")
 	resourcesV2.GET("/count", httpserver.AuthorizeHandler(h.CountResources, api.ViewerRole))
+	resourcesV2.GET("/history", httpserver.AuthorizeHandler(h.GetResourceHistory, api.ViewerRole))
+	resourcesV2.GET("/changes", httpserver.AuthorizeHandler(h.ListResourceChanges, api.ViewerRole))
 
 	analyticsV2 := v2.Group("/analytics")
 	analyticsV2.GET("/count", httpserver.AuthorizeHandler(h.CountAnalytics, api.ViewerRole))
@@ -112,9 +119,22 @@ func (h *HttpHandler) Register(e *echo.Echo) {
 	v3.POST("/queries", httpserver.AuthorizeHandler(h.ListQueriesV2, api.ViewerRole))
 	v3.GET("/queries/filters", httpserver.AuthorizeHandler(h.ListQueriesFilters, api.ViewerRole))
 	v3.GET("/query/:query_id", httpserver.AuthorizeHandler(h.GetQuery, api.ViewerRole))
+	v3.GET("/saved-queries", httpserver.AuthorizeHandler(h.ListSavedQueries, api.ViewerRole))
+	v3.POST("/saved-queries", httpserver.AuthorizeHandler(h.CreateSavedQuery, api.ViewerRole))
+	v3.GET("/saved-queries/:query_id", httpserver.AuthorizeHandler(h.GetSavedQuery, api.ViewerRole))
+	v3.PUT("/saved-queries/:query_id", httpserver.AuthorizeHandler(h.UpdateSavedQuery, api.ViewerRole))
+	v3.DELETE("/saved-queries/:query_id", httpserver.AuthorizeHandler(h.DeleteSavedQuery, api.ViewerRole))
+	v3.GET("/saved-queries/:query_id/versions", httpserver.AuthorizeHandler(h.ListSavedQueryVersions, api.ViewerRole))
+	v3.GET("/saved-queries/:query_id/diff", httpserver.AuthorizeHandler(h.GetSavedQueryVersionDiff, api.ViewerRole))
 	v3.GET("/queries/tags", httpserver.AuthorizeHandler(h.ListQueriesTags, api.ViewerRole))
 	v3.POST("/query/run", httpserver.AuthorizeHandler(h.RunQueryByID, api.ViewerRole))
 	v3.GET("/query/async/run/:run_id/result", httpserver.AuthorizeHandler(h.GetAsyncQueryRunResult, api.ViewerRole))
+	v3.POST("/query/async/run/diff", httpserver.AuthorizeHandler(h.DiffQueryRuns, api.ViewerRole))
+	v3.POST("/query/validate", httpserver.AuthorizeHandler(h.ValidateQuery, api.ViewerRole))
+	v3.POST("/query/results", httpserver.AuthorizeHandler(h.CreateQueryResult, api.ViewerRole))
+	v3.GET("/query/results/:run_id", httpserver.AuthorizeHandler(h.GetQueryResultPage, api.ViewerRole))
+	v3.DELETE("/query/results/:run_id", httpserver.AuthorizeHandler(h.DeleteQueryResult, api.ViewerRole))
+	v3.GET("/query/results/:run_id/download", httpserver.AuthorizeHandler(h.DownloadQueryResult, api.ViewerRole))
 	v3.GET("/resources/categories", httpserver.AuthorizeHandler(h.GetResourceCategories, api.ViewerRole))
 	v3.GET("/queries/categories", httpserver.AuthorizeHandler(h.GetQueriesResourceCategories, api.ViewerRole))
 	v3.GET("/tables/categories", httpserver.AuthorizeHandler(h.GetTablesResourceCategories, api.ViewerRole))
@@ -2196,6 +2216,436 @@ func (h *HttpHandler) GetQuery(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, result)
 }
 
+// ListSavedQueries godoc
+//
+//	@Summary		List saved queries
+//	@Description	Retrieving saved queries owned by the user or shared with them
+//	@Security		BearerToken
+//	@Tags			saved_query
+//	@Produce		json
+//	@Param			titleFilter	query		string	false	"Filter by title"
+//	@Param			visibility	query		string	false	"Filter by visibility"	Enums(private,team,workspace)
+//	@Param			owned		query		bool	false	"Only list queries owned by the user"
+//	@Success		200			{object}	inventoryApi.ListSavedQueriesResponse
+//	@Router			/inventory/api/v3/saved-queries [get]
+func (h *HttpHandler) ListSavedQueries(ctx echo.Context) error {
+	var search *string
+	if titleFilter := ctx.QueryParam("titleFilter"); titleFilter != "" {
+		search = &titleFilter
+	}
+	var visibility *inventoryApi.SavedQueryVisibility
+	if v := inventoryApi.SavedQueryVisibility(ctx.QueryParam("visibility")); v != "" {
+		if !v.IsValid() {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid visibility")
+		}
+		visibility = &v
+	}
+	owned := false
+	if ownedStr := ctx.QueryParam("owned"); ownedStr != "" {
+		var err error
+		owned, err = strconv.ParseBool(ownedStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid owned")
+		}
+	}
+
+	// trace :
+	_, span := tracer.Start(ctx.Request().Context(), "new_ListSavedQueries", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_ListSavedQueries")
+
+	queries, err := h.db.ListSavedQueriesVisibleTo(requestUserID(ctx), search, visibility, owned)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.logger.Error("failed to list saved queries", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list saved queries")
+	}
+	span.End()
+
+	items := make([]inventoryApi.SavedQuery, 0, len(queries))
+	for _, query := range queries {
+		items = append(items, query.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, inventoryApi.ListSavedQueriesResponse{
+		Items:      items,
+		TotalCount: len(items),
+	})
+}
+
+// CreateSavedQuery godoc
+//
+//	@Summary		Create saved query
+//	@Description	Saving a query owned by the user, private unless another visibility is given
+//	@Security		BearerToken
+//	@Tags			saved_query
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.CreateSavedQueryRequest	true	"Saved query"
+//	@Success		201		{object}	inventoryApi.SavedQuery
+//	@Router			/inventory/api/v3/saved-queries [post]
+func (h *HttpHandler) CreateSavedQuery(ctx echo.Context) error {
+	var req inventoryApi.CreateSavedQueryRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	ownerID := requestUserID(ctx)
+	if ownerID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "saved queries can only be created on behalf of a user")
+	}
+	if req.Visibility == "" {
+		req.Visibility = inventoryApi.SavedQueryVisibilityPrivate
+	}
+	if !req.Visibility.IsValid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid visibility")
+	}
+	if req.Engine == "" {
+		req.Engine = inventoryApi.QueryEngine_OdysseusSQL
+	}
+
+	query := SavedQuery{
+		ID:             uuid.New().String(),
+		Title:          req.Title,
+		Description:    req.Description,
+		OwnerID:        ownerID,
+		Visibility:     req.Visibility,
+		SharedWith:     req.SharedWith,
+		Connectors:     req.Connectors,
+		QueryToExecute: req.QueryToExecute,
+		Engine:         req.Engine,
+		Tags:           savedQueryTags(req.Tags),
+		Parameters:     savedQueryParameters(req.Parameters),
+	}
+
+	// trace :
+	_, span := tracer.Start(ctx.Request().Context(), "new_CreateSavedQuery", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_CreateSavedQuery")
+
+	if err := h.db.CreateSavedQuery(&query); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.logger.Error("failed to create saved query", zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to create saved query")
+	}
+	span.End()
+
+	return ctx.JSON(http.StatusCreated, query.ToApi())
+}
+
+// GetSavedQuery godoc
+//
+//	@Summary		Get saved query
+//	@Description	Retrieving a saved query visible to the user
+//	@Security		BearerToken
+//	@Tags			saved_query
+//	@Produce		json
+//	@Param			query_id	path		string	true	"Saved query ID"
+//	@Success		200			{object}	inventoryApi.SavedQuery
+//	@Router			/inventory/api/v3/saved-queries/{query_id} [get]
+func (h *HttpHandler) GetSavedQuery(ctx echo.Context) error {
+	query, err := h.getVisibleSavedQuery(ctx)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, query.ToApi())
+}
+
+// UpdateSavedQuery godoc
+//
+//	@Summary		Update saved query
+//	@Description	Updating a saved query as a new version, only the owner or an admin can update it
+//	@Security		BearerToken
+//	@Tags			saved_query
+//	@Accept			json
+//	@Produce		json
+//	@Param			query_id	path		string							true	"Saved query ID"
+//	@Param			request		body		inventoryApi.UpdateSavedQueryRequest	true	"Changed fields"
+//	@Success		200			{object}	inventoryApi.SavedQuery
+//	@Router			/inventory/api/v3/saved-queries/{query_id} [put]
+func (h *HttpHandler) UpdateSavedQuery(ctx echo.Context) error {
+	var req inventoryApi.UpdateSavedQueryRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	query, err := h.getEditableSavedQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	if req.Title != nil {
+		if *req.Title == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "title cannot be empty")
+		}
+		query.Title = *req.Title
+	}
+	if req.Description != nil {
+		query.Description = *req.Description
+	}
+	if req.Visibility != nil {
+		if !req.Visibility.IsValid() {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid visibility")
+		}
+		query.Visibility = *req.Visibility
+	}
+	if req.SharedWith != nil {
+		query.SharedWith = req.SharedWith
+	}
+	if req.Connectors != nil {
+		query.Connectors = req.Connectors
+	}
+	if req.QueryToExecute != nil {
+		if *req.QueryToExecute == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "query cannot be empty")
+		}
+		query.QueryToExecute = *req.QueryToExecute
+	}
+	if req.Engine != nil {
+		query.Engine = *req.Engine
+	}
+	if req.Parameters != nil {
+		query.Parameters = savedQueryParameters(req.Parameters)
+	}
+	if req.Tags != nil {
+		query.Tags = savedQueryTags(req.Tags)
+	}
+	// associations are replaced as a whole, point them to the query again
+	for i := range query.Parameters {
+		query.Parameters[i].SavedQueryID = query.ID
+	}
+	for i := range query.Tags {
+		query.Tags[i].SavedQueryID = query.ID
+	}
+
+	// trace :
+	_, span := tracer.Start(ctx.Request().Context(), "new_UpdateSavedQuery", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_UpdateSavedQuery")
+
+	if err := h.db.UpdateSavedQuery(query, requestUserID(ctx)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		h.logger.Error("failed to update saved query", zap.String("id", query.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to update saved query")
+	}
+	span.End()
+
+	return ctx.JSON(http.StatusOK, query.ToApi())
+}
+
+// DeleteSavedQuery godoc
+//
+//	@Summary		Delete saved query
+//	@Description	Deleting a saved query with its versions, only the owner or an admin can delete it
+//	@Security		BearerToken
+//	@Tags			saved_query
+//	@Param			query_id	path	string	true	"Saved query ID"
+//	@Success		200
+//	@Router			/inventory/api/v3/saved-queries/{query_id} [delete]
+func (h *HttpHandler) DeleteSavedQuery(ctx echo.Context) error {
+	query, err := h.getEditableSavedQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.db.DeleteSavedQuery(query.ID); err != nil {
+		h.logger.Error("failed to delete saved query", zap.String("id", query.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete saved query")
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// ListSavedQueryVersions godoc
+//
+//	@Summary		List saved query versions
+//	@Description	Retrieving the version history of a saved query, newest first
+//	@Security		BearerToken
+//	@Tags			saved_query
+//	@Produce		json
+//	@Param			query_id	path		string	true	"Saved query ID"
+//	@Success		200			{object}	[]inventoryApi.SavedQueryVersion
+//	@Router			/inventory/api/v3/saved-queries/{query_id}/versions [get]
+func (h *HttpHandler) ListSavedQueryVersions(ctx echo.Context) error {
+	query, err := h.getVisibleSavedQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	versions, err := h.db.ListSavedQueryVersions(query.ID)
+	if err != nil {
+		h.logger.Error("failed to list saved query versions", zap.String("id", query.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to list saved query versions")
+	}
+
+	res := make([]inventoryApi.SavedQueryVersion, 0, len(versions))
+	for _, version := range versions {
+		res = append(res, version.ToApi())
+	}
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// GetSavedQueryVersionDiff godoc
+//
+//	@Summary		Diff saved query versions
+//	@Description	Comparing two versions of a saved query. By default the latest version is compared to the one before it
+//	@Security		BearerToken
+//	@Tags			saved_query
+//	@Produce		json
+//	@Param			query_id	path		string	true	"Saved query ID"
+//	@Param			from		query		int		false	"From version"
+//	@Param			to			query		int		false	"To version"
+//	@Success		200			{object}	inventoryApi.SavedQueryVersionDiff
+//	@Router			/inventory/api/v3/saved-queries/{query_id}/diff [get]
+func (h *HttpHandler) GetSavedQueryVersionDiff(ctx echo.Context) error {
+	query, err := h.getVisibleSavedQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	toVersion := query.Version
+	if toStr := ctx.QueryParam("to"); toStr != "" {
+		toVersion, err = strconv.Atoi(toStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid to")
+		}
+	}
+	fromVersion := toVersion - 1
+	if fromStr := ctx.QueryParam("from"); fromStr != "" {
+		fromVersion, err = strconv.Atoi(fromStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid from")
+		}
+	}
+
+	from, err := h.db.GetSavedQueryVersion(query.ID, fromVersion)
+	if err != nil {
+		h.logger.Error("failed to get saved query version", zap.String("id", query.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved query version")
+	}
+	to, err := h.db.GetSavedQueryVersion(query.ID, toVersion)
+	if err != nil {
+		h.logger.Error("failed to get saved query version", zap.String("id", query.ID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved query version")
+	}
+	if from == nil || to == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "version not found")
+	}
+
+	queryDiff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.QueryToExecute),
+		B:        difflib.SplitLines(to.QueryToExecute),
+		FromFile: fmt.Sprintf("version %d", from.Version),
+		ToFile:   fmt.Sprintf("version %d", to.Version),
+		Context:  3,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	changedFields := make([]string, 0)
+	if from.Title != to.Title {
+		changedFields = append(changedFields, "title")
+	}
+	if from.Description != to.Description {
+		changedFields = append(changedFields, "description")
+	}
+	if from.QueryToExecute != to.QueryToExecute {
+		changedFields = append(changedFields, "query_to_execute")
+	}
+	if from.Engine != to.Engine {
+		changedFields = append(changedFields, "engine")
+	}
+	if string(from.Parameters.Bytes) != string(to.Parameters.Bytes) {
+		changedFields = append(changedFields, "parameters")
+	}
+
+	return ctx.JSON(http.StatusOK, inventoryApi.SavedQueryVersionDiff{
+		FromVersion:   from.Version,
+		ToVersion:     to.Version,
+		ChangedFields: changedFields,
+		QueryDiff:     queryDiff,
+	})
+}
+
+// getSavedQuery returns the saved query of the query_id param whoever the user is
+func (h *HttpHandler) getSavedQuery(ctx echo.Context) (*SavedQuery, error) {
+	query, err := h.db.GetSavedQuery(ctx.Param("query_id"))
+	if err != nil {
+		h.logger.Error("failed to get saved query", zap.String("id", ctx.Param("query_id")), zap.Error(err))
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved query")
+	}
+	if query == nil {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "saved query not found")
+	}
+	return query, nil
+}
+
+// getVisibleSavedQuery returns the saved query of the query_id param if the user can see it
+func (h *HttpHandler) getVisibleSavedQuery(ctx echo.Context) (*SavedQuery, error) {
+	query, err := h.getSavedQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !query.IsVisibleTo(requestUserID(ctx)) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "saved query not found")
+	}
+	return query, nil
+}
+
+// getEditableSavedQuery returns the saved query of the query_id param if the user owns it or is an admin
+func (h *HttpHandler) getEditableSavedQuery(ctx echo.Context) (*SavedQuery, error) {
+	query, err := h.getSavedQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEditableSavedQuery(query, requestUserID(ctx), httpserver.GetUserRole(ctx)); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// checkEditableSavedQuery checks if the user can change the query, admins and internal calls can change every query
+// including the private ones of other users
+func checkEditableSavedQuery(query *SavedQuery, userID string, role api.Role) error {
+	if role == api.AdminRole || role == api.InternalRole {
+		return nil
+	}
+	if !query.IsVisibleTo(userID) {
+		return echo.NewHTTPError(http.StatusNotFound, "saved query not found")
+	}
+	if query.OwnerID != userID {
+		return echo.NewHTTPError(http.StatusForbidden, "only the owner can change the saved query")
+	}
+	return nil
+}
+
+// requestUserID returns the calling user, empty for internal calls which are not made on behalf of a user
+func requestUserID(ctx echo.Context) string {
+	return ctx.Request().Header.Get(httpserver.XKaytuUserIDHeader)
+}
+
+func savedQueryTags(tags map[string][]string) []SavedQueryTag {
+	res := make([]SavedQueryTag, 0, len(tags))
+	for key, value := range tags {
+		res = append(res, SavedQueryTag{
+			Tag: model.Tag{
+				Key:   key,
+				Value: value,
+			},
+		})
+	}
+	return res
+}
+
+func savedQueryParameters(parameters []inventoryApi.QueryParameter) []SavedQueryParameter {
+	res := make([]SavedQueryParameter, 0, len(parameters))
+	for _, p := range parameters {
+		res = append(res, SavedQueryParameter{
+			Key:      p.Key,
+			Required: p.Required,
+		})
+	}
+	return res
+}
+
 func filterTagsByRegex(regexPattern *string, tags map[string][]string) map[string][]string {
 	if regexPattern == nil {
 		return tags
@@ -2286,14 +2736,14 @@ func (h *HttpHandler) RunQuery(ctx echo.Context) error {
 
 	var resp *inventoryApi.RunQueryResponse
 	if req.Engine == nil || *req.Engine == inventoryApi.QueryEngine_OdysseusSQL {
-		resp, err = h.RunSQLNamedQuery(outputS, *req.Query, queryOutput.String(), &req)
+		resp, err = h.RunSQLNamedQuery(outputS, requestUserID(ctx), *req.Query, queryOutput.String(), &req)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
 			return err
 		}
 	} else if *req.Engine == inventoryApi.QueryEngine_OdysseusRego {
-		resp, err = h.RunRegoNamedQuery(outputS, *req.Query, queryOutput.String(), &req)
+		resp, err = h.RunRegoNamedQuery(outputS, requestUserID(ctx), *req.Query, queryOutput.String(), &req)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
@@ -2325,7 +2775,7 @@ func (h *HttpHandler) GetRecentRanQueries(ctx echo.Context) error {
 	_, span := tracer.Start(ctx.Request().Context(), "new_GetQueryHistory", trace.WithSpanKind(trace.SpanKindServer))
 	span.SetName("new_GetQueryHistory")
 
-	namedQueryHistories, err := h.db.GetQueryHistory()
+	namedQueryHistories, err := h.db.GetQueryHistory(requestUserID(ctx))
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -2354,18 +2804,177 @@ func (h *HttpHandler) CountResources(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, totalCount)
 }
 
-func (h *HttpHandler) RunSQLNamedQuery(ctx context.Context, title, query string, req *inventoryApi.RunQueryRequest) (*inventoryApi.RunQueryResponse, error) {
+// GetResourceHistory godoc
+//
+//	@Summary		Get resource change history
+//	@Description	Retrieving the changes recorded by discovery jobs for a resource, newest first.
+//	@Security		BearerToken
+//	@Tags			resource
+//	@Produce		json
+//	@Param			resourceId		query		string	true	"Kaytu resource ID"
+//	@Param			resourceType	query		string	false	"Resource type"
+//	@Param			limit			query		int		false	"Maximum number of changes, default 100"
+//	@Success		200				{object}	inventoryApi.GetResourceHistoryResponse
+//	@Router			/inventory/api/v2/resources/history [get]
+func (h *HttpHandler) GetResourceHistory(ctx echo.Context) error {
+	resourceID := ctx.QueryParam("resourceId")
+	if resourceID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "resourceId is required")
+	}
+	limit, err := resourceChangesLimit(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := es.GetResourceChanges(ctx.Request().Context(), h.client, resourceID, ctx.QueryParam("resourceType"), limit)
+	if err != nil {
+		h.logger.Error("failed to get resource changes", zap.Error(err), zap.String("resourceId", resourceID))
+		return err
+	}
+
+	response := inventoryApi.GetResourceHistoryResponse{
+		Changes: make([]inventoryApi.ResourceChange, 0, len(changes)),
+	}
+	for _, change := range changes {
+		if httpserver.CheckAccessToConnectionID(ctx, change.ConnectionID) != nil {
+			continue
+		}
+		response.Changes = append(response.Changes, resourceChangeToApi(change))
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ListResourceChanges godoc
+//
+//	@Summary		List recent resource changes
+//	@Description	Retrieving the resources created, modified or deleted in the workspace during the last hours, newest first.
+//	@Security		BearerToken
+//	@Tags			resource
+//	@Produce		json
+//	@Param			hours			query		int			false	"Number of hours to look back, default 24"
+//	@Param			connectionId	query		[]string	false	"Connection IDs to filter by"
+//	@Param			connectionGroup	query		[]string	false	"Connection groups to filter by "
+//	@Param			resourceType	query		[]string	false	"Resource types to filter by"
+//	@Param			changeType		query		[]string	false	"Change types to filter by"	Enums(create, modify, delete)
+//	@Param			limit			query		int			false	"Maximum number of changes, default 100"
+//	@Success		200				{object}	inventoryApi.ListResourceChangesResponse
+//	@Router			/inventory/api/v2/resources/changes [get]
+func (h *HttpHandler) ListResourceChanges(ctx echo.Context) error {
+	hours := 24
+	if hoursStr := ctx.QueryParam("hours"); hoursStr != "" {
+		hoursVal, err := strconv.Atoi(hoursStr)
+		if err != nil || hoursVal <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "hours must be a positive number")
+		}
+		hours = hoursVal
+	}
+	limit, err := resourceChangesLimit(ctx)
+	if err != nil {
+		return err
+	}
+	connectionIDs, err := h.getConnectionIdFilterFromParams(ctx)
+	if err != nil {
+		return err
+	}
+	changeTypes := httpserver.QueryArrayParam(ctx, "changeType")
+	for _, changeType := range changeTypes {
+		switch types.ResourceChangeType(changeType) {
+		case types.ResourceChangeTypeCreate, types.ResourceChangeTypeModify, types.ResourceChangeTypeDelete:
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid changeType %s", changeType))
+		}
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	changes, totalCount, err := es.ListResourceChanges(ctx.Request().Context(), h.client, since, connectionIDs,
+		httpserver.QueryArrayParam(ctx, "resourceType"), changeTypes, limit)
+	if err != nil {
+		h.logger.Error("failed to list resource changes", zap.Error(err))
+		return err
+	}
+
+	response := inventoryApi.ListResourceChangesResponse{
+		TotalCount: totalCount,
+		Changes:    make([]inventoryApi.ResourceChange, 0, len(changes)),
+	}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, resourceChangeToApi(change))
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func resourceChangesLimit(ctx echo.Context) (int, error) {
+	limitStr := ctx.QueryParam("limit")
+	if limitStr == "" {
+		return 100, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > 10000 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 10000")
+	}
+	return limit, nil
+}
+
+func resourceChangeToApi(change types.ResourceChange) inventoryApi.ResourceChange {
+	apiChange := inventoryApi.ResourceChange{
+		KaytuResourceID: change.KaytuResourceID,
+		ResourceType:    change.ResourceType,
+		ResourceName:    change.ResourceName,
+		ConnectionID:    change.ConnectionID,
+		Connector:       change.Connector,
+		DiscoveryJobID:  change.DiscoveryJobID,
+		ChangeType:      string(change.ChangeType),
+		ChangedAt:       time.UnixMilli(change.ChangedAt),
+	}
+	for _, diff := range change.Diff {
+		apiChange.Diff = append(apiChange.Diff, inventoryApi.ResourceChangeDiff{
+			Operation: string(diff.Operation),
+			Path:      diff.Path,
+			OldValue:  diff.OldValue,
+			NewValue:  diff.NewValue,
+		})
+	}
+	return apiChange
+}
+
+// sortedQuery wraps the query to order its result by all of the sort items, in the given priority
+func sortedQuery(query string, sorts []inventoryApi.NamedQuerySortItem) (string, error) {
+	orderBy := make([]string, 0, len(sorts))
+	for _, item := range sorts {
+		if item.Field == "" {
+			return "", errors.New("sort field is required")
+		}
+		direction := "ASC"
+		switch item.Direction {
+		case "", inventoryApi.DirectionAscending:
+		case inventoryApi.DirectionDescending:
+			direction = "DESC"
+		default:
+			return "", fmt.Errorf("invalid sort direction: %s", item.Direction)
+		}
+		orderBy = append(orderBy, fmt.Sprintf(`"%s" %s`, strings.ReplaceAll(item.Field, `"`, `""`), direction))
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	// the query is kept on its own lines so trailing line comments do not swallow the wrapper
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) AS sorted_query ORDER BY %s", query, strings.Join(orderBy, ", ")), nil
+}
+
+func (h *HttpHandler) RunSQLNamedQuery(ctx context.Context, userID, title, query string, req *inventoryApi.RunQueryRequest) (*inventoryApi.RunQueryResponse, error) {
 	var err error
 	lastIdx := (req.Page.No - 1) * req.Page.Size
 
 	direction := inventoryApi.DirectionType("")
 	orderBy := ""
-	if req.Sorts != nil && len(req.Sorts) > 0 {
+	executedQuery := query
+	if len(req.Sorts) == 1 {
 		direction = req.Sorts[0].Direction
 		orderBy = req.Sorts[0].Field
-	}
-	if len(req.Sorts) > 1 {
-		return nil, errors.New("multiple sort items not supported")
+	} else if len(req.Sorts) > 1 {
+		executedQuery, err = sortedQuery(query, req.Sorts)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 	}
 
 	for i := 0; i < 10; i++ {
@@ -2380,8 +2989,8 @@ func (h *HttpHandler) RunSQLNamedQuery(ctx context.Context, title, query string,
 		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	h.logger.Info("executing named query", zap.String("query", query))
-	res, err := h.steampipeConn.Query(ctx, query, &lastIdx, &req.Page.Size, orderBy, steampipe.DirectionType(direction))
+	h.logger.Info("executing named query", zap.String("query", executedQuery))
+	res, err := h.steampipeConn.Query(ctx, executedQuery, &lastIdx, &req.Page.Size, orderBy, steampipe.DirectionType(direction))
 	if err != nil {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
@@ -2427,17 +3036,19 @@ func (h *HttpHandler) RunSQLNamedQuery(ctx context.Context, title, query string,
 		}
 	}
 
-	_, span := tracer.Start(ctx, "new_UpdateQueryHistory", trace.WithSpanKind(trace.SpanKindServer))
-	span.SetName("new_UpdateQueryHistory")
+	if userID != "" {
+		_, span := tracer.Start(ctx, "new_UpdateQueryHistory", trace.WithSpanKind(trace.SpanKindServer))
+		span.SetName("new_UpdateQueryHistory")
 
-	err = h.db.UpdateQueryHistory(query)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		h.logger.Error("failed to update query history", zap.Error(err))
-		return nil, err
+		err = h.db.UpdateQueryHistory(userID, query)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			h.logger.Error("failed to update query history", zap.Error(err))
+			return nil, err
+		}
+		span.End()
 	}
-	span.End()
 
 	resp := inventoryApi.RunQueryResponse{
 		Title:   title,
@@ -2453,7 +3064,7 @@ type resourceFieldItem struct {
 	value     interface{}
 }
 
-func (h *HttpHandler) RunRegoNamedQuery(ctx context.Context, title, query string, req *inventoryApi.RunQueryRequest) (*inventoryApi.RunQueryResponse, error) {
+func (h *HttpHandler) RunRegoNamedQuery(ctx context.Context, userID, title, query string, req *inventoryApi.RunQueryRequest) (*inventoryApi.RunQueryResponse, error) {
 	var err error
 	lastIdx := (req.Page.No - 1) * req.Page.Size
 
@@ -2575,17 +3186,19 @@ func (h *HttpHandler) RunRegoNamedQuery(ctx context.Context, title, query string
 		}
 	}
 
-	_, span := tracer.Start(ctx, "new_UpdateQueryHistory", trace.WithSpanKind(trace.SpanKindServer))
-	span.SetName("new_UpdateQueryHistory")
+	if userID != "" {
+		_, span := tracer.Start(ctx, "new_UpdateQueryHistory", trace.WithSpanKind(trace.SpanKindServer))
+		span.SetName("new_UpdateQueryHistory")
 
-	err = h.db.UpdateQueryHistory(query)
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-		h.logger.Error("failed to update query history", zap.Error(err))
-		return nil, err
+		err = h.db.UpdateQueryHistory(userID, query)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			h.logger.Error("failed to update query history", zap.Error(err))
+			return nil, err
+		}
+		span.End()
 	}
-	span.End()
 
 	resp := inventoryApi.RunQueryResponse{
 		Title:   title,
@@ -3222,6 +3835,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 	span.SetName("new_RunNamedQuery")
 
 	var query, engineStr string
+	queryParamMap := req.QueryParams
 	if strings.ToLower(req.Type) == "namedquery" || strings.ToLower(req.Type) == "named_query" {
 		namedQuery, err := h.db.GetQuery(req.ID)
 		if err != nil || namedQuery == nil {
@@ -3241,8 +3855,23 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 		}
 		query = control.Query.QueryToExecute
 		engineStr = control.Query.Engine
+	} else if strings.ToLower(req.Type) == "savedquery" || strings.ToLower(req.Type) == "saved_query" {
+		savedQuery, err := h.db.GetSavedQuery(req.ID)
+		if err != nil {
+			h.logger.Error("failed to get saved query", zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to get saved query")
+		}
+		if savedQuery == nil || !savedQuery.IsVisibleTo(requestUserID(ctx)) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Could not find saved query")
+		}
+		query = savedQuery.QueryToExecute
+		engineStr = savedQuery.Engine
+		queryParamMap, err = savedQuery.queryParams(req.QueryParams)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 	} else {
-		return echo.NewHTTPError(http.StatusBadRequest, "Runnable Type is not valid. Options: named_query, control")
+		return echo.NewHTTPError(http.StatusBadRequest, "Runnable Type is not valid. Options: named_query, control, saved_query")
 	}
 	var engine inventoryApi.QueryEngine
 	if engineStr == "" {
@@ -3251,8 +3880,6 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 		engine = inventoryApi.QueryEngine(engineStr)
 	}
 
-	queryParamMap := req.QueryParams
-
 	queryTemplate, err := template.New("query").Parse(query)
 	if err != nil {
 		return err
@@ -3264,7 +3891,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 
 	var resp *inventoryApi.RunQueryResponse
 	if engine == inventoryApi.QueryEngine_OdysseusSQL {
-		resp, err = h.RunSQLNamedQuery(newCtx, query, queryOutput.String(), &inventoryApi.RunQueryRequest{
+		resp, err = h.RunSQLNamedQuery(newCtx, requestUserID(ctx), query, queryOutput.String(), &inventoryApi.RunQueryRequest{
 			Page:   req.Page,
 			Query:  &query,
 			Engine: &engine,
@@ -3276,7 +3903,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 			return err
 		}
 	} else if engine == inventoryApi.QueryEngine_OdysseusRego {
-		resp, err = h.RunRegoNamedQuery(newCtx, query, queryOutput.String(), &inventoryApi.RunQueryRequest{
+		resp, err = h.RunRegoNamedQuery(newCtx, requestUserID(ctx), query, queryOutput.String(), &inventoryApi.RunQueryRequest{
 			Page:   req.Page,
 			Query:  &query,
 			Engine: &engine,
@@ -3288,7 +3915,7 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 			return err
 		}
 	} else {
-		resp, err = h.RunSQLNamedQuery(newCtx, query, queryOutput.String(), &inventoryApi.RunQueryRequest{
+		resp, err = h.RunSQLNamedQuery(newCtx, requestUserID(ctx), query, queryOutput.String(), &inventoryApi.RunQueryRequest{
 			Page:   req.Page,
 			Query:  &query,
 			Engine: &engine,
@@ -3307,6 +3934,10 @@ func (h *HttpHandler) RunQueryByID(ctx echo.Context) error {
 	span.End()
 	select {
 	case <-newCtx.Done():
+		if strings.ToLower(req.Type) == "savedquery" || strings.ToLower(req.Type) == "saved_query" {
+			// async query runs only know about named queries
+			return echo.NewHTTPError(http.StatusRequestTimeout, "Query execution timed out")
+		}
 		job, err := h.schedulerClient.RunQuery(&httpclient.Context{UserRole: api.InternalRole}, req.ID)
 		if err != nil {
 			h.logger.Error("failed to run async query run", zap.Error(err))
@@ -3405,6 +4036,464 @@ func (h *HttpHandler) GetAsyncQueryRunResult(ctx echo.Context) error {
 	return ctx.JSON(200, resp)
 }
 
+// DiffQueryRuns godoc
+//
+//	@Summary		Diff two query runs
+//	@Description	Compares the results of two async runs of the same query, both run by the user. Rows are matched by the key columns and the changed cells of matched rows are returned.
+//	@Security		BearerToken
+//	@Tags			named_query
+//	@Accepts		json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.DiffQueryRunsRequest	true	"Request Body"
+//	@Success		200		{object}	inventoryApi.QueryRunDiff
+//	@Router			/inventory/api/v3/query/async/run/diff [post]
+func (h *HttpHandler) DiffQueryRuns(ctx echo.Context) error {
+	var req inventoryApi.DiffQueryRunsRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// tracer :
+	newCtx, span := tracer.Start(ctx.Request().Context(), "new_DiffQueryRuns", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_DiffQueryRuns")
+	defer span.End()
+
+	base, err := es.GetAsyncQueryRunResult(newCtx, h.logger, h.client, req.BaseRunID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to find async query run result")
+	}
+	target, err := es.GetAsyncQueryRunResult(newCtx, h.logger, h.client, req.TargetRunID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to find async query run result")
+	}
+	if err := checkDiffableQueryRuns(requestUserID(ctx), base, target); err != nil {
+		return err
+	}
+
+	diff, err := queryrunner.DiffResults(base.ColumnNames, base.Result, target.ColumnNames, target.Result, req.KeyColumns)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	diff.BaseRunID = base.RunId
+	diff.TargetRunID = target.RunId
+	diff.QueryID = target.QueryID
+
+	span.AddEvent("information", trace.WithAttributes(
+		attribute.Int("added", diff.AddedCount),
+		attribute.Int("removed", diff.RemovedCount),
+		attribute.Int("changed", diff.ChangedCount),
+	))
+	return ctx.JSON(http.StatusOK, diff)
+}
+
+// checkDiffableQueryRuns makes sure both runs were run by the user and are runs of the same query,
+// runs of other users are reported as not found
+func checkDiffableQueryRuns(userID string, base, target *es.GetAsyncQueryRunResultSource) error {
+	if base.RunId == "" || base.CreatedBy != userID {
+		return echo.NewHTTPError(http.StatusNotFound, "base run result not found")
+	}
+	if target.RunId == "" || target.CreatedBy != userID {
+		return echo.NewHTTPError(http.StatusNotFound, "target run result not found")
+	}
+	if base.QueryID != target.QueryID {
+		return echo.NewHTTPError(http.StatusBadRequest, "runs are not of the same query")
+	}
+	return nil
+}
+
+// ValidateQuery godoc
+//
+//	@Summary		Validate query
+//	@Description	Checks a query without running it. The template parameters are checked against the workspace query parameters and the tables and columns against the schemas of the steampipe plugins.
+//	@Security		BearerToken
+//	@Tags			named_query
+//	@Accepts		json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.ValidateQueryRequest	true	"Request Body"
+//	@Success		200		{object}	inventoryApi.ValidateQueryResponse
+//	@Router			/inventory/api/v3/query/validate [post]
+func (h *HttpHandler) ValidateQuery(ctx echo.Context) error {
+	var req inventoryApi.ValidateQueryRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// tracer :
+	newCtx, span := tracer.Start(ctx.Request().Context(), "new_ValidateQuery", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_ValidateQuery")
+	defer span.End()
+
+	queryParams, err := h.metadataClient.ListQueryParameters(&httpclient.Context{UserRole: api.InternalRole})
+	if err != nil {
+		return err
+	}
+	queryParamMap := make(map[string]string)
+	for _, qp := range queryParams.QueryParameters {
+		queryParamMap[qp.Key] = qp.Value
+	}
+	for k, v := range req.QueryParams {
+		queryParamMap[k] = v
+	}
+
+	var resp inventoryApi.ValidateQueryResponse
+	if req.Engine != nil && *req.Engine == inventoryApi.QueryEngine_OdysseusRego {
+		// rego queries are not sql, only the template parameters are checked
+		resp = queryvalidator.ValidateTemplate(req.Query, queryParamMap)
+	} else {
+		resp = h.querySchema.Validate(req.Query, queryParamMap, h.queryViews(newCtx))
+	}
+
+	span.AddEvent("information", trace.WithAttributes(
+		attribute.Bool("valid", resp.Valid),
+		attribute.Int("issues", len(resp.Issues)),
+	))
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// queryViews returns the names of the query views of the steampipe database, they can be used as tables in queries
+func (h *HttpHandler) queryViews(ctx context.Context) []string {
+	rows, err := h.steampipeConn.Conn().Query(ctx, "SELECT matviewname FROM pg_matviews")
+	if err != nil {
+		h.logger.Error("failed to list query views", zap.Error(err))
+		return nil
+	}
+	defer rows.Close()
+
+	var views []string
+	for rows.Next() {
+		var view string
+		if err := rows.Scan(&view); err != nil {
+			h.logger.Error("failed to read query view", zap.Error(err))
+			return views
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// CreateQueryResult godoc
+//
+//	@Summary		Run query into a cached result
+//	@Description	Runs the query once and keeps its whole result for an hour, results over the row limit or the remaining result quota of the user are truncated. Returns the first page and a cursor for the next one, the result can also be downloaded.
+//	@Security		BearerToken
+//	@Tags			named_query
+//	@Accepts		json
+//	@Produce		json
+//	@Param			request	body		inventoryApi.CreateQueryResultRequest	true	"Request Body"
+//	@Success		200		{object}	inventoryApi.QueryResultPage
+//	@Router			/inventory/api/v3/query/results [post]
+func (h *HttpHandler) CreateQueryResult(ctx echo.Context) error {
+	var req inventoryApi.CreateQueryResultRequest
+	if err := bindValidate(ctx, &req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	pageSize, err := queryResultPageSize(req.PageSize)
+	if err != nil {
+		return err
+	}
+	// tracer :
+	outputS, span := tracer.Start(ctx.Request().Context(), "new_CreateQueryResult", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetName("new_CreateQueryResult")
+	defer span.End()
+
+	queryParams, err := h.metadataClient.ListQueryParameters(&httpclient.Context{UserRole: api.InternalRole})
+	if err != nil {
+		return err
+	}
+	queryParamMap := make(map[string]string)
+	for _, qp := range queryParams.QueryParameters {
+		queryParamMap[qp.Key] = qp.Value
+	}
+
+	queryTemplate, err := template.New("query").Parse(req.Query)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	var queryOutput bytes.Buffer
+	if err := queryTemplate.Execute(&queryOutput, queryParamMap); err != nil {
+		return fmt.Errorf("failed to execute query template: %w", err)
+	}
+	query := queryOutput.String()
+	if len(req.Sorts) > 0 {
+		query, err = sortedQuery(query, req.Sorts)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	userID := requestUserID(ctx)
+	result, err := h.cacheQueryResult(outputS, userID, query)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if userID != "" {
+		if err := h.db.UpdateQueryHistory(userID, req.Query); err != nil {
+			h.logger.Error("failed to update query history", zap.Error(err))
+			return err
+		}
+	}
+
+	page, err := h.queryResultPage(result, 0, pageSize)
+	if err != nil {
+		return err
+	}
+	span.AddEvent("information", trace.WithAttributes(
+		attribute.String("run id", result.RunID),
+		attribute.Int("rows", result.RowCount),
+	))
+	return ctx.JSON(http.StatusOK, page)
+}
+
+// GetQueryResultPage godoc
+//
+//	@Summary		Get a page of a cached query result
+//	@Description	Reads the rows after the cursor from a cached query result without running the query again.
+//	@Security		BearerToken
+//	@Tags			named_query
+//	@Produce		json
+//	@Param			run_id	path		string	true	"Run ID of the cached result"
+//	@Param			cursor	query		string	false	"Cursor returned by the previous page, first page if empty"
+//	@Param			size	query		int		false	"Page size, defaults to 100"
+//	@Success		200		{object}	inventoryApi.QueryResultPage
+//	@Router			/inventory/api/v3/query/results/{run_id} [get]
+func (h *HttpHandler) GetQueryResultPage(ctx echo.Context) error {
+	result, err := h.getOwnedQueryResult(ctx)
+	if err != nil {
+		return err
+	}
+
+	from := 0
+	if cursor := ctx.QueryParam("cursor"); cursor != "" {
+		from, err = strconv.Atoi(cursor)
+		if err != nil || from < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor")
+		}
+	}
+	size := 0
+	if sizeStr := ctx.QueryParam("size"); sizeStr != "" {
+		size, err = strconv.Atoi(sizeStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid size")
+		}
+	}
+	size, err = queryResultPageSize(size)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.queryResultPage(result, from, size)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, page)
+}
+
+// DownloadQueryResult godoc
+//
+//	@Summary		Download a cached query result
+//	@Description	Streams the whole cached query result as CSV, JSON lines or Parquet.
+//	@Security		BearerToken
+//	@Tags			named_query
+//	@Produce		text/csv,application/x-ndjson,application/vnd.apache.parquet
+//	@Param			run_id	path	string	true	"Run ID of the cached result"
+//	@Param			format	query	string	false	"Download format, defaults to csv"	Enums(csv,jsonl,parquet)
+//	@Success		200
+//	@Router			/inventory/api/v3/query/results/{run_id}/download [get]
+func (h *HttpHandler) DownloadQueryResult(ctx echo.Context) error {
+	result, err := h.getOwnedQueryResult(ctx)
+	if err != nil {
+		return err
+	}
+
+	format := inventoryApi.QueryResultFormatCSV
+	if f := ctx.QueryParam("format"); f != "" {
+		format = inventoryApi.QueryResultFormat(strings.ToLower(f))
+	}
+	if !format.IsValid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid format")
+	}
+
+	resp := ctx.Response()
+	exporter, err := newQueryResultExporter(format, resp, result.Headers)
+	if err != nil {
+		return err
+	}
+	resp.Header().Set(echo.HeaderContentType, queryResultContentType(format))
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s.%s", result.RunID, format)))
+	resp.WriteHeader(http.StatusOK)
+
+	err = result.Each(exporter.WriteRow)
+	if err == nil {
+		err = exporter.Close()
+	}
+	if err != nil {
+		// headers are already sent, the client sees a cut response
+		h.logger.Error("failed to stream query result", zap.String("runID", result.RunID), zap.Error(err))
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
+// DeleteQueryResult godoc
+//
+//	@Summary		Delete a cached query result
+//	@Description	Removes a cached query result before it expires.
+//	@Security		BearerToken
+//	@Tags			named_query
+//	@Param			run_id	path	string	true	"Run ID of the cached result"
+//	@Success		200
+//	@Router			/inventory/api/v3/query/results/{run_id} [delete]
+func (h *HttpHandler) DeleteQueryResult(ctx echo.Context) error {
+	result, err := h.getOwnedQueryResult(ctx)
+	if err != nil {
+		return err
+	}
+	if err := h.queryResults.Delete(result.RunID); err != nil {
+		h.logger.Error("failed to delete query result", zap.String("runID", result.RunID), zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to delete query result")
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+// getOwnedQueryResult returns the cached result of the run_id param, results of other users are reported as not found
+func (h *HttpHandler) getOwnedQueryResult(ctx echo.Context) (*QueryResult, error) {
+	result, err := h.queryResults.Get(ctx.Param("run_id"))
+	if err != nil {
+		h.logger.Error("failed to get query result", zap.String("runID", ctx.Param("run_id")), zap.Error(err))
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to get query result")
+	}
+	if result == nil || result.OwnerID != requestUserID(ctx) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "query result not found")
+	}
+	return result, nil
+}
+
+func queryResultPageSize(size int) (int, error) {
+	if size == 0 {
+		return DefaultQueryResultPageSize, nil
+	}
+	if size < 0 || size > MaxQueryResultPageSize {
+		return 0, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("page size must be between 1 and %d", MaxQueryResultPageSize))
+	}
+	return size, nil
+}
+
+func (h *HttpHandler) queryResultPage(result *QueryResult, from, size int) (*inventoryApi.QueryResultPage, error) {
+	rows, err := result.Page(from, size)
+	if errors.Is(err, errQueryResultNotFound) {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "query result not found")
+	} else if err != nil {
+		h.logger.Error("failed to read query result", zap.String("runID", result.RunID), zap.Error(err))
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to read query result")
+	}
+
+	page := inventoryApi.QueryResultPage{
+		RunID:     result.RunID,
+		Headers:   result.Headers,
+		TotalRows: result.RowCount,
+		Truncated: result.Truncated,
+		Result:    rows,
+		ExpiresAt: result.ExpiresAt,
+	}
+	if next := from + len(rows); next < result.RowCount {
+		page.NextCursor = strconv.Itoa(next)
+	}
+	return &page, nil
+}
+
+// cacheQueryResult runs the query and streams its rows into the result cache, adding
+// account_name next to kaytu_account_id the same way RunSQLNamedQuery does.
+func (h *HttpHandler) cacheQueryResult(ctx context.Context, ownerID, query string) (*QueryResult, error) {
+	connections, err := h.onboardClient.ListSources(&httpclient.Context{UserRole: api.InternalRole}, nil)
+	if err != nil {
+		return nil, err
+	}
+	connectionToNameMap := make(map[string]string)
+	for _, connection := range connections {
+		connectionToNameMap[connection.ID.String()] = connection.ConnectionName
+	}
+
+	h.logger.Info("executing query into result cache", zap.String("query", query))
+	rows, err := h.steampipeConn.Conn().Query(ctx, query)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	defer rows.Close()
+
+	var headers []string
+	ctxIdx, accountIDIdx := -1, -1
+	for idx, field := range rows.FieldDescriptions() {
+		name := string(field.Name)
+		if name == "_ctx" {
+			ctxIdx = idx
+			continue
+		}
+		if strings.ToLower(name) == "kaytu_account_id" && accountIDIdx == -1 {
+			accountIDIdx = len(headers)
+		}
+		headers = append(headers, name)
+	}
+	if accountIDIdx != -1 {
+		headers = append(headers, "account_name")
+	}
+
+	writer, err := h.queryResults.NewWriter(ownerID, headers)
+	if errors.Is(err, errQueryResultQuotaExceeded) {
+		return nil, echo.NewHTTPError(http.StatusTooManyRequests, "query result quota exceeded, delete cached results or wait for them to expire")
+	} else if err != nil {
+		h.logger.Error("failed to create query result", zap.Error(err))
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to create query result")
+	}
+
+	truncated := false
+	for rows.Next() {
+		if writer.Full() {
+			truncated = true
+			break
+		}
+		values, err := rows.Values()
+		if err != nil {
+			writer.Abort()
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		record := make([]any, 0, len(headers))
+		for idx, value := range values {
+			if idx != ctxIdx {
+				record = append(record, value)
+			}
+		}
+		if accountIDIdx != -1 {
+			accountName := "null"
+			if accountID, ok := record[accountIDIdx].(string); ok {
+				if name, ok := connectionToNameMap[accountID]; ok {
+					accountName = name
+				}
+			}
+			record = append(record, accountName)
+		}
+		if err := writer.Write(record); err != nil {
+			writer.Abort()
+			h.logger.Error("failed to write query result", zap.Error(err))
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to write query result")
+		}
+	}
+	if err := rows.Err(); err != nil {
+		writer.Abort()
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	result, err := writer.Commit(truncated)
+	if errors.Is(err, errQueryResultQuotaExceeded) {
+		return nil, echo.NewHTTPError(http.StatusTooManyRequests, "query result quota exceeded, delete cached results or wait for them to expire")
+	} else if err != nil {
+		h.logger.Error("failed to commit query result", zap.Error(err))
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "failed to write query result")
+	}
+	return result, nil
+}
+
 // GetResourceCategories godoc
 //
 //	@Summary		Get list of unique resource categories
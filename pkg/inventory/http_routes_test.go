@@ -0,0 +1,152 @@
+package inventory
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kaytu-io/kaytu-util/pkg/api"
+	"github.com/kaytu-io/kaytu-util/pkg/httpserver"
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
+	"github.com/kaytu-io/open-governance/pkg/inventory/es"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortedQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		sorts   []inventoryApi.NamedQuerySortItem
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "sort items keep their priority",
+			query: "SELECT name, region FROM aws_ec2_instance",
+			sorts: []inventoryApi.NamedQuerySortItem{
+				{Field: "region", Direction: inventoryApi.DirectionDescending},
+				{Field: "name"},
+				{Field: "id", Direction: inventoryApi.DirectionAscending},
+			},
+			want: "SELECT * FROM (\nSELECT name, region FROM aws_ec2_instance\n) AS sorted_query ORDER BY \"region\" DESC, \"name\" ASC, \"id\" ASC",
+		},
+		{
+			name:  "trailing semicolons and spaces are dropped",
+			query: "  SELECT 1 AS n;;  \n",
+			sorts: []inventoryApi.NamedQuerySortItem{{Field: "n"}},
+			want:  "SELECT * FROM (\nSELECT 1 AS n\n) AS sorted_query ORDER BY \"n\" ASC",
+		},
+		{
+			name:  "trailing line comment stays inside the subquery",
+			query: "SELECT name FROM aws_iam_role -- roles",
+			sorts: []inventoryApi.NamedQuerySortItem{{Field: "name"}},
+			want:  "SELECT * FROM (\nSELECT name FROM aws_iam_role -- roles\n) AS sorted_query ORDER BY \"name\" ASC",
+		},
+		{
+			name:  "quotes in field names are escaped",
+			query: "SELECT 1",
+			sorts: []inventoryApi.NamedQuerySortItem{{Field: `a" DESC; DROP TABLE x; --`}},
+			want:  "SELECT * FROM (\nSELECT 1\n) AS sorted_query ORDER BY \"a\"\" DESC; DROP TABLE x; --\" ASC",
+		},
+		{
+			name:    "empty field",
+			query:   "SELECT 1",
+			sorts:   []inventoryApi.NamedQuerySortItem{{Field: "name"}, {Direction: inventoryApi.DirectionDescending}},
+			wantErr: "sort field is required",
+		},
+		{
+			name:    "invalid direction",
+			query:   "SELECT 1",
+			sorts:   []inventoryApi.NamedQuerySortItem{{Field: "name", Direction: "up"}},
+			wantErr: "invalid sort direction: up",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := sortedQuery(tc.query, tc.sorts)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, query)
+		})
+	}
+}
+
+func TestCheckDiffableQueryRuns(t *testing.T) {
+	run := func(runID, queryID, createdBy string) *es.GetAsyncQueryRunResultSource {
+		return &es.GetAsyncQueryRunResultSource{RunId: runID, QueryID: queryID, CreatedBy: createdBy}
+	}
+
+	tests := []struct {
+		name     string
+		base     *es.GetAsyncQueryRunResultSource
+		target   *es.GetAsyncQueryRunResultSource
+		wantCode int
+		wantMsg  string
+	}{
+		{name: "runs of the user", base: run("run-1", "q-1", "user-1"), target: run("run-2", "q-1", "user-1")},
+		{name: "missing base run", base: run("", "", ""), target: run("run-2", "q-1", "user-1"), wantCode: http.StatusNotFound, wantMsg: "base run result not found"},
+		{name: "base run of another user", base: run("run-1", "q-1", "user-2"), target: run("run-2", "q-1", "user-1"), wantCode: http.StatusNotFound, wantMsg: "base run result not found"},
+		{name: "missing target run", base: run("run-1", "q-1", "user-1"), target: run("", "", ""), wantCode: http.StatusNotFound, wantMsg: "target run result not found"},
+		{name: "target run of another user", base: run("run-1", "q-1", "user-1"), target: run("run-2", "q-1", "user-2"), wantCode: http.StatusNotFound, wantMsg: "target run result not found"},
+		{name: "runs of different queries", base: run("run-1", "q-1", "user-1"), target: run("run-2", "q-2", "user-1"), wantCode: http.StatusBadRequest, wantMsg: "runs are not of the same query"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkDiffableQueryRuns("user-1", tc.base, tc.target)
+			if tc.wantCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tc.wantCode, httpErr.Code)
+			assert.Equal(t, tc.wantMsg, httpErr.Message)
+		})
+	}
+}
+
+func TestCheckEditableSavedQuery(t *testing.T) {
+	private := &SavedQuery{OwnerID: "alice", Visibility: inventoryApi.SavedQueryVisibilityPrivate}
+	team := &SavedQuery{OwnerID: "alice", Visibility: inventoryApi.SavedQueryVisibilityTeam, SharedWith: []string{"bob"}}
+
+	tests := []struct {
+		name     string
+		query    *SavedQuery
+		userID   string
+		role     api.Role
+		wantCode int
+	}{
+		{name: "owner", query: private, userID: "alice", role: api.EditorRole},
+		{name: "admin on a private query of another user", query: private, userID: "bob", role: api.AdminRole},
+		{name: "internal call without a user", query: private, userID: "", role: api.InternalRole},
+		{name: "team member", query: team, userID: "bob", role: api.EditorRole, wantCode: http.StatusForbidden},
+		{name: "private query of another user", query: private, userID: "bob", role: api.EditorRole, wantCode: http.StatusNotFound},
+		{name: "call without a user", query: private, userID: "", role: api.ViewerRole, wantCode: http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkEditableSavedQuery(tc.query, tc.userID, tc.role)
+			if tc.wantCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tc.wantCode, httpErr.Code)
+		})
+	}
+}
+
+func TestRequestUserID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	assert.Equal(t, "", requestUserID(ctx), "internal calls have no user")
+
+	req.Header.Set(httpserver.XKaytuUserIDHeader, "alice")
+	assert.Equal(t, "alice", requestUserID(ctx))
+}
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"errors"
+	"io"
+	"reflect"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const parquetDefaultRowGroupSize = 10000
+
+// ParquetWriter writes rows of nullable strings as a flat parquet file with one
+// optional UTF8 column per header. Only the current row group is kept in memory,
+// so the memory footprint is bounded by the row group size and not by the file size.
+type ParquetWriter struct {
+	writer       *parquet.Writer
+	columns      []string
+	rowGroupSize int
+
+	row    parquet.Row
+	rows   int
+	closed bool
+}
+
+// NewParquetWriter returns a writer for the given columns. A rowGroupSize <= 0 uses the default row group size.
+func NewParquetWriter(w io.Writer, columns []string, rowGroupSize int) *ParquetWriter {
+	if rowGroupSize <= 0 {
+		rowGroupSize = parquetDefaultRowGroupSize
+	}
+	fields := make([]parquet.Field, 0, len(columns))
+	for _, column := range columns {
+		fields = append(fields, parquetColumn{Node: parquet.Optional(parquet.String()), name: column})
+	}
+	schema := parquet.NewSchema("query_result", parquetColumns{fields: fields})
+	return &ParquetWriter{
+		writer:       parquet.NewWriter(w, schema),
+		columns:      columns,
+		rowGroupSize: rowGroupSize,
+		row:          make(parquet.Row, len(columns)),
+	}
+}
+
+func (p *ParquetWriter) Columns() []string {
+	return p.columns
+}
+
+// Write appends a row, nil values are written as nulls.
+func (p *ParquetWriter) Write(row []*string) error {
+	if p.closed {
+		return errors.New("parquet writer is closed")
+	}
+	if len(row) != len(p.columns) {
+		return errors.New("row length does not match the number of columns")
+	}
+	for idx, value := range row {
+		if value == nil {
+			p.row[idx] = parquet.NullValue().Level(0, 0, idx)
+		} else {
+			p.row[idx] = parquet.ByteArrayValue([]byte(*value)).Level(0, 1, idx)
+		}
+	}
+	if _, err := p.writer.WriteRows([]parquet.Row{p.row}); err != nil {
+		return err
+	}
+	p.rows++
+	if p.rows >= p.rowGroupSize {
+		p.rows = 0
+		return p.writer.Flush()
+	}
+	return nil
+}
+
+// Close flushes the buffered rows and writes the file footer. It does not close the underlying writer.
+func (p *ParquetWriter) Close() error {
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return p.writer.Close()
+}
+
+// parquetColumns is the root of the schema. Unlike parquet.Group, which sorts its
+// fields by name, it keeps the columns in the order of the query result.
+type parquetColumns struct {
+	parquet.Group
+	fields []parquet.Field
+}
+
+func (c parquetColumns) Fields() []parquet.Field {
+	return c.fields
+}
+
+func (c parquetColumns) String() string {
+	return parquet.NewSchema("", c).String()
+}
+
+// parquetColumn names a column of parquetColumns. Rows are written as parquet.Row
+// values, so the Go value of the column is never looked up.
+type parquetColumn struct {
+	parquet.Node
+	name string
+}
+
+func (c parquetColumn) Name() string {
+	return c.name
+}
+
+func (c parquetColumn) Value(base reflect.Value) reflect.Value {
+	return reflect.Value{}
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readParquet reads the file back with an independent parquet implementation
+func readParquet(t *testing.T, data []byte) (*parquet.File, [][]*string) {
+	t.Helper()
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	var result [][]*string
+	for _, rowGroup := range file.RowGroups() {
+		rows := rowGroup.Rows()
+		buf := make([]parquet.Row, 7)
+		for {
+			n, err := rows.ReadRows(buf)
+			for _, row := range buf[:n] {
+				values := make([]*string, len(file.Schema().Fields()))
+				for _, value := range row {
+					if value.IsNull() {
+						continue
+					}
+					s := string(value.ByteArray())
+					values[value.Column()] = &s
+				}
+				result = append(result, values)
+			}
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+		require.NoError(t, rows.Close())
+	}
+	return file, result
+}
+
+func writeParquet(t *testing.T, columns []string, rows [][]*string, rowGroupSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := NewParquetWriter(&buf, columns, rowGroupSize)
+	for _, row := range rows {
+		require.NoError(t, writer.Write(row))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func strValue(s string) *string {
+	return &s
+}
+
+func TestParquetWriter_RoundTrip(t *testing.T) {
+	columns := []string{"id", "name", "note"}
+	var rows [][]*string
+	for i := 0; i < 25; i++ {
+		row := []*string{strValue(fmt.Sprintf("i-%d", i)), strValue(fmt.Sprintf("instance %d", i)), nil}
+		switch {
+		case i%5 == 0:
+			row[1] = nil
+		case i%7 == 0:
+			row[1] = strValue("")
+		}
+		if i >= 10 && i < 13 {
+			row[2] = strValue("ünïcödé, \"quoted\"\nmulti line")
+		}
+		rows = append(rows, row)
+	}
+
+	file, read := readParquet(t, writeParquet(t, columns, rows, 10))
+
+	assert.Equal(t, int64(25), file.NumRows())
+	assert.Len(t, file.RowGroups(), 3)
+	fields := file.Schema().Fields()
+	require.Len(t, fields, len(columns))
+	for idx, field := range fields {
+		assert.Equal(t, columns[idx], field.Name())
+		assert.True(t, field.Optional(), field.Name())
+		assert.Equal(t, parquet.ByteArray, field.Type().Kind(), field.Name())
+		assert.NotNil(t, field.Type().LogicalType().UTF8, field.Name())
+	}
+	assert.Equal(t, rows, read)
+}
+
+func TestParquetWriter_ManyColumns(t *testing.T) {
+	// columns keep the order of the query result and not the order of their names
+	var columns []string
+	row := make([]*string, 0, 20)
+	for i := 0; i < 20; i++ {
+		columns = append(columns, fmt.Sprintf("column_%d", i))
+		if i%3 == 0 {
+			row = append(row, nil)
+		} else {
+			row = append(row, strValue(fmt.Sprintf("value %d", i)))
+		}
+	}
+
+	file, read := readParquet(t, writeParquet(t, columns, [][]*string{row, row}, 0))
+	assert.Len(t, file.Schema().Fields(), 20)
+	assert.Equal(t, [][]*string{row, row}, read)
+}
+
+func TestParquetWriter_Empty(t *testing.T) {
+	file, read := readParquet(t, writeParquet(t, []string{"id"}, nil, 0))
+	assert.Zero(t, file.NumRows())
+	assert.Empty(t, file.RowGroups())
+	assert.Empty(t, read)
+}
+
+func TestParquetWriter_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewParquetWriter(&buf, []string{"id", "name"}, 0)
+	assert.EqualError(t, writer.Write([]*string{strValue("i-1")}), "row length does not match the number of columns")
+	require.NoError(t, writer.Close())
+	assert.EqualError(t, writer.Write([]*string{strValue("i-1"), nil}), "parquet writer is closed")
+}
@@ -101,11 +101,21 @@ func (p NamedQuery) GetTagsMap() map[string][]string {
 	return tagsMap
 }
 
+// NamedQueryHistory is the list of recently ran queries of a user
 type NamedQueryHistory struct {
+	UserID     string `gorm:"primaryKey"`
 	Query      string `gorm:"type:citext; primaryKey"`
 	ExecutedAt time.Time
 }
 
+// legacyNamedQueryHistoryTable is the former history table shared by every user, keyed by query only
+const legacyNamedQueryHistoryTable = "named_query_histories"
+
+// TableName keeps the per user history apart from the former global history table, which is dropped on startup
+func (NamedQueryHistory) TableName() string {
+	return "user_named_query_histories"
+}
+
 func (s NamedQueryHistory) ToApi() api.NamedQueryHistory {
 	return api.NamedQueryHistory{
 		Query:      s.Query,
@@ -10,4 +10,9 @@ const (
 
 	JobTimeoutMinutes = 5
 	JobTimeout        = JobTimeoutMinutes * time.Minute
+
+	// MaxScheduledResultRows and MaxScheduledResultBytes keep the job result messages of scheduled runs
+	// below the payload limit of nats
+	MaxScheduledResultRows  = 1000
+	MaxScheduledResultBytes = 512 * 1024
 )
@@ -0,0 +1,138 @@
+package query_runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+)
+
+type diffColumn struct {
+	name      string
+	baseIdx   int
+	targetIdx int
+}
+
+// DiffResults compares two results of a query. Rows are matched by the values of the key columns,
+// rows sharing a key are matched in order, and matched rows are compared on the columns both results have.
+// Without key columns all of the common columns are used as the key, so rows are only added or removed.
+func DiffResults(baseColumns []string, baseRows [][]string, targetColumns []string, targetRows [][]string, keyColumns []string) (*api.QueryRunDiff, error) {
+	baseIdx := diffColumnIndexes(baseColumns)
+	var common []diffColumn
+	for targetIdx, name := range targetColumns {
+		if idx, ok := baseIdx[strings.ToLower(name)]; ok {
+			common = append(common, diffColumn{name: name, baseIdx: idx, targetIdx: targetIdx})
+		}
+	}
+
+	var keys []diffColumn
+	if len(keyColumns) == 0 {
+		keys = common
+		for _, column := range common {
+			keyColumns = append(keyColumns, column.name)
+		}
+	} else {
+		targetIdx := diffColumnIndexes(targetColumns)
+		for _, name := range keyColumns {
+			bIdx, ok := baseIdx[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("key column %s is not in the base run", name)
+			}
+			tIdx, ok := targetIdx[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("key column %s is not in the target run", name)
+			}
+			keys = append(keys, diffColumn{name: name, baseIdx: bIdx, targetIdx: tIdx})
+		}
+	}
+
+	diff := api.QueryRunDiff{
+		KeyColumns:        keyColumns,
+		BaseColumnNames:   baseColumns,
+		TargetColumnNames: targetColumns,
+		Added:             []api.QueryRunDiffRow{},
+		Removed:           []api.QueryRunDiffRow{},
+		Changed:           []api.QueryRunChangedRow{},
+	}
+
+	baseByKey := make(map[string][]int)
+	for idx, row := range baseRows {
+		key := diffRowKey(row, keys, false)
+		baseByKey[key] = append(baseByKey[key], idx)
+	}
+
+	matched := make([]bool, len(baseRows))
+	for _, row := range targetRows {
+		key := diffRowKey(row, keys, true)
+		candidates := baseByKey[key]
+		if len(candidates) == 0 {
+			diff.Added = append(diff.Added, api.QueryRunDiffRow{Key: diffKeyValues(row, keys, true), Row: row})
+			continue
+		}
+		baseRow := baseRows[candidates[0]]
+		matched[candidates[0]] = true
+		baseByKey[key] = candidates[1:]
+
+		var cells []api.QueryRunChangedCell
+		for _, column := range common {
+			before, after := diffCell(baseRow, column.baseIdx), diffCell(row, column.targetIdx)
+			if before != after {
+				cells = append(cells, api.QueryRunChangedCell{Column: column.name, Before: before, After: after})
+			}
+		}
+		if len(cells) == 0 {
+			diff.UnchangedCount++
+			continue
+		}
+		diff.Changed = append(diff.Changed, api.QueryRunChangedRow{
+			Key:    diffKeyValues(row, keys, true),
+			Before: baseRow,
+			After:  row,
+			Cells:  cells,
+		})
+	}
+	for idx, row := range baseRows {
+		if !matched[idx] {
+			diff.Removed = append(diff.Removed, api.QueryRunDiffRow{Key: diffKeyValues(row, keys, false), Row: row})
+		}
+	}
+
+	diff.AddedCount = len(diff.Added)
+	diff.RemovedCount = len(diff.Removed)
+	diff.ChangedCount = len(diff.Changed)
+	return &diff, nil
+}
+
+// diffColumnIndexes maps the lower cased column names to their first index
+func diffColumnIndexes(columns []string) map[string]int {
+	indexes := make(map[string]int, len(columns))
+	for idx, name := range columns {
+		if _, ok := indexes[strings.ToLower(name)]; !ok {
+			indexes[strings.ToLower(name)] = idx
+		}
+	}
+	return indexes
+}
+
+func diffKeyValues(row []string, keys []diffColumn, target bool) []string {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		idx := key.baseIdx
+		if target {
+			idx = key.targetIdx
+		}
+		values = append(values, diffCell(row, idx))
+	}
+	return values
+}
+
+func diffRowKey(row []string, keys []diffColumn, target bool) string {
+	return strings.Join(diffKeyValues(row, keys, target), "\x1f")
+}
+
+func diffCell(row []string, idx int) string {
+	if idx < len(row) {
+		return row[idx]
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package query_runner
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffResults_KeyColumns(t *testing.T) {
+	baseColumns := []string{"id", "state", "region"}
+	baseRows := [][]string{
+		{"i-1", "running", "us-east-1"},
+		{"i-2", "running", "us-east-1"},
+		{"i-3", "stopped", "eu-west-1"},
+	}
+	// columns are matched by name in any case and order
+	targetColumns := []string{"Region", "ID", "State"}
+	targetRows := [][]string{
+		{"us-east-1", "i-4", "pending"},
+		{"us-east-1", "i-1", "running"},
+		{"us-west-2", "i-3", "running"},
+	}
+
+	diff, err := DiffResults(baseColumns, baseRows, targetColumns, targetRows, []string{"id"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"id"}, diff.KeyColumns)
+	assert.Equal(t, 1, diff.AddedCount)
+	assert.Equal(t, 1, diff.RemovedCount)
+	assert.Equal(t, 1, diff.ChangedCount)
+	assert.Equal(t, 1, diff.UnchangedCount)
+	assert.True(t, diff.HasChanges())
+
+	assert.Equal(t, []api.QueryRunDiffRow{{Key: []string{"i-4"}, Row: targetRows[0]}}, diff.Added)
+	assert.Equal(t, []api.QueryRunDiffRow{{Key: []string{"i-2"}, Row: baseRows[1]}}, diff.Removed)
+	assert.Equal(t, []api.QueryRunChangedRow{{
+		Key:    []string{"i-3"},
+		Before: baseRows[2],
+		After:  targetRows[2],
+		Cells: []api.QueryRunChangedCell{
+			{Column: "Region", Before: "eu-west-1", After: "us-west-2"},
+			{Column: "State", Before: "stopped", After: "running"},
+		},
+	}}, diff.Changed)
+}
+
+func TestDiffResults_WithoutKeyColumns(t *testing.T) {
+	columns := []string{"id", "state"}
+	baseRows := [][]string{{"i-1", "running"}, {"i-2", "running"}}
+	targetRows := [][]string{{"i-2", "running"}, {"i-1", "stopped"}}
+
+	diff, err := DiffResults(columns, baseRows, columns, targetRows, nil)
+	require.NoError(t, err)
+
+	// the whole row is the key, so a changed value is a removed and an added row
+	assert.Equal(t, []string{"id", "state"}, diff.KeyColumns)
+	assert.Equal(t, []api.QueryRunDiffRow{{Key: []string{"i-1", "stopped"}, Row: targetRows[1]}}, diff.Added)
+	assert.Equal(t, []api.QueryRunDiffRow{{Key: []string{"i-1", "running"}, Row: baseRows[0]}}, diff.Removed)
+	assert.Empty(t, diff.Changed)
+	assert.Equal(t, 1, diff.UnchangedCount)
+}
+
+func TestDiffResults_DuplicateKeys(t *testing.T) {
+	columns := []string{"account", "finding"}
+	baseRows := [][]string{{"a-1", "open port"}, {"a-1", "public bucket"}, {"a-1", "root keys"}}
+	targetRows := [][]string{{"a-1", "open port"}, {"a-1", "mfa disabled"}}
+
+	diff, err := DiffResults(columns, baseRows, columns, targetRows, []string{"account"})
+	require.NoError(t, err)
+
+	// rows sharing a key are matched in order
+	assert.Equal(t, 1, diff.UnchangedCount)
+	require.Len(t, diff.Changed, 1)
+	assert.Equal(t, []api.QueryRunChangedCell{{Column: "finding", Before: "public bucket", After: "mfa disabled"}}, diff.Changed[0].Cells)
+	assert.Equal(t, []api.QueryRunDiffRow{{Key: []string{"a-1"}, Row: baseRows[2]}}, diff.Removed)
+	assert.Empty(t, diff.Added)
+}
+
+func TestDiffResults_ColumnChanges(t *testing.T) {
+	baseColumns := []string{"id", "state", "owner"}
+	targetColumns := []string{"id", "state", "tags"}
+	baseRows := [][]string{{"i-1", "running", "alice"}}
+	targetRows := [][]string{{"i-1", "running", "env=prod"}}
+
+	diff, err := DiffResults(baseColumns, baseRows, targetColumns, targetRows, []string{"id"})
+	require.NoError(t, err)
+
+	// only the columns both runs have are compared
+	assert.False(t, diff.HasChanges())
+	assert.Equal(t, 1, diff.UnchangedCount)
+	assert.Equal(t, baseColumns, diff.BaseColumnNames)
+	assert.Equal(t, targetColumns, diff.TargetColumnNames)
+
+	t.Run("short rows are compared as empty cells", func(t *testing.T) {
+		diff, err := DiffResults(baseColumns, [][]string{{"i-1"}}, targetColumns, targetRows, []string{"id"})
+		require.NoError(t, err)
+		require.Len(t, diff.Changed, 1)
+		assert.Equal(t, []api.QueryRunChangedCell{{Column: "state", Before: "", After: "running"}}, diff.Changed[0].Cells)
+	})
+}
+
+func TestDiffResults_InvalidKeyColumns(t *testing.T) {
+	_, err := DiffResults([]string{"id", "owner"}, nil, []string{"id"}, nil, []string{"owner"})
+	assert.EqualError(t, err, "key column owner is not in the target run")
+
+	_, err = DiffResults([]string{"id"}, nil, []string{"id", "tags"}, nil, []string{"ID", "tags"})
+	assert.EqualError(t, err, "key column tags is not in the base run")
+}
+
+func TestDiffResults_Empty(t *testing.T) {
+	diff, err := DiffResults([]string{"id"}, nil, []string{"id"}, nil, nil)
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges())
+	assert.NotNil(t, diff.Added)
+	assert.NotNil(t, diff.Removed)
+	assert.NotNil(t, diff.Changed)
+}
+
+func TestQueryRunDiff_Compact(t *testing.T) {
+	columns := []string{"id", "state"}
+	baseRows := [][]string{{"i-1", "running"}, {"i-2", "running"}, {"i-3", "running"}}
+	targetRows := [][]string{{"i-1", "stopped"}, {"i-2", "stopped"}, {"i-4", "running"}}
+
+	diff, err := DiffResults(columns, baseRows, columns, targetRows, []string{"id"})
+	require.NoError(t, err)
+
+	compact := diff.Compact(1)
+	assert.True(t, compact.Truncated)
+	assert.Equal(t, 2, compact.ChangedCount)
+	assert.Equal(t, []api.QueryRunChangedRow{{
+		Key:   []string{"i-1"},
+		Cells: []api.QueryRunChangedCell{{Column: "state", Before: "running", After: "stopped"}},
+	}}, compact.Changed)
+	assert.Equal(t, []api.QueryRunDiffRow{{Key: []string{"i-4"}}}, compact.Added)
+	assert.Equal(t, []api.QueryRunDiffRow{{Key: []string{"i-3"}}}, compact.Removed)
+
+	assert.False(t, diff.Compact(2).Truncated)
+	// the full diff is not changed
+	assert.Len(t, diff.Changed, 2)
+	assert.Equal(t, baseRows[0], diff.Changed[0].Before)
+}
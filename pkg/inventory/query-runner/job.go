@@ -21,14 +21,15 @@ type Job struct {
 	QueryId     string               `json:"queryId"`
 	Parameters  []api.QueryParameter `json:"parameters"`
 	Query       string               `json:"query"`
+	ScheduleID  *uint                `json:"scheduleID,omitempty"` // Set for runs of a query schedule
 }
 
-func (w *Worker) RunJob(ctx context.Context, job Job) error {
+func (w *Worker) RunJob(ctx context.Context, job Job) (*types.QueryRunResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, JobTimeout)
 	defer cancel()
 	queryResult, err := w.RunSQLNamedQuery(ctx, job.Query)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var results [][]string
@@ -59,8 +60,8 @@ func (w *Worker) RunJob(ctx context.Context, job Job) error {
 
 	if _, err := w.sinkClient.Ingest(&httpclient.Context{Ctx: ctx, UserRole: authApi.InternalRole}, doc); err != nil {
 		w.logger.Error("Failed to sink Query Run Result", zap.String("ID", strconv.Itoa(int(job.ID))), zap.String("QueryID", job.QueryId), zap.Error(err))
-		return err
+		return nil, err
 	}
 
-	return nil
+	return &queryRunResult, nil
 }
@@ -1,5 +1,7 @@
 package query_runner
 
+import "encoding/json"
+
 type QueryRunnerStatus string
 
 const (
@@ -16,4 +18,35 @@ type JobResult struct {
 	ID             uint              `json:"ID"`
 	Status         QueryRunnerStatus `json:"status"`
 	FailureMessage string            `json:"failureMessage"`
+	// ScheduledResult is only set for succeeded runs of a query schedule
+	ScheduledResult *ScheduledResult `json:"scheduledResult,omitempty"`
+}
+
+// ScheduledResult carries the rows of a scheduled run back to the scheduler to evaluate the alert conditions
+type ScheduledResult struct {
+	ColumnNames []string   `json:"columnNames"`
+	Rows        [][]string `json:"rows"`     // Capped at MaxScheduledResultRows and MaxScheduledResultBytes
+	RowCount    int        `json:"rowCount"` // Row count of the whole result
+}
+
+// NewScheduledResult keeps the leading rows of the result that fit in MaxScheduledResultRows and MaxScheduledResultBytes
+func NewScheduledResult(columnNames []string, rows [][]string) *ScheduledResult {
+	size := 0
+	if b, err := json.Marshal(columnNames); err == nil {
+		size += len(b)
+	}
+	kept := 0
+	for kept < len(rows) && kept < MaxScheduledResultRows {
+		b, err := json.Marshal(rows[kept])
+		if err != nil || size+len(b)+1 > MaxScheduledResultBytes {
+			break
+		}
+		size += len(b) + 1
+		kept++
+	}
+	return &ScheduledResult{
+		ColumnNames: columnNames,
+		Rows:        rows[:kept],
+		RowCount:    len(rows),
+	}
 }
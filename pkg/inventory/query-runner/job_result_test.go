@@ -0,0 +1,63 @@
+package query_runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScheduledResult(t *testing.T) {
+	columnNames := []string{"id", "payload"}
+	newRows := func(count, payloadSize int) [][]string {
+		rows := make([][]string, 0, count)
+		for i := 0; i < count; i++ {
+			rows = append(rows, []string{fmt.Sprintf("r-%d", i), strings.Repeat("x", payloadSize)})
+		}
+		return rows
+	}
+
+	t.Run("small result is kept whole", func(t *testing.T) {
+		rows := newRows(10, 10)
+		result := NewScheduledResult(columnNames, rows)
+		assert.Equal(t, columnNames, result.ColumnNames)
+		assert.Equal(t, rows, result.Rows)
+		assert.Equal(t, 10, result.RowCount)
+	})
+
+	t.Run("rows are capped by count", func(t *testing.T) {
+		rows := newRows(MaxScheduledResultRows+5, 1)
+		result := NewScheduledResult(columnNames, rows)
+		assert.Len(t, result.Rows, MaxScheduledResultRows)
+		assert.Equal(t, rows[:MaxScheduledResultRows], result.Rows)
+		assert.Equal(t, MaxScheduledResultRows+5, result.RowCount)
+	})
+
+	t.Run("rows are capped by size", func(t *testing.T) {
+		rows := newRows(200, 10*1024)
+		result := NewScheduledResult(columnNames, rows)
+		require.NotEmpty(t, result.Rows)
+		assert.Less(t, len(result.Rows), 200)
+		assert.Equal(t, rows[:len(result.Rows)], result.Rows)
+		assert.Equal(t, 200, result.RowCount)
+
+		message, err := json.Marshal(JobResult{ID: 1, Status: QueryRunnerSucceeded, ScheduledResult: result})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(message), MaxScheduledResultBytes+1024)
+	})
+
+	t.Run("row over the size limit", func(t *testing.T) {
+		result := NewScheduledResult(columnNames, newRows(3, MaxScheduledResultBytes))
+		assert.Empty(t, result.Rows)
+		assert.Equal(t, 3, result.RowCount)
+	})
+
+	t.Run("empty result", func(t *testing.T) {
+		result := NewScheduledResult(columnNames, nil)
+		assert.Empty(t, result.Rows)
+		assert.Zero(t, result.RowCount)
+	})
+}
@@ -0,0 +1,330 @@
+package query_validator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+)
+
+type relationRef struct {
+	schema   string
+	name     string
+	alias    string
+	location int
+}
+
+type columnRef struct {
+	fields   []string
+	location int
+}
+
+// analysis holds the relations and columns referenced anywhere in the parse tree of a query
+type analysis struct {
+	relations []relationRef
+	columns   []columnRef
+	ctes      map[string]bool
+	// aliases of subqueries and function calls, their columns are not known
+	opaqueAliases map[string]bool
+	outputNames   map[string]bool
+}
+
+func newAnalysis() *analysis {
+	return &analysis{
+		ctes:          make(map[string]bool),
+		opaqueAliases: make(map[string]bool),
+		outputNames:   make(map[string]bool),
+	}
+}
+
+// walk visits a node of the JSON parse tree of pg_query
+func (a *analysis) walk(node any) {
+	switch n := node.(type) {
+	case []any:
+		for _, child := range n {
+			a.walk(child)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(n))
+		for key := range n {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := n[key]
+			fields, _ := value.(map[string]any)
+			switch key {
+			case "RangeVar":
+				a.addRelation(fields)
+			case "relation":
+				// the target table of insert, update and delete statements is not wrapped in a RangeVar node
+				if _, ok := fields["relname"]; ok {
+					a.addRelation(fields)
+				}
+			case "ColumnRef":
+				a.addColumn(fields)
+			case "CommonTableExpr":
+				a.ctes[strings.ToLower(stringField(fields, "ctename"))] = true
+			case "RangeSubselect", "RangeFunction", "RangeTableFunc":
+				if alias := aliasName(fields); alias != "" {
+					a.opaqueAliases[strings.ToLower(alias)] = true
+				}
+			case "ResTarget":
+				if name := stringField(fields, "name"); name != "" {
+					a.outputNames[strings.ToLower(name)] = true
+				}
+			}
+			a.walk(value)
+		}
+	}
+}
+
+func (a *analysis) addRelation(node map[string]any) {
+	a.relations = append(a.relations, relationRef{
+		schema:   strings.ToLower(stringField(node, "schemaname")),
+		name:     strings.ToLower(stringField(node, "relname")),
+		alias:    strings.ToLower(aliasName(node)),
+		location: intField(node, "location"),
+	})
+}
+
+func (a *analysis) addColumn(node map[string]any) {
+	ref := columnRef{location: intField(node, "location")}
+	items, _ := node["fields"].([]any)
+	for _, item := range items {
+		field, _ := item.(map[string]any)
+		if str, ok := field["String"].(map[string]any); ok {
+			ref.fields = append(ref.fields, strings.ToLower(stringField(str, "sval")))
+		} else if _, ok := field["A_Star"]; ok {
+			ref.fields = append(ref.fields, "*")
+		}
+	}
+	if len(ref.fields) > 0 {
+		a.columns = append(a.columns, ref)
+	}
+}
+
+// outputColumns returns the names of the columns of a select statement as postgres names them,
+// nil if they cannot be known without running the query
+func outputColumns(stmt map[string]any) []string {
+	if larg, ok := stmt["larg"].(map[string]any); ok {
+		// set operations are named after their first query
+		return outputColumns(larg)
+	}
+	targets, _ := stmt["targetList"].([]any)
+	if len(targets) == 0 {
+		return nil
+	}
+	columns := make([]string, 0, len(targets))
+	for _, target := range targets {
+		node, _ := target.(map[string]any)
+		res, _ := node["ResTarget"].(map[string]any)
+		if name := stringField(res, "name"); name != "" {
+			columns = append(columns, name)
+			continue
+		}
+		name := targetName(res["val"])
+		if name == "" {
+			return nil
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// targetName is the name postgres gives to an unnamed select target, empty for stars
+func targetName(val any) string {
+	node, _ := val.(map[string]any)
+	switch {
+	case node["ColumnRef"] != nil:
+		columnRef, _ := node["ColumnRef"].(map[string]any)
+		return lastName(columnRef["fields"])
+	case node["FuncCall"] != nil:
+		funcCall, _ := node["FuncCall"].(map[string]any)
+		if name := lastName(funcCall["funcname"]); name != "" {
+			return name
+		}
+	case node["TypeCast"] != nil:
+		cast, _ := node["TypeCast"].(map[string]any)
+		if name := targetName(cast["arg"]); name != "?column?" {
+			return name
+		}
+		// casted constants are named after the type
+		typeName, _ := cast["typeName"].(map[string]any)
+		if name := lastName(typeName["names"]); name != "" {
+			return name
+		}
+	case node["CoalesceExpr"] != nil:
+		return "coalesce"
+	case node["CaseExpr"] != nil:
+		return "case"
+	}
+	return "?column?"
+}
+
+// lastName returns the last string of a qualified name list, empty if it is a star
+func lastName(list any) string {
+	items, _ := list.([]any)
+	if len(items) == 0 {
+		return ""
+	}
+	last, _ := items[len(items)-1].(map[string]any)
+	str, _ := last["String"].(map[string]any)
+	return stringField(str, "sval")
+}
+
+// check reports the unknown tables and columns of the analysis and fills in the tables and connectors of the response
+func (s *Schema) check(a *analysis, views []string, resp *api.ValidateQueryResponse) []api.QueryValidationIssue {
+	viewSet := make(map[string]bool, len(views))
+	for _, view := range views {
+		viewSet[strings.ToLower(view)] = true
+	}
+
+	var issues []api.QueryValidationIssue
+	// relations which can be used as column qualifiers, a nil table has unknown columns
+	sources := make(map[string]*schemaTable)
+	for alias := range a.opaqueAliases {
+		sources[alias] = nil
+	}
+	hasOpaqueSource := len(a.opaqueAliases) > 0
+	tables := make(map[string]bool)
+	connectors := make(map[source.Type]bool)
+	var known []*schemaTable
+	for _, rel := range a.relations {
+		qualifier := rel.alias
+		if qualifier == "" {
+			qualifier = rel.name
+		}
+
+		if systemSchemas[rel.schema] || (rel.schema == "" && (strings.HasPrefix(rel.name, "pg_") || a.ctes[rel.name])) {
+			sources[qualifier] = nil
+			hasOpaqueSource = true
+			continue
+		}
+		if viewSet[rel.name] {
+			tables[rel.name] = true
+			sources[qualifier] = nil
+			hasOpaqueSource = true
+			continue
+		}
+		table, ok := s.tables[rel.name]
+		if !ok {
+			issues = append(issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeUnknownTable, issuePosition(rel.location),
+				"table %s does not exist", rel.name))
+			sources[qualifier] = nil
+			hasOpaqueSource = true
+			continue
+		}
+		tables[rel.name] = true
+		if table.connector != source.Nil {
+			connectors[table.connector] = true
+		}
+		sources[qualifier] = &table
+		if rel.alias != "" {
+			// the table name can still be used as a qualifier if it is not hidden by another relation
+			if _, ok := sources[rel.name]; !ok {
+				sources[rel.name] = &table
+			}
+		}
+		known = append(known, &table)
+	}
+
+	for _, col := range a.columns {
+		name := col.fields[len(col.fields)-1]
+		if name == "*" {
+			continue
+		}
+		if len(col.fields) == 1 {
+			if hasOpaqueSource || a.outputNames[name] {
+				continue
+			}
+			found := false
+			for _, table := range known {
+				if table.columns[name] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				issues = append(issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeUnknownColumn, issuePosition(col.location),
+					"column %s does not exist in the tables of the query", name))
+			}
+			continue
+		}
+
+		qualifier := col.fields[len(col.fields)-2]
+		table, ok := sources[qualifier]
+		if !ok {
+			issues = append(issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeUnknownColumn, issuePosition(col.location),
+				"%s is not a table of the query", qualifier))
+			continue
+		}
+		if table != nil && !table.columns[name] {
+			issues = append(issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeUnknownColumn, issuePosition(col.location),
+				"column %s.%s does not exist", qualifier, name))
+		}
+	}
+
+	for table := range tables {
+		resp.Tables = append(resp.Tables, table)
+	}
+	sort.Strings(resp.Tables)
+	for connector := range connectors {
+		resp.Connectors = append(resp.Connectors, connector)
+	}
+	sort.Slice(resp.Connectors, func(i, j int) bool {
+		return resp.Connectors[i] < resp.Connectors[j]
+	})
+
+	return dedupeIssues(issues)
+}
+
+// dedupeIssues sorts the issues by position and keeps the first issue of each message
+func dedupeIssues(issues []api.QueryValidationIssue) []api.QueryValidationIssue {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Position == nil || issues[j].Position == nil {
+			return issues[j].Position == nil && issues[i].Position != nil
+		}
+		return *issues[i].Position < *issues[j].Position
+	})
+	seen := make(map[string]bool)
+	var result []api.QueryValidationIssue
+	for _, issue := range issues {
+		key := string(issue.Code) + ":" + issue.Message
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, issue)
+	}
+	return result
+}
+
+// issuePosition converts a 0-based location of the parse tree to a 1-based position
+func issuePosition(location int) *int {
+	if location < 0 {
+		return nil
+	}
+	position := location + 1
+	return &position
+}
+
+func aliasName(node map[string]any) string {
+	alias, _ := node["alias"].(map[string]any)
+	return stringField(alias, "aliasname")
+}
+
+func stringField(node map[string]any, key string) string {
+	value, _ := node[key].(string)
+	return value
+}
+
+func intField(node map[string]any, key string) int {
+	// the JSON output omits zero values
+	value, ok := node[key].(float64)
+	if !ok {
+		return 0
+	}
+	return int(value)
+}
@@ -0,0 +1,196 @@
+package query_validator
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate_Tables(t *testing.T) {
+	s := newTestSchema()
+	tests := []struct {
+		name           string
+		query          string
+		views          []string
+		wantTables     []string
+		wantConnectors []source.Type
+		wantIssues     []api.QueryValidationIssue
+	}{
+		{
+			name:           "plugin tables",
+			query:          "select v.arn, u.name from aws_ebs_volume v join aws_iam_user u on u.kaytu_account_id = v.kaytu_account_id join kaytu_resources r on r.kaytu_id = v.arn",
+			wantTables:     []string{"aws_ebs_volume", "aws_iam_user", "kaytu_resources"},
+			wantConnectors: []source.Type{source.CloudAWS},
+		},
+		{
+			name:       "table names are case insensitive",
+			query:      "select ARN from AWS_EBS_VOLUME",
+			wantTables: []string{"aws_ebs_volume"},
+			// the connector of the table is reported once
+			wantConnectors: []source.Type{source.CloudAWS},
+		},
+		{
+			name:           "unknown table",
+			query:          "select arn from aws_ebs_volume join aws_ec2_instance i on i.arn = arn",
+			wantTables:     []string{"aws_ebs_volume"},
+			wantConnectors: []source.Type{source.CloudAWS},
+			wantIssues: []api.QueryValidationIssue{{
+				Severity: api.QueryValidationSeverityError,
+				Code:     api.QueryValidationCodeUnknownTable,
+				Message:  "table aws_ec2_instance does not exist",
+				Position: position(37),
+			}},
+		},
+		{
+			name:  "system tables are not checked",
+			query: "select c.relname, t.table_name from pg_catalog.pg_class c, information_schema.tables t, pg_namespace n",
+		},
+		{
+			name:       "query views",
+			query:      "select anything from public_buckets",
+			views:      []string{"Public_Buckets"},
+			wantTables: []string{"public_buckets"},
+		},
+		{
+			name:           "common table expressions",
+			query:          "with encrypted as (select arn from aws_ebs_volume where encrypted) select e.arn, e.anything from encrypted e",
+			wantTables:     []string{"aws_ebs_volume"},
+			wantConnectors: []source.Type{source.CloudAWS},
+		},
+		{
+			name:           "subqueries",
+			query:          "select s.arn, s.anything from (select arn from aws_iam_user) s where s.arn in (select arn from aws_ebs_volume)",
+			wantTables:     []string{"aws_ebs_volume", "aws_iam_user"},
+			wantConnectors: []source.Type{source.CloudAWS},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := s.Validate(tc.query, nil, tc.views)
+			if tc.wantTables == nil {
+				tc.wantTables = []string{}
+			}
+			if tc.wantConnectors == nil {
+				tc.wantConnectors = []source.Type{}
+			}
+			if tc.wantIssues == nil {
+				tc.wantIssues = []api.QueryValidationIssue{}
+			}
+			assert.Equal(t, tc.wantTables, resp.Tables)
+			assert.Equal(t, tc.wantConnectors, resp.Connectors)
+			assert.Equal(t, tc.wantIssues, resp.Issues)
+			assert.Equal(t, len(tc.wantIssues) == 0, resp.Valid)
+		})
+	}
+}
+
+func TestValidate_ColumnReferences(t *testing.T) {
+	s := newTestSchema()
+	tests := []struct {
+		name       string
+		query      string
+		wantIssues []api.QueryValidationIssue
+	}{
+		{
+			name:  "known columns",
+			query: "select arn, v.title, aws_iam_user.name, mfa_enabled from aws_ebs_volume v, aws_iam_user where v.encrypted and aws_iam_user.kaytu_account_id = v.kaytu_account_id",
+		},
+		{
+			name:  "implicit steampipe columns",
+			query: "select arn, sp_connection_name, _ctx from aws_ebs_volume",
+		},
+		{
+			name:  "table name is a qualifier next to its alias",
+			query: "select aws_ebs_volume.arn from aws_ebs_volume v",
+		},
+		{
+			name:  "output names can be used in order by",
+			query: "select arn as resource from aws_ebs_volume order by resource",
+		},
+		{
+			name:  "columns of function results are not known",
+			query: "select arn, t.key from aws_ebs_volume, jsonb_each_text(tags) t",
+		},
+		{
+			name:  "unknown column",
+			query: "select arn, region from aws_ebs_volume",
+			wantIssues: []api.QueryValidationIssue{{
+				Severity: api.QueryValidationSeverityError,
+				Code:     api.QueryValidationCodeUnknownColumn,
+				Message:  "column region does not exist in the tables of the query",
+				Position: position(13),
+			}},
+		},
+		{
+			name:  "unknown qualified column",
+			query: "select v.arn, u.title from aws_ebs_volume v, aws_iam_user u",
+			wantIssues: []api.QueryValidationIssue{{
+				Severity: api.QueryValidationSeverityError,
+				Code:     api.QueryValidationCodeUnknownColumn,
+				Message:  "column u.title does not exist",
+				Position: position(15),
+			}},
+		},
+		{
+			name:  "unknown qualifier",
+			query: "select x.arn from aws_ebs_volume v",
+			wantIssues: []api.QueryValidationIssue{{
+				Severity: api.QueryValidationSeverityError,
+				Code:     api.QueryValidationCodeUnknownColumn,
+				Message:  "x is not a table of the query",
+				Position: position(8),
+			}},
+		},
+		{
+			name:  "issues are ordered by position and reported once",
+			query: "select region, arn from aws_ebs_volume where region = 'x' and v.arn = arn",
+			wantIssues: []api.QueryValidationIssue{
+				{
+					Severity: api.QueryValidationSeverityError,
+					Code:     api.QueryValidationCodeUnknownColumn,
+					Message:  "column region does not exist in the tables of the query",
+					Position: position(8),
+				},
+				{
+					Severity: api.QueryValidationSeverityError,
+					Code:     api.QueryValidationCodeUnknownColumn,
+					Message:  "v is not a table of the query",
+					Position: position(63),
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := s.Validate(tc.query, nil, nil)
+			if tc.wantIssues == nil {
+				tc.wantIssues = []api.QueryValidationIssue{}
+			}
+			assert.Equal(t, tc.wantIssues, resp.Issues)
+			assert.Equal(t, len(tc.wantIssues) == 0, resp.Valid)
+		})
+	}
+}
+
+func TestDedupeIssues(t *testing.T) {
+	issue := func(message string, position *int) api.QueryValidationIssue {
+		return api.QueryValidationIssue{Severity: api.QueryValidationSeverityError, Code: api.QueryValidationCodeUnknownColumn, Message: message, Position: position}
+	}
+	issues := dedupeIssues([]api.QueryValidationIssue{
+		issue("b", nil),
+		issue("a", position(30)),
+		issue("c", position(10)),
+		issue("a", position(20)),
+		{Severity: api.QueryValidationSeverityError, Code: api.QueryValidationCodeUnknownTable, Message: "a", Position: position(40)},
+	})
+	assert.Equal(t, []api.QueryValidationIssue{
+		issue("c", position(10)),
+		issue("a", position(20)),
+		{Severity: api.QueryValidationSeverityError, Code: api.QueryValidationCodeUnknownTable, Message: "a", Position: position(40)},
+		issue("b", nil),
+	}, issues)
+
+	assert.Nil(t, dedupeIssues(nil))
+}
@@ -0,0 +1,20 @@
+package query_validator
+
+import (
+	"context"
+
+	awsSteampipe "github.com/kaytu-io/kaytu-aws-describer/pkg/steampipe"
+	azureSteampipe "github.com/kaytu-io/kaytu-azure-describer/pkg/steampipe"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/steampipe-plugin-kaytu/kaytu"
+)
+
+// NewPluginSchema returns the schema of the steampipe plugins served by the workspace, including the kaytu_* tables
+func NewPluginSchema(ctx context.Context) *Schema {
+	s := NewSchema()
+	s.AddTables(source.CloudAWS, awsSteampipe.Plugin().TableMap)
+	s.AddTables(source.CloudAzure, azureSteampipe.Plugin().TableMap)
+	s.AddTables(source.CloudAzure, azureSteampipe.ADPlugin().TableMap)
+	s.AddTables(source.Nil, kaytu.TableMap(ctx))
+	return s
+}
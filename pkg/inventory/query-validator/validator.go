@@ -0,0 +1,249 @@
+package query_validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+	pg_query "github.com/pganalyze/pg_query_go/v4"
+	pgQueryParser "github.com/pganalyze/pg_query_go/v4/parser"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// unboundParameterValue is used in place of unbound parameters so the rest of the query can still be parsed
+const unboundParameterValue = "NULL"
+
+// steampipe adds these columns to every table
+var implicitColumns = []string{"_ctx", "sp_ctx", "sp_connection_name"}
+
+// schemas which are not served by the plugins, their tables are not checked
+var systemSchemas = map[string]bool{
+	"pg_catalog":         true,
+	"information_schema": true,
+}
+
+type schemaTable struct {
+	connector source.Type
+	columns   map[string]bool
+}
+
+// Schema holds the tables and columns queries are validated against
+type Schema struct {
+	tables map[string]schemaTable
+}
+
+func NewSchema() *Schema {
+	return &Schema{
+		tables: make(map[string]schemaTable),
+	}
+}
+
+// AddTables adds the tables of a steampipe plugin, connector is source.Nil for tables which are not bound to a connector
+func (s *Schema) AddTables(connector source.Type, tables map[string]*plugin.Table) {
+	for name, table := range tables {
+		columns := make(map[string]bool, len(table.Columns)+len(implicitColumns))
+		for _, column := range table.Columns {
+			columns[strings.ToLower(column.Name)] = true
+		}
+		for _, column := range implicitColumns {
+			columns[column] = true
+		}
+		s.tables[strings.ToLower(name)] = schemaTable{
+			connector: connector,
+			columns:   columns,
+		}
+	}
+}
+
+// Validate checks an SQL query before it is run. params are the values of the bound template parameters
+// and views are the query views which can be used as tables, columns of views are not checked.
+func (s *Schema) Validate(query string, params map[string]string, views []string) (resp api.ValidateQueryResponse) {
+	resp = newValidateQueryResponse()
+	defer func() {
+		resp.Valid = isValid(resp.Issues)
+	}()
+
+	rendered, parameters, issues := renderTemplate(query, params)
+	resp.Parameters = parameters
+	resp.Issues = append(resp.Issues, issues...)
+	if rendered == nil {
+		return resp
+	}
+
+	tree, err := pg_query.ParseToJSON(*rendered)
+	if err != nil {
+		issue := api.QueryValidationIssue{
+			Severity: api.QueryValidationSeverityError,
+			Code:     api.QueryValidationCodeSyntaxError,
+			Message:  err.Error(),
+		}
+		var parseErr *pgQueryParser.Error
+		if errors.As(err, &parseErr) && parseErr.Cursorpos > 0 {
+			position := parseErr.Cursorpos
+			issue.Position = &position
+		}
+		resp.Issues = append(resp.Issues, issue)
+		return resp
+	}
+
+	var parsed struct {
+		Stmts []struct {
+			Stmt map[string]any `json:"stmt"`
+		} `json:"stmts"`
+	}
+	if err := json.Unmarshal([]byte(tree), &parsed); err != nil {
+		resp.Issues = append(resp.Issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeSyntaxError, nil, "failed to read the parsed query: %v", err))
+		return resp
+	}
+	if len(parsed.Stmts) > 1 {
+		resp.Issues = append(resp.Issues, newIssue(api.QueryValidationSeverityWarning, api.QueryValidationCodeMultipleStatements, nil,
+			"query has %d statements, only a single statement is expected", len(parsed.Stmts)))
+	}
+
+	if len(parsed.Stmts) == 1 {
+		if selectStmt, ok := parsed.Stmts[0].Stmt["SelectStmt"].(map[string]any); ok {
+			if columns := outputColumns(selectStmt); columns != nil {
+				resp.Columns = columns
+			}
+		}
+	}
+
+	a := newAnalysis()
+	for _, stmt := range parsed.Stmts {
+		for kind := range stmt.Stmt {
+			if kind != "SelectStmt" {
+				resp.Issues = append(resp.Issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeUnsupportedStatement, nil,
+					"%s statements are not supported, queries are read only", strings.TrimSuffix(kind, "Stmt")))
+			}
+		}
+		a.walk(stmt.Stmt)
+	}
+	resp.Issues = append(resp.Issues, s.check(a, views, &resp)...)
+	return resp
+}
+
+// ValidateTemplate only checks the template parameters of a query, it is used for queries which are not SQL
+func ValidateTemplate(query string, params map[string]string) api.ValidateQueryResponse {
+	resp := newValidateQueryResponse()
+	_, parameters, issues := renderTemplate(query, params)
+	resp.Parameters = parameters
+	resp.Issues = append(resp.Issues, issues...)
+	resp.Valid = isValid(resp.Issues)
+	return resp
+}
+
+func newValidateQueryResponse() api.ValidateQueryResponse {
+	return api.ValidateQueryResponse{
+		Issues:     []api.QueryValidationIssue{},
+		Parameters: []string{},
+		Tables:     []string{},
+		Connectors: []source.Type{},
+		Columns:    []string{},
+	}
+}
+
+func isValid(issues []api.QueryValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == api.QueryValidationSeverityError {
+			return false
+		}
+	}
+	return true
+}
+
+func newIssue(severity api.QueryValidationSeverity, code api.QueryValidationCode, position *int, format string, args ...any) api.QueryValidationIssue {
+	return api.QueryValidationIssue{
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Position: position,
+	}
+}
+
+// renderTemplate fills in the template parameters of the query, unbound parameters are reported and replaced by NULL.
+// The rendered query is nil if the template is invalid.
+func renderTemplate(query string, params map[string]string) (*string, []string, []api.QueryValidationIssue) {
+	tmpl, err := template.New("query").Parse(query)
+	if err != nil {
+		return nil, []string{}, []api.QueryValidationIssue{
+			newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeInvalidTemplate, nil, "%v", err),
+		}
+	}
+
+	used := make(map[string]bool)
+	if tmpl.Tree != nil {
+		collectTemplateFields(tmpl.Tree.Root, used)
+	}
+	parameters := make([]string, 0, len(used))
+	for name := range used {
+		parameters = append(parameters, name)
+	}
+	sort.Strings(parameters)
+
+	var issues []api.QueryValidationIssue
+	values := make(map[string]string, len(params)+len(parameters))
+	for k, v := range params {
+		values[k] = v
+	}
+	for _, name := range parameters {
+		if _, ok := values[name]; !ok {
+			issues = append(issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeUnboundParameter, nil,
+				"parameter %s has no value, it is not a query parameter of the workspace", name))
+			values[name] = unboundParameterValue
+		}
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, values); err != nil {
+		return nil, parameters, append(issues, newIssue(api.QueryValidationSeverityError, api.QueryValidationCodeInvalidTemplate, nil, "%v", err))
+	}
+	rendered := out.String()
+	return &rendered, parameters, issues
+}
+
+// collectTemplateFields collects the names of the {{.name}} fields of the template
+func collectTemplateFields(node parse.Node, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectTemplateFields(child, fields)
+		}
+	case *parse.ActionNode:
+		collectTemplateFields(n.Pipe, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				collectTemplateFields(arg, fields)
+			}
+		}
+	case *parse.FieldNode:
+		if len(n.Ident) > 0 {
+			fields[n.Ident[0]] = true
+		}
+	case *parse.IfNode:
+		collectTemplateFields(n.Pipe, fields)
+		collectTemplateFields(n.List, fields)
+		collectTemplateFields(n.ElseList, fields)
+	case *parse.RangeNode:
+		collectTemplateFields(n.Pipe, fields)
+		collectTemplateFields(n.List, fields)
+		collectTemplateFields(n.ElseList, fields)
+	case *parse.WithNode:
+		collectTemplateFields(n.Pipe, fields)
+		collectTemplateFields(n.List, fields)
+		collectTemplateFields(n.ElseList, fields)
+	}
+}
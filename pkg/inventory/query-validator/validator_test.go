@@ -0,0 +1,190 @@
+package query_validator
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// newTestSchema returns a schema with a couple of plugin tables
+func newTestSchema() *Schema {
+	s := NewSchema()
+	s.AddTables(source.CloudAWS, map[string]*plugin.Table{
+		"aws_ebs_volume": testTable("arn", "title", "encrypted", "kaytu_account_id", "tags"),
+		"aws_iam_user":   testTable("arn", "name", "mfa_enabled", "kaytu_account_id"),
+	})
+	s.AddTables(source.Nil, map[string]*plugin.Table{
+		"kaytu_resources": testTable("kaytu_id", "resource_type", "connection_id"),
+	})
+	return s
+}
+
+func testTable(columns ...string) *plugin.Table {
+	table := &plugin.Table{}
+	for _, column := range columns {
+		table.Columns = append(table.Columns, &plugin.Column{Name: column, Type: proto.ColumnType_STRING})
+	}
+	return table
+}
+
+func TestValidate_Columns(t *testing.T) {
+	s := newTestSchema()
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "named and plain columns",
+			query: "select arn as resource, kaytu_account_id, 'ok' as status, title as reason from aws_ebs_volume",
+			want:  []string{"resource", "kaytu_account_id", "status", "reason"},
+		},
+		{
+			name:  "qualified column",
+			query: "select v.arn from aws_ebs_volume v",
+			want:  []string{"arn"},
+		},
+		{
+			name:  "functions, casts and expressions",
+			query: "select count(*), arn::text, 1::int, coalesce(title, ''), case when encrypted then 1 end, 1 + 1 from aws_ebs_volume",
+			want:  []string{"count", "arn", "int4", "coalesce", "case", "?column?"},
+		},
+		{
+			name:  "set operations are named after the first query",
+			query: "select arn as resource from aws_ebs_volume union select title from aws_ebs_volume",
+			want:  []string{"resource"},
+		},
+		{
+			name:  "star is not known",
+			query: "select * from aws_ebs_volume",
+			want:  []string{},
+		},
+		{
+			name:  "qualified star is not known",
+			query: "select v.*, arn from aws_ebs_volume v",
+			want:  []string{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := s.Validate(tc.query, nil, nil)
+			assert.True(t, resp.Valid, "unexpected issues %v", resp.Issues)
+			assert.Equal(t, tc.want, resp.Columns)
+		})
+	}
+}
+
+func position(p int) *int {
+	return &p
+}
+
+func TestValidate_Parameters(t *testing.T) {
+	s := newTestSchema()
+	query := "select arn from aws_ebs_volume where kaytu_account_id = '{{.awsAccountId}}' and title = '{{.title}}'"
+
+	t.Run("bound parameters", func(t *testing.T) {
+		resp := s.Validate(query, map[string]string{"awsAccountId": "123", "title": "x", "unused": "y"}, nil)
+		assert.True(t, resp.Valid, "unexpected issues %v", resp.Issues)
+		assert.Empty(t, resp.Issues)
+		assert.Equal(t, []string{"awsAccountId", "title"}, resp.Parameters)
+	})
+
+	t.Run("unbound parameters", func(t *testing.T) {
+		resp := s.Validate(query, map[string]string{"title": "x"}, nil)
+		assert.False(t, resp.Valid)
+		assert.Equal(t, []string{"awsAccountId", "title"}, resp.Parameters)
+		assert.Equal(t, []api.QueryValidationIssue{{
+			Severity: api.QueryValidationSeverityError,
+			Code:     api.QueryValidationCodeUnboundParameter,
+			Message:  "parameter awsAccountId has no value, it is not a query parameter of the workspace",
+		}}, resp.Issues)
+		// the rest of the query is still checked
+		assert.Equal(t, []string{"aws_ebs_volume"}, resp.Tables)
+	})
+
+	t.Run("parameters in template actions", func(t *testing.T) {
+		resp := s.Validate("select arn from aws_ebs_volume {{if .onlyEncrypted}}where encrypted{{end}}{{with .title}} and title = '{{.}}'{{end}}", map[string]string{"onlyEncrypted": "", "title": ""}, nil)
+		assert.True(t, resp.Valid, "unexpected issues %v", resp.Issues)
+		assert.Equal(t, []string{"onlyEncrypted", "title"}, resp.Parameters)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		resp := s.Validate("select arn from aws_ebs_volume where title = '{{.title'", nil, nil)
+		assert.False(t, resp.Valid)
+		require.Len(t, resp.Issues, 1)
+		assert.Equal(t, api.QueryValidationCodeInvalidTemplate, resp.Issues[0].Code)
+		assert.Empty(t, resp.Parameters)
+		assert.Empty(t, resp.Tables)
+	})
+}
+
+func TestValidate_SyntaxError(t *testing.T) {
+	resp := newTestSchema().Validate("select arn form aws_ebs_volume", nil, nil)
+	assert.False(t, resp.Valid)
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, api.QueryValidationCodeSyntaxError, resp.Issues[0].Code)
+	assert.Equal(t, `syntax error at or near "aws_ebs_volume"`, resp.Issues[0].Message)
+	assert.Equal(t, position(17), resp.Issues[0].Position)
+	assert.Empty(t, resp.Columns)
+}
+
+func TestValidate_Statements(t *testing.T) {
+	s := newTestSchema()
+
+	t.Run("write statements are not supported", func(t *testing.T) {
+		resp := s.Validate("delete from aws_ebs_volume where encrypted", nil, nil)
+		assert.False(t, resp.Valid)
+		assert.Equal(t, []api.QueryValidationIssue{{
+			Severity: api.QueryValidationSeverityError,
+			Code:     api.QueryValidationCodeUnsupportedStatement,
+			Message:  "Delete statements are not supported, queries are read only",
+		}}, resp.Issues)
+		assert.Equal(t, []string{"aws_ebs_volume"}, resp.Tables)
+	})
+
+	t.Run("tables of write statements are checked", func(t *testing.T) {
+		resp := s.Validate("update aws_iam_user set name = 'x' where region = 'y'", nil, nil)
+		assert.False(t, resp.Valid)
+		require.Len(t, resp.Issues, 2)
+		assert.Equal(t, api.QueryValidationCodeUnsupportedStatement, resp.Issues[0].Code)
+		assert.Equal(t, "column region does not exist in the tables of the query", resp.Issues[1].Message)
+	})
+
+	t.Run("multiple statements are a warning", func(t *testing.T) {
+		resp := s.Validate("select arn from aws_ebs_volume; select name from aws_iam_user", nil, nil)
+		assert.True(t, resp.Valid)
+		assert.Equal(t, []api.QueryValidationIssue{{
+			Severity: api.QueryValidationSeverityWarning,
+			Code:     api.QueryValidationCodeMultipleStatements,
+			Message:  "query has 2 statements, only a single statement is expected",
+		}}, resp.Issues)
+		// columns are only known for a single statement
+		assert.Empty(t, resp.Columns)
+		assert.Equal(t, []string{"aws_ebs_volume", "aws_iam_user"}, resp.Tables)
+	})
+}
+
+func TestValidateTemplate(t *testing.T) {
+	resp := ValidateTemplate(`{"query": {"term": {"account": "{{.awsAccountId}}"}}, "size": {{.size}}}`, map[string]string{"size": "10"})
+	assert.False(t, resp.Valid)
+	assert.Equal(t, []string{"awsAccountId", "size"}, resp.Parameters)
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, api.QueryValidationCodeUnboundParameter, resp.Issues[0].Code)
+	// the query is not parsed as SQL
+	assert.Empty(t, resp.Tables)
+
+	resp = ValidateTemplate("not sql at all", nil)
+	assert.True(t, resp.Valid)
+	assert.Empty(t, resp.Issues)
+	assert.Empty(t, resp.Parameters)
+
+	resp = ValidateTemplate("{{end}}", nil)
+	assert.False(t, resp.Valid)
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, api.QueryValidationCodeInvalidTemplate, resp.Issues[0].Code)
+}
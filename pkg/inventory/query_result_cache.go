@@ -0,0 +1,429 @@
+package inventory
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaytu-io/kaytu-util/pkg/ticker"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	QueryResultCacheTTL              = time.Hour
+	QueryResultCacheEvictionInterval = 5 * time.Minute
+	QueryResultCacheMaxRows          = 1000000
+	QueryResultCacheUserQuota        = 1 << 30 // Bytes of unexpired results a user can keep
+
+	queryResultIndexStride = 1000
+	queryResultFilePattern = "run-*.jsonl"
+)
+
+var (
+	errQueryResultNotFound      = errors.New("query result not found")
+	errQueryResultQuotaExceeded = errors.New("query result quota exceeded")
+)
+
+// QueryResult is a query run whose rows are kept in FileName on the query result cache dir as one JSON
+// array per line, Offsets holds the byte offset of every queryResultIndexStride-th row so pages can be
+// read without scanning the whole file.
+type QueryResult struct {
+	RunID     string         `gorm:"primaryKey"`
+	OwnerID   string         `gorm:"index"`
+	Headers   pq.StringArray `gorm:"type:text[]"`
+	RowCount  int
+	Truncated bool
+	FileName  string
+	Size      int64
+	Offsets   pq.Int64Array `gorm:"type:bigint[]"`
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+
+	path string `gorm:"-:all"`
+}
+
+// queryResultIndex keeps the results written by every replica, implemented by Database
+type queryResultIndex interface {
+	CreateQueryResult(result *QueryResult, quota int64) error
+	GetQueryResult(runID string) (*QueryResult, error)
+	DeleteQueryResult(runID string) (*QueryResult, error)
+	DeleteExpiredQueryResults(now time.Time) ([]QueryResult, error)
+	GetQueryResultsSize(ownerID string, now time.Time) (int64, error)
+	ListQueryResultFileNames() ([]string, error)
+}
+
+// queryResultCache keeps query results on the cache dir, which has to be shared by every replica
+// (e.g. a ReadWriteMany volume) since a result is read by whichever replica gets the next request.
+type queryResultCache struct {
+	dir    string
+	quota  int64
+	index  queryResultIndex
+	logger *zap.Logger
+}
+
+func newQueryResultCache(dir string, index queryResultIndex, logger *zap.Logger) (*queryResultCache, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "query-results")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create query result cache dir: %w", err)
+	}
+
+	return &queryResultCache{
+		dir:    dir,
+		quota:  QueryResultCacheUserQuota,
+		index:  index,
+		logger: logger,
+	}, nil
+}
+
+func (c *queryResultCache) RunEviction() {
+	t := ticker.NewTicker(QueryResultCacheEvictionInterval, time.Second*10)
+	defer t.Stop()
+
+	for ; ; <-t.C {
+		c.evictExpired()
+	}
+}
+
+func (c *queryResultCache) evictExpired() {
+	// every replica evicts, the index hands each expired result to only one of them
+	expired, err := c.index.DeleteExpiredQueryResults(time.Now())
+	if err != nil {
+		c.logger.Error("failed to delete expired query results", zap.Error(err))
+		return
+	}
+	for _, result := range expired {
+		if err := os.Remove(c.resultPath(result.FileName)); err != nil && !os.IsNotExist(err) {
+			c.logger.Error("failed to remove expired query result", zap.String("runID", result.RunID), zap.Error(err))
+		}
+	}
+
+	c.removeOrphans()
+}
+
+// removeOrphans removes the files of results which were never committed, e.g. because their replica was stopped
+// while writing them. Files younger than the TTL are kept since their writer may still be running.
+func (c *queryResultCache) removeOrphans() {
+	paths, err := filepath.Glob(filepath.Join(c.dir, queryResultFilePattern))
+	if err != nil {
+		c.logger.Error("failed to list query result files", zap.Error(err))
+		return
+	}
+	if len(paths) == 0 {
+		return
+	}
+	fileNames, err := c.index.ListQueryResultFileNames()
+	if err != nil {
+		c.logger.Error("failed to list indexed query result files", zap.Error(err))
+		return
+	}
+	indexed := make(map[string]bool, len(fileNames))
+	for _, fileName := range fileNames {
+		indexed[fileName] = true
+	}
+
+	for _, path := range paths {
+		if indexed[filepath.Base(path)] {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || time.Since(info.ModTime()) < QueryResultCacheTTL {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			c.logger.Warn("failed to remove orphan query result", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
+
+func (c *queryResultCache) resultPath(fileName string) string {
+	return filepath.Join(c.dir, filepath.Base(fileName))
+}
+
+// Get returns the result with the given run id, nil if it does not exist or is expired
+func (c *queryResultCache) Get(runID string) (*QueryResult, error) {
+	result, err := c.index.GetQueryResult(runID)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil || time.Now().After(result.ExpiresAt) {
+		return nil, nil
+	}
+	result.path = c.resultPath(result.FileName)
+	return result, nil
+}
+
+func (c *queryResultCache) Delete(runID string) error {
+	result, err := c.index.DeleteQueryResult(runID)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	if err := os.Remove(c.resultPath(result.FileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// NewWriter starts a new result, rows are written to disk as they are added. It fails with
+// errQueryResultQuotaExceeded if the unexpired results of the owner already use their quota.
+// The quota is checked again on commit since other runs of the owner may be written meanwhile.
+func (c *queryResultCache) NewWriter(ownerID string, headers []string) (*queryResultWriter, error) {
+	used, err := c.index.GetQueryResultsSize(ownerID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if used >= c.quota {
+		return nil, errQueryResultQuotaExceeded
+	}
+
+	file, err := os.CreateTemp(c.dir, queryResultFilePattern)
+	if err != nil {
+		return nil, err
+	}
+	return &queryResultWriter{
+		cache:     c,
+		file:      file,
+		buf:       bufio.NewWriter(file),
+		available: c.quota - used,
+		result: &QueryResult{
+			RunID:    uuid.New().String(),
+			OwnerID:  ownerID,
+			Headers:  headers,
+			FileName: filepath.Base(file.Name()),
+			path:     file.Name(),
+		},
+	}, nil
+}
+
+type queryResultWriter struct {
+	cache     *queryResultCache
+	file      *os.File
+	buf       *bufio.Writer
+	written   int64
+	available int64
+	full      bool
+	result    *QueryResult
+}
+
+// Full tells if the result reached QueryResultCacheMaxRows or the remaining quota of its owner
+func (w *queryResultWriter) Full() bool {
+	return w.result.RowCount >= QueryResultCacheMaxRows || w.full
+}
+
+func (w *queryResultWriter) Write(row []any) error {
+	line, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if w.written+int64(len(line)) > w.available {
+		// the row is dropped and the result is committed as truncated
+		w.full = true
+		return nil
+	}
+	if w.result.RowCount%queryResultIndexStride == 0 {
+		w.result.Offsets = append(w.result.Offsets, w.written)
+	}
+	n, err := w.buf.Write(line)
+	w.written += int64(n)
+	if err != nil {
+		return err
+	}
+	w.result.RowCount++
+	return nil
+}
+
+// Commit makes the result available to readers, truncated marks results that were cut when the writer was full.
+// It fails with errQueryResultQuotaExceeded if results of the owner committed meanwhile leave no room for it.
+func (w *queryResultWriter) Commit(truncated bool) (*QueryResult, error) {
+	if err := w.buf.Flush(); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	if err := w.file.Close(); err != nil {
+		w.Abort()
+		return nil, err
+	}
+
+	w.result.Truncated = truncated || w.full
+	w.result.Size = w.written
+	w.result.CreatedAt = time.Now()
+	w.result.ExpiresAt = w.result.CreatedAt.Add(QueryResultCacheTTL)
+
+	if err := w.cache.index.CreateQueryResult(w.result, w.cache.quota); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	return w.result, nil
+}
+
+func (w *queryResultWriter) Abort() {
+	_ = w.file.Close()
+	if err := os.Remove(w.result.path); err != nil && !os.IsNotExist(err) {
+		w.cache.logger.Error("failed to remove aborted query result", zap.String("path", w.result.path), zap.Error(err))
+	}
+}
+
+// Page reads up to size rows starting at row from
+func (r *QueryResult) Page(from, size int) ([][]any, error) {
+	rows := make([][]any, 0)
+	if from >= r.RowCount || size <= 0 {
+		return rows, nil
+	}
+
+	file, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(r.Offsets[from/queryResultIndexStride], io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(file)
+	for skip := from % queryResultIndexStride; skip > 0; skip-- {
+		if _, err := reader.ReadBytes('\n'); err != nil {
+			return nil, err
+		}
+	}
+	for len(rows) < size && from+len(rows) < r.RowCount {
+		row, err := readQueryResultRow(reader)
+		if err != nil {
+			return nil, err
+		}
+		record := make([]any, len(row))
+		for idx, value := range row {
+			record[idx] = value
+		}
+		rows = append(rows, record)
+	}
+	return rows, nil
+}
+
+// Each calls f for every row of the result in order, reading the file sequentially
+func (r *QueryResult) Each(f func(row []json.RawMessage) error) error {
+	file, err := r.open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for i := 0; i < r.RowCount; i++ {
+		row, err := readQueryResultRow(reader)
+		if err != nil {
+			return err
+		}
+		if err := f(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *QueryResult) open() (*os.File, error) {
+	file, err := os.Open(r.path)
+	if os.IsNotExist(err) {
+		return nil, errQueryResultNotFound
+	}
+	return file, err
+}
+
+func readQueryResultRow(reader *bufio.Reader) ([]json.RawMessage, error) {
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	var row []json.RawMessage
+	if err := json.Unmarshal(line, &row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// CreateQueryResult adds the result unless the unexpired results of its owner would go over the quota. The owner is
+// locked until the transaction ends, so results committed by several replicas at once are summed one after the other.
+func (db Database) CreateQueryResult(result *QueryResult, quota int64) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", result.OwnerID).Error; err != nil {
+			return err
+		}
+		var used int64
+		err := tx.Model(&QueryResult{}).Select("COALESCE(SUM(size), 0)").
+			Where("owner_id = ? AND expires_at >= ?", result.OwnerID, result.CreatedAt).Scan(&used).Error
+		if err != nil {
+			return err
+		}
+		if used+result.Size > quota {
+			return errQueryResultQuotaExceeded
+		}
+		return tx.Create(result).Error
+	})
+}
+
+func (db Database) GetQueryResult(runID string) (*QueryResult, error) {
+	var result QueryResult
+	tx := db.orm.Model(&QueryResult{}).Where("run_id = ?", runID).First(&result)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &result, nil
+}
+
+// DeleteQueryResult returns the deleted result, nil if it did not exist
+func (db Database) DeleteQueryResult(runID string) (*QueryResult, error) {
+	var results []QueryResult
+	tx := db.orm.Clauses(clause.Returning{}).Where("run_id = ?", runID).Delete(&results)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &results[0], nil
+}
+
+func (db Database) DeleteExpiredQueryResults(now time.Time) ([]QueryResult, error) {
+	var results []QueryResult
+	tx := db.orm.Clauses(clause.Returning{}).Where("expires_at < ?", now).Delete(&results)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return results, nil
+}
+
+// GetQueryResultsSize returns the bytes used by the unexpired results of the owner
+func (db Database) GetQueryResultsSize(ownerID string, now time.Time) (int64, error) {
+	var size int64
+	tx := db.orm.Model(&QueryResult{}).Select("COALESCE(SUM(size), 0)").
+		Where("owner_id = ? AND expires_at >= ?", ownerID, now).Scan(&size)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return size, nil
+}
+
+func (db Database) ListQueryResultFileNames() ([]string, error) {
+	var fileNames []string
+	tx := db.orm.Model(&QueryResult{}).Pluck("file_name", &fileNames)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return fileNames, nil
+}
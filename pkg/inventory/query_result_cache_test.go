@@ -0,0 +1,289 @@
+package inventory
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testQueryResultIndex keeps the index in memory, shared by the caches of the test like the database is by the replicas
+type testQueryResultIndex struct {
+	mu      sync.Mutex
+	results map[string]QueryResult
+}
+
+func newTestQueryResultIndex() *testQueryResultIndex {
+	return &testQueryResultIndex{results: map[string]QueryResult{}}
+}
+
+func (i *testQueryResultIndex) CreateQueryResult(result *QueryResult, quota int64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	used := result.Size
+	for _, other := range i.results {
+		if other.OwnerID == result.OwnerID && !other.ExpiresAt.Before(result.CreatedAt) {
+			used += other.Size
+		}
+	}
+	if used > quota {
+		return errQueryResultQuotaExceeded
+	}
+	i.results[result.RunID] = *result
+	return nil
+}
+
+func (i *testQueryResultIndex) GetQueryResult(runID string) (*QueryResult, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	result, ok := i.results[runID]
+	if !ok {
+		return nil, nil
+	}
+	result.path = ""
+	return &result, nil
+}
+
+func (i *testQueryResultIndex) DeleteQueryResult(runID string) (*QueryResult, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	result, ok := i.results[runID]
+	if !ok {
+		return nil, nil
+	}
+	delete(i.results, runID)
+	return &result, nil
+}
+
+func (i *testQueryResultIndex) DeleteExpiredQueryResults(now time.Time) ([]QueryResult, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var expired []QueryResult
+	for runID, result := range i.results {
+		if result.ExpiresAt.Before(now) {
+			expired = append(expired, result)
+			delete(i.results, runID)
+		}
+	}
+	return expired, nil
+}
+
+func (i *testQueryResultIndex) GetQueryResultsSize(ownerID string, now time.Time) (int64, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var size int64
+	for _, result := range i.results {
+		if result.OwnerID == ownerID && !result.ExpiresAt.Before(now) {
+			size += result.Size
+		}
+	}
+	return size, nil
+}
+
+func (i *testQueryResultIndex) ListQueryResultFileNames() ([]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var fileNames []string
+	for _, result := range i.results {
+		fileNames = append(fileNames, result.FileName)
+	}
+	return fileNames, nil
+}
+
+func newTestQueryResultCache(t *testing.T, dir string, index queryResultIndex) *queryResultCache {
+	t.Helper()
+	cache, err := newQueryResultCache(dir, index, zap.NewNop())
+	require.NoError(t, err)
+	return cache
+}
+
+func writeTestQueryResult(t *testing.T, cache *queryResultCache, ownerID string, rows int) *QueryResult {
+	t.Helper()
+	writer, err := cache.NewWriter(ownerID, []string{"id", "name"})
+	require.NoError(t, err)
+	truncated := false
+	for i := 0; i < rows; i++ {
+		if writer.Full() {
+			truncated = true
+			break
+		}
+		require.NoError(t, writer.Write([]any{i, "resource"}))
+	}
+	result, err := writer.Commit(truncated)
+	require.NoError(t, err)
+	return result
+}
+
+func pageIDs(t *testing.T, rows [][]any) []int {
+	t.Helper()
+	ids := make([]int, 0, len(rows))
+	for _, row := range rows {
+		raw, ok := row[0].(json.RawMessage)
+		require.True(t, ok)
+		var id int
+		require.NoError(t, json.Unmarshal(raw, &id))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestQueryResultCache_SharedByReplicas(t *testing.T) {
+	dir := t.TempDir()
+	index := newTestQueryResultIndex()
+	writerReplica := newTestQueryResultCache(t, dir, index)
+	readerReplica := newTestQueryResultCache(t, dir, index)
+
+	written := writeTestQueryResult(t, writerReplica, "user-1", 2500)
+	assert.Equal(t, 2500, written.RowCount)
+	assert.False(t, written.Truncated)
+	assert.Len(t, written.Offsets, 3)
+
+	result, err := readerReplica.Get(written.RunID)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "user-1", result.OwnerID)
+	assert.Equal(t, []string{"id", "name"}, []string(result.Headers))
+
+	t.Run("pages across the offset index", func(t *testing.T) {
+		rows, err := result.Page(998, 4)
+		require.NoError(t, err)
+		assert.Equal(t, []int{998, 999, 1000, 1001}, pageIDs(t, rows))
+
+		rows, err = result.Page(2498, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{2498, 2499}, pageIDs(t, rows))
+
+		rows, err = result.Page(2500, 10)
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+
+	t.Run("every row is read in order", func(t *testing.T) {
+		count := 0
+		require.NoError(t, result.Each(func(row []json.RawMessage) error {
+			var id int
+			require.NoError(t, json.Unmarshal(row[0], &id))
+			assert.Equal(t, count, id)
+			count++
+			return nil
+		}))
+		assert.Equal(t, 2500, count)
+	})
+
+	t.Run("deleted by another replica", func(t *testing.T) {
+		require.NoError(t, readerReplica.Delete(written.RunID))
+		result, err := writerReplica.Get(written.RunID)
+		require.NoError(t, err)
+		assert.Nil(t, result)
+		assert.NoFileExists(t, filepath.Join(dir, written.FileName))
+		assert.NoError(t, readerReplica.Delete(written.RunID))
+	})
+}
+
+func TestQueryResultCache_Quota(t *testing.T) {
+	index := newTestQueryResultIndex()
+	cache := newTestQueryResultCache(t, t.TempDir(), index)
+	// each row is written as [n,"resource"] plus a new line
+	cache.quota = 300
+
+	first := writeTestQueryResult(t, cache, "user-1", 10)
+	assert.False(t, first.Truncated)
+	assert.Equal(t, int64(len(`[0,"resource"]`)+1)*10, first.Size)
+
+	// rows 0 to 9 fill the quota exactly, row 10 does not fit
+	second := writeTestQueryResult(t, cache, "user-1", 100)
+	assert.True(t, second.Truncated)
+	assert.Equal(t, 10, second.RowCount)
+	assert.Equal(t, cache.quota, first.Size+second.Size)
+
+	_, err := cache.NewWriter("user-1", []string{"id"})
+	assert.ErrorIs(t, err, errQueryResultQuotaExceeded)
+
+	t.Run("quota is per user", func(t *testing.T) {
+		result := writeTestQueryResult(t, cache, "user-2", 10)
+		assert.False(t, result.Truncated)
+	})
+
+	t.Run("deleted and expired results free the quota", func(t *testing.T) {
+		require.NoError(t, cache.Delete(second.RunID))
+		expired := index.results[first.RunID]
+		expired.ExpiresAt = time.Now().Add(-time.Minute)
+		index.results[first.RunID] = expired
+
+		writer, err := cache.NewWriter("user-1", []string{"id"})
+		require.NoError(t, err)
+		writer.Abort()
+	})
+}
+
+func TestQueryResultCache_QuotaOnCommit(t *testing.T) {
+	index := newTestQueryResultIndex()
+	// replicas writing results of the same user at the same time
+	caches := []*queryResultCache{newTestQueryResultCache(t, t.TempDir(), index), newTestQueryResultCache(t, t.TempDir(), index)}
+	var writers []*queryResultWriter
+	for _, cache := range caches {
+		cache.quota = 300
+		writer, err := cache.NewWriter("user-1", []string{"id", "name"})
+		require.NoError(t, err)
+		for i := 0; i < 15; i++ {
+			require.NoError(t, writer.Write([]any{i, "resource"}))
+		}
+		writers = append(writers, writer)
+	}
+
+	first, err := writers[0].Commit(false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(230), first.Size)
+
+	_, err = writers[1].Commit(false)
+	assert.ErrorIs(t, err, errQueryResultQuotaExceeded)
+	assert.NoFileExists(t, writers[1].result.path)
+	got, err := caches[1].Get(writers[1].result.RunID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	t.Run("results that fit next to each other are both kept", func(t *testing.T) {
+		result := writeTestQueryResult(t, caches[1], "user-1", 4)
+		assert.Equal(t, int64(290), first.Size+result.Size)
+	})
+}
+
+func TestQueryResultCache_Eviction(t *testing.T) {
+	dir := t.TempDir()
+	index := newTestQueryResultIndex()
+	cache := newTestQueryResultCache(t, dir, index)
+
+	kept := writeTestQueryResult(t, cache, "user-1", 5)
+	expired := writeTestQueryResult(t, cache, "user-1", 5)
+	result := index.results[expired.RunID]
+	result.ExpiresAt = time.Now().Add(-time.Second)
+	index.results[expired.RunID] = result
+
+	// files of results which were never committed
+	old := filepath.Join(dir, "run-old.jsonl")
+	require.NoError(t, os.WriteFile(old, []byte("[1]\n"), 0o600))
+	require.NoError(t, os.Chtimes(old, time.Now().Add(-2*QueryResultCacheTTL), time.Now().Add(-2*QueryResultCacheTTL)))
+	inProgress, err := cache.NewWriter("user-1", []string{"id"})
+	require.NoError(t, err)
+	defer inProgress.Abort()
+
+	cache.evictExpired()
+
+	got, err := cache.Get(expired.RunID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.NoFileExists(t, filepath.Join(dir, expired.FileName))
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, inProgress.result.path)
+
+	got, err = cache.Get(kept.RunID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.FileExists(t, filepath.Join(dir, kept.FileName))
+}
@@ -0,0 +1,183 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
+	"github.com/kaytu-io/open-governance/pkg/inventory/internal"
+)
+
+// queryResultExporter writes the rows of a cached query result in a download format
+type queryResultExporter interface {
+	WriteRow(row []json.RawMessage) error
+	Close() error
+}
+
+func newQueryResultExporter(format inventoryApi.QueryResultFormat, w io.Writer, headers []string) (queryResultExporter, error) {
+	switch format {
+	case inventoryApi.QueryResultFormatCSV:
+		return newCSVQueryResultExporter(w, headers)
+	case inventoryApi.QueryResultFormatJSONL:
+		return newJSONLQueryResultExporter(w, headers)
+	case inventoryApi.QueryResultFormatParquet:
+		return &parquetQueryResultExporter{
+			writer: internal.NewParquetWriter(w, uniqueColumnNames(headers), 0),
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid format: %s", format)
+	}
+}
+
+func queryResultContentType(format inventoryApi.QueryResultFormat) string {
+	switch format {
+	case inventoryApi.QueryResultFormatCSV:
+		return "text/csv"
+	case inventoryApi.QueryResultFormatJSONL:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// queryResultCell converts a cell to its text form, strings are unquoted and
+// other values keep their JSON representation. ok is false for nulls.
+func queryResultCell(value json.RawMessage) (cell string, ok bool, err error) {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 || bytes.Equal(value, []byte("null")) {
+		return "", false, nil
+	}
+	if value[0] == '"' {
+		if err := json.Unmarshal(value, &cell); err != nil {
+			return "", false, err
+		}
+		return cell, true, nil
+	}
+	return string(value), true, nil
+}
+
+type csvQueryResultExporter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVQueryResultExporter(w io.Writer, headers []string) (*csvQueryResultExporter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return nil, err
+	}
+	return &csvQueryResultExporter{
+		writer: writer,
+		record: make([]string, len(headers)),
+	}, nil
+}
+
+func (e *csvQueryResultExporter) WriteRow(row []json.RawMessage) error {
+	for idx := range e.record {
+		e.record[idx] = ""
+		if idx >= len(row) {
+			continue
+		}
+		cell, _, err := queryResultCell(row[idx])
+		if err != nil {
+			return err
+		}
+		e.record[idx] = cell
+	}
+	return e.writer.Write(e.record)
+}
+
+func (e *csvQueryResultExporter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type jsonlQueryResultExporter struct {
+	w    io.Writer
+	keys [][]byte
+	line bytes.Buffer
+}
+
+func newJSONLQueryResultExporter(w io.Writer, headers []string) (*jsonlQueryResultExporter, error) {
+	keys := make([][]byte, 0, len(headers))
+	for _, header := range headers {
+		key, err := json.Marshal(header)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return &jsonlQueryResultExporter{
+		w:    w,
+		keys: keys,
+	}, nil
+}
+
+// WriteRow writes the row as an object, keeping the column order of the query
+func (e *jsonlQueryResultExporter) WriteRow(row []json.RawMessage) error {
+	e.line.Reset()
+	e.line.WriteByte('{')
+	for idx, key := range e.keys {
+		if idx > 0 {
+			e.line.WriteByte(',')
+		}
+		e.line.Write(key)
+		e.line.WriteByte(':')
+		if idx < len(row) {
+			e.line.Write(row[idx])
+		} else {
+			e.line.WriteString("null")
+		}
+	}
+	e.line.WriteString("}\n")
+	_, err := e.w.Write(e.line.Bytes())
+	return err
+}
+
+func (e *jsonlQueryResultExporter) Close() error {
+	return nil
+}
+
+type parquetQueryResultExporter struct {
+	writer *internal.ParquetWriter
+}
+
+func (e *parquetQueryResultExporter) WriteRow(row []json.RawMessage) error {
+	record := make([]*string, len(e.writer.Columns()))
+	for idx := range record {
+		if idx >= len(row) {
+			continue
+		}
+		cell, ok, err := queryResultCell(row[idx])
+		if err != nil {
+			return err
+		}
+		if ok {
+			record[idx] = &cell
+		}
+	}
+	return e.writer.Write(record)
+}
+
+func (e *parquetQueryResultExporter) Close() error {
+	return e.writer.Close()
+}
+
+// uniqueColumnNames suffixes repeated headers, parquet schemas can not have duplicate column names
+func uniqueColumnNames(headers []string) []string {
+	seen := make(map[string]int)
+	columns := make([]string, 0, len(headers))
+	for _, header := range headers {
+		name := header
+		for seen[name] > 0 {
+			seen[header]++
+			name = fmt.Sprintf("%s_%d", header, seen[header])
+		}
+		seen[name]++
+		columns = append(columns, name)
+	}
+	return columns
+}
@@ -0,0 +1,273 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"github.com/kaytu-io/kaytu-util/pkg/model"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// SavedQuery is a query authored by a user of the workspace, unlike NamedQuery which is loaded by the migrator
+type SavedQuery struct {
+	ID             string `gorm:"primarykey"`
+	Title          string
+	Description    string
+	OwnerID        string `gorm:"index"`
+	Visibility     api.SavedQueryVisibility
+	SharedWith     pq.StringArray `gorm:"type:text[]"` // Users of the team when visibility is team
+	Connectors     pq.StringArray `gorm:"type:text[]"`
+	QueryToExecute string
+	Engine         string
+	Version        int
+
+	Tags       []SavedQueryTag       `gorm:"foreignKey:SavedQueryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Parameters []SavedQueryParameter `gorm:"foreignKey:SavedQueryID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type SavedQueryTag struct {
+	model.Tag
+	SavedQueryID string `gorm:"primaryKey"`
+}
+
+type SavedQueryParameter struct {
+	SavedQueryID string `gorm:"primaryKey"`
+	Key          string `gorm:"primaryKey"`
+	Required     bool   `gorm:"not null"`
+}
+
+// SavedQueryVersion is a snapshot of a saved query, a new one is kept on every update
+type SavedQueryVersion struct {
+	SavedQueryID   string `gorm:"primaryKey"`
+	Version        int    `gorm:"primaryKey"`
+	Title          string
+	Description    string
+	QueryToExecute string
+	Engine         string
+	Parameters     pgtype.JSONB
+	CreatedBy      string
+	CreatedAt      time.Time
+}
+
+func (q SavedQuery) GetTagsMap() map[string][]string {
+	tagLikeArr := make([]model.TagLike, 0, len(q.Tags))
+	for _, tag := range q.Tags {
+		tagLikeArr = append(tagLikeArr, tag)
+	}
+	return model.GetTagsMap(tagLikeArr)
+}
+
+// IsVisibleTo tells if the user can see and run the query
+func (q SavedQuery) IsVisibleTo(userID string) bool {
+	switch q.Visibility {
+	case api.SavedQueryVisibilityWorkspace:
+		return true
+	case api.SavedQueryVisibilityTeam:
+		for _, member := range q.SharedWith {
+			if member == userID {
+				return true
+			}
+		}
+	}
+	return q.OwnerID == userID
+}
+
+// queryParams returns the parameters to render the query with, the required parameters have to be given
+// and the optional ones which are not given are rendered empty
+func (q SavedQuery) queryParams(given map[string]string) (map[string]string, error) {
+	params := make(map[string]string, len(given)+len(q.Parameters))
+	for key, value := range given {
+		params[key] = value
+	}
+	for _, p := range q.Parameters {
+		if _, ok := params[p.Key]; ok {
+			continue
+		}
+		if p.Required {
+			return nil, fmt.Errorf("required query parameter not found: %s", p.Key)
+		}
+		params[p.Key] = ""
+	}
+	return params, nil
+}
+
+func (q SavedQuery) apiParameters() []api.QueryParameter {
+	parameters := make([]api.QueryParameter, 0, len(q.Parameters))
+	for _, p := range q.Parameters {
+		parameters = append(parameters, api.QueryParameter{Key: p.Key, Required: p.Required})
+	}
+	return parameters
+}
+
+func (q SavedQuery) ToApi() api.SavedQuery {
+	return api.SavedQuery{
+		ID:             q.ID,
+		Title:          q.Title,
+		Description:    q.Description,
+		OwnerID:        q.OwnerID,
+		Visibility:     q.Visibility,
+		SharedWith:     q.SharedWith,
+		Connectors:     source.ParseTypes(q.Connectors),
+		QueryToExecute: q.QueryToExecute,
+		Engine:         q.Engine,
+		Parameters:     q.apiParameters(),
+		Tags:           q.GetTagsMap(),
+		Version:        q.Version,
+		CreatedAt:      q.CreatedAt,
+		UpdatedAt:      q.UpdatedAt,
+	}
+}
+
+// snapshot returns the version of the query as it is now
+func (q SavedQuery) snapshot(createdBy string) (SavedQueryVersion, error) {
+	version := SavedQueryVersion{
+		SavedQueryID:   q.ID,
+		Version:        q.Version,
+		Title:          q.Title,
+		Description:    q.Description,
+		QueryToExecute: q.QueryToExecute,
+		Engine:         q.Engine,
+		CreatedBy:      createdBy,
+	}
+	parameters, err := json.Marshal(q.apiParameters())
+	if err != nil {
+		return version, err
+	}
+	if err := version.Parameters.Set(parameters); err != nil {
+		return version, err
+	}
+	return version, nil
+}
+
+func (v SavedQueryVersion) GetParameters() []api.QueryParameter {
+	var parameters []api.QueryParameter
+	if v.Parameters.Status == pgtype.Present {
+		_ = json.Unmarshal(v.Parameters.Bytes, &parameters)
+	}
+	return parameters
+}
+
+func (v SavedQueryVersion) ToApi() api.SavedQueryVersion {
+	return api.SavedQueryVersion{
+		Version:        v.Version,
+		Title:          v.Title,
+		Description:    v.Description,
+		QueryToExecute: v.QueryToExecute,
+		Engine:         v.Engine,
+		Parameters:     v.GetParameters(),
+		CreatedBy:      v.CreatedBy,
+		CreatedAt:      v.CreatedAt,
+	}
+}
+
+func (db Database) CreateSavedQuery(query *SavedQuery) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		query.Version = 1
+		if err := tx.Create(query).Error; err != nil {
+			return err
+		}
+		version, err := query.snapshot(query.OwnerID)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&version).Error
+	})
+}
+
+// GetSavedQuery returns the saved query, or nil if it does not exist
+func (db Database) GetSavedQuery(id string) (*SavedQuery, error) {
+	var query SavedQuery
+	tx := db.orm.Model(&SavedQuery{}).Preload("Tags").Preload("Parameters").
+		Where("id = ?", id).First(&query)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &query, nil
+}
+
+// ListSavedQueriesVisibleTo returns the saved queries the user owns or which are shared with them
+func (db Database) ListSavedQueriesVisibleTo(userID string, search *string, visibility *api.SavedQueryVisibility, ownedOnly bool) ([]SavedQuery, error) {
+	var queries []SavedQuery
+	tx := db.orm.Model(&SavedQuery{}).Preload("Tags").Preload("Parameters")
+	if ownedOnly {
+		tx = tx.Where("owner_id = ?", userID)
+	} else {
+		tx = tx.Where("owner_id = ? OR visibility = ? OR (visibility = ? AND ? = ANY(shared_with))",
+			userID, api.SavedQueryVisibilityWorkspace, api.SavedQueryVisibilityTeam, userID)
+	}
+	if search != nil {
+		tx = tx.Where("title ILIKE ?", "%"+*search+"%")
+	}
+	if visibility != nil {
+		tx = tx.Where("visibility = ?", *visibility)
+	}
+	tx = tx.Order("updated_at DESC").Find(&queries)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return queries, nil
+}
+
+// UpdateSavedQuery stores the changes of the query as its next version
+func (db Database) UpdateSavedQuery(query *SavedQuery, updatedBy string) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		query.Version++
+		if err := tx.Where("saved_query_id = ?", query.ID).Delete(&SavedQueryTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("saved_query_id = ?", query.ID).Delete(&SavedQueryParameter{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(query).Error; err != nil {
+			return err
+		}
+		version, err := query.snapshot(updatedBy)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&version).Error
+	})
+}
+
+func (db Database) DeleteSavedQuery(id string) error {
+	return db.orm.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("saved_query_id = ?", id).Delete(&SavedQueryVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Select("Tags", "Parameters").Delete(&SavedQuery{ID: id}).Error
+	})
+}
+
+func (db Database) ListSavedQueryVersions(id string) ([]SavedQueryVersion, error) {
+	var versions []SavedQueryVersion
+	tx := db.orm.Model(&SavedQueryVersion{}).Where("saved_query_id = ?", id).Order("version DESC").Find(&versions)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return versions, nil
+}
+
+// GetSavedQueryVersion returns a version of the saved query, or nil if it does not exist
+func (db Database) GetSavedQueryVersion(id string, version int) (*SavedQueryVersion, error) {
+	var v SavedQueryVersion
+	tx := db.orm.Model(&SavedQueryVersion{}).Where("saved_query_id = ? AND version = ?", id, version).First(&v)
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, tx.Error
+	}
+	return &v, nil
+}
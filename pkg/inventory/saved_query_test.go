@@ -0,0 +1,111 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/kaytu-io/kaytu-util/pkg/model"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+	"github.com/kaytu-io/open-governance/pkg/inventory/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedQuery_IsVisibleTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  SavedQuery
+		userID string
+		want   bool
+	}{
+		{name: "private query of the owner", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityPrivate}, userID: "alice", want: true},
+		{name: "private query of another user", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityPrivate}, userID: "bob", want: false},
+		{name: "team member", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityTeam, SharedWith: []string{"bob", "carol"}}, userID: "carol", want: true},
+		{name: "outside the team", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityTeam, SharedWith: []string{"bob"}}, userID: "dave", want: false},
+		{name: "owner of a team query", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityTeam, SharedWith: []string{"bob"}}, userID: "alice", want: true},
+		{name: "shared with ignored on private queries", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityPrivate, SharedWith: []string{"bob"}}, userID: "bob", want: false},
+		{name: "workspace query", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityWorkspace}, userID: "bob", want: true},
+		{name: "user ids are case sensitive", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityTeam, SharedWith: []string{"Bob"}}, userID: "bob", want: false},
+		{name: "anonymous user", query: SavedQuery{OwnerID: "alice", Visibility: api.SavedQueryVisibilityPrivate}, userID: "", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.query.IsVisibleTo(tc.userID))
+		})
+	}
+}
+
+func TestSavedQuery_QueryParams(t *testing.T) {
+	query := SavedQuery{Parameters: []SavedQueryParameter{
+		{Key: "region", Required: true},
+		{Key: "tag", Required: false},
+	}}
+
+	params, err := query.queryParams(map[string]string{"region": "us-east-1", "extra": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "us-east-1", "tag": "", "extra": "x"}, params)
+
+	params, err = query.queryParams(map[string]string{"region": "", "tag": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "", "tag": "prod"}, params)
+
+	_, err = query.queryParams(map[string]string{"tag": "prod"})
+	assert.EqualError(t, err, "required query parameter not found: region")
+
+	_, err = query.queryParams(nil)
+	assert.EqualError(t, err, "required query parameter not found: region")
+
+	params, err = SavedQuery{}.queryParams(nil)
+	require.NoError(t, err)
+	assert.Empty(t, params)
+}
+
+func TestSavedQuery_Snapshot(t *testing.T) {
+	query := SavedQuery{
+		ID:             "q-1",
+		Title:          "Public buckets",
+		Description:    "Buckets readable by anyone",
+		OwnerID:        "alice",
+		Visibility:     api.SavedQueryVisibilityTeam,
+		Connectors:     []string{"AWS"},
+		QueryToExecute: "SELECT * FROM aws_s3_bucket WHERE region = '{{.region}}'",
+		Engine:         string(api.QueryEngine_OdysseusSQL),
+		Version:        3,
+		Tags:           []SavedQueryTag{{Tag: model.Tag{Key: "team", Value: []string{"security"}}, SavedQueryID: "q-1"}},
+		Parameters:     []SavedQueryParameter{{SavedQueryID: "q-1", Key: "region", Required: true}},
+	}
+
+	version, err := query.snapshot("bob")
+	require.NoError(t, err)
+	assert.Equal(t, "q-1", version.SavedQueryID)
+	assert.Equal(t, 3, version.Version)
+	assert.Equal(t, "bob", version.CreatedBy)
+	assert.Equal(t, []api.QueryParameter{{Key: "region", Required: true}}, version.GetParameters())
+
+	apiVersion := version.ToApi()
+	assert.Equal(t, 3, apiVersion.Version)
+	assert.Equal(t, query.Title, apiVersion.Title)
+	assert.Equal(t, query.Description, apiVersion.Description)
+	assert.Equal(t, query.QueryToExecute, apiVersion.QueryToExecute)
+	assert.Equal(t, query.Engine, apiVersion.Engine)
+
+	// the snapshot keeps the version as it was once the query changes
+	query.QueryToExecute = "SELECT * FROM aws_s3_bucket"
+	query.Parameters = nil
+	query.Version++
+	next, err := query.snapshot("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 4, next.Version)
+	assert.Empty(t, next.GetParameters())
+	assert.Equal(t, "SELECT * FROM aws_s3_bucket WHERE region = '{{.region}}'", version.QueryToExecute)
+	assert.Equal(t, []api.QueryParameter{{Key: "region", Required: true}}, version.GetParameters())
+
+	apiQuery := query.ToApi()
+	assert.Equal(t, 4, apiQuery.Version)
+	assert.Equal(t, []source.Type{source.CloudAWS}, apiQuery.Connectors)
+	assert.Equal(t, map[string][]string{"team": {"security"}}, apiQuery.Tags)
+	assert.Empty(t, apiQuery.Parameters)
+}
+
+func TestSavedQueryVersion_GetParameters_Missing(t *testing.T) {
+	assert.Nil(t, SavedQueryVersion{}.GetParameters())
+}
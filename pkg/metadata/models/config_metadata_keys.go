@@ -63,6 +63,8 @@ const (
 	MetadataKeyAzureDiscoveryRequiredOnly  MetadataKey = "azure_discovery_required_only"
 	MetadataKeyAssetDiscoveryEnabled       MetadataKey = "asset_discovery_enabled"
 	MetadataKeySpendDiscoveryEnabled       MetadataKey = "spend_discovery_enabled"
+	// MetadataKeyOutboundWebhookURL is where workspace alerts, e.g. of scheduled queries, are posted
+	MetadataKeyOutboundWebhookURL MetadataKey = "outbound_webhook_url"
 )
 
 var MetadataKeys = []MetadataKey{
@@ -103,6 +105,7 @@ var MetadataKeys = []MetadataKey{
 	MetadataKeyAzureDiscoveryRequiredOnly,
 	MetadataKeyAssetDiscoveryEnabled,
 	MetadataKeySpendDiscoveryEnabled,
+	MetadataKeyOutboundWebhookURL,
 }
 
 func (k MetadataKey) String() string {
@@ -185,6 +188,8 @@ func (k MetadataKey) GetConfigMetadataType() ConfigMetadataType {
 		return ConfigMetadataTypeBool
 	case MetadataKeySpendDiscoveryEnabled:
 		return ConfigMetadataTypeBool
+	case MetadataKeyOutboundWebhookURL:
+		return ConfigMetadataTypeString
 	}
 	return ""
 }
@@ -265,6 +270,8 @@ func (k MetadataKey) GetMinAuthRole() api.Role {
 		return api.KaytuAdminRole
 	case MetadataKeySpendDiscoveryEnabled:
 		return api.KaytuAdminRole
+	case MetadataKeyOutboundWebhookURL:
+		return api.AdminRole
 	}
 	return ""
 }
@@ -157,6 +157,21 @@ $$ LANGUAGE plpgsql;`
 	}()
 }
 
+// TableMap returns the tables of the plugin, it can be used to read the table schemas without starting the plugin
+func TableMap(ctx context.Context) map[string]*plugin.Table {
+	return map[string]*plugin.Table{
+		"kaytu_findings":               tableKaytuFindings(ctx),
+		"kaytu_resources":              tableKaytuResources(ctx),
+		"kaytu_lookup":                 tableKaytuLookup(ctx),
+		"kaytu_cost":                   tableKaytuCost(ctx),
+		"pennywise_cost_estimate":      tableKaytuCostEstimate(ctx),
+		"kaytu_connections":            tableKaytuConnections(ctx),
+		"kaytu_metrics":                tableKaytuMetrics(ctx),
+		"kaytu_api_benchmark_summary":  tableKaytuApiBenchmarkSummary(ctx),
+		"kaytu_api_benchmark_controls": tableKaytuApiBenchmarkControls(ctx),
+	}
+}
+
 func Plugin(ctx context.Context) *plugin.Plugin {
 	p := &plugin.Plugin{
 		Name:             "steampipe-plugin-kaytu",
@@ -165,17 +180,7 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			NewInstance: config.Instance,
 			Schema:      config.Schema(),
 		},
-		TableMap: map[string]*plugin.Table{
-			"kaytu_findings":               tableKaytuFindings(ctx),
-			"kaytu_resources":              tableKaytuResources(ctx),
-			"kaytu_lookup":                 tableKaytuLookup(ctx),
-			"kaytu_cost":                   tableKaytuCost(ctx),
-			"pennywise_cost_estimate":      tableKaytuCostEstimate(ctx),
-			"kaytu_connections":            tableKaytuConnections(ctx),
-			"kaytu_metrics":                tableKaytuMetrics(ctx),
-			"kaytu_api_benchmark_summary":  tableKaytuApiBenchmarkSummary(ctx),
-			"kaytu_api_benchmark_controls": tableKaytuApiBenchmarkControls(ctx),
-		},
+		TableMap: TableMap(ctx),
 	}
 
 	go initViews(ctx)
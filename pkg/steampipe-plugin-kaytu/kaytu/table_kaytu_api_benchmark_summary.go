@@ -262,6 +262,12 @@ func tableKaytuApiBenchmarkSummary(_ context.Context) *plugin.Table {
 				Description: "The cost optimization score of the benchmark summary",
 				Transform:   transform.FromField("CostOptimization"),
 			},
+			{
+				Name:        "weighted_security_score",
+				Type:        proto.ColumnType_DOUBLE,
+				Description: "The pass ratio of the benchmark summary findings weighted by severity and control overrides",
+				Transform:   transform.FromField("WeightedSecurityScore"),
+			},
 			{
 				Name:        "evaluated_at",
 				Type:        proto.ColumnType_TIMESTAMP,
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"fmt"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+)
+
+// ComplianceEvidence is the state of a resource at the time a control was evaluated against it,
+// documents are written once per compliance job and never updated
+type ComplianceEvidence struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	FindingEsID           string            `json:"findingEsID"`
+	ParentComplianceJobID uint              `json:"parentComplianceJobID"`
+	ComplianceJobID       uint              `json:"complianceJobID"`
+	BenchmarkID           string            `json:"benchmarkID" example:"azure_cis_v140"`
+	ControlID             string            `json:"controlID" example:"azure_cis_v140_7_5"`
+	QueryID               string            `json:"queryID"`
+	ConnectionID          string            `json:"connectionID" example:"8e0f8e7a-1b1c-4e6f-b7e4-9c6af9d2b1c8"`
+	Connector             source.Type       `json:"connector" example:"Azure"`
+	KaytuResourceID       string            `json:"kaytuResourceID" example:"/subscriptions/123/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1"`
+	ResourceID            string            `json:"resourceID" example:"/subscriptions/123/resourceGroups/rg-1/providers/Microsoft.Compute/virtualMachines/vm-1"`
+	ResourceType          string            `json:"resourceType" example:"Microsoft.Compute/virtualMachines"`
+	ConformanceStatus     ConformanceStatus `json:"conformanceStatus" example:"alarm"`
+	Reason                string            `json:"reason"`
+	EvaluatedAt           int64             `json:"evaluatedAt"`
+
+	// Resource is the resource document from the resources index, stored as json so it is kept as is
+	Resource string `json:"resource"`
+	// Checksum is the hex sha256 of Resource
+	Checksum string `json:"checksum"`
+}
+
+func (r ComplianceEvidence) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.FindingEsID,
+		fmt.Sprintf("%d", r.ComplianceJobID),
+	}, ComplianceEvidenceIndex
+}
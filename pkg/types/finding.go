@@ -61,6 +61,7 @@ type Finding struct {
 	ComplianceJobID       uint              `json:"complianceJobID" example:"1"`
 	ParentComplianceJobID uint              `json:"parentComplianceJobID" example:"1"`
 	LastTransition        int64             `json:"lastTransition" example:"1589395200"`
+	Owner                 string            `json:"owner" example:"payments"`
 
 	ParentBenchmarkReferences []string `json:"parentBenchmarkReferences"`
 	ParentBenchmarks          []string `json:"parentBenchmarks"`
@@ -96,6 +97,7 @@ type ResourceFinding struct {
 	ResourceName     string      `json:"resourceName"`
 	ResourceLocation string      `json:"resourceLocation"`
 	Connector        source.Type `json:"connector"`
+	Owner            string      `json:"owner"`
 
 	//ConformanceStatusPerSeverity ConformanceStatusPerSeverity `json:"conformanceStatusPerSeverity"`
 	Findings []Finding `json:"findings"`
@@ -1,9 +1,13 @@
 package types
 
 const (
-	FindingsIndex         = "findings"
-	FindingEventsIndex    = "finding_events"
-	ResourceFindingsIndex = "resource_findings"
-	BenchmarkSummaryIndex = "benchmark_summary"
-	QueryRunIndex         = "query_run"
+	FindingsIndex           = "findings"
+	FindingEventsIndex      = "finding_events"
+	ResourceFindingsIndex   = "resource_findings"
+	BenchmarkSummaryIndex   = "benchmark_summary"
+	QueryRunIndex           = "query_run"
+	ComplianceEvidenceIndex = "compliance_evidence"
+	ResourceChangesIndex    = "resource_changes"
+	ResourceSnapshotsIndex  = "resource_snapshots"
+	TerraformResourcesIndex = "terraform_resources"
 )
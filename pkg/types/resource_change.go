@@ -0,0 +1,80 @@
+package types
+
+import (
+	"fmt"
+	"github.com/kaytu-io/kaytu-util/pkg/source"
+)
+
+type ResourceChangeType string
+
+const (
+	ResourceChangeTypeCreate ResourceChangeType = "create"
+	ResourceChangeTypeModify ResourceChangeType = "modify"
+	ResourceChangeTypeDelete ResourceChangeType = "delete"
+)
+
+type ResourceChangeOperation string
+
+const (
+	ResourceChangeOperationAdd     ResourceChangeOperation = "add"
+	ResourceChangeOperationRemove  ResourceChangeOperation = "remove"
+	ResourceChangeOperationReplace ResourceChangeOperation = "replace"
+)
+
+// ResourceChangeDiff is a single change in the resource description, Path is a json pointer.
+// Values are kept as json so documents of different resource types do not clash in the index mapping
+type ResourceChangeDiff struct {
+	Operation ResourceChangeOperation `json:"operation"`
+	Path      string                  `json:"path"`
+	OldValue  string                  `json:"oldValue,omitempty"`
+	NewValue  string                  `json:"newValue,omitempty"`
+}
+
+// ResourceChange is recorded when a discovery job finds a new resource, finds a resource with a different
+// description than the previous discovery or does not find a resource anymore
+type ResourceChange struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	KaytuResourceID string               `json:"kaytuResourceID"`
+	ResourceType    string               `json:"resourceType"`
+	ResourceName    string               `json:"resourceName"`
+	ConnectionID    string               `json:"connectionID"`
+	Connector       source.Type          `json:"connector"`
+	DiscoveryJobID  uint                 `json:"discoveryJobID"`
+	ChangeType      ResourceChangeType   `json:"changeType"`
+	Diff            []ResourceChangeDiff `json:"diff"`
+	ChangedAt       int64                `json:"changedAt"`
+}
+
+func (r ResourceChange) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.KaytuResourceID,
+		r.ResourceType,
+		fmt.Sprintf("%d", r.DiscoveryJobID),
+		string(r.ChangeType),
+	}, ResourceChangesIndex
+}
+
+// ResourceSnapshot is the last description recorded for a resource, changes are computed against it
+type ResourceSnapshot struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	KaytuResourceID string      `json:"kaytuResourceID"`
+	ResourceType    string      `json:"resourceType"`
+	ConnectionID    string      `json:"connectionID"`
+	Connector       source.Type `json:"connector"`
+	DiscoveryJobID  uint        `json:"discoveryJobID"`
+	// Description is the resource description as json
+	Description string `json:"description"`
+	Checksum    string `json:"checksum"`
+	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+func (r ResourceSnapshot) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.KaytuResourceID,
+		r.ResourceType,
+	}, ResourceSnapshotsIndex
+}
@@ -0,0 +1,35 @@
+package types
+
+import "strings"
+
+type TerraformDriftStatus string
+
+const (
+	TerraformDriftStatusInSync     TerraformDriftStatus = "in_sync"
+	TerraformDriftStatusNotInState TerraformDriftStatus = "not_in_state" // found in the cloud but in none of the connection's states
+	TerraformDriftStatusNotInCloud TerraformDriftStatus = "not_in_cloud" // in a state but not found in the cloud anymore
+)
+
+// TerraformResource matches a cloud resource of a connection with the terraform states of that connection
+type TerraformResource struct {
+	EsID    string `json:"es_id"`
+	EsIndex string `json:"es_index"`
+
+	ConnectionID       string               `json:"connectionID"`
+	ResourceID         string               `json:"resourceID"` // ARN for AWS, resource ID for Azure
+	ResourceType       string               `json:"resourceType,omitempty"`
+	ResourceName       string               `json:"resourceName,omitempty"`
+	BackendID          uint                 `json:"backendID,omitempty"`
+	Address            string               `json:"address,omitempty"`
+	TerraformType      string               `json:"terraformType,omitempty"`
+	ManagedByTerraform bool                 `json:"managedByTerraform"`
+	DriftStatus        TerraformDriftStatus `json:"driftStatus"`
+	SyncedAt           int64                `json:"syncedAt"`
+}
+
+func (r TerraformResource) KeysAndIndex() ([]string, string) {
+	return []string{
+		r.ConnectionID,
+		strings.ToLower(r.ResourceID),
+	}, TerraformResourcesIndex
+}
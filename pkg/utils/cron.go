@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed standard 5 field cron expression (minute hour day-of-month month day-of-week),
+// fields support *, lists, ranges and steps. Times are evaluated in UTC.
+type CronSchedule struct {
+	minutes     [60]bool
+	hours       [24]bool
+	daysOfMonth [32]bool
+	months      [13]bool
+	daysOfWeek  [7]bool
+
+	// day of month and day of week are OR-ed when neither of them starts with *, like in crontab
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expr)
+	}
+
+	var c CronSchedule
+	var err error
+	if _, err = parseCronField(fields[0], 0, 59, c.minutes[:]); err != nil {
+		return nil, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if _, err = parseCronField(fields[1], 0, 23, c.hours[:]); err != nil {
+		return nil, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if c.anyDayOfMonth, err = parseCronField(fields[2], 1, 31, c.daysOfMonth[:]); err != nil {
+		return nil, fmt.Errorf("invalid day of month field: %w", err)
+	}
+	if _, err = parseCronField(fields[3], 1, 12, c.months[:]); err != nil {
+		return nil, fmt.Errorf("invalid month field: %w", err)
+	}
+	var daysOfWeek [8]bool
+	if c.anyDayOfWeek, err = parseCronField(fields[4], 0, 7, daysOfWeek[:]); err != nil {
+		return nil, fmt.Errorf("invalid day of week field: %w", err)
+	}
+	copy(c.daysOfWeek[:], daysOfWeek[:7])
+	// 7 is sunday as well
+	c.daysOfWeek[0] = c.daysOfWeek[0] || daysOfWeek[7]
+
+	return &c, nil
+}
+
+// parseCronField sets the allowed values of the field and reports whether the field starts with *,
+// like in crontab a stepped * such as */2 does not make day of month and day of week OR-ed
+func parseCronField(field string, min, max int, allowed []bool) (bool, error) {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return false, fmt.Errorf("invalid step in %q", part)
+			}
+			step = s
+			part = part[:idx]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return false, fmt.Errorf("invalid range %q", part)
+			}
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return false, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return false, fmt.Errorf("invalid value %q", part)
+			}
+			start, end = v, v
+			if step > 1 {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return false, fmt.Errorf("%q is out of range %d-%d", part, min, max)
+		}
+		for v := start; v <= end; v += step {
+			allowed[v] = true
+		}
+	}
+	return strings.HasPrefix(field, "*"), nil
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	dom := c.daysOfMonth[t.Day()]
+	dow := c.daysOfWeek[int(t.Weekday())]
+	if c.anyDayOfMonth || c.anyDayOfWeek {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first time strictly after t matching the schedule, zero if there is none in the next 5 years
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !c.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.hours[t.Hour()] {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !c.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
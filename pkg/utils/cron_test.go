@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@every 5m",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseCron(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	// 2024-01-01 is a monday
+	from := time.Date(2024, 1, 1, 10, 30, 15, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr string
+		want []time.Time
+	}{
+		{expr: "* * * * *", want: []time.Time{at(1, 1, 10, 31), at(1, 1, 10, 32)}},
+		{expr: "*/15 * * * *", want: []time.Time{at(1, 1, 10, 45), at(1, 1, 11, 0), at(1, 1, 11, 15)}},
+		{expr: "30 * * * *", want: []time.Time{at(1, 1, 11, 30), at(1, 1, 12, 30)}},
+		{expr: "0 9-17/4 * * *", want: []time.Time{at(1, 1, 13, 0), at(1, 1, 17, 0), at(1, 2, 9, 0)}},
+		{expr: "0 0,12 * * *", want: []time.Time{at(1, 1, 12, 0), at(1, 2, 0, 0), at(1, 2, 12, 0)}},
+		{expr: "5/20 10 * * *", want: []time.Time{at(1, 1, 10, 45), at(1, 2, 10, 5)}},
+		{expr: "@daily", want: []time.Time{at(1, 2, 0, 0), at(1, 3, 0, 0)}},
+		{expr: "@hourly", want: []time.Time{at(1, 1, 11, 0)}},
+		{expr: "@weekly", want: []time.Time{at(1, 7, 0, 0), at(1, 14, 0, 0)}},
+		{expr: "@monthly", want: []time.Time{at(2, 1, 0, 0), at(3, 1, 0, 0)}},
+		{expr: "0 0 31 * *", want: []time.Time{at(1, 31, 0, 0), at(3, 31, 0, 0)}},
+		{expr: "0 0 29 2 *", want: []time.Time{at(2, 29, 0, 0), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)}},
+		{expr: "0 0 * * 7", want: []time.Time{at(1, 7, 0, 0)}},
+		{expr: "0 0 * * 1-5", want: []time.Time{at(1, 2, 0, 0), at(1, 3, 0, 0), at(1, 4, 0, 0), at(1, 5, 0, 0), at(1, 8, 0, 0)}},
+		// day of month and day of week are OR-ed when both are restricted: the 10th or any friday
+		{expr: "0 0 10 * 5", want: []time.Time{at(1, 5, 0, 0), at(1, 10, 0, 0), at(1, 12, 0, 0)}},
+		// a stepped * counts as unrestricted like in crontab, so both day fields have to match:
+		// odd days that are fridays, and the 10th when it is on an even weekday
+		{expr: "0 0 */2 * 5", want: []time.Time{at(1, 5, 0, 0), at(1, 19, 0, 0), at(2, 9, 0, 0)}},
+		{expr: "0 0 10 * */2", want: []time.Time{at(2, 10, 0, 0), at(3, 10, 0, 0), at(8, 10, 0, 0)}},
+		{expr: "0 0 */10 * *", want: []time.Time{at(1, 11, 0, 0), at(1, 21, 0, 0), at(1, 31, 0, 0), at(2, 1, 0, 0)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			c, err := ParseCron(tc.expr)
+			require.NoError(t, err)
+			next := from
+			for _, want := range tc.want {
+				next = c.Next(next)
+				assert.Equal(t, want, next)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_Next_Never(t *testing.T) {
+	c, err := ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, c.Next(time.Now()).IsZero())
+}
+
+func TestCronSchedule_Next_UTC(t *testing.T) {
+	c, err := ParseCron("0 12 * * *")
+	require.NoError(t, err)
+	from := time.Date(2024, 1, 1, 11, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), c.Next(from))
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/jq"
 	es "github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
 	"github.com/kaytu-io/kaytu-util/pkg/koanf"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	"github.com/kaytu-io/open-governance/services/es-sink/api"
 	"github.com/kaytu-io/open-governance/services/es-sink/config"
 	"github.com/kaytu-io/open-governance/services/es-sink/grpcApi"
@@ -51,7 +52,13 @@ func Command() *cobra.Command {
 				return err
 			}
 
-			sinkService, err := service.NewEsSinkService(ctx, logger, esClient, nats)
+			deadLetters, err := dlq.New(cnf.NATS.URL, logger)
+			if err != nil {
+				logger.Error("failed to create dead-letter queue", zap.Error(err))
+				return err
+			}
+
+			sinkService, err := service.NewEsSinkService(ctx, logger, esClient, nats, deadLetters)
 			if err != nil {
 				logger.Error("failed to create es sink service", zap.Error(err))
 				return err
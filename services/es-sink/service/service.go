@@ -8,6 +8,7 @@ import (
 	"github.com/kaytu-io/kaytu-util/pkg/es"
 	"github.com/kaytu-io/kaytu-util/pkg/jq"
 	essdk "github.com/kaytu-io/kaytu-util/pkg/kaytu-es-sdk"
+	"github.com/kaytu-io/open-governance/pkg/dlq"
 	"github.com/kaytu-io/open-governance/pkg/utils"
 	"github.com/nats-io/nats.go/jetstream"
 	"go.uber.org/zap"
@@ -24,14 +25,16 @@ type EsSinkService struct {
 	logger        *zap.Logger
 	elasticSearch essdk.Client
 	nats          *jq.JobQueue
+	dlq           *dlq.DeadLetterQueue
 	esSinkModule  *EsSinkModule
 }
 
-func NewEsSinkService(ctx context.Context, logger *zap.Logger, elasticSearch essdk.Client, nats *jq.JobQueue) (*EsSinkService, error) {
+func NewEsSinkService(ctx context.Context, logger *zap.Logger, elasticSearch essdk.Client, nats *jq.JobQueue, dlq *dlq.DeadLetterQueue) (*EsSinkService, error) {
 	service := EsSinkService{
 		logger:        logger,
 		elasticSearch: elasticSearch,
 		nats:          nats,
+		dlq:           dlq,
 	}
 
 	esSinkModule, err := NewEsSinkModule(ctx, logger, elasticSearch)
@@ -84,6 +87,7 @@ func (s *EsSinkService) ConsumeCycle(ctx context.Context) {
 		err := json.Unmarshal(msg.Data(), &doc)
 		if err != nil {
 			s.logger.Error("failed to unmarshal doc", zap.Error(err), zap.Any("msg", msg))
+			s.dlq.Fail(ctx, ConsumerGroup, msg, err, 1)
 			return
 		}
 
@@ -18,6 +18,7 @@ type JobInfo struct {
 	MigrationJobName string     `json:"migrationJobName"`
 	Status           JobsStatus `json:"status"`
 	FailureReason    string     `json:"failureReason"`
+	ValidationErrors []string   `json:"validationErrors,omitempty"` // Errors of the imported queries, they do not fail the migration
 }
 
 type Migration struct {
@@ -18,7 +18,7 @@ var migrations = map[string]types.Migration{
 	"inventory":           inventory.Migration{},
 	"resource_collection": resource_collection.Migration{},
 	"elasticsearch":       elasticsearch.Migration{},
-	"compliance":          compliance.Migration{},
-	"analytics":           analytics.Migration{},
+	"compliance":          &compliance.Migration{},
+	"analytics":           &analytics.Migration{},
 	"resource_info":       resource_info.Migration{},
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/kaytu-io/open-governance/pkg/inventory"
 	"github.com/kaytu-io/open-governance/pkg/metadata/models"
 	"github.com/kaytu-io/open-governance/services/migrator/config"
+	"github.com/kaytu-io/open-governance/services/migrator/job/migrations/shared"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -24,17 +25,22 @@ import (
 var QueryParameters []models.QueryParameter
 
 type Migration struct {
+	validationErrors []string
 }
 
-func (m Migration) IsGitBased() bool {
+func (m *Migration) IsGitBased() bool {
 	return true
 }
 
-func (m Migration) AttachmentFolderPath() string {
+func (m *Migration) AttachmentFolderPath() string {
 	return ""
 }
 
-func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *zap.Logger) error {
+func (m *Migration) ValidationErrors() []string {
+	return m.validationErrors
+}
+
+func (m *Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *zap.Logger) error {
 	orm, err := postgres.NewClient(&postgres.Config{
 		Host:    conf.PostgreSQL.Host,
 		Port:    conf.PostgreSQL.Port,
@@ -46,6 +52,7 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 	if err != nil {
 		return fmt.Errorf("new inventory postgres client: %w", err)
 	}
+	m.validationErrors = nil
 
 	err = filepath.Walk(config.AssetsGitPath, func(path string, info fs.FileInfo, err error) error {
 		if strings.HasSuffix(path, ".yaml") {
@@ -106,6 +113,25 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 		return err
 	}
 
+	validator, err := shared.NewQueryValidator(ctx, metadataOrm)
+	if err != nil {
+		logger.Error("failed to initialize query validator", zap.Error(err))
+		return nil
+	}
+	var queries []inventory.Query
+	if err := orm.WithContext(ctx).Preload("Parameters").Find(&queries).Error; err != nil {
+		logger.Error("failed to list queries for validation", zap.Error(err))
+		return nil
+	}
+	for _, query := range queries {
+		params := make([]string, 0, len(query.Parameters))
+		for _, param := range query.Parameters {
+			params = append(params, param.Key)
+		}
+		validator.Validate(query.ID, query.Engine, query.QueryToExecute, params)
+	}
+	m.validationErrors = validator.Errors()
+
 	return nil
 }
 
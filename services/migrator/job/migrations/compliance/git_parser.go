@@ -6,6 +6,7 @@ import (
 	"github.com/goccy/go-yaml"
 	"github.com/jackc/pgtype"
 	"github.com/kaytu-io/kaytu-util/pkg/model"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
 	"github.com/kaytu-io/open-governance/pkg/compliance/db"
 	"github.com/kaytu-io/open-governance/pkg/metadata/models"
 	"github.com/kaytu-io/open-governance/pkg/types"
@@ -214,6 +215,7 @@ func (g *GitParser) ExtractControls(complianceControlsPath string, controlEnrich
 				Severity:           types.ParseFindingSeverity(control.Severity),
 				ManualVerification: control.ManualVerification,
 				Managed:            control.Managed,
+				Source:             complianceApi.ContentSourceBuiltIn,
 			}
 
 			if control.Query != nil {
@@ -225,6 +227,7 @@ func (g *GitParser) ExtractControls(complianceControlsPath string, controlEnrich
 					ListOfTables:   control.Query.ListOfTables,
 					Engine:         control.Query.Engine,
 					Global:         control.Query.Global,
+					Source:         complianceApi.ContentSourceBuiltIn,
 				}
 				g.controlsQueries[control.ID] = q
 				for _, parameter := range control.Query.Parameters {
@@ -311,6 +314,7 @@ func (g *GitParser) ExtractBenchmarks(complianceBenchmarksPath string) error {
 			Description:       o.Description,
 			AutoAssign:        o.AutoAssign,
 			TracksDriftEvents: o.TracksDriftEvents,
+			Source:            complianceApi.ContentSourceBuiltIn,
 			Tags:              tags,
 			Children:          nil,
 			Controls:          nil,
@@ -538,3 +542,68 @@ func contains[T uint | int | string](arr []T, ob T) bool {
 	}
 	return false
 }
+
+// dropCustomClashes removes the content objects which have the same id as a benchmark, control or query
+// created through the API, the custom objects are kept. It returns a message for each dropped object.
+func (g *GitParser) dropCustomClashes(customBenchmarkIDs, customControlIDs, customQueryIDs []string) []string {
+	customBenchmarks := make(map[string]bool, len(customBenchmarkIDs))
+	for _, id := range customBenchmarkIDs {
+		customBenchmarks[id] = true
+	}
+	customControls := make(map[string]bool, len(customControlIDs))
+	for _, id := range customControlIDs {
+		customControls[id] = true
+	}
+	customQueries := make(map[string]bool, len(customQueryIDs))
+	for _, id := range customQueryIDs {
+		customQueries[id] = true
+	}
+
+	var messages []string
+	queries := g.queries[:0]
+	for _, q := range g.queries {
+		if customQueries[q.ID] {
+			messages = append(messages, fmt.Sprintf("query %s clashes with a custom query, the custom query is kept", q.ID))
+			continue
+		}
+		queries = append(queries, q)
+	}
+	g.queries = queries
+
+	controls := g.controls[:0]
+	for _, c := range g.controls {
+		if customControls[c.ID] {
+			messages = append(messages, fmt.Sprintf("control %s clashes with a custom control, the custom control is kept", c.ID))
+			continue
+		}
+		controls = append(controls, c)
+	}
+	g.controls = controls
+
+	benchmarks := g.benchmarks[:0]
+	for _, b := range g.benchmarks {
+		if customBenchmarks[b.ID] {
+			messages = append(messages, fmt.Sprintf("benchmark %s clashes with a custom benchmark, the custom benchmark is kept", b.ID))
+			continue
+		}
+		// content benchmarks must not pick up custom objects through the clashing ids
+		children := b.Children[:0]
+		for _, child := range b.Children {
+			if !customBenchmarks[child.ID] {
+				children = append(children, child)
+			}
+		}
+		b.Children = children
+		benchmarkControls := b.Controls[:0]
+		for _, control := range b.Controls {
+			if !customControls[control.ID] {
+				benchmarkControls = append(benchmarkControls, control)
+			}
+		}
+		b.Controls = benchmarkControls
+		benchmarks = append(benchmarks, b)
+	}
+	g.benchmarks = benchmarks
+
+	return messages
+}
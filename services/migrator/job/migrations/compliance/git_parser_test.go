@@ -0,0 +1,95 @@
+package compliance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
+	"github.com/kaytu-io/open-governance/pkg/compliance/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestExtractControls_KeepsContentManaged(t *testing.T) {
+	dir := t.TempDir()
+	controls := map[string]string{
+		"managed.yaml": `ID: aws_managed
+Title: Managed control
+Connector: [AWS]
+Managed: true
+Query:
+  QueryToExecute: select 1
+  Engine: odysseus-v0.0.1
+`,
+		"unmanaged.yaml": `ID: aws_unmanaged
+Title: Unmanaged control
+Connector: [AWS]
+Query:
+  QueryToExecute: select 2
+  Engine: odysseus-v0.0.1
+`,
+	}
+	for name, content := range controls {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	g := GitParser{logger: zap.NewNop(), controlsQueries: make(map[string]db.Query)}
+	require.NoError(t, g.ExtractControls(dir, t.TempDir()))
+	require.Len(t, g.controls, 2)
+	require.Len(t, g.queries, 2)
+
+	managed := make(map[string]bool)
+	for _, control := range g.controls {
+		managed[control.ID] = control.Managed
+		assert.Equal(t, complianceApi.ContentSourceBuiltIn, control.Source)
+	}
+	assert.Equal(t, map[string]bool{"aws_managed": true, "aws_unmanaged": false}, managed)
+	for _, query := range g.queries {
+		assert.Equal(t, complianceApi.ContentSourceBuiltIn, query.Source)
+	}
+}
+
+func TestDropCustomClashes(t *testing.T) {
+	g := GitParser{
+		queries: []db.Query{{ID: "q1"}, {ID: "shared"}},
+		controls: []db.Control{
+			{ID: "c1"},
+			{ID: "shared"},
+		},
+		benchmarks: []db.Benchmark{
+			{
+				ID:       "b1",
+				Children: []db.Benchmark{{ID: "b2"}, {ID: "shared"}},
+				Controls: []db.Control{{ID: "c1"}, {ID: "shared"}},
+			},
+			{ID: "b2"},
+			{ID: "shared"},
+		},
+	}
+
+	messages := g.dropCustomClashes([]string{"shared"}, []string{"shared"}, []string{"shared"})
+	assert.Len(t, messages, 3)
+
+	assert.Equal(t, []db.Query{{ID: "q1"}}, g.queries)
+	assert.Equal(t, []db.Control{{ID: "c1"}}, g.controls)
+	require.Len(t, g.benchmarks, 2)
+	assert.Equal(t, "b1", g.benchmarks[0].ID)
+	assert.Equal(t, []db.Benchmark{{ID: "b2"}}, g.benchmarks[0].Children)
+	assert.Equal(t, []db.Control{{ID: "c1"}}, g.benchmarks[0].Controls)
+	assert.Equal(t, "b2", g.benchmarks[1].ID)
+}
+
+func TestDropCustomClashes_NoCustomContent(t *testing.T) {
+	g := GitParser{
+		queries:    []db.Query{{ID: "q1"}},
+		controls:   []db.Control{{ID: "c1"}},
+		benchmarks: []db.Benchmark{{ID: "b1", Controls: []db.Control{{ID: "c1"}}}},
+	}
+
+	assert.Empty(t, g.dropCustomClashes(nil, nil, nil))
+	assert.Len(t, g.queries, 1)
+	assert.Len(t, g.controls, 1)
+	assert.Equal(t, []db.Control{{ID: "c1"}}, g.benchmarks[0].Controls)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/kaytu-io/kaytu-util/pkg/postgres"
+	complianceApi "github.com/kaytu-io/open-governance/pkg/compliance/api"
 	"github.com/kaytu-io/open-governance/pkg/compliance/db"
 	"github.com/kaytu-io/open-governance/pkg/metadata/models"
 	"github.com/kaytu-io/open-governance/services/migrator/config"
+	"github.com/kaytu-io/open-governance/services/migrator/job/migrations/shared"
 	"go.uber.org/zap"
 
 	"gorm.io/gorm"
@@ -14,17 +16,22 @@ import (
 )
 
 type Migration struct {
+	validationErrors []string
 }
 
-func (m Migration) IsGitBased() bool {
+func (m *Migration) IsGitBased() bool {
 	return true
 }
 
-func (m Migration) AttachmentFolderPath() string {
+func (m *Migration) AttachmentFolderPath() string {
 	return ""
 }
 
-func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *zap.Logger) error {
+func (m *Migration) ValidationErrors() []string {
+	return m.validationErrors
+}
+
+func (m *Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *zap.Logger) error {
 	orm, err := postgres.NewClient(&postgres.Config{
 		Host:    conf.PostgreSQL.Host,
 		Port:    conf.PostgreSQL.Port,
@@ -37,6 +44,7 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 		return fmt.Errorf("new postgres client: %w", err)
 	}
 	dbm := db.Database{Orm: orm}
+	m.validationErrors = nil
 
 	ormMetadata, err := postgres.NewClient(&postgres.Config{
 		Host:    conf.PostgreSQL.Host,
@@ -66,14 +74,45 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 
 	logger.Info("extracted controls, benchmarks and query views", zap.Int("controls", len(p.controls)), zap.Int("benchmarks", len(p.benchmarks)), zap.Int("query_views", len(p.queries)))
 
+	// Custom benchmarks, controls and queries created through the API survive the reload. The custom object is kept
+	// when it has the same id as a content object, the clash is reported in the migration status.
+	custom := complianceApi.ContentSourceCustom
+	var customBenchmarkIDs, customControlIDs, customQueryIDs []string
+	for _, list := range []struct {
+		model any
+		ids   *[]string
+	}{
+		{model: &db.Benchmark{}, ids: &customBenchmarkIDs},
+		{model: &db.Control{}, ids: &customControlIDs},
+		{model: &db.Query{}, ids: &customQueryIDs},
+	} {
+		if err := dbm.Orm.WithContext(ctx).Model(list.model).Where("source = ?", custom).Pluck("id", list.ids).Error; err != nil {
+			logger.Error("failed to list custom content", zap.Error(err))
+			return err
+		}
+	}
+	m.validationErrors = append(m.validationErrors, p.dropCustomClashes(customBenchmarkIDs, customControlIDs, customQueryIDs)...)
+
+	// Links of custom benchmarks are kept aside since they may point to built-in controls and benchmarks which are recreated below.
+	var customBenchmarkChildren []db.BenchmarkChild
+	var customBenchmarkControls []db.BenchmarkControls
 	loadedQueries := make(map[string]bool)
 	err = dbm.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		customBenchmarks := tx.Model(&db.Benchmark{}).Select("id").Where("source = ?", custom)
+		if err := tx.Where("benchmark_id IN (?)", customBenchmarks).Find(&customBenchmarkChildren).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("benchmark_id IN (?)", customBenchmarks).Find(&customBenchmarkControls).Error; err != nil {
+			return err
+		}
+
 		tx.Model(&db.BenchmarkChild{}).Where("1=1").Unscoped().Delete(&db.BenchmarkChild{})
 		tx.Model(&db.BenchmarkControls{}).Where("1=1").Unscoped().Delete(&db.BenchmarkControls{})
-		tx.Model(&db.Benchmark{}).Where("1=1").Unscoped().Delete(&db.Benchmark{})
-		tx.Model(&db.Control{}).Where("1=1").Unscoped().Delete(&db.Control{})
-		tx.Model(&db.QueryParameter{}).Where("1=1").Unscoped().Delete(&db.QueryParameter{})
-		tx.Model(&db.Query{}).Where("1=1").Unscoped().Delete(&db.Query{})
+		tx.Model(&db.Benchmark{}).Where("source IS NULL OR source <> ?", custom).Unscoped().Delete(&db.Benchmark{})
+		tx.Model(&db.Control{}).Where("source IS NULL OR source <> ?", custom).Unscoped().Delete(&db.Control{})
+		tx.Model(&db.QueryParameter{}).Where("query_id IN (?)",
+			tx.Model(&db.Query{}).Select("id").Where("source IS NULL OR source <> ?", custom)).Unscoped().Delete(&db.QueryParameter{})
+		tx.Model(&db.Query{}).Where("source IS NULL OR source <> ?", custom).Unscoped().Delete(&db.Query{})
 
 		for _, obj := range p.queries {
 			obj.Controls = nil
@@ -134,6 +173,23 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 		return err
 	}
 
+	validator, err := shared.NewQueryValidator(ctx, ormMetadata)
+	if err != nil {
+		logger.Error("failed to initialize query validator", zap.Error(err))
+	} else {
+		for _, obj := range p.queries {
+			params := make([]string, 0, len(obj.Parameters))
+			for _, param := range obj.Parameters {
+				params = append(params, param.Key)
+			}
+			validator.Validate(obj.ID, obj.Engine, obj.QueryToExecute, params)
+		}
+		for _, obj := range p.queryViews {
+			validator.Validate(obj.ID, "", obj.Query, nil)
+		}
+		m.validationErrors = append(m.validationErrors, validator.Errors()...)
+	}
+
 	missingQueries := make(map[string]bool)
 	err = dbm.Orm.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 
@@ -214,6 +270,33 @@ func (m Migration) Run(ctx context.Context, conf config.MigratorConfig, logger *
 			}
 		}
 
+		for _, link := range customBenchmarkChildren {
+			var count int64
+			if err := tx.Model(&db.Benchmark{}).Where("id = ?", link.ChildID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				logger.Warn("dropping custom benchmark child removed from content", zap.String("benchmark_id", link.BenchmarkID), zap.String("child_id", link.ChildID))
+				continue
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+				return err
+			}
+		}
+		for _, link := range customBenchmarkControls {
+			var count int64
+			if err := tx.Model(&db.Control{}).Where("id = ?", link.ControlID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				logger.Warn("dropping custom benchmark control removed from content", zap.String("benchmark_id", link.BenchmarkID), zap.String("control_id", link.ControlID))
+				continue
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+				return err
+			}
+		}
+
 		missingQueriesList := make([]string, 0, len(missingQueries))
 		for query := range missingQueries {
 			missingQueriesList = append(missingQueriesList, query)
@@ -0,0 +1,73 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+
+	inventoryApi "github.com/kaytu-io/open-governance/pkg/inventory/api"
+	queryvalidator "github.com/kaytu-io/open-governance/pkg/inventory/query-validator"
+	"github.com/kaytu-io/open-governance/pkg/metadata/models"
+	"gorm.io/gorm"
+)
+
+// declaredParameterValue is used for parameters declared by a query which have no workspace value, they are given at run time
+const declaredParameterValue = "NULL"
+
+// QueryValidator checks the queries imported by a migration against the steampipe plugin schemas.
+// Invalid queries are still imported, their errors are reported in the migration status.
+type QueryValidator struct {
+	schema *queryvalidator.Schema
+	params map[string]string
+	views  []string
+	errors []string
+}
+
+func NewQueryValidator(ctx context.Context, metadataOrm *gorm.DB) (*QueryValidator, error) {
+	var queryParams []models.QueryParameter
+	if err := metadataOrm.WithContext(ctx).Find(&queryParams).Error; err != nil {
+		return nil, fmt.Errorf("failed to list query parameters: %w", err)
+	}
+	var queryViews []models.QueryView
+	if err := metadataOrm.WithContext(ctx).Find(&queryViews).Error; err != nil {
+		return nil, fmt.Errorf("failed to list query views: %w", err)
+	}
+
+	v := QueryValidator{
+		schema: queryvalidator.NewPluginSchema(ctx),
+		params: make(map[string]string),
+	}
+	for _, qp := range queryParams {
+		v.params[qp.Key] = qp.Value
+	}
+	for _, view := range queryViews {
+		v.views = append(v.views, view.ID)
+	}
+	return &v, nil
+}
+
+// Validate checks a query and records its errors, declaredParams are the keys of the parameters the query declares
+func (v *QueryValidator) Validate(id, engine, query string, declaredParams []string) {
+	params := make(map[string]string, len(v.params)+len(declaredParams))
+	for _, key := range declaredParams {
+		params[key] = declaredParameterValue
+	}
+	for key, value := range v.params {
+		params[key] = value
+	}
+
+	var resp inventoryApi.ValidateQueryResponse
+	if engine == inventoryApi.QueryEngine_OdysseusRego {
+		resp = queryvalidator.ValidateTemplate(query, params)
+	} else {
+		resp = v.schema.Validate(query, params, v.views)
+	}
+	for _, issue := range resp.Issues {
+		if issue.Severity == inventoryApi.QueryValidationSeverityError {
+			v.errors = append(v.errors, fmt.Sprintf("query %s: %s", id, issue.Message))
+		}
+	}
+}
+
+func (v *QueryValidator) Errors() []string {
+	return v.errors
+}
@@ -11,3 +11,8 @@ type Migration interface {
 	IsGitBased() bool
 	AttachmentFolderPath() string
 }
+
+// ValidationReporter is implemented by migrations which validate the queries they import
+type ValidationReporter interface {
+	ValidationErrors() []string
+}
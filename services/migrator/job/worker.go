@@ -9,6 +9,7 @@ import (
 	"github.com/kaytu-io/open-governance/services/migrator/config"
 	"github.com/kaytu-io/open-governance/services/migrator/db"
 	"github.com/kaytu-io/open-governance/services/migrator/db/model"
+	"github.com/kaytu-io/open-governance/services/migrator/job/types"
 	"go.uber.org/zap"
 	"time"
 )
@@ -148,6 +149,13 @@ func (w *Job) Run(ctx context.Context) error {
 			w.logger.Error("failed to run migration", zap.Error(err), zap.String("migrationName", name))
 			updateFailed = true
 		}
+		var validationErrors []string
+		if reporter, ok := mig.(types.ValidationReporter); ok {
+			validationErrors = reporter.ValidationErrors()
+			if len(validationErrors) > 0 {
+				w.logger.Warn("migration imported invalid queries", zap.String("migrationName", name), zap.Strings("errors", validationErrors))
+			}
+		}
 
 		jobsStatus, err = getJobsStatus(m)
 		if err != nil {
@@ -159,12 +167,14 @@ func (w *Job) Run(ctx context.Context) error {
 				MigrationJobName: name,
 				Status:           model.JobStatusFailed,
 				FailureReason:    migErr.Error(),
+				ValidationErrors: validationErrors,
 			}
 		} else {
 			jobsStatus[name] = model.JobInfo{
 				MigrationJobName: name,
 				Status:           model.JobStatusCompleted,
 				FailureReason:    "",
+				ValidationErrors: validationErrors,
 			}
 		}
 		err = w.updateJob(m, m.Status, jobsStatus)